@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
 	"time"
@@ -8,18 +10,43 @@ import (
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/adaptive"
+	"github.com/odundlaw/cbt-backend/internal/analysis"
+	"github.com/odundlaw/cbt-backend/internal/attempts"
+	"github.com/odundlaw/cbt-backend/internal/combinations"
+	"github.com/odundlaw/cbt-backend/internal/commissions"
+	"github.com/odundlaw/cbt-backend/internal/entitlements"
+	"github.com/odundlaw/cbt-backend/internal/exams"
+	"github.com/odundlaw/cbt-backend/internal/grading"
+	"github.com/odundlaw/cbt-backend/internal/importer"
+	"github.com/odundlaw/cbt-backend/internal/marking"
+	"github.com/odundlaw/cbt-backend/internal/media"
 	"github.com/odundlaw/cbt-backend/internal/middlewares"
+	"github.com/odundlaw/cbt-backend/internal/pastpapers"
+	"github.com/odundlaw/cbt-backend/internal/payments"
+	"github.com/odundlaw/cbt-backend/internal/practice"
+	"github.com/odundlaw/cbt-backend/internal/proctoring"
+	"github.com/odundlaw/cbt-backend/internal/questions"
+	"github.com/odundlaw/cbt-backend/internal/results"
+	"github.com/odundlaw/cbt-backend/internal/reviews"
+	"github.com/odundlaw/cbt-backend/internal/richtext"
+	"github.com/odundlaw/cbt-backend/internal/scheduling"
+	"github.com/odundlaw/cbt-backend/internal/similarity"
+	"github.com/odundlaw/cbt-backend/internal/storage"
 	"github.com/odundlaw/cbt-backend/internal/store"
+	"github.com/odundlaw/cbt-backend/internal/subscriptions"
 	"github.com/odundlaw/cbt-backend/internal/users"
-	"github.com/redis/go-redis/v9"
+	"github.com/odundlaw/cbt-backend/internal/vouchers"
 )
 
 type Application struct {
 	config Config
-	conn   *pgx.Conn
-	rdb    *redis.Client
+	db     *pgxpool.Pool
+	rdb    *store.Redis
+	blob   storage.Blob
+	pay    payments.Provider
 }
 
 type Config struct {
@@ -39,12 +66,13 @@ type RedisConfig struct {
 func (app *Application) mount() http.Handler {
 	r := chi.NewRouter()
 
-	rdb := store.NewRedis(app.config.redis.addr)
+	rdb := app.rdb
+	queries := repo.New(app.db)
 
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "Idempotency-Key"},
 		AllowCredentials: true,
 	}))
 
@@ -60,15 +88,108 @@ func (app *Application) mount() http.Handler {
 		w.Write([]byte("all is good"))
 	})
 
-	userSerice := users.NewService(repo.New(app.conn))
+	commissionService := commissions.NewService(queries, app.db)
+	commissionHandler := commissions.NewHandler(commissionService)
+
+	userSerice := users.NewService(queries, app.db, commissionService)
 	userHandler := users.NewHandler(userSerice, rdb)
 
+	examService := exams.NewService(queries)
+	examHandler := exams.NewHandler(examService)
+
+	gradingService := grading.NewService(queries, app.db)
+	gradingHandler := grading.NewHandler(gradingService)
+
+	questionService := questions.NewService(queries, app.db)
+	questionHandler := questions.NewHandler(questionService, gradingService)
+
+	mediaService := media.NewService(queries, app.blob)
+	mediaHandler := media.NewHandler(mediaService)
+
+	importService := importer.NewService(queries, app.db, mediaService)
+	importHandler := importer.NewHandler(importService)
+
+	reviewService := reviews.NewService(queries, app.db)
+	reviewHandler := reviews.NewHandler(reviewService)
+
+	similarityService := similarity.NewService(queries, app.db)
+	similarityHandler := similarity.NewHandler(similarityService)
+
+	entitlementService := entitlements.NewService(queries)
+	entitlementHandler := entitlements.NewHandler(entitlementService)
+
+	schedulingService := scheduling.NewService(queries, entitlementService)
+	schedulingHandler := scheduling.NewHandler(schedulingService)
+
+	voucherService := vouchers.NewService(queries, app.db, commissionService)
+	voucherHandler := vouchers.NewHandler(voucherService)
+
+	combinationService := combinations.NewService(queries, app.db)
+	combinationHandler := combinations.NewHandler(combinationService)
+
+	attemptService := attempts.NewService(queries, app.db, rdb, gradingService, schedulingService, combinationService)
+	attemptHandler := attempts.NewHandler(attemptService)
+
+	adaptiveService := adaptive.NewService(queries, app.db, attemptService)
+	adaptiveHandler := adaptive.NewHandler(adaptiveService)
+
+	proctoringService := proctoring.NewService(queries, app.db, rdb, attemptService)
+	proctoringHandler := proctoring.NewHandler(proctoringService)
+
+	markingService := marking.NewService(queries, app.db, gradingService)
+	markingHandler := marking.NewHandler(markingService)
+
+	subscriptionService := subscriptions.NewService(queries, app.db)
+	subscriptionHandler := subscriptions.NewHandler(subscriptionService)
+
+	paymentService := payments.NewService(queries, app.db, app.pay, subscriptionService)
+	paymentHandler := payments.NewHandler(paymentService)
+
+	resultService := results.NewService(queries)
+	resultHandler := results.NewHandler(resultService)
+
+	practiceService := practice.NewService(queries, app.db)
+	practiceHandler := practice.NewHandler(practiceService)
+
+	pastPaperService := pastpapers.NewService(queries, app.db)
+	pastPaperHandler := pastpapers.NewHandler(pastPaperService)
+
+	analysisService := analysis.NewService(queries, app.db)
+	analysisHandler := analysis.NewHandler(analysisService)
+
+	richTextHandler := richtext.NewHandler()
+
 	r.Mount("/", AuthRoutes(userHandler, rdb))
+	r.Mount("/api/exams", ExamRoutes(examHandler, attemptHandler, combinationHandler, rdb))
+	r.Mount("/api/attempts", AttemptRoutes(attemptHandler, adaptiveHandler, proctoringHandler, rdb))
+	r.Mount("/api/results", ResultRoutes(resultHandler, rdb))
+	r.Mount("/api/vouchers", VoucherRoutes(voucherHandler, rdb))
+	r.Mount("/api/payments", PaymentRoutes(paymentHandler, rdb))
+	r.Mount("/api/plans", PlanRoutes(subscriptionHandler, rdb))
+	r.Mount("/api/subscriptions", SubscriptionRoutes(subscriptionHandler, rdb))
+	r.Mount("/api/entitlements", EntitlementRoutes(entitlementHandler, rdb))
+	r.Mount("/api/practice", PracticeRoutes(practiceHandler, entitlementService, rdb))
+	r.Mount("/api/past-papers", PastPaperRoutes(pastPaperHandler, rdb))
+	r.Mount("/api/media", MediaRoutes(mediaHandler, rdb, queries))
+	r.Mount("/api/content", ContentRoutes(richTextHandler, rdb))
+	r.Mount("/api/agent", AgentRoutes(voucherHandler, commissionHandler, rdb, queries))
+	r.Mount("/api/admin/exams", AdminExamRoutes(examHandler, questionHandler, gradingHandler, resultHandler, schedulingHandler, combinationHandler, adaptiveHandler, analysisHandler, importHandler, proctoringHandler, rdb, queries))
+	r.Mount("/api/admin/questions", AdminQuestionRoutes(questionHandler, markingHandler, adaptiveHandler, importHandler, reviewHandler, similarityHandler, rdb, queries))
+	r.Mount("/api/admin/attempts", AdminAttemptRoutes(gradingHandler, proctoringHandler, rdb, queries))
+	r.Mount("/api/admin/users", AdminUserRoutes(userHandler, rdb, queries))
+	r.Mount("/api/admin/marking", MarkingRoutes(markingHandler, rdb, queries))
+	r.Mount("/api/admin/groups", AdminGroupRoutes(schedulingHandler, rdb, queries))
+	r.Mount("/api/admin/vouchers", AdminVoucherRoutes(voucherHandler, rdb, queries))
+	r.Mount("/api/admin/commissions", AdminCommissionRoutes(commissionHandler, rdb, queries))
+	r.Mount("/api/admin/payments", AdminPaymentRoutes(paymentHandler, rdb, queries))
+	r.Mount("/api/admin/plans", AdminPlanRoutes(subscriptionHandler, rdb, queries))
+	r.Mount("/api/admin/practice", AdminPracticeRoutes(practiceHandler, rdb, queries))
+	r.Mount("/api/admin/past-papers", AdminPastPaperRoutes(pastPaperHandler, rdb, queries))
 
 	return r
 }
 
-func (app *Application) run(h http.Handler) error {
+func (app *Application) run(ctx context.Context, h http.Handler) error {
 	server := &http.Server{
 		Addr:         app.config.add,
 		Handler:      h,
@@ -77,9 +198,20 @@ func (app *Application) run(h http.Handler) error {
 		IdleTimeout:  time.Minute,
 	}
 
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
 	log.Printf("server has started at add: %v", server.Addr)
 
-	return server.ListenAndServe()
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
 }
 
 func AuthRoutes(handler *users.Handler, rdb *store.Redis) http.Handler {
@@ -117,3 +249,417 @@ func AuthRoutes(handler *users.Handler, rdb *store.Redis) http.Handler {
 
 	return r
 }
+
+func ExamRoutes(handler *exams.Handler, attemptHandler *attempts.Handler, combinationHandler *combinations.Handler, rdb *store.Redis) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(rdb))
+	r.Get("/", handler.ListPublishedExams)
+	r.Get("/{examID}", handler.GetExam)
+	r.Get("/{examID}/subjects", combinationHandler.GetConfig)
+	r.Post("/attempts", attemptHandler.StartAttempt)
+
+	return r
+}
+
+func AttemptRoutes(handler *attempts.Handler, adaptiveHandler *adaptive.Handler, proctoringHandler *proctoring.Handler, rdb *store.Redis) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(rdb))
+	r.Get("/{attemptID}/paper", handler.GetPaper)
+	r.Get("/{attemptID}/answers", handler.ListAnswers)
+	r.Put("/{attemptID}/answers", handler.SaveAnswer)
+	r.Post("/{attemptID}/submit", handler.SubmitAttempt)
+
+	r.Get("/{attemptID}/adaptive", adaptiveHandler.Next)
+	r.Post("/{attemptID}/adaptive/answers", adaptiveHandler.Answer)
+
+	r.Post("/{attemptID}/proctoring/events", proctoringHandler.Record)
+
+	return r
+}
+
+func ResultRoutes(handler *results.Handler, rdb *store.Redis) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(rdb))
+	r.Get("/", handler.ListResults)
+	r.Get("/{attemptID}", handler.GetResult)
+	r.Get("/{attemptID}/review", handler.ReviewAnswers)
+
+	return r
+}
+
+func AdminExamRoutes(handler *exams.Handler, questionHandler *questions.Handler, gradingHandler *grading.Handler, resultHandler *results.Handler, schedulingHandler *scheduling.Handler, combinationHandler *combinations.Handler, adaptiveHandler *adaptive.Handler, analysisHandler *analysis.Handler, importHandler *importer.Handler, proctoringHandler *proctoring.Handler, rdb *store.Redis, q *repo.Queries) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(rdb))
+	r.Use(middlewares.RequireRole(q, repo.UserRoleADMIN))
+	r.Get("/", handler.ListExams)
+	r.Post("/", handler.CreateExam)
+	r.Get("/{examID}", handler.GetExam)
+	r.Put("/{examID}/status", handler.UpdateExamStatus)
+
+	r.Get("/{examID}/questions", questionHandler.ListExamQuestions)
+	r.Post("/{examID}/questions", questionHandler.AddExamQuestion)
+
+	r.Get("/{examID}/subjects", combinationHandler.GetConfig)
+	r.Put("/{examID}/subjects", combinationHandler.SaveSubjects)
+	r.Post("/{examID}/combinations", combinationHandler.AddCombination)
+	r.Delete("/{examID}/combinations/{combinationID}", combinationHandler.RemoveCombination)
+
+	r.Get("/{examID}/adaptive", adaptiveHandler.GetSettings)
+	r.Put("/{examID}/adaptive", adaptiveHandler.UpsertSettings)
+	r.Delete("/{examID}/adaptive", adaptiveHandler.DeleteSettings)
+
+	r.Get("/{examID}/proctoring", proctoringHandler.GetRules)
+	r.Put("/{examID}/proctoring", proctoringHandler.SaveRules)
+	r.Get("/{examID}/proctoring/flags", proctoringHandler.ListFlags)
+
+	r.Get("/{examID}/grading-policy", gradingHandler.GetPolicy)
+	r.Put("/{examID}/grading-policy", gradingHandler.UpsertPolicy)
+	r.Post("/{examID}/regrade", gradingHandler.RegradeExam)
+
+	r.Get("/{examID}/result-settings", resultHandler.GetSettings)
+	r.Put("/{examID}/result-settings", resultHandler.UpsertSettings)
+	r.Post("/{examID}/results/publish", resultHandler.PublishResults)
+
+	r.Get("/{examID}/item-analysis", analysisHandler.ListRuns)
+	r.Post("/{examID}/item-analysis", analysisHandler.RequestAnalysis)
+	r.Get("/{examID}/item-analysis/{runID}", analysisHandler.GetReport)
+	r.Get("/{examID}/item-analysis/{runID}/export", analysisHandler.ExportReport)
+
+	r.Get("/{examID}/export/qti", importHandler.ExportExam)
+
+	r.Get("/{examID}/schedule", schedulingHandler.GetSchedule)
+	r.Put("/{examID}/schedule", schedulingHandler.UpsertSchedule)
+	r.Get("/{examID}/eligibility", schedulingHandler.GetEligibility)
+	r.Put("/{examID}/eligibility", schedulingHandler.UpsertEligibility)
+	r.Get("/{examID}/assignments", schedulingHandler.ListAssignments)
+	r.Post("/{examID}/assignments", schedulingHandler.AssignExam)
+	r.Delete("/{examID}/assignments/{assignmentID}", schedulingHandler.RemoveAssignment)
+
+	return r
+}
+
+func AdminQuestionRoutes(handler *questions.Handler, markingHandler *marking.Handler, adaptiveHandler *adaptive.Handler, importHandler *importer.Handler, reviewHandler *reviews.Handler, similarityHandler *similarity.Handler, rdb *store.Redis, q *repo.Queries) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(rdb))
+	r.Use(middlewares.RequireRole(q, repo.UserRoleADMIN))
+	r.Get("/", handler.ListQuestions)
+	r.Post("/", handler.CreateQuestion)
+	r.Get("/search", handler.Search)
+	r.Post("/import/preview", importHandler.Preview)
+	r.Post("/import", importHandler.Import)
+	r.Get("/import/template", importHandler.Template)
+	r.Post("/import/spreadsheet/preview", importHandler.PreviewSheet)
+	r.Post("/import/spreadsheet", importHandler.ImportSheet)
+	r.Post("/import/qti/preview", importHandler.PreviewPackage)
+	r.Post("/import/qti", importHandler.ImportPackage)
+	r.Get("/export/qti", importHandler.ExportQuestions)
+	r.Get("/duplicates", similarityHandler.ListFlags)
+	r.Post("/duplicates/scan", similarityHandler.Scan)
+	r.Post("/duplicates/{flagID}/merge", similarityHandler.Merge)
+	r.Post("/duplicates/{flagID}/dismiss", similarityHandler.Dismiss)
+	r.Get("/{questionID}", handler.GetQuestion)
+	r.Put("/{questionID}", handler.UpdateQuestion)
+	r.Put("/{questionID}/answer-key", handler.UpdateAnswerKey)
+	r.Get("/{questionID}/versions", handler.ListVersions)
+	r.Get("/{questionID}/versions/diff", handler.DiffVersions)
+	r.Get("/{questionID}/versions/{version}/attempts", handler.ListVersionAttempts)
+	r.Post("/{questionID}/rollback", handler.Rollback)
+	r.Get("/{questionID}/usage", handler.GetUsage)
+
+	r.Get("/{questionID}/rubric", markingHandler.GetRubric)
+	r.Put("/{questionID}/rubric", markingHandler.SaveRubric)
+
+	r.Put("/{questionID}/irt", adaptiveHandler.SetItemParameters)
+
+	r.Get("/{questionID}/review", reviewHandler.GetReview)
+	r.Post("/{questionID}/review/submit", reviewHandler.Submit)
+	r.Post("/{questionID}/review/reviewers", reviewHandler.AssignReviewer)
+	r.Delete("/{questionID}/review/reviewers/{reviewerID}", reviewHandler.RemoveReviewer)
+	r.Post("/{questionID}/review/comments", reviewHandler.AddComment)
+	r.Post("/{questionID}/retire", reviewHandler.Retire)
+
+	r.Group(func(reviewer chi.Router) {
+		reviewer.Use(middlewares.RequirePermission(q, repo.AdminPermissionReviewer))
+		reviewer.Get("/review/queue", reviewHandler.ListQueue)
+		reviewer.Post("/{questionID}/review/decision", reviewHandler.Decide)
+	})
+
+	return r
+}
+
+func AdminAttemptRoutes(gradingHandler *grading.Handler, proctoringHandler *proctoring.Handler, rdb *store.Redis, q *repo.Queries) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(rdb))
+	r.Use(middlewares.RequireRole(q, repo.UserRoleADMIN))
+	r.Get("/{attemptID}/scores", gradingHandler.GetAttemptScores)
+	r.Get("/{attemptID}/proctoring", proctoringHandler.Timeline)
+
+	return r
+}
+
+func AdminUserRoutes(handler *users.Handler, rdb *store.Redis, q *repo.Queries) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(rdb))
+	r.Use(middlewares.RequireRole(q, repo.UserRoleADMIN))
+	r.Get("/{userID}/permissions", handler.ListPermissions)
+
+	r.Group(func(super chi.Router) {
+		super.Use(middlewares.RequirePermission(q, repo.AdminPermissionSuperAdmin))
+		super.Post("/{userID}/permissions", handler.GrantPermission)
+		super.Delete("/{userID}/permissions/{permission}", handler.RevokePermission)
+	})
+	r.Put("/{userID}/role", handler.UpdateRole)
+
+	return r
+}
+
+func MarkingRoutes(handler *marking.Handler, rdb *store.Redis, q *repo.Queries) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(rdb))
+	r.Use(middlewares.RequireRole(q, repo.UserRoleADMIN))
+
+	r.Group(func(grader chi.Router) {
+		grader.Use(middlewares.RequirePermission(q, repo.AdminPermissionGrader))
+		grader.Get("/exams/{examID}/queue", handler.ListQueue)
+		grader.Post("/responses/{candidateCode}/{questionID}/marks", handler.SubmitMark)
+	})
+
+	r.Group(func(moderator chi.Router) {
+		moderator.Use(middlewares.RequirePermission(q, repo.AdminPermissionModerator))
+		moderator.Get("/exams/{examID}/moderation", handler.ListModeration)
+		moderator.Post("/responses/{candidateCode}/{questionID}/moderate", handler.Moderate)
+	})
+
+	return r
+}
+
+func AdminGroupRoutes(handler *scheduling.Handler, rdb *store.Redis, q *repo.Queries) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(rdb))
+	r.Use(middlewares.RequireRole(q, repo.UserRoleADMIN))
+	r.Get("/", handler.ListGroups)
+	r.Post("/", handler.CreateGroup)
+	r.Get("/{groupID}/members", handler.ListGroupMembers)
+	r.Post("/{groupID}/members", handler.AddGroupMembers)
+	r.Delete("/{groupID}/members/{userID}", handler.RemoveGroupMember)
+
+	return r
+}
+
+func VoucherRoutes(handler *vouchers.Handler, rdb *store.Redis) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(rdb))
+	r.Post("/redeem", handler.Redeem)
+	r.Get("/access", handler.ListAccess)
+
+	return r
+}
+
+func PaymentRoutes(handler *payments.Handler, rdb *store.Redis) http.Handler {
+	r := chi.NewRouter()
+
+	// Signed by the provider instead of authenticated.
+	r.Post("/webhook", handler.Webhook)
+
+	r.Group(func(protected chi.Router) {
+		protected.Use(middlewares.AuthMiddleware(rdb))
+		protected.Get("/prices", handler.ListPrices)
+		protected.Post("/orders", handler.Checkout)
+		protected.Get("/orders", handler.ListOrders)
+		protected.Get("/orders/{reference}", handler.GetOrder)
+		protected.Post("/orders/{reference}/verify", handler.VerifyOrder)
+	})
+
+	return r
+}
+
+func PlanRoutes(handler *subscriptions.Handler, rdb *store.Redis) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(rdb))
+	r.Get("/", handler.ListPlans)
+	r.Get("/{planID}", handler.GetPlan)
+
+	return r
+}
+
+func SubscriptionRoutes(handler *subscriptions.Handler, rdb *store.Redis) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(rdb))
+	r.Get("/", handler.ListSubscriptions)
+	r.Post("/{planID}/cancel", handler.Cancel)
+
+	return r
+}
+
+func EntitlementRoutes(handler *entitlements.Handler, rdb *store.Redis) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(rdb))
+	r.Get("/", handler.Summary)
+	r.Get("/{targetType}/{targetID}", handler.Check)
+
+	return r
+}
+
+// PracticeRoutes lets anyone browse packs, but starting a session needs the
+// pack bought or included in a plan.
+func PracticeRoutes(handler *practice.Handler, e middlewares.Entitlements, rdb *store.Redis) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(rdb))
+	r.Get("/packs", handler.ListPacks)
+	r.Get("/packs/{packID}", handler.GetPack)
+	r.With(middlewares.RequireEntitlement(e, repo.AccessTargetPracticePack, "packID")).
+		Post("/packs/{packID}/sessions", handler.StartSession)
+	r.Get("/sessions", handler.ListSessions)
+	r.Get("/sessions/{sessionID}", handler.GetSession)
+	r.Post("/sessions/{sessionID}/answers", handler.Answer)
+	r.Get("/streak", handler.Streak)
+
+	return r
+}
+
+func PastPaperRoutes(handler *pastpapers.Handler, rdb *store.Redis) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(rdb))
+	r.Get("/", handler.ListPapers)
+
+	return r
+}
+
+// AdminPastPaperRoutes is where admins put a past paper up as a mock exam.
+// Candidates sit it through the usual exam routes once entitled to it.
+func AdminPastPaperRoutes(handler *pastpapers.Handler, rdb *store.Redis, q *repo.Queries) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(rdb))
+	r.Use(middlewares.RequireRole(q, repo.UserRoleADMIN))
+	r.Post("/mock", handler.LaunchMock)
+
+	return r
+}
+
+func MediaRoutes(handler *media.Handler, rdb *store.Redis, q *repo.Queries) http.Handler {
+	r := chi.NewRouter()
+
+	// Signed into the paper of an active attempt instead of authenticated.
+	r.Get("/{mediaID}/signed", handler.GetSigned)
+
+	r.Group(func(admin chi.Router) {
+		admin.Use(middlewares.AuthMiddleware(rdb))
+		admin.Use(middlewares.RequireRole(q, repo.UserRoleADMIN))
+		admin.Post("/", handler.Upload)
+		admin.Get("/{mediaID}", handler.GetFile)
+	})
+
+	return r
+}
+
+func ContentRoutes(handler *richtext.Handler, rdb *store.Redis) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(rdb))
+	r.Post("/render", handler.Render)
+
+	return r
+}
+
+func AgentRoutes(voucherHandler *vouchers.Handler, commissionHandler *commissions.Handler, rdb *store.Redis, q *repo.Queries) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(rdb))
+	r.Use(middlewares.RequireRole(q, repo.UserRoleAGENT))
+	r.Get("/vouchers/stock", voucherHandler.AgentStock)
+	r.Get("/vouchers/sales", voucherHandler.AgentSales)
+	r.Get("/statement", commissionHandler.MyStatement)
+
+	return r
+}
+
+func AdminVoucherRoutes(handler *vouchers.Handler, rdb *store.Redis, q *repo.Queries) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(rdb))
+	r.Use(middlewares.RequireRole(q, repo.UserRoleADMIN))
+	r.Get("/batches", handler.ListBatches)
+	r.Post("/batches", handler.GenerateBatch)
+	r.Get("/batches/{batchID}", handler.GetBatch)
+	r.Post("/batches/{batchID}/allocate", handler.AllocateBatch)
+	r.Post("/{serial}/void", handler.VoidVoucher)
+
+	return r
+}
+
+func AdminCommissionRoutes(handler *commissions.Handler, rdb *store.Redis, q *repo.Queries) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(rdb))
+	r.Use(middlewares.RequireRole(q, repo.UserRoleADMIN))
+	r.Get("/rules", handler.ListRules)
+	r.Put("/rules", handler.UpsertRule)
+	r.Delete("/rules/{ruleID}", handler.DeleteRule)
+
+	r.Get("/agents/{agentID}/statement", handler.AgentStatement)
+	r.Post("/agents/{agentID}/adjustments", handler.Adjust)
+	r.Post("/agents/{agentID}/payouts", handler.CreatePayout)
+
+	r.Get("/payouts", handler.ListPayouts)
+	r.Post("/payouts/{payoutID}/complete", handler.CompletePayout)
+	r.Post("/payouts/{payoutID}/cancel", handler.CancelPayout)
+
+	return r
+}
+
+func AdminPaymentRoutes(handler *payments.Handler, rdb *store.Redis, q *repo.Queries) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(rdb))
+	r.Use(middlewares.RequireRole(q, repo.UserRoleADMIN))
+	r.Get("/prices", handler.ListPrices)
+	r.Put("/prices", handler.SetPrice)
+	r.Get("/orders", handler.ListAllOrders)
+	r.Post("/orders/{orderID}/refund", handler.Refund)
+
+	return r
+}
+
+func AdminPlanRoutes(handler *subscriptions.Handler, rdb *store.Redis, q *repo.Queries) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(rdb))
+	r.Use(middlewares.RequireRole(q, repo.UserRoleADMIN))
+	r.Get("/", handler.ListAllPlans)
+	r.Post("/", handler.CreatePlan)
+	r.Get("/{planID}", handler.GetPlan)
+	r.Put("/{planID}", handler.UpdatePlan)
+	r.Post("/{planID}/items", handler.AddPlanItem)
+	r.Delete("/{planID}/items/{targetType}/{targetID}", handler.RemovePlanItem)
+	r.Post("/subscriptions", handler.Grant)
+
+	return r
+}
+
+func AdminPracticeRoutes(handler *practice.Handler, rdb *store.Redis, q *repo.Queries) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middlewares.AuthMiddleware(rdb))
+	r.Use(middlewares.RequireRole(q, repo.UserRoleADMIN))
+	r.Get("/packs", handler.ListPacks)
+	r.Post("/packs", handler.CreatePack)
+	r.Get("/packs/{packID}", handler.GetPack)
+
+	return r
+}
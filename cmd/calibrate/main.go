@@ -0,0 +1,119 @@
+// Command calibrate estimates IRT item parameters from graded responses.
+//
+//	go run ./cmd/calibrate -model 3pl -exam 12 -min-responses 200
+//
+// Every run is kept as a calibration. Items that converge replace the
+// question's parameters; the rest are listed at the end for review.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/calibration"
+	"github.com/odundlaw/cbt-backend/internal/config"
+	"github.com/odundlaw/cbt-backend/internal/irt"
+)
+
+func main() {
+	model := flag.String("model", "2pl", "model to fit: 1pl, 2pl or 3pl")
+	examID := flag.Int64("exam", 0, "only use attempts at this exam (default: the whole bank)")
+	minResponses := flag.Int("min-responses", 100, "skip questions with fewer graded responses")
+	maxCycles := flag.Int("max-cycles", 500, "EM cycles before giving up")
+	tolerance := flag.Float64("tolerance", 1e-4, "largest parameter change that counts as converged")
+	dryRun := flag.Bool("dry-run", false, "report estimates without writing them")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	pool, err := pgxpool.New(ctx, config.DatabaseURL)
+	if err != nil {
+		logger.Error("failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	defer pool.Close()
+
+	calibrator := calibration.NewCalibrator(repo.New(pool), pool, logger)
+
+	report, err := calibrator.Run(ctx, calibration.Options{
+		Model:        irt.Model(*model),
+		ExamID:       *examID,
+		MinResponses: *minResponses,
+		MaxCycles:    *maxCycles,
+		Tolerance:    *tolerance,
+		DryRun:       *dryRun,
+	})
+	if errors.Is(err, calibration.ErrNoCalibrationData) {
+		logger.Warn(err.Error(), "skipped", len(report.Skipped))
+		os.Exit(1)
+	}
+	if err != nil {
+		logger.Error("calibration failed", "error", err)
+		os.Exit(1)
+	}
+
+	printReport(report)
+
+	if !report.Converged {
+		os.Exit(2)
+	}
+}
+
+func printReport(report calibration.Report) {
+	if report.CalibrationID != 0 {
+		fmt.Printf("calibration %d\n", report.CalibrationID)
+	} else {
+		fmt.Println("dry run, nothing written")
+	}
+	fmt.Printf("model %s, %d respondents, %d items, %d cycles, converged %t\n",
+		report.Model, report.Respondents, len(report.Items), report.Cycles, report.Converged)
+	fmt.Printf("-2LL %.2f, AIC %.2f, BIC %.2f\n\n", -2*report.LogLikelihood, report.AIC, report.BIC)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "question\tn\ta\tb\tc\tse(a)\tse(b)\tse(c)\trmsd\tapplied")
+
+	var failed []calibration.ItemResult
+	for _, item := range report.Items {
+		fit := item.Fit
+		fmt.Fprintf(tw, "%d\t%d\t%.3f\t%.3f\t%.3f\t%.3f\t%.3f\t%.3f\t%.3f\t%t\n",
+			item.QuestionID, fit.Responses, fit.Item.A, fit.Item.B, fit.Item.C,
+			fit.SE.A, fit.SE.B, fit.SE.C, fit.RMSD, item.Applied)
+		if !fit.Converged {
+			failed = append(failed, item)
+		}
+	}
+	tw.Flush()
+
+	if len(failed) > 0 {
+		fmt.Printf("\n%d items did not converge:\n", len(failed))
+		for _, item := range failed {
+			fmt.Printf("  question %d: %s\n", item.QuestionID, item.Fit.Reason)
+		}
+	}
+
+	if len(report.Skipped) > 0 {
+		ids := make([]int64, 0, len(report.Skipped))
+		for id := range report.Skipped {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+		fmt.Printf("\n%d questions skipped for too few responses:\n", len(ids))
+		for _, id := range ids {
+			fmt.Printf("  question %d: %d responses\n", id, report.Skipped[id])
+		}
+	}
+}
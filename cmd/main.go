@@ -4,13 +4,27 @@ import (
 	"context"
 	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/analysis"
+	"github.com/odundlaw/cbt-backend/internal/attempts"
 	"github.com/odundlaw/cbt-backend/internal/config"
+	"github.com/odundlaw/cbt-backend/internal/payments"
+	"github.com/odundlaw/cbt-backend/internal/storage"
+	"github.com/odundlaw/cbt-backend/internal/store"
 )
 
 func main() {
-	ctx := context.Background()
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	cfg := Config{
 		add: ":8080",
@@ -25,20 +39,65 @@ func main() {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	slog.Default()
 
-	conn, err := pgx.Connect(ctx, cfg.db.dsn)
+	// Requests, the autosave flusher and the analysis worker all run
+	// concurrently, so each statement or transaction takes its own
+	// connection from the pool.
+	pool, err := pgxpool.New(ctx, cfg.db.dsn)
 	if err != nil {
 		panic(err)
 	}
-	defer conn.Close(ctx)
+	defer pool.Close()
 
 	logger.Info("connected to database", "dsn", cfg.db.dsn)
 
+	rdb := store.NewRedis(cfg.redis.addr)
+
+	blob, err := storage.New()
+	if err != nil {
+		panic(err)
+	}
+
+	payment, err := payments.New()
+	if err != nil {
+		panic(err)
+	}
+
+	flusher := attempts.NewFlusher(
+		repo.New(pool),
+		rdb,
+		time.Duration(config.AutosaveFlushSeconds)*time.Second,
+		int64(config.AutosaveBatchSize),
+		logger,
+	)
+	flushed := make(chan struct{})
+	go func() {
+		flusher.Run(ctx)
+		close(flushed)
+	}()
+
+	worker := analysis.NewWorker(
+		repo.New(pool),
+		pool,
+		time.Duration(config.AnalysisPollSeconds)*time.Second,
+		logger,
+	)
+	go worker.Run(ctx)
+
 	api := Application{
 		config: cfg,
-		conn:   conn,
+		db:     pool,
+		rdb:    rdb,
+		blob:   blob,
+		pay:    payment,
 	}
 
-	if err := api.run(api.mount()); err != nil {
+	err = api.run(ctx, api.mount())
+
+	// Let the flusher drain whatever is still dirty before exiting.
+	stop()
+	<-flushed
+
+	if err != nil {
 		slog.Error("server has failed to start", "error", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,55 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE exam_status AS ENUM ('draft', 'published', 'archived');
+CREATE TYPE attempt_status AS ENUM ('in_progress', 'submitted');
+
+CREATE TABLE IF NOT EXISTS exams (
+  id BIGSERIAL PRIMARY KEY,
+  title TEXT NOT NULL,
+  description TEXT,
+  duration_minutes INT NOT NULL CHECK (duration_minutes > 0),
+  status exam_status NOT NULL DEFAULT 'draft',
+  created_by BIGINT NOT NULL REFERENCES users(id),
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS exam_attempts (
+  id BIGSERIAL PRIMARY KEY,
+  exam_id BIGINT NOT NULL REFERENCES exams(id),
+  user_id BIGINT NOT NULL REFERENCES users(id),
+  status attempt_status NOT NULL DEFAULT 'in_progress',
+  started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  expires_at TIMESTAMPTZ NOT NULL,
+  submitted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS exam_attempts_exam_user_idx ON exam_attempts (exam_id, user_id);
+
+-- Only one attempt per candidate per exam may be open at a time.
+CREATE UNIQUE INDEX IF NOT EXISTS exam_attempts_one_open_idx
+  ON exam_attempts (exam_id, user_id)
+  WHERE status = 'in_progress';
+
+-- Answers are written by the autosave flusher from Redis. version is the
+-- per-question counter handed to the client, so a stale flush never
+-- overwrites a newer answer.
+CREATE TABLE IF NOT EXISTS attempt_answers (
+  attempt_id BIGINT NOT NULL REFERENCES exam_attempts(id) ON DELETE CASCADE,
+  question_id BIGINT NOT NULL,
+  answer JSONB NOT NULL,
+  version BIGINT NOT NULL,
+  saved_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (attempt_id, question_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS attempt_answers;
+DROP TABLE IF EXISTS exam_attempts;
+DROP TABLE IF EXISTS exams;
+DROP TYPE IF EXISTS attempt_status;
+DROP TYPE IF EXISTS exam_status;
+-- +goose StatementEnd
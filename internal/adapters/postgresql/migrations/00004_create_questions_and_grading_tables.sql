@@ -0,0 +1,107 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE question_type AS ENUM (
+  'single_choice',
+  'multiple_choice',
+  'true_false',
+  'numeric',
+  'matching',
+  'short_answer',
+  'essay'
+);
+
+CREATE TABLE IF NOT EXISTS questions (
+  id BIGSERIAL PRIMARY KEY,
+  type question_type NOT NULL,
+  stem TEXT NOT NULL,
+  options JSONB NOT NULL DEFAULT '[]',
+  answer_key JSONB NOT NULL,
+  explanation TEXT,
+  marks DOUBLE PRECISION NOT NULL DEFAULT 1 CHECK (marks > 0),
+  created_by BIGINT NOT NULL REFERENCES users(id),
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS exam_questions (
+  exam_id BIGINT NOT NULL REFERENCES exams(id) ON DELETE CASCADE,
+  question_id BIGINT NOT NULL REFERENCES questions(id),
+  section TEXT NOT NULL DEFAULT 'general',
+  position INT NOT NULL DEFAULT 0,
+  -- Overrides questions.marks for this exam when set.
+  marks DOUBLE PRECISION CHECK (marks > 0),
+  PRIMARY KEY (exam_id, question_id)
+);
+
+CREATE INDEX IF NOT EXISTS exam_questions_question_idx ON exam_questions (question_id);
+
+ALTER TABLE attempt_answers
+ADD CONSTRAINT attempt_answers_question_fk
+FOREIGN KEY (question_id) REFERENCES questions(id);
+
+CREATE TABLE IF NOT EXISTS exam_grading_policies (
+  exam_id BIGINT PRIMARY KEY REFERENCES exams(id) ON DELETE CASCADE,
+  -- Fraction of a question's marks deducted for a wrong answer, e.g. 0.25.
+  negative_marking DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (negative_marking >= 0 AND negative_marking <= 1),
+  partial_credit BOOLEAN NOT NULL DEFAULT false,
+  -- {"section name": weight}; sections not listed weigh 1.
+  section_weights JSONB NOT NULL DEFAULT '{}',
+  max_score DOUBLE PRECISION CHECK (max_score > 0),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TYPE score_outcome AS ENUM ('correct', 'partial', 'incorrect', 'unanswered', 'pending');
+CREATE TYPE result_status AS ENUM ('pending_manual', 'graded');
+
+CREATE TABLE IF NOT EXISTS attempt_question_scores (
+  attempt_id BIGINT NOT NULL REFERENCES exam_attempts(id) ON DELETE CASCADE,
+  question_id BIGINT NOT NULL REFERENCES questions(id),
+  score DOUBLE PRECISION NOT NULL,
+  max_score DOUBLE PRECISION NOT NULL,
+  outcome score_outcome NOT NULL,
+  graded_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (attempt_id, question_id)
+);
+
+CREATE TABLE IF NOT EXISTS attempt_results (
+  attempt_id BIGINT PRIMARY KEY REFERENCES exam_attempts(id) ON DELETE CASCADE,
+  exam_id BIGINT NOT NULL REFERENCES exams(id),
+  user_id BIGINT NOT NULL REFERENCES users(id),
+  total_score DOUBLE PRECISION NOT NULL,
+  max_score DOUBLE PRECISION NOT NULL,
+  status result_status NOT NULL,
+  graded_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS attempt_results_exam_idx ON attempt_results (exam_id);
+
+-- Every change to a graded score is kept. question_id is NULL for changes
+-- to the attempt total.
+CREATE TABLE IF NOT EXISTS score_changes (
+  id BIGSERIAL PRIMARY KEY,
+  attempt_id BIGINT NOT NULL REFERENCES exam_attempts(id) ON DELETE CASCADE,
+  question_id BIGINT REFERENCES questions(id),
+  old_score DOUBLE PRECISION NOT NULL,
+  new_score DOUBLE PRECISION NOT NULL,
+  reason TEXT NOT NULL,
+  changed_by BIGINT REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS score_changes_attempt_idx ON score_changes (attempt_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS score_changes;
+DROP TABLE IF EXISTS attempt_results;
+DROP TABLE IF EXISTS attempt_question_scores;
+DROP TYPE IF EXISTS result_status;
+DROP TYPE IF EXISTS score_outcome;
+DROP TABLE IF EXISTS exam_grading_policies;
+ALTER TABLE attempt_answers DROP CONSTRAINT IF EXISTS attempt_answers_question_fk;
+DROP TABLE IF EXISTS exam_questions;
+DROP TABLE IF EXISTS questions;
+DROP TYPE IF EXISTS question_type;
+-- +goose StatementEnd
@@ -0,0 +1,68 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE admin_permission AS ENUM ('grader', 'moderator');
+
+CREATE TABLE IF NOT EXISTS user_permissions (
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  permission admin_permission NOT NULL,
+  granted_by BIGINT NOT NULL REFERENCES users(id),
+  granted_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (user_id, permission)
+);
+
+ALTER TABLE exam_grading_policies
+ADD COLUMN double_marking BOOLEAN NOT NULL DEFAULT false,
+-- Largest gap in marks between two graders that is settled by averaging.
+-- Anything wider goes to a moderator.
+ADD COLUMN moderation_threshold DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (moderation_threshold >= 0);
+
+CREATE TABLE IF NOT EXISTS rubric_criteria (
+  id BIGSERIAL PRIMARY KEY,
+  question_id BIGINT NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  description TEXT,
+  max_points DOUBLE PRECISION NOT NULL CHECK (max_points > 0),
+  position INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS rubric_criteria_question_idx ON rubric_criteria (question_id);
+
+CREATE TABLE IF NOT EXISTS manual_marks (
+  id BIGSERIAL PRIMARY KEY,
+  attempt_id BIGINT NOT NULL REFERENCES exam_attempts(id) ON DELETE CASCADE,
+  question_id BIGINT NOT NULL REFERENCES questions(id),
+  grader_id BIGINT NOT NULL REFERENCES users(id),
+  -- {"<criterion id>": points}
+  criteria_scores JSONB NOT NULL DEFAULT '{}',
+  score DOUBLE PRECISION NOT NULL CHECK (score >= 0),
+  comment TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (attempt_id, question_id, grader_id)
+);
+
+CREATE TYPE manual_review_status AS ENUM ('awaiting_marks', 'needs_moderation', 'finalized');
+
+CREATE TABLE IF NOT EXISTS manual_reviews (
+  attempt_id BIGINT NOT NULL REFERENCES exam_attempts(id) ON DELETE CASCADE,
+  question_id BIGINT NOT NULL REFERENCES questions(id),
+  status manual_review_status NOT NULL DEFAULT 'awaiting_marks',
+  final_score DOUBLE PRECISION,
+  moderator_comment TEXT,
+  finalized_by BIGINT REFERENCES users(id),
+  finalized_at TIMESTAMPTZ,
+  PRIMARY KEY (attempt_id, question_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS manual_reviews;
+DROP TYPE IF EXISTS manual_review_status;
+DROP TABLE IF EXISTS manual_marks;
+DROP TABLE IF EXISTS rubric_criteria;
+ALTER TABLE exam_grading_policies
+DROP COLUMN IF EXISTS moderation_threshold,
+DROP COLUMN IF EXISTS double_marking;
+DROP TABLE IF EXISTS user_permissions;
+DROP TYPE IF EXISTS admin_permission;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE result_release_mode AS ENUM ('immediate', 'scheduled', 'manual');
+
+CREATE TABLE IF NOT EXISTS exam_result_settings (
+  exam_id BIGINT PRIMARY KEY REFERENCES exams(id) ON DELETE CASCADE,
+  release_mode result_release_mode NOT NULL DEFAULT 'immediate',
+  -- Only used by scheduled release.
+  release_at TIMESTAMPTZ,
+  -- Set when an admin publishes results; only used by manual release.
+  released_at TIMESTAMPTZ,
+  -- Percentage of the maximum score needed to pass. NULL means the exam has
+  -- no pass mark.
+  pass_mark DOUBLE PRECISION CHECK (pass_mark BETWEEN 0 AND 100),
+  allow_answer_review BOOLEAN NOT NULL DEFAULT false,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK (release_mode <> 'scheduled' OR release_at IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS attempt_results_exam_score_idx ON attempt_results (exam_id, total_score);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS attempt_results_exam_score_idx;
+DROP TABLE IF EXISTS exam_result_settings;
+DROP TYPE IF EXISTS result_release_mode;
+-- +goose StatementEnd
@@ -0,0 +1,70 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+ADD COLUMN email_verified_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS exam_schedules (
+  exam_id BIGINT PRIMARY KEY REFERENCES exams(id) ON DELETE CASCADE,
+  opens_at TIMESTAMPTZ,
+  closes_at TIMESTAMPTZ,
+  -- IANA zone the window was set in, used to show it back to people.
+  time_zone TEXT NOT NULL DEFAULT 'UTC',
+  -- NULL means unlimited attempts.
+  max_attempts INT CHECK (max_attempts > 0),
+  retake_cooldown_minutes INT NOT NULL DEFAULT 0 CHECK (retake_cooldown_minutes >= 0),
+  -- Only assigned candidates may sit the exam.
+  assigned_only BOOLEAN NOT NULL DEFAULT false,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK (opens_at IS NULL OR closes_at IS NULL OR opens_at < closes_at)
+);
+
+CREATE TABLE IF NOT EXISTS exam_eligibility_rules (
+  exam_id BIGINT PRIMARY KEY REFERENCES exams(id) ON DELETE CASCADE,
+  -- Empty means any school/state.
+  schools TEXT[] NOT NULL DEFAULT '{}',
+  states TEXT[] NOT NULL DEFAULT '{}',
+  require_profile_completed BOOLEAN NOT NULL DEFAULT false,
+  require_email_verified BOOLEAN NOT NULL DEFAULT false,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS candidate_groups (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL UNIQUE,
+  description TEXT,
+  created_by BIGINT NOT NULL REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS candidate_group_members (
+  group_id BIGINT NOT NULL REFERENCES candidate_groups(id) ON DELETE CASCADE,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  added_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS candidate_group_members_user_idx ON candidate_group_members (user_id);
+
+CREATE TABLE IF NOT EXISTS exam_assignments (
+  id BIGSERIAL PRIMARY KEY,
+  exam_id BIGINT NOT NULL REFERENCES exams(id) ON DELETE CASCADE,
+  user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+  group_id BIGINT REFERENCES candidate_groups(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK ((user_id IS NULL) <> (group_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS exam_assignments_user_idx ON exam_assignments (exam_id, user_id) WHERE user_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS exam_assignments_group_idx ON exam_assignments (exam_id, group_id) WHERE group_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS exam_assignments;
+DROP TABLE IF EXISTS candidate_group_members;
+DROP TABLE IF EXISTS candidate_groups;
+DROP TABLE IF EXISTS exam_eligibility_rules;
+DROP TABLE IF EXISTS exam_schedules;
+ALTER TABLE users
+DROP COLUMN IF EXISTS email_verified_at;
+-- +goose StatementEnd
@@ -0,0 +1,91 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The original checks only allowed USER and ADMIN, so agents could not exist.
+ALTER TABLE users
+DROP CONSTRAINT IF EXISTS admin_code_required_for_admin,
+DROP CONSTRAINT IF EXISTS department_required_for_admin;
+
+ALTER TABLE users
+ADD CONSTRAINT admin_code_required_for_admin CHECK (role <> 'ADMIN' OR admin_code IS NOT NULL),
+ADD CONSTRAINT department_required_for_admin CHECK (role <> 'ADMIN' OR department IS NOT NULL);
+
+CREATE TYPE access_target AS ENUM ('exam', 'practice_pack');
+
+-- What a candidate has unlocked and how. target_id points at exams(id) or a
+-- practice pack depending on target_type, so it has no foreign key.
+CREATE TABLE IF NOT EXISTS access_grants (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  target_type access_target NOT NULL,
+  target_id BIGINT NOT NULL,
+  source TEXT NOT NULL,
+  source_id BIGINT,
+  expires_at TIMESTAMPTZ,
+  granted_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (user_id, target_type, target_id, source, source_id)
+);
+
+CREATE INDEX IF NOT EXISTS access_grants_lookup_idx ON access_grants (user_id, target_type, target_id);
+
+ALTER TABLE exam_schedules
+ADD COLUMN requires_access BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS voucher_batches (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  target_type access_target NOT NULL,
+  target_id BIGINT NOT NULL,
+  quantity INT NOT NULL CHECK (quantity > 0),
+  -- How many different candidates may redeem one PIN.
+  max_uses INT NOT NULL DEFAULT 1 CHECK (max_uses > 0),
+  -- Price of one PIN in the smallest currency unit.
+  unit_price BIGINT NOT NULL DEFAULT 0 CHECK (unit_price >= 0),
+  expires_at TIMESTAMPTZ,
+  created_by BIGINT NOT NULL REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TYPE voucher_status AS ENUM ('active', 'void');
+
+CREATE TABLE IF NOT EXISTS vouchers (
+  id BIGSERIAL PRIMARY KEY,
+  batch_id BIGINT NOT NULL REFERENCES voucher_batches(id) ON DELETE CASCADE,
+  serial TEXT NOT NULL UNIQUE,
+  -- HMAC of the PIN; the PIN itself is only shown once, when generated.
+  pin_hash TEXT NOT NULL,
+  agent_id BIGINT REFERENCES users(id),
+  allocated_at TIMESTAMPTZ,
+  uses INT NOT NULL DEFAULT 0,
+  status voucher_status NOT NULL DEFAULT 'active',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS vouchers_batch_idx ON vouchers (batch_id, id);
+CREATE INDEX IF NOT EXISTS vouchers_agent_idx ON vouchers (agent_id) WHERE agent_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS voucher_redemptions (
+  id BIGSERIAL PRIMARY KEY,
+  voucher_id BIGINT NOT NULL REFERENCES vouchers(id),
+  user_id BIGINT NOT NULL REFERENCES users(id),
+  redeemed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (voucher_id, user_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS voucher_redemptions;
+DROP TABLE IF EXISTS vouchers;
+DROP TYPE IF EXISTS voucher_status;
+DROP TABLE IF EXISTS voucher_batches;
+ALTER TABLE exam_schedules
+DROP COLUMN IF EXISTS requires_access;
+DROP TABLE IF EXISTS access_grants;
+DROP TYPE IF EXISTS access_target;
+ALTER TABLE users
+DROP CONSTRAINT IF EXISTS admin_code_required_for_admin,
+DROP CONSTRAINT IF EXISTS department_required_for_admin;
+ALTER TABLE users
+ADD CONSTRAINT admin_code_required_for_admin CHECK ((role = 'ADMIN' AND admin_code IS NOT NULL) OR (role = 'USER')),
+ADD CONSTRAINT department_required_for_admin CHECK ((role = 'ADMIN' AND department IS NOT NULL) OR (role = 'USER'));
+-- +goose StatementEnd
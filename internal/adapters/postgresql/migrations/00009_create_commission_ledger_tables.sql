@@ -0,0 +1,104 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+ADD COLUMN referral_code TEXT UNIQUE,
+ADD COLUMN referred_by BIGINT REFERENCES users(id);
+
+CREATE TYPE commission_product AS ENUM ('exam', 'practice_pack', 'registration');
+
+-- A rule with no product_id covers every product of that kind; a rule for a
+-- specific product wins over it.
+CREATE TABLE IF NOT EXISTS commission_rules (
+  id BIGSERIAL PRIMARY KEY,
+  product commission_product NOT NULL,
+  product_id BIGINT,
+  -- Share of the sale price in basis points (1/100 of a percent).
+  rate_bps INT NOT NULL DEFAULT 0 CHECK (rate_bps BETWEEN 0 AND 10000),
+  flat_amount BIGINT NOT NULL DEFAULT 0 CHECK (flat_amount >= 0),
+  created_by BIGINT NOT NULL REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS commission_rules_product_idx ON commission_rules (product, (COALESCE(product_id, 0)));
+
+CREATE TYPE ledger_account_type AS ENUM ('asset', 'liability', 'expense');
+
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+  id BIGSERIAL PRIMARY KEY,
+  code TEXT NOT NULL UNIQUE,
+  name TEXT NOT NULL,
+  type ledger_account_type NOT NULL,
+  -- Set on the payable account of each agent.
+  agent_id BIGINT UNIQUE REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO ledger_accounts (code, name, type)
+VALUES ('commission_expense', 'Agent commission expense', 'expense'),
+       ('cash', 'Cash', 'asset');
+
+CREATE TYPE ledger_kind AS ENUM ('earning', 'adjustment', 'payout');
+
+CREATE TABLE IF NOT EXISTS ledger_transactions (
+  id BIGSERIAL PRIMARY KEY,
+  idempotency_key TEXT NOT NULL UNIQUE,
+  kind ledger_kind NOT NULL,
+  agent_id BIGINT NOT NULL REFERENCES users(id),
+  description TEXT NOT NULL,
+  reference_type TEXT,
+  reference_id BIGINT,
+  created_by BIGINT REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TYPE payout_status AS ENUM ('pending', 'paid', 'cancelled');
+
+CREATE TABLE IF NOT EXISTS agent_payouts (
+  id BIGSERIAL PRIMARY KEY,
+  agent_id BIGINT NOT NULL REFERENCES users(id),
+  amount BIGINT NOT NULL CHECK (amount > 0),
+  status payout_status NOT NULL DEFAULT 'pending',
+  idempotency_key TEXT NOT NULL UNIQUE,
+  reference TEXT,
+  created_by BIGINT NOT NULL REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  paid_at TIMESTAMPTZ,
+  cancelled_at TIMESTAMPTZ
+);
+
+-- At most one payout per agent can be in flight.
+CREATE UNIQUE INDEX IF NOT EXISTS agent_payouts_pending_idx ON agent_payouts (agent_id) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+  id BIGSERIAL PRIMARY KEY,
+  transaction_id BIGINT NOT NULL REFERENCES ledger_transactions(id),
+  account_id BIGINT NOT NULL REFERENCES ledger_accounts(id),
+  debit BIGINT NOT NULL DEFAULT 0 CHECK (debit >= 0),
+  credit BIGINT NOT NULL DEFAULT 0 CHECK (credit >= 0),
+  -- The payout that settles this entry, once one has been raised.
+  payout_id BIGINT REFERENCES agent_payouts(id),
+  settled_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK ((debit > 0) <> (credit > 0))
+);
+
+CREATE INDEX IF NOT EXISTS ledger_entries_account_idx ON ledger_entries (account_id, id);
+CREATE INDEX IF NOT EXISTS ledger_entries_payout_idx ON ledger_entries (payout_id) WHERE payout_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS ledger_entries;
+DROP TABLE IF EXISTS agent_payouts;
+DROP TYPE IF EXISTS payout_status;
+DROP TABLE IF EXISTS ledger_transactions;
+DROP TYPE IF EXISTS ledger_kind;
+DROP TABLE IF EXISTS ledger_accounts;
+DROP TYPE IF EXISTS ledger_account_type;
+DROP TABLE IF EXISTS commission_rules;
+DROP TYPE IF EXISTS commission_product;
+ALTER TABLE users
+DROP COLUMN IF EXISTS referred_by,
+DROP COLUMN IF EXISTS referral_code;
+-- +goose StatementEnd
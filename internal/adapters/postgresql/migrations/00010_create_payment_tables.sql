@@ -0,0 +1,55 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Amounts are in minor currency units, like voucher prices.
+CREATE TABLE IF NOT EXISTS product_prices (
+  target_type access_target NOT NULL,
+  target_id BIGINT NOT NULL,
+  amount BIGINT NOT NULL CHECK (amount > 0),
+  currency TEXT NOT NULL,
+  updated_by BIGINT NOT NULL REFERENCES users(id),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (target_type, target_id)
+);
+
+CREATE TYPE order_status AS ENUM ('pending', 'paid', 'failed', 'cancelled', 'refunded');
+
+CREATE TABLE IF NOT EXISTS orders (
+  id BIGSERIAL PRIMARY KEY,
+  reference TEXT NOT NULL UNIQUE,
+  user_id BIGINT NOT NULL REFERENCES users(id),
+  target_type access_target NOT NULL,
+  target_id BIGINT NOT NULL,
+  amount BIGINT NOT NULL CHECK (amount > 0),
+  currency TEXT NOT NULL,
+  provider TEXT NOT NULL,
+  provider_reference TEXT,
+  authorization_url TEXT,
+  status order_status NOT NULL DEFAULT 'pending',
+  failure_reason TEXT,
+  paid_at TIMESTAMPTZ,
+  refunded_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS orders_user_idx ON orders (user_id, created_at DESC);
+
+-- Every webhook event accepted, so a replayed delivery is recognised and
+-- ignored.
+CREATE TABLE IF NOT EXISTS payment_webhook_events (
+  provider TEXT NOT NULL,
+  event_id TEXT NOT NULL,
+  event_type TEXT NOT NULL,
+  order_reference TEXT,
+  received_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (provider, event_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS payment_webhook_events;
+DROP TABLE IF EXISTS orders;
+DROP TYPE IF EXISTS order_status;
+DROP TABLE IF EXISTS product_prices;
+-- +goose StatementEnd
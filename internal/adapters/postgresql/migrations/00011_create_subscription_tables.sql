@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Plans are sold through orders like exams are.
+ALTER TYPE access_target ADD VALUE IF NOT EXISTS 'plan';
+
+CREATE TABLE IF NOT EXISTS plans (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  description TEXT,
+  period_days INT NOT NULL CHECK (period_days > 0),
+  -- Days after a period ends during which access continues while the
+  -- subscriber renews.
+  grace_days INT NOT NULL DEFAULT 0 CHECK (grace_days >= 0),
+  active BOOLEAN NOT NULL DEFAULT true,
+  created_by BIGINT NOT NULL REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- What a plan unlocks.
+CREATE TABLE IF NOT EXISTS plan_items (
+  plan_id BIGINT NOT NULL REFERENCES plans(id) ON DELETE CASCADE,
+  target_type access_target NOT NULL,
+  target_id BIGINT NOT NULL,
+  PRIMARY KEY (plan_id, target_type, target_id)
+);
+
+CREATE INDEX IF NOT EXISTS plan_items_target_idx ON plan_items (target_type, target_id);
+
+CREATE TABLE IF NOT EXISTS subscriptions (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id),
+  plan_id BIGINT NOT NULL REFERENCES plans(id),
+  started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  current_period_start TIMESTAMPTZ NOT NULL,
+  current_period_end TIMESTAMPTZ NOT NULL,
+  -- Access ends here: the period end plus the plan's grace, or the period
+  -- end alone once cancelled.
+  grace_ends_at TIMESTAMPTZ NOT NULL,
+  cancelled_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (user_id, plan_id),
+  CHECK (current_period_end >= current_period_start AND grace_ends_at >= current_period_end)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS subscriptions;
+DROP TABLE IF EXISTS plan_items;
+DROP TABLE IF EXISTS plans;
+-- Postgres cannot drop an enum value; 'plan' stays on access_target.
+-- +goose StatementEnd
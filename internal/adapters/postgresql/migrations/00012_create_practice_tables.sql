@@ -0,0 +1,72 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE questions
+ADD COLUMN subject TEXT,
+ADD COLUMN topic TEXT;
+
+CREATE INDEX IF NOT EXISTS questions_subject_topic_idx ON questions (subject, topic);
+
+-- A practice pack sells practice on one subject's questions. It is the
+-- practice_pack target of access grants and plan items.
+CREATE TABLE IF NOT EXISTS practice_packs (
+  id BIGSERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  subject TEXT NOT NULL,
+  description TEXT,
+  created_by BIGINT NOT NULL REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TYPE practice_session_status AS ENUM ('in_progress', 'completed');
+
+-- Practice is kept apart from exam_attempts so it never reaches results.
+CREATE TABLE IF NOT EXISTS practice_sessions (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id),
+  pack_id BIGINT NOT NULL REFERENCES practice_packs(id),
+  topics TEXT[] NOT NULL DEFAULT '{}',
+  question_count INT NOT NULL CHECK (question_count > 0),
+  answered_count INT NOT NULL DEFAULT 0,
+  correct_count INT NOT NULL DEFAULT 0,
+  -- Consecutive correct answers in this session, and the best run so far.
+  current_run INT NOT NULL DEFAULT 0,
+  best_run INT NOT NULL DEFAULT 0,
+  status practice_session_status NOT NULL DEFAULT 'in_progress',
+  started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS practice_sessions_user_idx ON practice_sessions (user_id, started_at DESC);
+
+CREATE TABLE IF NOT EXISTS practice_session_questions (
+  session_id BIGINT NOT NULL REFERENCES practice_sessions(id) ON DELETE CASCADE,
+  question_id BIGINT NOT NULL REFERENCES questions(id),
+  position INT NOT NULL,
+  answer JSONB,
+  fraction DOUBLE PRECISION,
+  answered_at TIMESTAMPTZ,
+  PRIMARY KEY (session_id, question_id)
+);
+
+-- Days in a row on which the candidate answered at least one practice
+-- question, counted in UTC.
+CREATE TABLE IF NOT EXISTS practice_streaks (
+  user_id BIGINT PRIMARY KEY REFERENCES users(id),
+  current_streak INT NOT NULL DEFAULT 0,
+  longest_streak INT NOT NULL DEFAULT 0,
+  last_practice_date DATE NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS practice_streaks;
+DROP TABLE IF EXISTS practice_session_questions;
+DROP TABLE IF EXISTS practice_sessions;
+DROP TYPE IF EXISTS practice_session_status;
+DROP TABLE IF EXISTS practice_packs;
+DROP INDEX IF EXISTS questions_subject_topic_idx;
+ALTER TABLE questions
+DROP COLUMN IF EXISTS topic,
+DROP COLUMN IF EXISTS subject;
+-- +goose StatementEnd
@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A past question records where it was first set. Body, year and paper go
+-- together; the original question number is optional.
+ALTER TABLE questions
+ADD COLUMN exam_body TEXT,
+ADD COLUMN exam_year INT CHECK (exam_year BETWEEN 1900 AND 2100),
+ADD COLUMN paper_number INT CHECK (paper_number > 0),
+ADD COLUMN question_number INT CHECK (question_number > 0),
+ADD CONSTRAINT questions_past_paper_check CHECK (
+  (exam_body IS NULL) = (exam_year IS NULL)
+  AND (exam_body IS NULL) = (paper_number IS NULL)
+  AND (question_number IS NULL OR exam_body IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS questions_past_paper_idx ON questions (exam_body, exam_year, paper_number, question_number)
+WHERE exam_body IS NOT NULL;
+
+-- A paper launched as a mock is kept as an ordinary exam so attempts,
+-- grading and results work unchanged. There is one per paper.
+ALTER TABLE exams
+ADD COLUMN source_exam_body TEXT,
+ADD COLUMN source_year INT,
+ADD COLUMN source_paper INT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS exams_source_paper_idx ON exams (source_exam_body, source_year, source_paper)
+WHERE source_exam_body IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS exams_source_paper_idx;
+ALTER TABLE exams
+DROP COLUMN IF EXISTS source_paper,
+DROP COLUMN IF EXISTS source_year,
+DROP COLUMN IF EXISTS source_exam_body;
+
+DROP INDEX IF EXISTS questions_past_paper_idx;
+ALTER TABLE questions
+DROP CONSTRAINT IF EXISTS questions_past_paper_check,
+DROP COLUMN IF EXISTS question_number,
+DROP COLUMN IF EXISTS paper_number,
+DROP COLUMN IF EXISTS exam_year,
+DROP COLUMN IF EXISTS exam_body;
+-- +goose StatementEnd
@@ -0,0 +1,59 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A combination exam is split into subjects, each one a section of
+-- exam_questions. Candidates sit the required subjects plus elective_count
+-- electives of their choice.
+ALTER TABLE exams
+ADD COLUMN elective_count INT NOT NULL DEFAULT 0 CHECK (elective_count >= 0);
+
+CREATE TABLE IF NOT EXISTS exam_subjects (
+  exam_id BIGINT NOT NULL REFERENCES exams(id) ON DELETE CASCADE,
+  subject TEXT NOT NULL,
+  required BOOLEAN NOT NULL DEFAULT false,
+  -- Raw subject scores are scaled onto 0..scaled_max before they are added up.
+  scaled_max DOUBLE PRECISION NOT NULL DEFAULT 100 CHECK (scaled_max > 0),
+  position INT NOT NULL DEFAULT 0,
+  PRIMARY KEY (exam_id, subject)
+);
+
+-- When an exam has any rows here, candidates must name a course of study and
+-- their electives must be one of its combinations.
+CREATE TABLE IF NOT EXISTS exam_subject_combinations (
+  id BIGSERIAL PRIMARY KEY,
+  exam_id BIGINT NOT NULL REFERENCES exams(id) ON DELETE CASCADE,
+  course TEXT NOT NULL,
+  electives TEXT[] NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS exam_subject_combinations_exam_idx ON exam_subject_combinations (exam_id, course);
+
+-- subjects is what the candidate sits, required subjects included. It stays
+-- empty for exams without subjects.
+ALTER TABLE exam_attempts
+ADD COLUMN course TEXT,
+ADD COLUMN subjects TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE TABLE IF NOT EXISTS attempt_subject_scores (
+  attempt_id BIGINT NOT NULL REFERENCES exam_attempts(id) ON DELETE CASCADE,
+  subject TEXT NOT NULL,
+  raw_score DOUBLE PRECISION NOT NULL,
+  raw_max DOUBLE PRECISION NOT NULL,
+  scaled_score DOUBLE PRECISION NOT NULL,
+  scaled_max DOUBLE PRECISION NOT NULL,
+  graded_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (attempt_id, subject)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS attempt_subject_scores;
+ALTER TABLE exam_attempts
+DROP COLUMN IF EXISTS subjects,
+DROP COLUMN IF EXISTS course;
+DROP TABLE IF EXISTS exam_subject_combinations;
+DROP TABLE IF EXISTS exam_subjects;
+ALTER TABLE exams
+DROP COLUMN IF EXISTS elective_count;
+-- +goose StatementEnd
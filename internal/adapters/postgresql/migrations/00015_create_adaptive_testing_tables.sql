@@ -0,0 +1,85 @@
+-- +goose Up
+-- +goose StatementBegin
+-- 3PL item parameters on the logistic metric. Only questions with irt_a set
+-- can be used in adaptive exams.
+ALTER TABLE questions
+ADD COLUMN irt_a DOUBLE PRECISION CHECK (irt_a > 0),
+ADD COLUMN irt_b DOUBLE PRECISION,
+ADD COLUMN irt_c DOUBLE PRECISION CHECK (irt_c >= 0 AND irt_c < 1),
+ADD CONSTRAINT questions_irt_check CHECK ((irt_a IS NULL) = (irt_b IS NULL) AND (irt_a IS NULL) = (irt_c IS NULL));
+
+CREATE TYPE ability_estimator AS ENUM ('eap', 'mle');
+
+-- An exam with a row here is adaptive: its questions are a pool and each
+-- candidate gets the items most informative at their current estimate.
+CREATE TABLE IF NOT EXISTS exam_adaptive_settings (
+  exam_id BIGINT PRIMARY KEY REFERENCES exams(id) ON DELETE CASCADE,
+  estimator ability_estimator NOT NULL DEFAULT 'eap',
+  min_items INT NOT NULL DEFAULT 5 CHECK (min_items > 0),
+  max_items INT NOT NULL DEFAULT 30 CHECK (max_items >= min_items),
+  se_threshold DOUBLE PRECISION NOT NULL DEFAULT 0.3 CHECK (se_threshold > 0),
+  -- The next item is drawn at random from this many of the most informative.
+  randomesque INT NOT NULL DEFAULT 5 CHECK (randomesque > 0),
+  -- Items given to more than this share of candidates are rested.
+  max_exposure_rate DOUBLE PRECISION NOT NULL DEFAULT 0.3 CHECK (max_exposure_rate > 0 AND max_exposure_rate <= 1),
+  -- Target share of items per section, e.g. {"algebra": 0.4, "geometry": 0.6}.
+  content_targets JSONB NOT NULL DEFAULT '{}',
+  scale_mean DOUBLE PRECISION NOT NULL DEFAULT 500,
+  scale_sd DOUBLE PRECISION NOT NULL DEFAULT 100 CHECK (scale_sd > 0),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS adaptive_states (
+  attempt_id BIGINT PRIMARY KEY REFERENCES exam_attempts(id) ON DELETE CASCADE,
+  exam_id BIGINT NOT NULL REFERENCES exams(id) ON DELETE CASCADE,
+  theta DOUBLE PRECISION NOT NULL DEFAULT 0,
+  se DOUBLE PRECISION NOT NULL DEFAULT 1,
+  scaled_score DOUBLE PRECISION NOT NULL,
+  items INT NOT NULL DEFAULT 0,
+  current_question_id BIGINT REFERENCES questions(id),
+  finished_at TIMESTAMPTZ,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS adaptive_states_exam_idx ON adaptive_states (exam_id);
+
+CREATE TABLE IF NOT EXISTS adaptive_responses (
+  attempt_id BIGINT NOT NULL REFERENCES exam_attempts(id) ON DELETE CASCADE,
+  question_id BIGINT NOT NULL REFERENCES questions(id),
+  seq INT NOT NULL,
+  section TEXT NOT NULL,
+  -- Item parameters as they were when the item was given, so later
+  -- recalibration does not change a finished estimate.
+  irt_a DOUBLE PRECISION NOT NULL,
+  irt_b DOUBLE PRECISION NOT NULL,
+  irt_c DOUBLE PRECISION NOT NULL,
+  answer JSONB NOT NULL,
+  fraction DOUBLE PRECISION NOT NULL,
+  -- The estimate after this answer.
+  theta DOUBLE PRECISION NOT NULL,
+  se DOUBLE PRECISION NOT NULL,
+  answered_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (attempt_id, question_id)
+);
+
+CREATE TABLE IF NOT EXISTS adaptive_exposures (
+  exam_id BIGINT NOT NULL REFERENCES exams(id) ON DELETE CASCADE,
+  question_id BIGINT NOT NULL REFERENCES questions(id),
+  administered INT NOT NULL DEFAULT 0,
+  PRIMARY KEY (exam_id, question_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS adaptive_exposures;
+DROP TABLE IF EXISTS adaptive_responses;
+DROP TABLE IF EXISTS adaptive_states;
+DROP TABLE IF EXISTS exam_adaptive_settings;
+DROP TYPE IF EXISTS ability_estimator;
+ALTER TABLE questions
+DROP CONSTRAINT IF EXISTS questions_irt_check,
+DROP COLUMN IF EXISTS irt_c,
+DROP COLUMN IF EXISTS irt_b,
+DROP COLUMN IF EXISTS irt_a;
+-- +goose StatementEnd
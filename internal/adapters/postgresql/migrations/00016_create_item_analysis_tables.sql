@@ -0,0 +1,73 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE analysis_status AS ENUM ('queued', 'running', 'completed', 'failed');
+
+-- One item analysis of an exam. Runs are queued by admins and worked by the
+-- analysis job; each keeps its own statistics, so earlier reports stay
+-- available after regrading or more sittings.
+CREATE TABLE IF NOT EXISTS item_analysis_runs (
+  id BIGSERIAL PRIMARY KEY,
+  exam_id BIGINT NOT NULL REFERENCES exams(id) ON DELETE CASCADE,
+  status analysis_status NOT NULL DEFAULT 'queued',
+  requested_by BIGINT REFERENCES users(id),
+  attempts INT NOT NULL DEFAULT 0,
+  items INT NOT NULL DEFAULT 0,
+  mean_score DOUBLE PRECISION,
+  sd_score DOUBLE PRECISION,
+  -- Reliability over the questions every analysed attempt was scored on.
+  -- kr20 is only set when all of them are scored right or wrong.
+  kr20 DOUBLE PRECISION,
+  alpha DOUBLE PRECISION,
+  error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  started_at TIMESTAMPTZ,
+  completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS item_analysis_runs_exam_idx ON item_analysis_runs (exam_id, created_at DESC);
+
+-- At most one run per exam waits or runs at a time.
+CREATE UNIQUE INDEX IF NOT EXISTS item_analysis_runs_active_idx ON item_analysis_runs (exam_id)
+WHERE status IN ('queued', 'running');
+
+CREATE TABLE IF NOT EXISTS item_statistics (
+  run_id BIGINT NOT NULL REFERENCES item_analysis_runs(id) ON DELETE CASCADE,
+  question_id BIGINT NOT NULL REFERENCES questions(id),
+  section TEXT NOT NULL,
+  position INT NOT NULL,
+  responses INT NOT NULL,
+  omitted INT NOT NULL,
+  -- Mean share of the marks earned.
+  p_value DOUBLE PRECISION NOT NULL,
+  -- Upper minus lower 27% group p-value.
+  discrimination DOUBLE PRECISION NOT NULL,
+  -- Correlation with the total of the other questions. NULL when either
+  -- side has no variance.
+  point_biserial DOUBLE PRECISION,
+  alpha_if_deleted DOUBLE PRECISION,
+  flags TEXT[] NOT NULL DEFAULT '{}',
+  PRIMARY KEY (run_id, question_id)
+);
+
+-- How often each option of a choice question was picked, and by whom.
+CREATE TABLE IF NOT EXISTS item_distractors (
+  run_id BIGINT NOT NULL REFERENCES item_analysis_runs(id) ON DELETE CASCADE,
+  question_id BIGINT NOT NULL REFERENCES questions(id),
+  option TEXT NOT NULL,
+  is_key BOOLEAN NOT NULL,
+  chosen INT NOT NULL,
+  proportion DOUBLE PRECISION NOT NULL,
+  upper_proportion DOUBLE PRECISION NOT NULL,
+  lower_proportion DOUBLE PRECISION NOT NULL,
+  point_biserial DOUBLE PRECISION,
+  PRIMARY KEY (run_id, question_id, option)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS item_distractors;
+DROP TABLE IF EXISTS item_statistics;
+DROP TABLE IF EXISTS item_analysis_runs;
+DROP TYPE IF EXISTS analysis_status;
+-- +goose StatementEnd
@@ -0,0 +1,58 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE irt_model AS ENUM ('1pl', '2pl', '3pl');
+
+-- One run of the calibration command. exam_id is NULL when the whole bank
+-- was calibrated together.
+CREATE TABLE IF NOT EXISTS irt_calibrations (
+  id BIGSERIAL PRIMARY KEY,
+  model irt_model NOT NULL,
+  exam_id BIGINT REFERENCES exams(id) ON DELETE SET NULL,
+  respondents INT NOT NULL,
+  items INT NOT NULL,
+  cycles INT NOT NULL,
+  converged BOOLEAN NOT NULL,
+  log_likelihood DOUBLE PRECISION NOT NULL,
+  aic DOUBLE PRECISION NOT NULL,
+  bic DOUBLE PRECISION NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Every estimate a calibration produced, applied or not. Together with
+-- questions.irt_calibration_id this is the history of each question's
+-- parameters.
+CREATE TABLE IF NOT EXISTS item_calibrations (
+  calibration_id BIGINT NOT NULL REFERENCES irt_calibrations(id) ON DELETE CASCADE,
+  question_id BIGINT NOT NULL REFERENCES questions(id),
+  irt_a DOUBLE PRECISION NOT NULL,
+  irt_b DOUBLE PRECISION NOT NULL,
+  irt_c DOUBLE PRECISION NOT NULL,
+  -- Zero for parameters the model fixes.
+  se_a DOUBLE PRECISION NOT NULL,
+  se_b DOUBLE PRECISION NOT NULL,
+  se_c DOUBLE PRECISION NOT NULL,
+  rmsd DOUBLE PRECISION NOT NULL,
+  responses INT NOT NULL,
+  converged BOOLEAN NOT NULL,
+  -- Why the estimate did not converge.
+  reason TEXT,
+  applied BOOLEAN NOT NULL DEFAULT false,
+  PRIMARY KEY (calibration_id, question_id)
+);
+
+CREATE INDEX IF NOT EXISTS item_calibrations_question_idx ON item_calibrations (question_id);
+
+-- The calibration the current parameters came from. NULL when they were
+-- entered by hand.
+ALTER TABLE questions
+ADD COLUMN irt_calibration_id BIGINT REFERENCES irt_calibrations(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE questions
+DROP COLUMN IF EXISTS irt_calibration_id;
+DROP TABLE IF EXISTS item_calibrations;
+DROP TABLE IF EXISTS irt_calibrations;
+DROP TYPE IF EXISTS irt_model;
+-- +goose StatementEnd
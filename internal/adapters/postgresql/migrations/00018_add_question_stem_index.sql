@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Imports look questions up by stem with case and spacing ignored, so the
+-- same question typed slightly differently is still caught as a duplicate.
+CREATE INDEX IF NOT EXISTS questions_normalized_stem_idx ON questions (btrim(regexp_replace(lower(stem), '\s+', ' ', 'g')));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS questions_normalized_stem_idx;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Images and other files used in question text. Question HTML links to them
+-- as /api/media/{id}. Files are stored once per checksum, so importing the
+-- same package twice does not copy its media again.
+CREATE TABLE IF NOT EXISTS media_files (
+  id BIGSERIAL PRIMARY KEY,
+  checksum TEXT NOT NULL UNIQUE,
+  name TEXT NOT NULL,
+  content_type TEXT NOT NULL,
+  size BIGINT NOT NULL,
+  data BYTEA NOT NULL,
+  created_by BIGINT NOT NULL REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS media_files;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- File contents move to blob storage under a content-addressed key. Files
+-- stored before this keep their bytes in data, which new files leave NULL.
+ALTER TABLE media_files
+  ADD COLUMN IF NOT EXISTS storage_key TEXT,
+  ADD COLUMN IF NOT EXISTS width INT,
+  ADD COLUMN IF NOT EXISTS height INT,
+  ALTER COLUMN data DROP NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM media_files WHERE data IS NULL;
+ALTER TABLE media_files
+  DROP COLUMN IF EXISTS storage_key,
+  DROP COLUMN IF EXISTS width,
+  DROP COLUMN IF EXISTS height,
+  ALTER COLUMN data SET NOT NULL;
+-- +goose StatementEnd
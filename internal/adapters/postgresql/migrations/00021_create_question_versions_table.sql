@@ -0,0 +1,97 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Every edit to a question's content or key writes a new version. The
+-- question row always holds the latest one; version is its number.
+ALTER TABLE questions
+ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS question_versions (
+  question_id BIGINT NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+  version INT NOT NULL,
+  -- The version whose stem and options this one carries. A key correction
+  -- keeps the content of the version before it, so candidates who were
+  -- shown that content are graded against the corrected key; any other
+  -- edit, and a rollback, starts new content.
+  content_version INT NOT NULL,
+  type question_type NOT NULL,
+  stem TEXT NOT NULL,
+  options JSONB NOT NULL,
+  answer_key JSONB NOT NULL,
+  explanation TEXT,
+  marks DOUBLE PRECISION NOT NULL,
+  subject TEXT,
+  topic TEXT,
+  reason TEXT NOT NULL DEFAULT '',
+  created_by BIGINT NOT NULL REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (question_id, version),
+  CHECK (content_version <= version)
+);
+
+CREATE INDEX IF NOT EXISTS question_versions_content_idx ON question_versions (question_id, content_version, version DESC);
+
+INSERT INTO question_versions (question_id, version, content_version, type, stem, options, answer_key, explanation, marks, subject, topic, created_by, created_at)
+SELECT id, 1, 1, type, stem, options, answer_key, explanation, marks, subject, topic, created_by, COALESCE(updated_at, created_at)
+FROM questions
+ON CONFLICT DO NOTHING;
+
+-- The version of each question an attempt was shown, fixed when the paper
+-- is drawn up or, in adaptive exams, when the item is presented.
+CREATE TABLE IF NOT EXISTS attempt_question_versions (
+  attempt_id BIGINT NOT NULL REFERENCES exam_attempts(id) ON DELETE CASCADE,
+  question_id BIGINT NOT NULL,
+  version INT NOT NULL,
+  PRIMARY KEY (attempt_id, question_id),
+  FOREIGN KEY (question_id, version) REFERENCES question_versions(question_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS attempt_question_versions_version_idx ON attempt_question_versions (question_id, version);
+
+-- Attempts from before versioning were all shown version 1.
+INSERT INTO attempt_question_versions (attempt_id, question_id, version)
+SELECT t.id, eq.question_id, 1
+FROM exam_attempts t
+JOIN exam_questions eq ON eq.exam_id = t.exam_id
+WHERE NOT EXISTS (SELECT 1 FROM exam_adaptive_settings s WHERE s.exam_id = t.exam_id)
+  AND (cardinality(t.subjects) = 0 OR eq.section = ANY(t.subjects))
+UNION
+SELECT attempt_id, question_id, 1 FROM adaptive_responses
+UNION
+SELECT attempt_id, current_question_id, 1 FROM adaptive_states WHERE current_question_id IS NOT NULL
+UNION
+SELECT attempt_id, question_id, 1 FROM attempt_question_scores
+UNION
+SELECT attempt_id, question_id, 1 FROM attempt_answers
+ON CONFLICT DO NOTHING;
+
+-- What an attempt was shown, with the answer key it is graded against: the
+-- latest key given to the same content.
+CREATE OR REPLACE VIEW delivered_questions AS
+SELECT d.attempt_id,
+       v.question_id,
+       v.version,
+       v.type,
+       v.stem,
+       v.options,
+       (
+         SELECT k.answer_key
+         FROM question_versions k
+         WHERE k.question_id = v.question_id
+           AND k.content_version = v.content_version
+         ORDER BY k.version DESC
+         LIMIT 1
+       ) AS answer_key,
+       v.explanation,
+       v.marks
+FROM attempt_question_versions d
+JOIN question_versions v ON v.question_id = d.question_id AND v.version = d.version;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP VIEW IF EXISTS delivered_questions;
+DROP TABLE IF EXISTS attempt_question_versions;
+DROP TABLE IF EXISTS question_versions;
+ALTER TABLE questions
+DROP COLUMN IF EXISTS version;
+-- +goose StatementEnd
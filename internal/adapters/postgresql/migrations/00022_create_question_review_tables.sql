@@ -0,0 +1,59 @@
+-- +goose Up
+-- +goose StatementBegin
+-- New questions start as drafts and only approved ones can be put on
+-- exams or drawn for practice and adaptive tests. Questions already in the
+-- bank are in use, so they start approved.
+CREATE TYPE question_status AS ENUM ('draft', 'in_review', 'approved', 'rejected', 'retired');
+CREATE TYPE review_decision AS ENUM ('approved', 'rejected');
+
+ALTER TYPE admin_permission ADD VALUE IF NOT EXISTS 'reviewer';
+
+ALTER TABLE questions
+ADD COLUMN IF NOT EXISTS status question_status NOT NULL DEFAULT 'draft';
+
+UPDATE questions SET status = 'approved';
+
+CREATE INDEX IF NOT EXISTS questions_status_idx ON questions (status);
+
+-- Reviewers assigned to a question and their decision in the current round.
+-- version is the question version the decision was made on; a decision on
+-- an older version no longer counts.
+CREATE TABLE IF NOT EXISTS question_reviewers (
+  question_id BIGINT NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+  reviewer_id BIGINT NOT NULL REFERENCES users(id),
+  assigned_by BIGINT NOT NULL REFERENCES users(id),
+  assigned_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  decision review_decision,
+  version INT,
+  decided_at TIMESTAMPTZ,
+  PRIMARY KEY (question_id, reviewer_id),
+  CHECK ((decision IS NULL) = (version IS NULL) AND (decision IS NULL) = (decided_at IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS question_reviewers_reviewer_idx ON question_reviewers (reviewer_id);
+
+-- Review discussion. A comment with a parent is a reply to it.
+CREATE TABLE IF NOT EXISTS question_review_comments (
+  id BIGSERIAL PRIMARY KEY,
+  question_id BIGINT NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+  parent_id BIGINT REFERENCES question_review_comments(id) ON DELETE CASCADE,
+  author_id BIGINT NOT NULL REFERENCES users(id),
+  version INT NOT NULL,
+  body TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS question_review_comments_question_idx ON question_review_comments (question_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- The reviewer permission value stays, since enum values can't be dropped.
+DELETE FROM user_permissions WHERE permission = 'reviewer';
+DROP TABLE IF EXISTS question_review_comments;
+DROP TABLE IF EXISTS question_reviewers;
+ALTER TABLE questions
+DROP COLUMN IF EXISTS status;
+DROP TYPE IF EXISTS review_decision;
+DROP TYPE IF EXISTS question_status;
+-- +goose StatementEnd
@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A merged question is a duplicate retired in favour of the question it
+-- points at. Its attempts, versions and scores are kept as they were.
+ALTER TABLE questions
+ADD COLUMN IF NOT EXISTS merged_into BIGINT REFERENCES questions(id);
+
+-- The MinHash signature of each question's normalized text, and the band
+-- hashes used to find questions whose signatures may be close.
+CREATE TABLE IF NOT EXISTS question_signatures (
+  question_id BIGINT PRIMARY KEY REFERENCES questions(id) ON DELETE CASCADE,
+  minhash BIGINT[] NOT NULL,
+  bands BIGINT[] NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS question_signatures_bands_idx ON question_signatures USING GIN (bands);
+
+CREATE TYPE duplicate_status AS ENUM ('open', 'merged', 'dismissed');
+
+-- A pair of questions found to be near-duplicates. question_id is the newer
+-- of the two, so a pair is only flagged once.
+CREATE TABLE IF NOT EXISTS duplicate_flags (
+  id BIGSERIAL PRIMARY KEY,
+  question_id BIGINT NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+  duplicate_of BIGINT NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+  similarity DOUBLE PRECISION NOT NULL,
+  status duplicate_status NOT NULL DEFAULT 'open',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  resolved_by BIGINT REFERENCES users(id),
+  resolved_at TIMESTAMPTZ,
+  UNIQUE (question_id, duplicate_of),
+  CHECK (question_id > duplicate_of)
+);
+
+CREATE INDEX IF NOT EXISTS duplicate_flags_status_idx ON duplicate_flags (status, similarity DESC);
+CREATE INDEX IF NOT EXISTS duplicate_flags_duplicate_of_idx ON duplicate_flags (duplicate_of);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS duplicate_flags;
+DROP TYPE IF EXISTS duplicate_status;
+DROP TABLE IF EXISTS question_signatures;
+ALTER TABLE questions
+DROP COLUMN IF EXISTS merged_into;
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Bank search ranks a match in the stem above one in the options, and
+-- either above one in the explanation. Markup is skipped by the parser, so
+-- only the text of sanitized HTML and MathML is indexed.
+ALTER TABLE questions
+ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+  setweight(to_tsvector('english', stem), 'A') ||
+  setweight(to_tsvector('english', jsonb_path_query_array(options, 'strict $.**.text')), 'B') ||
+  setweight(to_tsvector('english', coalesce(explanation, '')), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS questions_search_idx ON questions USING GIN (search_vector);
+
+-- Difficulty bands from the calibrated IRT difficulty. Questions not yet
+-- calibrated have none.
+CREATE TYPE question_difficulty AS ENUM ('easy', 'medium', 'hard');
+
+ALTER TABLE questions
+ADD COLUMN IF NOT EXISTS difficulty question_difficulty GENERATED ALWAYS AS (
+  CASE
+    WHEN irt_b IS NULL THEN NULL
+    WHEN irt_b < -1 THEN 'easy'::question_difficulty
+    WHEN irt_b <= 1 THEN 'medium'::question_difficulty
+    ELSE 'hard'::question_difficulty
+  END
+) STORED;
+
+CREATE INDEX IF NOT EXISTS questions_facets_idx ON questions (subject, topic, type, difficulty);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS questions_facets_idx;
+DROP INDEX IF EXISTS questions_search_idx;
+ALTER TABLE questions
+DROP COLUMN IF EXISTS difficulty,
+DROP COLUMN IF EXISTS search_vector;
+DROP TYPE IF EXISTS question_difficulty;
+-- +goose StatementEnd
@@ -0,0 +1,62 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Integrity events reported by the exam client during a remote sitting.
+-- multiple_faces comes from a face detection model running in the client.
+CREATE TYPE proctor_event_type AS ENUM ('tab_switch', 'fullscreen_exit', 'copy', 'paste', 'window_blur', 'multiple_faces');
+CREATE TYPE proctor_action AS ENUM ('flag', 'submit');
+
+-- Per-exam thresholds: once an attempt has reported flag_after events of a
+-- type it is flagged for review, and at submit_after it is submitted for
+-- the candidate. Either may be left out.
+CREATE TABLE IF NOT EXISTS exam_proctoring_rules (
+  exam_id BIGINT NOT NULL REFERENCES exams(id) ON DELETE CASCADE,
+  event_type proctor_event_type NOT NULL,
+  flag_after INT CHECK (flag_after > 0),
+  submit_after INT CHECK (submit_after > 0),
+  PRIMARY KEY (exam_id, event_type),
+  CHECK (flag_after IS NOT NULL OR submit_after IS NOT NULL)
+);
+
+-- Events are only ever appended and read back per attempt in order, so the
+-- table has no key of its own. occurred_at is the client's clock and
+-- received_at the server's.
+CREATE TABLE IF NOT EXISTS proctor_events (
+  attempt_id BIGINT NOT NULL REFERENCES exam_attempts(id) ON DELETE CASCADE,
+  event_type proctor_event_type NOT NULL,
+  occurred_at TIMESTAMPTZ NOT NULL,
+  received_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  details JSONB NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX IF NOT EXISTS proctor_events_attempt_idx ON proctor_events (attempt_id, occurred_at);
+
+-- Running totals per attempt and type, kept with the events so thresholds
+-- are checked without counting the log.
+CREATE TABLE IF NOT EXISTS attempt_proctor_counts (
+  attempt_id BIGINT NOT NULL REFERENCES exam_attempts(id) ON DELETE CASCADE,
+  event_type proctor_event_type NOT NULL,
+  count INT NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (attempt_id, event_type)
+);
+
+-- Actions taken when an attempt crossed a threshold. Each is taken once.
+CREATE TABLE IF NOT EXISTS attempt_proctor_actions (
+  attempt_id BIGINT NOT NULL REFERENCES exam_attempts(id) ON DELETE CASCADE,
+  event_type proctor_event_type NOT NULL,
+  action proctor_action NOT NULL,
+  event_count INT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (attempt_id, event_type, action)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS attempt_proctor_actions;
+DROP TABLE IF EXISTS attempt_proctor_counts;
+DROP TABLE IF EXISTS proctor_events;
+DROP TABLE IF EXISTS exam_proctoring_rules;
+DROP TYPE IF EXISTS proctor_action;
+DROP TYPE IF EXISTS proctor_event_type;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+-- Only super admins hand out grading, moderation and review permissions, so
+-- an admin can't give themselves a second mark on a response. A new enum
+-- value can't be used in the transaction that adds it, so this migration
+-- runs statement by statement.
+ALTER TYPE admin_permission ADD VALUE IF NOT EXISTS 'super_admin';
+
+-- The first admin becomes the first super admin.
+INSERT INTO user_permissions (user_id, permission, granted_by)
+SELECT id, 'super_admin', id
+FROM users
+WHERE role = 'ADMIN'
+ORDER BY id
+LIMIT 1
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+-- The super_admin permission value stays, since enum values can't be dropped.
+DELETE FROM user_permissions WHERE permission = 'super_admin';
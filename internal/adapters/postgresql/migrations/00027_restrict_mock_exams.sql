@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Mock exams are now put up by admins and need access to sit. Mocks
+-- candidates launched before are handed to the first admin, locked behind
+-- access and given a duration to match their questions.
+UPDATE exams e
+SET created_by = a.id
+FROM (SELECT MIN(id) AS id FROM users WHERE role = 'ADMIN') a
+WHERE e.source_exam_body IS NOT NULL
+  AND a.id IS NOT NULL;
+
+INSERT INTO exam_schedules (exam_id, requires_access)
+SELECT id, true
+FROM exams
+WHERE source_exam_body IS NOT NULL
+ON CONFLICT (exam_id) DO UPDATE
+SET requires_access = true,
+    updated_at = now();
+
+UPDATE exams e
+SET duration_minutes = GREATEST(c.questions, 1)
+FROM (
+  SELECT exam_id, COUNT(*)::int AS questions
+  FROM exam_questions
+  GROUP BY exam_id
+) c
+WHERE c.exam_id = e.id
+  AND e.source_exam_body IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Who launched each mock is not kept, so ownership stays with the admin.
+UPDATE exam_schedules s
+SET requires_access = false,
+    updated_at = now()
+FROM exams e
+WHERE e.id = s.exam_id
+  AND e.source_exam_body IS NOT NULL;
+-- +goose StatementEnd
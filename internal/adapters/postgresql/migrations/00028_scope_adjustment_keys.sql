@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Adjustment keys come from clients and shared a namespace with the keys
+-- the system posts earnings and payouts under. They are now kept as
+-- adjustment:<agent id>:<client key>.
+UPDATE ledger_transactions
+SET idempotency_key = 'adjustment:' || agent_id || ':' || idempotency_key
+WHERE kind = 'adjustment';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE ledger_transactions
+SET idempotency_key = substr(idempotency_key, length('adjustment:' || agent_id || ':') + 1)
+WHERE kind = 'adjustment'
+  AND idempotency_key LIKE 'adjustment:' || agent_id || ':%';
+-- +goose StatementEnd
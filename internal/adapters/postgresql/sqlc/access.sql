@@ -0,0 +1,49 @@
+-- name: GrantAccess :one
+INSERT INTO access_grants (
+  user_id,
+  target_type,
+  target_id,
+  source,
+  source_id,
+  expires_at
+)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (user_id, target_type, target_id, source, source_id) DO UPDATE
+SET expires_at = EXCLUDED.expires_at,
+    granted_at = now()
+RETURNING *;
+
+
+-- name: HasAccess :one
+SELECT EXISTS (
+  SELECT 1
+  FROM access_grants
+  WHERE user_id = $1
+    AND target_type = $2
+    AND target_id = $3
+    AND (expires_at IS NULL OR expires_at > now())
+);
+
+
+-- name: ListUserAccessGrants :many
+SELECT *
+FROM access_grants
+WHERE user_id = $1
+ORDER BY granted_at DESC;
+
+
+-- name: RevokeAccessBySource :execrows
+DELETE FROM access_grants
+WHERE source = $1
+  AND source_id = $2;
+
+
+-- name: GetActiveAccessGrant :one
+SELECT *
+FROM access_grants
+WHERE user_id = $1
+  AND target_type = $2
+  AND target_id = $3
+  AND (expires_at IS NULL OR expires_at > now())
+ORDER BY expires_at DESC NULLS FIRST
+LIMIT 1;
@@ -0,0 +1,147 @@
+-- name: GetAdaptiveSettings :one
+SELECT *
+FROM exam_adaptive_settings
+WHERE exam_id = $1;
+
+
+-- name: UpsertAdaptiveSettings :one
+INSERT INTO exam_adaptive_settings (
+  exam_id,
+  estimator,
+  min_items,
+  max_items,
+  se_threshold,
+  randomesque,
+  max_exposure_rate,
+  content_targets,
+  scale_mean,
+  scale_sd
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (exam_id) DO UPDATE
+SET estimator = EXCLUDED.estimator,
+    min_items = EXCLUDED.min_items,
+    max_items = EXCLUDED.max_items,
+    se_threshold = EXCLUDED.se_threshold,
+    randomesque = EXCLUDED.randomesque,
+    max_exposure_rate = EXCLUDED.max_exposure_rate,
+    content_targets = EXCLUDED.content_targets,
+    scale_mean = EXCLUDED.scale_mean,
+    scale_sd = EXCLUDED.scale_sd,
+    updated_at = now()
+RETURNING *;
+
+
+-- name: DeleteAdaptiveSettings :execrows
+DELETE FROM exam_adaptive_settings
+WHERE exam_id = $1;
+
+
+-- name: ListAdaptivePool :many
+SELECT q.id AS question_id,
+       eq.section,
+       q.irt_a::double precision AS irt_a,
+       q.irt_b::double precision AS irt_b,
+       q.irt_c::double precision AS irt_c,
+       COALESCE(x.administered, 0)::int AS administered
+FROM exam_questions eq
+JOIN questions q ON q.id = eq.question_id
+LEFT JOIN adaptive_exposures x ON x.exam_id = eq.exam_id AND x.question_id = q.id
+WHERE eq.exam_id = $1
+  AND q.irt_a IS NOT NULL
+  AND q.status = 'approved'
+  AND q.type NOT IN ('short_answer', 'essay');
+
+
+-- name: CountAdaptiveStates :one
+SELECT COUNT(*)::bigint
+FROM adaptive_states
+WHERE exam_id = $1;
+
+
+-- name: CreateAdaptiveState :one
+INSERT INTO adaptive_states (
+  attempt_id,
+  exam_id,
+  scaled_score
+)
+VALUES ($1, $2, $3)
+ON CONFLICT (attempt_id) DO UPDATE
+SET updated_at = adaptive_states.updated_at
+RETURNING *;
+
+
+-- name: GetAdaptiveState :one
+SELECT *
+FROM adaptive_states
+WHERE attempt_id = $1;
+
+
+-- name: GetAdaptiveStateForUpdate :one
+SELECT *
+FROM adaptive_states
+WHERE attempt_id = $1
+FOR UPDATE;
+
+
+-- name: SetAdaptiveCurrentQuestion :exec
+UPDATE adaptive_states
+SET current_question_id = $2,
+    updated_at = now()
+WHERE attempt_id = $1;
+
+
+-- name: UpdateAdaptiveEstimate :one
+UPDATE adaptive_states
+SET theta = @theta,
+    se = @se,
+    scaled_score = @scaled_score,
+    items = items + 1,
+    current_question_id = NULL,
+    finished_at = CASE WHEN @finished::boolean THEN now() ELSE finished_at END,
+    updated_at = now()
+WHERE attempt_id = @attempt_id
+RETURNING *;
+
+
+-- name: FinishAdaptiveState :exec
+UPDATE adaptive_states
+SET current_question_id = NULL,
+    finished_at = COALESCE(finished_at, now()),
+    updated_at = now()
+WHERE attempt_id = $1;
+
+
+-- name: RecordAdaptiveExposure :exec
+INSERT INTO adaptive_exposures (
+  exam_id,
+  question_id,
+  administered
+)
+VALUES ($1, $2, 1)
+ON CONFLICT (exam_id, question_id) DO UPDATE
+SET administered = adaptive_exposures.administered + 1;
+
+
+-- name: CreateAdaptiveResponse :exec
+INSERT INTO adaptive_responses (
+  attempt_id,
+  question_id,
+  seq,
+  section,
+  irt_a,
+  irt_b,
+  irt_c,
+  answer,
+  fraction,
+  theta,
+  se
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
+
+
+-- name: ListAdaptiveResponses :many
+SELECT *
+FROM adaptive_responses
+WHERE attempt_id = $1
+ORDER BY seq;
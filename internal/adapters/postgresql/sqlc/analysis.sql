@@ -0,0 +1,136 @@
+-- name: CreateAnalysisRun :one
+INSERT INTO item_analysis_runs (
+  exam_id,
+  requested_by
+)
+VALUES ($1, $2)
+ON CONFLICT (exam_id) WHERE status IN ('queued', 'running') DO NOTHING
+RETURNING *;
+
+
+-- name: GetActiveAnalysisRun :one
+SELECT *
+FROM item_analysis_runs
+WHERE exam_id = $1
+  AND status IN ('queued', 'running');
+
+
+-- name: GetAnalysisRun :one
+SELECT *
+FROM item_analysis_runs
+WHERE id = $1;
+
+
+-- name: ListAnalysisRuns :many
+SELECT *
+FROM item_analysis_runs
+WHERE exam_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+
+-- name: ClaimAnalysisRun :one
+UPDATE item_analysis_runs
+SET status = 'running',
+    started_at = now()
+WHERE id = (
+  SELECT id
+  FROM item_analysis_runs
+  WHERE status = 'queued'
+     OR (status = 'running' AND started_at < now() - interval '30 minutes')
+  ORDER BY created_at
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+
+-- name: CompleteAnalysisRun :one
+UPDATE item_analysis_runs
+SET status = 'completed',
+    attempts = $2,
+    items = $3,
+    mean_score = $4,
+    sd_score = $5,
+    kr20 = $6,
+    alpha = $7,
+    completed_at = now()
+WHERE id = $1
+RETURNING *;
+
+
+-- name: FailAnalysisRun :exec
+UPDATE item_analysis_runs
+SET status = 'failed',
+    error = $2,
+    completed_at = now()
+WHERE id = $1;
+
+
+-- name: ListAnalysisResponses :many
+SELECT s.attempt_id,
+       s.question_id,
+       eq.section,
+       eq.position,
+       q.type,
+       q.answer_key,
+       s.score,
+       s.max_score,
+       a.answer
+FROM attempt_question_scores s
+JOIN attempt_results r ON r.attempt_id = s.attempt_id
+JOIN exam_questions eq ON eq.exam_id = r.exam_id AND eq.question_id = s.question_id
+JOIN questions q ON q.id = s.question_id
+LEFT JOIN attempt_answers a ON a.attempt_id = s.attempt_id AND a.question_id = s.question_id
+WHERE r.exam_id = $1
+  AND r.status = 'graded'
+ORDER BY s.attempt_id, eq.section, eq.position, s.question_id;
+
+
+-- name: CreateItemStatistic :exec
+INSERT INTO item_statistics (
+  run_id,
+  question_id,
+  section,
+  position,
+  responses,
+  omitted,
+  p_value,
+  discrimination,
+  point_biserial,
+  alpha_if_deleted,
+  flags
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
+
+
+-- name: CreateItemDistractor :exec
+INSERT INTO item_distractors (
+  run_id,
+  question_id,
+  option,
+  is_key,
+  chosen,
+  proportion,
+  upper_proportion,
+  lower_proportion,
+  point_biserial
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+
+-- name: ListItemStatistics :many
+SELECT st.*,
+       q.type,
+       q.stem
+FROM item_statistics st
+JOIN questions q ON q.id = st.question_id
+WHERE st.run_id = $1
+ORDER BY st.section, st.position, st.question_id;
+
+
+-- name: ListItemDistractors :many
+SELECT *
+FROM item_distractors
+WHERE run_id = $1
+ORDER BY question_id, option;
@@ -0,0 +1,120 @@
+-- name: CreateAttempt :one
+INSERT INTO exam_attempts (
+  exam_id,
+  user_id,
+  expires_at,
+  course,
+  subjects
+)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+
+-- name: GetAttemptByID :one
+SELECT *
+FROM exam_attempts
+WHERE id = $1;
+
+
+-- name: GetOpenAttempt :one
+SELECT *
+FROM exam_attempts
+WHERE exam_id = $1
+  AND user_id = $2
+  AND status = 'in_progress'
+LIMIT 1;
+
+
+-- name: SubmitAttempt :one
+UPDATE exam_attempts
+SET status = 'submitted',
+    submitted_at = now()
+WHERE id = $1
+  AND status = 'in_progress'
+RETURNING *;
+
+
+-- name: ListAttemptAnswers :many
+SELECT *
+FROM attempt_answers
+WHERE attempt_id = $1
+ORDER BY question_id;
+
+
+-- name: UpsertAttemptAnswers :execrows
+INSERT INTO attempt_answers (
+  attempt_id,
+  question_id,
+  answer,
+  version,
+  saved_at
+)
+SELECT unnest(@attempt_ids::bigint[]),
+       unnest(@question_ids::bigint[]),
+       unnest(@answers::jsonb[]),
+       unnest(@versions::bigint[]),
+       unnest(@saved_ats::timestamptz[])
+ON CONFLICT (attempt_id, question_id) DO UPDATE
+SET answer = EXCLUDED.answer,
+    version = EXCLUDED.version,
+    saved_at = EXCLUDED.saved_at
+WHERE attempt_answers.version < EXCLUDED.version;
+
+
+-- name: ListSubmittedAttemptIDs :many
+SELECT id
+FROM exam_attempts
+WHERE exam_id = $1
+  AND status = 'submitted'
+ORDER BY id;
+
+
+-- name: ListAttemptPaper :many
+SELECT eq.section,
+       eq.position,
+       d.question_id,
+       d.type,
+       d.stem,
+       d.options
+FROM delivered_questions d
+JOIN exam_attempts t ON t.id = d.attempt_id
+JOIN exam_questions eq ON eq.exam_id = t.exam_id AND eq.question_id = d.question_id
+WHERE d.attempt_id = @attempt_id
+ORDER BY array_position(@subjects::text[], eq.section), eq.section, eq.position, d.question_id;
+
+
+-- name: RecordAttemptPaperVersions :exec
+INSERT INTO attempt_question_versions (attempt_id, question_id, version)
+SELECT @attempt_id, q.id, q.version
+FROM exam_questions eq
+JOIN questions q ON q.id = eq.question_id
+WHERE eq.exam_id = @exam_id
+  AND (cardinality(@subjects::text[]) = 0 OR eq.section = ANY(@subjects::text[]))
+ON CONFLICT (attempt_id, question_id) DO NOTHING;
+
+
+-- name: RecordAttemptQuestionVersion :exec
+INSERT INTO attempt_question_versions (attempt_id, question_id, version)
+SELECT @attempt_id, id, version
+FROM questions
+WHERE id = @question_id
+ON CONFLICT (attempt_id, question_id) DO NOTHING;
+
+
+-- name: ListAttemptQuestionIDs :many
+SELECT question_id
+FROM attempt_question_versions
+WHERE attempt_id = $1
+ORDER BY question_id;
+
+
+-- name: GetAttemptQuestion :one
+SELECT question_id,
+       version,
+       type,
+       stem,
+       options,
+       answer_key::jsonb AS answer_key
+FROM delivered_questions
+WHERE attempt_id = $1
+  AND question_id = $2;
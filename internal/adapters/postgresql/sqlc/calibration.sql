@@ -0,0 +1,56 @@
+-- name: ListCalibrationResponses :many
+SELECT s.attempt_id,
+       s.question_id,
+       (s.score >= s.max_score)::boolean AS correct
+FROM attempt_question_scores s
+JOIN attempt_results r ON r.attempt_id = s.attempt_id
+JOIN questions q ON q.id = s.question_id
+WHERE r.status = 'graded'
+  AND q.type NOT IN ('short_answer', 'essay')
+  AND (sqlc.narg(exam_id)::bigint IS NULL OR r.exam_id = sqlc.narg(exam_id))
+ORDER BY s.attempt_id, s.question_id;
+
+
+-- name: CreateCalibration :one
+INSERT INTO irt_calibrations (
+  model,
+  exam_id,
+  respondents,
+  items,
+  cycles,
+  converged,
+  log_likelihood,
+  aic,
+  bic
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+
+-- name: CreateItemCalibration :exec
+INSERT INTO item_calibrations (
+  calibration_id,
+  question_id,
+  irt_a,
+  irt_b,
+  irt_c,
+  se_a,
+  se_b,
+  se_c,
+  rmsd,
+  responses,
+  converged,
+  reason,
+  applied
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13);
+
+
+-- name: ApplyQuestionCalibration :exec
+UPDATE questions
+SET irt_a = $2,
+    irt_b = $3,
+    irt_c = $4,
+    irt_calibration_id = $5,
+    updated_at = now()
+WHERE id = $1;
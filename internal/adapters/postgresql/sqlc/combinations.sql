@@ -0,0 +1,103 @@
+-- name: SetExamElectiveCount :one
+UPDATE exams
+SET elective_count = $2,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+
+-- name: DeleteExamSubjects :exec
+DELETE FROM exam_subjects
+WHERE exam_id = $1;
+
+
+-- name: CreateExamSubject :one
+INSERT INTO exam_subjects (
+  exam_id,
+  subject,
+  required,
+  scaled_max,
+  position
+)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+
+-- name: ListExamSubjects :many
+SELECT *
+FROM exam_subjects
+WHERE exam_id = $1
+ORDER BY position, subject;
+
+
+-- name: CreateSubjectCombination :one
+INSERT INTO exam_subject_combinations (
+  exam_id,
+  course,
+  electives
+)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+
+-- name: DeleteSubjectCombination :execrows
+DELETE FROM exam_subject_combinations
+WHERE id = $1
+  AND exam_id = $2;
+
+
+-- name: ListSubjectCombinations :many
+SELECT *
+FROM exam_subject_combinations
+WHERE exam_id = $1
+ORDER BY course, id;
+
+
+-- name: HasSubjectCombinations :one
+SELECT EXISTS (
+  SELECT 1
+  FROM exam_subject_combinations
+  WHERE exam_id = $1
+);
+
+
+-- name: SubjectCombinationAllowed :one
+SELECT EXISTS (
+  SELECT 1
+  FROM exam_subject_combinations
+  WHERE exam_id = @exam_id
+    AND course = @course
+    AND electives @> @electives::text[]
+    AND electives <@ @electives::text[]
+);
+
+
+-- name: UpsertAttemptSubjectScores :exec
+INSERT INTO attempt_subject_scores (
+  attempt_id,
+  subject,
+  raw_score,
+  raw_max,
+  scaled_score,
+  scaled_max
+)
+SELECT @attempt_id::bigint,
+       unnest(@subjects::text[]),
+       unnest(@raw_scores::double precision[]),
+       unnest(@raw_maxes::double precision[]),
+       unnest(@scaled_scores::double precision[]),
+       unnest(@scaled_maxes::double precision[])
+ON CONFLICT (attempt_id, subject) DO UPDATE
+SET raw_score = EXCLUDED.raw_score,
+    raw_max = EXCLUDED.raw_max,
+    scaled_score = EXCLUDED.scaled_score,
+    scaled_max = EXCLUDED.scaled_max,
+    graded_at = now();
+
+
+-- name: ListAttemptSubjectScores :many
+SELECT s.*
+FROM attempt_subject_scores s
+JOIN exam_attempts t ON t.id = s.attempt_id
+WHERE s.attempt_id = $1
+ORDER BY array_position(t.subjects, s.subject);
@@ -0,0 +1,208 @@
+-- name: UpsertCommissionRule :one
+INSERT INTO commission_rules (
+  product,
+  product_id,
+  rate_bps,
+  flat_amount,
+  created_by
+)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (product, (COALESCE(product_id, 0))) DO UPDATE
+SET rate_bps = EXCLUDED.rate_bps,
+    flat_amount = EXCLUDED.flat_amount,
+    updated_at = now()
+RETURNING *;
+
+
+-- name: ListCommissionRules :many
+SELECT *
+FROM commission_rules
+ORDER BY product, product_id NULLS FIRST;
+
+
+-- name: DeleteCommissionRule :execrows
+DELETE FROM commission_rules
+WHERE id = $1;
+
+
+-- name: FindCommissionRule :one
+SELECT *
+FROM commission_rules
+WHERE product = $1
+  AND (product_id = $2 OR product_id IS NULL)
+ORDER BY product_id NULLS LAST
+LIMIT 1;
+
+
+-- name: GetLedgerAccountByCode :one
+SELECT *
+FROM ledger_accounts
+WHERE code = $1;
+
+
+-- name: UpsertAgentLedgerAccount :one
+INSERT INTO ledger_accounts (
+  code,
+  name,
+  type,
+  agent_id
+)
+VALUES ($1, $2, 'liability', $3)
+ON CONFLICT (agent_id) DO UPDATE
+SET name = EXCLUDED.name
+RETURNING *;
+
+
+-- name: GetAgentLedgerAccount :one
+SELECT *
+FROM ledger_accounts
+WHERE agent_id = $1;
+
+
+-- name: GetAgentLedgerAccountForUpdate :one
+SELECT *
+FROM ledger_accounts
+WHERE agent_id = $1
+FOR UPDATE;
+
+
+-- name: CreateLedgerTransaction :one
+INSERT INTO ledger_transactions (
+  idempotency_key,
+  kind,
+  agent_id,
+  description,
+  reference_type,
+  reference_id,
+  created_by
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (idempotency_key) DO NOTHING
+RETURNING *;
+
+
+-- name: GetLedgerTransactionByKey :one
+SELECT *
+FROM ledger_transactions
+WHERE idempotency_key = $1;
+
+
+-- name: CreateLedgerEntry :one
+INSERT INTO ledger_entries (
+  transaction_id,
+  account_id,
+  debit,
+  credit,
+  payout_id,
+  settled_at
+)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+
+-- name: ListLedgerEntriesByTransaction :many
+SELECT *
+FROM ledger_entries
+WHERE transaction_id = $1
+ORDER BY id;
+
+
+-- name: ListAccountStatement :many
+SELECT *
+FROM (
+  SELECT e.id,
+         e.transaction_id,
+         t.kind,
+         t.description,
+         t.reference_type,
+         t.reference_id,
+         e.debit,
+         e.credit,
+         SUM(e.credit - e.debit) OVER (ORDER BY e.id)::bigint AS balance,
+         e.payout_id,
+         e.settled_at,
+         e.created_at
+  FROM ledger_entries e
+  JOIN ledger_transactions t ON t.id = e.transaction_id
+  WHERE e.account_id = $1
+) s
+ORDER BY s.id DESC
+LIMIT $2 OFFSET $3;
+
+
+-- name: GetAccountBalance :one
+SELECT COALESCE(SUM(credit - debit), 0)::bigint AS balance,
+       COALESCE(SUM(credit - debit) FILTER (WHERE payout_id IS NULL), 0)::bigint AS unsettled
+FROM ledger_entries
+WHERE account_id = $1;
+
+
+-- name: CreateAgentPayout :one
+INSERT INTO agent_payouts (
+  agent_id,
+  amount,
+  idempotency_key,
+  created_by
+)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (idempotency_key) DO NOTHING
+RETURNING *;
+
+
+-- name: GetAgentPayoutByKey :one
+SELECT *
+FROM agent_payouts
+WHERE idempotency_key = $1;
+
+
+-- name: GetAgentPayoutForUpdate :one
+SELECT *
+FROM agent_payouts
+WHERE id = $1
+FOR UPDATE;
+
+
+-- name: ListAgentPayouts :many
+SELECT *
+FROM agent_payouts
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
+
+
+-- name: AttachEntriesToPayout :exec
+UPDATE ledger_entries
+SET payout_id = $1
+WHERE account_id = $2
+  AND payout_id IS NULL;
+
+
+-- name: SettlePayoutEntries :exec
+UPDATE ledger_entries
+SET settled_at = now()
+WHERE payout_id = $1
+  AND settled_at IS NULL;
+
+
+-- name: DetachPayoutEntries :exec
+UPDATE ledger_entries
+SET payout_id = NULL
+WHERE payout_id = $1;
+
+
+-- name: MarkPayoutPaid :one
+UPDATE agent_payouts
+SET status = 'paid',
+    reference = $2,
+    paid_at = now()
+WHERE id = $1
+  AND status = 'pending'
+RETURNING *;
+
+
+-- name: CancelAgentPayout :one
+UPDATE agent_payouts
+SET status = 'cancelled',
+    cancelled_at = now()
+WHERE id = $1
+  AND status = 'pending'
+RETURNING *;
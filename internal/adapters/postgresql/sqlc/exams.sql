@@ -0,0 +1,39 @@
+-- name: CreateExam :one
+INSERT INTO exams (
+  title,
+  description,
+  duration_minutes,
+  created_by
+)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+
+-- name: GetExamByID :one
+SELECT *
+FROM exams
+WHERE id = $1;
+
+
+-- name: ListExams :many
+SELECT *
+FROM exams
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
+
+
+-- name: ListPublishedExams :many
+SELECT *
+FROM exams
+WHERE status = 'published'
+  AND source_exam_body IS NULL
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
+
+
+-- name: UpdateExamStatus :one
+UPDATE exams
+SET status = $2,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,113 @@
+-- name: GetGradingPolicy :one
+SELECT *
+FROM exam_grading_policies
+WHERE exam_id = $1;
+
+
+-- name: UpsertGradingPolicy :one
+INSERT INTO exam_grading_policies (
+  exam_id,
+  negative_marking,
+  partial_credit,
+  section_weights,
+  max_score,
+  double_marking,
+  moderation_threshold
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (exam_id) DO UPDATE
+SET negative_marking = EXCLUDED.negative_marking,
+    partial_credit = EXCLUDED.partial_credit,
+    section_weights = EXCLUDED.section_weights,
+    max_score = EXCLUDED.max_score,
+    double_marking = EXCLUDED.double_marking,
+    moderation_threshold = EXCLUDED.moderation_threshold,
+    updated_at = now()
+RETURNING *;
+
+
+-- name: ListAttemptQuestionScores :many
+SELECT *
+FROM attempt_question_scores
+WHERE attempt_id = $1
+ORDER BY question_id;
+
+
+-- name: UpsertAttemptQuestionScores :exec
+INSERT INTO attempt_question_scores (
+  attempt_id,
+  question_id,
+  score,
+  max_score,
+  outcome
+)
+SELECT @attempt_id::bigint,
+       unnest(@question_ids::bigint[]),
+       unnest(@scores::double precision[]),
+       unnest(@max_scores::double precision[]),
+       unnest(@outcomes::text[])::score_outcome
+ON CONFLICT (attempt_id, question_id) DO UPDATE
+SET score = EXCLUDED.score,
+    max_score = EXCLUDED.max_score,
+    outcome = EXCLUDED.outcome,
+    graded_at = now();
+
+
+-- name: GetAttemptResult :one
+SELECT *
+FROM attempt_results
+WHERE attempt_id = $1;
+
+
+-- name: UpsertAttemptResult :one
+INSERT INTO attempt_results (
+  attempt_id,
+  exam_id,
+  user_id,
+  total_score,
+  max_score,
+  status
+)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (attempt_id) DO UPDATE
+SET total_score = EXCLUDED.total_score,
+    max_score = EXCLUDED.max_score,
+    status = EXCLUDED.status,
+    graded_at = now()
+RETURNING *;
+
+
+-- name: CreateScoreChange :exec
+INSERT INTO score_changes (
+  attempt_id,
+  question_id,
+  old_score,
+  new_score,
+  reason,
+  changed_by
+)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+
+-- name: ListScoreChanges :many
+SELECT *
+FROM score_changes
+WHERE attempt_id = $1
+ORDER BY created_at DESC, id DESC;
+
+
+-- name: GetAttemptQuestionScore :one
+SELECT *
+FROM attempt_question_scores
+WHERE attempt_id = $1
+  AND question_id = $2;
+
+
+-- name: UpdateAttemptQuestionScore :one
+UPDATE attempt_question_scores
+SET score = $3,
+    outcome = $4,
+    graded_at = now()
+WHERE attempt_id = $1
+  AND question_id = $2
+RETURNING *;
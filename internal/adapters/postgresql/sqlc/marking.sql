@@ -0,0 +1,149 @@
+-- name: CreateRubricCriterion :one
+INSERT INTO rubric_criteria (
+  question_id,
+  name,
+  description,
+  max_points,
+  position
+)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+
+-- name: DeleteRubricCriteria :exec
+DELETE FROM rubric_criteria
+WHERE question_id = $1;
+
+
+-- name: ListRubricCriteria :many
+SELECT *
+FROM rubric_criteria
+WHERE question_id = $1
+ORDER BY position, id;
+
+
+-- name: ListPendingResponses :many
+SELECT s.attempt_id,
+       s.question_id,
+       s.max_score,
+       d.type,
+       d.stem,
+       a.answer,
+       (
+         SELECT count(*)
+         FROM manual_marks m
+         WHERE m.attempt_id = s.attempt_id
+           AND m.question_id = s.question_id
+       ) AS marks_count
+FROM attempt_question_scores s
+JOIN exam_attempts t ON t.id = s.attempt_id
+JOIN delivered_questions d ON d.attempt_id = s.attempt_id AND d.question_id = s.question_id
+JOIN attempt_answers a ON a.attempt_id = s.attempt_id AND a.question_id = s.question_id
+LEFT JOIN manual_reviews r ON r.attempt_id = s.attempt_id AND r.question_id = s.question_id
+WHERE t.exam_id = $1
+  AND s.outcome = 'pending'
+  AND COALESCE(r.status, 'awaiting_marks') = 'awaiting_marks'
+  AND NOT EXISTS (
+    SELECT 1
+    FROM manual_marks m
+    WHERE m.attempt_id = s.attempt_id
+      AND m.question_id = s.question_id
+      AND m.grader_id = $2
+  )
+ORDER BY s.attempt_id, s.question_id
+LIMIT $3 OFFSET $4;
+
+
+-- name: ListResponsesForModeration :many
+SELECT s.attempt_id,
+       s.question_id,
+       s.max_score,
+       d.type,
+       d.stem,
+       a.answer
+FROM manual_reviews r
+JOIN attempt_question_scores s ON s.attempt_id = r.attempt_id AND s.question_id = r.question_id
+JOIN exam_attempts t ON t.id = r.attempt_id
+JOIN delivered_questions d ON d.attempt_id = r.attempt_id AND d.question_id = r.question_id
+JOIN attempt_answers a ON a.attempt_id = r.attempt_id AND a.question_id = r.question_id
+WHERE t.exam_id = $1
+  AND r.status = 'needs_moderation'
+ORDER BY r.attempt_id, r.question_id
+LIMIT $2 OFFSET $3;
+
+
+-- name: GetResponseForMarking :one
+SELECT t.exam_id,
+       s.max_score,
+       s.outcome,
+       d.type
+FROM attempt_question_scores s
+JOIN exam_attempts t ON t.id = s.attempt_id
+JOIN delivered_questions d ON d.attempt_id = s.attempt_id AND d.question_id = s.question_id
+WHERE s.attempt_id = $1
+  AND s.question_id = $2;
+
+
+-- name: CreateManualMark :one
+INSERT INTO manual_marks (
+  attempt_id,
+  question_id,
+  grader_id,
+  criteria_scores,
+  score,
+  comment
+)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+
+-- name: ListManualMarks :many
+SELECT *
+FROM manual_marks
+WHERE attempt_id = $1
+  AND question_id = $2
+ORDER BY created_at, id;
+
+
+-- name: GetManualReview :one
+SELECT *
+FROM manual_reviews
+WHERE attempt_id = $1
+  AND question_id = $2;
+
+
+-- name: EnsureManualReview :exec
+INSERT INTO manual_reviews (attempt_id, question_id, status)
+VALUES ($1, $2, 'awaiting_marks')
+ON CONFLICT (attempt_id, question_id) DO NOTHING;
+
+
+-- name: GetManualReviewForUpdate :one
+SELECT *
+FROM manual_reviews
+WHERE attempt_id = $1
+  AND question_id = $2
+FOR UPDATE;
+
+
+-- name: UpsertManualReview :one
+INSERT INTO manual_reviews (
+  attempt_id,
+  question_id,
+  status,
+  final_score,
+  moderator_comment,
+  finalized_by,
+  finalized_at
+)
+VALUES (
+  $1, $2, $3, $4, $5, $6,
+  CASE WHEN $3::manual_review_status = 'finalized' THEN now() END
+)
+ON CONFLICT (attempt_id, question_id) DO UPDATE
+SET status = EXCLUDED.status,
+    final_score = EXCLUDED.final_score,
+    moderator_comment = EXCLUDED.moderator_comment,
+    finalized_by = EXCLUDED.finalized_by,
+    finalized_at = EXCLUDED.finalized_at
+RETURNING *;
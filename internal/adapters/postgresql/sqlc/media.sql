@@ -0,0 +1,27 @@
+-- name: CreateMediaFile :one
+INSERT INTO media_files (
+  checksum,
+  name,
+  content_type,
+  size,
+  storage_key,
+  width,
+  height,
+  created_by
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (checksum) DO UPDATE
+SET checksum = EXCLUDED.checksum
+RETURNING *;
+
+
+-- name: GetMediaFile :one
+SELECT *
+FROM media_files
+WHERE id = $1;
+
+
+-- name: GetMediaFileByChecksum :one
+SELECT *
+FROM media_files
+WHERE checksum = $1;
@@ -11,6 +11,1047 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AbilityEstimator string
+
+const (
+	AbilityEstimatorEap AbilityEstimator = "eap"
+	AbilityEstimatorMle AbilityEstimator = "mle"
+)
+
+func (e *AbilityEstimator) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AbilityEstimator(s)
+	case string:
+		*e = AbilityEstimator(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AbilityEstimator: %T", src)
+	}
+	return nil
+}
+
+type NullAbilityEstimator struct {
+	AbilityEstimator AbilityEstimator `json:"ability_estimator"`
+	Valid            bool             `json:"valid"` // Valid is true if AbilityEstimator is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAbilityEstimator) Scan(value interface{}) error {
+	if value == nil {
+		ns.AbilityEstimator, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AbilityEstimator.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAbilityEstimator) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AbilityEstimator), nil
+}
+
+type AccessTarget string
+
+const (
+	AccessTargetExam         AccessTarget = "exam"
+	AccessTargetPracticePack AccessTarget = "practice_pack"
+	AccessTargetPlan         AccessTarget = "plan"
+)
+
+func (e *AccessTarget) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AccessTarget(s)
+	case string:
+		*e = AccessTarget(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AccessTarget: %T", src)
+	}
+	return nil
+}
+
+type NullAccessTarget struct {
+	AccessTarget AccessTarget `json:"access_target"`
+	Valid        bool         `json:"valid"` // Valid is true if AccessTarget is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAccessTarget) Scan(value interface{}) error {
+	if value == nil {
+		ns.AccessTarget, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AccessTarget.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAccessTarget) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AccessTarget), nil
+}
+
+type AdminPermission string
+
+const (
+	AdminPermissionGrader     AdminPermission = "grader"
+	AdminPermissionModerator  AdminPermission = "moderator"
+	AdminPermissionReviewer   AdminPermission = "reviewer"
+	AdminPermissionSuperAdmin AdminPermission = "super_admin"
+)
+
+func (e *AdminPermission) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AdminPermission(s)
+	case string:
+		*e = AdminPermission(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AdminPermission: %T", src)
+	}
+	return nil
+}
+
+type NullAdminPermission struct {
+	AdminPermission AdminPermission `json:"admin_permission"`
+	Valid           bool            `json:"valid"` // Valid is true if AdminPermission is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAdminPermission) Scan(value interface{}) error {
+	if value == nil {
+		ns.AdminPermission, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AdminPermission.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAdminPermission) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AdminPermission), nil
+}
+
+type AnalysisStatus string
+
+const (
+	AnalysisStatusQueued    AnalysisStatus = "queued"
+	AnalysisStatusRunning   AnalysisStatus = "running"
+	AnalysisStatusCompleted AnalysisStatus = "completed"
+	AnalysisStatusFailed    AnalysisStatus = "failed"
+)
+
+func (e *AnalysisStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AnalysisStatus(s)
+	case string:
+		*e = AnalysisStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AnalysisStatus: %T", src)
+	}
+	return nil
+}
+
+type NullAnalysisStatus struct {
+	AnalysisStatus AnalysisStatus `json:"analysis_status"`
+	Valid          bool           `json:"valid"` // Valid is true if AnalysisStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAnalysisStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.AnalysisStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AnalysisStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAnalysisStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AnalysisStatus), nil
+}
+
+type AttemptStatus string
+
+const (
+	AttemptStatusInProgress AttemptStatus = "in_progress"
+	AttemptStatusSubmitted  AttemptStatus = "submitted"
+)
+
+func (e *AttemptStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AttemptStatus(s)
+	case string:
+		*e = AttemptStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AttemptStatus: %T", src)
+	}
+	return nil
+}
+
+type NullAttemptStatus struct {
+	AttemptStatus AttemptStatus `json:"attempt_status"`
+	Valid         bool          `json:"valid"` // Valid is true if AttemptStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAttemptStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.AttemptStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AttemptStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAttemptStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AttemptStatus), nil
+}
+
+type CommissionProduct string
+
+const (
+	CommissionProductExam         CommissionProduct = "exam"
+	CommissionProductPracticePack CommissionProduct = "practice_pack"
+	CommissionProductRegistration CommissionProduct = "registration"
+)
+
+func (e *CommissionProduct) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = CommissionProduct(s)
+	case string:
+		*e = CommissionProduct(s)
+	default:
+		return fmt.Errorf("unsupported scan type for CommissionProduct: %T", src)
+	}
+	return nil
+}
+
+type NullCommissionProduct struct {
+	CommissionProduct CommissionProduct `json:"commission_product"`
+	Valid             bool              `json:"valid"` // Valid is true if CommissionProduct is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullCommissionProduct) Scan(value interface{}) error {
+	if value == nil {
+		ns.CommissionProduct, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.CommissionProduct.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullCommissionProduct) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.CommissionProduct), nil
+}
+
+type DuplicateStatus string
+
+const (
+	DuplicateStatusOpen      DuplicateStatus = "open"
+	DuplicateStatusMerged    DuplicateStatus = "merged"
+	DuplicateStatusDismissed DuplicateStatus = "dismissed"
+)
+
+func (e *DuplicateStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DuplicateStatus(s)
+	case string:
+		*e = DuplicateStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DuplicateStatus: %T", src)
+	}
+	return nil
+}
+
+type NullDuplicateStatus struct {
+	DuplicateStatus DuplicateStatus `json:"duplicate_status"`
+	Valid           bool            `json:"valid"` // Valid is true if DuplicateStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDuplicateStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.DuplicateStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DuplicateStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDuplicateStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DuplicateStatus), nil
+}
+
+type ExamStatus string
+
+const (
+	ExamStatusDraft     ExamStatus = "draft"
+	ExamStatusPublished ExamStatus = "published"
+	ExamStatusArchived  ExamStatus = "archived"
+)
+
+func (e *ExamStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ExamStatus(s)
+	case string:
+		*e = ExamStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ExamStatus: %T", src)
+	}
+	return nil
+}
+
+type NullExamStatus struct {
+	ExamStatus ExamStatus `json:"exam_status"`
+	Valid      bool       `json:"valid"` // Valid is true if ExamStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullExamStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ExamStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ExamStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullExamStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ExamStatus), nil
+}
+
+type IrtModel string
+
+const (
+	IrtModel1pl IrtModel = "1pl"
+	IrtModel2pl IrtModel = "2pl"
+	IrtModel3pl IrtModel = "3pl"
+)
+
+func (e *IrtModel) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = IrtModel(s)
+	case string:
+		*e = IrtModel(s)
+	default:
+		return fmt.Errorf("unsupported scan type for IrtModel: %T", src)
+	}
+	return nil
+}
+
+type NullIrtModel struct {
+	IrtModel IrtModel `json:"irt_model"`
+	Valid    bool     `json:"valid"` // Valid is true if IrtModel is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullIrtModel) Scan(value interface{}) error {
+	if value == nil {
+		ns.IrtModel, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.IrtModel.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullIrtModel) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.IrtModel), nil
+}
+
+type LedgerAccountType string
+
+const (
+	LedgerAccountTypeAsset     LedgerAccountType = "asset"
+	LedgerAccountTypeLiability LedgerAccountType = "liability"
+	LedgerAccountTypeExpense   LedgerAccountType = "expense"
+)
+
+func (e *LedgerAccountType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = LedgerAccountType(s)
+	case string:
+		*e = LedgerAccountType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for LedgerAccountType: %T", src)
+	}
+	return nil
+}
+
+type NullLedgerAccountType struct {
+	LedgerAccountType LedgerAccountType `json:"ledger_account_type"`
+	Valid             bool              `json:"valid"` // Valid is true if LedgerAccountType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullLedgerAccountType) Scan(value interface{}) error {
+	if value == nil {
+		ns.LedgerAccountType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.LedgerAccountType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullLedgerAccountType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.LedgerAccountType), nil
+}
+
+type LedgerKind string
+
+const (
+	LedgerKindEarning    LedgerKind = "earning"
+	LedgerKindAdjustment LedgerKind = "adjustment"
+	LedgerKindPayout     LedgerKind = "payout"
+)
+
+func (e *LedgerKind) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = LedgerKind(s)
+	case string:
+		*e = LedgerKind(s)
+	default:
+		return fmt.Errorf("unsupported scan type for LedgerKind: %T", src)
+	}
+	return nil
+}
+
+type NullLedgerKind struct {
+	LedgerKind LedgerKind `json:"ledger_kind"`
+	Valid      bool       `json:"valid"` // Valid is true if LedgerKind is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullLedgerKind) Scan(value interface{}) error {
+	if value == nil {
+		ns.LedgerKind, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.LedgerKind.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullLedgerKind) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.LedgerKind), nil
+}
+
+type ManualReviewStatus string
+
+const (
+	ManualReviewStatusAwaitingMarks   ManualReviewStatus = "awaiting_marks"
+	ManualReviewStatusNeedsModeration ManualReviewStatus = "needs_moderation"
+	ManualReviewStatusFinalized       ManualReviewStatus = "finalized"
+)
+
+func (e *ManualReviewStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ManualReviewStatus(s)
+	case string:
+		*e = ManualReviewStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ManualReviewStatus: %T", src)
+	}
+	return nil
+}
+
+type NullManualReviewStatus struct {
+	ManualReviewStatus ManualReviewStatus `json:"manual_review_status"`
+	Valid              bool               `json:"valid"` // Valid is true if ManualReviewStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullManualReviewStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ManualReviewStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ManualReviewStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullManualReviewStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ManualReviewStatus), nil
+}
+
+type OrderStatus string
+
+const (
+	OrderStatusPending   OrderStatus = "pending"
+	OrderStatusPaid      OrderStatus = "paid"
+	OrderStatusFailed    OrderStatus = "failed"
+	OrderStatusCancelled OrderStatus = "cancelled"
+	OrderStatusRefunded  OrderStatus = "refunded"
+)
+
+func (e *OrderStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrderStatus(s)
+	case string:
+		*e = OrderStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrderStatus: %T", src)
+	}
+	return nil
+}
+
+type NullOrderStatus struct {
+	OrderStatus OrderStatus `json:"order_status"`
+	Valid       bool        `json:"valid"` // Valid is true if OrderStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrderStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrderStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrderStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrderStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrderStatus), nil
+}
+
+type PayoutStatus string
+
+const (
+	PayoutStatusPending   PayoutStatus = "pending"
+	PayoutStatusPaid      PayoutStatus = "paid"
+	PayoutStatusCancelled PayoutStatus = "cancelled"
+)
+
+func (e *PayoutStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PayoutStatus(s)
+	case string:
+		*e = PayoutStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PayoutStatus: %T", src)
+	}
+	return nil
+}
+
+type NullPayoutStatus struct {
+	PayoutStatus PayoutStatus `json:"payout_status"`
+	Valid        bool         `json:"valid"` // Valid is true if PayoutStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPayoutStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.PayoutStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PayoutStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPayoutStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PayoutStatus), nil
+}
+
+type PracticeSessionStatus string
+
+const (
+	PracticeSessionStatusInProgress PracticeSessionStatus = "in_progress"
+	PracticeSessionStatusCompleted  PracticeSessionStatus = "completed"
+)
+
+func (e *PracticeSessionStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PracticeSessionStatus(s)
+	case string:
+		*e = PracticeSessionStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PracticeSessionStatus: %T", src)
+	}
+	return nil
+}
+
+type NullPracticeSessionStatus struct {
+	PracticeSessionStatus PracticeSessionStatus `json:"practice_session_status"`
+	Valid                 bool                  `json:"valid"` // Valid is true if PracticeSessionStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPracticeSessionStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.PracticeSessionStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PracticeSessionStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPracticeSessionStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PracticeSessionStatus), nil
+}
+
+type ProctorAction string
+
+const (
+	ProctorActionFlag   ProctorAction = "flag"
+	ProctorActionSubmit ProctorAction = "submit"
+)
+
+func (e *ProctorAction) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ProctorAction(s)
+	case string:
+		*e = ProctorAction(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ProctorAction: %T", src)
+	}
+	return nil
+}
+
+type NullProctorAction struct {
+	ProctorAction ProctorAction `json:"proctor_action"`
+	Valid         bool          `json:"valid"` // Valid is true if ProctorAction is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullProctorAction) Scan(value interface{}) error {
+	if value == nil {
+		ns.ProctorAction, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ProctorAction.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullProctorAction) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ProctorAction), nil
+}
+
+type ProctorEventType string
+
+const (
+	ProctorEventTypeTabSwitch      ProctorEventType = "tab_switch"
+	ProctorEventTypeFullscreenExit ProctorEventType = "fullscreen_exit"
+	ProctorEventTypeCopy           ProctorEventType = "copy"
+	ProctorEventTypePaste          ProctorEventType = "paste"
+	ProctorEventTypeWindowBlur     ProctorEventType = "window_blur"
+	ProctorEventTypeMultipleFaces  ProctorEventType = "multiple_faces"
+)
+
+func (e *ProctorEventType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ProctorEventType(s)
+	case string:
+		*e = ProctorEventType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ProctorEventType: %T", src)
+	}
+	return nil
+}
+
+type NullProctorEventType struct {
+	ProctorEventType ProctorEventType `json:"proctor_event_type"`
+	Valid            bool             `json:"valid"` // Valid is true if ProctorEventType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullProctorEventType) Scan(value interface{}) error {
+	if value == nil {
+		ns.ProctorEventType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ProctorEventType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullProctorEventType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ProctorEventType), nil
+}
+
+type QuestionDifficulty string
+
+const (
+	QuestionDifficultyEasy   QuestionDifficulty = "easy"
+	QuestionDifficultyMedium QuestionDifficulty = "medium"
+	QuestionDifficultyHard   QuestionDifficulty = "hard"
+)
+
+func (e *QuestionDifficulty) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = QuestionDifficulty(s)
+	case string:
+		*e = QuestionDifficulty(s)
+	default:
+		return fmt.Errorf("unsupported scan type for QuestionDifficulty: %T", src)
+	}
+	return nil
+}
+
+type NullQuestionDifficulty struct {
+	QuestionDifficulty QuestionDifficulty `json:"question_difficulty"`
+	Valid              bool               `json:"valid"` // Valid is true if QuestionDifficulty is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullQuestionDifficulty) Scan(value interface{}) error {
+	if value == nil {
+		ns.QuestionDifficulty, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.QuestionDifficulty.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullQuestionDifficulty) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.QuestionDifficulty), nil
+}
+
+type QuestionStatus string
+
+const (
+	QuestionStatusDraft    QuestionStatus = "draft"
+	QuestionStatusInReview QuestionStatus = "in_review"
+	QuestionStatusApproved QuestionStatus = "approved"
+	QuestionStatusRejected QuestionStatus = "rejected"
+	QuestionStatusRetired  QuestionStatus = "retired"
+)
+
+func (e *QuestionStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = QuestionStatus(s)
+	case string:
+		*e = QuestionStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for QuestionStatus: %T", src)
+	}
+	return nil
+}
+
+type NullQuestionStatus struct {
+	QuestionStatus QuestionStatus `json:"question_status"`
+	Valid          bool           `json:"valid"` // Valid is true if QuestionStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullQuestionStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.QuestionStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.QuestionStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullQuestionStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.QuestionStatus), nil
+}
+
+type QuestionType string
+
+const (
+	QuestionTypeSingleChoice   QuestionType = "single_choice"
+	QuestionTypeMultipleChoice QuestionType = "multiple_choice"
+	QuestionTypeTrueFalse      QuestionType = "true_false"
+	QuestionTypeNumeric        QuestionType = "numeric"
+	QuestionTypeMatching       QuestionType = "matching"
+	QuestionTypeShortAnswer    QuestionType = "short_answer"
+	QuestionTypeEssay          QuestionType = "essay"
+)
+
+func (e *QuestionType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = QuestionType(s)
+	case string:
+		*e = QuestionType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for QuestionType: %T", src)
+	}
+	return nil
+}
+
+type NullQuestionType struct {
+	QuestionType QuestionType `json:"question_type"`
+	Valid        bool         `json:"valid"` // Valid is true if QuestionType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullQuestionType) Scan(value interface{}) error {
+	if value == nil {
+		ns.QuestionType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.QuestionType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullQuestionType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.QuestionType), nil
+}
+
+type ResultReleaseMode string
+
+const (
+	ResultReleaseModeImmediate ResultReleaseMode = "immediate"
+	ResultReleaseModeScheduled ResultReleaseMode = "scheduled"
+	ResultReleaseModeManual    ResultReleaseMode = "manual"
+)
+
+func (e *ResultReleaseMode) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ResultReleaseMode(s)
+	case string:
+		*e = ResultReleaseMode(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ResultReleaseMode: %T", src)
+	}
+	return nil
+}
+
+type NullResultReleaseMode struct {
+	ResultReleaseMode ResultReleaseMode `json:"result_release_mode"`
+	Valid             bool              `json:"valid"` // Valid is true if ResultReleaseMode is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullResultReleaseMode) Scan(value interface{}) error {
+	if value == nil {
+		ns.ResultReleaseMode, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ResultReleaseMode.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullResultReleaseMode) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ResultReleaseMode), nil
+}
+
+type ResultStatus string
+
+const (
+	ResultStatusPendingManual ResultStatus = "pending_manual"
+	ResultStatusGraded        ResultStatus = "graded"
+)
+
+func (e *ResultStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ResultStatus(s)
+	case string:
+		*e = ResultStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ResultStatus: %T", src)
+	}
+	return nil
+}
+
+type NullResultStatus struct {
+	ResultStatus ResultStatus `json:"result_status"`
+	Valid        bool         `json:"valid"` // Valid is true if ResultStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullResultStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ResultStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ResultStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullResultStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ResultStatus), nil
+}
+
+type ReviewDecision string
+
+const (
+	ReviewDecisionApproved ReviewDecision = "approved"
+	ReviewDecisionRejected ReviewDecision = "rejected"
+)
+
+func (e *ReviewDecision) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ReviewDecision(s)
+	case string:
+		*e = ReviewDecision(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ReviewDecision: %T", src)
+	}
+	return nil
+}
+
+type NullReviewDecision struct {
+	ReviewDecision ReviewDecision `json:"review_decision"`
+	Valid          bool           `json:"valid"` // Valid is true if ReviewDecision is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullReviewDecision) Scan(value interface{}) error {
+	if value == nil {
+		ns.ReviewDecision, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ReviewDecision.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullReviewDecision) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ReviewDecision), nil
+}
+
+type ScoreOutcome string
+
+const (
+	ScoreOutcomeCorrect    ScoreOutcome = "correct"
+	ScoreOutcomePartial    ScoreOutcome = "partial"
+	ScoreOutcomeIncorrect  ScoreOutcome = "incorrect"
+	ScoreOutcomeUnanswered ScoreOutcome = "unanswered"
+	ScoreOutcomePending    ScoreOutcome = "pending"
+)
+
+func (e *ScoreOutcome) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ScoreOutcome(s)
+	case string:
+		*e = ScoreOutcome(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ScoreOutcome: %T", src)
+	}
+	return nil
+}
+
+type NullScoreOutcome struct {
+	ScoreOutcome ScoreOutcome `json:"score_outcome"`
+	Valid        bool         `json:"valid"` // Valid is true if ScoreOutcome is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullScoreOutcome) Scan(value interface{}) error {
+	if value == nil {
+		ns.ScoreOutcome, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ScoreOutcome.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullScoreOutcome) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ScoreOutcome), nil
+}
+
 type UserRole string
 
 const (
@@ -97,6 +1138,680 @@ func (ns NullUserStatus) Value() (driver.Value, error) {
 	return string(ns.UserStatus), nil
 }
 
+type VoucherStatus string
+
+const (
+	VoucherStatusActive VoucherStatus = "active"
+	VoucherStatusVoid   VoucherStatus = "void"
+)
+
+func (e *VoucherStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = VoucherStatus(s)
+	case string:
+		*e = VoucherStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for VoucherStatus: %T", src)
+	}
+	return nil
+}
+
+type NullVoucherStatus struct {
+	VoucherStatus VoucherStatus `json:"voucher_status"`
+	Valid         bool          `json:"valid"` // Valid is true if VoucherStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullVoucherStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.VoucherStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.VoucherStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullVoucherStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.VoucherStatus), nil
+}
+
+type AccessGrant struct {
+	ID         int64              `json:"id"`
+	UserID     int64              `json:"user_id"`
+	TargetType AccessTarget       `json:"target_type"`
+	TargetID   int64              `json:"target_id"`
+	Source     string             `json:"source"`
+	SourceID   pgtype.Int8        `json:"source_id"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+	GrantedAt  pgtype.Timestamptz `json:"granted_at"`
+}
+
+type AdaptiveExposure struct {
+	ExamID       int64 `json:"exam_id"`
+	QuestionID   int64 `json:"question_id"`
+	Administered int32 `json:"administered"`
+}
+
+type AdaptiveResponse struct {
+	AttemptID  int64              `json:"attempt_id"`
+	QuestionID int64              `json:"question_id"`
+	Seq        int32              `json:"seq"`
+	Section    string             `json:"section"`
+	IrtA       float64            `json:"irt_a"`
+	IrtB       float64            `json:"irt_b"`
+	IrtC       float64            `json:"irt_c"`
+	Answer     []byte             `json:"answer"`
+	Fraction   float64            `json:"fraction"`
+	Theta      float64            `json:"theta"`
+	Se         float64            `json:"se"`
+	AnsweredAt pgtype.Timestamptz `json:"answered_at"`
+}
+
+type AdaptiveState struct {
+	AttemptID         int64              `json:"attempt_id"`
+	ExamID            int64              `json:"exam_id"`
+	Theta             float64            `json:"theta"`
+	Se                float64            `json:"se"`
+	ScaledScore       float64            `json:"scaled_score"`
+	Items             int32              `json:"items"`
+	CurrentQuestionID pgtype.Int8        `json:"current_question_id"`
+	FinishedAt        pgtype.Timestamptz `json:"finished_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+}
+
+type AgentPayout struct {
+	ID             int64              `json:"id"`
+	AgentID        int64              `json:"agent_id"`
+	Amount         int64              `json:"amount"`
+	Status         PayoutStatus       `json:"status"`
+	IdempotencyKey string             `json:"idempotency_key"`
+	Reference      pgtype.Text        `json:"reference"`
+	CreatedBy      int64              `json:"created_by"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	PaidAt         pgtype.Timestamptz `json:"paid_at"`
+	CancelledAt    pgtype.Timestamptz `json:"cancelled_at"`
+}
+
+type AttemptAnswer struct {
+	AttemptID  int64              `json:"attempt_id"`
+	QuestionID int64              `json:"question_id"`
+	Answer     []byte             `json:"answer"`
+	Version    int64              `json:"version"`
+	SavedAt    pgtype.Timestamptz `json:"saved_at"`
+}
+
+type AttemptProctorAction struct {
+	AttemptID  int64              `json:"attempt_id"`
+	EventType  ProctorEventType   `json:"event_type"`
+	Action     ProctorAction      `json:"action"`
+	EventCount int32              `json:"event_count"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type AttemptProctorCount struct {
+	AttemptID int64              `json:"attempt_id"`
+	EventType ProctorEventType   `json:"event_type"`
+	Count     int32              `json:"count"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type AttemptQuestionScore struct {
+	AttemptID  int64              `json:"attempt_id"`
+	QuestionID int64              `json:"question_id"`
+	Score      float64            `json:"score"`
+	MaxScore   float64            `json:"max_score"`
+	Outcome    ScoreOutcome       `json:"outcome"`
+	GradedAt   pgtype.Timestamptz `json:"graded_at"`
+}
+
+type AttemptQuestionVersion struct {
+	AttemptID  int64 `json:"attempt_id"`
+	QuestionID int64 `json:"question_id"`
+	Version    int32 `json:"version"`
+}
+
+type AttemptResult struct {
+	AttemptID  int64              `json:"attempt_id"`
+	ExamID     int64              `json:"exam_id"`
+	UserID     int64              `json:"user_id"`
+	TotalScore float64            `json:"total_score"`
+	MaxScore   float64            `json:"max_score"`
+	Status     ResultStatus       `json:"status"`
+	GradedAt   pgtype.Timestamptz `json:"graded_at"`
+}
+
+type AttemptSubjectScore struct {
+	AttemptID   int64              `json:"attempt_id"`
+	Subject     string             `json:"subject"`
+	RawScore    float64            `json:"raw_score"`
+	RawMax      float64            `json:"raw_max"`
+	ScaledScore float64            `json:"scaled_score"`
+	ScaledMax   float64            `json:"scaled_max"`
+	GradedAt    pgtype.Timestamptz `json:"graded_at"`
+}
+
+type CandidateGroup struct {
+	ID          int64              `json:"id"`
+	Name        string             `json:"name"`
+	Description pgtype.Text        `json:"description"`
+	CreatedBy   int64              `json:"created_by"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type CandidateGroupMember struct {
+	GroupID int64              `json:"group_id"`
+	UserID  int64              `json:"user_id"`
+	AddedAt pgtype.Timestamptz `json:"added_at"`
+}
+
+type CommissionRule struct {
+	ID         int64              `json:"id"`
+	Product    CommissionProduct  `json:"product"`
+	ProductID  pgtype.Int8        `json:"product_id"`
+	RateBps    int32              `json:"rate_bps"`
+	FlatAmount int64              `json:"flat_amount"`
+	CreatedBy  int64              `json:"created_by"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+}
+
+type DeliveredQuestion struct {
+	AttemptID   int64        `json:"attempt_id"`
+	QuestionID  int64        `json:"question_id"`
+	Version     int32        `json:"version"`
+	Type        QuestionType `json:"type"`
+	Stem        string       `json:"stem"`
+	Options     []byte       `json:"options"`
+	AnswerKey   []byte       `json:"answer_key"`
+	Explanation pgtype.Text  `json:"explanation"`
+	Marks       float64      `json:"marks"`
+}
+
+type DuplicateFlag struct {
+	ID          int64              `json:"id"`
+	QuestionID  int64              `json:"question_id"`
+	DuplicateOf int64              `json:"duplicate_of"`
+	Similarity  float64            `json:"similarity"`
+	Status      DuplicateStatus    `json:"status"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	ResolvedBy  pgtype.Int8        `json:"resolved_by"`
+	ResolvedAt  pgtype.Timestamptz `json:"resolved_at"`
+}
+
+type Exam struct {
+	ID              int64              `json:"id"`
+	Title           string             `json:"title"`
+	Description     pgtype.Text        `json:"description"`
+	DurationMinutes int32              `json:"duration_minutes"`
+	Status          ExamStatus         `json:"status"`
+	CreatedBy       int64              `json:"created_by"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	SourceExamBody  pgtype.Text        `json:"source_exam_body"`
+	SourceYear      pgtype.Int4        `json:"source_year"`
+	SourcePaper     pgtype.Int4        `json:"source_paper"`
+	ElectiveCount   int32              `json:"elective_count"`
+}
+
+type ExamAdaptiveSetting struct {
+	ExamID          int64              `json:"exam_id"`
+	Estimator       AbilityEstimator   `json:"estimator"`
+	MinItems        int32              `json:"min_items"`
+	MaxItems        int32              `json:"max_items"`
+	SeThreshold     float64            `json:"se_threshold"`
+	Randomesque     int32              `json:"randomesque"`
+	MaxExposureRate float64            `json:"max_exposure_rate"`
+	ContentTargets  []byte             `json:"content_targets"`
+	ScaleMean       float64            `json:"scale_mean"`
+	ScaleSd         float64            `json:"scale_sd"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+}
+
+type ExamAssignment struct {
+	ID        int64              `json:"id"`
+	ExamID    int64              `json:"exam_id"`
+	UserID    pgtype.Int8        `json:"user_id"`
+	GroupID   pgtype.Int8        `json:"group_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type ExamAttempt struct {
+	ID          int64              `json:"id"`
+	ExamID      int64              `json:"exam_id"`
+	UserID      int64              `json:"user_id"`
+	Status      AttemptStatus      `json:"status"`
+	StartedAt   pgtype.Timestamptz `json:"started_at"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+	SubmittedAt pgtype.Timestamptz `json:"submitted_at"`
+	Course      pgtype.Text        `json:"course"`
+	Subjects    []string           `json:"subjects"`
+}
+
+type ExamEligibilityRule struct {
+	ExamID                  int64              `json:"exam_id"`
+	Schools                 []string           `json:"schools"`
+	States                  []string           `json:"states"`
+	RequireProfileCompleted bool               `json:"require_profile_completed"`
+	RequireEmailVerified    bool               `json:"require_email_verified"`
+	UpdatedAt               pgtype.Timestamptz `json:"updated_at"`
+}
+
+type ExamGradingPolicy struct {
+	ExamID              int64              `json:"exam_id"`
+	NegativeMarking     float64            `json:"negative_marking"`
+	PartialCredit       bool               `json:"partial_credit"`
+	SectionWeights      []byte             `json:"section_weights"`
+	MaxScore            pgtype.Float8      `json:"max_score"`
+	UpdatedAt           pgtype.Timestamptz `json:"updated_at"`
+	DoubleMarking       bool               `json:"double_marking"`
+	ModerationThreshold float64            `json:"moderation_threshold"`
+}
+
+type ExamProctoringRule struct {
+	ExamID      int64            `json:"exam_id"`
+	EventType   ProctorEventType `json:"event_type"`
+	FlagAfter   pgtype.Int4      `json:"flag_after"`
+	SubmitAfter pgtype.Int4      `json:"submit_after"`
+}
+
+type ExamQuestion struct {
+	ExamID     int64         `json:"exam_id"`
+	QuestionID int64         `json:"question_id"`
+	Section    string        `json:"section"`
+	Position   int32         `json:"position"`
+	Marks      pgtype.Float8 `json:"marks"`
+}
+
+type ExamResultSetting struct {
+	ExamID            int64              `json:"exam_id"`
+	ReleaseMode       ResultReleaseMode  `json:"release_mode"`
+	ReleaseAt         pgtype.Timestamptz `json:"release_at"`
+	ReleasedAt        pgtype.Timestamptz `json:"released_at"`
+	PassMark          pgtype.Float8      `json:"pass_mark"`
+	AllowAnswerReview bool               `json:"allow_answer_review"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+}
+
+type ExamSchedule struct {
+	ExamID                int64              `json:"exam_id"`
+	OpensAt               pgtype.Timestamptz `json:"opens_at"`
+	ClosesAt              pgtype.Timestamptz `json:"closes_at"`
+	TimeZone              string             `json:"time_zone"`
+	MaxAttempts           pgtype.Int4        `json:"max_attempts"`
+	RetakeCooldownMinutes int32              `json:"retake_cooldown_minutes"`
+	AssignedOnly          bool               `json:"assigned_only"`
+	UpdatedAt             pgtype.Timestamptz `json:"updated_at"`
+	RequiresAccess        bool               `json:"requires_access"`
+}
+
+type ExamSubject struct {
+	ExamID    int64   `json:"exam_id"`
+	Subject   string  `json:"subject"`
+	Required  bool    `json:"required"`
+	ScaledMax float64 `json:"scaled_max"`
+	Position  int32   `json:"position"`
+}
+
+type ExamSubjectCombination struct {
+	ID        int64              `json:"id"`
+	ExamID    int64              `json:"exam_id"`
+	Course    string             `json:"course"`
+	Electives []string           `json:"electives"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type IrtCalibration struct {
+	ID            int64              `json:"id"`
+	Model         IrtModel           `json:"model"`
+	ExamID        pgtype.Int8        `json:"exam_id"`
+	Respondents   int32              `json:"respondents"`
+	Items         int32              `json:"items"`
+	Cycles        int32              `json:"cycles"`
+	Converged     bool               `json:"converged"`
+	LogLikelihood float64            `json:"log_likelihood"`
+	Aic           float64            `json:"aic"`
+	Bic           float64            `json:"bic"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type ItemAnalysisRun struct {
+	ID          int64              `json:"id"`
+	ExamID      int64              `json:"exam_id"`
+	Status      AnalysisStatus     `json:"status"`
+	RequestedBy pgtype.Int8        `json:"requested_by"`
+	Attempts    int32              `json:"attempts"`
+	Items       int32              `json:"items"`
+	MeanScore   pgtype.Float8      `json:"mean_score"`
+	SdScore     pgtype.Float8      `json:"sd_score"`
+	Kr20        pgtype.Float8      `json:"kr20"`
+	Alpha       pgtype.Float8      `json:"alpha"`
+	Error       pgtype.Text        `json:"error"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	StartedAt   pgtype.Timestamptz `json:"started_at"`
+	CompletedAt pgtype.Timestamptz `json:"completed_at"`
+}
+
+type ItemCalibration struct {
+	CalibrationID int64       `json:"calibration_id"`
+	QuestionID    int64       `json:"question_id"`
+	IrtA          float64     `json:"irt_a"`
+	IrtB          float64     `json:"irt_b"`
+	IrtC          float64     `json:"irt_c"`
+	SeA           float64     `json:"se_a"`
+	SeB           float64     `json:"se_b"`
+	SeC           float64     `json:"se_c"`
+	Rmsd          float64     `json:"rmsd"`
+	Responses     int32       `json:"responses"`
+	Converged     bool        `json:"converged"`
+	Reason        pgtype.Text `json:"reason"`
+	Applied       bool        `json:"applied"`
+}
+
+type ItemDistractor struct {
+	RunID           int64         `json:"run_id"`
+	QuestionID      int64         `json:"question_id"`
+	Option          string        `json:"option"`
+	IsKey           bool          `json:"is_key"`
+	Chosen          int32         `json:"chosen"`
+	Proportion      float64       `json:"proportion"`
+	UpperProportion float64       `json:"upper_proportion"`
+	LowerProportion float64       `json:"lower_proportion"`
+	PointBiserial   pgtype.Float8 `json:"point_biserial"`
+}
+
+type ItemStatistic struct {
+	RunID          int64         `json:"run_id"`
+	QuestionID     int64         `json:"question_id"`
+	Section        string        `json:"section"`
+	Position       int32         `json:"position"`
+	Responses      int32         `json:"responses"`
+	Omitted        int32         `json:"omitted"`
+	PValue         float64       `json:"p_value"`
+	Discrimination float64       `json:"discrimination"`
+	PointBiserial  pgtype.Float8 `json:"point_biserial"`
+	AlphaIfDeleted pgtype.Float8 `json:"alpha_if_deleted"`
+	Flags          []string      `json:"flags"`
+}
+
+type LedgerAccount struct {
+	ID        int64              `json:"id"`
+	Code      string             `json:"code"`
+	Name      string             `json:"name"`
+	Type      LedgerAccountType  `json:"type"`
+	AgentID   pgtype.Int8        `json:"agent_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type LedgerEntry struct {
+	ID            int64              `json:"id"`
+	TransactionID int64              `json:"transaction_id"`
+	AccountID     int64              `json:"account_id"`
+	Debit         int64              `json:"debit"`
+	Credit        int64              `json:"credit"`
+	PayoutID      pgtype.Int8        `json:"payout_id"`
+	SettledAt     pgtype.Timestamptz `json:"settled_at"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type LedgerTransaction struct {
+	ID             int64              `json:"id"`
+	IdempotencyKey string             `json:"idempotency_key"`
+	Kind           LedgerKind         `json:"kind"`
+	AgentID        int64              `json:"agent_id"`
+	Description    string             `json:"description"`
+	ReferenceType  pgtype.Text        `json:"reference_type"`
+	ReferenceID    pgtype.Int8        `json:"reference_id"`
+	CreatedBy      pgtype.Int8        `json:"created_by"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
+type ManualMark struct {
+	ID             int64              `json:"id"`
+	AttemptID      int64              `json:"attempt_id"`
+	QuestionID     int64              `json:"question_id"`
+	GraderID       int64              `json:"grader_id"`
+	CriteriaScores []byte             `json:"criteria_scores"`
+	Score          float64            `json:"score"`
+	Comment        pgtype.Text        `json:"comment"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
+type ManualReview struct {
+	AttemptID        int64              `json:"attempt_id"`
+	QuestionID       int64              `json:"question_id"`
+	Status           ManualReviewStatus `json:"status"`
+	FinalScore       pgtype.Float8      `json:"final_score"`
+	ModeratorComment pgtype.Text        `json:"moderator_comment"`
+	FinalizedBy      pgtype.Int8        `json:"finalized_by"`
+	FinalizedAt      pgtype.Timestamptz `json:"finalized_at"`
+}
+
+type MediaFile struct {
+	ID          int64              `json:"id"`
+	Checksum    string             `json:"checksum"`
+	Name        string             `json:"name"`
+	ContentType string             `json:"content_type"`
+	Size        int64              `json:"size"`
+	Data        []byte             `json:"data"`
+	CreatedBy   int64              `json:"created_by"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	StorageKey  pgtype.Text        `json:"storage_key"`
+	Width       pgtype.Int4        `json:"width"`
+	Height      pgtype.Int4        `json:"height"`
+}
+
+type Order struct {
+	ID                int64              `json:"id"`
+	Reference         string             `json:"reference"`
+	UserID            int64              `json:"user_id"`
+	TargetType        AccessTarget       `json:"target_type"`
+	TargetID          int64              `json:"target_id"`
+	Amount            int64              `json:"amount"`
+	Currency          string             `json:"currency"`
+	Provider          string             `json:"provider"`
+	ProviderReference pgtype.Text        `json:"provider_reference"`
+	AuthorizationUrl  pgtype.Text        `json:"authorization_url"`
+	Status            OrderStatus        `json:"status"`
+	FailureReason     pgtype.Text        `json:"failure_reason"`
+	PaidAt            pgtype.Timestamptz `json:"paid_at"`
+	RefundedAt        pgtype.Timestamptz `json:"refunded_at"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+}
+
+type PaymentWebhookEvent struct {
+	Provider       string             `json:"provider"`
+	EventID        string             `json:"event_id"`
+	EventType      string             `json:"event_type"`
+	OrderReference pgtype.Text        `json:"order_reference"`
+	ReceivedAt     pgtype.Timestamptz `json:"received_at"`
+}
+
+type Plan struct {
+	ID          int64              `json:"id"`
+	Name        string             `json:"name"`
+	Description pgtype.Text        `json:"description"`
+	PeriodDays  int32              `json:"period_days"`
+	GraceDays   int32              `json:"grace_days"`
+	Active      bool               `json:"active"`
+	CreatedBy   int64              `json:"created_by"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type PlanItem struct {
+	PlanID     int64        `json:"plan_id"`
+	TargetType AccessTarget `json:"target_type"`
+	TargetID   int64        `json:"target_id"`
+}
+
+type PracticePack struct {
+	ID          int64              `json:"id"`
+	Name        string             `json:"name"`
+	Subject     string             `json:"subject"`
+	Description pgtype.Text        `json:"description"`
+	CreatedBy   int64              `json:"created_by"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type PracticeSession struct {
+	ID            int64                 `json:"id"`
+	UserID        int64                 `json:"user_id"`
+	PackID        int64                 `json:"pack_id"`
+	Topics        []string              `json:"topics"`
+	QuestionCount int32                 `json:"question_count"`
+	AnsweredCount int32                 `json:"answered_count"`
+	CorrectCount  int32                 `json:"correct_count"`
+	CurrentRun    int32                 `json:"current_run"`
+	BestRun       int32                 `json:"best_run"`
+	Status        PracticeSessionStatus `json:"status"`
+	StartedAt     pgtype.Timestamptz    `json:"started_at"`
+	CompletedAt   pgtype.Timestamptz    `json:"completed_at"`
+}
+
+type PracticeSessionQuestion struct {
+	SessionID  int64              `json:"session_id"`
+	QuestionID int64              `json:"question_id"`
+	Position   int32              `json:"position"`
+	Answer     []byte             `json:"answer"`
+	Fraction   pgtype.Float8      `json:"fraction"`
+	AnsweredAt pgtype.Timestamptz `json:"answered_at"`
+}
+
+type PracticeStreak struct {
+	UserID           int64       `json:"user_id"`
+	CurrentStreak    int32       `json:"current_streak"`
+	LongestStreak    int32       `json:"longest_streak"`
+	LastPracticeDate pgtype.Date `json:"last_practice_date"`
+}
+
+type ProctorEvent struct {
+	AttemptID  int64              `json:"attempt_id"`
+	EventType  ProctorEventType   `json:"event_type"`
+	OccurredAt pgtype.Timestamptz `json:"occurred_at"`
+	ReceivedAt pgtype.Timestamptz `json:"received_at"`
+	Details    []byte             `json:"details"`
+}
+
+type ProductPrice struct {
+	TargetType AccessTarget       `json:"target_type"`
+	TargetID   int64              `json:"target_id"`
+	Amount     int64              `json:"amount"`
+	Currency   string             `json:"currency"`
+	UpdatedBy  int64              `json:"updated_by"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Question struct {
+	ID               int64                  `json:"id"`
+	Type             QuestionType           `json:"type"`
+	Stem             string                 `json:"stem"`
+	Options          []byte                 `json:"options"`
+	AnswerKey        []byte                 `json:"answer_key"`
+	Explanation      pgtype.Text            `json:"explanation"`
+	Marks            float64                `json:"marks"`
+	CreatedBy        int64                  `json:"created_by"`
+	CreatedAt        pgtype.Timestamptz     `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz     `json:"updated_at"`
+	Subject          pgtype.Text            `json:"subject"`
+	Topic            pgtype.Text            `json:"topic"`
+	ExamBody         pgtype.Text            `json:"exam_body"`
+	ExamYear         pgtype.Int4            `json:"exam_year"`
+	PaperNumber      pgtype.Int4            `json:"paper_number"`
+	QuestionNumber   pgtype.Int4            `json:"question_number"`
+	IrtA             pgtype.Float8          `json:"irt_a"`
+	IrtB             pgtype.Float8          `json:"irt_b"`
+	IrtC             pgtype.Float8          `json:"irt_c"`
+	IrtCalibrationID pgtype.Int8            `json:"irt_calibration_id"`
+	Version          int32                  `json:"version"`
+	Status           QuestionStatus         `json:"status"`
+	MergedInto       pgtype.Int8            `json:"merged_into"`
+	SearchVector     string                 `json:"-"`
+	Difficulty       NullQuestionDifficulty `json:"difficulty"`
+}
+
+type QuestionReviewComment struct {
+	ID         int64              `json:"id"`
+	QuestionID int64              `json:"question_id"`
+	ParentID   pgtype.Int8        `json:"parent_id"`
+	AuthorID   int64              `json:"author_id"`
+	Version    int32              `json:"version"`
+	Body       string             `json:"body"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type QuestionReviewer struct {
+	QuestionID int64              `json:"question_id"`
+	ReviewerID int64              `json:"reviewer_id"`
+	AssignedBy int64              `json:"assigned_by"`
+	AssignedAt pgtype.Timestamptz `json:"assigned_at"`
+	Decision   NullReviewDecision `json:"decision"`
+	Version    pgtype.Int4        `json:"version"`
+	DecidedAt  pgtype.Timestamptz `json:"decided_at"`
+}
+
+type QuestionSignature struct {
+	QuestionID int64              `json:"question_id"`
+	Minhash    []int64            `json:"minhash"`
+	Bands      []int64            `json:"bands"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+}
+
+type QuestionVersion struct {
+	QuestionID     int64              `json:"question_id"`
+	Version        int32              `json:"version"`
+	ContentVersion int32              `json:"content_version"`
+	Type           QuestionType       `json:"type"`
+	Stem           string             `json:"stem"`
+	Options        []byte             `json:"options"`
+	AnswerKey      []byte             `json:"answer_key"`
+	Explanation    pgtype.Text        `json:"explanation"`
+	Marks          float64            `json:"marks"`
+	Subject        pgtype.Text        `json:"subject"`
+	Topic          pgtype.Text        `json:"topic"`
+	Reason         string             `json:"reason"`
+	CreatedBy      int64              `json:"created_by"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
+type RubricCriterium struct {
+	ID          int64       `json:"id"`
+	QuestionID  int64       `json:"question_id"`
+	Name        string      `json:"name"`
+	Description pgtype.Text `json:"description"`
+	MaxPoints   float64     `json:"max_points"`
+	Position    int32       `json:"position"`
+}
+
+type ScoreChange struct {
+	ID         int64              `json:"id"`
+	AttemptID  int64              `json:"attempt_id"`
+	QuestionID pgtype.Int8        `json:"question_id"`
+	OldScore   float64            `json:"old_score"`
+	NewScore   float64            `json:"new_score"`
+	Reason     string             `json:"reason"`
+	ChangedBy  pgtype.Int8        `json:"changed_by"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type Subscription struct {
+	ID                 int64              `json:"id"`
+	UserID             int64              `json:"user_id"`
+	PlanID             int64              `json:"plan_id"`
+	StartedAt          pgtype.Timestamptz `json:"started_at"`
+	CurrentPeriodStart pgtype.Timestamptz `json:"current_period_start"`
+	CurrentPeriodEnd   pgtype.Timestamptz `json:"current_period_end"`
+	GraceEndsAt        pgtype.Timestamptz `json:"grace_ends_at"`
+	CancelledAt        pgtype.Timestamptz `json:"cancelled_at"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
+}
+
 type User struct {
 	ID               int64              `json:"id"`
 	FullName         string             `json:"full_name"`
@@ -116,4 +1831,46 @@ type User struct {
 	CreatedAt        pgtype.Timestamptz `json:"created_at"`
 	LastLogin        pgtype.Timestamp   `json:"last_login"`
 	UpdatedAt        pgtype.Timestamp   `json:"updated_at"`
+	EmailVerifiedAt  pgtype.Timestamptz `json:"email_verified_at"`
+	ReferralCode     pgtype.Text        `json:"referral_code"`
+	ReferredBy       pgtype.Int8        `json:"referred_by"`
+}
+
+type UserPermission struct {
+	UserID     int64              `json:"user_id"`
+	Permission AdminPermission    `json:"permission"`
+	GrantedBy  int64              `json:"granted_by"`
+	GrantedAt  pgtype.Timestamptz `json:"granted_at"`
+}
+
+type Voucher struct {
+	ID          int64              `json:"id"`
+	BatchID     int64              `json:"batch_id"`
+	Serial      string             `json:"serial"`
+	PinHash     string             `json:"pin_hash"`
+	AgentID     pgtype.Int8        `json:"agent_id"`
+	AllocatedAt pgtype.Timestamptz `json:"allocated_at"`
+	Uses        int32              `json:"uses"`
+	Status      VoucherStatus      `json:"status"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type VoucherBatch struct {
+	ID         int64              `json:"id"`
+	Name       string             `json:"name"`
+	TargetType AccessTarget       `json:"target_type"`
+	TargetID   int64              `json:"target_id"`
+	Quantity   int32              `json:"quantity"`
+	MaxUses    int32              `json:"max_uses"`
+	UnitPrice  int64              `json:"unit_price"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+	CreatedBy  int64              `json:"created_by"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type VoucherRedemption struct {
+	ID         int64              `json:"id"`
+	VoucherID  int64              `json:"voucher_id"`
+	UserID     int64              `json:"user_id"`
+	RedeemedAt pgtype.Timestamptz `json:"redeemed_at"`
 }
@@ -0,0 +1,87 @@
+-- name: ListPastPapers :many
+SELECT q.exam_body::text AS exam_body,
+       q.exam_year::int AS exam_year,
+       q.paper_number::int AS paper_number,
+       array_remove(array_agg(DISTINCT q.subject), NULL)::text[] AS subjects,
+       COUNT(*)::bigint AS questions,
+       e.id AS mock_exam_id
+FROM questions q
+LEFT JOIN exams e ON e.source_exam_body = q.exam_body
+  AND e.source_year = q.exam_year
+  AND e.source_paper = q.paper_number
+WHERE q.exam_body IS NOT NULL
+  AND q.status = 'approved'
+  AND (sqlc.narg(exam_body)::text IS NULL OR q.exam_body = sqlc.narg(exam_body))
+  AND (sqlc.narg(exam_year)::int IS NULL OR q.exam_year = sqlc.narg(exam_year))
+  AND (sqlc.narg(subject)::text IS NULL OR q.subject = sqlc.narg(subject))
+GROUP BY q.exam_body, q.exam_year, q.paper_number, e.id
+ORDER BY q.exam_body, q.exam_year DESC, q.paper_number;
+
+
+-- name: CountPaperQuestions :one
+SELECT COUNT(*)::bigint
+FROM questions
+WHERE exam_body = $1
+  AND exam_year = $2
+  AND paper_number = $3
+  AND status = 'approved';
+
+
+-- name: GetPaperMockExam :one
+SELECT *
+FROM exams
+WHERE source_exam_body = $1
+  AND source_year = $2
+  AND source_paper = $3;
+
+
+-- name: CreatePaperMockExam :one
+INSERT INTO exams (
+  title,
+  description,
+  duration_minutes,
+  status,
+  created_by,
+  source_exam_body,
+  source_year,
+  source_paper
+)
+VALUES ($1, $2, $3, 'published', $4, $5, $6, $7)
+ON CONFLICT (source_exam_body, source_year, source_paper) WHERE source_exam_body IS NOT NULL DO NOTHING
+RETURNING *;
+
+
+-- name: AddPaperQuestionsToExam :execrows
+INSERT INTO exam_questions (
+  exam_id,
+  question_id,
+  section,
+  position
+)
+SELECT @exam_id,
+       q.id,
+       COALESCE(q.subject, 'general'),
+       COALESCE(q.question_number, 0)
+FROM questions q
+WHERE q.exam_body = @exam_body
+  AND q.exam_year = @exam_year
+  AND q.paper_number = @paper_number
+  AND q.status = 'approved'
+ON CONFLICT (exam_id, question_id) DO NOTHING;
+
+
+-- name: RequireMockExamAccess :exec
+INSERT INTO exam_schedules (
+  exam_id,
+  requires_access
+)
+VALUES ($1, true)
+ON CONFLICT (exam_id) DO NOTHING;
+
+
+-- name: UpdateMockExamDuration :one
+UPDATE exams
+SET duration_minutes = GREATEST((SELECT COUNT(*) FROM exam_questions WHERE exam_id = @id)::int * @minutes_per_question::int, 1),
+    updated_at = now()
+WHERE id = @id
+RETURNING *;
@@ -0,0 +1,109 @@
+-- name: UpsertProductPrice :one
+INSERT INTO product_prices (
+  target_type,
+  target_id,
+  amount,
+  currency,
+  updated_by
+)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (target_type, target_id) DO UPDATE
+SET amount = EXCLUDED.amount,
+    currency = EXCLUDED.currency,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = now()
+RETURNING *;
+
+
+-- name: GetProductPrice :one
+SELECT *
+FROM product_prices
+WHERE target_type = $1
+  AND target_id = $2;
+
+
+-- name: ListProductPrices :many
+SELECT *
+FROM product_prices
+ORDER BY target_type, target_id;
+
+
+-- name: CreateOrder :one
+INSERT INTO orders (
+  reference,
+  user_id,
+  target_type,
+  target_id,
+  amount,
+  currency,
+  provider
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+
+-- name: SetOrderCheckout :one
+UPDATE orders
+SET provider_reference = $2,
+    authorization_url = $3,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+
+-- name: GetOrderByReference :one
+SELECT *
+FROM orders
+WHERE reference = $1;
+
+
+-- name: GetOrderByReferenceForUpdate :one
+SELECT *
+FROM orders
+WHERE reference = $1
+FOR UPDATE;
+
+
+-- name: GetOrderForUpdate :one
+SELECT *
+FROM orders
+WHERE id = $1
+FOR UPDATE;
+
+
+-- name: ListUserOrders :many
+SELECT *
+FROM orders
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+
+-- name: ListOrders :many
+SELECT *
+FROM orders
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
+
+
+-- name: UpdateOrderStatus :one
+UPDATE orders
+SET status = $2,
+    failure_reason = $3,
+    paid_at = CASE WHEN $2::order_status = 'paid' THEN now() ELSE paid_at END,
+    refunded_at = CASE WHEN $2::order_status = 'refunded' THEN now() ELSE refunded_at END,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+
+-- name: RecordWebhookEvent :one
+INSERT INTO payment_webhook_events (
+  provider,
+  event_id,
+  event_type,
+  order_reference
+)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (provider, event_id) DO NOTHING
+RETURNING *;
@@ -0,0 +1,33 @@
+-- name: GrantUserPermission :one
+INSERT INTO user_permissions (
+  user_id,
+  permission,
+  granted_by
+)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, permission) DO UPDATE
+SET granted_by = EXCLUDED.granted_by,
+    granted_at = now()
+RETURNING *;
+
+
+-- name: RevokeUserPermission :execrows
+DELETE FROM user_permissions
+WHERE user_id = $1
+  AND permission = $2;
+
+
+-- name: ListUserPermissions :many
+SELECT *
+FROM user_permissions
+WHERE user_id = $1
+ORDER BY permission;
+
+
+-- name: HasUserPermission :one
+SELECT EXISTS (
+  SELECT 1
+  FROM user_permissions
+  WHERE user_id = $1
+    AND permission = $2
+);
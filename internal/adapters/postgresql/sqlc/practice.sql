@@ -0,0 +1,181 @@
+-- name: CreatePracticePack :one
+INSERT INTO practice_packs (
+  name,
+  subject,
+  description,
+  created_by
+)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+
+-- name: GetPracticePack :one
+SELECT *
+FROM practice_packs
+WHERE id = $1;
+
+
+-- name: ListPracticePacks :many
+SELECT *
+FROM practice_packs
+ORDER BY subject, name;
+
+
+-- name: ListSubjectTopics :many
+SELECT COALESCE(topic, '')::text AS topic,
+       COUNT(*)::bigint AS questions
+FROM questions
+WHERE subject = $1
+  AND status = 'approved'
+  AND type NOT IN ('short_answer', 'essay')
+GROUP BY topic
+ORDER BY topic;
+
+
+-- name: PickPracticeQuestions :many
+SELECT q.id
+FROM questions q
+WHERE q.subject = @subject
+  AND (cardinality(@topics::text[]) = 0 OR q.topic = ANY(@topics::text[]))
+  AND q.status = 'approved'
+  AND q.type NOT IN ('short_answer', 'essay')
+  AND NOT EXISTS (
+    SELECT 1
+    FROM exam_questions eq
+    JOIN exams e ON e.id = eq.exam_id
+    WHERE eq.question_id = q.id
+      AND e.status <> 'archived'
+      AND e.source_exam_body IS NULL
+  )
+ORDER BY random()
+LIMIT @count;
+
+
+-- name: CreatePracticeSession :one
+INSERT INTO practice_sessions (
+  user_id,
+  pack_id,
+  topics,
+  question_count
+)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+
+-- name: AddPracticeSessionQuestions :exec
+INSERT INTO practice_session_questions (
+  session_id,
+  question_id,
+  position
+)
+SELECT @session_id, q.id, q.position::int
+FROM unnest(@question_ids::bigint[]) WITH ORDINALITY AS q(id, position);
+
+
+-- name: GetPracticeSession :one
+SELECT *
+FROM practice_sessions
+WHERE id = $1;
+
+
+-- name: ListUserPracticeSessions :many
+SELECT *
+FROM practice_sessions
+WHERE user_id = $1
+ORDER BY started_at DESC
+LIMIT $2 OFFSET $3;
+
+
+-- name: ListPracticeSessionQuestions :many
+SELECT psq.question_id,
+       psq.position,
+       q.type,
+       q.stem,
+       q.options,
+       q.answer_key,
+       q.explanation,
+       psq.answer,
+       psq.fraction,
+       psq.answered_at,
+       EXISTS (
+         SELECT 1
+         FROM exam_questions eq
+         JOIN exams e ON e.id = eq.exam_id
+         WHERE eq.question_id = q.id
+           AND e.status <> 'archived'
+           AND e.source_exam_body IS NULL
+       ) AS on_exam
+FROM practice_session_questions psq
+JOIN questions q ON q.id = psq.question_id
+WHERE psq.session_id = $1
+ORDER BY psq.position;
+
+
+-- name: GetPracticeQuestionForUpdate :one
+SELECT psq.answered_at,
+       q.type,
+       q.answer_key,
+       q.explanation,
+       EXISTS (
+         SELECT 1
+         FROM exam_questions eq
+         JOIN exams e ON e.id = eq.exam_id
+         WHERE eq.question_id = q.id
+           AND e.status <> 'archived'
+           AND e.source_exam_body IS NULL
+       ) AS on_exam
+FROM practice_session_questions psq
+JOIN questions q ON q.id = psq.question_id
+WHERE psq.session_id = $1
+  AND psq.question_id = $2
+FOR UPDATE OF psq;
+
+
+-- name: RecordPracticeAnswer :exec
+UPDATE practice_session_questions
+SET answer = $3,
+    fraction = $4,
+    answered_at = now()
+WHERE session_id = $1
+  AND question_id = $2;
+
+
+-- name: AdvancePracticeSession :one
+UPDATE practice_sessions
+SET answered_count = answered_count + 1,
+    correct_count = correct_count + CASE WHEN @correct::boolean THEN 1 ELSE 0 END,
+    current_run = CASE WHEN @correct::boolean THEN current_run + 1 ELSE 0 END,
+    best_run = GREATEST(best_run, CASE WHEN @correct::boolean THEN current_run + 1 ELSE 0 END),
+    status = CASE WHEN answered_count + 1 >= question_count THEN 'completed'::practice_session_status ELSE status END,
+    completed_at = CASE WHEN answered_count + 1 >= question_count THEN now() ELSE completed_at END
+WHERE id = @id
+RETURNING *;
+
+
+-- name: GetPracticeStreak :one
+SELECT *
+FROM practice_streaks
+WHERE user_id = $1;
+
+
+-- name: TouchPracticeStreak :one
+INSERT INTO practice_streaks (
+  user_id,
+  current_streak,
+  longest_streak,
+  last_practice_date
+)
+VALUES (@user_id, 1, 1, @day)
+ON CONFLICT (user_id) DO UPDATE
+SET current_streak = CASE
+      WHEN practice_streaks.last_practice_date >= EXCLUDED.last_practice_date THEN practice_streaks.current_streak
+      WHEN practice_streaks.last_practice_date = EXCLUDED.last_practice_date - 1 THEN practice_streaks.current_streak + 1
+      ELSE 1
+    END,
+    longest_streak = GREATEST(practice_streaks.longest_streak, CASE
+      WHEN practice_streaks.last_practice_date >= EXCLUDED.last_practice_date THEN practice_streaks.current_streak
+      WHEN practice_streaks.last_practice_date = EXCLUDED.last_practice_date - 1 THEN practice_streaks.current_streak + 1
+      ELSE 1
+    END),
+    last_practice_date = GREATEST(practice_streaks.last_practice_date, EXCLUDED.last_practice_date)
+RETURNING *;
@@ -0,0 +1,120 @@
+-- name: ListProctorRules :many
+SELECT *
+FROM exam_proctoring_rules
+WHERE exam_id = $1
+ORDER BY event_type;
+
+
+-- name: DeleteProctorRules :exec
+DELETE FROM exam_proctoring_rules
+WHERE exam_id = $1;
+
+
+-- name: CreateProctorRule :one
+INSERT INTO exam_proctoring_rules (
+  exam_id,
+  event_type,
+  flag_after,
+  submit_after
+)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+
+-- name: RecordProctorEvents :execrows
+INSERT INTO proctor_events (
+  attempt_id,
+  event_type,
+  occurred_at,
+  details
+)
+SELECT @attempt_id::bigint,
+       unnest(@event_types::text[])::proctor_event_type,
+       unnest(@occurred_ats::timestamptz[]),
+       unnest(@details::jsonb[]);
+
+
+-- name: IncrementProctorCounts :many
+INSERT INTO attempt_proctor_counts (
+  attempt_id,
+  event_type,
+  count
+)
+SELECT @attempt_id::bigint,
+       t::proctor_event_type,
+       COUNT(*)::int
+FROM unnest(@event_types::text[]) t
+GROUP BY t
+ON CONFLICT (attempt_id, event_type) DO UPDATE
+SET count = attempt_proctor_counts.count + EXCLUDED.count,
+    updated_at = now()
+RETURNING *;
+
+
+-- name: RecordProctorAction :one
+INSERT INTO attempt_proctor_actions (
+  attempt_id,
+  event_type,
+  action,
+  event_count
+)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (attempt_id, event_type, action) DO NOTHING
+RETURNING *;
+
+
+-- name: GetProctorAttempt :one
+SELECT t.id,
+       t.exam_id,
+       t.user_id,
+       u.full_name,
+       u.email,
+       t.status,
+       t.started_at,
+       t.expires_at,
+       t.submitted_at
+FROM exam_attempts t
+JOIN users u ON u.id = t.user_id
+WHERE t.id = $1;
+
+
+-- name: ListAttemptProctorCounts :many
+SELECT *
+FROM attempt_proctor_counts
+WHERE attempt_id = $1
+ORDER BY event_type;
+
+
+-- name: ListAttemptProctorActions :many
+SELECT *
+FROM attempt_proctor_actions
+WHERE attempt_id = $1
+ORDER BY created_at;
+
+
+-- name: ListAttemptProctorEvents :many
+SELECT event_type,
+       occurred_at,
+       received_at,
+       details
+FROM proctor_events
+WHERE attempt_id = $1
+ORDER BY occurred_at, received_at
+LIMIT $2 OFFSET $3;
+
+
+-- name: ListExamProctorActions :many
+SELECT a.attempt_id,
+       t.user_id,
+       u.full_name,
+       t.status AS attempt_status,
+       a.event_type,
+       a.action,
+       a.event_count,
+       a.created_at
+FROM attempt_proctor_actions a
+JOIN exam_attempts t ON t.id = a.attempt_id
+JOIN users u ON u.id = t.user_id
+WHERE t.exam_id = $1
+ORDER BY a.created_at DESC
+LIMIT $2 OFFSET $3;
@@ -6,18 +6,280 @@ package repo
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	AddCandidateGroupMembers(ctx context.Context, arg AddCandidateGroupMembersParams) (int64, error)
+	AddExamQuestion(ctx context.Context, arg AddExamQuestionParams) (ExamQuestion, error)
+	AddPaperQuestionsToExam(ctx context.Context, arg AddPaperQuestionsToExamParams) (int64, error)
+	AddPlanItem(ctx context.Context, arg AddPlanItemParams) error
+	AddPracticeSessionQuestions(ctx context.Context, arg AddPracticeSessionQuestionsParams) error
+	AdvancePracticeSession(ctx context.Context, arg AdvancePracticeSessionParams) (PracticeSession, error)
+	AllocateVouchers(ctx context.Context, arg AllocateVouchersParams) ([]string, error)
+	ApplyQuestionCalibration(ctx context.Context, arg ApplyQuestionCalibrationParams) error
+	AssignExamToGroups(ctx context.Context, arg AssignExamToGroupsParams) (int64, error)
+	AssignExamToUsers(ctx context.Context, arg AssignExamToUsersParams) (int64, error)
+	AssignQuestionReviewer(ctx context.Context, arg AssignQuestionReviewerParams) (QuestionReviewer, error)
+	AttachEntriesToPayout(ctx context.Context, arg AttachEntriesToPayoutParams) error
+	CancelAgentPayout(ctx context.Context, id int64) (AgentPayout, error)
+	CancelSubscription(ctx context.Context, id int64) (Subscription, error)
+	ClaimAnalysisRun(ctx context.Context) (ItemAnalysisRun, error)
+	ClearOpenDuplicateFlags(ctx context.Context, questionID int64) error
+	ClearReviewDecisions(ctx context.Context, questionID int64) error
+	CompleteAnalysisRun(ctx context.Context, arg CompleteAnalysisRunParams) (ItemAnalysisRun, error)
+	CountAdaptiveStates(ctx context.Context, examID int64) (int64, error)
+	CountPaperQuestions(ctx context.Context, arg CountPaperQuestionsParams) (int64, error)
+	CountReviewApprovals(ctx context.Context, arg CountReviewApprovalsParams) (int64, error)
+	CountUnapprovedExamQuestions(ctx context.Context, examID int64) (int64, error)
+	CountUnindexedQuestions(ctx context.Context) (int64, error)
+	CountUserAttempts(ctx context.Context, arg CountUserAttemptsParams) (int64, error)
+	CreateAdaptiveResponse(ctx context.Context, arg CreateAdaptiveResponseParams) error
+	CreateAdaptiveState(ctx context.Context, arg CreateAdaptiveStateParams) (AdaptiveState, error)
 	CreateAdmin(ctx context.Context, arg CreateAdminParams) (User, error)
+	CreateAgentPayout(ctx context.Context, arg CreateAgentPayoutParams) (AgentPayout, error)
+	CreateAnalysisRun(ctx context.Context, arg CreateAnalysisRunParams) (ItemAnalysisRun, error)
+	CreateAttempt(ctx context.Context, arg CreateAttemptParams) (ExamAttempt, error)
+	CreateCalibration(ctx context.Context, arg CreateCalibrationParams) (IrtCalibration, error)
+	CreateCandidateGroup(ctx context.Context, arg CreateCandidateGroupParams) (CandidateGroup, error)
+	CreateExam(ctx context.Context, arg CreateExamParams) (Exam, error)
+	CreateExamSubject(ctx context.Context, arg CreateExamSubjectParams) (ExamSubject, error)
+	CreateItemCalibration(ctx context.Context, arg CreateItemCalibrationParams) error
+	CreateItemDistractor(ctx context.Context, arg CreateItemDistractorParams) error
+	CreateItemStatistic(ctx context.Context, arg CreateItemStatisticParams) error
+	CreateLedgerEntry(ctx context.Context, arg CreateLedgerEntryParams) (LedgerEntry, error)
+	CreateLedgerTransaction(ctx context.Context, arg CreateLedgerTransactionParams) (LedgerTransaction, error)
+	CreateManualMark(ctx context.Context, arg CreateManualMarkParams) (ManualMark, error)
+	CreateMediaFile(ctx context.Context, arg CreateMediaFileParams) (MediaFile, error)
+	CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error)
+	CreatePaperMockExam(ctx context.Context, arg CreatePaperMockExamParams) (Exam, error)
+	CreatePlan(ctx context.Context, arg CreatePlanParams) (Plan, error)
+	CreatePracticePack(ctx context.Context, arg CreatePracticePackParams) (PracticePack, error)
+	CreatePracticeSession(ctx context.Context, arg CreatePracticeSessionParams) (PracticeSession, error)
+	CreateProctorRule(ctx context.Context, arg CreateProctorRuleParams) (ExamProctoringRule, error)
+	CreateQuestion(ctx context.Context, arg CreateQuestionParams) (Question, error)
+	CreateQuestionVersion(ctx context.Context, arg CreateQuestionVersionParams) (QuestionVersion, error)
+	CreateReviewComment(ctx context.Context, arg CreateReviewCommentParams) (QuestionReviewComment, error)
+	CreateRubricCriterion(ctx context.Context, arg CreateRubricCriterionParams) (RubricCriterium, error)
+	CreateScoreChange(ctx context.Context, arg CreateScoreChangeParams) error
+	CreateSubjectCombination(ctx context.Context, arg CreateSubjectCombinationParams) (ExamSubjectCombination, error)
+	CreateSubscription(ctx context.Context, arg CreateSubscriptionParams) (Subscription, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateVoucherBatch(ctx context.Context, arg CreateVoucherBatchParams) (VoucherBatch, error)
+	CreateVoucherRedemption(ctx context.Context, arg CreateVoucherRedemptionParams) (VoucherRedemption, error)
+	CreateVouchers(ctx context.Context, arg CreateVouchersParams) (int64, error)
+	DeleteAdaptiveSettings(ctx context.Context, examID int64) (int64, error)
+	DeleteCommissionRule(ctx context.Context, id int64) (int64, error)
+	DeleteExamAssignment(ctx context.Context, arg DeleteExamAssignmentParams) (int64, error)
+	DeleteExamSubjects(ctx context.Context, examID int64) error
+	DeleteProctorRules(ctx context.Context, examID int64) error
+	DeleteRubricCriteria(ctx context.Context, questionID int64) error
+	DeleteSubjectCombination(ctx context.Context, arg DeleteSubjectCombinationParams) (int64, error)
+	DetachPayoutEntries(ctx context.Context, payoutID pgtype.Int8) error
+	DismissDuplicateFlagsFor(ctx context.Context, arg DismissDuplicateFlagsForParams) error
+	DropMergedExamQuestions(ctx context.Context, mergedID int64) (int64, error)
+	EnsureManualReview(ctx context.Context, arg EnsureManualReviewParams) error
+	FailAnalysisRun(ctx context.Context, arg FailAnalysisRunParams) error
+	FindCommissionRule(ctx context.Context, arg FindCommissionRuleParams) (CommissionRule, error)
+	FindQuestionsByNormalizedStem(ctx context.Context, stems []string) ([]FindQuestionsByNormalizedStemRow, error)
+	FindSignatureCandidates(ctx context.Context, arg FindSignatureCandidatesParams) ([]FindSignatureCandidatesRow, error)
+	FinishAdaptiveState(ctx context.Context, attemptID int64) error
+	FlagDuplicate(ctx context.Context, arg FlagDuplicateParams) error
+	GetAccountBalance(ctx context.Context, accountID int64) (GetAccountBalanceRow, error)
+	GetActiveAccessGrant(ctx context.Context, arg GetActiveAccessGrantParams) (AccessGrant, error)
+	GetActiveAnalysisRun(ctx context.Context, examID int64) (ItemAnalysisRun, error)
+	GetAdaptiveSettings(ctx context.Context, examID int64) (ExamAdaptiveSetting, error)
+	GetAdaptiveState(ctx context.Context, attemptID int64) (AdaptiveState, error)
+	GetAdaptiveStateForUpdate(ctx context.Context, attemptID int64) (AdaptiveState, error)
+	GetAgentLedgerAccount(ctx context.Context, agentID pgtype.Int8) (LedgerAccount, error)
+	GetAgentLedgerAccountForUpdate(ctx context.Context, agentID pgtype.Int8) (LedgerAccount, error)
+	GetAgentPayoutByKey(ctx context.Context, idempotencyKey string) (AgentPayout, error)
+	GetAgentPayoutForUpdate(ctx context.Context, id int64) (AgentPayout, error)
+	GetAnalysisRun(ctx context.Context, id int64) (ItemAnalysisRun, error)
+	GetAttemptByID(ctx context.Context, id int64) (ExamAttempt, error)
+	GetAttemptQuestion(ctx context.Context, arg GetAttemptQuestionParams) (GetAttemptQuestionRow, error)
+	GetAttemptQuestionScore(ctx context.Context, arg GetAttemptQuestionScoreParams) (AttemptQuestionScore, error)
+	GetAttemptResult(ctx context.Context, attemptID int64) (AttemptResult, error)
+	GetCandidateGroup(ctx context.Context, id int64) (CandidateGroup, error)
+	GetDuplicateFlag(ctx context.Context, id int64) (DuplicateFlag, error)
+	GetExamByID(ctx context.Context, id int64) (Exam, error)
+	GetExamEligibility(ctx context.Context, examID int64) (ExamEligibilityRule, error)
+	GetExamSchedule(ctx context.Context, examID int64) (ExamSchedule, error)
+	GetGradingPolicy(ctx context.Context, examID int64) (ExamGradingPolicy, error)
+	GetLastSubmittedAttempt(ctx context.Context, arg GetLastSubmittedAttemptParams) (ExamAttempt, error)
+	GetLedgerAccountByCode(ctx context.Context, code string) (LedgerAccount, error)
+	GetLedgerTransactionByKey(ctx context.Context, idempotencyKey string) (LedgerTransaction, error)
+	GetManualReview(ctx context.Context, arg GetManualReviewParams) (ManualReview, error)
+	GetManualReviewForUpdate(ctx context.Context, arg GetManualReviewForUpdateParams) (ManualReview, error)
+	GetMediaFile(ctx context.Context, id int64) (MediaFile, error)
+	GetMediaFileByChecksum(ctx context.Context, checksum string) (MediaFile, error)
+	GetOpenAttempt(ctx context.Context, arg GetOpenAttemptParams) (ExamAttempt, error)
+	GetOrderByReference(ctx context.Context, reference string) (Order, error)
+	GetOrderByReferenceForUpdate(ctx context.Context, reference string) (Order, error)
+	GetOrderForUpdate(ctx context.Context, id int64) (Order, error)
+	GetPaperMockExam(ctx context.Context, arg GetPaperMockExamParams) (Exam, error)
+	GetPlan(ctx context.Context, id int64) (Plan, error)
+	GetPlanEntitlement(ctx context.Context, arg GetPlanEntitlementParams) (GetPlanEntitlementRow, error)
+	GetPracticePack(ctx context.Context, id int64) (PracticePack, error)
+	GetPracticeQuestionForUpdate(ctx context.Context, arg GetPracticeQuestionForUpdateParams) (GetPracticeQuestionForUpdateRow, error)
+	GetPracticeSession(ctx context.Context, id int64) (PracticeSession, error)
+	GetPracticeStreak(ctx context.Context, userID int64) (PracticeStreak, error)
+	GetProctorAttempt(ctx context.Context, id int64) (GetProctorAttemptRow, error)
+	GetProductPrice(ctx context.Context, arg GetProductPriceParams) (ProductPrice, error)
+	GetQuestionByID(ctx context.Context, id int64) (Question, error)
+	GetQuestionReviewer(ctx context.Context, arg GetQuestionReviewerParams) (QuestionReviewer, error)
+	GetQuestionVersion(ctx context.Context, arg GetQuestionVersionParams) (QuestionVersion, error)
+	GetResponseForMarking(ctx context.Context, arg GetResponseForMarkingParams) (GetResponseForMarkingRow, error)
+	GetResultSettings(ctx context.Context, examID int64) (ExamResultSetting, error)
+	GetResultStanding(ctx context.Context, arg GetResultStandingParams) (GetResultStandingRow, error)
+	GetReviewComment(ctx context.Context, id int64) (QuestionReviewComment, error)
+	GetSubscriptionForUpdate(ctx context.Context, arg GetSubscriptionForUpdateParams) (Subscription, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserByID(ctx context.Context, id int64) (User, error)
+	GetUserByReferralCode(ctx context.Context, referralCode pgtype.Text) (User, error)
+	GetVoucherBatch(ctx context.Context, id int64) (VoucherBatch, error)
+	GetVoucherBatchStats(ctx context.Context, batchID int64) (GetVoucherBatchStatsRow, error)
+	GetVoucherBySerialForUpdate(ctx context.Context, serial string) (Voucher, error)
+	GrantAccess(ctx context.Context, arg GrantAccessParams) (AccessGrant, error)
+	GrantUserPermission(ctx context.Context, arg GrantUserPermissionParams) (UserPermission, error)
+	HasAccess(ctx context.Context, arg HasAccessParams) (bool, error)
+	HasSubjectCombinations(ctx context.Context, examID int64) (bool, error)
+	HasUserPermission(ctx context.Context, arg HasUserPermissionParams) (bool, error)
+	IncrementProctorCounts(ctx context.Context, arg IncrementProctorCountsParams) ([]AttemptProctorCount, error)
+	IncrementVoucherUses(ctx context.Context, id int64) error
+	IsAssignedToExam(ctx context.Context, arg IsAssignedToExamParams) (bool, error)
+	ListAccountStatement(ctx context.Context, arg ListAccountStatementParams) ([]ListAccountStatementRow, error)
+	ListAdaptivePool(ctx context.Context, examID int64) ([]ListAdaptivePoolRow, error)
+	ListAdaptiveResponses(ctx context.Context, attemptID int64) ([]AdaptiveResponse, error)
+	ListAgentPayouts(ctx context.Context, arg ListAgentPayoutsParams) ([]AgentPayout, error)
+	ListAgentSales(ctx context.Context, arg ListAgentSalesParams) ([]ListAgentSalesRow, error)
+	ListAgentStock(ctx context.Context, agentID pgtype.Int8) ([]ListAgentStockRow, error)
+	ListAnalysisResponses(ctx context.Context, examID int64) ([]ListAnalysisResponsesRow, error)
+	ListAnalysisRuns(ctx context.Context, arg ListAnalysisRunsParams) ([]ItemAnalysisRun, error)
+	ListAttemptAnswers(ctx context.Context, attemptID int64) ([]AttemptAnswer, error)
+	ListAttemptPaper(ctx context.Context, arg ListAttemptPaperParams) ([]ListAttemptPaperRow, error)
+	ListAttemptProctorActions(ctx context.Context, attemptID int64) ([]AttemptProctorAction, error)
+	ListAttemptProctorCounts(ctx context.Context, attemptID int64) ([]AttemptProctorCount, error)
+	ListAttemptProctorEvents(ctx context.Context, arg ListAttemptProctorEventsParams) ([]ListAttemptProctorEventsRow, error)
+	ListAttemptQuestionIDs(ctx context.Context, attemptID int64) ([]int64, error)
+	ListAttemptQuestionScores(ctx context.Context, attemptID int64) ([]AttemptQuestionScore, error)
+	ListAttemptQuestionsForGrading(ctx context.Context, attemptID int64) ([]ListAttemptQuestionsForGradingRow, error)
+	ListAttemptReview(ctx context.Context, attemptID int64) ([]ListAttemptReviewRow, error)
+	ListAttemptSectionScores(ctx context.Context, attemptID int64) ([]ListAttemptSectionScoresRow, error)
+	ListAttemptSubjectScores(ctx context.Context, attemptID int64) ([]AttemptSubjectScore, error)
+	ListCalibrationResponses(ctx context.Context, examID pgtype.Int8) ([]ListCalibrationResponsesRow, error)
+	ListCandidateGroupMembers(ctx context.Context, arg ListCandidateGroupMembersParams) ([]CandidateGroupMember, error)
+	ListCandidateGroups(ctx context.Context, arg ListCandidateGroupsParams) ([]CandidateGroup, error)
+	ListCommissionRules(ctx context.Context) ([]CommissionRule, error)
+	ListDuplicateFlags(ctx context.Context, arg ListDuplicateFlagsParams) ([]ListDuplicateFlagsRow, error)
+	ListExamAssignments(ctx context.Context, examID int64) ([]ExamAssignment, error)
+	ListExamIDsByQuestion(ctx context.Context, questionID int64) ([]int64, error)
+	ListExamProctorActions(ctx context.Context, arg ListExamProctorActionsParams) ([]ListExamProctorActionsRow, error)
+	ListExamQuestions(ctx context.Context, examID int64) ([]ExamQuestion, error)
+	ListExamQuestionsForExport(ctx context.Context, examID int64) ([]ListExamQuestionsForExportRow, error)
+	ListExamSubjects(ctx context.Context, examID int64) ([]ExamSubject, error)
+	ListExams(ctx context.Context, arg ListExamsParams) ([]Exam, error)
+	ListItemDistractors(ctx context.Context, runID int64) ([]ItemDistractor, error)
+	ListItemStatistics(ctx context.Context, runID int64) ([]ListItemStatisticsRow, error)
+	ListLedgerEntriesByTransaction(ctx context.Context, transactionID int64) ([]LedgerEntry, error)
+	ListManualMarks(ctx context.Context, arg ListManualMarksParams) ([]ManualMark, error)
+	ListOrders(ctx context.Context, arg ListOrdersParams) ([]Order, error)
+	ListPastPapers(ctx context.Context, arg ListPastPapersParams) ([]ListPastPapersRow, error)
+	ListPendingResponses(ctx context.Context, arg ListPendingResponsesParams) ([]ListPendingResponsesRow, error)
+	ListPlanItems(ctx context.Context, planID int64) ([]PlanItem, error)
+	ListPlans(ctx context.Context, activeOnly bool) ([]Plan, error)
+	ListPracticePacks(ctx context.Context) ([]PracticePack, error)
+	ListPracticeSessionQuestions(ctx context.Context, sessionID int64) ([]ListPracticeSessionQuestionsRow, error)
+	ListProctorRules(ctx context.Context, examID int64) ([]ExamProctoringRule, error)
+	ListProductPrices(ctx context.Context) ([]ProductPrice, error)
+	ListPublishedExams(ctx context.Context, arg ListPublishedExamsParams) ([]Exam, error)
+	ListQuestionExams(ctx context.Context, questionID int64) ([]ListQuestionExamsRow, error)
+	ListQuestionReviewers(ctx context.Context, questionID int64) ([]ListQuestionReviewersRow, error)
+	ListQuestionVersionAttempts(ctx context.Context, arg ListQuestionVersionAttemptsParams) ([]ListQuestionVersionAttemptsRow, error)
+	ListQuestionVersionUsage(ctx context.Context, questionID int64) ([]ListQuestionVersionUsageRow, error)
+	ListQuestionVersions(ctx context.Context, questionID int64) ([]QuestionVersion, error)
+	ListQuestions(ctx context.Context, arg ListQuestionsParams) ([]Question, error)
+	ListResponsesForModeration(ctx context.Context, arg ListResponsesForModerationParams) ([]ListResponsesForModerationRow, error)
+	ListReviewComments(ctx context.Context, questionID int64) ([]ListReviewCommentsRow, error)
+	ListReviewQueue(ctx context.Context, arg ListReviewQueueParams) ([]Question, error)
+	ListRubricCriteria(ctx context.Context, questionID int64) ([]RubricCriterium, error)
+	ListScoreChanges(ctx context.Context, attemptID int64) ([]ScoreChange, error)
+	ListSubjectCombinations(ctx context.Context, examID int64) ([]ExamSubjectCombination, error)
+	ListSubjectTopics(ctx context.Context, subject pgtype.Text) ([]ListSubjectTopicsRow, error)
+	ListSubmittedAttemptIDs(ctx context.Context, examID int64) ([]int64, error)
+	ListUnindexedQuestions(ctx context.Context, limit int32) ([]Question, error)
+	ListUserAccessGrants(ctx context.Context, userID int64) ([]AccessGrant, error)
+	ListUserOrders(ctx context.Context, arg ListUserOrdersParams) ([]Order, error)
+	ListUserPermissions(ctx context.Context, userID int64) ([]UserPermission, error)
+	ListUserPracticeSessions(ctx context.Context, arg ListUserPracticeSessionsParams) ([]PracticeSession, error)
+	ListUserResults(ctx context.Context, arg ListUserResultsParams) ([]ListUserResultsRow, error)
+	ListUserSubscriptions(ctx context.Context, userID int64) ([]ListUserSubscriptionsRow, error)
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	ListVoucherBatches(ctx context.Context, arg ListVoucherBatchesParams) ([]VoucherBatch, error)
+	MarkEmailVerified(ctx context.Context, id int64) error
+	MarkPayoutPaid(ctx context.Context, arg MarkPayoutPaidParams) (AgentPayout, error)
+	MergeQuestion(ctx context.Context, arg MergeQuestionParams) (Question, error)
+	MoveMergedExamQuestions(ctx context.Context, arg MoveMergedExamQuestionsParams) (int64, error)
+	PickPracticeQuestions(ctx context.Context, arg PickPracticeQuestionsParams) ([]int64, error)
+	PublishResults(ctx context.Context, examID int64) (ExamResultSetting, error)
+	RecordAdaptiveExposure(ctx context.Context, arg RecordAdaptiveExposureParams) error
+	RecordAttemptPaperVersions(ctx context.Context, arg RecordAttemptPaperVersionsParams) error
+	RecordAttemptQuestionVersion(ctx context.Context, arg RecordAttemptQuestionVersionParams) error
+	RecordPracticeAnswer(ctx context.Context, arg RecordPracticeAnswerParams) error
+	RecordProctorAction(ctx context.Context, arg RecordProctorActionParams) (AttemptProctorAction, error)
+	RecordProctorEvents(ctx context.Context, arg RecordProctorEventsParams) (int64, error)
+	RecordReviewDecision(ctx context.Context, arg RecordReviewDecisionParams) (QuestionReviewer, error)
+	RecordWebhookEvent(ctx context.Context, arg RecordWebhookEventParams) (PaymentWebhookEvent, error)
+	RemoveCandidateGroupMember(ctx context.Context, arg RemoveCandidateGroupMemberParams) (int64, error)
+	RemovePlanItem(ctx context.Context, arg RemovePlanItemParams) (int64, error)
+	RemoveQuestionReviewer(ctx context.Context, arg RemoveQuestionReviewerParams) (int64, error)
+	RenewSubscription(ctx context.Context, arg RenewSubscriptionParams) (Subscription, error)
+	RequireMockExamAccess(ctx context.Context, examID int64) error
+	ResolveDuplicateFlag(ctx context.Context, arg ResolveDuplicateFlagParams) (DuplicateFlag, error)
+	RestoreQuestionVersion(ctx context.Context, arg RestoreQuestionVersionParams) (Question, error)
+	RevokeAccessBySource(ctx context.Context, arg RevokeAccessBySourceParams) (int64, error)
+	RevokeUserPermission(ctx context.Context, arg RevokeUserPermissionParams) (int64, error)
+	SaveQuestionSignature(ctx context.Context, arg SaveQuestionSignatureParams) error
+	SearchQuestionFacets(ctx context.Context, arg SearchQuestionFacetsParams) ([]SearchQuestionFacetsRow, error)
+	SearchQuestions(ctx context.Context, arg SearchQuestionsParams) ([]SearchQuestionsRow, error)
+	SetAdaptiveCurrentQuestion(ctx context.Context, arg SetAdaptiveCurrentQuestionParams) error
+	SetExamElectiveCount(ctx context.Context, arg SetExamElectiveCountParams) (Exam, error)
+	SetOrderCheckout(ctx context.Context, arg SetOrderCheckoutParams) (Order, error)
+	SetQuestionStatus(ctx context.Context, arg SetQuestionStatusParams) (Question, error)
+	SetReferralCode(ctx context.Context, arg SetReferralCodeParams) (User, error)
+	SettlePayoutEntries(ctx context.Context, payoutID pgtype.Int8) error
+	SubjectCombinationAllowed(ctx context.Context, arg SubjectCombinationAllowedParams) (bool, error)
+	SubmitAttempt(ctx context.Context, id int64) (ExamAttempt, error)
+	TerminateSubscription(ctx context.Context, id int64) (Subscription, error)
+	TouchPracticeStreak(ctx context.Context, arg TouchPracticeStreakParams) (PracticeStreak, error)
+	UpdateAdaptiveEstimate(ctx context.Context, arg UpdateAdaptiveEstimateParams) (AdaptiveState, error)
 	UpdateAdminFields(ctx context.Context, arg UpdateAdminFieldsParams) (User, error)
+	UpdateAttemptQuestionScore(ctx context.Context, arg UpdateAttemptQuestionScoreParams) (AttemptQuestionScore, error)
+	UpdateExamStatus(ctx context.Context, arg UpdateExamStatusParams) (Exam, error)
 	UpdateLastLogin(ctx context.Context, id int64) (User, error)
+	UpdateMockExamDuration(ctx context.Context, arg UpdateMockExamDurationParams) (Exam, error)
+	UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) (Order, error)
+	UpdatePlan(ctx context.Context, arg UpdatePlanParams) (Plan, error)
+	UpdateQuestion(ctx context.Context, arg UpdateQuestionParams) (Question, error)
+	UpdateQuestionAnswerKey(ctx context.Context, arg UpdateQuestionAnswerKeyParams) (Question, error)
+	UpdateQuestionIRT(ctx context.Context, arg UpdateQuestionIRTParams) (Question, error)
 	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
 	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	UpsertAdaptiveSettings(ctx context.Context, arg UpsertAdaptiveSettingsParams) (ExamAdaptiveSetting, error)
+	UpsertAgentLedgerAccount(ctx context.Context, arg UpsertAgentLedgerAccountParams) (LedgerAccount, error)
+	UpsertAttemptAnswers(ctx context.Context, arg UpsertAttemptAnswersParams) (int64, error)
+	UpsertAttemptQuestionScores(ctx context.Context, arg UpsertAttemptQuestionScoresParams) error
+	UpsertAttemptResult(ctx context.Context, arg UpsertAttemptResultParams) (AttemptResult, error)
+	UpsertAttemptSubjectScores(ctx context.Context, arg UpsertAttemptSubjectScoresParams) error
+	UpsertCommissionRule(ctx context.Context, arg UpsertCommissionRuleParams) (CommissionRule, error)
+	UpsertExamEligibility(ctx context.Context, arg UpsertExamEligibilityParams) (ExamEligibilityRule, error)
+	UpsertExamSchedule(ctx context.Context, arg UpsertExamScheduleParams) (ExamSchedule, error)
+	UpsertGradingPolicy(ctx context.Context, arg UpsertGradingPolicyParams) (ExamGradingPolicy, error)
+	UpsertManualReview(ctx context.Context, arg UpsertManualReviewParams) (ManualReview, error)
+	UpsertProductPrice(ctx context.Context, arg UpsertProductPriceParams) (ProductPrice, error)
+	UpsertResultSettings(ctx context.Context, arg UpsertResultSettingsParams) (ExamResultSetting, error)
+	VoidVoucher(ctx context.Context, serial string) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -3,9 +3,10 @@ INSERT INTO users (
   full_name,
   email,
   password,
-  phone
+  phone,
+  referred_by
 )
-VALUES ($1, $2, $3, $4)
+VALUES ($1, $2, $3, $4, $5)
 RETURNING *;
 
 
@@ -64,6 +65,11 @@ SET password = $2,
 WHERE id = $1
 RETURNING *;
 
+-- name: MarkEmailVerified :exec
+UPDATE users
+SET email_verified_at = COALESCE(email_verified_at, now())
+WHERE id = $1;
+
 
 -- name: UpdateAdminFields :one
 UPDATE users
@@ -73,3 +79,17 @@ SET admin_code = $2,
 WHERE id = $1
 RETURNING *;
 
+
+-- name: GetUserByReferralCode :one
+SELECT *
+FROM users
+WHERE referral_code = $1;
+
+
+-- name: SetReferralCode :one
+UPDATE users
+SET referral_code = $2,
+    updated_at = now()
+WHERE id = $1
+  AND referral_code IS NULL
+RETURNING *;
@@ -23,7 +23,7 @@ INSERT INTO users (
   phone
 )
 VALUES ($1, $2, $3, 'ADMIN', 'pending_approval', $4, $5, $6)
-RETURNING id, full_name, email, age, phone, date_of_birth, country, state, school, profile_completed, status, password, role, admin_code, department, created_at, last_login, updated_at
+RETURNING id, full_name, email, age, phone, date_of_birth, country, state, school, profile_completed, status, password, role, admin_code, department, created_at, last_login, updated_at, email_verified_at, referral_code, referred_by
 `
 
 type CreateAdminParams struct {
@@ -64,6 +64,9 @@ func (q *Queries) CreateAdmin(ctx context.Context, arg CreateAdminParams) (User,
 		&i.CreatedAt,
 		&i.LastLogin,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.ReferralCode,
+		&i.ReferredBy,
 	)
 	return i, err
 }
@@ -73,17 +76,19 @@ INSERT INTO users (
   full_name,
   email,
   password,
-  phone
+  phone,
+  referred_by
 )
-VALUES ($1, $2, $3, $4)
-RETURNING id, full_name, email, age, phone, date_of_birth, country, state, school, profile_completed, status, password, role, admin_code, department, created_at, last_login, updated_at
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, full_name, email, age, phone, date_of_birth, country, state, school, profile_completed, status, password, role, admin_code, department, created_at, last_login, updated_at, email_verified_at, referral_code, referred_by
 `
 
 type CreateUserParams struct {
-	FullName string      `json:"full_name"`
-	Email    string      `json:"email"`
-	Password string      `json:"password"`
-	Phone    pgtype.Text `json:"phone"`
+	FullName   string      `json:"full_name"`
+	Email      string      `json:"email"`
+	Password   string      `json:"password"`
+	Phone      pgtype.Text `json:"phone"`
+	ReferredBy pgtype.Int8 `json:"referred_by"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -92,6 +97,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.Email,
 		arg.Password,
 		arg.Phone,
+		arg.ReferredBy,
 	)
 	var i User
 	err := row.Scan(
@@ -113,12 +119,15 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.CreatedAt,
 		&i.LastLogin,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.ReferralCode,
+		&i.ReferredBy,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, full_name, email, age, phone, date_of_birth, country, state, school, profile_completed, status, password, role, admin_code, department, created_at, last_login, updated_at
+SELECT id, full_name, email, age, phone, date_of_birth, country, state, school, profile_completed, status, password, role, admin_code, department, created_at, last_login, updated_at, email_verified_at, referral_code, referred_by
 FROM users
 WHERE email = $1
 LIMIT 1
@@ -146,12 +155,15 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.CreatedAt,
 		&i.LastLogin,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.ReferralCode,
+		&i.ReferredBy,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, full_name, email, age, phone, date_of_birth, country, state, school, profile_completed, status, password, role, admin_code, department, created_at, last_login, updated_at
+SELECT id, full_name, email, age, phone, date_of_birth, country, state, school, profile_completed, status, password, role, admin_code, department, created_at, last_login, updated_at, email_verified_at, referral_code, referred_by
 FROM users
 WHERE id = $1
 `
@@ -178,12 +190,50 @@ func (q *Queries) GetUserByID(ctx context.Context, id int64) (User, error) {
 		&i.CreatedAt,
 		&i.LastLogin,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.ReferralCode,
+		&i.ReferredBy,
+	)
+	return i, err
+}
+
+const getUserByReferralCode = `-- name: GetUserByReferralCode :one
+SELECT id, full_name, email, age, phone, date_of_birth, country, state, school, profile_completed, status, password, role, admin_code, department, created_at, last_login, updated_at, email_verified_at, referral_code, referred_by
+FROM users
+WHERE referral_code = $1
+`
+
+func (q *Queries) GetUserByReferralCode(ctx context.Context, referralCode pgtype.Text) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByReferralCode, referralCode)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.FullName,
+		&i.Email,
+		&i.Age,
+		&i.Phone,
+		&i.DateOfBirth,
+		&i.Country,
+		&i.State,
+		&i.School,
+		&i.ProfileCompleted,
+		&i.Status,
+		&i.Password,
+		&i.Role,
+		&i.AdminCode,
+		&i.Department,
+		&i.CreatedAt,
+		&i.LastLogin,
+		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.ReferralCode,
+		&i.ReferredBy,
 	)
 	return i, err
 }
 
 const listUsers = `-- name: ListUsers :many
-SELECT id, full_name, email, age, phone, date_of_birth, country, state, school, profile_completed, status, password, role, admin_code, department, created_at, last_login, updated_at
+SELECT id, full_name, email, age, phone, date_of_birth, country, state, school, profile_completed, status, password, role, admin_code, department, created_at, last_login, updated_at, email_verified_at, referral_code, referred_by
 FROM users
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2
@@ -222,6 +272,9 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.CreatedAt,
 			&i.LastLogin,
 			&i.UpdatedAt,
+			&i.EmailVerifiedAt,
+			&i.ReferralCode,
+			&i.ReferredBy,
 		); err != nil {
 			return nil, err
 		}
@@ -233,13 +286,67 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 	return items, nil
 }
 
+const markEmailVerified = `-- name: MarkEmailVerified :exec
+UPDATE users
+SET email_verified_at = COALESCE(email_verified_at, now())
+WHERE id = $1
+`
+
+func (q *Queries) MarkEmailVerified(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, markEmailVerified, id)
+	return err
+}
+
+const setReferralCode = `-- name: SetReferralCode :one
+UPDATE users
+SET referral_code = $2,
+    updated_at = now()
+WHERE id = $1
+  AND referral_code IS NULL
+RETURNING id, full_name, email, age, phone, date_of_birth, country, state, school, profile_completed, status, password, role, admin_code, department, created_at, last_login, updated_at, email_verified_at, referral_code, referred_by
+`
+
+type SetReferralCodeParams struct {
+	ID           int64       `json:"id"`
+	ReferralCode pgtype.Text `json:"referral_code"`
+}
+
+func (q *Queries) SetReferralCode(ctx context.Context, arg SetReferralCodeParams) (User, error) {
+	row := q.db.QueryRow(ctx, setReferralCode, arg.ID, arg.ReferralCode)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.FullName,
+		&i.Email,
+		&i.Age,
+		&i.Phone,
+		&i.DateOfBirth,
+		&i.Country,
+		&i.State,
+		&i.School,
+		&i.ProfileCompleted,
+		&i.Status,
+		&i.Password,
+		&i.Role,
+		&i.AdminCode,
+		&i.Department,
+		&i.CreatedAt,
+		&i.LastLogin,
+		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.ReferralCode,
+		&i.ReferredBy,
+	)
+	return i, err
+}
+
 const updateAdminFields = `-- name: UpdateAdminFields :one
 UPDATE users
 SET admin_code = $2,
     phone = $3,
     updated_at = now()
 WHERE id = $1
-RETURNING id, full_name, email, age, phone, date_of_birth, country, state, school, profile_completed, status, password, role, admin_code, department, created_at, last_login, updated_at
+RETURNING id, full_name, email, age, phone, date_of_birth, country, state, school, profile_completed, status, password, role, admin_code, department, created_at, last_login, updated_at, email_verified_at, referral_code, referred_by
 `
 
 type UpdateAdminFieldsParams struct {
@@ -270,6 +377,9 @@ func (q *Queries) UpdateAdminFields(ctx context.Context, arg UpdateAdminFieldsPa
 		&i.CreatedAt,
 		&i.LastLogin,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.ReferralCode,
+		&i.ReferredBy,
 	)
 	return i, err
 }
@@ -278,7 +388,7 @@ const updateLastLogin = `-- name: UpdateLastLogin :one
 UPDATE users
 SET last_login = now()
 WHERE id = $1
-RETURNING id, full_name, email, age, phone, date_of_birth, country, state, school, profile_completed, status, password, role, admin_code, department, created_at, last_login, updated_at
+RETURNING id, full_name, email, age, phone, date_of_birth, country, state, school, profile_completed, status, password, role, admin_code, department, created_at, last_login, updated_at, email_verified_at, referral_code, referred_by
 `
 
 func (q *Queries) UpdateLastLogin(ctx context.Context, id int64) (User, error) {
@@ -303,6 +413,9 @@ func (q *Queries) UpdateLastLogin(ctx context.Context, id int64) (User, error) {
 		&i.CreatedAt,
 		&i.LastLogin,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.ReferralCode,
+		&i.ReferredBy,
 	)
 	return i, err
 }
@@ -312,7 +425,7 @@ UPDATE users
 SET password = $2,
     updated_at = now()
 WHERE id = $1
-RETURNING id, full_name, email, age, phone, date_of_birth, country, state, school, profile_completed, status, password, role, admin_code, department, created_at, last_login, updated_at
+RETURNING id, full_name, email, age, phone, date_of_birth, country, state, school, profile_completed, status, password, role, admin_code, department, created_at, last_login, updated_at, email_verified_at, referral_code, referred_by
 `
 
 type UpdateUserPasswordParams struct {
@@ -342,6 +455,9 @@ func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPassword
 		&i.CreatedAt,
 		&i.LastLogin,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.ReferralCode,
+		&i.ReferredBy,
 	)
 	return i, err
 }
@@ -351,7 +467,7 @@ UPDATE users
 SET role = $2,
     updated_at = now()
 WHERE id = $1
-RETURNING id, full_name, email, age, phone, date_of_birth, country, state, school, profile_completed, status, password, role, admin_code, department, created_at, last_login, updated_at
+RETURNING id, full_name, email, age, phone, date_of_birth, country, state, school, profile_completed, status, password, role, admin_code, department, created_at, last_login, updated_at, email_verified_at, referral_code, referred_by
 `
 
 type UpdateUserRoleParams struct {
@@ -381,6 +497,9 @@ func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams)
 		&i.CreatedAt,
 		&i.LastLogin,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.ReferralCode,
+		&i.ReferredBy,
 	)
 	return i, err
 }
@@ -0,0 +1,244 @@
+-- name: CreateQuestion :one
+INSERT INTO questions (
+  type,
+  stem,
+  options,
+  answer_key,
+  explanation,
+  marks,
+  created_by,
+  subject,
+  topic,
+  exam_body,
+  exam_year,
+  paper_number,
+  question_number
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+RETURNING *;
+
+
+-- name: GetQuestionByID :one
+SELECT *
+FROM questions
+WHERE id = $1;
+
+
+-- name: ListQuestions :many
+SELECT *
+FROM questions
+WHERE (sqlc.narg(subject)::text IS NULL OR subject = sqlc.narg(subject))
+  AND (sqlc.narg(topic)::text IS NULL OR topic = sqlc.narg(topic))
+  AND (sqlc.narg(exam_body)::text IS NULL OR exam_body = sqlc.narg(exam_body))
+  AND (sqlc.narg(exam_year)::int IS NULL OR exam_year = sqlc.narg(exam_year))
+  AND (sqlc.narg(paper_number)::int IS NULL OR paper_number = sqlc.narg(paper_number))
+  AND (sqlc.narg(status)::question_status IS NULL OR status = sqlc.narg(status))
+ORDER BY created_at DESC
+LIMIT @limit OFFSET @offset;
+
+
+-- name: UpdateQuestionAnswerKey :one
+UPDATE questions
+SET answer_key = $2,
+    version = version + 1,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+
+-- name: AddExamQuestion :one
+INSERT INTO exam_questions (
+  exam_id,
+  question_id,
+  section,
+  position,
+  marks
+)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (exam_id, question_id) DO UPDATE
+SET section = EXCLUDED.section,
+    position = EXCLUDED.position,
+    marks = EXCLUDED.marks
+RETURNING *;
+
+
+-- name: ListExamQuestions :many
+SELECT *
+FROM exam_questions
+WHERE exam_id = $1
+ORDER BY section, position, question_id;
+
+
+-- name: ListExamIDsByQuestion :many
+SELECT exam_id
+FROM exam_questions
+WHERE question_id = $1;
+
+
+-- name: ListAttemptQuestionsForGrading :many
+SELECT d.question_id,
+       d.type,
+       d.answer_key::jsonb AS answer_key,
+       eq.section,
+       COALESCE(eq.marks, d.marks)::double precision AS marks
+FROM delivered_questions d
+JOIN exam_attempts t ON t.id = d.attempt_id
+JOIN exam_questions eq ON eq.exam_id = t.exam_id AND eq.question_id = d.question_id
+WHERE d.attempt_id = $1
+ORDER BY eq.section, eq.position, d.question_id;
+
+
+-- name: UpdateQuestionIRT :one
+UPDATE questions
+SET irt_a = $2,
+    irt_b = $3,
+    irt_c = $4,
+    irt_calibration_id = NULL,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+
+-- name: FindQuestionsByNormalizedStem :many
+SELECT id,
+       btrim(regexp_replace(lower(stem), '\s+', ' ', 'g'))::text AS normalized_stem
+FROM questions
+WHERE btrim(regexp_replace(lower(stem), '\s+', ' ', 'g')) = ANY(@stems::text[])
+ORDER BY id;
+
+
+-- name: ListExamQuestionsForExport :many
+SELECT q.*,
+       eq.section,
+       eq.position,
+       eq.marks AS exam_marks
+FROM exam_questions eq
+JOIN questions q ON q.id = eq.question_id
+WHERE eq.exam_id = $1
+ORDER BY eq.section, eq.position, q.id;
+
+
+-- name: UpdateQuestion :one
+UPDATE questions
+SET stem = $2,
+    options = $3,
+    explanation = $4,
+    marks = $5,
+    subject = $6,
+    topic = $7,
+    version = version + 1,
+    status = CASE WHEN status IN ('approved', 'rejected') THEN 'draft' ELSE status END,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+
+-- name: RestoreQuestionVersion :one
+UPDATE questions q
+SET stem = v.stem,
+    options = v.options,
+    answer_key = v.answer_key,
+    explanation = v.explanation,
+    marks = v.marks,
+    subject = v.subject,
+    topic = v.topic,
+    version = q.version + 1,
+    status = CASE WHEN q.status IN ('approved', 'rejected') THEN 'draft' ELSE q.status END,
+    updated_at = now()
+FROM question_versions v
+WHERE q.id = @id
+  AND v.question_id = q.id
+  AND v.version = @version
+RETURNING q.*;
+
+
+-- name: CreateQuestionVersion :one
+INSERT INTO question_versions (
+  question_id,
+  version,
+  content_version,
+  type,
+  stem,
+  options,
+  answer_key,
+  explanation,
+  marks,
+  subject,
+  topic,
+  reason,
+  created_by
+)
+SELECT q.id,
+       q.version,
+       CASE
+         WHEN @key_only::boolean THEN COALESCE((
+           SELECT p.content_version
+           FROM question_versions p
+           WHERE p.question_id = q.id
+             AND p.version = q.version - 1
+         ), q.version)
+         ELSE q.version
+       END,
+       q.type,
+       q.stem,
+       q.options,
+       q.answer_key,
+       q.explanation,
+       q.marks,
+       q.subject,
+       q.topic,
+       @reason,
+       @created_by
+FROM questions q
+WHERE q.id = @question_id
+RETURNING *;
+
+
+-- name: ListQuestionVersions :many
+SELECT *
+FROM question_versions
+WHERE question_id = $1
+ORDER BY version DESC;
+
+
+-- name: GetQuestionVersion :one
+SELECT *
+FROM question_versions
+WHERE question_id = $1
+  AND version = $2;
+
+
+-- name: ListQuestionExams :many
+SELECT e.id AS exam_id,
+       e.title,
+       e.status,
+       eq.section
+FROM exam_questions eq
+JOIN exams e ON e.id = eq.exam_id
+WHERE eq.question_id = $1
+ORDER BY e.id;
+
+
+-- name: ListQuestionVersionUsage :many
+SELECT d.version,
+       t.exam_id,
+       COUNT(*)::bigint AS attempts
+FROM attempt_question_versions d
+JOIN exam_attempts t ON t.id = d.attempt_id
+WHERE d.question_id = $1
+GROUP BY d.version, t.exam_id
+ORDER BY d.version DESC, t.exam_id;
+
+
+-- name: ListQuestionVersionAttempts :many
+SELECT t.id AS attempt_id,
+       t.exam_id,
+       t.user_id,
+       t.status,
+       t.started_at
+FROM attempt_question_versions d
+JOIN exam_attempts t ON t.id = d.attempt_id
+WHERE d.question_id = @question_id
+  AND d.version = @version
+ORDER BY t.id DESC
+LIMIT @limit OFFSET @offset;
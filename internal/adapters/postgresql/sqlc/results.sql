@@ -0,0 +1,98 @@
+-- name: GetResultSettings :one
+SELECT *
+FROM exam_result_settings
+WHERE exam_id = $1;
+
+
+-- name: UpsertResultSettings :one
+INSERT INTO exam_result_settings (
+  exam_id,
+  release_mode,
+  release_at,
+  pass_mark,
+  allow_answer_review
+)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (exam_id) DO UPDATE
+SET release_mode = EXCLUDED.release_mode,
+    release_at = EXCLUDED.release_at,
+    pass_mark = EXCLUDED.pass_mark,
+    allow_answer_review = EXCLUDED.allow_answer_review,
+    updated_at = now()
+RETURNING *;
+
+
+-- name: PublishResults :one
+INSERT INTO exam_result_settings (
+  exam_id,
+  release_mode,
+  released_at
+)
+VALUES ($1, 'manual', now())
+ON CONFLICT (exam_id) DO UPDATE
+SET released_at = now(),
+    updated_at = now()
+RETURNING *;
+
+
+-- name: ListUserResults :many
+SELECT r.attempt_id,
+       r.exam_id,
+       e.title,
+       r.total_score,
+       r.max_score,
+       r.status,
+       r.graded_at
+FROM attempt_results r
+JOIN exams e ON e.id = r.exam_id
+WHERE r.user_id = $1
+ORDER BY r.graded_at DESC
+LIMIT $2 OFFSET $3;
+
+
+-- name: GetResultStanding :one
+WITH best AS (
+  SELECT DISTINCT ON (user_id) total_score
+  FROM attempt_results
+  WHERE exam_id = @exam_id
+    AND user_id <> @user_id
+    AND status = 'graded'
+  ORDER BY user_id, total_score DESC
+)
+SELECT count(*) FILTER (WHERE total_score > @total_score::double precision) AS above,
+       count(*) FILTER (WHERE total_score = @total_score::double precision) + 1 AS tied,
+       count(*) + 1 AS total
+FROM best;
+
+
+-- name: ListAttemptSectionScores :many
+SELECT eq.section,
+       SUM(s.score)::double precision AS score,
+       SUM(s.max_score)::double precision AS max_score
+FROM attempt_question_scores s
+JOIN exam_attempts t ON t.id = s.attempt_id
+JOIN exam_questions eq ON eq.exam_id = t.exam_id AND eq.question_id = s.question_id
+WHERE s.attempt_id = $1
+GROUP BY eq.section
+ORDER BY eq.section;
+
+
+-- name: ListAttemptReview :many
+SELECT d.question_id,
+       d.type,
+       d.stem,
+       d.options,
+       d.answer_key::jsonb AS answer_key,
+       d.explanation,
+       eq.section,
+       a.answer,
+       s.score,
+       s.max_score,
+       s.outcome
+FROM attempt_question_scores s
+JOIN exam_attempts t ON t.id = s.attempt_id
+JOIN exam_questions eq ON eq.exam_id = t.exam_id AND eq.question_id = s.question_id
+JOIN delivered_questions d ON d.attempt_id = s.attempt_id AND d.question_id = s.question_id
+LEFT JOIN attempt_answers a ON a.attempt_id = s.attempt_id AND a.question_id = s.question_id
+WHERE s.attempt_id = $1
+ORDER BY eq.section, eq.position, d.question_id;
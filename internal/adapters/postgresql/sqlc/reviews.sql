@@ -0,0 +1,122 @@
+-- name: SetQuestionStatus :one
+UPDATE questions
+SET status = $2,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+
+-- name: ClearReviewDecisions :exec
+UPDATE question_reviewers
+SET decision = NULL,
+    version = NULL,
+    decided_at = NULL
+WHERE question_id = $1;
+
+
+-- name: AssignQuestionReviewer :one
+INSERT INTO question_reviewers (
+  question_id,
+  reviewer_id,
+  assigned_by
+)
+VALUES ($1, $2, $3)
+ON CONFLICT (question_id, reviewer_id) DO UPDATE
+SET assigned_by = EXCLUDED.assigned_by
+RETURNING *;
+
+
+-- name: RemoveQuestionReviewer :execrows
+DELETE FROM question_reviewers
+WHERE question_id = $1
+  AND reviewer_id = $2;
+
+
+-- name: GetQuestionReviewer :one
+SELECT *
+FROM question_reviewers
+WHERE question_id = $1
+  AND reviewer_id = $2;
+
+
+-- name: ListQuestionReviewers :many
+SELECT r.reviewer_id,
+       u.full_name,
+       r.assigned_by,
+       r.assigned_at,
+       r.decision,
+       r.version,
+       r.decided_at
+FROM question_reviewers r
+JOIN users u ON u.id = r.reviewer_id
+WHERE r.question_id = $1
+ORDER BY r.assigned_at, r.reviewer_id;
+
+
+-- name: RecordReviewDecision :one
+UPDATE question_reviewers
+SET decision = $3,
+    version = $4,
+    decided_at = now()
+WHERE question_id = $1
+  AND reviewer_id = $2
+RETURNING *;
+
+
+-- name: CountReviewApprovals :one
+SELECT COUNT(*)::bigint
+FROM question_reviewers
+WHERE question_id = $1
+  AND version = $2
+  AND decision = 'approved';
+
+
+-- name: CreateReviewComment :one
+INSERT INTO question_review_comments (
+  question_id,
+  parent_id,
+  author_id,
+  version,
+  body
+)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+
+-- name: GetReviewComment :one
+SELECT *
+FROM question_review_comments
+WHERE id = $1;
+
+
+-- name: ListReviewComments :many
+SELECT c.id,
+       c.parent_id,
+       c.author_id,
+       u.full_name AS author_name,
+       c.version,
+       c.body,
+       c.created_at
+FROM question_review_comments c
+JOIN users u ON u.id = c.author_id
+WHERE c.question_id = $1
+ORDER BY c.created_at, c.id;
+
+
+-- name: ListReviewQueue :many
+SELECT q.*
+FROM question_reviewers r
+JOIN questions q ON q.id = r.question_id
+WHERE r.reviewer_id = $1
+  AND q.status = 'in_review'
+  AND (r.version IS NULL OR r.version <> q.version)
+ORDER BY r.assigned_at, q.id
+LIMIT $2 OFFSET $3;
+
+
+-- name: CountUnapprovedExamQuestions :one
+SELECT COUNT(*)::bigint
+FROM exam_questions eq
+JOIN questions q ON q.id = eq.question_id
+WHERE eq.exam_id = $1
+  AND q.status <> 'approved';
@@ -0,0 +1,165 @@
+-- name: GetExamSchedule :one
+SELECT *
+FROM exam_schedules
+WHERE exam_id = $1;
+
+
+-- name: UpsertExamSchedule :one
+INSERT INTO exam_schedules (
+  exam_id,
+  opens_at,
+  closes_at,
+  time_zone,
+  max_attempts,
+  retake_cooldown_minutes,
+  assigned_only,
+  requires_access
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (exam_id) DO UPDATE
+SET opens_at = EXCLUDED.opens_at,
+    closes_at = EXCLUDED.closes_at,
+    time_zone = EXCLUDED.time_zone,
+    max_attempts = EXCLUDED.max_attempts,
+    retake_cooldown_minutes = EXCLUDED.retake_cooldown_minutes,
+    assigned_only = EXCLUDED.assigned_only,
+    requires_access = EXCLUDED.requires_access,
+    updated_at = now()
+RETURNING *;
+
+
+-- name: GetExamEligibility :one
+SELECT *
+FROM exam_eligibility_rules
+WHERE exam_id = $1;
+
+
+-- name: UpsertExamEligibility :one
+INSERT INTO exam_eligibility_rules (
+  exam_id,
+  schools,
+  states,
+  require_profile_completed,
+  require_email_verified
+)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (exam_id) DO UPDATE
+SET schools = EXCLUDED.schools,
+    states = EXCLUDED.states,
+    require_profile_completed = EXCLUDED.require_profile_completed,
+    require_email_verified = EXCLUDED.require_email_verified,
+    updated_at = now()
+RETURNING *;
+
+
+-- name: CreateCandidateGroup :one
+INSERT INTO candidate_groups (
+  name,
+  description,
+  created_by
+)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+
+-- name: GetCandidateGroup :one
+SELECT *
+FROM candidate_groups
+WHERE id = $1;
+
+
+-- name: ListCandidateGroups :many
+SELECT *
+FROM candidate_groups
+ORDER BY name
+LIMIT $1 OFFSET $2;
+
+
+-- name: AddCandidateGroupMembers :execrows
+INSERT INTO candidate_group_members (
+  group_id,
+  user_id
+)
+SELECT @group_id::bigint,
+       unnest(@user_ids::bigint[])
+ON CONFLICT DO NOTHING;
+
+
+-- name: RemoveCandidateGroupMember :execrows
+DELETE FROM candidate_group_members
+WHERE group_id = $1
+  AND user_id = $2;
+
+
+-- name: ListCandidateGroupMembers :many
+SELECT *
+FROM candidate_group_members
+WHERE group_id = $1
+ORDER BY user_id
+LIMIT $2 OFFSET $3;
+
+
+-- name: AssignExamToUsers :execrows
+INSERT INTO exam_assignments (
+  exam_id,
+  user_id
+)
+SELECT @exam_id::bigint,
+       unnest(@user_ids::bigint[])
+ON CONFLICT DO NOTHING;
+
+
+-- name: AssignExamToGroups :execrows
+INSERT INTO exam_assignments (
+  exam_id,
+  group_id
+)
+SELECT @exam_id::bigint,
+       unnest(@group_ids::bigint[])
+ON CONFLICT DO NOTHING;
+
+
+-- name: ListExamAssignments :many
+SELECT *
+FROM exam_assignments
+WHERE exam_id = $1
+ORDER BY id;
+
+
+-- name: DeleteExamAssignment :execrows
+DELETE FROM exam_assignments
+WHERE exam_id = $1
+  AND id = $2;
+
+
+-- name: IsAssignedToExam :one
+SELECT EXISTS (
+  SELECT 1
+  FROM exam_assignments ea
+  WHERE ea.exam_id = $1
+    AND (
+      ea.user_id = $2
+      OR ea.group_id IN (
+        SELECT gm.group_id
+        FROM candidate_group_members gm
+        WHERE gm.user_id = $2
+      )
+    )
+);
+
+
+-- name: CountUserAttempts :one
+SELECT count(*)
+FROM exam_attempts
+WHERE exam_id = $1
+  AND user_id = $2;
+
+
+-- name: GetLastSubmittedAttempt :one
+SELECT *
+FROM exam_attempts
+WHERE exam_id = $1
+  AND user_id = $2
+  AND status = 'submitted'
+ORDER BY submitted_at DESC
+LIMIT 1;
@@ -0,0 +1,49 @@
+-- name: SearchQuestions :many
+SELECT q.id,
+       q.type,
+       q.status,
+       q.subject,
+       q.topic,
+       q.difficulty,
+       q.marks,
+       q.version,
+       ts_rank_cd(q.search_vector, tsq)::float8 AS rank,
+       ts_headline('english', q.stem, tsq, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS stem_highlight,
+       ts_headline('english', q.options, tsq, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS options_highlight,
+       ts_headline('english', coalesce(q.explanation, ''), tsq, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2') AS explanation_highlight
+FROM questions q,
+     websearch_to_tsquery('english', @query) tsq
+WHERE q.search_vector @@ tsq
+  AND (sqlc.narg(subject)::text IS NULL OR q.subject = sqlc.narg(subject))
+  AND (sqlc.narg(topic)::text IS NULL OR q.topic = sqlc.narg(topic))
+  AND (sqlc.narg(type)::question_type IS NULL OR q.type = sqlc.narg(type))
+  AND (sqlc.narg(difficulty)::question_difficulty IS NULL OR q.difficulty = sqlc.narg(difficulty))
+  AND (sqlc.narg(status)::question_status IS NULL OR q.status = sqlc.narg(status))
+ORDER BY rank DESC, q.id
+LIMIT @limit OFFSET @offset;
+
+
+-- name: SearchQuestionFacets :many
+SELECT CASE
+         WHEN GROUPING(q.subject) = 0 THEN 'subject'
+         WHEN GROUPING(q.topic) = 0 THEN 'topic'
+         WHEN GROUPING(q.difficulty) = 0 THEN 'difficulty'
+         ELSE 'type'
+       END::text AS facet,
+       CASE
+         WHEN GROUPING(q.subject) = 0 THEN q.subject
+         WHEN GROUPING(q.topic) = 0 THEN q.topic
+         WHEN GROUPING(q.difficulty) = 0 THEN q.difficulty::text
+         ELSE q.type::text
+       END AS value,
+       COUNT(*) AS count
+FROM questions q,
+     websearch_to_tsquery('english', @query) tsq
+WHERE q.search_vector @@ tsq
+  AND (sqlc.narg(subject)::text IS NULL OR q.subject = sqlc.narg(subject))
+  AND (sqlc.narg(topic)::text IS NULL OR q.topic = sqlc.narg(topic))
+  AND (sqlc.narg(type)::question_type IS NULL OR q.type = sqlc.narg(type))
+  AND (sqlc.narg(difficulty)::question_difficulty IS NULL OR q.difficulty = sqlc.narg(difficulty))
+  AND (sqlc.narg(status)::question_status IS NULL OR q.status = sqlc.narg(status))
+GROUP BY GROUPING SETS ((q.subject), (q.topic), (q.difficulty), (q.type))
+ORDER BY facet, count DESC, value;
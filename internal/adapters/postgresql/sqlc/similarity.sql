@@ -0,0 +1,123 @@
+-- name: SaveQuestionSignature :exec
+INSERT INTO question_signatures (
+  question_id,
+  minhash,
+  bands
+)
+VALUES ($1, $2, $3)
+ON CONFLICT (question_id) DO UPDATE
+SET minhash = EXCLUDED.minhash,
+    bands = EXCLUDED.bands,
+    updated_at = now();
+
+
+-- name: FindSignatureCandidates :many
+SELECT s.question_id,
+       s.minhash
+FROM question_signatures s
+JOIN questions q ON q.id = s.question_id
+WHERE s.bands && @bands::bigint[]
+  AND s.question_id <> @question_id
+  AND q.merged_into IS NULL;
+
+
+-- name: ListUnindexedQuestions :many
+SELECT q.*
+FROM questions q
+WHERE NOT EXISTS (SELECT 1 FROM question_signatures s WHERE s.question_id = q.id)
+  AND q.merged_into IS NULL
+ORDER BY q.id
+LIMIT $1;
+
+
+-- name: CountUnindexedQuestions :one
+SELECT COUNT(*)
+FROM questions q
+WHERE NOT EXISTS (SELECT 1 FROM question_signatures s WHERE s.question_id = q.id)
+  AND q.merged_into IS NULL;
+
+
+-- name: ClearOpenDuplicateFlags :exec
+DELETE FROM duplicate_flags
+WHERE status = 'open'
+  AND (question_id = $1 OR duplicate_of = $1);
+
+
+-- name: FlagDuplicate :exec
+INSERT INTO duplicate_flags (
+  question_id,
+  duplicate_of,
+  similarity
+)
+VALUES ($1, $2, $3)
+ON CONFLICT (question_id, duplicate_of) DO NOTHING;
+
+
+-- name: GetDuplicateFlag :one
+SELECT *
+FROM duplicate_flags
+WHERE id = $1;
+
+
+-- name: ListDuplicateFlags :many
+SELECT f.id,
+       f.question_id,
+       a.stem AS question_stem,
+       a.status AS question_status,
+       f.duplicate_of,
+       b.stem AS duplicate_stem,
+       b.status AS duplicate_status,
+       f.similarity,
+       f.status,
+       f.created_at,
+       f.resolved_by,
+       f.resolved_at
+FROM duplicate_flags f
+JOIN questions a ON a.id = f.question_id
+JOIN questions b ON b.id = f.duplicate_of
+WHERE (sqlc.narg(status)::duplicate_status IS NULL OR f.status = sqlc.narg(status))
+  AND (sqlc.narg(question_id)::bigint IS NULL OR sqlc.narg(question_id) IN (f.question_id, f.duplicate_of))
+ORDER BY f.similarity DESC, f.id
+LIMIT @limit OFFSET @offset;
+
+
+-- name: ResolveDuplicateFlag :one
+UPDATE duplicate_flags
+SET status = $2,
+    resolved_by = $3,
+    resolved_at = now()
+WHERE id = $1
+  AND status = 'open'
+RETURNING *;
+
+
+-- name: DismissDuplicateFlagsFor :exec
+UPDATE duplicate_flags
+SET status = 'dismissed',
+    resolved_by = $2,
+    resolved_at = now()
+WHERE status = 'open'
+  AND (question_id = $1 OR duplicate_of = $1);
+
+
+-- name: MergeQuestion :one
+UPDATE questions
+SET status = 'retired',
+    merged_into = $2,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+
+-- name: MoveMergedExamQuestions :execrows
+UPDATE exam_questions eq
+SET question_id = @keep_id
+WHERE eq.question_id = @merged_id
+  AND NOT EXISTS (SELECT 1 FROM exam_attempts t WHERE t.exam_id = eq.exam_id)
+  AND NOT EXISTS (SELECT 1 FROM exam_questions k WHERE k.exam_id = eq.exam_id AND k.question_id = @keep_id);
+
+
+-- name: DropMergedExamQuestions :execrows
+DELETE FROM exam_questions eq
+WHERE eq.question_id = @merged_id
+  AND NOT EXISTS (SELECT 1 FROM exam_attempts t WHERE t.exam_id = eq.exam_id);
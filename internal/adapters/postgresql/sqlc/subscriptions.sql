@@ -0,0 +1,132 @@
+-- name: CreatePlan :one
+INSERT INTO plans (
+  name,
+  description,
+  period_days,
+  grace_days,
+  created_by
+)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+
+-- name: UpdatePlan :one
+UPDATE plans
+SET name = $2,
+    description = $3,
+    period_days = $4,
+    grace_days = $5,
+    active = $6,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+
+-- name: GetPlan :one
+SELECT *
+FROM plans
+WHERE id = $1;
+
+
+-- name: ListPlans :many
+SELECT *
+FROM plans
+WHERE active OR NOT @active_only::boolean
+ORDER BY name;
+
+
+-- name: AddPlanItem :exec
+INSERT INTO plan_items (
+  plan_id,
+  target_type,
+  target_id
+)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+
+-- name: RemovePlanItem :execrows
+DELETE FROM plan_items
+WHERE plan_id = $1
+  AND target_type = $2
+  AND target_id = $3;
+
+
+-- name: ListPlanItems :many
+SELECT *
+FROM plan_items
+WHERE plan_id = $1
+ORDER BY target_type, target_id;
+
+
+-- name: GetSubscriptionForUpdate :one
+SELECT *
+FROM subscriptions
+WHERE user_id = $1
+  AND plan_id = $2
+FOR UPDATE;
+
+
+-- name: CreateSubscription :one
+INSERT INTO subscriptions (
+  user_id,
+  plan_id,
+  current_period_start,
+  current_period_end,
+  grace_ends_at
+)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+
+-- name: RenewSubscription :one
+UPDATE subscriptions
+SET current_period_start = $2,
+    current_period_end = $3,
+    grace_ends_at = $4,
+    cancelled_at = NULL,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+
+-- name: CancelSubscription :one
+UPDATE subscriptions
+SET cancelled_at = now(),
+    grace_ends_at = current_period_end,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+
+-- name: TerminateSubscription :one
+UPDATE subscriptions
+SET current_period_end = LEAST(current_period_end, now()),
+    grace_ends_at = LEAST(current_period_end, now()),
+    cancelled_at = COALESCE(cancelled_at, now()),
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+
+-- name: ListUserSubscriptions :many
+SELECT s.*, p.name AS plan_name
+FROM subscriptions s
+JOIN plans p ON p.id = s.plan_id
+WHERE s.user_id = $1
+ORDER BY s.grace_ends_at DESC;
+
+
+-- name: GetPlanEntitlement :one
+SELECT s.id,
+       s.plan_id,
+       s.current_period_end,
+       s.grace_ends_at
+FROM subscriptions s
+JOIN plan_items i ON i.plan_id = s.plan_id
+WHERE s.user_id = $1
+  AND i.target_type = $2
+  AND i.target_id = $3
+  AND s.grace_ends_at > now()
+ORDER BY s.grace_ends_at DESC
+LIMIT 1;
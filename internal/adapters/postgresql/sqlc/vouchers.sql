@@ -0,0 +1,136 @@
+-- name: CreateVoucherBatch :one
+INSERT INTO voucher_batches (
+  name,
+  target_type,
+  target_id,
+  quantity,
+  max_uses,
+  unit_price,
+  expires_at,
+  created_by
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+
+-- name: CreateVouchers :execrows
+INSERT INTO vouchers (
+  batch_id,
+  serial,
+  pin_hash
+)
+SELECT @batch_id::bigint,
+       unnest(@serials::text[]),
+       unnest(@pin_hashes::text[]);
+
+
+-- name: GetVoucherBatch :one
+SELECT *
+FROM voucher_batches
+WHERE id = $1;
+
+
+-- name: ListVoucherBatches :many
+SELECT *
+FROM voucher_batches
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
+
+
+-- name: GetVoucherBatchStats :one
+SELECT count(*) AS total,
+       count(*) FILTER (WHERE agent_id IS NOT NULL) AS allocated,
+       count(*) FILTER (WHERE uses > 0) AS used,
+       count(*) FILTER (WHERE status = 'void') AS void
+FROM vouchers
+WHERE batch_id = $1;
+
+
+-- name: AllocateVouchers :many
+UPDATE vouchers
+SET agent_id = @agent_id,
+    allocated_at = now()
+WHERE id IN (
+  SELECT v.id
+  FROM vouchers v
+  WHERE v.batch_id = @batch_id
+    AND v.agent_id IS NULL
+    AND v.uses = 0
+    AND v.status = 'active'
+  ORDER BY v.id
+  LIMIT @quantity
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING serial;
+
+
+-- name: VoidVoucher :execrows
+UPDATE vouchers
+SET status = 'void'
+WHERE serial = $1
+  AND status = 'active';
+
+
+-- name: GetVoucherBySerialForUpdate :one
+SELECT *
+FROM vouchers
+WHERE serial = $1
+FOR UPDATE;
+
+
+-- name: CreateVoucherRedemption :one
+INSERT INTO voucher_redemptions (
+  voucher_id,
+  user_id
+)
+VALUES ($1, $2)
+ON CONFLICT (voucher_id, user_id) DO NOTHING
+RETURNING *;
+
+
+-- name: IncrementVoucherUses :exec
+UPDATE vouchers
+SET uses = uses + 1
+WHERE id = $1;
+
+
+-- name: ListAgentStock :many
+SELECT b.id AS batch_id,
+       b.name,
+       b.target_type,
+       b.target_id,
+       b.unit_price,
+       b.expires_at,
+       count(*) AS allocated,
+       count(*) FILTER (WHERE v.uses > 0) AS sold,
+       count(*) FILTER (
+         WHERE v.uses = 0
+           AND v.status = 'active'
+           AND (b.expires_at IS NULL OR b.expires_at > now())
+       ) AS available,
+       count(*) FILTER (
+         WHERE v.uses = 0
+           AND b.expires_at IS NOT NULL
+           AND b.expires_at <= now()
+       ) AS expired
+FROM vouchers v
+JOIN voucher_batches b ON b.id = v.batch_id
+WHERE v.agent_id = $1
+GROUP BY b.id
+ORDER BY b.created_at DESC;
+
+
+-- name: ListAgentSales :many
+SELECT v.serial,
+       b.id AS batch_id,
+       b.name,
+       b.unit_price,
+       min(r.redeemed_at)::timestamptz AS sold_at
+FROM vouchers v
+JOIN voucher_batches b ON b.id = v.batch_id
+JOIN voucher_redemptions r ON r.voucher_id = v.id
+WHERE v.agent_id = @agent_id
+GROUP BY v.id, b.id
+HAVING min(r.redeemed_at) >= @sold_from::timestamptz
+   AND min(r.redeemed_at) < @sold_to::timestamptz
+ORDER BY sold_at DESC;
@@ -0,0 +1,199 @@
+package adaptive
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/helpers"
+	"github.com/odundlaw/cbt-backend/internal/json"
+	"github.com/odundlaw/cbt-backend/internal/middlewares"
+	"github.com/odundlaw/cbt-backend/internal/store"
+	"github.com/odundlaw/cbt-backend/internal/validation"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service,
+	}
+}
+
+func (h *Handler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	settings, err := h.service.GetSettings(r.Context(), examID)
+	if err != nil {
+		writeAdaptiveError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, settings, nil)
+}
+
+func (h *Handler) UpsertSettings(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req upsertSettingsParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	settings, err := h.service.UpsertSettings(r.Context(), examID, req)
+	if errors.Is(err, pgx.ErrNoRows) {
+		json.JSONError(w, http.StatusNotFound, constants.ErrExamNotFound, nil)
+		return
+	}
+	if err != nil {
+		writeAdaptiveError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgAdaptiveSettingsSaved, settings, nil)
+}
+
+func (h *Handler) DeleteSettings(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	if err := h.service.DeleteSettings(r.Context(), examID); err != nil {
+		writeAdaptiveError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgAdaptiveDisabled, nil, nil)
+}
+
+func (h *Handler) SetItemParameters(w http.ResponseWriter, r *http.Request) {
+	questionID, err := helpers.IDParam(r, "questionID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req itemParameters
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	question, err := h.service.SetItemParameters(r.Context(), questionID, req)
+	if errors.Is(err, pgx.ErrNoRows) {
+		json.JSONError(w, http.StatusNotFound, constants.ErrQuestionNotFound, nil)
+		return
+	}
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgItemParametersSaved, question, nil)
+}
+
+// Next returns the question to answer now, or the final estimate once the
+// test has stopped.
+func (h *Handler) Next(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	attemptID, err := helpers.IDParam(r, "attemptID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	state, err := h.service.Next(r.Context(), userID, attemptID)
+	if err != nil {
+		writeAdaptiveError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, state, nil)
+}
+
+func (h *Handler) Answer(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	attemptID, err := helpers.IDParam(r, "attemptID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req answerParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	state, err := h.service.Answer(r.Context(), userID, attemptID, req)
+	if err != nil {
+		writeAdaptiveError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgAdaptiveAnswerSaved, state, nil)
+}
+
+func writeAdaptiveError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		json.JSONError(w, http.StatusNotFound, constants.ErrNotFound, nil)
+	case errors.Is(err, ErrNotAdaptiveExam), errors.Is(err, ErrEmptyItemPool):
+		json.JSONError(w, http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, store.ErrAttemptNotOwner):
+		json.JSONError(w, http.StatusForbidden, err.Error(), nil)
+	case errors.Is(err, store.ErrAttemptClosed), errors.Is(err, store.ErrAttemptExpired),
+		errors.Is(err, ErrNotCurrentItem):
+		json.JSONError(w, http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, ErrBlankAnswer), errors.Is(err, ErrInvalidAnswer),
+		errors.Is(err, ErrInvalidAdaptiveSettings):
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+	default:
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+	}
+}
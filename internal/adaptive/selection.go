@@ -0,0 +1,123 @@
+package adaptive
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand/v2"
+	"sort"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/irt"
+)
+
+// estimate re-scores the candidate from every response so far. MLE has no
+// answer while all responses are right or all wrong, so EAP stands in.
+func estimate(estimator repo.AbilityEstimator, responses []repo.AdaptiveResponse) (float64, float64) {
+	scored := make([]irt.Response, 0, len(responses))
+	for _, r := range responses {
+		correct := 0.0
+		if r.Fraction >= 1 {
+			correct = 1
+		}
+		scored = append(scored, irt.Response{
+			Item:    irt.Item{A: r.IrtA, B: r.IrtB, C: r.IrtC},
+			Correct: correct,
+		})
+	}
+
+	if estimator == repo.AbilityEstimatorMle {
+		if theta, se, ok := irt.MLE(scored); ok {
+			return theta, se
+		}
+	}
+
+	return irt.EAP(scored)
+}
+
+// scaledScore maps theta onto the reporting scale, clipped at four standard
+// deviations either side of the mean.
+func scaledScore(settings repo.ExamAdaptiveSetting, theta float64) float64 {
+	score := settings.ScaleMean + settings.ScaleSd*theta
+	score = math.Max(settings.ScaleMean-4*settings.ScaleSd, math.Min(settingsMax(settings), score))
+	return math.Round(score*100) / 100
+}
+
+func settingsMax(settings repo.ExamAdaptiveSetting) float64 {
+	return settings.ScaleMean + 4*settings.ScaleSd
+}
+
+// selectItem picks the next item. Items already given are skipped, and so
+// are items over the exposure cap unless nothing else is left. When content
+// targets are set the section furthest behind its target goes first. The
+// item is then drawn at random from the few most informative at theta, so
+// the best items are not handed to every candidate.
+func selectItem(settings repo.ExamAdaptiveSetting, pool []repo.ListAdaptivePoolRow, responses []repo.AdaptiveResponse, theta float64, candidates int64) (repo.ListAdaptivePoolRow, bool) {
+	given := make(map[int64]bool, len(responses))
+	bySection := make(map[string]int, len(responses))
+	for _, r := range responses {
+		given[r.QuestionID] = true
+		bySection[r.Section]++
+	}
+
+	var fresh, rested []repo.ListAdaptivePoolRow
+	for _, item := range pool {
+		if given[item.QuestionID] {
+			continue
+		}
+		if candidates > 0 && float64(item.Administered)/float64(candidates) >= settings.MaxExposureRate {
+			rested = append(rested, item)
+			continue
+		}
+		fresh = append(fresh, item)
+	}
+
+	available := fresh
+	if len(available) == 0 {
+		available = rested
+	}
+	if len(available) == 0 {
+		return repo.ListAdaptivePoolRow{}, false
+	}
+
+	available = balanceContent(settings, available, bySection, len(responses))
+
+	sort.Slice(available, func(i, j int) bool {
+		return info(available[i], theta) > info(available[j], theta)
+	})
+
+	k := min(int(settings.Randomesque), len(available))
+	return available[rand.IntN(k)], true
+}
+
+func balanceContent(settings repo.ExamAdaptiveSetting, available []repo.ListAdaptivePoolRow, bySection map[string]int, given int) []repo.ListAdaptivePoolRow {
+	var targets map[string]float64
+	if err := json.Unmarshal(settings.ContentTargets, &targets); err != nil || len(targets) == 0 {
+		return available
+	}
+
+	inSection := make(map[string][]repo.ListAdaptivePoolRow)
+	for _, item := range available {
+		inSection[item.Section] = append(inSection[item.Section], item)
+	}
+
+	best, bestDeficit := "", math.Inf(-1)
+	for section, target := range targets {
+		if len(inSection[section]) == 0 {
+			continue
+		}
+		deficit := target*float64(given+1) - float64(bySection[section])
+		if deficit > bestDeficit || (deficit == bestDeficit && section < best) {
+			best, bestDeficit = section, deficit
+		}
+	}
+
+	if best == "" {
+		return available
+	}
+
+	return inSection[best]
+}
+
+func info(item repo.ListAdaptivePoolRow, theta float64) float64 {
+	return irt.Item{A: item.IrtA, B: item.IrtB, C: item.IrtC}.Information(theta)
+}
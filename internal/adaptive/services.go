@@ -0,0 +1,472 @@
+// Package adaptive where adaptive exams choose each next question from the candidate's current ability estimate
+package adaptive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/config"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/media"
+	"github.com/odundlaw/cbt-backend/internal/questions"
+	"github.com/odundlaw/cbt-backend/internal/store"
+)
+
+var (
+	ErrNotAdaptiveExam         = errors.New(constants.ErrNotAdaptiveExam)
+	ErrNotCurrentItem          = errors.New(constants.ErrNotCurrentItem)
+	ErrBlankAnswer             = errors.New(constants.ErrBlankAdaptiveAnswer)
+	ErrInvalidAnswer           = errors.New(constants.ErrInvalidPracticeAnswer)
+	ErrInvalidAdaptiveSettings = errors.New(constants.ErrInvalidAdaptiveSettings)
+	ErrEmptyItemPool           = errors.New(constants.ErrEmptyItemPool)
+)
+
+// Defaults for settings left out of a request. They match the column
+// defaults.
+const (
+	defaultMinItems        = 5
+	defaultMaxItems        = 30
+	defaultSeThreshold     = 0.3
+	defaultRandomesque     = 5
+	defaultMaxExposureRate = 0.3
+	defaultScaleMean       = 500
+	defaultScaleSd         = 100
+)
+
+type svc struct {
+	repo      *repo.Queries
+	db        *pgxpool.Pool
+	submitter Submitter
+}
+
+func NewService(repo *repo.Queries, db *pgxpool.Pool, submitter Submitter) Service {
+	return &svc{repo: repo, db: db, submitter: submitter}
+}
+
+func (s *svc) GetSettings(ctx context.Context, examID int64) (repo.ExamAdaptiveSetting, error) {
+	settings, err := s.repo.GetAdaptiveSettings(ctx, examID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.ExamAdaptiveSetting{}, ErrNotAdaptiveExam
+	}
+
+	return settings, err
+}
+
+// UpsertSettings makes the exam adaptive. The exam's questions become the
+// item pool; only those with IRT parameters are ever given.
+func (s *svc) UpsertSettings(ctx context.Context, examID int64, params upsertSettingsParams) (repo.ExamAdaptiveSetting, error) {
+	if _, err := s.repo.GetExamByID(ctx, examID); err != nil {
+		return repo.ExamAdaptiveSetting{}, err
+	}
+
+	estimator := params.Estimator
+	if estimator == "" {
+		estimator = repo.AbilityEstimatorEap
+	}
+
+	minItems := orDefault(params.MinItems, defaultMinItems)
+	maxItems := orDefault(params.MaxItems, max(defaultMaxItems, minItems))
+	if minItems > maxItems {
+		return repo.ExamAdaptiveSetting{}, ErrInvalidAdaptiveSettings
+	}
+
+	targets := params.ContentTargets
+	if targets == nil {
+		targets = map[string]float64{}
+	}
+
+	raw, err := json.Marshal(targets)
+	if err != nil {
+		return repo.ExamAdaptiveSetting{}, err
+	}
+
+	scaleMean := params.ScaleMean
+	if scaleMean == 0 {
+		scaleMean = defaultScaleMean
+	}
+
+	return s.repo.UpsertAdaptiveSettings(ctx, repo.UpsertAdaptiveSettingsParams{
+		ExamID:          examID,
+		Estimator:       estimator,
+		MinItems:        minItems,
+		MaxItems:        maxItems,
+		SeThreshold:     orDefault(params.SeThreshold, defaultSeThreshold),
+		Randomesque:     orDefault(params.Randomesque, defaultRandomesque),
+		MaxExposureRate: orDefault(params.MaxExposureRate, defaultMaxExposureRate),
+		ContentTargets:  raw,
+		ScaleMean:       scaleMean,
+		ScaleSd:         orDefault(params.ScaleSd, defaultScaleSd),
+	})
+}
+
+func (s *svc) DeleteSettings(ctx context.Context, examID int64) error {
+	n, err := s.repo.DeleteAdaptiveSettings(ctx, examID)
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrNotAdaptiveExam
+	}
+
+	return nil
+}
+
+func (s *svc) SetItemParameters(ctx context.Context, questionID int64, params itemParameters) (repo.Question, error) {
+	return s.repo.UpdateQuestionIRT(ctx, repo.UpdateQuestionIRTParams{
+		ID:   questionID,
+		IrtA: pgtype.Float8{Float64: params.A, Valid: true},
+		IrtB: pgtype.Float8{Float64: params.B, Valid: true},
+		IrtC: pgtype.Float8{Float64: params.C, Valid: true},
+	})
+}
+
+// Next returns the question the candidate should answer now, choosing one
+// if none is waiting. The first call starts the test at theta = 0.
+func (s *svc) Next(ctx context.Context, userID, attemptID int64) (stateResponse, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return stateResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	attempt, settings, state, err := s.load(ctx, qtx, userID, attemptID)
+	if err != nil {
+		return stateResponse{}, err
+	}
+
+	if state.FinishedAt.Valid || attempt.Status != repo.AttemptStatusInProgress {
+		return finishedResponse(state), nil
+	}
+
+	if !attempt.ExpiresAt.Time.After(time.Now()) {
+		return stateResponse{}, store.ErrAttemptExpired
+	}
+
+	if !state.CurrentQuestionID.Valid {
+		responses, err := qtx.ListAdaptiveResponses(ctx, attemptID)
+		if err != nil {
+			return stateResponse{}, err
+		}
+
+		next, ok, err := s.pick(ctx, qtx, settings, state, responses)
+		if err != nil {
+			return stateResponse{}, err
+		}
+
+		if !ok {
+			if state.Items == 0 {
+				return stateResponse{}, ErrEmptyItemPool
+			}
+			return s.finish(ctx, tx, qtx, userID, state)
+		}
+
+		state.CurrentQuestionID = pgtype.Int8{Int64: next, Valid: true}
+	}
+
+	res, err := s.present(ctx, qtx, state)
+	if err != nil {
+		return stateResponse{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return stateResponse{}, err
+	}
+
+	return res, nil
+}
+
+// Answer scores the presented question, updates the ability estimate and
+// either presents the next question or, when a stopping rule is met, ends
+// the test and submits the attempt. The test stops at max_items, or once
+// min_items are answered and the standard error is at or below
+// se_threshold, or when the pool runs out.
+func (s *svc) Answer(ctx context.Context, userID, attemptID int64, params answerParams) (stateResponse, error) {
+	if questions.IsBlank(params.Answer) {
+		return stateResponse{}, ErrBlankAnswer
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return stateResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	attempt, settings, state, err := s.load(ctx, qtx, userID, attemptID)
+	if err != nil {
+		return stateResponse{}, err
+	}
+
+	if state.FinishedAt.Valid || attempt.Status != repo.AttemptStatusInProgress {
+		return stateResponse{}, store.ErrAttemptClosed
+	}
+
+	if !attempt.ExpiresAt.Time.After(time.Now()) {
+		return stateResponse{}, store.ErrAttemptExpired
+	}
+
+	if !state.CurrentQuestionID.Valid || state.CurrentQuestionID.Int64 != params.QuestionID {
+		return stateResponse{}, ErrNotCurrentItem
+	}
+
+	question, err := qtx.GetQuestionByID(ctx, params.QuestionID)
+	if err != nil {
+		return stateResponse{}, err
+	}
+
+	// The answer is graded against the version the candidate was shown.
+	delivered, err := qtx.GetAttemptQuestion(ctx, repo.GetAttemptQuestionParams{
+		AttemptID:  attemptID,
+		QuestionID: params.QuestionID,
+	})
+	if err != nil {
+		return stateResponse{}, err
+	}
+
+	grader, err := questions.GraderFor(delivered.Type)
+	if err != nil {
+		return stateResponse{}, err
+	}
+
+	result, err := grader.Grade(delivered.AnswerKey, params.Answer)
+	if errors.Is(err, questions.ErrInvalidAnswerKey) {
+		return stateResponse{}, err
+	}
+	if err != nil {
+		return stateResponse{}, ErrInvalidAnswer
+	}
+
+	pool, err := qtx.ListAdaptivePool(ctx, attempt.ExamID)
+	if err != nil {
+		return stateResponse{}, err
+	}
+
+	// Parameters are taken from the pool as the item was given; a question
+	// recalibrated or pulled since keeps its own stored parameters.
+	response := repo.AdaptiveResponse{
+		AttemptID:  attemptID,
+		QuestionID: params.QuestionID,
+		Fraction:   result.Fraction,
+		IrtA:       question.IrtA.Float64,
+		IrtB:       question.IrtB.Float64,
+		IrtC:       question.IrtC.Float64,
+	}
+	for _, item := range pool {
+		if item.QuestionID == params.QuestionID {
+			response.Section = item.Section
+			response.IrtA, response.IrtB, response.IrtC = item.IrtA, item.IrtB, item.IrtC
+			break
+		}
+	}
+
+	responses, err := qtx.ListAdaptiveResponses(ctx, attemptID)
+	if err != nil {
+		return stateResponse{}, err
+	}
+	responses = append(responses, response)
+
+	theta, se := estimate(settings.Estimator, responses)
+	items := state.Items + 1
+	finished := items >= settings.MaxItems || (items >= settings.MinItems && se <= settings.SeThreshold)
+
+	if err := qtx.CreateAdaptiveResponse(ctx, repo.CreateAdaptiveResponseParams{
+		AttemptID:  attemptID,
+		QuestionID: params.QuestionID,
+		Seq:        items,
+		Section:    response.Section,
+		IrtA:       response.IrtA,
+		IrtB:       response.IrtB,
+		IrtC:       response.IrtC,
+		Answer:     params.Answer,
+		Fraction:   result.Fraction,
+		Theta:      theta,
+		Se:         se,
+	}); err != nil {
+		return stateResponse{}, err
+	}
+
+	state, err = qtx.UpdateAdaptiveEstimate(ctx, repo.UpdateAdaptiveEstimateParams{
+		Theta:       theta,
+		Se:          se,
+		ScaledScore: scaledScore(settings, theta),
+		Finished:    finished,
+		AttemptID:   attemptID,
+	})
+	if err != nil {
+		return stateResponse{}, err
+	}
+
+	if finished {
+		return s.finish(ctx, tx, qtx, userID, state)
+	}
+
+	next, ok, err := s.pick(ctx, qtx, settings, state, responses)
+	if err != nil {
+		return stateResponse{}, err
+	}
+
+	if !ok {
+		return s.finish(ctx, tx, qtx, userID, state)
+	}
+
+	state.CurrentQuestionID = pgtype.Int8{Int64: next, Valid: true}
+
+	res, err := s.present(ctx, qtx, state)
+	if err != nil {
+		return stateResponse{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return stateResponse{}, err
+	}
+
+	return res, nil
+}
+
+// load checks the attempt belongs to the candidate and is for an adaptive
+// exam, then locks its state, creating it on first use.
+func (s *svc) load(ctx context.Context, q *repo.Queries, userID, attemptID int64) (repo.ExamAttempt, repo.ExamAdaptiveSetting, repo.AdaptiveState, error) {
+	attempt, err := q.GetAttemptByID(ctx, attemptID)
+	if err != nil {
+		return repo.ExamAttempt{}, repo.ExamAdaptiveSetting{}, repo.AdaptiveState{}, err
+	}
+
+	if attempt.UserID != userID {
+		return repo.ExamAttempt{}, repo.ExamAdaptiveSetting{}, repo.AdaptiveState{}, store.ErrAttemptNotOwner
+	}
+
+	settings, err := q.GetAdaptiveSettings(ctx, attempt.ExamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.ExamAttempt{}, repo.ExamAdaptiveSetting{}, repo.AdaptiveState{}, ErrNotAdaptiveExam
+	}
+	if err != nil {
+		return repo.ExamAttempt{}, repo.ExamAdaptiveSetting{}, repo.AdaptiveState{}, err
+	}
+
+	if _, err := q.CreateAdaptiveState(ctx, repo.CreateAdaptiveStateParams{
+		AttemptID:   attemptID,
+		ExamID:      attempt.ExamID,
+		ScaledScore: scaledScore(settings, 0),
+	}); err != nil {
+		return repo.ExamAttempt{}, repo.ExamAdaptiveSetting{}, repo.AdaptiveState{}, err
+	}
+
+	state, err := q.GetAdaptiveStateForUpdate(ctx, attemptID)
+	if err != nil {
+		return repo.ExamAttempt{}, repo.ExamAdaptiveSetting{}, repo.AdaptiveState{}, err
+	}
+
+	return attempt, settings, state, nil
+}
+
+// pick chooses the next item and records that it was given.
+func (s *svc) pick(ctx context.Context, q *repo.Queries, settings repo.ExamAdaptiveSetting, state repo.AdaptiveState, responses []repo.AdaptiveResponse) (int64, bool, error) {
+	pool, err := q.ListAdaptivePool(ctx, state.ExamID)
+	if err != nil {
+		return 0, false, err
+	}
+
+	candidates, err := q.CountAdaptiveStates(ctx, state.ExamID)
+	if err != nil {
+		return 0, false, err
+	}
+
+	item, ok := selectItem(settings, pool, responses, state.Theta, candidates)
+	if !ok {
+		return 0, false, nil
+	}
+
+	if err := q.SetAdaptiveCurrentQuestion(ctx, repo.SetAdaptiveCurrentQuestionParams{
+		AttemptID:         state.AttemptID,
+		CurrentQuestionID: pgtype.Int8{Int64: item.QuestionID, Valid: true},
+	}); err != nil {
+		return 0, false, err
+	}
+
+	if err := q.RecordAdaptiveExposure(ctx, repo.RecordAdaptiveExposureParams{
+		ExamID:     state.ExamID,
+		QuestionID: item.QuestionID,
+	}); err != nil {
+		return 0, false, err
+	}
+
+	return item.QuestionID, true, nil
+}
+
+// finish ends the test and submits the attempt once the state is saved.
+func (s *svc) finish(ctx context.Context, tx pgx.Tx, q *repo.Queries, userID int64, state repo.AdaptiveState) (stateResponse, error) {
+	if err := q.FinishAdaptiveState(ctx, state.AttemptID); err != nil {
+		return stateResponse{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return stateResponse{}, err
+	}
+
+	if _, err := s.submitter.SubmitAttempt(ctx, userID, state.AttemptID); err != nil {
+		return stateResponse{}, err
+	}
+
+	return finishedResponse(state), nil
+}
+
+// present shows the current item, fixing the version the candidate sees the
+// first time it is presented.
+func (s *svc) present(ctx context.Context, q *repo.Queries, state repo.AdaptiveState) (stateResponse, error) {
+	if err := q.RecordAttemptQuestionVersion(ctx, repo.RecordAttemptQuestionVersionParams{
+		AttemptID:  state.AttemptID,
+		QuestionID: state.CurrentQuestionID.Int64,
+	}); err != nil {
+		return stateResponse{}, err
+	}
+
+	question, err := q.GetAttemptQuestion(ctx, repo.GetAttemptQuestionParams{
+		AttemptID:  state.AttemptID,
+		QuestionID: state.CurrentQuestionID.Int64,
+	})
+	if err != nil {
+		return stateResponse{}, err
+	}
+
+	// Links last a while rather than to the end of the attempt, since the
+	// next item is fetched afresh anyway.
+	expires := time.Now().Add(time.Duration(config.MediaURLTTLMinutes) * time.Minute)
+
+	return stateResponse{
+		Items: state.Items,
+		Question: &adaptiveQuestion{
+			QuestionID: question.QuestionID,
+			Type:       question.Type,
+			Stem:       media.SignReferences(question.Stem, state.AttemptID, expires),
+			Options:    json.RawMessage(media.SignReferences(string(question.Options), state.AttemptID, expires)),
+		},
+	}, nil
+}
+
+// finishedResponse reports the estimate only once the test is over.
+func finishedResponse(state repo.AdaptiveState) stateResponse {
+	return stateResponse{
+		Items:       state.Items,
+		Finished:    true,
+		Theta:       &state.Theta,
+		SE:          &state.Se,
+		ScaledScore: &state.ScaledScore,
+	}
+}
+
+func orDefault[T int32 | float64](v, def T) T {
+	if v == 0 {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,64 @@
+package adaptive
+
+import (
+	"context"
+	"encoding/json"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+type Service interface {
+	GetSettings(ctx context.Context, examID int64) (repo.ExamAdaptiveSetting, error)
+	UpsertSettings(ctx context.Context, examID int64, params upsertSettingsParams) (repo.ExamAdaptiveSetting, error)
+	DeleteSettings(ctx context.Context, examID int64) error
+	SetItemParameters(ctx context.Context, questionID int64, params itemParameters) (repo.Question, error)
+	Next(ctx context.Context, userID, attemptID int64) (stateResponse, error)
+	Answer(ctx context.Context, userID, attemptID int64, params answerParams) (stateResponse, error)
+}
+
+// Submitter closes the attempt once the test stops, which also grades it.
+type Submitter interface {
+	SubmitAttempt(ctx context.Context, userID, attemptID int64) (repo.ExamAttempt, error)
+}
+
+type upsertSettingsParams struct {
+	Estimator       repo.AbilityEstimator `json:"estimator" validate:"omitempty,oneof=eap mle"`
+	MinItems        int32                 `json:"min_items" validate:"gte=0"`
+	MaxItems        int32                 `json:"max_items" validate:"gte=0"`
+	SeThreshold     float64               `json:"se_threshold" validate:"gte=0"`
+	Randomesque     int32                 `json:"randomesque" validate:"gte=0"`
+	MaxExposureRate float64               `json:"max_exposure_rate" validate:"gte=0,lte=1"`
+	// ContentTargets is the share of items wanted from each section.
+	ContentTargets map[string]float64 `json:"content_targets" validate:"dive,gte=0,lte=1"`
+	ScaleMean      float64            `json:"scale_mean"`
+	ScaleSd        float64            `json:"scale_sd" validate:"gte=0"`
+}
+
+type itemParameters struct {
+	A float64 `json:"a" validate:"required,gt=0,lte=5"`
+	B float64 `json:"b" validate:"gte=-6,lte=6"`
+	C float64 `json:"c" validate:"gte=0,lt=1"`
+}
+
+type answerParams struct {
+	QuestionID int64           `json:"question_id" validate:"required,gt=0"`
+	Answer     json.RawMessage `json:"answer" validate:"required"`
+}
+
+type adaptiveQuestion struct {
+	QuestionID int64             `json:"question_id"`
+	Type       repo.QuestionType `json:"type"`
+	Stem       string            `json:"stem"`
+	Options    json.RawMessage   `json:"options"`
+}
+
+// stateResponse shows the candidate where the test stands. Question is nil
+// once it has finished; correctness is never revealed during the test.
+type stateResponse struct {
+	Items       int32             `json:"items"`
+	Finished    bool              `json:"finished"`
+	Theta       *float64          `json:"theta,omitempty"`
+	SE          *float64          `json:"se,omitempty"`
+	ScaledScore *float64          `json:"scaled_score,omitempty"`
+	Question    *adaptiveQuestion `json:"question"`
+}
@@ -0,0 +1,93 @@
+package analysis
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var itemHeader = []string{
+	"question_id", "section", "position", "type", "stem", "responses", "omitted",
+	"p_value", "discrimination", "point_biserial", "alpha_if_deleted", "flags",
+}
+
+var distractorHeader = []string{
+	"question_id", "option", "is_key", "chosen", "proportion",
+	"upper_proportion", "lower_proportion", "point_biserial",
+}
+
+// writeItemsCSV writes one row per question. Flags are joined with "|".
+func writeItemsCSV(w io.Writer, report reportResponse) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(itemHeader); err != nil {
+		return err
+	}
+
+	for _, it := range report.Items {
+		s := it.Item
+		if err := cw.Write([]string{
+			strconv.FormatInt(s.QuestionID, 10),
+			s.Section,
+			strconv.Itoa(int(s.Position)),
+			string(s.Type),
+			s.Stem,
+			strconv.Itoa(int(s.Responses)),
+			strconv.Itoa(int(s.Omitted)),
+			formatFloat(s.PValue),
+			formatFloat(s.Discrimination),
+			formatFloat8(s.PointBiserial),
+			formatFloat8(s.AlphaIfDeleted),
+			strings.Join(s.Flags, "|"),
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeDistractorsCSV writes one row per option of each choice question.
+func writeDistractorsCSV(w io.Writer, report reportResponse) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(distractorHeader); err != nil {
+		return err
+	}
+
+	for _, it := range report.Items {
+		for _, d := range it.Distractors {
+			if err := cw.Write([]string{
+				strconv.FormatInt(d.QuestionID, 10),
+				d.Option,
+				strconv.FormatBool(d.IsKey),
+				strconv.Itoa(int(d.Chosen)),
+				formatFloat(d.Proportion),
+				formatFloat(d.UpperProportion),
+				formatFloat(d.LowerProportion),
+				formatFloat8(d.PointBiserial),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// formatFloat8 leaves undefined statistics empty.
+func formatFloat8(v pgtype.Float8) string {
+	if !v.Valid {
+		return ""
+	}
+	return formatFloat(v.Float64)
+}
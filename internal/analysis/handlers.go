@@ -0,0 +1,143 @@
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/helpers"
+	"github.com/odundlaw/cbt-backend/internal/json"
+	"github.com/odundlaw/cbt-backend/internal/middlewares"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service,
+	}
+}
+
+// RequestAnalysis queues an item analysis of the exam's graded attempts. The
+// run is computed in the background; poll it with GetReport.
+func (h *Handler) RequestAnalysis(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	run, err := h.service.RequestAnalysis(r.Context(), examID, userID)
+	if err != nil {
+		writeAnalysisError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusAccepted, constants.MsgAnalysisQueued, run, nil)
+}
+
+func (h *Handler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	limit, offset := helpers.Pagination(r)
+
+	runs, err := h.service.ListRuns(r.Context(), examID, limit, offset)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, runs, nil)
+}
+
+func (h *Handler) GetReport(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	runID, err := helpers.IDParam(r, "runID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	report, err := h.service.GetReport(r.Context(), examID, runID)
+	if err != nil {
+		writeAnalysisError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, report, nil)
+}
+
+// ExportReport downloads a completed run as CSV, one row per question, or
+// one row per option with ?detail=distractors.
+func (h *Handler) ExportReport(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	runID, err := helpers.IDParam(r, "runID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	report, err := h.service.GetReport(r.Context(), examID, runID)
+	if err != nil {
+		writeAnalysisError(w, err)
+		return
+	}
+
+	if report.Run.Status != repo.AnalysisStatusCompleted {
+		writeAnalysisError(w, ErrAnalysisNotReady)
+		return
+	}
+
+	write, name := writeItemsCSV, "items"
+	if r.URL.Query().Get("detail") == "distractors" {
+		write, name = writeDistractorsCSV, "distractors"
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="item-analysis-%d-%s.csv"`, runID, name))
+	w.WriteHeader(http.StatusOK)
+
+	if err := write(w, report); err != nil {
+		fmt.Println("failed to write item analysis csv:", err)
+	}
+}
+
+func writeAnalysisError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		json.JSONError(w, http.StatusNotFound, constants.ErrExamNotFound, nil)
+	case errors.Is(err, ErrAnalysisRunNotFound):
+		json.JSONError(w, http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, ErrAnalysisNotReady):
+		json.JSONError(w, http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, ErrAdaptiveExam):
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+	default:
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+	}
+}
@@ -0,0 +1,159 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+// Worker is the reporting job. It picks up queued runs, computes them from
+// the exam's graded attempts and stores the statistics. A run left running
+// by a worker that died is picked up again after half an hour.
+type Worker struct {
+	repo     *repo.Queries
+	db       *pgxpool.Pool
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewWorker(repo *repo.Queries, db *pgxpool.Pool, interval time.Duration, logger *slog.Logger) *Worker {
+	return &Worker{
+		repo:     repo,
+		db:       db,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run works the queue on every tick until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.drain(ctx)
+		}
+	}
+}
+
+func (w *Worker) drain(ctx context.Context) {
+	for {
+		ran, err := w.RunNext(ctx)
+		if err != nil {
+			w.logger.Error("item analysis failed", "error", err)
+			return
+		}
+		if !ran {
+			return
+		}
+	}
+}
+
+// RunNext computes the oldest queued run and reports whether there was one.
+// A run that cannot be computed is marked failed with the reason.
+func (w *Worker) RunNext(ctx context.Context) (bool, error) {
+	run, err := w.repo.ClaimAnalysisRun(ctx)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := w.compute(ctx, run); err != nil {
+		w.logger.Warn("item analysis run failed", "run_id", run.ID, "exam_id", run.ExamID, "error", err)
+		if err := w.repo.FailAnalysisRun(ctx, repo.FailAnalysisRunParams{
+			ID:    run.ID,
+			Error: pgtype.Text{String: err.Error(), Valid: true},
+		}); err != nil {
+			return true, err
+		}
+	}
+
+	return true, nil
+}
+
+func (w *Worker) compute(ctx context.Context, run repo.ItemAnalysisRun) error {
+	rows, err := w.repo.ListAnalysisResponses(ctx, run.ExamID)
+	if err != nil {
+		return err
+	}
+
+	rep := analyse(rows)
+	if rep.Attempts < 2 {
+		return ErrNotEnoughAttempts
+	}
+
+	tx, err := w.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := w.repo.WithTx(tx)
+
+	for _, item := range rep.Items {
+		if err := qtx.CreateItemStatistic(ctx, repo.CreateItemStatisticParams{
+			RunID:          run.ID,
+			QuestionID:     item.QuestionID,
+			Section:        item.Section,
+			Position:       item.Position,
+			Responses:      item.Responses,
+			Omitted:        item.Omitted,
+			PValue:         item.PValue,
+			Discrimination: item.Discrimination,
+			PointBiserial:  float8(item.PointBiserial),
+			AlphaIfDeleted: float8(item.AlphaIfDeleted),
+			Flags:          item.Flags,
+		}); err != nil {
+			return err
+		}
+
+		for _, d := range item.Distractors {
+			if err := qtx.CreateItemDistractor(ctx, repo.CreateItemDistractorParams{
+				RunID:           run.ID,
+				QuestionID:      item.QuestionID,
+				Option:          d.Option,
+				IsKey:           d.IsKey,
+				Chosen:          d.Chosen,
+				Proportion:      d.Proportion,
+				UpperProportion: d.UpperProportion,
+				LowerProportion: d.LowerProportion,
+				PointBiserial:   float8(d.PointBiserial),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := qtx.CompleteAnalysisRun(ctx, repo.CompleteAnalysisRunParams{
+		ID:        run.ID,
+		Attempts:  int32(rep.Attempts),
+		Items:     int32(len(rep.Items)),
+		MeanScore: pgtype.Float8{Float64: round(rep.Mean), Valid: true},
+		SdScore:   pgtype.Float8{Float64: round(rep.SD), Valid: true},
+		Kr20:      float8(rep.KR20),
+		Alpha:     float8(rep.Alpha),
+	}); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func float8(v *float64) pgtype.Float8 {
+	if v == nil {
+		return pgtype.Float8{}
+	}
+	return pgtype.Float8{Float64: *v, Valid: true}
+}
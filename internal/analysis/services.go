@@ -0,0 +1,103 @@
+// Package analysis where item statistics and exam reliability are computed from graded attempts
+package analysis
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+)
+
+var (
+	ErrAnalysisRunNotFound = errors.New(constants.ErrAnalysisRunNotFound)
+	ErrAnalysisNotReady    = errors.New(constants.ErrAnalysisNotReady)
+	ErrAdaptiveExam        = errors.New(constants.ErrAdaptiveItemAnalysis)
+	ErrNotEnoughAttempts   = errors.New(constants.ErrNotEnoughAttempts)
+)
+
+type svc struct {
+	repo *repo.Queries
+	db   *pgxpool.Pool
+}
+
+func NewService(repo *repo.Queries, db *pgxpool.Pool) Service {
+	return &svc{repo: repo, db: db}
+}
+
+// RequestAnalysis queues a run for the worker. While one is already queued or
+// running for the exam, that run is returned instead. Adaptive exams are
+// refused: each candidate sees items matched to their ability, which makes
+// p-values and item-total correlations meaningless.
+func (s *svc) RequestAnalysis(ctx context.Context, examID, requestedBy int64) (repo.ItemAnalysisRun, error) {
+	if _, err := s.repo.GetExamByID(ctx, examID); err != nil {
+		return repo.ItemAnalysisRun{}, err
+	}
+
+	if _, err := s.repo.GetAdaptiveSettings(ctx, examID); err == nil {
+		return repo.ItemAnalysisRun{}, ErrAdaptiveExam
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return repo.ItemAnalysisRun{}, err
+	}
+
+	run, err := s.repo.CreateAnalysisRun(ctx, repo.CreateAnalysisRunParams{
+		ExamID:      examID,
+		RequestedBy: pgtype.Int8{Int64: requestedBy, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s.repo.GetActiveAnalysisRun(ctx, examID)
+	}
+
+	return run, err
+}
+
+func (s *svc) ListRuns(ctx context.Context, examID int64, limit, offset int32) ([]repo.ItemAnalysisRun, error) {
+	return s.repo.ListAnalysisRuns(ctx, repo.ListAnalysisRunsParams{
+		ExamID: examID,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+func (s *svc) GetReport(ctx context.Context, examID, runID int64) (reportResponse, error) {
+	run, err := s.repo.GetAnalysisRun(ctx, runID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && run.ExamID != examID) {
+		return reportResponse{}, ErrAnalysisRunNotFound
+	}
+	if err != nil {
+		return reportResponse{}, err
+	}
+
+	res := reportResponse{Run: run, Items: []itemResponse{}}
+	if run.Status != repo.AnalysisStatusCompleted {
+		return res, nil
+	}
+
+	stats, err := s.repo.ListItemStatistics(ctx, runID)
+	if err != nil {
+		return reportResponse{}, err
+	}
+
+	distractors, err := s.repo.ListItemDistractors(ctx, runID)
+	if err != nil {
+		return reportResponse{}, err
+	}
+
+	byQuestion := make(map[int64][]repo.ItemDistractor, len(stats))
+	for _, d := range distractors {
+		byQuestion[d.QuestionID] = append(byQuestion[d.QuestionID], d)
+	}
+
+	for _, stat := range stats {
+		options := byQuestion[stat.QuestionID]
+		if options == nil {
+			options = []repo.ItemDistractor{}
+		}
+		res.Items = append(res.Items, itemResponse{Item: stat, Distractors: options})
+	}
+
+	return res, nil
+}
@@ -0,0 +1,468 @@
+package analysis
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/questions"
+)
+
+// Thresholds behind the item flags. They follow the usual classroom rules of
+// thumb rather than anything exam-specific.
+const (
+	groupShare        = 0.27
+	tooHardBelow      = 0.2
+	tooEasyAbove      = 0.9
+	lowDiscrimination = 0.2
+	highOmission      = 0.1
+)
+
+const (
+	flagTooHard                = "too_hard"
+	flagTooEasy                = "too_easy"
+	flagNegativeDiscrimination = "negative_discrimination"
+	flagLowDiscrimination      = "low_discrimination"
+	flagLowersReliability      = "lowers_reliability"
+	flagPossibleMiskey         = "possible_miskey"
+	flagHighOmission           = "high_omission"
+)
+
+type report struct {
+	Attempts int
+	Mean     float64
+	SD       float64
+	KR20     *float64
+	Alpha    *float64
+	Items    []itemReport
+}
+
+type itemReport struct {
+	QuestionID     int64
+	Section        string
+	Position       int32
+	Responses      int32
+	Omitted        int32
+	PValue         float64
+	Discrimination float64
+	PointBiserial  *float64
+	AlphaIfDeleted *float64
+	Flags          []string
+	Distractors    []distractorReport
+}
+
+type distractorReport struct {
+	Option          string
+	IsKey           bool
+	Chosen          int32
+	Proportion      float64
+	UpperProportion float64
+	LowerProportion float64
+	PointBiserial   *float64
+}
+
+// item gathers one question's scores, keyed by attempt index.
+type item struct {
+	row     repo.ListAnalysisResponsesRow
+	scores  map[int]float64
+	maxes   map[int]float64
+	answers map[int][]byte
+}
+
+// analyse computes classical test theory statistics from graded scores.
+// Totals are raw marks summed over every question an attempt was scored on,
+// before any policy scaling. Negative marking deductions are left out too:
+// a wrong answer counts as 0, so it neither drags p-values below what was
+// answered right nor stops right-or-wrong items from counting as such. In
+// combination exams each question is judged against the candidates who sat
+// it.
+func analyse(rows []repo.ListAnalysisResponsesRow) report {
+	attemptIndex := map[int64]int{}
+	var totals []float64
+	items := map[int64]*item{}
+	var order []*item
+
+	for _, row := range rows {
+		idx, ok := attemptIndex[row.AttemptID]
+		if !ok {
+			idx = len(totals)
+			attemptIndex[row.AttemptID] = idx
+			totals = append(totals, 0)
+		}
+		score := min(max(row.Score, 0), max(row.MaxScore, 0))
+		totals[idx] += score
+
+		it, ok := items[row.QuestionID]
+		if !ok {
+			it = &item{row: row, scores: map[int]float64{}, maxes: map[int]float64{}, answers: map[int][]byte{}}
+			items[row.QuestionID] = it
+			order = append(order, it)
+		}
+		it.scores[idx] = score
+		it.maxes[idx] = row.MaxScore
+		it.answers[idx] = row.Answer
+	}
+
+	sort.Slice(order, func(i, j int) bool {
+		a, b := order[i].row, order[j].row
+		if a.Section != b.Section {
+			return a.Section < b.Section
+		}
+		if a.Position != b.Position {
+			return a.Position < b.Position
+		}
+		return a.QuestionID < b.QuestionID
+	})
+
+	n := len(totals)
+	rep := report{Attempts: n}
+	rep.Mean, rep.SD = meanSD(totals)
+
+	upper, lower := groups(totals)
+
+	// Reliability needs a full matrix, so it uses the questions everyone
+	// was scored on.
+	var common []*item
+	for _, it := range order {
+		if len(it.scores) == n {
+			common = append(common, it)
+		}
+	}
+	rep.Alpha, rep.KR20 = reliability(common, n)
+
+	for _, it := range order {
+		r := itemReport{
+			QuestionID: it.row.QuestionID,
+			Section:    it.row.Section,
+			Position:   it.row.Position,
+			Responses:  int32(len(it.scores)),
+		}
+
+		var fractions, rest []float64
+		var sum, upperSum, lowerSum float64
+		var upperN, lowerN int
+		for idx, score := range it.scores {
+			f := fraction(score, it.maxes[idx])
+			fractions = append(fractions, f)
+			rest = append(rest, totals[idx]-score)
+			sum += f
+			if upper[idx] {
+				upperSum += f
+				upperN++
+			}
+			if lower[idx] {
+				lowerSum += f
+				lowerN++
+			}
+			if questions.IsBlank(it.answers[idx]) {
+				r.Omitted++
+			}
+		}
+
+		r.PValue = round(sum / float64(len(fractions)))
+		r.Discrimination = round(ratio(upperSum, upperN) - ratio(lowerSum, lowerN))
+		r.PointBiserial = correlation(fractions, rest)
+
+		if rep.Alpha != nil && len(it.scores) == n && len(common) > 2 {
+			others := make([]*item, 0, len(common)-1)
+			for _, c := range common {
+				if c != it {
+					others = append(others, c)
+				}
+			}
+			r.AlphaIfDeleted, _ = reliability(others, n)
+		}
+
+		r.Distractors = distractors(it, totals, upper, lower)
+		r.Flags = flags(r, rep.Alpha)
+
+		rep.Items = append(rep.Items, r)
+	}
+
+	return rep
+}
+
+// groups marks the top and bottom 27% of attempts by total.
+func groups(totals []float64) (upper, lower map[int]bool) {
+	idx := make([]int, len(totals))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool { return totals[idx[i]] < totals[idx[j]] })
+
+	size := int(math.Round(groupShare * float64(len(totals))))
+	size = max(size, 1)
+
+	upper, lower = map[int]bool{}, map[int]bool{}
+	for i := 0; i < size && i < len(idx); i++ {
+		lower[idx[i]] = true
+		upper[idx[len(idx)-1-i]] = true
+	}
+	return upper, lower
+}
+
+// reliability returns Cronbach's alpha over the given questions, and KR-20
+// when each of them was only ever scored right or wrong. Either is nil when
+// it is undefined.
+func reliability(items []*item, n int) (alpha, kr20 *float64) {
+	k := len(items)
+	if k < 2 || n < 2 {
+		return nil, nil
+	}
+
+	sums := make([]float64, n)
+	counts := make([]float64, n)
+	var itemVariance, pq float64
+	dichotomous := true
+
+	for _, it := range items {
+		scores := make([]float64, n)
+		var correct float64
+		for idx, score := range it.scores {
+			scores[idx] = score
+			sums[idx] += score
+
+			f := fraction(score, it.maxes[idx])
+			if f != 0 && f != 1 {
+				dichotomous = false
+			}
+			if f == 1 {
+				counts[idx]++
+				correct++
+			}
+		}
+
+		_, sd := meanSD(scores)
+		itemVariance += sd * sd
+
+		p := correct / float64(n)
+		pq += p * (1 - p)
+	}
+
+	factor := float64(k) / float64(k-1)
+
+	if _, sd := meanSD(sums); sd > 0 {
+		a := round(factor * (1 - itemVariance/(sd*sd)))
+		alpha = &a
+	}
+
+	if _, sd := meanSD(counts); dichotomous && sd > 0 {
+		r := round(factor * (1 - pq/(sd*sd)))
+		kr20 = &r
+	}
+
+	return alpha, kr20
+}
+
+// distractors tallies the options picked on choice questions. Options come
+// from the key and the answers given, so an option nobody picked and that is
+// not the key does not appear.
+func distractors(it *item, totals []float64, upper, lower map[int]bool) []distractorReport {
+	switch it.row.Type {
+	case repo.QuestionTypeSingleChoice, repo.QuestionTypeMultipleChoice, repo.QuestionTypeTrueFalse:
+	default:
+		return nil
+	}
+
+	key := map[string]bool{}
+	for _, opt := range options(it.row.AnswerKey) {
+		key[opt] = true
+	}
+
+	chosen := map[string]map[int]bool{}
+	for opt := range key {
+		chosen[opt] = map[int]bool{}
+	}
+
+	var upperN, lowerN int
+	for idx := range it.scores {
+		if upper[idx] {
+			upperN++
+		}
+		if lower[idx] {
+			lowerN++
+		}
+		for _, opt := range options(it.answers[idx]) {
+			if chosen[opt] == nil {
+				chosen[opt] = map[int]bool{}
+			}
+			chosen[opt][idx] = true
+		}
+	}
+
+	labels := make([]string, 0, len(chosen))
+	for opt := range chosen {
+		labels = append(labels, opt)
+	}
+	sort.Strings(labels)
+
+	out := make([]distractorReport, 0, len(labels))
+	for _, opt := range labels {
+		picked := chosen[opt]
+
+		var upperPicks, lowerPicks int
+		indicator := make([]float64, 0, len(it.scores))
+		scores := make([]float64, 0, len(it.scores))
+		for idx := range it.scores {
+			v := 0.0
+			if picked[idx] {
+				v = 1
+				if upper[idx] {
+					upperPicks++
+				}
+				if lower[idx] {
+					lowerPicks++
+				}
+			}
+			indicator = append(indicator, v)
+			scores = append(scores, totals[idx])
+		}
+
+		out = append(out, distractorReport{
+			Option:          opt,
+			IsKey:           key[opt],
+			Chosen:          int32(len(picked)),
+			Proportion:      round(ratio(float64(len(picked)), len(it.scores))),
+			UpperProportion: round(ratio(float64(upperPicks), upperN)),
+			LowerProportion: round(ratio(float64(lowerPicks), lowerN)),
+			PointBiserial:   correlation(indicator, scores),
+		})
+	}
+
+	return out
+}
+
+// options reads the labels out of a choice key or answer: "B", ["A", "C"]
+// or true.
+func options(raw []byte) []string {
+	if questions.IsBlank(raw) {
+		return nil
+	}
+
+	var one string
+	if err := json.Unmarshal(raw, &one); err == nil {
+		return []string{label(one)}
+	}
+
+	var many []string
+	if err := json.Unmarshal(raw, &many); err == nil {
+		seen := map[string]bool{}
+		out := make([]string, 0, len(many))
+		for _, opt := range many {
+			opt = label(opt)
+			if !seen[opt] {
+				seen[opt] = true
+				out = append(out, opt)
+			}
+		}
+		return out
+	}
+
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		if b {
+			return []string{"TRUE"}
+		}
+		return []string{"FALSE"}
+	}
+
+	return nil
+}
+
+func label(s string) string {
+	return strings.ToUpper(strings.TrimSpace(s))
+}
+
+func flags(r itemReport, alpha *float64) []string {
+	out := []string{}
+
+	switch {
+	case r.PValue < tooHardBelow:
+		out = append(out, flagTooHard)
+	case r.PValue > tooEasyAbove:
+		out = append(out, flagTooEasy)
+	}
+
+	if r.PointBiserial != nil {
+		switch {
+		case *r.PointBiserial < 0:
+			out = append(out, flagNegativeDiscrimination)
+		case *r.PointBiserial < lowDiscrimination:
+			out = append(out, flagLowDiscrimination)
+		}
+	}
+
+	if alpha != nil && r.AlphaIfDeleted != nil && *r.AlphaIfDeleted > *alpha {
+		out = append(out, flagLowersReliability)
+	}
+
+	// A wrong option that stronger candidates favour usually means the key
+	// is wrong or the option is arguably right.
+	for _, d := range r.Distractors {
+		if !d.IsKey && d.PointBiserial != nil && *d.PointBiserial > 0 {
+			out = append(out, flagPossibleMiskey)
+			break
+		}
+	}
+
+	if r.Responses > 0 && float64(r.Omitted)/float64(r.Responses) > highOmission {
+		out = append(out, flagHighOmission)
+	}
+
+	return out
+}
+
+func meanSD(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+
+	return mean, math.Sqrt(sq / float64(len(values)))
+}
+
+// correlation is Pearson's r, nil when either side is constant.
+func correlation(xs, ys []float64) *float64 {
+	mx, sx := meanSD(xs)
+	my, sy := meanSD(ys)
+	if sx == 0 || sy == 0 {
+		return nil
+	}
+
+	var cov float64
+	for i := range xs {
+		cov += (xs[i] - mx) * (ys[i] - my)
+	}
+	r := round(cov / float64(len(xs)) / (sx * sy))
+	return &r
+}
+
+func fraction(score, maxScore float64) float64 {
+	if maxScore <= 0 {
+		return 0
+	}
+	return score / maxScore
+}
+
+func ratio(v float64, n int) float64 {
+	if n == 0 {
+		return 0
+	}
+	return v / float64(n)
+}
+
+func round(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
@@ -0,0 +1,196 @@
+package analysis
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+// scoreRows turns a matrix of scores, one row per attempt and one column per
+// question, into analysis rows. Every question is worth maxScore and every
+// response is an answered short answer.
+func scoreRows(scores [][]float64, maxScore float64) []repo.ListAnalysisResponsesRow {
+	var rows []repo.ListAnalysisResponsesRow
+	for a, attempt := range scores {
+		for q, score := range attempt {
+			rows = append(rows, repo.ListAnalysisResponsesRow{
+				AttemptID:  int64(a + 1),
+				QuestionID: int64(q + 1),
+				Position:   int32(q + 1),
+				Type:       repo.QuestionTypeShortAnswer,
+				Score:      score,
+				MaxScore:   maxScore,
+				Answer:     []byte(`"x"`),
+			})
+		}
+	}
+	return rows
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+func TestAnalyse(t *testing.T) {
+	// A Guttman pattern: each attempt gets the easier questions right.
+	dichotomous := [][]float64{
+		{1, 1, 1},
+		{1, 1, 0},
+		{1, 0, 0},
+		{0, 0, 0},
+	}
+	// The same answers under negative marking, a quarter off when wrong.
+	negative := [][]float64{
+		{1, 1, 1},
+		{1, 1, -0.25},
+		{1, -0.25, -0.25},
+		{-0.25, -0.25, -0.25},
+	}
+	partial := [][]float64{
+		{2, 2, 2},
+		{2, 1, 0},
+		{1, 1, 0},
+		{0, 0, 1},
+	}
+
+	tests := []struct {
+		name       string
+		rows       []repo.ListAnalysisResponsesRow
+		wantMean   float64
+		wantSD     float64
+		wantKR20   *float64
+		wantAlpha  *float64
+		wantP      []float64
+		wantPBis   []*float64
+		wantDiscr  []float64
+		wantAlphas []*float64
+	}{
+		{
+			name:     "right or wrong",
+			rows:     scoreRows(dichotomous, 1),
+			wantMean: 1.5,
+			wantSD:   math.Sqrt(1.25),
+			// k/(k-1) * (1 - Σpq/σ²) = 1.5 * (1 - 0.625/1.25)
+			wantKR20:  ptr(0.75),
+			wantAlpha: ptr(0.75),
+			wantP:     []float64{0.75, 0.5, 0.25},
+			// Each item against the total of the other two.
+			wantPBis:   []*float64{ptr(0.5222), ptr(0.7071), ptr(0.5222)},
+			wantDiscr:  []float64{1, 1, 1},
+			wantAlphas: []*float64{ptr(0.7273), ptr(0.5), ptr(0.7273)},
+		},
+		{
+			name:       "negative marking counts wrong answers as 0",
+			rows:       scoreRows(negative, 1),
+			wantMean:   1.5,
+			wantSD:     math.Sqrt(1.25),
+			wantKR20:   ptr(0.75),
+			wantAlpha:  ptr(0.75),
+			wantP:      []float64{0.75, 0.5, 0.25},
+			wantPBis:   []*float64{ptr(0.5222), ptr(0.7071), ptr(0.5222)},
+			wantDiscr:  []float64{1, 1, 1},
+			wantAlphas: []*float64{ptr(0.7273), ptr(0.5), ptr(0.7273)},
+		},
+		{
+			name:     "partial credit has alpha but no KR-20",
+			rows:     scoreRows(partial, 2),
+			wantMean: 3,
+			wantSD:   math.Sqrt(3.5),
+			// 1.5 * (1 - Σσᵢ²/σ²) with item variances 0.6875 + 0.5 + 0.6875
+			// against a total variance of 3.5.
+			wantKR20:   nil,
+			wantAlpha:  ptr(0.6964),
+			wantP:      []float64{0.625, 0.5, 0.375},
+			wantPBis:   []*float64{ptr(0.5222), ptr(0.866), ptr(0.2548)},
+			wantDiscr:  []float64{1, 1, 0.5},
+			wantAlphas: []*float64{ptr(0.5926), ptr(0.1667), ptr(0.9143)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rep := analyse(tt.rows)
+
+			if rep.Attempts != 4 || !near(rep.Mean, tt.wantMean) || !near(rep.SD, tt.wantSD) {
+				t.Errorf("analyse() = %d attempts, mean %v, sd %v; want 4, %v, %v", rep.Attempts, rep.Mean, rep.SD, tt.wantMean, tt.wantSD)
+			}
+			if !reflect.DeepEqual(rep.KR20, tt.wantKR20) {
+				t.Errorf("KR-20 = %v; want %v", deref(rep.KR20), deref(tt.wantKR20))
+			}
+			if !reflect.DeepEqual(rep.Alpha, tt.wantAlpha) {
+				t.Errorf("alpha = %v; want %v", deref(rep.Alpha), deref(tt.wantAlpha))
+			}
+
+			for i, it := range rep.Items {
+				if it.PValue != tt.wantP[i] {
+					t.Errorf("item %d p-value = %v; want %v", i+1, it.PValue, tt.wantP[i])
+				}
+				if !reflect.DeepEqual(it.PointBiserial, tt.wantPBis[i]) {
+					t.Errorf("item %d point-biserial = %v; want %v", i+1, deref(it.PointBiserial), deref(tt.wantPBis[i]))
+				}
+				if it.Discrimination != tt.wantDiscr[i] {
+					t.Errorf("item %d discrimination = %v; want %v", i+1, it.Discrimination, tt.wantDiscr[i])
+				}
+				if !reflect.DeepEqual(it.AlphaIfDeleted, tt.wantAlphas[i]) {
+					t.Errorf("item %d alpha if deleted = %v; want %v", i+1, deref(it.AlphaIfDeleted), deref(tt.wantAlphas[i]))
+				}
+			}
+		})
+	}
+}
+
+func TestReliabilityUndefined(t *testing.T) {
+	tests := []struct {
+		name   string
+		scores [][]float64
+	}{
+		{"one question", [][]float64{{1}, {0}, {1}}},
+		{"one attempt", [][]float64{{1, 0, 1}}},
+		{"everyone scores the same", [][]float64{{1, 0}, {1, 0}, {1, 0}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rep := analyse(scoreRows(tt.scores, 1))
+			if rep.Alpha != nil || rep.KR20 != nil {
+				t.Errorf("analyse() alpha = %v, KR-20 = %v; want both undefined", deref(rep.Alpha), deref(rep.KR20))
+			}
+		})
+	}
+}
+
+func TestCorrelation(t *testing.T) {
+	tests := []struct {
+		name string
+		xs   []float64
+		ys   []float64
+		want *float64
+	}{
+		{"perfect", []float64{0, 1, 0, 1}, []float64{1, 3, 1, 3}, ptr(1)},
+		{"inverse", []float64{0, 1, 0, 1}, []float64{3, 1, 3, 1}, ptr(-1)},
+		{"unrelated", []float64{0, 1, 0, 1}, []float64{1, 1, 2, 2}, ptr(0)},
+		{"constant item", []float64{1, 1, 1}, []float64{1, 2, 3}, nil},
+		{"constant total", []float64{0, 1, 0}, []float64{2, 2, 2}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := correlation(tt.xs, tt.ys); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("correlation() = %v; want %v", deref(got), deref(tt.want))
+			}
+		})
+	}
+}
+
+func near(got, want float64) bool {
+	return math.Abs(got-want) < 1e-9
+}
+
+func deref(v *float64) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
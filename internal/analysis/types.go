@@ -0,0 +1,25 @@
+package analysis
+
+import (
+	"context"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+type Service interface {
+	RequestAnalysis(ctx context.Context, examID, requestedBy int64) (repo.ItemAnalysisRun, error)
+	ListRuns(ctx context.Context, examID int64, limit, offset int32) ([]repo.ItemAnalysisRun, error)
+	GetReport(ctx context.Context, examID, runID int64) (reportResponse, error)
+}
+
+type itemResponse struct {
+	Item        repo.ListItemStatisticsRow `json:"item"`
+	Distractors []repo.ItemDistractor      `json:"distractors"`
+}
+
+// reportResponse holds a run and, once it has completed, its statistics.
+// Items is empty while the run is queued or running.
+type reportResponse struct {
+	Run   repo.ItemAnalysisRun `json:"run"`
+	Items []itemResponse       `json:"items"`
+}
@@ -0,0 +1,164 @@
+package attempts
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/store"
+)
+
+// Flusher is the write-behind half of autosave. Answers land in Redis first
+// and the flusher copies dirty attempts into Postgres in batches. The dirty
+// set lives in Redis, so anything not yet flushed when the server stops is
+// picked up again on the next start.
+type Flusher struct {
+	repo      *repo.Queries
+	rdb       *store.Redis
+	interval  time.Duration
+	batchSize int64
+	logger    *slog.Logger
+}
+
+func NewFlusher(repo *repo.Queries, rdb *store.Redis, interval time.Duration, batchSize int64, logger *slog.Logger) *Flusher {
+	return &Flusher{
+		repo:      repo,
+		rdb:       rdb,
+		interval:  interval,
+		batchSize: batchSize,
+		logger:    logger,
+	}
+}
+
+// Run flushes on every tick until ctx is cancelled, then drains once more so
+// a graceful shutdown leaves nothing behind.
+func (f *Flusher) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			drainCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			f.drain(drainCtx)
+			cancel()
+			return
+		case <-ticker.C:
+			f.drain(ctx)
+		}
+	}
+}
+
+func (f *Flusher) drain(ctx context.Context) {
+	for {
+		n, err := f.Flush(ctx)
+		if err != nil {
+			f.logger.Error("autosave flush failed", "error", err)
+			return
+		}
+		if n < f.batchSize {
+			return
+		}
+	}
+}
+
+// Flush writes one batch of dirty attempts and returns how many attempts it
+// looked at.
+func (f *Flusher) Flush(ctx context.Context) (int64, error) {
+	dirty, err := f.rdb.DirtyAttempts(ctx, f.batchSize)
+	if err != nil {
+		return 0, err
+	}
+	if len(dirty) == 0 {
+		return 0, nil
+	}
+
+	cached := make(map[int64][]store.SavedAnswer, len(dirty))
+	var params repo.UpsertAttemptAnswersParams
+	for attemptID := range dirty {
+		answers, err := f.rdb.AttemptAnswers(ctx, attemptID)
+		if err != nil {
+			return 0, err
+		}
+		cached[attemptID] = answers
+		appendAnswers(&params, attemptID, answers)
+	}
+
+	if len(params.AttemptIds) > 0 {
+		if _, err := f.repo.UpsertAttemptAnswers(ctx, params); err != nil {
+			if !rejected(err) {
+				return 0, err
+			}
+			// Postgres refused a row. Write the attempts one at a time so
+			// only the one at fault is held back.
+			return f.flushEach(ctx, dirty, cached)
+		}
+	}
+
+	for attemptID, score := range dirty {
+		if err := f.rdb.ClearDirty(ctx, attemptID, score); err != nil {
+			return 0, err
+		}
+	}
+
+	return int64(len(dirty)), nil
+}
+
+// flushEach writes each attempt on its own. An attempt whose answers are
+// still refused is moved out of the dirty set and logged; its answers stay
+// in Redis for submission, which keeps only those on the paper.
+func (f *Flusher) flushEach(ctx context.Context, dirty map[int64]float64, cached map[int64][]store.SavedAnswer) (int64, error) {
+	for attemptID, score := range dirty {
+		var params repo.UpsertAttemptAnswersParams
+		appendAnswers(&params, attemptID, cached[attemptID])
+
+		if len(params.AttemptIds) > 0 {
+			if _, err := f.repo.UpsertAttemptAnswers(ctx, params); err != nil {
+				if !rejected(err) {
+					return 0, err
+				}
+				f.logger.Error("autosave flush rejected attempt", "attempt_id", attemptID, "error", err)
+				if err := f.rdb.FailDirty(ctx, attemptID, score); err != nil {
+					return 0, err
+				}
+				continue
+			}
+		}
+
+		if err := f.rdb.ClearDirty(ctx, attemptID, score); err != nil {
+			return 0, err
+		}
+	}
+
+	return int64(len(dirty)), nil
+}
+
+// rejected reports whether Postgres refused the data itself, as opposed to
+// the write failing for reasons a retry could fix.
+func rejected(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	// Class 22 is data exceptions, class 23 integrity constraint violations.
+	return strings.HasPrefix(pgErr.Code, "22") || strings.HasPrefix(pgErr.Code, "23")
+}
+
+func appendAnswers(params *repo.UpsertAttemptAnswersParams, attemptID int64, answers []store.SavedAnswer) {
+	for _, a := range answers {
+		params.AttemptIds = append(params.AttemptIds, attemptID)
+		params.QuestionIds = append(params.QuestionIds, a.QuestionID)
+		params.Answers = append(params.Answers, a.Answer)
+		params.Versions = append(params.Versions, a.Version)
+		params.SavedAts = append(params.SavedAts, pgtype.Timestamptz{
+			Time:  time.UnixMilli(a.SavedAt),
+			Valid: true,
+		})
+	}
+}
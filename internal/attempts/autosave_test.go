@@ -0,0 +1,398 @@
+package attempts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/store"
+	"github.com/redis/go-redis/v9"
+)
+
+const testUser = 42
+
+// answerDB stands in for Postgres under UpsertAttemptAnswers. It keeps the
+// newest version of each answer, as the query does, and refuses a whole
+// batch when it holds an attempt in reject.
+type answerDB struct {
+	repo.DBTX
+	answers map[[2]int64]store.SavedAnswer
+	reject  map[int64]bool
+	down    bool
+	// batches holds the attempts of every write, rejected or not.
+	batches [][]int64
+	// during runs once, while the first write is in flight.
+	during func()
+}
+
+func newAnswerDB() *answerDB {
+	return &answerDB{answers: map[[2]int64]store.SavedAnswer{}, reject: map[int64]bool{}}
+}
+
+func (db *answerDB) Exec(_ context.Context, _ string, args ...any) (pgconn.CommandTag, error) {
+	attemptIDs := args[0].([]int64)
+	questionIDs := args[1].([]int64)
+	answers := args[2].([][]byte)
+	versions := args[3].([]int64)
+	savedAts := args[4].([]pgtype.Timestamptz)
+
+	var batch []int64
+	for _, id := range attemptIDs {
+		if len(batch) == 0 || batch[len(batch)-1] != id {
+			batch = append(batch, id)
+		}
+	}
+	db.batches = append(db.batches, batch)
+
+	if db.during != nil {
+		db.during()
+		db.during = nil
+	}
+
+	if db.down {
+		return pgconn.CommandTag{}, errors.New("connection refused")
+	}
+	for _, id := range attemptIDs {
+		if db.reject[id] {
+			return pgconn.CommandTag{}, &pgconn.PgError{Code: "22P02", Message: "invalid input syntax for type json"}
+		}
+	}
+
+	n := 0
+	for i := range attemptIDs {
+		key := [2]int64{attemptIDs[i], questionIDs[i]}
+		if cur, ok := db.answers[key]; ok && cur.Version >= versions[i] {
+			continue
+		}
+		db.answers[key] = store.SavedAnswer{
+			QuestionID: questionIDs[i],
+			Answer:     answers[i],
+			Version:    versions[i],
+			SavedAt:    savedAts[i].Time.UnixMilli(),
+		}
+		n++
+	}
+
+	return pgconn.NewCommandTag(fmt.Sprintf("INSERT 0 %d", n)), nil
+}
+
+func newTestRedis(t *testing.T) (*store.Redis, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return &store.Redis{Client: client}, mr
+}
+
+// primeTestAttempts caches in-progress attempts on questions 1, 2 and 3.
+func primeTestAttempts(t *testing.T, r *store.Redis, now time.Time, attemptIDs ...int64) {
+	t.Helper()
+
+	for _, id := range attemptIDs {
+		err := r.PrimeAttempt(context.Background(), store.AttemptMeta{
+			AttemptID:   id,
+			ExamID:      1,
+			UserID:      testUser,
+			Status:      string(repo.AttemptStatusInProgress),
+			ExpiresAt:   now.Add(time.Hour),
+			QuestionIDs: []int64{1, 2, 3},
+		}, nil, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func save(t *testing.T, r *store.Redis, attemptID, questionID int64, answer string, at time.Time) {
+	t.Helper()
+
+	if _, err := r.SaveAnswer(context.Background(), attemptID, testUser, questionID, []byte(answer), -1, at); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func inSet(t *testing.T, mr *miniredis.Miniredis, key string, attemptID int64) bool {
+	t.Helper()
+
+	if !mr.Exists(key) {
+		return false
+	}
+	members, err := mr.ZMembers(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return slices.Contains(members, fmt.Sprint(attemptID))
+}
+
+func newTestFlusher(db *answerDB, r *store.Redis) *Flusher {
+	return NewFlusher(repo.New(db), r, time.Second, 10, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestFlush(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	r, mr := newTestRedis(t)
+	db := newAnswerDB()
+	primeTestAttempts(t, r, now, 1, 2)
+
+	save(t, r, 1, 1, `"A"`, now)
+	save(t, r, 1, 1, `"B"`, now)
+	save(t, r, 2, 3, `"C"`, now)
+
+	n, err := newTestFlusher(db, r).Flush(ctx)
+	if err != nil || n != 2 {
+		t.Fatalf("Flush() = %d, %v; want 2", n, err)
+	}
+	if len(db.batches) != 1 {
+		t.Errorf("Flush() wrote %d batches; want 1", len(db.batches))
+	}
+
+	want := map[[2]int64]string{{1, 1}: `"B"`, {2, 3}: `"C"`}
+	if len(db.answers) != len(want) {
+		t.Fatalf("Postgres holds %v; want %v", db.answers, want)
+	}
+	for key, answer := range want {
+		if got := db.answers[key]; string(got.Answer) != answer {
+			t.Errorf("Postgres answer %v = %s; want %s", key, got.Answer, answer)
+		}
+	}
+	if db.answers[[2]int64{1, 1}].Version != 2 {
+		t.Errorf("Postgres version = %d; want 2", db.answers[[2]int64{1, 1}].Version)
+	}
+
+	for _, id := range []int64{1, 2} {
+		if inSet(t, mr, store.DirtyAttemptsKey, id) {
+			t.Errorf("attempt %d still dirty after flush", id)
+		}
+	}
+
+	if n, err := newTestFlusher(db, r).Flush(ctx); err != nil || n != 0 {
+		t.Errorf("second Flush() = %d, %v; want 0", n, err)
+	}
+}
+
+func TestFlushKeepsLaterSaves(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	r, mr := newTestRedis(t)
+	db := newAnswerDB()
+	primeTestAttempts(t, r, now, 1)
+
+	save(t, r, 1, 1, `"A"`, now)
+
+	// The candidate saves again after the flush has read the cache.
+	db.during = func() { save(t, r, 1, 2, `"B"`, now.Add(time.Second)) }
+
+	f := newTestFlusher(db, r)
+	if _, err := f.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !inSet(t, mr, store.DirtyAttemptsKey, 1) {
+		t.Fatal("attempt cleared although a save landed during the flush")
+	}
+	if _, ok := db.answers[[2]int64{1, 2}]; ok {
+		t.Fatal("flush wrote an answer it never read")
+	}
+
+	if _, err := f.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := db.answers[[2]int64{1, 2}]; string(got.Answer) != `"B"` {
+		t.Errorf("Postgres answer = %s; want \"B\" after the next flush", got.Answer)
+	}
+	if inSet(t, mr, store.DirtyAttemptsKey, 1) {
+		t.Error("attempt still dirty after the next flush")
+	}
+}
+
+func TestFlushEach(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	r, mr := newTestRedis(t)
+	db := newAnswerDB()
+	primeTestAttempts(t, r, now, 1, 2, 3)
+
+	save(t, r, 1, 1, `"A"`, now)
+	save(t, r, 2, 1, `"B"`, now)
+	save(t, r, 3, 1, `"C"`, now)
+	db.reject[2] = true
+
+	n, err := newTestFlusher(db, r).Flush(ctx)
+	if err != nil || n != 3 {
+		t.Fatalf("Flush() = %d, %v; want 3", n, err)
+	}
+
+	// One rejected batch of all three, then one write per attempt.
+	if len(db.batches) != 4 || len(db.batches[0]) != 3 {
+		t.Errorf("Flush() wrote batches %v; want all three then each alone", db.batches)
+	}
+
+	for _, id := range []int64{1, 3} {
+		if _, ok := db.answers[[2]int64{id, 1}]; !ok {
+			t.Errorf("attempt %d was held back by attempt 2", id)
+		}
+		if inSet(t, mr, store.DirtyAttemptsKey, id) || inSet(t, mr, store.FailedAttemptsKey, id) {
+			t.Errorf("attempt %d still dirty or failed", id)
+		}
+	}
+
+	if _, ok := db.answers[[2]int64{2, 1}]; ok {
+		t.Error("rejected attempt reached Postgres")
+	}
+	if inSet(t, mr, store.DirtyAttemptsKey, 2) || !inSet(t, mr, store.FailedAttemptsKey, 2) {
+		t.Error("rejected attempt was not moved to the failed set")
+	}
+
+	// Its answers stay cached for submission.
+	cached, err := r.AttemptAnswers(ctx, 2)
+	if err != nil || len(cached) != 1 {
+		t.Errorf("AttemptAnswers() = %+v, %v; want the rejected answer", cached, err)
+	}
+
+	// Nothing is left, so the next flush does not retry it.
+	db.batches = nil
+	if n, err := newTestFlusher(db, r).Flush(ctx); err != nil || n != 0 || len(db.batches) != 0 {
+		t.Errorf("next Flush() = %d, %v with batches %v; want nothing to do", n, err, db.batches)
+	}
+}
+
+func TestFlushPostgresDown(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	r, mr := newTestRedis(t)
+	db := newAnswerDB()
+	primeTestAttempts(t, r, now, 1, 2)
+
+	save(t, r, 1, 1, `"A"`, now)
+	save(t, r, 2, 1, `"B"`, now)
+	db.down = true
+
+	if _, err := newTestFlusher(db, r).Flush(ctx); err == nil {
+		t.Fatal("Flush() error = nil; want the connection error")
+	}
+	if len(db.batches) != 1 {
+		t.Errorf("Flush() wrote %d batches; want 1, without falling back to one at a time", len(db.batches))
+	}
+	for _, id := range []int64{1, 2} {
+		if !inSet(t, mr, store.DirtyAttemptsKey, id) || inSet(t, mr, store.FailedAttemptsKey, id) {
+			t.Errorf("attempt %d left the dirty set on a transient error", id)
+		}
+	}
+}
+
+func TestRejected(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"invalid json", &pgconn.PgError{Code: "22P02"}, true},
+		{"foreign key", &pgconn.PgError{Code: "23503"}, true},
+		{"wrapped", fmt.Errorf("upsert: %w", &pgconn.PgError{Code: "23505"}), true},
+		{"serialization failure", &pgconn.PgError{Code: "40001"}, false},
+		{"connection failure", &pgconn.PgError{Code: "08006"}, false},
+		{"not from Postgres", errors.New("connection refused"), false},
+		{"cancelled", context.Canceled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rejected(tt.err); got != tt.want {
+				t.Errorf("rejected(%v) = %v; want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSubmitReconciliation walks the steps SubmitAttempt takes against Redis
+// and the answer upsert: close, read what is cached, keep what is on the
+// paper, write it and forget it.
+func TestSubmitReconciliation(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	r, mr := newTestRedis(t)
+	db := newAnswerDB()
+	primeTestAttempts(t, r, now, 1)
+
+	save(t, r, 1, 1, `"A"`, now)
+	save(t, r, 1, 2, `"old"`, now)
+	if _, err := newTestFlusher(db, r).Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// Saved after the last flush, so only Redis has it.
+	save(t, r, 1, 2, `"new"`, now.Add(time.Second))
+	// Cached before saves were checked against the paper.
+	mr.HSet("attempt:1:answers", "9", `{"question_id":9,"version":1,"saved_at":0,"answer":"X"}`)
+
+	if closed, err := r.CloseAttempt(ctx, 1, string(repo.AttemptStatusSubmitted)); err != nil || !closed {
+		t.Fatalf("CloseAttempt() = %v, %v; want true", closed, err)
+	}
+	if _, err := r.SaveAnswer(ctx, 1, testUser, 3, []byte(`"late"`), -1, now); !errors.Is(err, store.ErrAttemptClosed) {
+		t.Fatalf("SaveAnswer() after close error = %v; want %v", err, store.ErrAttemptClosed)
+	}
+
+	cached, err := r.AttemptAnswers(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached = onPaper(cached, []int64{1, 2, 3})
+
+	var params repo.UpsertAttemptAnswersParams
+	appendAnswers(&params, 1, cached)
+	if _, err := repo.New(db).UpsertAttemptAnswers(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.ForgetAnswers(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[int64]struct {
+		answer  string
+		version int64
+	}{
+		1: {`"A"`, 1},
+		2: {`"new"`, 2},
+	}
+	if len(db.answers) != len(want) {
+		t.Fatalf("Postgres holds %v; want questions 1 and 2 only", db.answers)
+	}
+	for questionID, w := range want {
+		got := db.answers[[2]int64{1, questionID}]
+		if string(got.Answer) != w.answer || got.Version != w.version {
+			t.Errorf("question %d = %s at version %d; want %s at %d", questionID, got.Answer, got.Version, w.answer, w.version)
+		}
+	}
+	if got := db.answers[[2]int64{1, 2}].SavedAt; got != now.Add(time.Second).UnixMilli() {
+		t.Errorf("question 2 saved at %d; want the later save's time", got)
+	}
+
+	if inSet(t, mr, store.DirtyAttemptsKey, 1) {
+		t.Error("submitted attempt is still dirty")
+	}
+	if cached, _ := r.AttemptAnswers(ctx, 1); len(cached) != 0 {
+		t.Errorf("AttemptAnswers() after forget = %+v; want none", cached)
+	}
+}
+
+func TestOnPaper(t *testing.T) {
+	answers := []store.SavedAnswer{{QuestionID: 1}, {QuestionID: 9}, {QuestionID: 3}}
+
+	got := onPaper(answers, []int64{1, 2, 3})
+	if len(got) != 2 || got[0].QuestionID != 1 || got[1].QuestionID != 3 {
+		t.Errorf("onPaper() = %+v; want questions 1 and 3", got)
+	}
+
+	if got := onPaper(nil, []int64{1}); len(got) != 0 {
+		t.Errorf("onPaper(nil) = %+v; want none", got)
+	}
+}
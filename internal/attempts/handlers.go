@@ -0,0 +1,190 @@
+package attempts
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/odundlaw/cbt-backend/internal/combinations"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/helpers"
+	"github.com/odundlaw/cbt-backend/internal/json"
+	"github.com/odundlaw/cbt-backend/internal/middlewares"
+	"github.com/odundlaw/cbt-backend/internal/scheduling"
+	"github.com/odundlaw/cbt-backend/internal/store"
+	"github.com/odundlaw/cbt-backend/internal/validation"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service,
+	}
+}
+
+func (h *Handler) StartAttempt(w http.ResponseWriter, r *http.Request) {
+	var req startAttemptParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	attempt, err := h.service.StartAttempt(r.Context(), userID, req)
+	if err != nil {
+		writeAttemptError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgAttemptStarted, attempt, nil)
+}
+
+func (h *Handler) GetPaper(w http.ResponseWriter, r *http.Request) {
+	attemptID, err := helpers.IDParam(r, "attemptID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	paper, err := h.service.GetPaper(r.Context(), userID, attemptID)
+	if err != nil {
+		writeAttemptError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, paper, nil)
+}
+
+func (h *Handler) SaveAnswer(w http.ResponseWriter, r *http.Request) {
+	attemptID, err := helpers.IDParam(r, "attemptID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req saveAnswerParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	res, err := h.service.SaveAnswer(r.Context(), userID, attemptID, req)
+	if errors.Is(err, store.ErrAnswerVersionStale) {
+		json.JSONError(w, http.StatusConflict, constants.ErrAnswerVersionStale, []json.FieldError{{
+			Field:   "version",
+			Message: fmt.Sprintf("current version is %d", res.Version),
+		}})
+		return
+	}
+	if err != nil {
+		writeAttemptError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgAnswerSaved, res, nil)
+}
+
+func (h *Handler) ListAnswers(w http.ResponseWriter, r *http.Request) {
+	attemptID, err := helpers.IDParam(r, "attemptID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	answers, err := h.service.ListAnswers(r.Context(), userID, attemptID)
+	if err != nil {
+		writeAttemptError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, answers, nil)
+}
+
+func (h *Handler) SubmitAttempt(w http.ResponseWriter, r *http.Request) {
+	attemptID, err := helpers.IDParam(r, "attemptID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	attempt, err := h.service.SubmitAttempt(r.Context(), userID, attemptID)
+	if err != nil {
+		writeAttemptError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgAttemptSubmitted, attempt, nil)
+}
+
+func writeAttemptError(w http.ResponseWriter, err error) {
+	var ineligible *scheduling.IneligibleError
+
+	switch {
+	case errors.As(err, &ineligible):
+		json.JSONError(w, http.StatusForbidden, ineligible.Message, []json.FieldError{
+			{Field: "exam_id", Message: ineligible.Message, Code: ineligible.Code},
+		})
+	case errors.Is(err, pgx.ErrNoRows):
+		json.JSONError(w, http.StatusNotFound, constants.ErrNotFound, nil)
+	case errors.Is(err, store.ErrAttemptNotOwner):
+		json.JSONError(w, http.StatusForbidden, err.Error(), nil)
+	case errors.Is(err, store.ErrAttemptClosed), errors.Is(err, store.ErrAttemptExpired):
+		json.JSONError(w, http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, ErrExamNotPublished), errors.Is(err, ErrInvalidAnswer),
+		errors.Is(err, store.ErrQuestionNotOnPaper),
+		errors.Is(err, ErrAdaptivePaper),
+		errors.Is(err, combinations.ErrInvalidSubjectSelection),
+		errors.Is(err, combinations.ErrCourseRequired),
+		errors.Is(err, combinations.ErrCombinationNotAllowed):
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+	default:
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+	}
+}
@@ -0,0 +1,399 @@
+// Package attempts where candidates sit exams and their answers are saved
+package attempts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/config"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/media"
+	"github.com/odundlaw/cbt-backend/internal/store"
+)
+
+var (
+	ErrExamNotPublished = errors.New(constants.ErrExamNotPublished)
+	ErrInvalidAnswer    = errors.New(constants.ErrInvalidAnswer)
+	ErrAdaptivePaper    = errors.New(constants.ErrAdaptivePaper)
+)
+
+type svc struct {
+	repo        *repo.Queries
+	db          *pgxpool.Pool
+	rdb         *store.Redis
+	grader      Grader
+	eligibility Eligibility
+	subjects    Subjects
+}
+
+func NewService(repo *repo.Queries, db *pgxpool.Pool, rdb *store.Redis, grader Grader, eligibility Eligibility, subjects Subjects) Service {
+	return &svc{repo: repo, db: db, rdb: rdb, grader: grader, eligibility: eligibility, subjects: subjects}
+}
+
+func cacheTTL() time.Duration {
+	return time.Duration(config.AttemptCacheTTLHours) * time.Hour
+}
+
+// StartAttempt opens a new attempt, or resumes the one under way. For exams
+// with subjects the candidate's course and electives are checked and the
+// subjects they sit are fixed on the attempt.
+func (s *svc) StartAttempt(ctx context.Context, userID int64, params startAttemptParams) (repo.ExamAttempt, error) {
+	examID := params.ExamID
+
+	exam, err := s.repo.GetExamByID(ctx, examID)
+	if err != nil {
+		return repo.ExamAttempt{}, err
+	}
+
+	if exam.Status != repo.ExamStatusPublished {
+		return repo.ExamAttempt{}, ErrExamNotPublished
+	}
+
+	// An attempt already under way is resumed even if the rules have changed
+	// since it started.
+	attempt, err := s.repo.GetOpenAttempt(ctx, repo.GetOpenAttemptParams{ExamID: examID, UserID: userID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		now := time.Now()
+
+		closesAt, checkErr := s.eligibility.CheckEligibility(ctx, userID, examID, now)
+		if checkErr != nil {
+			return repo.ExamAttempt{}, checkErr
+		}
+
+		subjects, resolveErr := s.subjects.ResolveSubjects(ctx, examID, params.Course, params.Electives)
+		if resolveErr != nil {
+			return repo.ExamAttempt{}, resolveErr
+		}
+
+		expiresAt := now.Add(time.Duration(exam.DurationMinutes) * time.Minute)
+		if !closesAt.IsZero() && closesAt.Before(expiresAt) {
+			expiresAt = closesAt
+		}
+
+		attempt, err = s.create(ctx, repo.CreateAttemptParams{
+			ExamID:    examID,
+			UserID:    userID,
+			ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+			Course:    pgtype.Text{String: params.Course, Valid: params.Course != "" && len(subjects) > 0},
+			Subjects:  subjects,
+		})
+	}
+	if err != nil {
+		return repo.ExamAttempt{}, err
+	}
+
+	if err := s.prime(ctx, attempt); err != nil {
+		return repo.ExamAttempt{}, err
+	}
+
+	return attempt, nil
+}
+
+// create opens the attempt and fixes the version of every question on its
+// paper, so later edits to a question never change what the candidate is
+// shown or graded against. Adaptive exams fix each item as it is presented.
+func (s *svc) create(ctx context.Context, params repo.CreateAttemptParams) (repo.ExamAttempt, error) {
+	_, err := s.repo.GetAdaptiveSettings(ctx, params.ExamID)
+	adaptive := err == nil
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return repo.ExamAttempt{}, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.ExamAttempt{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	attempt, err := qtx.CreateAttempt(ctx, params)
+	if err != nil {
+		return repo.ExamAttempt{}, err
+	}
+
+	if !adaptive {
+		if err := qtx.RecordAttemptPaperVersions(ctx, repo.RecordAttemptPaperVersionsParams{
+			AttemptID: attempt.ID,
+			ExamID:    attempt.ExamID,
+			Subjects:  attempt.Subjects,
+		}); err != nil {
+			return repo.ExamAttempt{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.ExamAttempt{}, err
+	}
+
+	return attempt, nil
+}
+
+// GetPaper returns the attempt's questions, as they were when it started,
+// grouped by section and limited to the subjects the candidate sits. Answer
+// keys are never included. Adaptive exams have no fixed paper.
+func (s *svc) GetPaper(ctx context.Context, userID, attemptID int64) (paperResponse, error) {
+	attempt, err := s.ownAttempt(ctx, userID, attemptID)
+	if err != nil {
+		return paperResponse{}, err
+	}
+
+	if _, err := s.repo.GetAdaptiveSettings(ctx, attempt.ExamID); err == nil {
+		return paperResponse{}, ErrAdaptivePaper
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return paperResponse{}, err
+	}
+
+	rows, err := s.repo.ListAttemptPaper(ctx, repo.ListAttemptPaperParams{
+		AttemptID: attempt.ID,
+		Subjects:  attempt.Subjects,
+	})
+	if err != nil {
+		return paperResponse{}, err
+	}
+
+	// Media links are signed to last as long as the attempt, so candidates
+	// can load them only while sitting the exam.
+	sign := func(html string) string { return html }
+	if attempt.Status == repo.AttemptStatusInProgress {
+		sign = func(html string) string { return media.SignReferences(html, attempt.ID, attempt.ExpiresAt.Time) }
+	}
+
+	sections := []paperSection{}
+	for _, row := range rows {
+		if len(sections) == 0 || sections[len(sections)-1].Section != row.Section {
+			sections = append(sections, paperSection{Section: row.Section})
+		}
+
+		last := &sections[len(sections)-1]
+		last.Questions = append(last.Questions, paperQuestion{
+			QuestionID: row.QuestionID,
+			Position:   row.Position,
+			Type:       row.Type,
+			Stem:       sign(row.Stem),
+			Options:    json.RawMessage(sign(string(row.Options))),
+		})
+	}
+
+	remaining := int64(0)
+	if attempt.Status == repo.AttemptStatusInProgress {
+		remaining = max(0, int64(time.Until(attempt.ExpiresAt.Time).Seconds()))
+	}
+
+	return paperResponse{
+		Attempt:          attempt,
+		RemainingSeconds: remaining,
+		Sections:         sections,
+	}, nil
+}
+
+func (s *svc) SaveAnswer(ctx context.Context, userID, attemptID int64, params saveAnswerParams) (saveAnswerResponse, error) {
+	if !json.Valid(params.Answer) {
+		return saveAnswerResponse{}, ErrInvalidAnswer
+	}
+
+	expected := int64(-1)
+	if params.Version != nil {
+		expected = *params.Version
+	}
+
+	now := time.Now()
+	version, err := s.rdb.SaveAnswer(ctx, attemptID, userID, params.QuestionID, params.Answer, expected, now)
+	if errors.Is(err, store.ErrAttemptNotCached) {
+		// Redis lost the attempt (eviction or restart without persistence);
+		// rebuild it from Postgres and try once more.
+		attempt, lookupErr := s.ownAttempt(ctx, userID, attemptID)
+		if lookupErr != nil {
+			return saveAnswerResponse{}, lookupErr
+		}
+		if primeErr := s.prime(ctx, attempt); primeErr != nil {
+			return saveAnswerResponse{}, primeErr
+		}
+		version, err = s.rdb.SaveAnswer(ctx, attemptID, userID, params.QuestionID, params.Answer, expected, now)
+	}
+	if err != nil {
+		return saveAnswerResponse{Version: version}, err
+	}
+
+	return saveAnswerResponse{
+		AttemptID:  attemptID,
+		QuestionID: params.QuestionID,
+		Version:    version,
+		SavedAt:    now.UTC().Format(time.RFC3339Nano),
+	}, nil
+}
+
+func (s *svc) ListAnswers(ctx context.Context, userID, attemptID int64) ([]store.SavedAnswer, error) {
+	if _, err := s.ownAttempt(ctx, userID, attemptID); err != nil {
+		return nil, err
+	}
+
+	persisted, err := s.repo.ListAttemptAnswers(ctx, attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, err := s.rdb.AttemptAnswers(ctx, attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := make(map[int64]store.SavedAnswer, len(persisted)+len(cached))
+	for _, a := range persisted {
+		latest[a.QuestionID] = store.SavedAnswer{
+			QuestionID: a.QuestionID,
+			Answer:     a.Answer,
+			Version:    a.Version,
+			SavedAt:    a.SavedAt.Time.UnixMilli(),
+		}
+	}
+	for _, a := range cached {
+		if cur, ok := latest[a.QuestionID]; !ok || a.Version > cur.Version {
+			latest[a.QuestionID] = a
+		}
+	}
+
+	answers := make([]store.SavedAnswer, 0, len(latest))
+	for _, a := range latest {
+		answers = append(answers, a)
+	}
+
+	return answers, nil
+}
+
+// SubmitAttempt closes the attempt in Redis first so no save can slip in,
+// then writes every cached answer and the submitted status in a single
+// transaction. A failed submit can simply be retried. Grading runs once the
+// submission is committed; a grading failure does not undo the submission
+// and the attempt can be graded again through a regrade.
+func (s *svc) SubmitAttempt(ctx context.Context, userID, attemptID int64) (repo.ExamAttempt, error) {
+	attempt, err := s.ownAttempt(ctx, userID, attemptID)
+	if err != nil {
+		return repo.ExamAttempt{}, err
+	}
+
+	if attempt.Status != repo.AttemptStatusInProgress {
+		return attempt, nil
+	}
+
+	if _, err := s.rdb.CloseAttempt(ctx, attemptID, string(repo.AttemptStatusSubmitted)); err != nil {
+		return repo.ExamAttempt{}, err
+	}
+
+	cached, err := s.rdb.AttemptAnswers(ctx, attemptID)
+	if err != nil {
+		return repo.ExamAttempt{}, err
+	}
+
+	// Answers cached before saves were checked against the paper may name
+	// questions that are not on it; they are dropped rather than failing the
+	// submission.
+	paper, err := s.repo.ListAttemptQuestionIDs(ctx, attemptID)
+	if err != nil {
+		return repo.ExamAttempt{}, err
+	}
+	cached = onPaper(cached, paper)
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.ExamAttempt{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	var params repo.UpsertAttemptAnswersParams
+	appendAnswers(&params, attemptID, cached)
+	if len(params.AttemptIds) > 0 {
+		if _, err := qtx.UpsertAttemptAnswers(ctx, params); err != nil {
+			return repo.ExamAttempt{}, err
+		}
+	}
+
+	submitted, err := qtx.SubmitAttempt(ctx, attemptID)
+	if err != nil {
+		return repo.ExamAttempt{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.ExamAttempt{}, err
+	}
+
+	if err := s.rdb.ForgetAnswers(ctx, attemptID); err != nil {
+		return repo.ExamAttempt{}, err
+	}
+
+	if _, err := s.grader.GradeAttempt(ctx, attemptID, 0, "submitted"); err != nil {
+		fmt.Println("failed to grade attempt:", err)
+	}
+
+	return submitted, nil
+}
+
+func (s *svc) ownAttempt(ctx context.Context, userID, attemptID int64) (repo.ExamAttempt, error) {
+	attempt, err := s.repo.GetAttemptByID(ctx, attemptID)
+	if err != nil {
+		return repo.ExamAttempt{}, err
+	}
+
+	if attempt.UserID != userID {
+		return repo.ExamAttempt{}, store.ErrAttemptNotOwner
+	}
+
+	return attempt, nil
+}
+
+func (s *svc) prime(ctx context.Context, attempt repo.ExamAttempt) error {
+	persisted, err := s.repo.ListAttemptAnswers(ctx, attempt.ID)
+	if err != nil {
+		return err
+	}
+
+	paper, err := s.repo.ListAttemptQuestionIDs(ctx, attempt.ID)
+	if err != nil {
+		return err
+	}
+
+	seed := make([]store.SavedAnswer, 0, len(persisted))
+	for _, a := range persisted {
+		seed = append(seed, store.SavedAnswer{
+			QuestionID: a.QuestionID,
+			Answer:     a.Answer,
+			Version:    a.Version,
+			SavedAt:    a.SavedAt.Time.UnixMilli(),
+		})
+	}
+
+	return s.rdb.PrimeAttempt(ctx, store.AttemptMeta{
+		AttemptID:   attempt.ID,
+		ExamID:      attempt.ExamID,
+		UserID:      attempt.UserID,
+		Status:      string(attempt.Status),
+		ExpiresAt:   attempt.ExpiresAt.Time,
+		QuestionIDs: paper,
+	}, seed, cacheTTL())
+}
+
+// onPaper keeps the answers to questions on the paper.
+func onPaper(answers []store.SavedAnswer, paper []int64) []store.SavedAnswer {
+	ids := make(map[int64]bool, len(paper))
+	for _, id := range paper {
+		ids[id] = true
+	}
+
+	kept := answers[:0]
+	for _, a := range answers {
+		if ids[a.QuestionID] {
+			kept = append(kept, a)
+		}
+	}
+
+	return kept
+}
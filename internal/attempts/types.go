@@ -0,0 +1,77 @@
+package attempts
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/store"
+)
+
+type Service interface {
+	StartAttempt(ctx context.Context, userID int64, params startAttemptParams) (repo.ExamAttempt, error)
+	GetPaper(ctx context.Context, userID, attemptID int64) (paperResponse, error)
+	SaveAnswer(ctx context.Context, userID, attemptID int64, params saveAnswerParams) (saveAnswerResponse, error)
+	ListAnswers(ctx context.Context, userID, attemptID int64) ([]store.SavedAnswer, error)
+	SubmitAttempt(ctx context.Context, userID, attemptID int64) (repo.ExamAttempt, error)
+}
+
+// Grader scores an attempt once it has been submitted.
+type Grader interface {
+	GradeAttempt(ctx context.Context, attemptID, changedBy int64, reason string) (repo.AttemptResult, error)
+}
+
+// Eligibility decides whether a candidate may start a new attempt. It
+// returns when the exam window closes, or the zero time if it never does.
+type Eligibility interface {
+	CheckEligibility(ctx context.Context, userID, examID int64, now time.Time) (time.Time, error)
+}
+
+// Subjects works out which subjects of a combination exam a candidate sits.
+type Subjects interface {
+	ResolveSubjects(ctx context.Context, examID int64, course string, electives []string) ([]string, error)
+}
+
+type startAttemptParams struct {
+	ExamID int64 `json:"exam_id" validate:"required,gt=0"`
+	// Course and Electives are only used by exams with subjects.
+	Course    string   `json:"course" validate:"max=100"`
+	Electives []string `json:"electives" validate:"dive,required"`
+}
+
+type saveAnswerParams struct {
+	QuestionID int64           `json:"question_id" validate:"required,gt=0"`
+	Answer     json.RawMessage `json:"answer" validate:"required"`
+	// Version is the answer version the client last saw. Leave it out to
+	// overwrite whatever is stored.
+	Version *int64 `json:"version" validate:"omitempty,gte=0"`
+}
+
+type saveAnswerResponse struct {
+	AttemptID  int64  `json:"attempt_id"`
+	QuestionID int64  `json:"question_id"`
+	Version    int64  `json:"version"`
+	SavedAt    string `json:"saved_at"`
+}
+
+type paperQuestion struct {
+	QuestionID int64             `json:"question_id"`
+	Position   int32             `json:"position"`
+	Type       repo.QuestionType `json:"type"`
+	Stem       string            `json:"stem"`
+	Options    json.RawMessage   `json:"options"`
+}
+
+// paperSection is one subject, or section, of the paper. Candidates move
+// between them freely while one timer runs for the whole attempt.
+type paperSection struct {
+	Section   string          `json:"section"`
+	Questions []paperQuestion `json:"questions"`
+}
+
+type paperResponse struct {
+	Attempt          repo.ExamAttempt `json:"attempt"`
+	RemainingSeconds int64            `json:"remaining_seconds"`
+	Sections         []paperSection   `json:"sections"`
+}
@@ -0,0 +1,240 @@
+// Package calibration where item parameters are estimated from graded responses and written back to the question bank
+package calibration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/irt"
+)
+
+var (
+	ErrNoCalibrationData = errors.New(constants.ErrNoCalibrationData)
+	ErrUnknownModel      = errors.New(constants.ErrUnknownIRTModel)
+)
+
+type Options struct {
+	Model irt.Model
+	// ExamID limits the data to one exam's attempts. Zero uses every graded
+	// attempt in the bank.
+	ExamID int64
+	// MinResponses leaves out questions answered fewer times than this.
+	MinResponses int
+	MaxCycles    int
+	Tolerance    float64
+	// DryRun estimates and reports without writing anything.
+	DryRun bool
+}
+
+// Report describes one run. CalibrationID is zero on a dry run.
+type Report struct {
+	CalibrationID int64
+	Model         irt.Model
+	Respondents   int
+	Cycles        int
+	Converged     bool
+	LogLikelihood float64
+	AIC           float64
+	BIC           float64
+	Items         []ItemResult
+	// Skipped lists questions with too few responses, by question ID.
+	Skipped map[int64]int
+}
+
+type ItemResult struct {
+	QuestionID int64
+	Fit        irt.ItemFit
+	Applied    bool
+}
+
+type Calibrator struct {
+	repo   *repo.Queries
+	db     *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewCalibrator(repo *repo.Queries, db *pgxpool.Pool, logger *slog.Logger) *Calibrator {
+	return &Calibrator{
+		repo:   repo,
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Run reads graded responses, fits the model and, unless it is a dry run,
+// records every estimate as a new calibration. Only items that converged
+// replace the question's current parameters; the rest are kept in the
+// history for review. Short answer and essay questions are never
+// calibrated, and a response counts as correct only with full marks.
+func (c *Calibrator) Run(ctx context.Context, opts Options) (Report, error) {
+	model, err := dbModel(opts.Model)
+	if err != nil {
+		return Report{}, err
+	}
+
+	rows, err := c.repo.ListCalibrationResponses(ctx, pgtype.Int8{Int64: opts.ExamID, Valid: opts.ExamID != 0})
+	if err != nil {
+		return Report{}, err
+	}
+
+	counts := map[int64]int{}
+	for _, row := range rows {
+		counts[row.QuestionID]++
+	}
+
+	report := Report{Model: opts.Model, Skipped: map[int64]int{}}
+
+	var questionIDs []int64
+	for id, n := range counts {
+		if n < opts.MinResponses {
+			report.Skipped[id] = n
+			continue
+		}
+		questionIDs = append(questionIDs, id)
+	}
+	sort.Slice(questionIDs, func(i, j int) bool { return questionIDs[i] < questionIDs[j] })
+
+	if len(questionIDs) == 0 {
+		return report, ErrNoCalibrationData
+	}
+
+	index := make(map[int64]int, len(questionIDs))
+	for i, id := range questionIDs {
+		index[id] = i
+	}
+
+	// Rows come ordered by attempt, so each attempt's answers are adjacent.
+	var patterns [][]irt.Observation
+	last := int64(0)
+	for _, row := range rows {
+		j, ok := index[row.QuestionID]
+		if !ok {
+			continue
+		}
+		if row.AttemptID != last || len(patterns) == 0 {
+			patterns = append(patterns, nil)
+			last = row.AttemptID
+		}
+		patterns[len(patterns)-1] = append(patterns[len(patterns)-1], irt.Observation{Item: j, Correct: row.Correct})
+	}
+
+	c.logger.Info("calibrating", "model", opts.Model, "items", len(questionIDs), "respondents", len(patterns))
+
+	fitted := irt.Calibrate(len(questionIDs), patterns, irt.CalibrationOptions{
+		Model:     opts.Model,
+		MaxCycles: opts.MaxCycles,
+		Tolerance: opts.Tolerance,
+	})
+
+	report.Respondents = len(patterns)
+	report.Cycles = fitted.Cycles
+	report.Converged = fitted.Converged
+	report.LogLikelihood = fitted.LogLikelihood
+	report.AIC = fitted.AIC
+	report.BIC = fitted.BIC
+
+	for i, fit := range fitted.Items {
+		report.Items = append(report.Items, ItemResult{
+			QuestionID: questionIDs[i],
+			Fit:        fit,
+			Applied:    fit.Converged && !opts.DryRun,
+		})
+	}
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	id, err := c.save(ctx, model, opts.ExamID, report)
+	if err != nil {
+		return Report{}, err
+	}
+	report.CalibrationID = id
+
+	return report, nil
+}
+
+func (c *Calibrator) save(ctx context.Context, model repo.IrtModel, examID int64, report Report) (int64, error) {
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := c.repo.WithTx(tx)
+
+	calibration, err := qtx.CreateCalibration(ctx, repo.CreateCalibrationParams{
+		Model:         model,
+		ExamID:        pgtype.Int8{Int64: examID, Valid: examID != 0},
+		Respondents:   int32(report.Respondents),
+		Items:         int32(len(report.Items)),
+		Cycles:        int32(report.Cycles),
+		Converged:     report.Converged,
+		LogLikelihood: report.LogLikelihood,
+		Aic:           report.AIC,
+		Bic:           report.BIC,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, item := range report.Items {
+		fit := item.Fit
+		if err := qtx.CreateItemCalibration(ctx, repo.CreateItemCalibrationParams{
+			CalibrationID: calibration.ID,
+			QuestionID:    item.QuestionID,
+			IrtA:          fit.Item.A,
+			IrtB:          fit.Item.B,
+			IrtC:          fit.Item.C,
+			SeA:           fit.SE.A,
+			SeB:           fit.SE.B,
+			SeC:           fit.SE.C,
+			Rmsd:          fit.RMSD,
+			Responses:     int32(fit.Responses),
+			Converged:     fit.Converged,
+			Reason:        pgtype.Text{String: fit.Reason, Valid: fit.Reason != ""},
+			Applied:       item.Applied,
+		}); err != nil {
+			return 0, err
+		}
+
+		if !item.Applied {
+			continue
+		}
+
+		if err := qtx.ApplyQuestionCalibration(ctx, repo.ApplyQuestionCalibrationParams{
+			ID:               item.QuestionID,
+			IrtA:             pgtype.Float8{Float64: fit.Item.A, Valid: true},
+			IrtB:             pgtype.Float8{Float64: fit.Item.B, Valid: true},
+			IrtC:             pgtype.Float8{Float64: fit.Item.C, Valid: true},
+			IrtCalibrationID: pgtype.Int8{Int64: calibration.ID, Valid: true},
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return calibration.ID, nil
+}
+
+func dbModel(model irt.Model) (repo.IrtModel, error) {
+	switch model {
+	case irt.Model1PL:
+		return repo.IrtModel1pl, nil
+	case irt.Model2PL:
+		return repo.IrtModel2pl, nil
+	case irt.Model3PL:
+		return repo.IrtModel3pl, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownModel, model)
+}
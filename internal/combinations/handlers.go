@@ -0,0 +1,132 @@
+package combinations
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/helpers"
+	"github.com/odundlaw/cbt-backend/internal/json"
+	"github.com/odundlaw/cbt-backend/internal/validation"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service,
+	}
+}
+
+// GetConfig tells candidates which subjects they must sit and which
+// electives and combinations they can pick from.
+func (h *Handler) GetConfig(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	config, err := h.service.GetConfig(r.Context(), examID)
+	if err != nil {
+		writeCombinationError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, config, nil)
+}
+
+func (h *Handler) SaveSubjects(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req saveSubjectsParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	config, err := h.service.SaveSubjects(r.Context(), examID, req)
+	if err != nil {
+		writeCombinationError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgExamSubjectsSaved, config, nil)
+}
+
+func (h *Handler) AddCombination(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req combinationParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	combination, err := h.service.AddCombination(r.Context(), examID, req)
+	if err != nil {
+		writeCombinationError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusCreated, constants.MsgCombinationAdded, combination, nil)
+}
+
+func (h *Handler) RemoveCombination(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	combinationID, err := helpers.IDParam(r, "combinationID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	if err := h.service.RemoveCombination(r.Context(), examID, combinationID); err != nil {
+		writeCombinationError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgCombinationRemoved, nil, nil)
+}
+
+func writeCombinationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		json.JSONError(w, http.StatusNotFound, constants.ErrExamNotFound, nil)
+	case errors.Is(err, ErrCombinationNotFound):
+		json.JSONError(w, http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, ErrInvalidSubjectSelection), errors.Is(err, ErrDuplicateExamSubject):
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+	default:
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+	}
+}
@@ -0,0 +1,238 @@
+// Package combinations where exams made of a compulsory subject and chosen electives are configured
+package combinations
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+)
+
+var (
+	ErrInvalidSubjectSelection = errors.New(constants.ErrInvalidSubjectSelection)
+	ErrCourseRequired          = errors.New(constants.ErrCourseRequired)
+	ErrCombinationNotAllowed   = errors.New(constants.ErrCombinationNotAllowed)
+	ErrCombinationNotFound     = errors.New(constants.ErrCombinationNotFound)
+	ErrDuplicateExamSubject    = errors.New(constants.ErrDuplicateExamSubject)
+)
+
+const defaultScaledMax = 100
+
+type svc struct {
+	repo *repo.Queries
+	db   *pgxpool.Pool
+}
+
+func NewService(repo *repo.Queries, db *pgxpool.Pool) Service {
+	return &svc{repo: repo, db: db}
+}
+
+func (s *svc) GetConfig(ctx context.Context, examID int64) (configResponse, error) {
+	exam, err := s.repo.GetExamByID(ctx, examID)
+	if err != nil {
+		return configResponse{}, err
+	}
+
+	subjects, err := s.repo.ListExamSubjects(ctx, examID)
+	if err != nil {
+		return configResponse{}, err
+	}
+
+	combinations, err := s.repo.ListSubjectCombinations(ctx, examID)
+	if err != nil {
+		return configResponse{}, err
+	}
+
+	return configResponse{
+		ElectiveCount: exam.ElectiveCount,
+		Subjects:      subjects,
+		Combinations:  combinations,
+	}, nil
+}
+
+// SaveSubjects replaces the exam's subjects in the order given, which is
+// also the order candidates move through them.
+func (s *svc) SaveSubjects(ctx context.Context, examID int64, params saveSubjectsParams) (configResponse, error) {
+	seen := make(map[string]bool, len(params.Subjects))
+	electives := int32(0)
+	for _, subject := range params.Subjects {
+		if seen[subject.Subject] {
+			return configResponse{}, ErrDuplicateExamSubject
+		}
+		seen[subject.Subject] = true
+
+		if !subject.Required {
+			electives++
+		}
+	}
+
+	if params.ElectiveCount > electives {
+		return configResponse{}, ErrInvalidSubjectSelection
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return configResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	if _, err := qtx.SetExamElectiveCount(ctx, repo.SetExamElectiveCountParams{
+		ID:            examID,
+		ElectiveCount: params.ElectiveCount,
+	}); err != nil {
+		return configResponse{}, err
+	}
+
+	if err := qtx.DeleteExamSubjects(ctx, examID); err != nil {
+		return configResponse{}, err
+	}
+
+	for i, subject := range params.Subjects {
+		scaledMax := subject.ScaledMax
+		if scaledMax == 0 {
+			scaledMax = defaultScaledMax
+		}
+
+		if _, err := qtx.CreateExamSubject(ctx, repo.CreateExamSubjectParams{
+			ExamID:    examID,
+			Subject:   subject.Subject,
+			Required:  subject.Required,
+			ScaledMax: scaledMax,
+			Position:  int32(i),
+		}); err != nil {
+			return configResponse{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return configResponse{}, err
+	}
+
+	return s.GetConfig(ctx, examID)
+}
+
+// AddCombination allows one set of electives for a course of study. Once an
+// exam has any combination, candidates can only sit listed ones.
+func (s *svc) AddCombination(ctx context.Context, examID int64, params combinationParams) (repo.ExamSubjectCombination, error) {
+	exam, err := s.repo.GetExamByID(ctx, examID)
+	if err != nil {
+		return repo.ExamSubjectCombination{}, err
+	}
+
+	subjects, err := s.repo.ListExamSubjects(ctx, examID)
+	if err != nil {
+		return repo.ExamSubjectCombination{}, err
+	}
+
+	if _, err := checkElectives(exam.ElectiveCount, subjects, params.Electives); err != nil {
+		return repo.ExamSubjectCombination{}, err
+	}
+
+	return s.repo.CreateSubjectCombination(ctx, repo.CreateSubjectCombinationParams{
+		ExamID:    examID,
+		Course:    params.Course,
+		Electives: params.Electives,
+	})
+}
+
+func (s *svc) RemoveCombination(ctx context.Context, examID, combinationID int64) error {
+	n, err := s.repo.DeleteSubjectCombination(ctx, repo.DeleteSubjectCombinationParams{
+		ID:     combinationID,
+		ExamID: examID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrCombinationNotFound
+	}
+
+	return nil
+}
+
+// ResolveSubjects checks a candidate's choice and returns every subject they
+// will sit, in exam order. Exams without subjects return an empty list and
+// ignore the choice.
+func (s *svc) ResolveSubjects(ctx context.Context, examID int64, course string, electives []string) ([]string, error) {
+	exam, err := s.repo.GetExamByID(ctx, examID)
+	if err != nil {
+		return nil, err
+	}
+
+	subjects, err := s.repo.ListExamSubjects(ctx, examID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(subjects) == 0 {
+		return []string{}, nil
+	}
+
+	chosen, err := checkElectives(exam.ElectiveCount, subjects, electives)
+	if err != nil {
+		return nil, err
+	}
+
+	restricted, err := s.repo.HasSubjectCombinations(ctx, examID)
+	if err != nil {
+		return nil, err
+	}
+
+	if restricted {
+		if course == "" {
+			return nil, ErrCourseRequired
+		}
+
+		allowed, err := s.repo.SubjectCombinationAllowed(ctx, repo.SubjectCombinationAllowedParams{
+			ExamID:    examID,
+			Course:    course,
+			Electives: electives,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if !allowed {
+			return nil, ErrCombinationNotAllowed
+		}
+	}
+
+	sitting := make([]string, 0, len(subjects))
+	for _, subject := range subjects {
+		if subject.Required || chosen[subject.Subject] {
+			sitting = append(sitting, subject.Subject)
+		}
+	}
+
+	return sitting, nil
+}
+
+// checkElectives makes sure exactly electiveCount distinct elective subjects
+// were picked.
+func checkElectives(electiveCount int32, subjects []repo.ExamSubject, electives []string) (map[string]bool, error) {
+	pool := make(map[string]bool, len(subjects))
+	for _, subject := range subjects {
+		if !subject.Required {
+			pool[subject.Subject] = true
+		}
+	}
+
+	chosen := make(map[string]bool, len(electives))
+	for _, elective := range electives {
+		if !pool[elective] || chosen[elective] {
+			return nil, ErrInvalidSubjectSelection
+		}
+		chosen[elective] = true
+	}
+
+	if int32(len(chosen)) != electiveCount {
+		return nil, ErrInvalidSubjectSelection
+	}
+
+	return chosen, nil
+}
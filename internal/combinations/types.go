@@ -0,0 +1,41 @@
+package combinations
+
+import (
+	"context"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+type Service interface {
+	GetConfig(ctx context.Context, examID int64) (configResponse, error)
+	SaveSubjects(ctx context.Context, examID int64, params saveSubjectsParams) (configResponse, error)
+	AddCombination(ctx context.Context, examID int64, params combinationParams) (repo.ExamSubjectCombination, error)
+	RemoveCombination(ctx context.Context, examID, combinationID int64) error
+	ResolveSubjects(ctx context.Context, examID int64, course string, electives []string) ([]string, error)
+}
+
+type subjectParams struct {
+	// Subject must match the section its questions were added under.
+	Subject  string `json:"subject" validate:"required,max=100"`
+	Required bool   `json:"required"`
+	// ScaledMax defaults to 100.
+	ScaledMax float64 `json:"scaled_max" validate:"gte=0"`
+}
+
+// saveSubjectsParams replaces an exam's subjects. An empty list turns the
+// exam back into an ordinary one.
+type saveSubjectsParams struct {
+	ElectiveCount int32           `json:"elective_count" validate:"gte=0"`
+	Subjects      []subjectParams `json:"subjects" validate:"dive"`
+}
+
+type combinationParams struct {
+	Course    string   `json:"course" validate:"required,max=100"`
+	Electives []string `json:"electives" validate:"required,dive,required"`
+}
+
+type configResponse struct {
+	ElectiveCount int32                         `json:"elective_count"`
+	Subjects      []repo.ExamSubject            `json:"subjects"`
+	Combinations  []repo.ExamSubjectCombination `json:"combinations"`
+}
@@ -0,0 +1,277 @@
+package commissions
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/helpers"
+	"github.com/odundlaw/cbt-backend/internal/json"
+	"github.com/odundlaw/cbt-backend/internal/middlewares"
+	"github.com/odundlaw/cbt-backend/internal/validation"
+)
+
+const (
+	idempotencyHeader = "Idempotency-Key"
+	maxKeyLength      = 255
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service,
+	}
+}
+
+func (h *Handler) ListRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.service.ListRules(r.Context())
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, rules, nil)
+}
+
+func (h *Handler) UpsertRule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	var req upsertRuleParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	rule, err := h.service.UpsertRule(r.Context(), userID, req)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgCommissionRuleSaved, rule, nil)
+}
+
+func (h *Handler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	ruleID, err := helpers.IDParam(r, "ruleID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	if err := h.service.DeleteRule(r.Context(), ruleID); err != nil {
+		writeCommissionError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgDeleteSuccessful, nil, nil)
+}
+
+// MyStatement is the signed-in agent's own statement.
+func (h *Handler) MyStatement(w http.ResponseWriter, r *http.Request) {
+	agentID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	h.statement(w, r, agentID)
+}
+
+func (h *Handler) AgentStatement(w http.ResponseWriter, r *http.Request) {
+	agentID, err := helpers.IDParam(r, "agentID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	h.statement(w, r, agentID)
+}
+
+func (h *Handler) statement(w http.ResponseWriter, r *http.Request, agentID int64) {
+	limit, offset := helpers.Pagination(r)
+
+	statement, err := h.service.Statement(r.Context(), agentID, limit, offset)
+	if err != nil {
+		writeCommissionError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, statement, nil)
+}
+
+func (h *Handler) Adjust(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	agentID, err := helpers.IDParam(r, "agentID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	key, ok := idempotencyKey(r)
+	if !ok {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrIdempotencyKeyRequired, nil)
+		return
+	}
+
+	var req adjustmentParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	// Admin keys get their own namespace so they can't collide with the
+	// keys the ledger derives for earnings.
+	posted, err := h.service.Adjust(r.Context(), agentID, userID, "adjustment:"+key, req)
+	if err != nil {
+		writeCommissionError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusCreated, constants.MsgAdjustmentPosted, posted, nil)
+}
+
+func (h *Handler) CreatePayout(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	agentID, err := helpers.IDParam(r, "agentID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	key, ok := idempotencyKey(r)
+	if !ok {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrIdempotencyKeyRequired, nil)
+		return
+	}
+
+	payout, err := h.service.CreatePayout(r.Context(), agentID, userID, key)
+	if err != nil {
+		writeCommissionError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusCreated, constants.MsgPayoutCreated, payout, nil)
+}
+
+func (h *Handler) ListPayouts(w http.ResponseWriter, r *http.Request) {
+	limit, offset := helpers.Pagination(r)
+
+	payouts, err := h.service.ListPayouts(r.Context(), limit, offset)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, payouts, nil)
+}
+
+func (h *Handler) CompletePayout(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	payoutID, err := helpers.IDParam(r, "payoutID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req completePayoutParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	payout, err := h.service.CompletePayout(r.Context(), payoutID, userID, req)
+	if err != nil {
+		writeCommissionError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgPayoutCompleted, payout, nil)
+}
+
+func (h *Handler) CancelPayout(w http.ResponseWriter, r *http.Request) {
+	payoutID, err := helpers.IDParam(r, "payoutID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	payout, err := h.service.CancelPayout(r.Context(), payoutID)
+	if err != nil {
+		writeCommissionError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgPayoutCancelled, payout, nil)
+}
+
+func idempotencyKey(r *http.Request) (string, bool) {
+	key := strings.TrimSpace(r.Header.Get(idempotencyHeader))
+	if key == "" || len(key) > maxKeyLength {
+		return "", false
+	}
+
+	return key, true
+}
+
+func writeCommissionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		json.JSONError(w, http.StatusNotFound, constants.ErrUserNotFound, nil)
+	case errors.Is(err, ErrCommissionRuleNotFound), errors.Is(err, ErrPayoutNotFound):
+		json.JSONError(w, http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, ErrIdempotencyKeyReused),
+		errors.Is(err, ErrPayoutInProgress),
+		errors.Is(err, ErrPayoutNotPending),
+		errors.Is(err, ErrPayoutAlreadyPosted):
+		json.JSONError(w, http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, ErrNotAgent), errors.Is(err, ErrNothingToPayOut):
+		json.JSONError(w, http.StatusUnprocessableEntity, err.Error(), nil)
+	default:
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+	}
+}
@@ -0,0 +1,543 @@
+// Package commissions where agent earnings, adjustments and payouts are kept in a double-entry ledger
+package commissions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+)
+
+// System accounts seeded by the ledger migration. Each agent also gets a
+// payable account the first time they earn.
+const (
+	expenseAccount = "commission_expense"
+	cashAccount    = "cash"
+)
+
+var (
+	ErrIdempotencyKeyReused   = errors.New(constants.ErrIdempotencyKeyReused)
+	ErrCommissionRuleNotFound = errors.New(constants.ErrCommissionRuleNotFound)
+	ErrUnbalancedTransaction  = errors.New(constants.ErrUnbalancedTransaction)
+	ErrNothingToPayOut        = errors.New(constants.ErrNothingToPayOut)
+	ErrPayoutInProgress       = errors.New(constants.ErrPayoutInProgress)
+	ErrPayoutNotFound         = errors.New(constants.ErrPayoutNotFound)
+	ErrPayoutNotPending       = errors.New(constants.ErrPayoutNotPending)
+	ErrPayoutAlreadyPosted    = errors.New(constants.ErrPayoutAlreadyPosted)
+	ErrNotAgent               = errors.New(constants.ErrNotAgent)
+)
+
+type svc struct {
+	repo *repo.Queries
+	db   *pgxpool.Pool
+}
+
+func NewService(repo *repo.Queries, db *pgxpool.Pool) Service {
+	return &svc{repo: repo, db: db}
+}
+
+func (s *svc) ListRules(ctx context.Context) ([]repo.CommissionRule, error) {
+	return s.repo.ListCommissionRules(ctx)
+}
+
+func (s *svc) UpsertRule(ctx context.Context, createdBy int64, params upsertRuleParams) (repo.CommissionRule, error) {
+	var productID pgtype.Int8
+	if params.ProductID != nil {
+		productID = pgtype.Int8{Int64: *params.ProductID, Valid: true}
+	}
+
+	return s.repo.UpsertCommissionRule(ctx, repo.UpsertCommissionRuleParams{
+		Product:    params.Product,
+		ProductID:  productID,
+		RateBps:    params.RateBps,
+		FlatAmount: params.FlatAmount,
+		CreatedBy:  createdBy,
+	})
+}
+
+func (s *svc) DeleteRule(ctx context.Context, ruleID int64) error {
+	n, err := s.repo.DeleteCommissionRule(ctx, ruleID)
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrCommissionRuleNotFound
+	}
+
+	return nil
+}
+
+// EarnOnRedemption credits the agent holding a voucher when it is first
+// redeemed. It runs on the caller's transaction so the sale and the
+// commission are recorded together; voucher is the row as read before the
+// redemption counted.
+func (s *svc) EarnOnRedemption(ctx context.Context, q *repo.Queries, voucher repo.Voucher, batch repo.VoucherBatch) error {
+	if !voucher.AgentID.Valid || voucher.Uses > 0 {
+		return nil
+	}
+
+	amount, err := s.commission(ctx, q, repo.CommissionProduct(batch.TargetType), batch.TargetID, batch.UnitPrice)
+	if err != nil || amount == 0 {
+		return err
+	}
+
+	_, _, err = s.earn(ctx, q, voucher.AgentID.Int64, amount, repo.CreateLedgerTransactionParams{
+		IdempotencyKey: fmt.Sprintf("voucher:%d", voucher.ID),
+		Description:    fmt.Sprintf("Commission on voucher %s", voucher.Serial),
+		ReferenceType:  pgtype.Text{String: "voucher", Valid: true},
+		ReferenceID:    pgtype.Int8{Int64: voucher.ID, Valid: true},
+	})
+
+	return err
+}
+
+// EarnOnRegistration credits an agent for a candidate who signed up with
+// their referral code. Registration rules pay their flat amount only. It runs
+// on the caller's transaction so the account and the commission are recorded
+// together.
+func (s *svc) EarnOnRegistration(ctx context.Context, q *repo.Queries, agentID, userID int64) error {
+	amount, err := s.commission(ctx, q, repo.CommissionProductRegistration, 0, 0)
+	if err != nil || amount == 0 {
+		return err
+	}
+
+	_, _, err = s.earn(ctx, q, agentID, amount, repo.CreateLedgerTransactionParams{
+		IdempotencyKey: fmt.Sprintf("registration:%d", userID),
+		Description:    "Commission on candidate registration",
+		ReferenceType:  pgtype.Text{String: "user", Valid: true},
+		ReferenceID:    pgtype.Int8{Int64: userID, Valid: true},
+	})
+
+	return err
+}
+
+// Statement lists an agent's payable account newest first, each line with
+// the balance after it was posted.
+func (s *svc) Statement(ctx context.Context, agentID int64, limit, offset int32) (statementResponse, error) {
+	agent, err := s.repo.GetUserByID(ctx, agentID)
+	if err != nil {
+		return statementResponse{}, err
+	}
+
+	statement := statementResponse{
+		AgentID:      agent.ID,
+		ReferralCode: agent.ReferralCode.String,
+		Entries:      []repo.ListAccountStatementRow{},
+	}
+
+	account, err := s.repo.GetAgentLedgerAccount(ctx, pgtype.Int8{Int64: agentID, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return statement, nil
+	}
+	if err != nil {
+		return statementResponse{}, err
+	}
+
+	balance, err := s.repo.GetAccountBalance(ctx, account.ID)
+	if err != nil {
+		return statementResponse{}, err
+	}
+
+	entries, err := s.repo.ListAccountStatement(ctx, repo.ListAccountStatementParams{
+		AccountID: account.ID,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return statementResponse{}, err
+	}
+
+	statement.Balance = balance.Balance
+	statement.Unsettled = balance.Unsettled
+	statement.Entries = entries
+
+	return statement, nil
+}
+
+// Adjust posts a manual correction to an agent's balance. Replaying the same
+// key returns the original transaction. Client keys are scoped to the agent
+// and kept apart from the keys the system posts under.
+func (s *svc) Adjust(ctx context.Context, agentID, createdBy int64, key string, params adjustmentParams) (transactionResponse, error) {
+	if err := s.requireAgent(ctx, agentID); err != nil {
+		return transactionResponse{}, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return transactionResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	agentAccount, err := s.agentAccount(ctx, qtx, agentID)
+	if err != nil {
+		return transactionResponse{}, err
+	}
+
+	expense, err := qtx.GetLedgerAccountByCode(ctx, expenseAccount)
+	if err != nil {
+		return transactionResponse{}, err
+	}
+
+	txn, created, err := s.post(ctx, qtx, repo.CreateLedgerTransactionParams{
+		IdempotencyKey: adjustmentKey(agentID, key),
+		Kind:           repo.LedgerKindAdjustment,
+		AgentID:        agentID,
+		Description:    params.Description,
+		CreatedBy:      pgtype.Int8{Int64: createdBy, Valid: true},
+	}, adjustmentEntries(expense.ID, agentAccount.ID, params.Amount))
+	if err != nil {
+		return transactionResponse{}, err
+	}
+
+	if !created && (txn.Kind != repo.LedgerKindAdjustment || txn.AgentID != agentID) {
+		return transactionResponse{}, ErrIdempotencyKeyReused
+	}
+
+	posted, err := qtx.ListLedgerEntriesByTransaction(ctx, txn.ID)
+	if err != nil {
+		return transactionResponse{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return transactionResponse{}, err
+	}
+
+	return transactionResponse{Transaction: txn, Entries: posted}, nil
+}
+
+// CreatePayout raises a pending payout for everything the agent has earned
+// and not yet been paid, and ties those entries to it. Replaying the same key
+// returns the original payout.
+func (s *svc) CreatePayout(ctx context.Context, agentID, createdBy int64, key string) (repo.AgentPayout, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.AgentPayout{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	existing, err := qtx.GetAgentPayoutByKey(ctx, key)
+	if err == nil {
+		if existing.AgentID != agentID {
+			return repo.AgentPayout{}, ErrIdempotencyKeyReused
+		}
+		return existing, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return repo.AgentPayout{}, err
+	}
+
+	// Locking the account holds off new earnings until the entries being
+	// paid are attached.
+	account, err := qtx.GetAgentLedgerAccountForUpdate(ctx, pgtype.Int8{Int64: agentID, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.AgentPayout{}, ErrNothingToPayOut
+	}
+	if err != nil {
+		return repo.AgentPayout{}, err
+	}
+
+	balance, err := qtx.GetAccountBalance(ctx, account.ID)
+	if err != nil {
+		return repo.AgentPayout{}, err
+	}
+
+	if balance.Unsettled <= 0 {
+		return repo.AgentPayout{}, ErrNothingToPayOut
+	}
+
+	payout, err := qtx.CreateAgentPayout(ctx, repo.CreateAgentPayoutParams{
+		AgentID:        agentID,
+		Amount:         balance.Unsettled,
+		IdempotencyKey: key,
+		CreatedBy:      createdBy,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return repo.AgentPayout{}, ErrPayoutInProgress
+		}
+		return repo.AgentPayout{}, err
+	}
+
+	if err := qtx.AttachEntriesToPayout(ctx, repo.AttachEntriesToPayoutParams{
+		PayoutID:  pgtype.Int8{Int64: payout.ID, Valid: true},
+		AccountID: account.ID,
+	}); err != nil {
+		return repo.AgentPayout{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.AgentPayout{}, err
+	}
+
+	return payout, nil
+}
+
+// CompletePayout records that the money has left: the agent's payable is
+// debited against cash and every entry on the payout is settled.
+func (s *svc) CompletePayout(ctx context.Context, payoutID, paidBy int64, params completePayoutParams) (repo.AgentPayout, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.AgentPayout{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	payout, err := s.pendingPayout(ctx, qtx, payoutID)
+	if err != nil {
+		return repo.AgentPayout{}, err
+	}
+
+	agentAccount, err := s.agentAccount(ctx, qtx, payout.AgentID)
+	if err != nil {
+		return repo.AgentPayout{}, err
+	}
+
+	cash, err := qtx.GetLedgerAccountByCode(ctx, cashAccount)
+	if err != nil {
+		return repo.AgentPayout{}, err
+	}
+
+	_, created, err := s.post(ctx, qtx, repo.CreateLedgerTransactionParams{
+		IdempotencyKey: fmt.Sprintf("payout:%d", payout.ID),
+		Kind:           repo.LedgerKindPayout,
+		AgentID:        payout.AgentID,
+		Description:    fmt.Sprintf("Commission payout %s", params.Reference),
+		ReferenceType:  pgtype.Text{String: "payout", Valid: true},
+		ReferenceID:    pgtype.Int8{Int64: payout.ID, Valid: true},
+		CreatedBy:      pgtype.Int8{Int64: paidBy, Valid: true},
+	}, []entry{
+		{accountID: agentAccount.ID, debit: payout.Amount, payoutID: payout.ID, settled: true},
+		{accountID: cash.ID, credit: payout.Amount},
+	})
+	if err != nil {
+		return repo.AgentPayout{}, err
+	}
+
+	// A pending payout with its debit already posted must not be settled
+	// again on the strength of someone else's transaction.
+	if !created {
+		return repo.AgentPayout{}, ErrPayoutAlreadyPosted
+	}
+
+	if err := qtx.SettlePayoutEntries(ctx, pgtype.Int8{Int64: payout.ID, Valid: true}); err != nil {
+		return repo.AgentPayout{}, err
+	}
+
+	paid, err := qtx.MarkPayoutPaid(ctx, repo.MarkPayoutPaidParams{
+		ID:        payout.ID,
+		Reference: pgtype.Text{String: params.Reference, Valid: true},
+	})
+	if err != nil {
+		return repo.AgentPayout{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.AgentPayout{}, err
+	}
+
+	return paid, nil
+}
+
+// CancelPayout drops a pending payout and releases its entries for the next
+// one.
+func (s *svc) CancelPayout(ctx context.Context, payoutID int64) (repo.AgentPayout, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.AgentPayout{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	if _, err := s.pendingPayout(ctx, qtx, payoutID); err != nil {
+		return repo.AgentPayout{}, err
+	}
+
+	if err := qtx.DetachPayoutEntries(ctx, pgtype.Int8{Int64: payoutID, Valid: true}); err != nil {
+		return repo.AgentPayout{}, err
+	}
+
+	cancelled, err := qtx.CancelAgentPayout(ctx, payoutID)
+	if err != nil {
+		return repo.AgentPayout{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.AgentPayout{}, err
+	}
+
+	return cancelled, nil
+}
+
+func (s *svc) ListPayouts(ctx context.Context, limit, offset int32) ([]repo.AgentPayout, error) {
+	return s.repo.ListAgentPayouts(ctx, repo.ListAgentPayoutsParams{Limit: limit, Offset: offset})
+}
+
+// commission applies the most specific rule for a product to a sale price.
+// No rule means no commission.
+func (s *svc) commission(ctx context.Context, q *repo.Queries, product repo.CommissionProduct, productID, price int64) (int64, error) {
+	var id pgtype.Int8
+	if productID != 0 {
+		id = pgtype.Int8{Int64: productID, Valid: true}
+	}
+
+	rule, err := q.FindCommissionRule(ctx, repo.FindCommissionRuleParams{Product: product, ProductID: id})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return commissionAmount(rule, price), nil
+}
+
+func commissionAmount(rule repo.CommissionRule, price int64) int64 {
+	return price*int64(rule.RateBps)/10000 + rule.FlatAmount
+}
+
+// adjustmentKey namespaces a client's Idempotency-Key by agent.
+func adjustmentKey(agentID int64, key string) string {
+	return fmt.Sprintf("adjustment:%d:%s", agentID, key)
+}
+
+// adjustmentEntries credits the agent for a positive amount and debits them
+// for a negative one, against commission expense.
+func adjustmentEntries(expenseID, agentAccountID, amount int64) []entry {
+	if amount < 0 {
+		return []entry{{accountID: agentAccountID, debit: -amount}, {accountID: expenseID, credit: -amount}}
+	}
+	return []entry{{accountID: expenseID, debit: amount}, {accountID: agentAccountID, credit: amount}}
+}
+
+// earn moves amount from commission expense to the agent's payable.
+func (s *svc) earn(ctx context.Context, q *repo.Queries, agentID, amount int64, params repo.CreateLedgerTransactionParams) (repo.LedgerTransaction, bool, error) {
+	agentAccount, err := s.agentAccount(ctx, q, agentID)
+	if err != nil {
+		return repo.LedgerTransaction{}, false, err
+	}
+
+	expense, err := q.GetLedgerAccountByCode(ctx, expenseAccount)
+	if err != nil {
+		return repo.LedgerTransaction{}, false, err
+	}
+
+	params.Kind = repo.LedgerKindEarning
+	params.AgentID = agentID
+
+	return s.post(ctx, q, params, []entry{
+		{accountID: expense.ID, debit: amount},
+		{accountID: agentAccount.ID, credit: amount},
+	})
+}
+
+// post writes a balanced transaction. When the idempotency key has been seen
+// before nothing is written and the earlier transaction is returned with
+// created false.
+func (s *svc) post(ctx context.Context, q *repo.Queries, params repo.CreateLedgerTransactionParams, entries []entry) (repo.LedgerTransaction, bool, error) {
+	if !balanced(entries) {
+		return repo.LedgerTransaction{}, false, ErrUnbalancedTransaction
+	}
+
+	txn, err := q.CreateLedgerTransaction(ctx, params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		existing, err := q.GetLedgerTransactionByKey(ctx, params.IdempotencyKey)
+		return existing, false, err
+	}
+	if err != nil {
+		return repo.LedgerTransaction{}, false, err
+	}
+
+	now := time.Now()
+	for _, e := range entries {
+		var payoutID pgtype.Int8
+		var settledAt pgtype.Timestamptz
+		if e.payoutID != 0 {
+			payoutID = pgtype.Int8{Int64: e.payoutID, Valid: true}
+		}
+		if e.settled {
+			settledAt = pgtype.Timestamptz{Time: now, Valid: true}
+		}
+
+		if _, err := q.CreateLedgerEntry(ctx, repo.CreateLedgerEntryParams{
+			TransactionID: txn.ID,
+			AccountID:     e.accountID,
+			Debit:         e.debit,
+			Credit:        e.credit,
+			PayoutID:      payoutID,
+			SettledAt:     settledAt,
+		}); err != nil {
+			return repo.LedgerTransaction{}, false, err
+		}
+	}
+
+	return txn, true, nil
+}
+
+// balanced reports whether entries move a non-zero amount with debits equal
+// to credits.
+func balanced(entries []entry) bool {
+	var debits, credits int64
+	for _, e := range entries {
+		if e.debit < 0 || e.credit < 0 {
+			return false
+		}
+		debits += e.debit
+		credits += e.credit
+	}
+	return debits == credits && debits != 0
+}
+
+// agentAccount returns the agent's payable account, opening it if needed.
+// The upsert also locks the row, which keeps postings and payouts for one
+// agent in order.
+func (s *svc) agentAccount(ctx context.Context, q *repo.Queries, agentID int64) (repo.LedgerAccount, error) {
+	return q.UpsertAgentLedgerAccount(ctx, repo.UpsertAgentLedgerAccountParams{
+		Code:    fmt.Sprintf("agent:%d:payable", agentID),
+		Name:    fmt.Sprintf("Commission payable to agent %d", agentID),
+		AgentID: pgtype.Int8{Int64: agentID, Valid: true},
+	})
+}
+
+func (s *svc) pendingPayout(ctx context.Context, q *repo.Queries, payoutID int64) (repo.AgentPayout, error) {
+	payout, err := q.GetAgentPayoutForUpdate(ctx, payoutID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.AgentPayout{}, ErrPayoutNotFound
+	}
+	if err != nil {
+		return repo.AgentPayout{}, err
+	}
+
+	if payout.Status != repo.PayoutStatusPending {
+		return repo.AgentPayout{}, ErrPayoutNotPending
+	}
+
+	return payout, nil
+}
+
+func (s *svc) requireAgent(ctx context.Context, agentID int64) error {
+	agent, err := s.repo.GetUserByID(ctx, agentID)
+	if err != nil {
+		return err
+	}
+
+	if agent.Role != repo.UserRoleAGENT {
+		return ErrNotAgent
+	}
+
+	return nil
+}
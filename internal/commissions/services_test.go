@@ -0,0 +1,96 @@
+package commissions
+
+import (
+	"reflect"
+	"testing"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+func TestCommissionAmount(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  repo.CommissionRule
+		price int64
+		want  int64
+	}{
+		{"rate only", repo.CommissionRule{RateBps: 1000}, 250000, 25000},
+		{"flat only", repo.CommissionRule{FlatAmount: 5000}, 250000, 5000},
+		{"rate and flat", repo.CommissionRule{RateBps: 250, FlatAmount: 100}, 10000, 350},
+		{"fractions of a unit are dropped", repo.CommissionRule{RateBps: 333}, 100, 3},
+		{"registration has no price", repo.CommissionRule{RateBps: 1000, FlatAmount: 20000}, 0, 20000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commissionAmount(tt.rule, tt.price); got != tt.want {
+				t.Errorf("commissionAmount() = %d; want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdjustmentKey(t *testing.T) {
+	system := []string{"payout:7", "voucher:7", "registration:7"}
+
+	for _, key := range system {
+		if got := adjustmentKey(7, key); got == key {
+			t.Errorf("adjustmentKey(7, %q) = %q; want it apart from system keys", key, got)
+		}
+	}
+
+	if adjustmentKey(7, "k") == adjustmentKey(8, "k") {
+		t.Errorf("adjustmentKey() gives two agents the same key")
+	}
+	if got, want := adjustmentKey(7, "k"), "adjustment:7:k"; got != want {
+		t.Errorf("adjustmentKey() = %q; want %q", got, want)
+	}
+}
+
+func TestAdjustmentEntries(t *testing.T) {
+	const expense, agent = 1, 2
+
+	tests := []struct {
+		name   string
+		amount int64
+		want   []entry
+	}{
+		{"credit the agent", 500, []entry{{accountID: expense, debit: 500}, {accountID: agent, credit: 500}}},
+		{"debit the agent", -300, []entry{{accountID: agent, debit: 300}, {accountID: expense, credit: 300}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := adjustmentEntries(expense, agent, tt.amount)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("adjustmentEntries() = %+v; want %+v", got, tt.want)
+			}
+			if !balanced(got) {
+				t.Errorf("adjustmentEntries() = %+v does not balance", got)
+			}
+		})
+	}
+}
+
+func TestBalanced(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []entry
+		want    bool
+	}{
+		{"balanced", []entry{{debit: 100}, {credit: 60}, {credit: 40}}, true},
+		{"more debits", []entry{{debit: 100}, {credit: 99}}, false},
+		{"nothing moved", []entry{{debit: 0}, {credit: 0}}, false},
+		{"no entries", nil, false},
+		{"negative amounts", []entry{{debit: -100}, {credit: -100}}, false},
+		{"negative offsets positive", []entry{{debit: 100}, {debit: -50}, {credit: 50}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := balanced(tt.entries); got != tt.want {
+				t.Errorf("balanced(%+v) = %v; want %v", tt.entries, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package commissions
+
+import (
+	"context"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+type Service interface {
+	ListRules(ctx context.Context) ([]repo.CommissionRule, error)
+	UpsertRule(ctx context.Context, createdBy int64, params upsertRuleParams) (repo.CommissionRule, error)
+	DeleteRule(ctx context.Context, ruleID int64) error
+	EarnOnRedemption(ctx context.Context, q *repo.Queries, voucher repo.Voucher, batch repo.VoucherBatch) error
+	EarnOnRegistration(ctx context.Context, q *repo.Queries, agentID, userID int64) error
+	Statement(ctx context.Context, agentID int64, limit, offset int32) (statementResponse, error)
+	Adjust(ctx context.Context, agentID, createdBy int64, key string, params adjustmentParams) (transactionResponse, error)
+	CreatePayout(ctx context.Context, agentID, createdBy int64, key string) (repo.AgentPayout, error)
+	CompletePayout(ctx context.Context, payoutID, paidBy int64, params completePayoutParams) (repo.AgentPayout, error)
+	CancelPayout(ctx context.Context, payoutID int64) (repo.AgentPayout, error)
+	ListPayouts(ctx context.Context, limit, offset int32) ([]repo.AgentPayout, error)
+}
+
+type upsertRuleParams struct {
+	Product repo.CommissionProduct `json:"product" validate:"required,oneof=exam practice_pack registration"`
+	// ProductID narrows the rule to one exam or practice pack. Leave it out
+	// for the default rule of the product kind.
+	ProductID  *int64 `json:"product_id" validate:"omitempty,gt=0"`
+	RateBps    int32  `json:"rate_bps" validate:"gte=0,lte=10000"`
+	FlatAmount int64  `json:"flat_amount" validate:"gte=0"`
+}
+
+// adjustmentParams credits the agent for a positive amount and debits them
+// for a negative one.
+type adjustmentParams struct {
+	Amount      int64  `json:"amount" validate:"required,ne=0"`
+	Description string `json:"description" validate:"required,min=3,max=255"`
+}
+
+type completePayoutParams struct {
+	Reference string `json:"reference" validate:"required,max=100"`
+}
+
+// entry is one leg of a ledger transaction before it is written.
+type entry struct {
+	accountID int64
+	debit     int64
+	credit    int64
+	payoutID  int64
+	settled   bool
+}
+
+type transactionResponse struct {
+	Transaction repo.LedgerTransaction `json:"transaction"`
+	Entries     []repo.LedgerEntry     `json:"entries"`
+}
+
+type statementResponse struct {
+	AgentID      int64                          `json:"agent_id"`
+	ReferralCode string                         `json:"referral_code,omitempty"`
+	Balance      int64                          `json:"balance"`
+	Unsettled    int64                          `json:"unsettled"`
+	Entries      []repo.ListAccountStatementRow `json:"entries"`
+}
@@ -1,7 +1,12 @@
 // Package config, where all environment config are stored
 package config
 
-import "github.com/odundlaw/cbt-backend/internal/env"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/odundlaw/cbt-backend/internal/env"
+)
 
 var (
 	AccessSecret        = []byte(env.GetString("ACCESS_TOKEN_SECRET", ""))
@@ -9,4 +14,104 @@ var (
 	ResetPasswordSecret = []byte(env.GetString("RESET_PASSWORD_SECRET", ""))
 	DatabaseURL         = env.GetString("DATABASE_URL", "")
 	RedisURL            = env.GetString("REDIS_ADDR", "")
+
+	// "development" enables conveniences that must never reach production,
+	// such as the fake payment provider
+	AppEnv = env.GetString("APP_ENV", "production")
+
+	// Autosave write-behind tuning
+	AutosaveFlushSeconds = positive(env.GetString("AUTOSAVE_FLUSH_SECONDS", 5), 5)
+	AutosaveBatchSize    = positive(env.GetString("AUTOSAVE_BATCH_SIZE", 200), 200)
+	AttemptCacheTTLHours = env.GetString("ATTEMPT_CACHE_TTL_HOURS", 24)
+
+	// How often the item analysis job looks for queued runs
+	AnalysisPollSeconds = positive(env.GetString("ANALYSIS_POLL_SECONDS", 30), 30)
+
+	// Key for the candidate codes graders see in place of user identities
+	GradingAnonSecret = []byte(env.GetString("GRADING_ANON_SECRET", ""))
+
+	// Key used to hash voucher PINs
+	VoucherPinSecret = []byte(env.GetString("VOUCHER_PIN_SECRET", ""))
+
+	// Largest question package upload, in megabytes
+	ImportMaxMB = env.GetString("IMPORT_MAX_MB", 50)
+
+	// Where uploaded media is kept: "local" under StorageDir, or "s3" in an
+	// S3-compatible bucket
+	StorageDriver = env.GetString("STORAGE_DRIVER", "local")
+	StorageDir    = env.GetString("STORAGE_DIR", "./data/media")
+	S3Endpoint    = env.GetString("S3_ENDPOINT", "")
+	S3Region      = env.GetString("S3_REGION", "us-east-1")
+	S3Bucket      = env.GetString("S3_BUCKET", "")
+	S3AccessKey   = env.GetString("S3_ACCESS_KEY", "")
+	S3SecretKey   = env.GetString("S3_SECRET_KEY", "")
+
+	// Media upload limits
+	MediaMaxImageMB   = env.GetString("MEDIA_MAX_IMAGE_MB", 5)
+	MediaMaxAudioMB   = env.GetString("MEDIA_MAX_AUDIO_MB", 20)
+	MediaMaxImageSide = env.GetString("MEDIA_MAX_IMAGE_SIDE", 4096)
+
+	// Key for the expiring media links candidates get during an attempt
+	MediaURLSecret     = []byte(env.GetString("MEDIA_URL_SECRET", ""))
+	MediaURLTTLMinutes = env.GetString("MEDIA_URL_TTL_MINUTES", 60)
+
+	// Approvals a question needs from its reviewers before it can be used
+	ReviewRequiredApprovals = env.GetString("REVIEW_REQUIRED_APPROVALS", 2)
+
+	// How alike, in percent, two questions' text must be to flag them as
+	// near-duplicates, and how many questions one duplicate scan indexes
+	DuplicateSimilarityPercent = env.GetString("DUPLICATE_SIMILARITY_PERCENT", 80)
+	DuplicateScanBatch         = env.GetString("DUPLICATE_SCAN_BATCH", 500)
+
+	// Proctoring events an attempt may report per minute
+	ProctorEventsPerMinute = env.GetString("PROCTOR_EVENTS_PER_MINUTE", 60)
+
+	// Payments. PAYMENT_PROVIDER is "paystack", or "fake" in development.
+	// Paystack signs webhooks with its secret key; PAYMENT_WEBHOOK_SECRET
+	// signs the fake provider's
+	PaymentProvider      = env.GetString("PAYMENT_PROVIDER", "paystack")
+	PaystackBaseURL      = env.GetString("PAYSTACK_BASE_URL", "https://api.paystack.co")
+	PaystackSecretKey    = env.GetString("PAYSTACK_SECRET_KEY", "")
+	PaymentWebhookSecret = []byte(env.GetString("PAYMENT_WEBHOOK_SECRET", ""))
+	PaymentCallbackURL   = env.GetString("PAYMENT_CALLBACK_URL", "http://localhost:8080/payments/callback")
+	PaymentCurrency      = env.GetString("PAYMENT_CURRENCY", "NGN")
 )
+
+// Validate reports the settings the API cannot start without. Every key
+// serves one purpose and none falls back to another, so rotating one never
+// invalidates what the others signed.
+func Validate() error {
+	type secret struct {
+		name  string
+		value []byte
+	}
+	secrets := []secret{
+		{"GRADING_ANON_SECRET", GradingAnonSecret},
+		{"VOUCHER_PIN_SECRET", VoucherPinSecret},
+		{"MEDIA_URL_SECRET", MediaURLSecret},
+	}
+	switch PaymentProvider {
+	case "paystack":
+		secrets = append(secrets, secret{"PAYSTACK_SECRET_KEY", []byte(PaystackSecretKey)})
+	case "fake":
+		secrets = append(secrets, secret{"PAYMENT_WEBHOOK_SECRET", PaymentWebhookSecret})
+	}
+
+	var errs []error
+	for _, s := range secrets {
+		if len(s.value) == 0 {
+			errs = append(errs, fmt.Errorf("%s must be set", s.name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// positive returns v, or fallback when v is zero or negative. Intervals and
+// batch sizes go through it, since time.NewTicker panics on a zero period.
+func positive(v, fallback int) int {
+	if v <= 0 {
+		return fallback
+	}
+	return v
+}
@@ -33,8 +33,256 @@ const (
 	ErrAccountNotApporve = "Admin account not approved, contact super admin"
 )
 
+// Permission errors
+const (
+	ErrPermissionRequired    = "You do not have the permission required for this action"
+	ErrPermissionNotGranted  = "User does not hold this permission"
+	ErrNotAdmin              = "Permissions can only be granted to admins"
+	ErrCannotChangeAdminRole = "Admin accounts cannot change role"
+	ErrSelfGrant             = "You cannot grant permissions to yourself"
+)
+
 // Validation errors
 const (
 	ErrValidationFailed = "Validation failed"
 	ErrFailedHashPass   = "failed to hash password"
 )
+
+// Exam errors
+const (
+	ErrExamNotFound     = "Exam not found"
+	ErrExamNotPublished = "Exam is not open for attempts"
+	ErrExamUnapproved   = "Every question must be approved before the exam is published"
+)
+
+// Attempt errors
+const (
+	ErrAttemptNotFound    = "Attempt not found"
+	ErrAttemptNotCached   = "attempt is not cached"
+	ErrAttemptNotOwner    = "Attempt does not belong to this user"
+	ErrAttemptClosed      = "Attempt has already been submitted"
+	ErrAttemptExpired     = "Attempt time has elapsed"
+	ErrAnswerVersionStale = "Answer was changed elsewhere, reload and try again"
+	ErrInvalidAnswer      = "Answer must be valid JSON"
+	ErrQuestionNotOnPaper = "Question is not on this attempt's paper"
+)
+
+// Eligibility errors
+const (
+	ErrExamNotOpenYet     = "Exam has not opened yet"
+	ErrExamWindowClosed   = "Exam has closed"
+	ErrExamNotAssigned    = "Exam has not been assigned to you"
+	ErrMaxAttemptsReached = "You have used all your attempts for this exam"
+	ErrRetakeCooldown     = "You must wait before retaking this exam"
+	ErrSchoolNotEligible  = "Exam is not open to candidates from your school"
+	ErrStateNotEligible   = "Exam is not open to candidates from your state"
+	ErrProfileIncomplete  = "Complete your profile before taking this exam"
+	ErrEmailNotVerified   = "Verify your email before taking this exam"
+	ErrExamAccessRequired = "Redeem a voucher to unlock this exam"
+	ErrInvalidTimeZone    = "Unknown time zone"
+	ErrInvalidWindow      = "Exam must open before it closes"
+	ErrInvalidWindowTime  = "Window times must look like 2006-01-02T15:04"
+	ErrGroupNotFound      = "Candidate group not found"
+	ErrAssignmentNotFound = "Assignment not found"
+	ErrMemberNotFound     = "User is not a member of this group"
+)
+
+// Eligibility error codes
+const (
+	CodeExamNotOpenYet     = "EXAM_NOT_OPEN_YET"
+	CodeExamWindowClosed   = "EXAM_WINDOW_CLOSED"
+	CodeExamNotAssigned    = "EXAM_NOT_ASSIGNED"
+	CodeMaxAttemptsReached = "MAX_ATTEMPTS_REACHED"
+	CodeRetakeCooldown     = "RETAKE_COOLDOWN"
+	CodeSchoolNotEligible  = "SCHOOL_NOT_ELIGIBLE"
+	CodeStateNotEligible   = "STATE_NOT_ELIGIBLE"
+	CodeProfileIncomplete  = "PROFILE_INCOMPLETE"
+	CodeEmailNotVerified   = "EMAIL_NOT_VERIFIED"
+	CodeExamAccessRequired = "EXAM_ACCESS_REQUIRED"
+)
+
+// Voucher errors
+const (
+	ErrInvalidVoucher         = "Invalid serial number or PIN"
+	ErrVoucherVoid            = "This voucher has been cancelled"
+	ErrVoucherExpired         = "This voucher has expired"
+	ErrVoucherUsedUp          = "This voucher has reached its usage limit"
+	ErrVoucherAlreadyRedeemed = "You have already redeemed this voucher"
+	ErrVoucherNotFound        = "Voucher not found"
+	ErrBatchNotFound          = "Voucher batch not found"
+	ErrNotAgent               = "User is not an agent"
+	ErrInsufficientStock      = "Not enough unallocated vouchers left in this batch"
+	ErrInvalidDateRange       = "Dates must look like 2006-01-02 and from must be before to"
+)
+
+// Commission errors
+const (
+	ErrIdempotencyKeyRequired = "Idempotency-Key header is required"
+	ErrIdempotencyKeyReused   = "Idempotency-Key was already used for a different request"
+	ErrCommissionRuleNotFound = "Commission rule not found"
+	ErrUnbalancedTransaction  = "Ledger transaction does not balance"
+	ErrNothingToPayOut        = "Agent has no unsettled commission to pay out"
+	ErrPayoutInProgress       = "Agent already has a pending payout"
+	ErrPayoutNotFound         = "Payout not found"
+	ErrPayoutNotPending       = "Payout is no longer pending"
+	ErrPayoutAlreadyPosted    = "Payout is already posted to the ledger"
+	ErrInvalidReferralCode    = "Invalid referral code"
+)
+
+// Payment errors
+const (
+	ErrPriceNotFound          = "This item is not for sale"
+	ErrAlreadyEntitled        = "You already have access to this item"
+	ErrOrderNotFound          = "Order not found"
+	ErrInvalidOrderTransition = "Order cannot move to that status from its current one"
+	ErrUnknownTransaction     = "Transaction not found at the payment provider"
+	ErrInvalidSignature       = "Invalid webhook signature"
+	ErrStaleWebhook           = "Webhook timestamp is outside the allowed window"
+	ErrInvalidWebhookPayload  = "Invalid webhook payload"
+)
+
+// Subscription errors
+const (
+	ErrPlanNotFound         = "Plan not found"
+	ErrPlanItemNotFound     = "Plan does not include this item"
+	ErrSubscriptionNotFound = "Subscription not found"
+	ErrEntitlementRequired  = "You need a purchase or an active subscription to access this"
+)
+
+// Practice errors
+const (
+	ErrPracticePackNotFound    = "Practice pack not found"
+	ErrPracticeSessionNotFound = "Practice session not found"
+	ErrNoPracticeQuestions     = "No practice questions match the chosen topics"
+	ErrNotInPracticeSession    = "Question is not part of this practice session"
+	ErrPracticeAlreadyAnswered = "Question has already been answered in this session"
+	ErrBlankPracticeAnswer     = "Answer cannot be blank"
+	ErrInvalidPracticeAnswer   = "Answer is not in the format this question expects"
+)
+
+// Subject combination errors
+const (
+	ErrInvalidSubjectSelection = "Choose the exam's number of electives from its elective subjects"
+	ErrCourseRequired          = "Choose a course of study for this exam"
+	ErrCombinationNotAllowed   = "This subject combination is not allowed for the chosen course"
+	ErrCombinationNotFound     = "Subject combination not found"
+	ErrDuplicateExamSubject    = "Each subject can only be listed once"
+)
+
+// Adaptive testing errors
+const (
+	ErrNotAdaptiveExam         = "This exam is not adaptive"
+	ErrAdaptivePaper           = "Questions in an adaptive exam are given one at a time"
+	ErrNotCurrentItem          = "Answer the question currently presented"
+	ErrBlankAdaptiveAnswer     = "Answer cannot be blank"
+	ErrInvalidAdaptiveSettings = "Minimum items cannot be more than maximum items"
+	ErrEmptyItemPool           = "No calibrated questions are available for this exam"
+)
+
+// Item analysis errors
+const (
+	ErrAnalysisRunNotFound  = "Item analysis not found"
+	ErrAnalysisNotReady     = "Item analysis has not completed yet"
+	ErrAdaptiveItemAnalysis = "Item analysis is not available for adaptive exams"
+	ErrNotEnoughAttempts    = "At least two graded attempts are needed for item analysis"
+)
+
+// Calibration errors
+const (
+	ErrNoCalibrationData = "No questions have enough graded responses to calibrate"
+	ErrUnknownIRTModel   = "Model must be 1pl, 2pl or 3pl"
+)
+
+// Past paper errors
+const (
+	ErrPaperNotFound = "Past paper not found"
+)
+
+// Question errors
+const (
+	ErrQuestionNotFound    = "Question not found"
+	ErrUnknownQuestionType = "Unknown question type"
+	ErrInvalidAnswerKey    = "Answer key does not match the question type"
+	ErrInvalidMath         = "Math or chemical formula could not be read"
+	ErrVersionNotFound     = "Question version not found"
+	ErrQuestionUnchanged   = "Question already has this content"
+	ErrQuestionNotApproved = "Only approved questions can be added to exams"
+)
+
+// Question review errors
+const (
+	ErrReviewTransition  = "Question cannot move to that status from its current one"
+	ErrNotReviewer       = "Reviewers need the reviewer permission"
+	ErrOwnQuestionReview = "Authors cannot review their own questions"
+	ErrNotAssigned       = "You are not a reviewer of this question"
+	ErrReviewerNotFound  = "Reviewer is not assigned to this question"
+	ErrCommentNotFound   = "Comment not found"
+	ErrNotInReview       = "Question is not in review"
+)
+
+// Duplicate detection errors
+const (
+	ErrDuplicateFlagNotFound = "Duplicate flag not found"
+	ErrDuplicateResolved     = "Duplicate flag has already been resolved"
+	ErrKeepNotInPair         = "Question to keep must be one of the flagged pair"
+	ErrKeepNotUsable         = "Question to keep has been retired or merged"
+	ErrKeepNotApproved       = "Question to keep must be approved before it replaces the other on exams"
+)
+
+// Proctoring errors
+const (
+	ErrProctorRateLimited   = "Too many proctoring events, slow down"
+	ErrInvalidProctorEvent  = "Event details must be a JSON object of at most 1 KB"
+	ErrDuplicateProctorRule = "Each event type can only have one rule"
+	ErrInvalidProctorRule   = "submit_after must not be below flag_after"
+)
+
+// Grading errors
+const (
+	ErrAttemptNotSubmitted = "Attempt has not been submitted"
+)
+
+// Result errors
+const (
+	ErrResultNotFound       = "Result not found"
+	ErrResultNotReleased    = "Results for this exam have not been released yet"
+	ErrAnswerReviewDisabled = "Answer review is not available for this exam"
+)
+
+// Manual marking errors
+const (
+	ErrResponseNotFound      = "Response not found"
+	ErrResponseNotManual     = "Response is not awaiting manual marking"
+	ErrResponseNotInReview   = "Response is not awaiting moderation"
+	ErrAlreadyMarked         = "You have already marked this response"
+	ErrMarkingComplete       = "Response has already received all its marks"
+	ErrScoreOutOfRange       = "Score must be between 0 and the question's marks"
+	ErrUnknownCriterion      = "Criterion does not belong to this question's rubric"
+	ErrCriterionOutOfRange   = "Criterion points must be between 0 and its maximum"
+	ErrScoreOrCriteriaNeeded = "Provide either a score or rubric criteria scores"
+	ErrUnknownCandidate      = "Unknown candidate code"
+)
+
+// Import errors
+const (
+	ErrUnknownImportFormat = "Format must be gift, aiken or moodle_xml"
+	ErrImportHasErrors     = "Some questions cannot be imported, preview the file to see which"
+	ErrNothingToImport     = "Every question in the file is already in the bank"
+	ErrInvalidPackage      = "File is not a valid IMS content package"
+	ErrPackageTooLarge     = "Upload is larger than the import limit"
+	ErrUnknownQTIVersion   = "Version must be 2.1 or 3.0"
+	ErrInvalidSpreadsheet  = "File is not a valid CSV or XLSX spreadsheet"
+	ErrInvalidMediaArchive = "Media must be a zip file of images"
+	ErrSpreadsheetMissing  = "Attach the spreadsheet as the file field"
+)
+
+// Media errors
+const (
+	ErrMediaNotFound        = "File not found"
+	ErrUnsupportedMediaType = "Only PNG, JPEG, GIF and WebP images and MP3, M4A, AAC, OGG, WAV and FLAC audio can be uploaded"
+	ErrMediaTooLarge        = "File is larger than the upload limit for its type"
+	ErrImageTooLarge        = "Image is wider or taller than allowed"
+	ErrInvalidImage         = "Image could not be read"
+	ErrMediaLinkExpired     = "Link is invalid or has expired"
+	ErrMediaFileMissing     = "Attach the upload as the file field"
+)
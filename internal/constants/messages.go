@@ -3,15 +3,78 @@ package constants
 // Response Messages
 // Success messages
 const (
-	MsgAccountCreated       = "Account created successfully"
-	MsgAdminAccountCreated  = "Admin account created successfully, Awaiting approval"
-	MsgAdminCreated         = "Admin account created successfully"
-	MsgLoginSuccessful      = "Login successful"
-	MsgAdminLoginSuccessful = "Admin login successful"
-	MsgFetchSuccessful      = "Data fetched successfully"
-	MsgUpdateSuccessful     = "Update completed successfully"
-	MsgDeleteSuccessful     = "Resource deleted successfully"
-	MsgLogoutSuccessful     = "Logout successful"
-	PswResetSentSuccessful  = "Password reset link set to your email"
-	MsgRefresSuccessful     = "refresh done successful"
+	MsgAccountCreated        = "Account created successfully"
+	MsgAdminAccountCreated   = "Admin account created successfully, Awaiting approval"
+	MsgAdminCreated          = "Admin account created successfully"
+	MsgLoginSuccessful       = "Login successful"
+	MsgAdminLoginSuccessful  = "Admin login successful"
+	MsgFetchSuccessful       = "Data fetched successfully"
+	MsgUpdateSuccessful      = "Update completed successfully"
+	MsgDeleteSuccessful      = "Resource deleted successfully"
+	MsgLogoutSuccessful      = "Logout successful"
+	PswResetSentSuccessful   = "Password reset link set to your email"
+	MsgRefresSuccessful      = "refresh done successful"
+	MsgExamCreated           = "Exam created successfully"
+	MsgAttemptStarted        = "Attempt started successfully"
+	MsgAnswerSaved           = "Answer saved"
+	MsgAttemptSubmitted      = "Attempt submitted successfully"
+	MsgQuestionCreated       = "Question created successfully"
+	MsgAnswerKeyUpdated      = "Answer key updated and attempts re-graded"
+	MsgExamRegraded          = "Exam re-graded successfully"
+	MsgPermissionGranted     = "Permission granted successfully"
+	MsgPermissionRevoked     = "Permission revoked successfully"
+	MsgRubricSaved           = "Rubric saved successfully"
+	MsgMarkSubmitted         = "Mark submitted successfully"
+	MsgResponseModerated     = "Response moderated successfully"
+	MsgResultsPublished      = "Results published successfully"
+	MsgGroupCreated          = "Candidate group created successfully"
+	MsgMembersAdded          = "Members added successfully"
+	MsgExamAssigned          = "Exam assigned successfully"
+	MsgRoleUpdated           = "Role updated successfully"
+	MsgVoucherBatchCreated   = "Voucher batch generated successfully"
+	MsgVouchersAllocated     = "Vouchers allocated successfully"
+	MsgVoucherRedeemed       = "Voucher redeemed successfully"
+	MsgVoucherVoided         = "Voucher cancelled successfully"
+	MsgCommissionRuleSaved   = "Commission rule saved successfully"
+	MsgAdjustmentPosted      = "Adjustment posted successfully"
+	MsgPayoutCreated         = "Payout created successfully"
+	MsgPayoutCompleted       = "Payout marked as paid"
+	MsgPayoutCancelled       = "Payout cancelled successfully"
+	MsgPriceSaved            = "Price saved successfully"
+	MsgOrderCreated          = "Order created, continue to payment"
+	MsgWebhookReceived       = "Webhook received"
+	MsgOrderRefunded         = "Order refunded successfully"
+	MsgPlanCreated           = "Plan created successfully"
+	MsgPlanItemAdded         = "Item added to plan"
+	MsgPlanItemRemoved       = "Item removed from plan"
+	MsgSubscriptionGranted   = "Subscription granted successfully"
+	MsgSubscriptionCanceled  = "Subscription cancelled, access continues until the period ends"
+	MsgPracticePackCreated   = "Practice pack created successfully"
+	MsgPracticeStarted       = "Practice session started"
+	MsgPracticeAnswered      = "Answer checked"
+	MsgMockExamReady         = "Mock exam ready, start an attempt to begin"
+	MsgExamSubjectsSaved     = "Exam subjects saved successfully"
+	MsgCombinationAdded      = "Subject combination added successfully"
+	MsgCombinationRemoved    = "Subject combination removed successfully"
+	MsgAdaptiveSettingsSaved = "Adaptive settings saved successfully"
+	MsgAdaptiveDisabled      = "Adaptive mode turned off"
+	MsgItemParametersSaved   = "Item parameters saved successfully"
+	MsgAdaptiveAnswerSaved   = "Answer recorded"
+	MsgAnalysisQueued        = "Item analysis queued, check back shortly"
+	MsgQuestionsImported     = "Questions imported successfully"
+	MsgMediaUploaded         = "File uploaded successfully"
+	MsgContentRendered       = "Content rendered"
+	MsgQuestionUpdated       = "Question updated, a new version was saved"
+	MsgQuestionRolledBack    = "Question rolled back, a new version was saved"
+	MsgSubmittedForReview    = "Question submitted for review"
+	MsgReviewerAssigned      = "Reviewer assigned successfully"
+	MsgReviewerRemoved       = "Reviewer removed successfully"
+	MsgReviewRecorded        = "Review recorded"
+	MsgCommentAdded          = "Comment added successfully"
+	MsgQuestionRetired       = "Question retired"
+	MsgDuplicatesScanned     = "Questions scanned for duplicates"
+	MsgDuplicatesMerged      = "Duplicate merged, attempt history was kept"
+	MsgDuplicateDismissed    = "Duplicate flag dismissed"
+	MsgProctorEventsRecorded = "Events recorded"
+	MsgProctorRulesSaved     = "Proctoring rules saved successfully"
 )
@@ -0,0 +1,68 @@
+package entitlements
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/helpers"
+	"github.com/odundlaw/cbt-backend/internal/json"
+	"github.com/odundlaw/cbt-backend/internal/middlewares"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service,
+	}
+}
+
+func (h *Handler) Summary(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	summary, err := h.service.Summary(r.Context(), userID)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, summary, nil)
+}
+
+// Check lets clients ask ahead of time whether a resource will open, e.g. to
+// show a buy button instead.
+func (h *Handler) Check(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	targetID, err := helpers.IDParam(r, "targetID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	target := repo.AccessTarget(chi.URLParam(r, "targetType"))
+	if target != repo.AccessTargetExam && target != repo.AccessTargetPracticePack {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	decision, err := h.service.Check(r.Context(), userID, target, targetID)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, decision, nil)
+}
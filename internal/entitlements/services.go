@@ -0,0 +1,89 @@
+// Package entitlements where it is decided whether a user may use an exam, practice pack or other paid resource
+package entitlements
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+type svc struct {
+	repo *repo.Queries
+}
+
+func NewService(repo *repo.Queries) Service {
+	return &svc{repo: repo}
+}
+
+// Check looks, in order, at whether the resource is free, at direct grants
+// from vouchers and orders, and at subscriptions to plans that include it.
+// Exams are free unless their schedule requires access; everything else
+// always needs an entitlement.
+func (s *svc) Check(ctx context.Context, userID int64, target repo.AccessTarget, targetID int64) (Decision, error) {
+	if target == repo.AccessTargetExam {
+		schedule, err := s.repo.GetExamSchedule(ctx, targetID)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && !schedule.RequiresAccess) {
+			return Decision{Allowed: true, Source: SourceOpen}, nil
+		}
+		if err != nil {
+			return Decision{}, err
+		}
+	}
+
+	grant, err := s.repo.GetActiveAccessGrant(ctx, repo.GetActiveAccessGrantParams{
+		UserID:     userID,
+		TargetType: target,
+		TargetID:   targetID,
+	})
+	if err == nil {
+		decision := Decision{Allowed: true, Source: SourceGrant}
+		if grant.ExpiresAt.Valid {
+			decision.ExpiresAt = &grant.ExpiresAt.Time
+		}
+		return decision, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return Decision{}, err
+	}
+
+	sub, err := s.repo.GetPlanEntitlement(ctx, repo.GetPlanEntitlementParams{
+		UserID:     userID,
+		TargetType: target,
+		TargetID:   targetID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Decision{Allowed: false}, nil
+	}
+	if err != nil {
+		return Decision{}, err
+	}
+
+	return Decision{
+		Allowed:   true,
+		Source:    SourceSubscription,
+		ExpiresAt: &sub.GraceEndsAt.Time,
+		InGrace:   !time.Now().Before(sub.CurrentPeriodEnd.Time),
+	}, nil
+}
+
+func (s *svc) Allowed(ctx context.Context, userID int64, target repo.AccessTarget, targetID int64) (bool, error) {
+	decision, err := s.Check(ctx, userID, target, targetID)
+	return decision.Allowed, err
+}
+
+func (s *svc) Summary(ctx context.Context, userID int64) (summaryResponse, error) {
+	grants, err := s.repo.ListUserAccessGrants(ctx, userID)
+	if err != nil {
+		return summaryResponse{}, err
+	}
+
+	subs, err := s.repo.ListUserSubscriptions(ctx, userID)
+	if err != nil {
+		return summaryResponse{}, err
+	}
+
+	return summaryResponse{Grants: grants, Subscriptions: subs}, nil
+}
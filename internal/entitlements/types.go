@@ -0,0 +1,37 @@
+package entitlements
+
+import (
+	"context"
+	"time"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+type Service interface {
+	Check(ctx context.Context, userID int64, target repo.AccessTarget, targetID int64) (Decision, error)
+	Allowed(ctx context.Context, userID int64, target repo.AccessTarget, targetID int64) (bool, error)
+	Summary(ctx context.Context, userID int64) (summaryResponse, error)
+}
+
+// Where an allowed decision came from.
+const (
+	SourceOpen         = "open"
+	SourceGrant        = "grant"
+	SourceSubscription = "subscription"
+)
+
+// Decision says whether a user may use a resource and, if so, on what
+// terms.
+type Decision struct {
+	Allowed   bool       `json:"allowed"`
+	Source    string     `json:"source,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// InGrace is set when a subscription period has ended but its grace
+	// period still holds.
+	InGrace bool `json:"in_grace,omitempty"`
+}
+
+type summaryResponse struct {
+	Grants        []repo.AccessGrant              `json:"grants"`
+	Subscriptions []repo.ListUserSubscriptionsRow `json:"subscriptions"`
+}
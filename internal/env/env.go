@@ -2,6 +2,8 @@
 package env
 
 import (
+	"errors"
+	"io/fs"
 	"log"
 	"os"
 	"strconv"
@@ -9,9 +11,11 @@ import (
 	"github.com/joho/godotenv"
 )
 
+// loadEnv reads .env when there is one. Without it the process environment
+// is used as is, which is how tests and containers run.
 func loadEnv() {
 	err := godotenv.Load()
-	if err != nil {
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
 		log.Fatal("Error Loading .env file")
 	}
 }
@@ -0,0 +1,133 @@
+package exams
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/helpers"
+	"github.com/odundlaw/cbt-backend/internal/json"
+	"github.com/odundlaw/cbt-backend/internal/middlewares"
+	"github.com/odundlaw/cbt-backend/internal/validation"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service,
+	}
+}
+
+func (h *Handler) CreateExam(w http.ResponseWriter, r *http.Request) {
+	var req createExamParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	adminID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	exam, err := h.service.CreateExam(r.Context(), adminID, req)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusCreated, constants.MsgExamCreated, exam, nil)
+}
+
+func (h *Handler) ListExams(w http.ResponseWriter, r *http.Request) {
+	limit, offset := helpers.Pagination(r)
+
+	exams, err := h.service.ListExams(r.Context(), limit, offset)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, exams, nil)
+}
+
+func (h *Handler) ListPublishedExams(w http.ResponseWriter, r *http.Request) {
+	limit, offset := helpers.Pagination(r)
+
+	exams, err := h.service.ListPublishedExams(r.Context(), limit, offset)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, exams, nil)
+}
+
+func (h *Handler) GetExam(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	exam, err := h.service.GetExamByID(r.Context(), examID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		json.JSONError(w, http.StatusNotFound, constants.ErrExamNotFound, nil)
+		return
+	}
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, exam, nil)
+}
+
+func (h *Handler) UpdateExamStatus(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req updateExamStatusParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	exam, err := h.service.UpdateExamStatus(r.Context(), examID, req.Status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		json.JSONError(w, http.StatusNotFound, constants.ErrExamNotFound, nil)
+		return
+	}
+	if errors.Is(err, ErrExamUnapproved) {
+		json.JSONError(w, http.StatusConflict, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgUpdateSuccessful, exam, nil)
+}
@@ -0,0 +1,58 @@
+// Package exams where exam definitions are managed
+package exams
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+)
+
+var ErrExamUnapproved = errors.New(constants.ErrExamUnapproved)
+
+type svc struct {
+	repo *repo.Queries
+}
+
+func NewService(repo *repo.Queries) Service {
+	return &svc{repo: repo}
+}
+
+func (s *svc) CreateExam(ctx context.Context, createdBy int64, params createExamParams) (repo.Exam, error) {
+	return s.repo.CreateExam(ctx, repo.CreateExamParams{
+		Title:           params.Title,
+		Description:     pgtype.Text{String: params.Description, Valid: params.Description != ""},
+		DurationMinutes: params.DurationMinutes,
+		CreatedBy:       createdBy,
+	})
+}
+
+func (s *svc) GetExamByID(ctx context.Context, ID int64) (repo.Exam, error) {
+	return s.repo.GetExamByID(ctx, ID)
+}
+
+func (s *svc) ListExams(ctx context.Context, limit, offset int32) ([]repo.Exam, error) {
+	return s.repo.ListExams(ctx, repo.ListExamsParams{Limit: limit, Offset: offset})
+}
+
+func (s *svc) ListPublishedExams(ctx context.Context, limit, offset int32) ([]repo.Exam, error) {
+	return s.repo.ListPublishedExams(ctx, repo.ListPublishedExamsParams{Limit: limit, Offset: offset})
+}
+
+// UpdateExamStatus moves an exam between draft, published and archived. An
+// exam is only published once every question on it is approved.
+func (s *svc) UpdateExamStatus(ctx context.Context, ID int64, status repo.ExamStatus) (repo.Exam, error) {
+	if status == repo.ExamStatusPublished {
+		unapproved, err := s.repo.CountUnapprovedExamQuestions(ctx, ID)
+		if err != nil {
+			return repo.Exam{}, err
+		}
+		if unapproved > 0 {
+			return repo.Exam{}, ErrExamUnapproved
+		}
+	}
+
+	return s.repo.UpdateExamStatus(ctx, repo.UpdateExamStatusParams{ID: ID, Status: status})
+}
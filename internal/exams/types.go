@@ -0,0 +1,25 @@
+package exams
+
+import (
+	"context"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+type Service interface {
+	CreateExam(ctx context.Context, createdBy int64, params createExamParams) (repo.Exam, error)
+	GetExamByID(ctx context.Context, ID int64) (repo.Exam, error)
+	ListExams(ctx context.Context, limit, offset int32) ([]repo.Exam, error)
+	ListPublishedExams(ctx context.Context, limit, offset int32) ([]repo.Exam, error)
+	UpdateExamStatus(ctx context.Context, ID int64, status repo.ExamStatus) (repo.Exam, error)
+}
+
+type createExamParams struct {
+	Title           string `json:"title" validate:"required,min=3,max=200"`
+	Description     string `json:"description"`
+	DurationMinutes int32  `json:"duration_minutes" validate:"required,gt=0"`
+}
+
+type updateExamStatusParams struct {
+	Status repo.ExamStatus `json:"status" validate:"required,oneof=draft published archived"`
+}
@@ -0,0 +1,62 @@
+package grading
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+// gradeAdaptive scores an adaptive attempt. Each item given counts one mark
+// for the breakdown, but the total is the scaled ability estimate out of the
+// top of the scale, since candidates see different items of differing
+// difficulty. An attempt that stopped before any answer scores zero.
+func (s *svc) gradeAdaptive(ctx context.Context, attempt repo.ExamAttempt, settings repo.ExamAdaptiveSetting, changedBy int64, reason string) (repo.AttemptResult, error) {
+	responses, err := s.repo.ListAdaptiveResponses(ctx, attempt.ID)
+	if err != nil {
+		return repo.AttemptResult{}, err
+	}
+
+	previous, err := s.repo.ListAttemptQuestionScores(ctx, attempt.ID)
+	if err != nil {
+		return repo.AttemptResult{}, err
+	}
+
+	previousByQuestion := make(map[int64]repo.AttemptQuestionScore, len(previous))
+	for _, p := range previous {
+		previousByQuestion[p.QuestionID] = p
+	}
+
+	var total float64
+	state, err := s.repo.GetAdaptiveState(ctx, attempt.ID)
+	if err == nil && state.Items > 0 {
+		total = state.ScaledScore
+	}
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return repo.AttemptResult{}, err
+	}
+
+	scores := repo.UpsertAttemptQuestionScoresParams{AttemptID: attempt.ID}
+	for _, r := range responses {
+		outcome := repo.ScoreOutcomeIncorrect
+		switch {
+		case r.Fraction >= 1:
+			outcome = repo.ScoreOutcomeCorrect
+		case r.Fraction > 0:
+			outcome = repo.ScoreOutcomePartial
+		}
+
+		scores.QuestionIds = append(scores.QuestionIds, r.QuestionID)
+		scores.Scores = append(scores.Scores, round(r.Fraction))
+		scores.MaxScores = append(scores.MaxScores, 1)
+		scores.Outcomes = append(scores.Outcomes, string(outcome))
+	}
+
+	return s.record(ctx, attempt, sheet{
+		scores: scores,
+		total:  total,
+		full:   round(settings.ScaleMean + 4*settings.ScaleSd),
+		status: repo.ResultStatusGraded,
+	}, previousByQuestion, changedBy, reason)
+}
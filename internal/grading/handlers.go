@@ -0,0 +1,130 @@
+package grading
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/helpers"
+	"github.com/odundlaw/cbt-backend/internal/json"
+	"github.com/odundlaw/cbt-backend/internal/middlewares"
+	"github.com/odundlaw/cbt-backend/internal/validation"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service,
+	}
+}
+
+func (h *Handler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	policy, err := h.service.GetPolicy(r.Context(), examID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		json.JSONError(w, http.StatusNotFound, constants.ErrExamNotFound, nil)
+		return
+	}
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, policy, nil)
+}
+
+func (h *Handler) UpsertPolicy(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req upsertPolicyParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	policy, err := h.service.UpsertPolicy(r.Context(), examID, req)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgUpdateSuccessful, policy, nil)
+}
+
+func (h *Handler) RegradeExam(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req regradeParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	adminID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	regraded, err := h.service.RegradeExam(r.Context(), examID, adminID, req.Reason)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgExamRegraded, regradeResponse{
+		ExamID:           examID,
+		AttemptsRegraded: regraded,
+	}, nil)
+}
+
+func (h *Handler) GetAttemptScores(w http.ResponseWriter, r *http.Request) {
+	attemptID, err := helpers.IDParam(r, "attemptID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	scores, err := h.service.GetAttemptScores(r.Context(), attemptID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		json.JSONError(w, http.StatusNotFound, constants.ErrNotFound, nil)
+		return
+	}
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, scores, nil)
+}
@@ -0,0 +1,120 @@
+package grading
+
+import (
+	"encoding/json"
+	"math"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/questions"
+)
+
+// Policy is an exam's grading rules in a form the scorer can use directly.
+type Policy struct {
+	NegativeMarking float64
+	PartialCredit   bool
+	SectionWeights  map[string]float64
+	MaxScore        float64 // 0 means uncapped
+}
+
+// defaultPolicy applies when an exam has no grading policy row: plain
+// all-or-nothing marking with no deductions.
+var defaultPolicy = Policy{SectionWeights: map[string]float64{}}
+
+func policyFromRow(row repo.ExamGradingPolicy) (Policy, error) {
+	p := Policy{
+		NegativeMarking: row.NegativeMarking,
+		PartialCredit:   row.PartialCredit,
+		SectionWeights:  map[string]float64{},
+	}
+
+	if len(row.SectionWeights) > 0 {
+		if err := json.Unmarshal(row.SectionWeights, &p.SectionWeights); err != nil {
+			return Policy{}, err
+		}
+	}
+
+	if row.MaxScore.Valid {
+		p.MaxScore = row.MaxScore.Float64
+	}
+
+	return p, nil
+}
+
+func (p Policy) weight(section string) float64 {
+	if w, ok := p.SectionWeights[section]; ok {
+		return w
+	}
+	return 1
+}
+
+// ScoreQuestion turns a grader result into a weighted score for one question.
+// Blank answers score zero and are never penalised.
+func (p Policy) ScoreQuestion(marks float64, section string, res questions.Result, blank bool) (float64, float64, repo.ScoreOutcome) {
+	weight := p.weight(section)
+	full := round(marks * weight)
+
+	switch {
+	case blank:
+		return 0, full, repo.ScoreOutcomeUnanswered
+	case res.Manual:
+		return 0, full, repo.ScoreOutcomePending
+	}
+
+	fraction := res.Fraction
+	if !p.PartialCredit && fraction < 1 {
+		fraction = 0
+	}
+
+	switch {
+	case fraction >= 1:
+		return full, full, repo.ScoreOutcomeCorrect
+	case fraction > 0:
+		return round(fraction * full), full, repo.ScoreOutcomePartial
+	default:
+		return round(-p.NegativeMarking * full), full, repo.ScoreOutcomeIncorrect
+	}
+}
+
+// Total sums question scores into the attempt total. Negative totals are
+// floored at zero and both total and maximum are capped at MaxScore.
+func (p Policy) Total(score, full float64) (float64, float64) {
+	score = math.Max(0, score)
+	if p.MaxScore > 0 {
+		score = math.Min(score, p.MaxScore)
+		full = math.Min(full, p.MaxScore)
+	}
+	return round(score), round(full)
+}
+
+// scaleSubjects puts each subject the candidate sat on its own 0..scaled_max
+// scale, so subjects with more questions do not outweigh the rest. A
+// subject's raw score is floored at zero before scaling. The attempt total
+// is the sum of the scaled scores.
+func scaleSubjects(attemptID int64, subjects []repo.ExamSubject, sitting map[string]bool, raw, rawMax map[string]float64) repo.UpsertAttemptSubjectScoresParams {
+	params := repo.UpsertAttemptSubjectScoresParams{AttemptID: attemptID}
+
+	for _, subject := range subjects {
+		if !sitting[subject.Subject] {
+			continue
+		}
+
+		score, full := math.Max(0, raw[subject.Subject]), rawMax[subject.Subject]
+
+		scaled := 0.0
+		if full > 0 {
+			scaled = round(score / full * subject.ScaledMax)
+		}
+
+		params.Subjects = append(params.Subjects, subject.Subject)
+		params.RawScores = append(params.RawScores, round(score))
+		params.RawMaxes = append(params.RawMaxes, round(full))
+		params.ScaledScores = append(params.ScaledScores, scaled)
+		params.ScaledMaxes = append(params.ScaledMaxes, subject.ScaledMax)
+	}
+
+	return params
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,145 @@
+package grading
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/questions"
+)
+
+func TestScoreQuestion(t *testing.T) {
+	weighted := Policy{SectionWeights: map[string]float64{"maths": 2}}
+	partial := Policy{PartialCredit: true, SectionWeights: map[string]float64{}}
+	negative := Policy{NegativeMarking: 0.25, SectionWeights: map[string]float64{}}
+
+	tests := []struct {
+		name        string
+		policy      Policy
+		marks       float64
+		section     string
+		res         questions.Result
+		blank       bool
+		wantScore   float64
+		wantMax     float64
+		wantOutcome repo.ScoreOutcome
+	}{
+		{"correct", defaultPolicy, 2, "", questions.Result{Fraction: 1}, false, 2, 2, repo.ScoreOutcomeCorrect},
+		{"wrong", defaultPolicy, 2, "", questions.Result{}, false, 0, 2, repo.ScoreOutcomeIncorrect},
+		{"partial without partial credit", defaultPolicy, 2, "", questions.Result{Fraction: 0.5}, false, 0, 2, repo.ScoreOutcomeIncorrect},
+		{"partial with partial credit", partial, 3, "", questions.Result{Fraction: 1.0 / 3}, false, 1, 3, repo.ScoreOutcomePartial},
+		{"blank is never penalised", negative, 4, "", questions.Result{}, true, 0, 4, repo.ScoreOutcomeUnanswered},
+		{"wrong with negative marking", negative, 4, "", questions.Result{}, false, -1, 4, repo.ScoreOutcomeIncorrect},
+		{"manual waits for a person", negative, 5, "", questions.Result{Manual: true}, false, 0, 5, repo.ScoreOutcomePending},
+		{"weighted section", weighted, 1.5, "maths", questions.Result{Fraction: 1}, false, 3, 3, repo.ScoreOutcomeCorrect},
+		{"unweighted section", weighted, 1.5, "english", questions.Result{Fraction: 1}, false, 1.5, 1.5, repo.ScoreOutcomeCorrect},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, full, outcome := tt.policy.ScoreQuestion(tt.marks, tt.section, tt.res, tt.blank)
+			if score != tt.wantScore || full != tt.wantMax || outcome != tt.wantOutcome {
+				t.Errorf("ScoreQuestion() = %v, %v, %v; want %v, %v, %v",
+					score, full, outcome, tt.wantScore, tt.wantMax, tt.wantOutcome)
+			}
+		})
+	}
+}
+
+func TestTotal(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    Policy
+		score     float64
+		full      float64
+		wantScore float64
+		wantFull  float64
+	}{
+		{"uncapped", defaultPolicy, 42.345, 60, 42.35, 60},
+		{"negative floored at zero", defaultPolicy, -3, 10, 0, 10},
+		{"capped", Policy{MaxScore: 50}, 55, 80, 50, 50},
+		{"under the cap", Policy{MaxScore: 50}, 20, 40, 20, 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, full := tt.policy.Total(tt.score, tt.full)
+			if score != tt.wantScore || full != tt.wantFull {
+				t.Errorf("Total() = %v, %v; want %v, %v", score, full, tt.wantScore, tt.wantFull)
+			}
+		})
+	}
+}
+
+func TestScaleSubjects(t *testing.T) {
+	subjects := []repo.ExamSubject{
+		{Subject: "english", ScaledMax: 100},
+		{Subject: "maths", ScaledMax: 100},
+		{Subject: "physics", ScaledMax: 100},
+	}
+	sitting := map[string]bool{"english": true, "maths": true}
+	raw := map[string]float64{"english": 30, "maths": -2, "physics": 10}
+	rawMax := map[string]float64{"english": 60, "maths": 40, "physics": 20}
+
+	got := scaleSubjects(7, subjects, sitting, raw, rawMax)
+	want := repo.UpsertAttemptSubjectScoresParams{
+		AttemptID:    7,
+		Subjects:     []string{"english", "maths"},
+		RawScores:    []float64{30, 0},
+		RawMaxes:     []float64{60, 40},
+		ScaledScores: []float64{50, 0},
+		ScaledMaxes:  []float64{100, 100},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("scaleSubjects() = %+v; want %+v", got, want)
+	}
+}
+
+func TestPolicyFromRow(t *testing.T) {
+	tests := []struct {
+		name    string
+		row     repo.ExamGradingPolicy
+		want    Policy
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			row:  repo.ExamGradingPolicy{},
+			want: Policy{SectionWeights: map[string]float64{}},
+		},
+		{
+			name: "all set",
+			row: repo.ExamGradingPolicy{
+				NegativeMarking: 0.5,
+				PartialCredit:   true,
+				SectionWeights:  []byte(`{"maths":2}`),
+				MaxScore:        pgtype.Float8{Float64: 400, Valid: true},
+			},
+			want: Policy{
+				NegativeMarking: 0.5,
+				PartialCredit:   true,
+				SectionWeights:  map[string]float64{"maths": 2},
+				MaxScore:        400,
+			},
+		},
+		{
+			name:    "bad weights",
+			row:     repo.ExamGradingPolicy{SectionWeights: []byte(`[1]`)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := policyFromRow(tt.row)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("policyFromRow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("policyFromRow() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
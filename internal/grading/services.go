@@ -0,0 +1,361 @@
+// Package grading where submitted attempts are scored
+package grading
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/questions"
+)
+
+var ErrAttemptNotSubmitted = errors.New(constants.ErrAttemptNotSubmitted)
+
+type svc struct {
+	repo *repo.Queries
+	db   *pgxpool.Pool
+}
+
+func NewService(repo *repo.Queries, db *pgxpool.Pool) Service {
+	return &svc{repo: repo, db: db}
+}
+
+// GradeAttempt scores every question of a submitted attempt under the exam's
+// policy and stores per-question and total scores. Scores a person already
+// gave to manually graded questions are kept. When the attempt was graded
+// before, every score that moved is written to score_changes with reason.
+// Adaptive exams are scored on their ability scale instead.
+func (s *svc) GradeAttempt(ctx context.Context, attemptID, changedBy int64, reason string) (repo.AttemptResult, error) {
+	attempt, err := s.repo.GetAttemptByID(ctx, attemptID)
+	if err != nil {
+		return repo.AttemptResult{}, err
+	}
+
+	if attempt.Status != repo.AttemptStatusSubmitted {
+		return repo.AttemptResult{}, ErrAttemptNotSubmitted
+	}
+
+	adaptive, err := s.repo.GetAdaptiveSettings(ctx, attempt.ExamID)
+	if err == nil {
+		return s.gradeAdaptive(ctx, attempt, adaptive, changedBy, reason)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return repo.AttemptResult{}, err
+	}
+
+	policy, err := s.policy(ctx, attempt.ExamID)
+	if err != nil {
+		return repo.AttemptResult{}, err
+	}
+
+	// Each question is scored as the candidate was shown it, against the
+	// latest key given to that content.
+	examQuestions, err := s.repo.ListAttemptQuestionsForGrading(ctx, attemptID)
+	if err != nil {
+		return repo.AttemptResult{}, err
+	}
+
+	answers, err := s.repo.ListAttemptAnswers(ctx, attemptID)
+	if err != nil {
+		return repo.AttemptResult{}, err
+	}
+
+	answerByQuestion := make(map[int64][]byte, len(answers))
+	for _, a := range answers {
+		answerByQuestion[a.QuestionID] = a.Answer
+	}
+
+	previous, err := s.repo.ListAttemptQuestionScores(ctx, attemptID)
+	if err != nil {
+		return repo.AttemptResult{}, err
+	}
+
+	previousByQuestion := make(map[int64]repo.AttemptQuestionScore, len(previous))
+	for _, p := range previous {
+		previousByQuestion[p.QuestionID] = p
+	}
+
+	examSubjects, err := s.repo.ListExamSubjects(ctx, attempt.ExamID)
+	if err != nil {
+		return repo.AttemptResult{}, err
+	}
+
+	// In a combination exam only the subjects the candidate chose count.
+	sitting := make(map[string]bool, len(attempt.Subjects))
+	for _, subject := range attempt.Subjects {
+		sitting[subject] = true
+	}
+	bySubject := len(examSubjects) > 0 && len(sitting) > 0
+	rawScores := make(map[string]float64, len(sitting))
+	rawMaxes := make(map[string]float64, len(sitting))
+
+	scores := repo.UpsertAttemptQuestionScoresParams{AttemptID: attemptID}
+	var total, full float64
+	status := repo.ResultStatusGraded
+
+	for _, q := range examQuestions {
+		if bySubject && !sitting[q.Section] {
+			continue
+		}
+
+		grader, err := questions.GraderFor(q.Type)
+		if err != nil {
+			return repo.AttemptResult{}, err
+		}
+
+		answer := answerByQuestion[q.QuestionID]
+		blank := questions.IsBlank(answer)
+
+		var res questions.Result
+		if !blank {
+			// An answer the grader can't read is simply wrong.
+			res, _ = grader.Grade(q.AnswerKey, answer)
+		}
+
+		score, qmax, outcome := policy.ScoreQuestion(q.Marks, q.Section, res, blank)
+
+		if prev, ok := previousByQuestion[q.QuestionID]; ok && res.Manual && prev.Outcome != repo.ScoreOutcomePending {
+			score, outcome = prev.Score, prev.Outcome
+		}
+
+		if outcome == repo.ScoreOutcomePending {
+			status = repo.ResultStatusPendingManual
+		}
+
+		scores.QuestionIds = append(scores.QuestionIds, q.QuestionID)
+		scores.Scores = append(scores.Scores, score)
+		scores.MaxScores = append(scores.MaxScores, qmax)
+		scores.Outcomes = append(scores.Outcomes, string(outcome))
+
+		total += score
+		full += qmax
+		rawScores[q.Section] += score
+		rawMaxes[q.Section] += qmax
+	}
+
+	var subjectScores repo.UpsertAttemptSubjectScoresParams
+	if bySubject {
+		subjectScores = scaleSubjects(attemptID, examSubjects, sitting, rawScores, rawMaxes)
+		total, full = 0, 0
+		for i := range subjectScores.Subjects {
+			total += subjectScores.ScaledScores[i]
+			full += subjectScores.ScaledMaxes[i]
+		}
+		total, full = round(total), round(full)
+	} else {
+		total, full = policy.Total(total, full)
+	}
+
+	return s.record(ctx, attempt, sheet{
+		scores:   scores,
+		subjects: subjectScores,
+		total:    total,
+		full:     full,
+		status:   status,
+	}, previousByQuestion, changedBy, reason)
+}
+
+// sheet holds an attempt's scores ready to be stored.
+type sheet struct {
+	scores   repo.UpsertAttemptQuestionScoresParams
+	subjects repo.UpsertAttemptSubjectScoresParams
+	total    float64
+	full     float64
+	status   repo.ResultStatus
+}
+
+// record stores a graded sheet. When the attempt was graded before, every
+// score that moved against previous is written to score_changes with reason.
+func (s *svc) record(ctx context.Context, attempt repo.ExamAttempt, sheet sheet, previous map[int64]repo.AttemptQuestionScore, changedBy int64, reason string) (repo.AttemptResult, error) {
+	previousResult, err := s.repo.GetAttemptResult(ctx, attempt.ID)
+	regrading := err == nil
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return repo.AttemptResult{}, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.AttemptResult{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	if len(sheet.scores.QuestionIds) > 0 {
+		if err := qtx.UpsertAttemptQuestionScores(ctx, sheet.scores); err != nil {
+			return repo.AttemptResult{}, err
+		}
+	}
+
+	if len(sheet.subjects.Subjects) > 0 {
+		if err := qtx.UpsertAttemptSubjectScores(ctx, sheet.subjects); err != nil {
+			return repo.AttemptResult{}, err
+		}
+	}
+
+	result, err := qtx.UpsertAttemptResult(ctx, repo.UpsertAttemptResultParams{
+		AttemptID:  attempt.ID,
+		ExamID:     attempt.ExamID,
+		UserID:     attempt.UserID,
+		TotalScore: sheet.total,
+		MaxScore:   sheet.full,
+		Status:     sheet.status,
+	})
+	if err != nil {
+		return repo.AttemptResult{}, err
+	}
+
+	if regrading {
+		by := pgtype.Int8{Int64: changedBy, Valid: changedBy > 0}
+
+		for i, questionID := range sheet.scores.QuestionIds {
+			prev, ok := previous[questionID]
+			if !ok || prev.Score == sheet.scores.Scores[i] {
+				continue
+			}
+			if err := qtx.CreateScoreChange(ctx, repo.CreateScoreChangeParams{
+				AttemptID:  attempt.ID,
+				QuestionID: pgtype.Int8{Int64: questionID, Valid: true},
+				OldScore:   prev.Score,
+				NewScore:   sheet.scores.Scores[i],
+				Reason:     reason,
+				ChangedBy:  by,
+			}); err != nil {
+				return repo.AttemptResult{}, err
+			}
+		}
+
+		if previousResult.TotalScore != sheet.total {
+			if err := qtx.CreateScoreChange(ctx, repo.CreateScoreChangeParams{
+				AttemptID: attempt.ID,
+				OldScore:  previousResult.TotalScore,
+				NewScore:  sheet.total,
+				Reason:    reason,
+				ChangedBy: by,
+			}); err != nil {
+				return repo.AttemptResult{}, err
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.AttemptResult{}, err
+	}
+
+	return result, nil
+}
+
+func (s *svc) RegradeExam(ctx context.Context, examID, changedBy int64, reason string) (int, error) {
+	attemptIDs, err := s.repo.ListSubmittedAttemptIDs(ctx, examID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Each attempt is committed as it is graded, so on failure the count is
+	// of the attempts already regraded.
+	for i, attemptID := range attemptIDs {
+		if _, err := s.GradeAttempt(ctx, attemptID, changedBy, reason); err != nil {
+			return i, err
+		}
+	}
+
+	return len(attemptIDs), nil
+}
+
+func (s *svc) RegradeQuestion(ctx context.Context, questionID, changedBy int64, reason string) (int, error) {
+	examIDs, err := s.repo.ListExamIDsByQuestion(ctx, questionID)
+	if err != nil {
+		return 0, err
+	}
+
+	regraded := 0
+	for _, examID := range examIDs {
+		n, err := s.RegradeExam(ctx, examID, changedBy, reason)
+		regraded += n
+		if err != nil {
+			return regraded, err
+		}
+	}
+
+	return regraded, nil
+}
+
+func (s *svc) GetPolicy(ctx context.Context, examID int64) (repo.ExamGradingPolicy, error) {
+	policy, err := s.repo.GetGradingPolicy(ctx, examID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, err := s.repo.GetExamByID(ctx, examID); err != nil {
+			return repo.ExamGradingPolicy{}, err
+		}
+		return repo.ExamGradingPolicy{ExamID: examID, SectionWeights: []byte("{}")}, nil
+	}
+
+	return policy, err
+}
+
+func (s *svc) UpsertPolicy(ctx context.Context, examID int64, params upsertPolicyParams) (repo.ExamGradingPolicy, error) {
+	weights := params.SectionWeights
+	if weights == nil {
+		weights = map[string]float64{}
+	}
+
+	raw, err := json.Marshal(weights)
+	if err != nil {
+		return repo.ExamGradingPolicy{}, err
+	}
+
+	return s.repo.UpsertGradingPolicy(ctx, repo.UpsertGradingPolicyParams{
+		ExamID:              examID,
+		NegativeMarking:     params.NegativeMarking,
+		PartialCredit:       params.PartialCredit,
+		SectionWeights:      raw,
+		MaxScore:            pgtype.Float8{Float64: params.MaxScore, Valid: params.MaxScore > 0},
+		DoubleMarking:       params.DoubleMarking,
+		ModerationThreshold: params.ModerationThreshold,
+	})
+}
+
+func (s *svc) GetAttemptScores(ctx context.Context, attemptID int64) (attemptScoresResponse, error) {
+	result, err := s.repo.GetAttemptResult(ctx, attemptID)
+	if err != nil {
+		return attemptScoresResponse{}, err
+	}
+
+	scores, err := s.repo.ListAttemptQuestionScores(ctx, attemptID)
+	if err != nil {
+		return attemptScoresResponse{}, err
+	}
+
+	subjects, err := s.repo.ListAttemptSubjectScores(ctx, attemptID)
+	if err != nil {
+		return attemptScoresResponse{}, err
+	}
+
+	history, err := s.repo.ListScoreChanges(ctx, attemptID)
+	if err != nil {
+		return attemptScoresResponse{}, err
+	}
+
+	return attemptScoresResponse{
+		Result:    result,
+		Questions: scores,
+		Subjects:  subjects,
+		History:   history,
+	}, nil
+}
+
+func (s *svc) policy(ctx context.Context, examID int64) (Policy, error) {
+	row, err := s.repo.GetGradingPolicy(ctx, examID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return defaultPolicy, nil
+	}
+	if err != nil {
+		return Policy{}, err
+	}
+
+	return policyFromRow(row)
+}
@@ -0,0 +1,44 @@
+package grading
+
+import (
+	"context"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+type Service interface {
+	GradeAttempt(ctx context.Context, attemptID, changedBy int64, reason string) (repo.AttemptResult, error)
+	RegradeExam(ctx context.Context, examID, changedBy int64, reason string) (int, error)
+	RegradeQuestion(ctx context.Context, questionID, changedBy int64, reason string) (int, error)
+	GetPolicy(ctx context.Context, examID int64) (repo.ExamGradingPolicy, error)
+	UpsertPolicy(ctx context.Context, examID int64, params upsertPolicyParams) (repo.ExamGradingPolicy, error)
+	GetAttemptScores(ctx context.Context, attemptID int64) (attemptScoresResponse, error)
+}
+
+type upsertPolicyParams struct {
+	NegativeMarking float64            `json:"negative_marking" validate:"gte=0,lte=1"`
+	PartialCredit   bool               `json:"partial_credit"`
+	SectionWeights  map[string]float64 `json:"section_weights" validate:"dive,gte=0"`
+	MaxScore        float64            `json:"max_score" validate:"omitempty,gt=0"`
+	DoubleMarking   bool               `json:"double_marking"`
+	// ModerationThreshold is the largest gap between two graders' marks
+	// that is settled by averaging rather than sent to a moderator.
+	ModerationThreshold float64 `json:"moderation_threshold" validate:"gte=0"`
+}
+
+type regradeParams struct {
+	Reason string `json:"reason" validate:"required,min=3"`
+}
+
+type regradeResponse struct {
+	ExamID           int64 `json:"exam_id"`
+	AttemptsRegraded int   `json:"attempts_regraded"`
+}
+
+type attemptScoresResponse struct {
+	Result    repo.AttemptResult          `json:"result"`
+	Questions []repo.AttemptQuestionScore `json:"questions"`
+	// Subjects holds scaled scores for exams with subjects.
+	Subjects []repo.AttemptSubjectScore `json:"subjects"`
+	History  []repo.ScoreChange         `json:"history"`
+}
@@ -3,9 +3,11 @@ package helpers
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/go-chi/chi"
 	"github.com/odundlaw/cbt-backend/internal/jwt"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -85,3 +87,50 @@ func BearerFromHeader(r *http.Request) string {
 
 	return ""
 }
+
+// IDParam parses a numeric chi URL parameter.
+func IDParam(r *http.Request, name string) (int64, error) {
+	return strconv.ParseInt(chi.URLParam(r, name), 10, 64)
+}
+
+// Pagination reads limit and offset from the query string, falling back to
+// the first 20 rows and capping limit at 100.
+func Pagination(r *http.Request) (int32, int32) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	return int32(limit), int32(offset)
+}
+
+// OptionalInt32 parses a numeric query parameter. It returns 0 when the
+// parameter is missing.
+func OptionalInt32(r *http.Request, name string) (int32, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.ParseInt(v, 10, 32)
+	return int32(n), err
+}
+
+// OptionalInt64 parses a numeric query parameter, such as an ID. It returns
+// 0 when the parameter is missing.
+func OptionalInt64(r *http.Request, name string) (int64, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseInt(v, 10, 64)
+}
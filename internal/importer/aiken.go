@@ -0,0 +1,109 @@
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+var (
+	aikenOption = regexp.MustCompile(`^([A-Z])[.)]\s+(.+)$`)
+	aikenAnswer = regexp.MustCompile(`^ANSWER:\s*(\S*)\s*$`)
+)
+
+// parseAiken reads Moodle's Aiken format: a stem, lettered options and an
+// ANSWER line, e.g.
+//
+//	What is the capital of Nigeria?
+//	A. Lagos
+//	B. Abuja
+//	ANSWER: B
+//
+// Every Aiken question is single choice. Blank lines between questions are
+// optional.
+func parseAiken(content string) ([]Draft, []Issue) {
+	var drafts []Draft
+	var issues []Issue
+
+	var stem []string
+	var options []option
+	start := 0
+
+	reset := func() {
+		stem, options, start = nil, nil, 0
+	}
+
+	for i, line := range strings.Split(content, "\n") {
+		n := i + 1
+		line = strings.TrimSpace(line)
+
+		if line == "" {
+			if len(options) > 0 {
+				issues = append(issues, Issue{Line: start, Severity: SeverityError, Message: "question has no ANSWER line"})
+				reset()
+			}
+			continue
+		}
+
+		if m := aikenAnswer.FindStringSubmatch(line); m != nil {
+			switch {
+			case len(stem) == 0:
+				issues = append(issues, Issue{Line: n, Severity: SeverityError, Message: "ANSWER line has no question before it"})
+			case len(options) < 2:
+				issues = append(issues, Issue{Line: start, Severity: SeverityError, Message: "question needs at least two options"})
+			default:
+				if d, ok := aikenDraft(start, stem, options, m[1]); ok {
+					drafts = append(drafts, d)
+				} else {
+					issues = append(issues, Issue{Line: n, Severity: SeverityError, Message: fmt.Sprintf("answer %q is not one of the options", m[1])})
+				}
+			}
+			reset()
+			continue
+		}
+
+		if m := aikenOption.FindStringSubmatch(line); m != nil && len(stem) > 0 {
+			if want := choiceLabel(len(options)); m[1] != want {
+				issues = append(issues, Issue{Line: n, Severity: SeverityWarning, Message: fmt.Sprintf("option %s is out of order, expected %s", m[1], want)})
+			}
+			options = append(options, option{Label: m[1], Text: strings.TrimSpace(m[2])})
+			continue
+		}
+
+		if len(options) > 0 {
+			// Text after the options without an ANSWER line in between
+			// starts the next question, so the last one had no answer.
+			issues = append(issues, Issue{Line: start, Severity: SeverityError, Message: "question has no ANSWER line"})
+			reset()
+		}
+
+		if len(stem) == 0 {
+			start = n
+		}
+		stem = append(stem, line)
+	}
+
+	if len(stem) > 0 {
+		issues = append(issues, Issue{Line: start, Severity: SeverityError, Message: "question has no ANSWER line"})
+	}
+
+	return drafts, issues
+}
+
+func aikenDraft(line int, stem []string, options []option, answer string) (Draft, bool) {
+	for _, opt := range options {
+		if opt.Label == answer {
+			return Draft{
+				Line:      line,
+				Type:      repo.QuestionTypeSingleChoice,
+				Stem:      strings.Join(stem, "\n"),
+				Options:   encode(options),
+				AnswerKey: encode(answer),
+				Marks:     1,
+			}, true
+		}
+	}
+	return Draft{}, false
+}
@@ -0,0 +1,194 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/similarity"
+)
+
+type Format string
+
+const (
+	FormatGIFT      Format = "gift"
+	FormatAiken     Format = "aiken"
+	FormatMoodleXML Format = "moodle_xml"
+	FormatQTI       Format = "qti"
+	FormatCSV       Format = "csv"
+	FormatXLSX      Format = "xlsx"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Draft is one question read from an import file, in the shape the bank
+// stores it. Line is where the question starts in the source, and File the
+// package entry it came from when the source is a package.
+type Draft struct {
+	File        string            `json:"file,omitempty"`
+	Line        int               `json:"line"`
+	Type        repo.QuestionType `json:"type"`
+	Stem        string            `json:"stem"`
+	Options     json.RawMessage   `json:"options"`
+	AnswerKey   json.RawMessage   `json:"answer_key"`
+	Explanation string            `json:"explanation,omitempty"`
+	Marks       float64           `json:"marks"`
+	Subject     string            `json:"subject,omitempty"`
+	Topic       string            `json:"topic,omitempty"`
+	// Media lists the package files the question links to. They are stored
+	// and the links rewritten when the question is imported.
+	Media []string `json:"media,omitempty"`
+	// DuplicateOf is the bank question with the same stem, and DuplicateLine
+	// and DuplicateFile locate an earlier question in the same import with it.
+	DuplicateOf   *int64 `json:"duplicate_of,omitempty"`
+	DuplicateLine int    `json:"duplicate_line,omitempty"`
+	DuplicateFile string `json:"duplicate_file,omitempty"`
+	// SimilarTo lists bank questions whose text is close to this one's
+	// without being the same. They are imported, and flagged for review.
+	SimilarTo []similarity.Match `json:"similar_to,omitempty"`
+
+	// original is one more than the index of that earlier question.
+	original int
+}
+
+// Issue is something a parser could not carry over. Errors stop the question
+// from being imported; warnings mean it was imported with something left out.
+type Issue struct {
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// parser reads a whole file. Questions it cannot import are reported as
+// issues and left out of the drafts.
+type parser func(content string) ([]Draft, []Issue)
+
+var parsers = map[Format]parser{
+	FormatGIFT:      parseGIFT,
+	FormatAiken:     parseAiken,
+	FormatMoodleXML: parseMoodleXML,
+}
+
+// option is one entry of a choice question's options, e.g.
+// {"label": "B", "text": "Abuja"}.
+type option struct {
+	Label string `json:"label"`
+	Text  string `json:"text"`
+}
+
+// matchingOptions lists both sides of a matching question. Prompts are
+// labelled 1, 2, ... and choices a, b, ... as the matching key expects.
+type matchingOptions struct {
+	Prompts []option `json:"prompts"`
+	Choices []option `json:"choices"`
+}
+
+// pairUp builds a matching question from prompt and answer pairs. A pair
+// with an empty prompt only adds a distractor answer.
+func pairUp(pairs [][2]string) (matchingOptions, map[string]string, error) {
+	opts := matchingOptions{Prompts: []option{}, Choices: []option{}}
+	key := map[string]string{}
+	choices := map[string]string{}
+
+	for _, pair := range pairs {
+		prompt, choice := pair[0], pair[1]
+
+		label, ok := choices[choice]
+		if !ok {
+			if len(opts.Choices) == maxChoices {
+				return opts, nil, fmt.Errorf("matching questions can have at most %d answers", maxChoices)
+			}
+			label = matchLabel(len(opts.Choices))
+			choices[choice] = label
+			opts.Choices = append(opts.Choices, option{Label: label, Text: choice})
+		}
+
+		if prompt == "" {
+			continue
+		}
+		p := promptLabel(len(opts.Prompts))
+		opts.Prompts = append(opts.Prompts, option{Label: p, Text: prompt})
+		key[p] = label
+	}
+
+	if len(key) == 0 {
+		return opts, nil, errors.New("matching question has no prompts")
+	}
+	return opts, key, nil
+}
+
+type numericKey struct {
+	Value     float64 `json:"value"`
+	Tolerance float64 `json:"tolerance"`
+}
+
+// manualKey is guidance for whoever marks a short answer or essay.
+type manualKey struct {
+	Accepted []string `json:"accepted,omitempty"`
+	Guidance string   `json:"guidance,omitempty"`
+}
+
+// maxChoices is how many options fit the A to Z labels.
+const maxChoices = 26
+
+func choiceLabel(i int) string {
+	return string(rune('A' + i))
+}
+
+func promptLabel(i int) string {
+	return strconv.Itoa(i + 1)
+}
+
+func matchLabel(i int) string {
+	return string(rune('a' + i))
+}
+
+func encode(v any) json.RawMessage {
+	b, _ := json.Marshal(v)
+	return b
+}
+
+// normalizeStem matches the questions_normalized_stem_idx expression, so
+// stems compare the same here and in the database.
+func normalizeStem(stem string) string {
+	return strings.Join(strings.Fields(strings.ToLower(stem)), " ")
+}
+
+// splitCategory turns a Moodle category path such as
+// "$course$/top/Mathematics/Algebra" into a subject and topic. The first
+// named level is the subject and the last, when deeper, the topic.
+func splitCategory(path string) (subject, topic string) {
+	var parts []string
+	for _, part := range strings.Split(path, "/") {
+		part = strings.TrimSpace(part)
+		if part == "" || part == "top" || (strings.HasPrefix(part, "$") && strings.HasSuffix(part, "$")) {
+			continue
+		}
+		parts = append(parts, part)
+	}
+
+	switch len(parts) {
+	case 0:
+		return "", ""
+	case 1:
+		return parts[0], ""
+	}
+	return parts[0], parts[len(parts)-1]
+}
+
+func sortIssues(issues []Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		return issues[i].Line < issues[j].Line
+	})
+}
@@ -0,0 +1,479 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+var giftFormat = regexp.MustCompile(`^\[(html|moodle|plain|markdown)\]\s*`)
+
+// giftBlock is the text of one question with comments removed. lines holds
+// the source line of each of its lines.
+type giftBlock struct {
+	lines []int
+	text  string
+}
+
+func (b giftBlock) lineAt(offset int) int {
+	return b.lines[strings.Count(b.text[:offset], "\n")]
+}
+
+// giftAnswer is one entry of an answer block, e.g. "=%50%Abuja#feedback".
+type giftAnswer struct {
+	offset   int
+	correct  bool
+	weight   *float64
+	text     string
+	feedback bool
+}
+
+// credit is the share of marks the answer earns in Moodle: full for "="
+// and nothing for "~" unless a weight says otherwise.
+func (a giftAnswer) credit() float64 {
+	switch {
+	case a.weight != nil:
+		return *a.weight
+	case a.correct:
+		return 100
+	}
+	return 0
+}
+
+// parseGIFT reads Moodle's GIFT format. Questions are separated by blank
+// lines and "$CATEGORY:" lines file the questions after them under a
+// subject and topic. Choice, true/false, numeric, matching, short answer,
+// missing word and essay questions are supported; descriptions are skipped.
+func parseGIFT(content string) ([]Draft, []Issue) {
+	var drafts []Draft
+	var issues []Issue
+	var subject, topic string
+
+	for _, b := range giftBlocks(content) {
+		if path, ok := strings.CutPrefix(b.text, "$CATEGORY:"); ok {
+			subject, topic = splitCategory(path)
+			continue
+		}
+
+		d, found := parseGIFTQuestion(b)
+		issues = append(issues, found...)
+		if d == nil {
+			continue
+		}
+		d.Subject, d.Topic = subject, topic
+		drafts = append(drafts, *d)
+	}
+
+	return drafts, issues
+}
+
+func giftBlocks(content string) []giftBlock {
+	var blocks []giftBlock
+	var current giftBlock
+	var lines []string
+
+	flush := func() {
+		if len(lines) > 0 {
+			current.text = strings.Join(lines, "\n")
+			blocks = append(blocks, current)
+		}
+		current, lines = giftBlock{}, nil
+	}
+
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "//"):
+		case strings.HasPrefix(line, "$CATEGORY:"):
+			flush()
+			current.lines = []int{i + 1}
+			lines = []string{line}
+			flush()
+		default:
+			current.lines = append(current.lines, i+1)
+			lines = append(lines, line)
+		}
+	}
+	flush()
+
+	return blocks
+}
+
+func parseGIFTQuestion(b giftBlock) (*Draft, []Issue) {
+	s := b.text
+	line := b.lines[0]
+	var issues []Issue
+
+	pos := 0
+	if strings.HasPrefix(s, "::") {
+		end := indexUnescaped(s, "::", 2)
+		if end < 0 {
+			return nil, []Issue{{Line: line, Severity: SeverityError, Message: "question title is not closed with ::"}}
+		}
+		pos = end + 2
+	}
+
+	open := indexUnescaped(s, "{", pos)
+	if open < 0 {
+		return nil, []Issue{{Line: line, Severity: SeverityWarning, Message: "text without an answer block is a description and was skipped"}}
+	}
+	end := indexUnescaped(s, "}", open+1)
+	if end < 0 {
+		return nil, []Issue{{Line: b.lineAt(open), Severity: SeverityError, Message: "answer block is not closed with }"}}
+	}
+	if extra := indexUnescaped(s, "{", end+1); extra >= 0 {
+		return nil, []Issue{{Line: b.lineAt(extra), Severity: SeverityError, Message: "questions with more than one answer block (cloze) are not supported"}}
+	}
+
+	stem := giftText(s[pos:open])
+	if after := giftText(s[end+1:]); after != "" {
+		stem = strings.TrimSpace(stem + " _____ " + after)
+	}
+	if stem == "" {
+		return nil, []Issue{{Line: line, Severity: SeverityError, Message: "question has no text"}}
+	}
+
+	d := &Draft{Line: line, Stem: stem, Options: encode([]option{}), Marks: 1}
+
+	body := s[open+1 : end]
+	if fb := indexUnescaped(body, "####", 0); fb >= 0 {
+		d.Explanation = giftText(body[fb+4:])
+		body = body[:fb]
+	}
+
+	at := func(offset int) int { return b.lineAt(open + 1 + offset) }
+	trimmed := strings.TrimSpace(body)
+
+	switch {
+	case trimmed == "":
+		d.Type = repo.QuestionTypeEssay
+		d.AnswerKey = encode(manualKey{})
+		return d, issues
+
+	case strings.HasPrefix(trimmed, "#"):
+		hash := strings.Index(body, "#")
+		key, found := giftNumeric(body[hash+1:], hash+1, at)
+		issues = append(issues, found...)
+		if key == nil {
+			return nil, issues
+		}
+		d.Type = repo.QuestionTypeNumeric
+		d.AnswerKey = encode(key)
+		return d, issues
+	}
+
+	if value, ok := giftBool(trimmed); ok {
+		if indexUnescaped(trimmed, "#", 0) >= 0 {
+			issues = append(issues, Issue{Line: line, Severity: SeverityWarning, Message: "answer feedback is not imported"})
+		}
+		d.Type = repo.QuestionTypeTrueFalse
+		d.AnswerKey = encode(value)
+		return d, issues
+	}
+
+	answers, err := giftAnswers(body)
+	if err != nil {
+		return nil, append(issues, Issue{Line: line, Severity: SeverityError, Message: err.Error()})
+	}
+
+	for _, a := range answers {
+		if a.feedback {
+			issues = append(issues, Issue{Line: at(a.offset), Severity: SeverityWarning, Message: "answer feedback is not imported"})
+		}
+	}
+
+	var wrong, arrows int
+	for _, a := range answers {
+		if !a.correct {
+			wrong++
+		}
+		if indexUnescaped(a.text, "->", 0) >= 0 {
+			arrows++
+		}
+	}
+
+	switch {
+	case arrows > 0:
+		if arrows != len(answers) || wrong > 0 {
+			return nil, append(issues, Issue{Line: line, Severity: SeverityError, Message: "every matching pair must look like =prompt -> answer"})
+		}
+		opts, key, found := giftMatching(answers, at)
+		issues = append(issues, found...)
+		if key == nil {
+			return nil, issues
+		}
+		d.Type = repo.QuestionTypeMatching
+		d.Options = encode(opts)
+		d.AnswerKey = encode(key)
+
+	case wrong == 0:
+		var accepted []string
+		for _, a := range answers {
+			if a.credit() < 100 {
+				issues = append(issues, Issue{Line: at(a.offset), Severity: SeverityWarning, Message: "partial credit answers are accepted in full; marking is manual"})
+			}
+			accepted = append(accepted, giftText(a.text))
+		}
+		d.Type = repo.QuestionTypeShortAnswer
+		d.AnswerKey = encode(manualKey{Accepted: accepted})
+
+	default:
+		typ, opts, key, found := giftChoice(answers, line)
+		issues = append(issues, found...)
+		if key == nil {
+			return nil, issues
+		}
+		d.Type = typ
+		d.Options = encode(opts)
+		d.AnswerKey = encode(key)
+	}
+
+	return d, issues
+}
+
+func giftChoice(answers []giftAnswer, line int) (repo.QuestionType, []option, any, []Issue) {
+	var issues []Issue
+
+	if len(answers) > maxChoices {
+		return "", nil, nil, []Issue{{Line: line, Severity: SeverityError, Message: fmt.Sprintf("choice questions can have at most %d options", maxChoices)}}
+	}
+
+	opts := make([]option, 0, len(answers))
+	var right []string
+	partial, penalties := false, false
+	for i, a := range answers {
+		opts = append(opts, option{Label: choiceLabel(i), Text: giftText(a.text)})
+		switch c := a.credit(); {
+		case c > 0:
+			right = append(right, choiceLabel(i))
+			if c < 100 {
+				partial = true
+			}
+		case c < 0:
+			penalties = true
+		}
+	}
+
+	switch len(right) {
+	case 0:
+		return "", nil, nil, []Issue{{Line: line, Severity: SeverityError, Message: "choice question has no correct answer"}}
+	case 1:
+		if partial {
+			issues = append(issues, Issue{Line: line, Severity: SeverityWarning, Message: "the only correct answer is worth full marks instead of its partial credit"})
+		}
+		if penalties {
+			issues = append(issues, Issue{Line: line, Severity: SeverityWarning, Message: "negative weights on wrong answers are not imported"})
+		}
+		return repo.QuestionTypeSingleChoice, opts, right[0], issues
+	}
+
+	if partial || penalties {
+		issues = append(issues, Issue{Line: line, Severity: SeverityWarning, Message: "answer weights are replaced by multiple choice scoring, where each wrong pick cancels a right one"})
+	}
+	return repo.QuestionTypeMultipleChoice, opts, right, issues
+}
+
+func giftMatching(answers []giftAnswer, at func(int) int) (matchingOptions, map[string]string, []Issue) {
+	var issues []Issue
+	var pairs [][2]string
+	for _, a := range answers {
+		arrow := indexUnescaped(a.text, "->", 0)
+		prompt, choice := giftText(a.text[:arrow]), giftText(a.text[arrow+2:])
+		if choice == "" {
+			issues = append(issues, Issue{Line: at(a.offset), Severity: SeverityError, Message: "matching pair has no answer"})
+			continue
+		}
+		pairs = append(pairs, [2]string{prompt, choice})
+	}
+	if len(issues) > 0 {
+		return matchingOptions{}, nil, issues
+	}
+
+	opts, key, err := pairUp(pairs)
+	if err != nil {
+		return opts, nil, []Issue{{Line: at(0), Severity: SeverityError, Message: err.Error()}}
+	}
+	return opts, key, nil
+}
+
+// giftNumeric reads the body of a {#...} block, which is one answer or a
+// list of "=" answers with weights. offset is where body starts in the
+// answer block.
+func giftNumeric(body string, offset int, at func(int) int) (*numericKey, []Issue) {
+	if !strings.HasPrefix(strings.TrimSpace(body), "=") {
+		spec := body
+		if fb := indexUnescaped(spec, "#", 0); fb >= 0 {
+			spec = spec[:fb]
+		}
+		key, err := numericSpec(spec)
+		if err != nil {
+			return nil, []Issue{{Line: at(offset), Severity: SeverityError, Message: err.Error()}}
+		}
+		return &key, nil
+	}
+
+	answers, err := giftAnswers(body)
+	if err != nil {
+		return nil, []Issue{{Line: at(offset), Severity: SeverityError, Message: err.Error()}}
+	}
+
+	var issues []Issue
+	var key *numericKey
+	for _, a := range answers {
+		if key != nil || a.credit() < 100 {
+			issues = append(issues, Issue{Line: at(offset + a.offset), Severity: SeverityWarning, Message: "only the first full-credit numeric answer is imported"})
+			continue
+		}
+		k, err := numericSpec(a.text)
+		if err != nil {
+			return nil, append(issues, Issue{Line: at(offset + a.offset), Severity: SeverityError, Message: err.Error()})
+		}
+		key = &k
+	}
+
+	if key == nil {
+		return nil, append(issues, Issue{Line: at(offset), Severity: SeverityError, Message: "numeric question has no full-credit answer"})
+	}
+	return key, issues
+}
+
+// numericSpec reads "3.14", "3.14:0.01" or the range "1..5".
+func numericSpec(spec string) (numericKey, error) {
+	spec = strings.TrimSpace(spec)
+
+	if from, to, ok := strings.Cut(spec, ".."); ok {
+		low, err1 := strconv.ParseFloat(strings.TrimSpace(from), 64)
+		high, err2 := strconv.ParseFloat(strings.TrimSpace(to), 64)
+		if err1 != nil || err2 != nil || low > high {
+			return numericKey{}, fmt.Errorf("%q is not a numeric range", spec)
+		}
+		return numericKey{Value: (low + high) / 2, Tolerance: (high - low) / 2}, nil
+	}
+
+	value, tolerance, _ := strings.Cut(spec, ":")
+	key := numericKey{}
+	var err error
+	if key.Value, err = strconv.ParseFloat(strings.TrimSpace(value), 64); err != nil {
+		return numericKey{}, fmt.Errorf("%q is not a number", value)
+	}
+	if tolerance != "" {
+		if key.Tolerance, err = strconv.ParseFloat(strings.TrimSpace(tolerance), 64); err != nil || key.Tolerance < 0 {
+			return numericKey{}, fmt.Errorf("%q is not a valid tolerance", tolerance)
+		}
+	}
+	return key, nil
+}
+
+func giftBool(body string) (bool, bool) {
+	word := body
+	if fb := indexUnescaped(word, "#", 0); fb >= 0 {
+		word = word[:fb]
+	}
+	switch strings.ToUpper(strings.TrimSpace(word)) {
+	case "T", "TRUE":
+		return true, true
+	case "F", "FALSE":
+		return false, true
+	}
+	return false, false
+}
+
+// giftAnswers splits an answer block on unescaped "=" and "~".
+func giftAnswers(body string) ([]giftAnswer, error) {
+	var answers []giftAnswer
+	start := -1
+
+	add := func(end int) error {
+		if start < 0 {
+			if strings.TrimSpace(body[:end]) != "" {
+				return errors.New("answers must start with = or ~")
+			}
+			return nil
+		}
+
+		a := giftAnswer{offset: start, correct: body[start] == '='}
+		text := body[start+1 : end]
+
+		if strings.HasPrefix(text, "%") {
+			weight, rest, ok := strings.Cut(text[1:], "%")
+			if !ok {
+				return errors.New("answer weight is not closed with %")
+			}
+			w, err := strconv.ParseFloat(weight, 64)
+			if err != nil {
+				return fmt.Errorf("%q is not a valid answer weight", weight)
+			}
+			a.weight = &w
+			text = rest
+		}
+
+		if fb := indexUnescaped(text, "#", 0); fb >= 0 {
+			a.feedback = strings.TrimSpace(text[fb+1:]) != ""
+			text = text[:fb]
+		}
+
+		a.text = text
+		answers = append(answers, a)
+		return nil
+	}
+
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '\\':
+			i++
+		case '=', '~':
+			if err := add(i); err != nil {
+				return nil, err
+			}
+			start = i
+		}
+	}
+	if err := add(len(body)); err != nil {
+		return nil, err
+	}
+
+	if len(answers) == 0 {
+		return nil, errors.New("answers must start with = or ~")
+	}
+	return answers, nil
+}
+
+// indexUnescaped finds sub in s from the given offset, skipping anything
+// escaped with a backslash.
+func indexUnescaped(s, sub string, from int) int {
+	for i := from; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(s[i:], sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+// giftText trims a piece of GIFT text, drops its format marker and undoes
+// escapes.
+func giftText(s string) string {
+	s = giftFormat.ReplaceAllString(strings.TrimSpace(s), "")
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' {
+				b.WriteByte('\n')
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return strings.TrimSpace(b.String())
+}
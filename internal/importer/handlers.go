@@ -0,0 +1,386 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/config"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/helpers"
+	"github.com/odundlaw/cbt-backend/internal/json"
+	"github.com/odundlaw/cbt-backend/internal/middlewares"
+	"github.com/odundlaw/cbt-backend/internal/validation"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service,
+	}
+}
+
+// Preview is a dry run of Import: it reports what would be created, which
+// questions are duplicates and what could not be carried over, line by line.
+func (h *Handler) Preview(w http.ResponseWriter, r *http.Request) {
+	var req importParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	preview, err := h.service.Preview(r.Context(), req)
+	if err != nil {
+		writeImportError(w, err, nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, preview, nil)
+}
+
+func (h *Handler) Import(w http.ResponseWriter, r *http.Request) {
+	var req importParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	adminID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	res, err := h.service.Import(r.Context(), adminID, req)
+	if err != nil {
+		writeImportError(w, err, res.Issues)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusCreated, constants.MsgQuestionsImported, res, nil)
+}
+
+// PreviewPackage is Preview for a QTI package uploaded as the raw request
+// body. ?subject=, ?topic= and ?include_duplicates= take the place of the
+// JSON fields.
+func (h *Handler) PreviewPackage(w http.ResponseWriter, r *http.Request) {
+	data, opts, ok := readPackageRequest(w, r)
+	if !ok {
+		return
+	}
+
+	preview, err := h.service.PreviewPackage(r.Context(), data, opts)
+	if err != nil {
+		writeImportError(w, err, nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, preview, nil)
+}
+
+func (h *Handler) ImportPackage(w http.ResponseWriter, r *http.Request) {
+	data, opts, ok := readPackageRequest(w, r)
+	if !ok {
+		return
+	}
+
+	adminID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	res, err := h.service.ImportPackage(r.Context(), adminID, data, opts)
+	if err != nil {
+		writeImportError(w, err, res.Issues)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusCreated, constants.MsgQuestionsImported, res, nil)
+}
+
+func readPackageRequest(w http.ResponseWriter, r *http.Request) ([]byte, importOptions, bool) {
+	opts, ok := readImportOptions(w, r.URL.Query())
+	if !ok {
+		return nil, opts, false
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(config.ImportMaxMB)<<20))
+	if err != nil {
+		writeUploadError(w, err)
+		return nil, opts, false
+	}
+
+	return data, opts, true
+}
+
+// readImportOptions reads subject, topic and include_duplicates from a query
+// string or form.
+func readImportOptions(w http.ResponseWriter, values url.Values) (importOptions, bool) {
+	opts := importOptions{Subject: values.Get("subject"), Topic: values.Get("topic")}
+
+	if v := values.Get("include_duplicates"); v != "" {
+		include, err := strconv.ParseBool(v)
+		if err != nil {
+			json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+			return opts, false
+		}
+		opts.IncludeDuplicates = include
+	}
+
+	if err := validation.Validate.Struct(opts); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return opts, false
+	}
+
+	return opts, true
+}
+
+func writeUploadError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		json.JSONError(w, http.StatusRequestEntityTooLarge, constants.ErrPackageTooLarge, nil)
+		return
+	}
+	json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+}
+
+// Template downloads a blank question sheet. ?type= picks the question
+// type and ?format= csv or xlsx, the default.
+func (h *Handler) Template(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	params := templateParams{Type: repo.QuestionType(query.Get("type")), Format: Format(query.Get("format"))}
+	if params.Format == "" {
+		params.Format = FormatXLSX
+	}
+
+	if err := validation.Validate.Struct(params); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	data, err := h.service.Template(params)
+	if err != nil {
+		writeImportError(w, err, nil)
+		return
+	}
+
+	contentType := "text/csv"
+	if params.Format == FormatXLSX {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-questions.%s"`, params.Type, params.Format))
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := w.Write(data); err != nil {
+		fmt.Println("failed to write question template:", err)
+	}
+}
+
+// PreviewSheet checks an uploaded question sheet row by row without writing
+// anything. The multipart form carries the sheet as file, an optional zip of
+// images as media, and type, subject, topic and include_duplicates.
+func (h *Handler) PreviewSheet(w http.ResponseWriter, r *http.Request) {
+	upload, opts, ok := readSheetRequest(w, r)
+	if !ok {
+		return
+	}
+
+	preview, err := h.service.PreviewSheet(r.Context(), upload, opts)
+	if err != nil {
+		writeImportError(w, err, nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, preview, nil)
+}
+
+func (h *Handler) ImportSheet(w http.ResponseWriter, r *http.Request) {
+	upload, opts, ok := readSheetRequest(w, r)
+	if !ok {
+		return
+	}
+
+	adminID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	res, err := h.service.ImportSheet(r.Context(), adminID, upload, opts)
+	if err != nil {
+		writeImportError(w, err, res.Issues)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusCreated, constants.MsgQuestionsImported, res, nil)
+}
+
+// maxSheetMemory is how much of a sheet upload is held in memory before
+// the rest spills to temporary files.
+const maxSheetMemory = 32 << 20
+
+func readSheetRequest(w http.ResponseWriter, r *http.Request) (sheetUpload, importOptions, bool) {
+	var upload sheetUpload
+
+	r.Body = http.MaxBytesReader(w, r.Body, int64(config.ImportMaxMB)<<20)
+	if err := r.ParseMultipartForm(maxSheetMemory); err != nil {
+		writeUploadError(w, err)
+		return upload, importOptions{}, false
+	}
+
+	opts, ok := readImportOptions(w, r.Form)
+	if !ok {
+		return upload, opts, false
+	}
+
+	upload.Type = repo.QuestionType(r.FormValue("type"))
+	if err := validation.Validate.Struct(upload); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return upload, opts, false
+	}
+
+	var err error
+	upload.Sheet, err = formFile(r, "file")
+	if err == nil && len(upload.Sheet) == 0 {
+		err = http.ErrMissingFile
+	}
+	if errors.Is(err, http.ErrMissingFile) {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrSpreadsheetMissing, nil)
+		return upload, opts, false
+	}
+	if err == nil {
+		upload.Media, err = formFile(r, "media")
+		if errors.Is(err, http.ErrMissingFile) {
+			err = nil
+		}
+	}
+	if err != nil {
+		writeUploadError(w, err)
+		return upload, opts, false
+	}
+
+	return upload, opts, true
+}
+
+func formFile(r *http.Request, field string) ([]byte, error) {
+	f, _, err := r.FormFile(field)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// ExportQuestions downloads bank questions as a QTI package. ?version=
+// picks QTI 2.1 (the default) or 3.0, and ?subject= and ?topic= narrow the
+// export.
+func (h *Handler) ExportQuestions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := ExportFilter{Subject: query.Get("subject"), Topic: query.Get("topic")}
+
+	res, err := h.service.ExportQuestions(r.Context(), filter, query.Get("version"))
+	if err != nil {
+		writeImportError(w, err, nil)
+		return
+	}
+
+	writePackage(w, "questions-qti.zip", res)
+}
+
+// ExportExam downloads an exam as a QTI package with an assessment test.
+func (h *Handler) ExportExam(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	res, err := h.service.ExportExam(r.Context(), examID, r.URL.Query().Get("version"))
+	if err != nil {
+		writeImportError(w, err, nil)
+		return
+	}
+
+	writePackage(w, fmt.Sprintf("exam-%d-qti.zip", examID), res)
+}
+
+// writePackage sends an export. Questions that could not be written are
+// listed by ID in X-Skipped-Questions.
+func writePackage(w http.ResponseWriter, name string, res exportResponse) {
+	ids := make([]int64, 0, len(res.Skipped))
+	for id := range res.Skipped {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	skipped := make([]string, 0, len(ids))
+	for _, id := range ids {
+		skipped = append(skipped, strconv.FormatInt(id, 10))
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, name))
+	if len(skipped) > 0 {
+		w.Header().Set("X-Skipped-Questions", strings.Join(skipped, ","))
+	}
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := w.Write(res.Package); err != nil {
+		fmt.Println("failed to write qti package:", err)
+	}
+}
+
+func writeImportError(w http.ResponseWriter, err error, issues []Issue) {
+	switch {
+	case errors.Is(err, ErrUnknownFormat):
+		json.JSONError(w, http.StatusBadRequest, constants.ErrUnknownImportFormat, nil)
+	case errors.Is(err, ErrImportHasErrors):
+		var errs []json.FieldError
+		for _, issue := range issues {
+			if issue.Severity == SeverityError {
+				field := fmt.Sprintf("line %d", issue.Line)
+				if issue.File != "" {
+					field = issue.File + " " + field
+				}
+				errs = append(errs, json.FieldError{Field: field, Message: issue.Message})
+			}
+		}
+		json.JSONError(w, http.StatusUnprocessableEntity, constants.ErrImportHasErrors, errs)
+	case errors.Is(err, ErrNothingToImport):
+		json.JSONError(w, http.StatusConflict, constants.ErrNothingToImport, nil)
+	case errors.Is(err, ErrInvalidPackage), errors.Is(err, ErrUnknownQTIVersion), errors.Is(err, ErrInvalidSheet), errors.Is(err, ErrInvalidMedia):
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+	case errors.Is(err, ErrExamNotFound):
+		json.JSONError(w, http.StatusNotFound, constants.ErrExamNotFound, nil)
+	default:
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+	}
+}
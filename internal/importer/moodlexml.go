@@ -0,0 +1,311 @@
+package importer
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+type moodleText struct {
+	Format string       `xml:"format,attr"`
+	Text   string       `xml:"text"`
+	Files  []moodleFile `xml:"file"`
+}
+
+type moodleFile struct {
+	Name string `xml:"name,attr"`
+}
+
+type moodleAnswer struct {
+	Fraction  string     `xml:"fraction,attr"`
+	Text      string     `xml:"text"`
+	Tolerance string     `xml:"tolerance"`
+	Feedback  moodleText `xml:"feedback"`
+}
+
+type moodleSubquestion struct {
+	Text   string     `xml:"text"`
+	Answer moodleText `xml:"answer"`
+}
+
+type moodleQuestion struct {
+	Type            string              `xml:"type,attr"`
+	Category        moodleText          `xml:"category"`
+	QuestionText    moodleText          `xml:"questiontext"`
+	GeneralFeedback moodleText          `xml:"generalfeedback"`
+	GraderInfo      moodleText          `xml:"graderinfo"`
+	DefaultGrade    string              `xml:"defaultgrade"`
+	Single          string              `xml:"single"`
+	Answers         []moodleAnswer      `xml:"answer"`
+	Subquestions    []moodleSubquestion `xml:"subquestion"`
+	Units           []struct{}          `xml:"units>unit"`
+}
+
+// parseMoodleXML reads a Moodle XML export. Category entries file the
+// questions after them under a subject and topic. Question text is kept as
+// Moodle stored it, HTML included.
+func parseMoodleXML(content string) ([]Draft, []Issue) {
+	var drafts []Draft
+	var issues []Issue
+	var subject, topic string
+
+	dec := xml.NewDecoder(strings.NewReader(content))
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			line, _ := dec.InputPos()
+			return drafts, append(issues, Issue{Line: line, Severity: SeverityError, Message: fmt.Sprintf("invalid XML: %v", err)})
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "question" {
+			continue
+		}
+
+		line, _ := dec.InputPos()
+		var q moodleQuestion
+		if err := dec.DecodeElement(&q, &start); err != nil {
+			return drafts, append(issues, Issue{Line: line, Severity: SeverityError, Message: fmt.Sprintf("invalid XML: %v", err)})
+		}
+
+		if q.Type == "category" {
+			subject, topic = splitCategory(q.Category.Text)
+			continue
+		}
+
+		d, found := moodleDraft(line, q)
+		issues = append(issues, found...)
+		if d == nil {
+			continue
+		}
+		d.Subject, d.Topic = subject, topic
+		drafts = append(drafts, *d)
+	}
+
+	return drafts, issues
+}
+
+func moodleDraft(line int, q moodleQuestion) (*Draft, []Issue) {
+	var issues []Issue
+
+	switch q.Type {
+	case "multichoice", "truefalse", "shortanswer", "numerical", "matching", "essay":
+	case "description":
+		return nil, []Issue{{Line: line, Severity: SeverityWarning, Message: "description items are not questions and were skipped"}}
+	default:
+		return nil, []Issue{{Line: line, Severity: SeverityError, Message: fmt.Sprintf("question type %q is not supported", q.Type)}}
+	}
+
+	d := &Draft{
+		Line:        line,
+		Stem:        strings.TrimSpace(q.QuestionText.Text),
+		Options:     encode([]option{}),
+		Explanation: strings.TrimSpace(q.GeneralFeedback.Text),
+		Marks:       1,
+	}
+	if d.Stem == "" {
+		return nil, []Issue{{Line: line, Severity: SeverityError, Message: "question has no text"}}
+	}
+
+	if q.DefaultGrade != "" {
+		grade, err := strconv.ParseFloat(strings.TrimSpace(q.DefaultGrade), 64)
+		switch {
+		case err != nil:
+			issues = append(issues, Issue{Line: line, Severity: SeverityWarning, Message: fmt.Sprintf("default grade %q is not a number, using 1 mark", q.DefaultGrade)})
+		case grade > 0:
+			d.Marks = grade
+		}
+	}
+
+	if len(q.QuestionText.Files) > 0 || len(q.GeneralFeedback.Files) > 0 {
+		issues = append(issues, Issue{Line: line, Severity: SeverityWarning, Message: "embedded files are not imported"})
+	}
+	for _, a := range q.Answers {
+		if strings.TrimSpace(a.Feedback.Text) != "" {
+			issues = append(issues, Issue{Line: line, Severity: SeverityWarning, Message: "answer feedback is not imported"})
+			break
+		}
+	}
+
+	var found []Issue
+	switch q.Type {
+	case "multichoice":
+		found = moodleChoice(line, q, d)
+	case "truefalse":
+		found = moodleTrueFalse(line, q, d)
+	case "shortanswer":
+		found = moodleShortAnswer(line, q, d)
+	case "numerical":
+		found = moodleNumeric(line, q, d)
+	case "matching":
+		found = moodleMatching(line, q, d)
+	case "essay":
+		d.Type = repo.QuestionTypeEssay
+		d.AnswerKey = encode(manualKey{Guidance: strings.TrimSpace(q.GraderInfo.Text)})
+	}
+
+	issues = append(issues, found...)
+	if d.AnswerKey == nil {
+		return nil, issues
+	}
+	return d, issues
+}
+
+// fraction reads an answer's credit in percent. A missing fraction is no
+// credit.
+func (a moodleAnswer) fraction() float64 {
+	f, err := strconv.ParseFloat(strings.TrimSpace(a.Fraction), 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
+
+func moodleChoice(line int, q moodleQuestion, d *Draft) []Issue {
+	if len(q.Answers) > maxChoices {
+		return []Issue{{Line: line, Severity: SeverityError, Message: fmt.Sprintf("choice questions can have at most %d options", maxChoices)}}
+	}
+
+	var issues []Issue
+	opts := make([]option, 0, len(q.Answers))
+	var right []string
+	partial, penalties := false, false
+	for i, a := range q.Answers {
+		opts = append(opts, option{Label: choiceLabel(i), Text: strings.TrimSpace(a.Text)})
+		switch f := a.fraction(); {
+		case f > 0:
+			right = append(right, choiceLabel(i))
+			if f < 100 && q.Single != "false" {
+				partial = true
+			}
+		case f < 0:
+			penalties = true
+		}
+	}
+
+	if len(right) == 0 {
+		return []Issue{{Line: line, Severity: SeverityError, Message: "choice question has no correct answer"}}
+	}
+	if partial {
+		issues = append(issues, Issue{Line: line, Severity: SeverityWarning, Message: "partial credit answers are imported as wrong"})
+	}
+	if penalties {
+		issues = append(issues, Issue{Line: line, Severity: SeverityWarning, Message: "negative fractions on wrong answers are not imported"})
+	}
+
+	d.Options = encode(opts)
+
+	// Moodle writes <single>true</single> for one-answer questions; anything
+	// else allows several.
+	if q.Single != "false" {
+		best := 0
+		for i, a := range q.Answers {
+			if a.fraction() > q.Answers[best].fraction() {
+				best = i
+			}
+		}
+		d.Type = repo.QuestionTypeSingleChoice
+		d.AnswerKey = encode(choiceLabel(best))
+		return issues
+	}
+
+	d.Type = repo.QuestionTypeMultipleChoice
+	d.AnswerKey = encode(right)
+	return issues
+}
+
+func moodleTrueFalse(line int, q moodleQuestion, d *Draft) []Issue {
+	for _, a := range q.Answers {
+		if a.fraction() < 100 {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(a.Text)) {
+		case "true":
+			d.AnswerKey = encode(true)
+		case "false":
+			d.AnswerKey = encode(false)
+		default:
+			continue
+		}
+		d.Type = repo.QuestionTypeTrueFalse
+		return nil
+	}
+	return []Issue{{Line: line, Severity: SeverityError, Message: "true/false question has no correct answer"}}
+}
+
+func moodleShortAnswer(line int, q moodleQuestion, d *Draft) []Issue {
+	var issues []Issue
+	var accepted []string
+	for _, a := range q.Answers {
+		f := a.fraction()
+		if f <= 0 {
+			continue
+		}
+		if f < 100 {
+			issues = append(issues, Issue{Line: line, Severity: SeverityWarning, Message: "partial credit answers are accepted in full; marking is manual"})
+		}
+		accepted = append(accepted, strings.TrimSpace(a.Text))
+	}
+
+	d.Type = repo.QuestionTypeShortAnswer
+	d.AnswerKey = encode(manualKey{Accepted: accepted})
+	return issues
+}
+
+func moodleNumeric(line int, q moodleQuestion, d *Draft) []Issue {
+	var issues []Issue
+	if len(q.Units) > 0 {
+		issues = append(issues, Issue{Line: line, Severity: SeverityWarning, Message: "units are not imported; answers are plain numbers"})
+	}
+
+	for _, a := range q.Answers {
+		if a.fraction() < 100 {
+			continue
+		}
+		key, err := numericSpec(a.Text)
+		if err != nil {
+			return append(issues, Issue{Line: line, Severity: SeverityError, Message: err.Error()})
+		}
+		if a.Tolerance != "" {
+			key.Tolerance, err = strconv.ParseFloat(strings.TrimSpace(a.Tolerance), 64)
+			if err != nil || key.Tolerance < 0 {
+				return append(issues, Issue{Line: line, Severity: SeverityError, Message: fmt.Sprintf("%q is not a valid tolerance", a.Tolerance)})
+			}
+		}
+		if len(q.Answers) > 1 {
+			issues = append(issues, Issue{Line: line, Severity: SeverityWarning, Message: "only the first full-credit numeric answer is imported"})
+		}
+		d.Type = repo.QuestionTypeNumeric
+		d.AnswerKey = encode(key)
+		return issues
+	}
+
+	return append(issues, Issue{Line: line, Severity: SeverityError, Message: "numeric question has no full-credit answer"})
+}
+
+func moodleMatching(line int, q moodleQuestion, d *Draft) []Issue {
+	var pairs [][2]string
+	for _, sub := range q.Subquestions {
+		if choice := strings.TrimSpace(sub.Answer.Text); choice != "" {
+			pairs = append(pairs, [2]string{strings.TrimSpace(sub.Text), choice})
+		}
+	}
+
+	opts, key, err := pairUp(pairs)
+	if err != nil {
+		return []Issue{{Line: line, Severity: SeverityError, Message: err.Error()}}
+	}
+
+	d.Type = repo.QuestionTypeMatching
+	d.Options = encode(opts)
+	d.AnswerKey = encode(key)
+	return nil
+}
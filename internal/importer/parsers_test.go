@@ -0,0 +1,188 @@
+package importer
+
+import (
+	"testing"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+// wantDraft is the part of a draft the parsers are responsible for. Options
+// and keys are compared as the JSON the parser wrote.
+type wantDraft struct {
+	Line    int
+	Type    repo.QuestionType
+	Stem    string
+	Options string
+	Key     string
+	Marks   float64
+}
+
+// wantIssue leaves out the message, which is free to be reworded.
+type wantIssue struct {
+	Line     int
+	Severity string
+}
+
+func checkParsed(t *testing.T, drafts []Draft, issues []Issue, wantDrafts []wantDraft, wantIssues []wantIssue) {
+	t.Helper()
+
+	if len(drafts) != len(wantDrafts) {
+		t.Fatalf("got %d drafts; want %d: %+v", len(drafts), len(wantDrafts), drafts)
+	}
+	for i, d := range drafts {
+		got := wantDraft{d.Line, d.Type, d.Stem, string(d.Options), string(d.AnswerKey), d.Marks}
+		if got != wantDrafts[i] {
+			t.Errorf("draft %d = %+v; want %+v", i, got, wantDrafts[i])
+		}
+	}
+
+	if len(issues) != len(wantIssues) {
+		t.Fatalf("got %d issues; want %d: %+v", len(issues), len(wantIssues), issues)
+	}
+	for i, is := range issues {
+		if got := (wantIssue{is.Line, is.Severity}); got != wantIssues[i] {
+			t.Errorf("issue %d = %+v (%s); want %+v", i, got, is.Message, wantIssues[i])
+		}
+	}
+}
+
+func TestParsers(t *testing.T) {
+	tests := []struct {
+		name       string
+		format     Format
+		content    string
+		wantDrafts []wantDraft
+		wantIssues []wantIssue
+	}{
+		{
+			name:    "aiken single choice",
+			format:  FormatAiken,
+			content: "What is 2+2?\nA. 3\nB) 4\nC. 5\nANSWER: B\n",
+			wantDrafts: []wantDraft{
+				{1, repo.QuestionTypeSingleChoice, "What is 2+2?", `[{"label":"A","text":"3"},{"label":"B","text":"4"},{"label":"C","text":"5"}]`, `"B"`, 1},
+			},
+		},
+		{
+			name:       "aiken answer not among the options",
+			format:     FormatAiken,
+			content:    "Capital of Nigeria?\nA. Lagos\nB. Abuja\nANSWER: C\n",
+			wantIssues: []wantIssue{{4, SeverityError}},
+		},
+		{
+			name:       "aiken without options",
+			format:     FormatAiken,
+			content:    "No options here\nANSWER: A\n",
+			wantIssues: []wantIssue{{1, SeverityError}},
+		},
+		{
+			name:    "gift single choice",
+			format:  FormatGIFT,
+			content: "// comment\n::Q1:: 2+2 is {=4 ~3 ~5}\n",
+			wantDrafts: []wantDraft{
+				{2, repo.QuestionTypeSingleChoice, "2+2 is", `[{"label":"A","text":"4"},{"label":"B","text":"3"},{"label":"C","text":"5"}]`, `"A"`, 1},
+			},
+		},
+		{
+			name:    "gift true false",
+			format:  FormatGIFT,
+			content: "The sun rises in the east. {T}\n",
+			wantDrafts: []wantDraft{
+				{1, repo.QuestionTypeTrueFalse, "The sun rises in the east.", `[]`, `true`, 1},
+			},
+		},
+		{
+			name:    "gift numeric with tolerance",
+			format:  FormatGIFT,
+			content: "::Num:: What is pi? {#3.14:0.01}\n",
+			wantDrafts: []wantDraft{
+				{1, repo.QuestionTypeNumeric, "What is pi?", `[]`, `{"value":3.14,"tolerance":0.01}`, 1},
+			},
+		},
+		{
+			name:    "gift matching",
+			format:  FormatGIFT,
+			content: "Match {=Nigeria -> Abuja =Ghana -> Accra}\n",
+			wantDrafts: []wantDraft{
+				{1, repo.QuestionTypeMatching, "Match",
+					`{"prompts":[{"label":"1","text":"Nigeria"},{"label":"2","text":"Ghana"}],"choices":[{"label":"a","text":"Abuja"},{"label":"b","text":"Accra"}]}`,
+					`{"1":"a","2":"b"}`, 1},
+			},
+		},
+		{
+			name:    "gift short answer",
+			format:  FormatGIFT,
+			content: "Who wrote Hamlet? {=Shakespeare =William Shakespeare}\n",
+			wantDrafts: []wantDraft{
+				{1, repo.QuestionTypeShortAnswer, "Who wrote Hamlet?", `[]`, `{"accepted":["Shakespeare","William Shakespeare"]}`, 1},
+			},
+		},
+		{
+			name:    "gift weighted answers become multiple choice",
+			format:  FormatGIFT,
+			content: "Pick two {~%50%A ~%50%B ~%-100%C}\n",
+			wantDrafts: []wantDraft{
+				{1, repo.QuestionTypeMultipleChoice, "Pick two", `[{"label":"A","text":"A"},{"label":"B","text":"B"},{"label":"C","text":"C"}]`, `["A","B"]`, 1},
+			},
+			wantIssues: []wantIssue{{1, SeverityWarning}},
+		},
+		{
+			name:       "gift unclosed answer block",
+			format:     FormatGIFT,
+			content:    "::Q1:: 2+2 is {=4 ~3}\n\nBroken {=a ~b\n",
+			wantDrafts: []wantDraft{{1, repo.QuestionTypeSingleChoice, "2+2 is", `[{"label":"A","text":"4"},{"label":"B","text":"3"}]`, `"A"`, 1}},
+			wantIssues: []wantIssue{{3, SeverityError}},
+		},
+		{
+			name:   "moodle multichoice with grade and feedback",
+			format: FormatMoodleXML,
+			content: `<quiz>
+<question type="multichoice"><questiontext format="html"><text><![CDATA[<p>2+2?</p>]]></text></questiontext><defaultgrade>2</defaultgrade><single>true</single>
+<answer fraction="100"><text>4</text><feedback><text>yes</text></feedback></answer><answer fraction="0"><text>5</text></answer></question>
+</quiz>`,
+			wantDrafts: []wantDraft{
+				{2, repo.QuestionTypeSingleChoice, "<p>2+2?</p>", `[{"label":"A","text":"4"},{"label":"B","text":"5"}]`, `"A"`, 2},
+			},
+			wantIssues: []wantIssue{{2, SeverityWarning}},
+		},
+		{
+			name:   "moodle truefalse numerical and shortanswer",
+			format: FormatMoodleXML,
+			content: `<quiz>
+<question type="category"><category><text>$course$/Maths</text></category></question>
+<question type="truefalse"><questiontext><text>Sky is blue</text></questiontext><answer fraction="100"><text>true</text></answer><answer fraction="0"><text>false</text></answer></question>
+<question type="numerical"><questiontext><text>pi?</text></questiontext><answer fraction="100"><text>3.14</text><tolerance>0.01</tolerance></answer></question>
+<question type="shortanswer"><questiontext><text>Capital?</text></questiontext><answer fraction="100"><text>Abuja</text></answer></question>
+</quiz>`,
+			wantDrafts: []wantDraft{
+				{3, repo.QuestionTypeTrueFalse, "Sky is blue", `[]`, `true`, 1},
+				{4, repo.QuestionTypeNumeric, "pi?", `[]`, `{"value":3.14,"tolerance":0.01}`, 1},
+				{5, repo.QuestionTypeShortAnswer, "Capital?", `[]`, `{"accepted":["Abuja"]}`, 1},
+			},
+		},
+		{
+			name:   "moodle matching",
+			format: FormatMoodleXML,
+			content: `<quiz>
+<question type="matching"><questiontext><text>Match</text></questiontext><subquestion><text>Nigeria</text><answer><text>Abuja</text></answer></subquestion><subquestion><text>Ghana</text><answer><text>Accra</text></answer></subquestion></question>
+</quiz>`,
+			wantDrafts: []wantDraft{
+				{2, repo.QuestionTypeMatching, "Match",
+					`{"prompts":[{"label":"1","text":"Nigeria"},{"label":"2","text":"Ghana"}],"choices":[{"label":"a","text":"Abuja"},{"label":"b","text":"Accra"}]}`,
+					`{"1":"a","2":"b"}`, 1},
+			},
+		},
+		{
+			name:       "moodle unsupported type",
+			format:     FormatMoodleXML,
+			content:    "<quiz>\n<question type=\"calculated\"><questiontext><text>x</text></questiontext></question>\n</quiz>",
+			wantIssues: []wantIssue{{2, SeverityError}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drafts, issues := parsers[tt.format](tt.content)
+			checkParsed(t, drafts, issues, tt.wantDrafts, tt.wantIssues)
+		})
+	}
+}
@@ -0,0 +1,574 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/media"
+	"github.com/odundlaw/cbt-backend/internal/storage"
+)
+
+// exportLimit caps how many bank questions one export writes.
+const exportLimit = 5000
+
+// qtiVersion holds what differs between the QTI versions we write.
+type qtiVersion struct {
+	v3            bool
+	itemNS        string
+	manifestNS    string
+	itemType      string
+	testType      string
+	schema        string
+	schemaVersion string
+}
+
+var qtiVersions = map[string]qtiVersion{
+	"2.1": {
+		itemNS:        "http://www.imsglobal.org/xsd/imsqti_v2p1",
+		manifestNS:    "http://www.imsglobal.org/xsd/imscp_v1p1",
+		itemType:      "imsqti_item_xmlv2p1",
+		testType:      "imsqti_test_xmlv2p1",
+		schema:        "QTIv2.1 Package",
+		schemaVersion: "1.0.0",
+	},
+	"3.0": {
+		v3:            true,
+		itemNS:        "http://www.imsglobal.org/xsd/imsqtiasi_v3p0",
+		manifestNS:    "http://www.imsglobal.org/xsd/qti/qtiv3p0/imscp_v1p1",
+		itemType:      "imsqti_item_xmlv3p0",
+		testType:      "imsqti_test_xmlv3p0",
+		schema:        "QTI Package",
+		schemaVersion: "3.0.0",
+	},
+}
+
+func versionFor(version string) (qtiVersion, error) {
+	if version == "" {
+		version = "2.1"
+	}
+	v, ok := qtiVersions[version]
+	if !ok {
+		return qtiVersion{}, fmt.Errorf("%w: %s", ErrUnknownQTIVersion, version)
+	}
+	return v, nil
+}
+
+// ExportQuestions writes the bank questions matching the filter as a QTI
+// package, one item per question.
+func (s *svc) ExportQuestions(ctx context.Context, filter ExportFilter, version string) (exportResponse, error) {
+	v, err := versionFor(version)
+	if err != nil {
+		return exportResponse{}, err
+	}
+
+	questions, err := s.repo.ListQuestions(ctx, repo.ListQuestionsParams{
+		Subject: pgtype.Text{String: filter.Subject, Valid: filter.Subject != ""},
+		Topic:   pgtype.Text{String: filter.Topic, Valid: filter.Topic != ""},
+		Limit:   exportLimit,
+	})
+	if err != nil {
+		return exportResponse{}, err
+	}
+
+	w := newPackageWriter(ctx, s.repo, s.media, v)
+	for _, q := range questions {
+		if _, err := w.addItem(q); err != nil {
+			return exportResponse{}, err
+		}
+	}
+	return w.finish()
+}
+
+// ExportExam writes an exam's questions and an assessment test that puts
+// them in the exam's sections and order. Questions whose exam marks differ
+// from their bank marks carry a weight.
+func (s *svc) ExportExam(ctx context.Context, examID int64, version string) (exportResponse, error) {
+	v, err := versionFor(version)
+	if err != nil {
+		return exportResponse{}, err
+	}
+
+	exam, err := s.repo.GetExamByID(ctx, examID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return exportResponse{}, ErrExamNotFound
+		}
+		return exportResponse{}, err
+	}
+
+	rows, err := s.repo.ListExamQuestionsForExport(ctx, examID)
+	if err != nil {
+		return exportResponse{}, err
+	}
+
+	w := newPackageWriter(ctx, s.repo, s.media, v)
+
+	test := el("assessmentTest", "xmlns", v.itemNS, "identifier", fmt.Sprintf("EXAM%d", exam.ID), "title", exam.Title)
+	test.add(el("timeLimits", "maxTime", strconv.Itoa(int(exam.DurationMinutes)*60)))
+	part := el("testPart", "identifier", "PART1", "navigationMode", "nonlinear", "submissionMode", "simultaneous")
+	test.add(part)
+
+	sections := map[string]*node{}
+	var deps []string
+	for _, row := range rows {
+		file, err := w.addItem(exportRowQuestion(row))
+		if err != nil {
+			return exportResponse{}, err
+		}
+		if file == "" {
+			continue
+		}
+
+		section, ok := sections[row.Section]
+		if !ok {
+			title := row.Section
+			if title == "" {
+				title = "Questions"
+			}
+			section = el("assessmentSection", "identifier", fmt.Sprintf("S%d", len(sections)+1), "title", title, "visible", "true")
+			sections[row.Section] = section
+			part.add(section)
+		}
+
+		id := fmt.Sprintf("Q%d", row.ID)
+		ref := el("assessmentItemRef", "identifier", id, "href", "../"+file)
+		if row.ExamMarks.Valid && row.Marks > 0 && row.ExamMarks.Float64 != row.Marks {
+			ref.add(el("weight", "identifier", "WEIGHT", "value", formatFloat(row.ExamMarks.Float64/row.Marks)))
+		}
+		section.add(ref)
+		deps = append(deps, id)
+	}
+
+	file := fmt.Sprintf("tests/exam-%d.xml", exam.ID)
+	w.files[file] = writeXML(test, v.v3)
+
+	res := plainEl("resource", "identifier", fmt.Sprintf("EXAM%d", exam.ID), "type", v.testType, "href", file)
+	res.add(plainEl("file", "href", file))
+	for _, id := range deps {
+		res.add(plainEl("dependency", "identifierref", id))
+	}
+	w.resources = append(w.resources, res)
+
+	return w.finish()
+}
+
+func exportRowQuestion(row repo.ListExamQuestionsForExportRow) repo.Question {
+	return repo.Question{
+		ID:          row.ID,
+		Type:        row.Type,
+		Stem:        row.Stem,
+		Options:     row.Options,
+		AnswerKey:   row.AnswerKey,
+		Explanation: row.Explanation,
+		Marks:       row.Marks,
+		Subject:     row.Subject,
+		Topic:       row.Topic,
+	}
+}
+
+// packageWriter collects the files and manifest resources of an export.
+type packageWriter struct {
+	ctx       context.Context
+	repo      *repo.Queries
+	media     media.Service
+	version   qtiVersion
+	files     map[string][]byte
+	resources []*node
+	// paths maps stored files to their path in the package.
+	paths   map[int64]string
+	skipped map[int64]string
+}
+
+func newPackageWriter(ctx context.Context, q *repo.Queries, m media.Service, v qtiVersion) *packageWriter {
+	return &packageWriter{
+		ctx:     ctx,
+		repo:    q,
+		media:   m,
+		version: v,
+		files:   map[string][]byte{},
+		paths:   map[int64]string{},
+		skipped: map[int64]string{},
+	}
+}
+
+var (
+	ncName     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+	unsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// addItem writes a question as an item and returns its path, or an empty
+// path when the question was skipped.
+func (w *packageWriter) addItem(q repo.Question) (string, error) {
+	id := fmt.Sprintf("Q%d", q.ID)
+	file := "items/" + id + ".xml"
+	if _, ok := w.files[file]; ok {
+		return file, nil
+	}
+
+	var used []string
+	text := func(s string) (string, error) {
+		for _, mediaID := range media.References(s) {
+			target, err := w.mediaPath(mediaID)
+			if err != nil {
+				return "", err
+			}
+			if target == "" {
+				continue
+			}
+			s = strings.ReplaceAll(s, `="`+media.URL(mediaID)+`"`, `="../`+target+`"`)
+			used = append(used, target)
+		}
+		return s, nil
+	}
+
+	item := el("assessmentItem", "xmlns", w.version.itemNS, "identifier", id, "title", fmt.Sprintf("Question %d", q.ID), "adaptive", "false", "timeDependent", "false")
+
+	stem, err := text(q.Stem)
+	if err != nil {
+		return "", err
+	}
+	body := el("itemBody").add(content(stem, true)...)
+
+	decl := el("responseDeclaration", "identifier", "RESPONSE", "cardinality", "single", "baseType", "identifier")
+	marks := formatFloat(q.Marks)
+	var rp *node
+	skip := func(reason string) (string, error) {
+		w.skipped[q.ID] = reason
+		return "", nil
+	}
+
+	switch q.Type {
+	case repo.QuestionTypeSingleChoice, repo.QuestionTypeMultipleChoice:
+		var opts []option
+		if err := json.Unmarshal(q.Options, &opts); err != nil || len(opts) == 0 {
+			return skip("options are not a list of labelled choices")
+		}
+
+		var right []string
+		if q.Type == repo.QuestionTypeSingleChoice {
+			var label string
+			if err := json.Unmarshal(q.AnswerKey, &label); err != nil {
+				return skip("answer key is not a single label")
+			}
+			right = []string{label}
+		} else {
+			if err := json.Unmarshal(q.AnswerKey, &right); err != nil {
+				return skip("answer key is not a list of labels")
+			}
+			decl.setAttr("cardinality", "multiple")
+		}
+
+		maxChoices := "1"
+		if q.Type == repo.QuestionTypeMultipleChoice {
+			maxChoices = "0"
+		}
+		it := el("choiceInteraction", "responseIdentifier", "RESPONSE", "shuffle", "false", "maxChoices", maxChoices)
+		ids := map[string]string{}
+		for i, opt := range opts {
+			ids[opt.Label] = opt.Label
+			if !ncName.MatchString(opt.Label) {
+				ids[opt.Label] = fmt.Sprintf("C%d", i+1)
+			}
+			t, err := text(opt.Text)
+			if err != nil {
+				return "", err
+			}
+			it.add(el("simpleChoice", "identifier", ids[opt.Label]).add(content(t, false)...))
+		}
+
+		correct := el("correctResponse")
+		for _, label := range right {
+			correct.add(el("value").add(textNode(ids[label])))
+		}
+		decl.add(correct)
+		body.add(it)
+		rp = scoreIf(el("match"), marks)
+
+	case repo.QuestionTypeTrueFalse:
+		var answer bool
+		if err := json.Unmarshal(q.AnswerKey, &answer); err != nil {
+			return skip("answer key is not true or false")
+		}
+		decl.add(el("correctResponse").add(el("value").add(textNode(strconv.FormatBool(answer)))))
+		body.add(el("choiceInteraction", "responseIdentifier", "RESPONSE", "shuffle", "false", "maxChoices", "1").add(
+			el("simpleChoice", "identifier", "true").add(textNode("True")),
+			el("simpleChoice", "identifier", "false").add(textNode("False")),
+		))
+		rp = scoreIf(el("match"), marks)
+
+	case repo.QuestionTypeNumeric:
+		var key numericKey
+		if err := json.Unmarshal(q.AnswerKey, &key); err != nil {
+			return skip("answer key is not a number and tolerance")
+		}
+		decl.setAttr("baseType", "float")
+		decl.add(el("correctResponse").add(el("value").add(textNode(formatFloat(key.Value)))))
+		body.add(plainEl("p").add(el("textEntryInteraction", "responseIdentifier", "RESPONSE", "expectedLength", "15")))
+
+		equal := el("equal", "toleranceMode", "exact")
+		if key.Tolerance > 0 {
+			t := formatFloat(key.Tolerance)
+			equal = el("equal", "toleranceMode", "absolute", "tolerance", t+" "+t)
+		}
+		rp = scoreIf(equal, marks)
+
+	case repo.QuestionTypeMatching:
+		var opts matchingOptions
+		var key map[string]string
+		if err := json.Unmarshal(q.Options, &opts); err != nil || len(opts.Prompts) == 0 {
+			return skip("options are not prompts and choices")
+		}
+		if err := json.Unmarshal(q.AnswerKey, &key); err != nil {
+			return skip("answer key is not a map of prompts to choices")
+		}
+
+		decl = el("responseDeclaration", "identifier", "RESPONSE", "cardinality", "multiple", "baseType", "directedPair")
+		it := el("matchInteraction", "responseIdentifier", "RESPONSE", "shuffle", "false", "maxAssociations", strconv.Itoa(len(opts.Prompts)))
+		prompts, choices := el("simpleMatchSet"), el("simpleMatchSet")
+		promptIDs, choiceIDs := map[string]string{}, map[string]string{}
+		for i, p := range opts.Prompts {
+			promptIDs[p.Label] = fmt.Sprintf("P%d", i+1)
+			t, err := text(p.Text)
+			if err != nil {
+				return "", err
+			}
+			prompts.add(el("simpleAssociableChoice", "identifier", promptIDs[p.Label], "matchMax", "1").add(content(t, false)...))
+		}
+		for i, c := range opts.Choices {
+			choiceIDs[c.Label] = fmt.Sprintf("C%d", i+1)
+			t, err := text(c.Text)
+			if err != nil {
+				return "", err
+			}
+			choices.add(el("simpleAssociableChoice", "identifier", choiceIDs[c.Label], "matchMax", strconv.Itoa(len(opts.Prompts))).add(content(t, false)...))
+		}
+		body.add(it.add(prompts, choices))
+
+		correct := el("correctResponse")
+		mapping := el("mapping", "lowerBound", "0", "upperBound", marks, "defaultValue", "0")
+		for _, p := range opts.Prompts {
+			c, ok := key[p.Label]
+			if !ok || choiceIDs[c] == "" {
+				continue
+			}
+			pair := promptIDs[p.Label] + " " + choiceIDs[c]
+			correct.add(el("value").add(textNode(pair)))
+			mapping.add(el("mapEntry", "mapKey", pair, "mappedValue", formatFloat(q.Marks/float64(len(key)))))
+		}
+		decl.add(correct, mapping)
+		rp = el("responseProcessing").add(
+			el("setOutcomeValue", "identifier", "SCORE").add(el("mapResponse", "identifier", "RESPONSE")),
+		)
+
+	case repo.QuestionTypeShortAnswer:
+		var key manualKey
+		_ = json.Unmarshal(q.AnswerKey, &key)
+		decl.setAttr("baseType", "string")
+		if len(key.Accepted) > 0 {
+			correct := el("correctResponse")
+			for _, a := range key.Accepted {
+				correct.add(el("value").add(textNode(a)))
+			}
+			decl.add(correct)
+		}
+		body.add(plainEl("p").add(el("textEntryInteraction", "responseIdentifier", "RESPONSE", "expectedLength", "30")))
+
+	case repo.QuestionTypeEssay:
+		var key manualKey
+		_ = json.Unmarshal(q.AnswerKey, &key)
+		decl.setAttr("baseType", "string")
+		if key.Guidance != "" {
+			body.add(w.wrap(el("rubricBlock", "view", "scorer"), content(key.Guidance, true)))
+		}
+		body.add(el("extendedTextInteraction", "responseIdentifier", "RESPONSE"))
+
+	default:
+		return skip(fmt.Sprintf("%s questions cannot be exported", q.Type))
+	}
+
+	item.add(decl)
+	item.add(el("outcomeDeclaration", "identifier", "SCORE", "cardinality", "single", "baseType", "float", "normalMaximum", marks).add(
+		el("defaultValue").add(el("value").add(textNode("0"))),
+	))
+	item.add(el("outcomeDeclaration", "identifier", "MAXSCORE", "cardinality", "single", "baseType", "float").add(
+		el("defaultValue").add(el("value").add(textNode(marks))),
+	))
+
+	var feedback *node
+	if q.Explanation.Valid && q.Explanation.String != "" {
+		explanation, err := text(q.Explanation.String)
+		if err != nil {
+			return "", err
+		}
+		item.add(el("outcomeDeclaration", "identifier", "FEEDBACK", "cardinality", "single", "baseType", "identifier"))
+		feedback = w.wrap(el("modalFeedback", "outcomeIdentifier", "FEEDBACK", "identifier", "EXPLANATION", "showHide", "show"), content(explanation, true))
+		if rp == nil {
+			rp = el("responseProcessing")
+		}
+		rp.add(el("setOutcomeValue", "identifier", "FEEDBACK").add(el("baseValue", "baseType", "identifier").add(textNode("EXPLANATION"))))
+	}
+
+	item.add(body)
+	if rp != nil {
+		item.add(rp)
+	}
+	if feedback != nil {
+		item.add(feedback)
+	}
+
+	w.files[file] = writeXML(item, w.version.v3)
+
+	res := plainEl("resource", "identifier", id, "type", w.version.itemType, "href", file)
+	if q.Subject.Valid && q.Subject.String != "" {
+		res.add(lomClassification(q.Subject.String, q.Topic.String))
+	}
+	res.add(plainEl("file", "href", file))
+	seen := map[string]bool{}
+	for _, m := range used {
+		if !seen[m] {
+			seen[m] = true
+			res.add(plainEl("file", "href", m))
+		}
+	}
+	w.resources = append(w.resources, res)
+
+	return file, nil
+}
+
+// scoreIf is response processing that awards the marks when the condition
+// holds between the response and the correct response.
+func scoreIf(condition *node, marks string) *node {
+	condition.add(el("variable", "identifier", "RESPONSE"), el("correct", "identifier", "RESPONSE"))
+	return el("responseProcessing").add(
+		el("responseCondition").add(
+			el("responseIf").add(
+				condition,
+				el("setOutcomeValue", "identifier", "SCORE").add(el("baseValue", "baseType", "float").add(textNode(marks))),
+			),
+			el("responseElse").add(
+				el("setOutcomeValue", "identifier", "SCORE").add(el("baseValue", "baseType", "float").add(textNode("0"))),
+			),
+		),
+	)
+}
+
+// wrap adds feedback or rubric content, inside the content body QTI 3.0
+// requires.
+func (w *packageWriter) wrap(n *node, children []*node) *node {
+	if w.version.v3 {
+		return n.add(el("contentBody").add(children...))
+	}
+	return n.add(children...)
+}
+
+// lomClassification records the subject and topic as a LOM taxon path,
+// which lomCategory reads back.
+func lomClassification(subject, topic string) *node {
+	path := plainEl("taxonPath")
+	for _, name := range []string{subject, topic} {
+		if name != "" {
+			path.add(plainEl("taxon").add(plainEl("entry").add(plainEl("string").add(textNode(name)))))
+		}
+	}
+	return plainEl("metadata").add(
+		plainEl("lom", "xmlns", "http://ltsc.ieee.org/xsd/LOM").add(
+			plainEl("classification").add(path),
+		),
+	)
+}
+
+// mediaPath adds a stored file to the package once and returns its path.
+// Files that no longer exist are left linked as they are.
+func (w *packageWriter) mediaPath(id int64) (string, error) {
+	if p, ok := w.paths[id]; ok {
+		return p, nil
+	}
+
+	f, err := w.media.GetFile(w.ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			w.paths[id] = ""
+			return "", nil
+		}
+		return "", err
+	}
+
+	body, err := w.media.Open(w.ctx, f)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			w.paths[id] = ""
+			return "", nil
+		}
+		return "", err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+
+	p := fmt.Sprintf("media/%d-%s", f.ID, unsafeName.ReplaceAllString(f.Name, "_"))
+	w.paths[id] = p
+	w.files[p] = data
+	return p, nil
+}
+
+// finish writes the manifest and zips the package, manifest first.
+func (w *packageWriter) finish() (exportResponse, error) {
+	manifest := plainEl("manifest", "xmlns", w.version.manifestNS, "identifier", "MANIFEST").add(
+		plainEl("metadata").add(
+			plainEl("schema").add(textNode(w.version.schema)),
+			plainEl("schemaversion").add(textNode(w.version.schemaVersion)),
+		),
+		plainEl("organizations"),
+		plainEl("resources").add(w.resources...),
+	)
+
+	names := make([]string, 0, len(w.files))
+	for name := range w.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name string, data []byte) error {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		return err
+	}
+
+	if err := write(manifestFile, writeXML(manifest, false)); err != nil {
+		return exportResponse{}, err
+	}
+	for _, name := range names {
+		if err := write(name, w.files[name]); err != nil {
+			return exportResponse{}, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return exportResponse{}, err
+	}
+
+	return exportResponse{Package: buf.Bytes(), Skipped: w.skipped}, nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
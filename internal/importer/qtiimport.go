@@ -0,0 +1,676 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/config"
+)
+
+const manifestFile = "imsmanifest.xml"
+
+// unpackedRatio bounds how far a package may expand when unzipped, relative
+// to the upload limit.
+const unpackedRatio = 4
+
+// defaultTestMinutes is the exam duration used when a test sets no time
+// limit.
+const defaultTestMinutes = 60
+
+// testDraft is an assessment test read from a package. It becomes a draft
+// exam over the questions its items are imported as.
+type testDraft struct {
+	File            string     `json:"file"`
+	Line            int        `json:"line"`
+	Title           string     `json:"title"`
+	DurationMinutes int32      `json:"duration_minutes"`
+	Items           []testItem `json:"items"`
+}
+
+// testItem places one item in the exam. Item is the item's path in the
+// package and Weight scales its marks when it is not 1.
+type testItem struct {
+	Item     string  `json:"item"`
+	Line     int     `json:"line"`
+	Section  string  `json:"section"`
+	Position int32   `json:"position"`
+	Weight   float64 `json:"weight,omitempty"`
+}
+
+// parseQTI reads an IMS content package of QTI 2.1 or 3.0 items and tests.
+// The manifest decides what is read: item and test resources are parsed and
+// everything else is only available as media.
+func parseQTI(data []byte) (parsed, error) {
+	files, err := readPackage(data, unpackedLimit())
+	if err != nil {
+		return parsed{}, fmt.Errorf("%w: %v", ErrInvalidPackage, err)
+	}
+
+	if _, ok := files[manifestFile]; !ok {
+		return parsed{}, fmt.Errorf("%w: %s is missing", ErrInvalidPackage, manifestFile)
+	}
+
+	manifest, err := parseXML(files[manifestFile])
+	if err != nil {
+		return parsed{}, fmt.Errorf("%w: %s: %v", ErrInvalidPackage, manifestFile, err)
+	}
+
+	p := parsed{format: FormatQTI, files: files}
+
+	for _, res := range manifest.find("resource") {
+		typ := res.attr("type")
+		isItem, isTest := strings.HasPrefix(typ, "imsqti_item"), strings.HasPrefix(typ, "imsqti_test")
+		if !isItem && !isTest {
+			continue
+		}
+
+		href := res.attr("href")
+		if f := res.child("file"); href == "" && f != nil {
+			href = f.attr("href")
+		}
+		href = path.Clean(href)
+		if _, ok := files[href]; !ok {
+			p.issues = append(p.issues, Issue{File: manifestFile, Line: res.Line, Severity: SeverityError, Message: fmt.Sprintf("resource file %s is not in the package", href)})
+			continue
+		}
+
+		if isTest {
+			t, issues := qtiTest(href, files)
+			p.issues = append(p.issues, issues...)
+			if t != nil {
+				p.tests = append(p.tests, *t)
+			}
+			continue
+		}
+
+		d, issues := qtiItem(href, files)
+		p.issues = append(p.issues, issues...)
+		if d == nil {
+			continue
+		}
+		d.Subject, d.Topic = lomCategory(res)
+		p.drafts = append(p.drafts, *d)
+	}
+
+	return p, nil
+}
+
+func unpackedLimit() int64 {
+	return int64(config.ImportMaxMB) << 20 * unpackedRatio
+}
+
+// readPackage unzips an upload into its files by path. limit caps the
+// unpacked size so a small upload cannot expand without bound.
+func readPackage(data []byte, limit int64) (map[string][]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+	var total int64
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		name := path.Clean(strings.TrimPrefix(f.Name, "/"))
+		if name == ".." || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("%s is outside the archive", f.Name)
+		}
+
+		total += int64(f.UncompressedSize64)
+		if total > limit {
+			return nil, fmt.Errorf("archive unpacks to more than %d MB", limit>>20)
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		b, err := io.ReadAll(io.LimitReader(rc, int64(f.UncompressedSize64)+1))
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		files[name] = b
+	}
+	return files, nil
+}
+
+// lomCategory reads the subject and topic our exports record as a LOM
+// classification on the resource.
+func lomCategory(res *node) (subject, topic string) {
+	for _, tp := range res.find("taxonPath") {
+		var names []string
+		for _, taxon := range tp.find("taxon") {
+			if s := taxon.find("string"); len(s) > 0 {
+				names = append(names, strings.TrimSpace(s[0].text()))
+			}
+		}
+		switch len(names) {
+		case 0:
+			continue
+		case 1:
+			return names[0], ""
+		}
+		return names[0], names[len(names)-1]
+	}
+	return "", ""
+}
+
+func xmlIssue(file string, err error) Issue {
+	line := 0
+	var syntax *xml.SyntaxError
+	if errors.As(err, &syntax) {
+		line = syntax.Line
+	}
+	return Issue{File: file, Line: line, Severity: SeverityError, Message: fmt.Sprintf("invalid XML: %v", err)}
+}
+
+// dropped are body elements that are not part of the question text.
+var dropped = map[string]bool{
+	"feedbackInline":  true,
+	"feedbackBlock":   true,
+	"rubricBlock":     true,
+	"templateBlock":   true,
+	"templateInline":  true,
+	"printedVariable": true,
+}
+
+func qtiItem(file string, files map[string][]byte) (*Draft, []Issue) {
+	root, err := parseXML(files[file])
+	if err != nil {
+		return nil, []Issue{xmlIssue(file, err)}
+	}
+
+	line := root.Line
+	issue := func(line int, severity, format string, args ...any) Issue {
+		return Issue{File: file, Line: line, Severity: severity, Message: fmt.Sprintf(format, args...)}
+	}
+
+	if root.Name != "assessmentItem" {
+		return nil, []Issue{issue(line, SeverityError, "%s is not an assessment item", root.Name)}
+	}
+	if t := root.find("templateDeclaration"); len(t) > 0 {
+		return nil, []Issue{issue(t[0].Line, SeverityError, "template items with generated values are not supported")}
+	}
+
+	body := root.child("itemBody")
+	if body == nil {
+		return nil, []Issue{issue(line, SeverityError, "item has no body")}
+	}
+
+	var interactions []*node
+	walk(body, func(n *node) {
+		if strings.HasSuffix(n.Name, "Interaction") {
+			interactions = append(interactions, n)
+		}
+	})
+	switch len(interactions) {
+	case 0:
+		return nil, []Issue{issue(line, SeverityWarning, "item has no interaction and was skipped")}
+	case 1:
+	default:
+		return nil, []Issue{issue(interactions[1].Line, SeverityError, "items with more than one interaction are not supported")}
+	}
+	it := interactions[0]
+
+	var issues []Issue
+	if root.attr("adaptive") == "true" {
+		issues = append(issues, issue(line, SeverityWarning, "adaptive item is imported as a single-step question"))
+	}
+
+	media, missing := resolveMedia(root, file, files)
+	for _, m := range missing {
+		issues = append(issues, issue(m.Line, SeverityError, "media file %s is not in the package", m.Text))
+	}
+
+	var guidance []string
+	walk(body, func(n *node) {
+		switch {
+		case n.Name == "rubricBlock" && strings.Contains(n.attr("view"), "scorer"):
+			guidance = append(guidance, contentText(bodyOf(n)))
+		case n.Name == "feedbackInline" || n.Name == "feedbackBlock":
+			issues = append(issues, issue(n.Line, SeverityWarning, "feedback inside the item body is not imported"))
+		}
+	})
+
+	stemNodes := strip(body.Children, it)
+	if prompt := it.child("prompt"); prompt != nil {
+		stemNodes = append(stemNodes, plainEl("p").add(prompt.Children...))
+	}
+
+	d := &Draft{
+		File:    file,
+		Line:    line,
+		Stem:    contentText(stemNodes),
+		Options: encode([]option{}),
+		Marks:   itemMarks(root),
+		Media:   media,
+	}
+	if d.Stem == "" {
+		return nil, append(issues, issue(line, SeverityError, "question has no text"))
+	}
+
+	switch feedback := root.children("modalFeedback"); len(feedback) {
+	case 0:
+	case 1:
+		d.Explanation = contentText(bodyOf(feedback[0]))
+	default:
+		d.Explanation = contentText(bodyOf(feedback[0]))
+		issues = append(issues, issue(feedback[1].Line, SeverityWarning, "only the first modal feedback is imported as the explanation"))
+	}
+
+	var decl *node
+	for _, rd := range root.children("responseDeclaration") {
+		if rd.attr("identifier") == it.attr("responseIdentifier") {
+			decl = rd
+		}
+	}
+	if decl == nil && it.Name != "extendedTextInteraction" {
+		return nil, append(issues, issue(it.Line, SeverityError, "interaction has no response declaration"))
+	}
+
+	var found []Issue
+	switch it.Name {
+	case "choiceInteraction":
+		found = qtiChoice(d, it, decl, issue)
+	case "textEntryInteraction":
+		found = qtiTextEntry(d, root, decl, issue)
+	case "extendedTextInteraction":
+		d.Type = repo.QuestionTypeEssay
+		d.AnswerKey = encode(manualKey{Guidance: strings.Join(guidance, "\n")})
+	case "matchInteraction":
+		found = qtiMatch(d, it, decl, issue)
+	default:
+		found = []Issue{issue(it.Line, SeverityError, "%s is not supported", it.Name)}
+	}
+
+	issues = append(issues, found...)
+	if d.AnswerKey == nil {
+		return nil, issues
+	}
+	return d, issues
+}
+
+type issueFunc func(line int, severity, format string, args ...any) Issue
+
+func qtiChoice(d *Draft, it, decl *node, issue issueFunc) []Issue {
+	choices := it.children("simpleChoice")
+	if len(choices) > maxChoices {
+		return []Issue{issue(it.Line, SeverityError, "choice questions can have at most %d options", maxChoices)}
+	}
+	if len(choices) < 2 {
+		return []Issue{issue(it.Line, SeverityError, "choice question needs at least two options")}
+	}
+
+	var issues []Issue
+	if decl.child("mapping") != nil {
+		issues = append(issues, issue(decl.Line, SeverityWarning, "partial credit mapping is not imported"))
+	}
+
+	labels := map[string]string{}
+	opts := make([]option, 0, len(choices))
+	for i, c := range choices {
+		labels[c.attr("identifier")] = choiceLabel(i)
+		opts = append(opts, option{Label: choiceLabel(i), Text: contentText(strip(c.Children, nil))})
+	}
+
+	var right []string
+	for _, v := range decl.child("correctResponse").values() {
+		label, ok := labels[v]
+		if !ok {
+			return append(issues, issue(decl.Line, SeverityError, "correct response %q is not one of the choices", v))
+		}
+		right = append(right, label)
+	}
+	if len(right) == 0 {
+		return append(issues, issue(decl.Line, SeverityError, "choice question has no correct response"))
+	}
+
+	if decl.attr("cardinality") != "single" {
+		d.Type = repo.QuestionTypeMultipleChoice
+		d.Options = encode(opts)
+		d.AnswerKey = encode(right)
+		return issues
+	}
+
+	// Two choices reading True and False are a true/false question.
+	if len(opts) == 2 {
+		first, second := strings.ToLower(opts[0].Text), strings.ToLower(opts[1].Text)
+		if (first == "true" && second == "false") || (first == "false" && second == "true") {
+			d.Type = repo.QuestionTypeTrueFalse
+			d.AnswerKey = encode(strings.ToLower(opts[labelIndex(right[0])].Text) == "true")
+			return issues
+		}
+	}
+
+	d.Type = repo.QuestionTypeSingleChoice
+	d.Options = encode(opts)
+	d.AnswerKey = encode(right[0])
+	return issues
+}
+
+func labelIndex(label string) int {
+	return int(label[0] - 'A')
+}
+
+func qtiTextEntry(d *Draft, root, decl *node, issue issueFunc) []Issue {
+	correct := decl.child("correctResponse").values()
+
+	switch decl.attr("baseType") {
+	case "float", "integer":
+		if len(correct) == 0 {
+			return []Issue{issue(decl.Line, SeverityError, "numeric question has no correct response")}
+		}
+		value, err := strconv.ParseFloat(correct[0], 64)
+		if err != nil {
+			return []Issue{issue(decl.Line, SeverityError, "%q is not a number", correct[0])}
+		}
+		key := numericKey{Value: value}
+
+		for _, eq := range root.find("equal") {
+			tolerance := strings.Fields(eq.attr("tolerance"))
+			if len(tolerance) == 0 {
+				continue
+			}
+			t, err := strconv.ParseFloat(tolerance[0], 64)
+			if err != nil || t < 0 {
+				return []Issue{issue(eq.Line, SeverityError, "%q is not a valid tolerance", eq.attr("tolerance"))}
+			}
+			switch eq.attr("toleranceMode") {
+			case "absolute":
+				key.Tolerance = t
+			case "relative":
+				key.Tolerance = math.Abs(value) * t / 100
+			}
+			break
+		}
+
+		d.Type = repo.QuestionTypeNumeric
+		d.AnswerKey = encode(key)
+		return nil
+
+	case "string":
+		accepted := correct
+		if mapping := decl.child("mapping"); mapping != nil {
+			for _, entry := range mapping.children("mapEntry") {
+				value, _ := strconv.ParseFloat(entry.attr("mappedValue"), 64)
+				if value > 0 && !contains(accepted, entry.attr("mapKey")) {
+					accepted = append(accepted, entry.attr("mapKey"))
+				}
+			}
+		}
+		d.Type = repo.QuestionTypeShortAnswer
+		d.AnswerKey = encode(manualKey{Accepted: accepted})
+		return nil
+	}
+
+	return []Issue{issue(decl.Line, SeverityError, "text entry with base type %q is not supported", decl.attr("baseType"))}
+}
+
+func qtiMatch(d *Draft, it, decl *node, issue issueFunc) []Issue {
+	sets := it.children("simpleMatchSet")
+	if len(sets) != 2 {
+		return []Issue{issue(it.Line, SeverityError, "match interaction needs exactly two sets")}
+	}
+	prompts, choices := sets[0].children("simpleAssociableChoice"), sets[1].children("simpleAssociableChoice")
+	if len(choices) > maxChoices {
+		return []Issue{issue(it.Line, SeverityError, "matching questions can have at most %d answers", maxChoices)}
+	}
+
+	opts := matchingOptions{Prompts: []option{}, Choices: []option{}}
+	promptLabels, choiceLabels := map[string]string{}, map[string]string{}
+	for i, p := range prompts {
+		promptLabels[p.attr("identifier")] = promptLabel(i)
+		opts.Prompts = append(opts.Prompts, option{Label: promptLabel(i), Text: contentText(p.Children)})
+	}
+	for i, c := range choices {
+		choiceLabels[c.attr("identifier")] = matchLabel(i)
+		opts.Choices = append(opts.Choices, option{Label: matchLabel(i), Text: contentText(c.Children)})
+	}
+
+	var issues []Issue
+	key := map[string]string{}
+	for _, v := range decl.child("correctResponse").values() {
+		ids := strings.Fields(v)
+		if len(ids) != 2 {
+			return append(issues, issue(decl.Line, SeverityError, "%q is not a pair", v))
+		}
+		p, ok1 := promptLabels[ids[0]]
+		c, ok2 := choiceLabels[ids[1]]
+		if !ok1 || !ok2 {
+			return append(issues, issue(decl.Line, SeverityError, "pair %q does not match the sets", v))
+		}
+		if _, ok := key[p]; ok {
+			issues = append(issues, issue(decl.Line, SeverityWarning, "only the first answer for each prompt is imported"))
+			continue
+		}
+		key[p] = c
+	}
+	if len(key) == 0 {
+		return append(issues, issue(decl.Line, SeverityError, "matching question has no correct response"))
+	}
+
+	d.Type = repo.QuestionTypeMatching
+	d.Options = encode(opts)
+	d.AnswerKey = encode(key)
+	return issues
+}
+
+// itemMarks reads the item's maximum score from MAXSCORE, then from SCORE's
+// normalMaximum, and otherwise gives it 1 mark.
+func itemMarks(root *node) float64 {
+	for _, od := range root.children("outcomeDeclaration") {
+		if od.attr("identifier") != "MAXSCORE" {
+			continue
+		}
+		if v := od.child("defaultValue").values(); len(v) > 0 {
+			if marks, err := strconv.ParseFloat(v[0], 64); err == nil && marks > 0 {
+				return marks
+			}
+		}
+	}
+	for _, od := range root.children("outcomeDeclaration") {
+		if od.attr("identifier") != "SCORE" {
+			continue
+		}
+		if marks, err := strconv.ParseFloat(od.attr("normalMaximum"), 64); err == nil && marks > 0 {
+			return marks
+		}
+	}
+	return 1
+}
+
+func qtiTest(file string, files map[string][]byte) (*testDraft, []Issue) {
+	root, err := parseXML(files[file])
+	if err != nil {
+		return nil, []Issue{xmlIssue(file, err)}
+	}
+	if root.Name != "assessmentTest" {
+		return nil, []Issue{{File: file, Line: root.Line, Severity: SeverityError, Message: fmt.Sprintf("%s is not an assessment test", root.Name)}}
+	}
+
+	var issues []Issue
+	t := &testDraft{File: file, Line: root.Line, Title: root.attr("title")}
+	if t.Title == "" {
+		t.Title = root.attr("identifier")
+	}
+
+	limits := root.find("timeLimits")
+	if len(limits) > 0 {
+		if seconds, err := strconv.ParseFloat(limits[0].attr("maxTime"), 64); err == nil && seconds > 0 {
+			t.DurationMinutes = int32(math.Ceil(seconds / 60))
+		}
+	}
+	if t.DurationMinutes == 0 {
+		t.DurationMinutes = defaultTestMinutes
+		issues = append(issues, Issue{File: file, Line: root.Line, Severity: SeverityWarning, Message: fmt.Sprintf("test has no time limit, the exam is set to %d minutes", defaultTestMinutes)})
+	}
+
+	// Nested sections are flattened into the top-level section they sit
+	// in. A test with one section puts its questions in the exam's default
+	// section.
+	var sections []*node
+	for _, part := range root.children("testPart") {
+		sections = append(sections, part.children("assessmentSection")...)
+	}
+
+	for _, section := range sections {
+		name := section.attr("title")
+		if len(sections) == 1 {
+			name = ""
+		}
+
+		var position int32
+		walk(section, func(n *node) {
+			if n.Name != "assessmentItemRef" {
+				return
+			}
+			position++
+			item := testItem{
+				Item:     path.Clean(path.Join(path.Dir(file), n.attr("href"))),
+				Line:     n.Line,
+				Section:  name,
+				Position: position,
+			}
+			if w := n.child("weight"); w != nil {
+				item.Weight, _ = strconv.ParseFloat(w.attr("value"), 64)
+			}
+			t.Items = append(t.Items, item)
+		})
+	}
+
+	return t, issues
+}
+
+// walk calls fn on every element under n, depth first.
+func walk(n *node, fn func(*node)) {
+	for _, c := range n.Children {
+		if c.Name == "" {
+			continue
+		}
+		fn(c)
+		walk(c, fn)
+	}
+}
+
+// bodyOf returns an element's content. QTI 3.0 wraps feedback and rubric
+// content in a content body element.
+func bodyOf(n *node) []*node {
+	if b := n.child("contentBody"); b != nil {
+		return b.Children
+	}
+	return n.Children
+}
+
+// strip copies content without the interaction and the elements in
+// dropped. An inline text entry inside a sentence leaves a gap behind, and
+// paragraphs left empty are removed.
+func strip(nodes []*node, it *node) []*node {
+	var out []*node
+	for _, n := range nodes {
+		switch {
+		case n == it:
+			if n.Name == "textEntryInteraction" && hasText(nodes) {
+				out = append(out, textNode("_____"))
+			}
+			continue
+		case dropped[n.Name]:
+			continue
+		case n.Name == "":
+			out = append(out, n)
+			continue
+		}
+
+		c := *n
+		c.Children = strip(n.Children, it)
+		if (c.Name == "p" || c.Name == "div") && len(n.Children) > 0 && !hasContent(c.Children) {
+			continue
+		}
+		out = append(out, &c)
+	}
+	return out
+}
+
+func hasText(nodes []*node) bool {
+	for _, n := range nodes {
+		if n.Name == "" && strings.TrimSpace(n.Text) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func hasContent(nodes []*node) bool {
+	for _, n := range nodes {
+		if n.Name != "" || strings.TrimSpace(n.Text) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveMedia points src and data attributes at package paths, relative to
+// the item, and lists the files they use. References to files missing from
+// the package come back as text nodes carrying the path and line.
+func resolveMedia(root *node, file string, files map[string][]byte) (media []string, missing []*node) {
+	seen := map[string]bool{}
+	walk(root, func(n *node) {
+		for i, a := range n.Attrs {
+			if a.Name.Local != "src" && a.Name.Local != "data" {
+				continue
+			}
+			if a.Value == "" || strings.Contains(a.Value, ":") || strings.HasPrefix(a.Value, "/") {
+				continue
+			}
+
+			target := path.Clean(path.Join(path.Dir(file), a.Value))
+			if _, ok := files[target]; !ok {
+				missing = append(missing, &node{Text: target, Line: n.Line})
+				continue
+			}
+			n.Attrs[i].Value = target
+			if !seen[target] {
+				seen[target] = true
+				media = append(media, target)
+			}
+		}
+	})
+	return media, missing
+}
+
+// linkMedia points a draft's references to a package file at its stored
+// copy. Options hold the same HTML inside JSON strings.
+func linkMedia(d *Draft, file, url string) {
+	from, to := `="`+html.EscapeString(file)+`"`, `="`+url+`"`
+	d.Stem = strings.ReplaceAll(d.Stem, from, to)
+	d.Explanation = strings.ReplaceAll(d.Explanation, from, to)
+	d.Options = []byte(strings.ReplaceAll(string(d.Options), jsonInner(from), jsonInner(to)))
+}
+
+func jsonInner(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b[1 : len(b)-1])
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,265 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+// qtiPackage zips files by path, as a content package upload would be.
+func qtiPackage(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func qtiManifest(resources ...string) string {
+	s := `<?xml version="1.0"?><manifest xmlns="http://www.imsglobal.org/xsd/imscp_v1p1"><resources>`
+	for _, r := range resources {
+		s += r
+	}
+	return s + `</resources></manifest>`
+}
+
+func qtiItemResource(href string) string {
+	return `<resource identifier="` + href + `" type="imsqti_item_xmlv2p1" href="` + href + `"><file href="` + href + `"/></resource>`
+}
+
+const (
+	qti21Choice = `<?xml version="1.0"?>
+<assessmentItem xmlns="http://www.imsglobal.org/xsd/imsqti_v2p1" identifier="q1" title="Q1">
+<responseDeclaration identifier="RESPONSE" cardinality="single" baseType="identifier"><correctResponse><value>B</value></correctResponse></responseDeclaration>
+<outcomeDeclaration identifier="MAXSCORE" cardinality="single" baseType="float"><defaultValue><value>2</value></defaultValue></outcomeDeclaration>
+<itemBody><p>Capital of Nigeria?</p><choiceInteraction responseIdentifier="RESPONSE" maxChoices="1"><simpleChoice identifier="A">Lagos</simpleChoice><simpleChoice identifier="B">Abuja</simpleChoice></choiceInteraction></itemBody>
+<modalFeedback outcomeIdentifier="FEEDBACK" identifier="f" showHide="show"><p>Abuja since 1991.</p></modalFeedback>
+</assessmentItem>`
+
+	qti30Multiple = `<?xml version="1.0"?>
+<qti-assessment-item xmlns="http://www.imsglobal.org/xsd/imsqtiasi_v3p0" identifier="q2" title="Q2">
+<qti-response-declaration identifier="RESPONSE" cardinality="multiple" base-type="identifier"><qti-correct-response><qti-value>A</qti-value><qti-value>C</qti-value></qti-correct-response></qti-response-declaration>
+<qti-item-body><qti-choice-interaction response-identifier="RESPONSE" max-choices="0"><qti-prompt>Which are prime?</qti-prompt><qti-simple-choice identifier="A">2</qti-simple-choice><qti-simple-choice identifier="B">4</qti-simple-choice><qti-simple-choice identifier="C">5</qti-simple-choice></qti-choice-interaction></qti-item-body>
+</qti-assessment-item>`
+
+	qti21TrueFalse = `<?xml version="1.0"?>
+<assessmentItem identifier="q3" title="Q3">
+<responseDeclaration identifier="RESPONSE" cardinality="single" baseType="identifier"><correctResponse><value>F</value></correctResponse></responseDeclaration>
+<itemBody><p>The earth is flat.</p><choiceInteraction responseIdentifier="RESPONSE" maxChoices="1"><simpleChoice identifier="T">True</simpleChoice><simpleChoice identifier="F">False</simpleChoice></choiceInteraction></itemBody>
+</assessmentItem>`
+
+	qti21Numeric = `<?xml version="1.0"?>
+<assessmentItem identifier="q4" title="Q4">
+<responseDeclaration identifier="RESPONSE" cardinality="single" baseType="float"><correctResponse><value>9.8</value></correctResponse></responseDeclaration>
+<itemBody><p>g in m/s² is <textEntryInteraction responseIdentifier="RESPONSE"/></p></itemBody>
+<responseProcessing><responseCondition><responseIf><equal toleranceMode="absolute" tolerance="0.1 0.1"><variable identifier="RESPONSE"/><correct identifier="RESPONSE"/></equal></responseIf></responseCondition></responseProcessing>
+</assessmentItem>`
+
+	qti21Match = `<?xml version="1.0"?>
+<assessmentItem identifier="q5" title="Q5">
+<responseDeclaration identifier="RESPONSE" cardinality="multiple" baseType="directedPair"><correctResponse><value>NG ABJ</value><value>GH ACC</value></correctResponse></responseDeclaration>
+<itemBody><p>Match the capitals.</p><matchInteraction responseIdentifier="RESPONSE">
+<simpleMatchSet><simpleAssociableChoice identifier="NG">Nigeria</simpleAssociableChoice><simpleAssociableChoice identifier="GH">Ghana</simpleAssociableChoice></simpleMatchSet>
+<simpleMatchSet><simpleAssociableChoice identifier="ACC">Accra</simpleAssociableChoice><simpleAssociableChoice identifier="ABJ">Abuja</simpleAssociableChoice></simpleMatchSet>
+</matchInteraction></itemBody>
+</assessmentItem>`
+
+	qti21TwoInteractions = `<?xml version="1.0"?>
+<assessmentItem identifier="q6" title="Q6">
+<itemBody><p>A <textEntryInteraction responseIdentifier="R1"/> and <textEntryInteraction responseIdentifier="R2"/></p></itemBody>
+</assessmentItem>`
+
+	qti21Test = `<?xml version="1.0"?>
+<assessmentTest identifier="t1" title="Mock paper">
+<timeLimits maxTime="5400"/>
+<testPart identifier="p1" navigationMode="linear" submissionMode="individual">
+<assessmentSection identifier="s1" title="Geography" visible="true"><assessmentItemRef identifier="q1" href="../items/q1.xml"><weight identifier="W" value="2"/></assessmentItemRef></assessmentSection>
+<assessmentSection identifier="s2" title="Science" visible="true"><assessmentItemRef identifier="q4" href="../items/q4.xml"/></assessmentSection>
+</testPart>
+</assessmentTest>`
+)
+
+func TestParseQTIItems(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		item       string
+		wantDrafts []wantDraft
+		wantIssues []wantIssue
+	}{
+		{
+			name: "QTI 2.1 single choice with marks",
+			file: "items/q1.xml",
+			item: qti21Choice,
+			wantDrafts: []wantDraft{
+				{2, repo.QuestionTypeSingleChoice, "Capital of Nigeria?", `[{"label":"A","text":"Lagos"},{"label":"B","text":"Abuja"}]`, `"B"`, 2},
+			},
+		},
+		{
+			name: "QTI 3.0 multiple choice with prompt",
+			file: "items/q2.xml",
+			item: qti30Multiple,
+			wantDrafts: []wantDraft{
+				{2, repo.QuestionTypeMultipleChoice, "Which are prime?", `[{"label":"A","text":"2"},{"label":"B","text":"4"},{"label":"C","text":"5"}]`, `["A","C"]`, 1},
+			},
+		},
+		{
+			name: "true and false choices",
+			file: "items/q3.xml",
+			item: qti21TrueFalse,
+			wantDrafts: []wantDraft{
+				{2, repo.QuestionTypeTrueFalse, "The earth is flat.", `[]`, `false`, 1},
+			},
+		},
+		{
+			name: "numeric text entry with tolerance",
+			file: "items/q4.xml",
+			item: qti21Numeric,
+			wantDrafts: []wantDraft{
+				{2, repo.QuestionTypeNumeric, "g in m/s² is _____", `[]`, `{"value":9.8,"tolerance":0.1}`, 1},
+			},
+		},
+		{
+			name: "match interaction",
+			file: "items/q5.xml",
+			item: qti21Match,
+			wantDrafts: []wantDraft{
+				{2, repo.QuestionTypeMatching, "Match the capitals.",
+					`{"prompts":[{"label":"1","text":"Nigeria"},{"label":"2","text":"Ghana"}],"choices":[{"label":"a","text":"Accra"},{"label":"b","text":"Abuja"}]}`,
+					`{"1":"b","2":"a"}`, 1},
+			},
+		},
+		{
+			name:       "more than one interaction",
+			file:       "items/q6.xml",
+			item:       qti21TwoInteractions,
+			wantIssues: []wantIssue{{3, SeverityError}},
+		},
+		{
+			name:       "not XML",
+			file:       "items/q7.xml",
+			item:       "<assessmentItem><itemBody>",
+			wantIssues: []wantIssue{{1, SeverityError}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := qtiPackage(t, map[string]string{
+				manifestFile: qtiManifest(qtiItemResource(tt.file)),
+				tt.file:      tt.item,
+			})
+
+			p, err := parseQTI(data)
+			if err != nil {
+				t.Fatalf("parseQTI() error = %v", err)
+			}
+			checkParsed(t, p.drafts, p.issues, tt.wantDrafts, tt.wantIssues)
+		})
+	}
+}
+
+func TestParseQTITest(t *testing.T) {
+	data := qtiPackage(t, map[string]string{
+		manifestFile: qtiManifest(
+			qtiItemResource("items/q1.xml"),
+			qtiItemResource("items/q4.xml"),
+			`<resource identifier="t1" type="imsqti_test_xmlv2p1" href="tests/t1.xml"/>`,
+			`<resource identifier="css" type="webcontent" href="style.css"/>`,
+		),
+		"items/q1.xml": qti21Choice,
+		"items/q4.xml": qti21Numeric,
+		"tests/t1.xml": qti21Test,
+	})
+
+	p, err := parseQTI(data)
+	if err != nil {
+		t.Fatalf("parseQTI() error = %v", err)
+	}
+	if len(p.drafts) != 2 || len(p.issues) != 0 {
+		t.Fatalf("parseQTI() = %d drafts, issues %+v; want 2 drafts and no issues", len(p.drafts), p.issues)
+	}
+
+	want := []testDraft{{
+		File:            "tests/t1.xml",
+		Line:            2,
+		Title:           "Mock paper",
+		DurationMinutes: 90,
+		Items: []testItem{
+			{Item: "items/q1.xml", Line: 5, Section: "Geography", Position: 1, Weight: 2},
+			{Item: "items/q4.xml", Line: 6, Section: "Science", Position: 1},
+		},
+	}}
+	if !reflect.DeepEqual(p.tests, want) {
+		t.Errorf("parseQTI() tests = %+v; want %+v", p.tests, want)
+	}
+}
+
+func TestParseQTIPackage(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      map[string]string
+		raw        []byte
+		wantErr    error
+		wantIssues []wantIssue
+	}{
+		{
+			name:    "not a zip",
+			raw:     []byte("not a zip"),
+			wantErr: ErrInvalidPackage,
+		},
+		{
+			name:    "no manifest",
+			files:   map[string]string{"items/q1.xml": qti21Choice},
+			wantErr: ErrInvalidPackage,
+		},
+		{
+			name:    "manifest is not XML",
+			files:   map[string]string{manifestFile: "<manifest>"},
+			wantErr: ErrInvalidPackage,
+		},
+		{
+			name:    "path outside the archive",
+			files:   map[string]string{manifestFile: qtiManifest(), "../evil.xml": qti21Choice},
+			wantErr: ErrInvalidPackage,
+		},
+		{
+			name:       "resource missing from the package",
+			files:      map[string]string{manifestFile: qtiManifest(qtiItemResource("items/missing.xml"))},
+			wantIssues: []wantIssue{{1, SeverityError}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := tt.raw
+			if data == nil {
+				data = qtiPackage(t, tt.files)
+			}
+
+			p, err := parseQTI(data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parseQTI() error = %v; want %v", err, tt.wantErr)
+			}
+			if err == nil {
+				checkParsed(t, p.drafts, p.issues, nil, tt.wantIssues)
+			}
+		})
+	}
+}
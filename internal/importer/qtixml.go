@@ -0,0 +1,349 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"html"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// node is a parsed or generated XML element, or a run of text when Name is
+// empty. QTI 3.0 names such as qti-choice-interaction and max-choices are
+// read as their QTI 2.1 forms, choiceInteraction and maxChoices, so the
+// rest of the package handles a single vocabulary.
+type node struct {
+	Name     string
+	Attrs    []xml.Attr
+	Children []*node
+	Text     string
+	Line     int
+	// Plain marks HTML content and packaging elements, which keep their
+	// names in every QTI version.
+	Plain bool
+}
+
+func (n *node) attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func (n *node) setAttr(name, value string) {
+	for i, a := range n.Attrs {
+		if a.Name.Local == name {
+			n.Attrs[i].Value = value
+			return
+		}
+	}
+	n.Attrs = append(n.Attrs, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+}
+
+// child returns the first child element with the given name.
+func (n *node) child(name string) *node {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func (n *node) children(name string) []*node {
+	var out []*node
+	for _, c := range n.Children {
+		if c.Name == name {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// find walks the tree depth first and returns every element with the name.
+func (n *node) find(name string) []*node {
+	var out []*node
+	for _, c := range n.Children {
+		if c.Name == name {
+			out = append(out, c)
+		}
+		out = append(out, c.find(name)...)
+	}
+	return out
+}
+
+// text is the element's character data with markup dropped.
+func (n *node) text() string {
+	if n.Name == "" {
+		return n.Text
+	}
+	var b strings.Builder
+	for _, c := range n.Children {
+		b.WriteString(c.text())
+	}
+	return b.String()
+}
+
+// values reads the <value> children of a correctResponse or defaultValue.
+func (n *node) values() []string {
+	if n == nil {
+		return nil
+	}
+	var out []string
+	for _, v := range n.children("value") {
+		out = append(out, strings.TrimSpace(v.text()))
+	}
+	return out
+}
+
+// el makes a QTI element, given in its 2.1 form.
+func el(name string, attrs ...string) *node {
+	n := &node{Name: name}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		n.setAttr(attrs[i], attrs[i+1])
+	}
+	return n
+}
+
+// plainEl makes an element that is written as named, such as a manifest
+// entry or a paragraph.
+func plainEl(name string, attrs ...string) *node {
+	n := el(name, attrs...)
+	n.Plain = true
+	return n
+}
+
+func (n *node) add(children ...*node) *node {
+	n.Children = append(n.Children, children...)
+	return n
+}
+
+func textNode(s string) *node {
+	return &node{Text: s}
+}
+
+// parseXML reads a document into a tree. HTML entities such as &nbsp; are
+// accepted since item bodies are often written by hand.
+func parseXML(data []byte) (*node, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Entity = xml.HTMLEntity
+
+	root := &node{}
+	stack := []*node{root}
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		parent := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			line, _ := dec.InputPos()
+			n := &node{Line: line}
+			qti := strings.HasPrefix(t.Name.Local, "qti-")
+			n.Name = t.Name.Local
+			if qti {
+				n.Name = camel(strings.TrimPrefix(t.Name.Local, "qti-"))
+			}
+			for _, a := range t.Attr {
+				if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+					continue
+				}
+				if qti {
+					a.Name.Local = camel(a.Name.Local)
+				}
+				a.Name.Space = ""
+				n.Attrs = append(n.Attrs, a)
+			}
+			parent.Children = append(parent.Children, n)
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			parent.Children = append(parent.Children, textNode(string(t)))
+		}
+	}
+
+	for _, c := range root.Children {
+		if c.Name != "" {
+			return c, nil
+		}
+	}
+	return nil, errors.New("document has no root element")
+}
+
+// parseFragment reads question HTML as XML. It fails on markup that is not
+// well formed, and the caller then treats the content as plain text.
+func parseFragment(s string) ([]*node, error) {
+	root, err := parseXML([]byte("<div>" + s + "</div>"))
+	if err != nil {
+		return nil, err
+	}
+	markPlain(root)
+	return root.Children, nil
+}
+
+func markPlain(n *node) {
+	n.Plain = true
+	for _, c := range n.Children {
+		markPlain(c)
+	}
+}
+
+func camel(s string) string {
+	parts := strings.Split(s, "-")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func kebab(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('-')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true,
+	"img": true, "input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// renderHTML writes content nodes back out as HTML.
+func renderHTML(nodes []*node) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		writeHTML(&b, n)
+	}
+	return b.String()
+}
+
+func writeHTML(b *strings.Builder, n *node) {
+	if n.Name == "" {
+		b.WriteString(html.EscapeString(n.Text))
+		return
+	}
+
+	b.WriteString("<" + n.Name)
+	for _, a := range n.Attrs {
+		b.WriteString(" " + a.Name.Local + `="` + html.EscapeString(a.Value) + `"`)
+	}
+	if voidElements[n.Name] {
+		b.WriteString("/>")
+		return
+	}
+	b.WriteString(">")
+	for _, c := range n.Children {
+		writeHTML(b, c)
+	}
+	b.WriteString("</" + n.Name + ">")
+}
+
+// contentText turns item content into a stem or option. Content that is only
+// text, or a single plain paragraph of it, becomes plain text so questions
+// exported from the bank come back as they were; anything richer stays HTML.
+func contentText(nodes []*node) string {
+	var elements []*node
+	for _, n := range nodes {
+		if n.Name == "" && strings.TrimSpace(n.Text) == "" {
+			continue
+		}
+		elements = append(elements, n)
+	}
+
+	if len(elements) == 1 && (elements[0].Name == "p" || elements[0].Name == "div") && len(elements[0].Attrs) == 0 {
+		elements = elements[0].Children
+	}
+
+	var b strings.Builder
+	for _, n := range elements {
+		switch n.Name {
+		case "":
+			b.WriteString(n.Text)
+		case "br":
+			b.WriteString("\n")
+		default:
+			return strings.TrimSpace(renderHTML(nodes))
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// content is the reverse of contentText: HTML that parses is embedded as
+// is, anything else is written as text. block wraps text in a paragraph.
+func content(s string, block bool) []*node {
+	if strings.Contains(s, "<") {
+		if nodes, err := parseFragment(s); err == nil {
+			return nodes
+		}
+	}
+
+	var nodes []*node
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			nodes = append(nodes, plainEl("br"))
+		}
+		nodes = append(nodes, textNode(line))
+	}
+	if !block {
+		return nodes
+	}
+	return []*node{plainEl("p").add(nodes...)}
+}
+
+// writeXML encodes a generated document. For QTI 3.0 the QTI elements and
+// their attributes take their qti- prefixed, hyphenated names.
+func writeXML(root *node, v3 bool) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	encodeNode(enc, root, v3)
+	enc.Flush()
+	buf.WriteString("\n")
+	return buf.Bytes()
+}
+
+func encodeNode(enc *xml.Encoder, n *node, v3 bool) {
+	if n.Name == "" {
+		enc.EncodeToken(xml.CharData(n.Text))
+		return
+	}
+
+	name := n.Name
+	rename := v3 && !n.Plain
+	if rename {
+		name = "qti-" + kebab(name)
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	for _, a := range n.Attrs {
+		if rename && a.Name.Local != "xmlns" {
+			a.Name.Local = kebab(a.Name.Local)
+		}
+		start.Attr = append(start.Attr, a)
+	}
+
+	enc.EncodeToken(start)
+	for _, c := range n.Children {
+		encodeNode(enc, c, v3)
+	}
+	enc.EncodeToken(start.End())
+}
@@ -0,0 +1,460 @@
+// Package importer where questions written for other learning systems are
+// read into the question bank, and banks and exams are exported as IMS QTI
+// packages
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/media"
+	"github.com/odundlaw/cbt-backend/internal/questions"
+	"github.com/odundlaw/cbt-backend/internal/richtext"
+	"github.com/odundlaw/cbt-backend/internal/similarity"
+)
+
+var (
+	ErrUnknownFormat     = errors.New(constants.ErrUnknownImportFormat)
+	ErrImportHasErrors   = errors.New(constants.ErrImportHasErrors)
+	ErrNothingToImport   = errors.New(constants.ErrNothingToImport)
+	ErrInvalidPackage    = errors.New(constants.ErrInvalidPackage)
+	ErrUnknownQTIVersion = errors.New(constants.ErrUnknownQTIVersion)
+	ErrExamNotFound      = errors.New(constants.ErrExamNotFound)
+	ErrInvalidSheet      = errors.New(constants.ErrInvalidSpreadsheet)
+	ErrInvalidMedia      = errors.New(constants.ErrInvalidMediaArchive)
+)
+
+type svc struct {
+	repo  *repo.Queries
+	db    *pgxpool.Pool
+	media media.Service
+}
+
+func NewService(repo *repo.Queries, db *pgxpool.Pool, media media.Service) Service {
+	return &svc{
+		repo:  repo,
+		db:    db,
+		media: media,
+	}
+}
+
+// parsed is everything read from one import before it is checked against
+// the bank.
+type parsed struct {
+	format Format
+	drafts []Draft
+	tests  []testDraft
+	issues []Issue
+	// files holds a package's entries by path, for the media drafts link to.
+	files map[string][]byte
+}
+
+// Preview parses the file and checks it against the bank without writing
+// anything.
+func (s *svc) Preview(ctx context.Context, params importParams) (previewResponse, error) {
+	p, err := parseText(params)
+	if err != nil {
+		return previewResponse{}, err
+	}
+	return s.check(ctx, s.repo, p, params.options())
+}
+
+// Import adds every question the preview would, in one transaction. A file
+// with errors is refused as a whole so a partial import never has to be
+// cleaned up; fix or remove the questions the preview reports and retry.
+func (s *svc) Import(ctx context.Context, createdBy int64, params importParams) (importResponse, error) {
+	p, err := parseText(params)
+	if err != nil {
+		return importResponse{}, err
+	}
+	return s.commit(ctx, createdBy, p, params.options())
+}
+
+// PreviewPackage is Preview for a QTI content package.
+func (s *svc) PreviewPackage(ctx context.Context, data []byte, opts importOptions) (previewResponse, error) {
+	p, err := parseQTI(data)
+	if err != nil {
+		return previewResponse{}, err
+	}
+	return s.check(ctx, s.repo, p, opts)
+}
+
+// ImportPackage is Import for a QTI content package. Media the items use is
+// stored alongside them, and each assessment test becomes a draft exam.
+func (s *svc) ImportPackage(ctx context.Context, createdBy int64, data []byte, opts importOptions) (importResponse, error) {
+	p, err := parseQTI(data)
+	if err != nil {
+		return importResponse{}, err
+	}
+	return s.commit(ctx, createdBy, p, opts)
+}
+
+// Template is a blank question sheet for one question type, with an
+// example row.
+func (s *svc) Template(params templateParams) ([]byte, error) {
+	return buildTemplate(params.Type, params.Format)
+}
+
+// PreviewSheet is Preview for a CSV or XLSX question sheet. Issues are
+// reported by spreadsheet row.
+func (s *svc) PreviewSheet(ctx context.Context, upload sheetUpload, opts importOptions) (previewResponse, error) {
+	p, err := parseSpreadsheet(upload)
+	if err != nil {
+		return previewResponse{}, err
+	}
+	return s.check(ctx, s.repo, p, opts)
+}
+
+// ImportSheet is Import for a question sheet. Images the rows name are
+// stored from the media zip.
+func (s *svc) ImportSheet(ctx context.Context, createdBy int64, upload sheetUpload, opts importOptions) (importResponse, error) {
+	p, err := parseSpreadsheet(upload)
+	if err != nil {
+		return importResponse{}, err
+	}
+	return s.commit(ctx, createdBy, p, opts)
+}
+
+func parseText(params importParams) (parsed, error) {
+	parse, ok := parsers[params.Format]
+	if !ok {
+		return parsed{}, fmt.Errorf("%w: %s", ErrUnknownFormat, params.Format)
+	}
+
+	drafts, issues := parse(params.Content)
+	return parsed{format: params.Format, drafts: drafts, issues: issues}, nil
+}
+
+func (s *svc) commit(ctx context.Context, createdBy int64, p parsed, opts importOptions) (importResponse, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return importResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	preview, err := s.check(ctx, qtx, p, opts)
+	if err != nil {
+		return importResponse{}, err
+	}
+
+	res := importResponse{Created: []int64{}, Skipped: []skippedQuestion{}, Issues: []Issue{}}
+	for _, issue := range preview.Issues {
+		if issue.Severity == SeverityError {
+			return importResponse{Issues: preview.Issues}, ErrImportHasErrors
+		}
+		res.Issues = append(res.Issues, issue)
+	}
+
+	// ids holds the bank question each draft ended up as, whether created
+	// now or already there.
+	ids := make([]int64, len(preview.Questions))
+	stored := map[string]int64{}
+
+	for i, d := range preview.Questions {
+		skip := skippedQuestion{File: d.File, Line: d.Line, DuplicateOf: d.DuplicateOf, DuplicateLine: d.DuplicateLine, DuplicateFile: d.DuplicateFile}
+		switch {
+		case d.original != 0:
+			ids[i] = ids[d.original-1]
+			res.Skipped = append(res.Skipped, skip)
+			continue
+		case d.DuplicateOf != nil && !opts.IncludeDuplicates:
+			ids[i] = *d.DuplicateOf
+			res.Skipped = append(res.Skipped, skip)
+			continue
+		}
+
+		for _, file := range d.Media {
+			id, ok := stored[file]
+			if !ok {
+				m, err := s.media.Store(ctx, qtx, createdBy, file, p.files[file])
+				if err != nil {
+					return importResponse{}, fmt.Errorf("%s: %w", file, err)
+				}
+				id = m.ID
+				stored[file] = id
+			}
+			linkMedia(&d, file, media.URL(id))
+		}
+
+		text, err := richtext.PrepareQuestion(questionContent(d))
+		if err != nil {
+			return importResponse{}, fmt.Errorf("%s line %d: %w", d.File, d.Line, err)
+		}
+
+		question, err := qtx.CreateQuestion(ctx, repo.CreateQuestionParams{
+			Type:        d.Type,
+			Stem:        text.Stem,
+			Options:     text.Options,
+			AnswerKey:   d.AnswerKey,
+			Explanation: pgtype.Text{String: text.Explanation, Valid: text.Explanation != ""},
+			Marks:       d.Marks,
+			CreatedBy:   createdBy,
+			Subject:     pgtype.Text{String: d.Subject, Valid: d.Subject != ""},
+			Topic:       pgtype.Text{String: d.Topic, Valid: d.Topic != ""},
+		})
+		if err != nil {
+			return importResponse{}, fmt.Errorf("%s line %d: %w", d.File, d.Line, err)
+		}
+		if err := questions.SaveVersion(ctx, qtx, question.ID, createdBy, false, ""); err != nil {
+			return importResponse{}, err
+		}
+		if _, err := similarity.Index(ctx, qtx, question); err != nil {
+			return importResponse{}, err
+		}
+		ids[i] = question.ID
+		res.Created = append(res.Created, question.ID)
+	}
+
+	for _, t := range preview.Tests {
+		examID, err := createTestExam(ctx, qtx, createdBy, t, preview.Questions, ids)
+		if err != nil {
+			return importResponse{}, err
+		}
+		res.Exams = append(res.Exams, examID)
+	}
+
+	if len(res.Created) == 0 && len(res.Exams) == 0 {
+		return res, ErrNothingToImport
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return importResponse{}, err
+	}
+
+	return res, nil
+}
+
+// createTestExam adds an imported assessment test as a draft exam using the
+// bank questions its items became.
+func createTestExam(ctx context.Context, q *repo.Queries, createdBy int64, t testDraft, drafts []Draft, ids []int64) (int64, error) {
+	exam, err := q.CreateExam(ctx, repo.CreateExamParams{
+		Title:           t.Title,
+		DurationMinutes: t.DurationMinutes,
+		CreatedBy:       createdBy,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	index := make(map[string]int, len(drafts))
+	for i, d := range drafts {
+		index[d.File] = i
+	}
+
+	for _, item := range t.Items {
+		i, ok := index[item.Item]
+		if !ok {
+			continue
+		}
+
+		marks := pgtype.Float8{}
+		if item.Weight != 0 && item.Weight != 1 {
+			marks = pgtype.Float8{Float64: drafts[i].Marks * item.Weight, Valid: true}
+		}
+
+		if _, err := q.AddExamQuestion(ctx, repo.AddExamQuestionParams{
+			ExamID:     exam.ID,
+			QuestionID: ids[i],
+			Section:    item.Section,
+			Position:   item.Position,
+			Marks:      marks,
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	return exam.ID, nil
+}
+
+func (s *svc) check(ctx context.Context, q *repo.Queries, p parsed, opts importOptions) (previewResponse, error) {
+	issues := p.issues
+
+	// Keys are checked with the same graders that will mark them, so a
+	// parser slip shows up here rather than at grading time.
+	valid := make([]Draft, 0, len(p.drafts))
+	for _, d := range p.drafts {
+		grader, err := questions.GraderFor(d.Type)
+		if err == nil {
+			err = grader.ValidateKey(d.AnswerKey)
+		}
+		if err != nil {
+			issues = append(issues, Issue{File: d.File, Line: d.Line, Severity: SeverityError, Message: err.Error()})
+			continue
+		}
+
+		// Media is held to the same rules as an upload, so a file the
+		// import would refuse is reported in the preview.
+		if bad := checkMedia(d, p.files); len(bad) > 0 {
+			issues = append(issues, bad...)
+			continue
+		}
+
+		// Content is sanitized when stored, once media links point at the
+		// stored files, but math that will not parse is reported now.
+		if _, err := richtext.PrepareQuestion(questionContent(d)); err != nil {
+			issues = append(issues, Issue{File: d.File, Line: d.Line, Severity: SeverityError, Message: err.Error()})
+			continue
+		}
+
+		if d.Subject == "" {
+			d.Subject, d.Topic = opts.Subject, opts.Topic
+		}
+		valid = append(valid, d)
+	}
+
+	if err := markDuplicates(ctx, q, valid); err != nil {
+		return previewResponse{}, err
+	}
+
+	if err := markSimilar(ctx, q, valid); err != nil {
+		return previewResponse{}, err
+	}
+
+	tests, found := checkTests(p.tests, valid)
+	issues = append(issues, found...)
+
+	sortIssues(issues)
+
+	res := previewResponse{
+		Format:    p.format,
+		Questions: valid,
+		Tests:     tests,
+		Issues:    issues,
+	}
+	if res.Issues == nil {
+		res.Issues = []Issue{}
+	}
+
+	res.Summary.Questions = len(valid)
+	for _, d := range valid {
+		if d.DuplicateOf != nil || d.original != 0 {
+			res.Summary.Duplicates++
+		} else if len(d.SimilarTo) > 0 {
+			res.Summary.NearDuplicates++
+		}
+	}
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			res.Summary.Errors++
+		} else {
+			res.Summary.Warnings++
+		}
+	}
+
+	return res, nil
+}
+
+func questionContent(d Draft) richtext.Question {
+	return richtext.Question{Stem: d.Stem, Options: d.Options, Explanation: d.Explanation}
+}
+
+func checkMedia(d Draft, files map[string][]byte) []Issue {
+	var issues []Issue
+	for _, file := range d.Media {
+		if _, err := media.Check(files[file]); err != nil {
+			issues = append(issues, Issue{File: d.File, Line: d.Line, Severity: SeverityError, Message: fmt.Sprintf("%s: %v", file, err)})
+		}
+	}
+	return issues
+}
+
+// checkTests drops test items whose question could not be read, and repeats
+// of an item, and reports them. A test left with no items is an error.
+func checkTests(tests []testDraft, drafts []Draft) ([]testDraft, []Issue) {
+	var issues []Issue
+
+	have := make(map[string]bool, len(drafts))
+	for _, d := range drafts {
+		have[d.File] = true
+	}
+
+	out := make([]testDraft, 0, len(tests))
+	for _, t := range tests {
+		items := make([]testItem, 0, len(t.Items))
+		used := map[string]bool{}
+		for _, item := range t.Items {
+			if !have[item.Item] {
+				issues = append(issues, Issue{File: t.File, Line: item.Line, Severity: SeverityWarning, Message: fmt.Sprintf("item %s is left out of the exam", item.Item)})
+				continue
+			}
+			if used[item.Item] {
+				issues = append(issues, Issue{File: t.File, Line: item.Line, Severity: SeverityWarning, Message: fmt.Sprintf("item %s is already in the exam", item.Item)})
+				continue
+			}
+			used[item.Item] = true
+			items = append(items, item)
+		}
+		if len(items) == 0 {
+			issues = append(issues, Issue{File: t.File, Line: t.Line, Severity: SeverityError, Message: "test has no items that can be imported"})
+			continue
+		}
+		t.Items = items
+		out = append(out, t)
+	}
+
+	return out, issues
+}
+
+// markDuplicates flags drafts whose stem is already in the bank or earlier
+// in the same import. Stems match with case and spacing ignored.
+func markDuplicates(ctx context.Context, q *repo.Queries, drafts []Draft) error {
+	stems := make([]string, 0, len(drafts))
+	first := map[string]int{}
+	for i := range drafts {
+		stem := normalizeStem(drafts[i].Stem)
+		if j, ok := first[stem]; ok {
+			drafts[i].DuplicateLine = drafts[j].Line
+			drafts[i].DuplicateFile = drafts[j].File
+			drafts[i].original = j + 1
+			continue
+		}
+		first[stem] = i
+		stems = append(stems, stem)
+	}
+
+	rows, err := q.FindQuestionsByNormalizedStem(ctx, stems)
+	if err != nil {
+		return err
+	}
+
+	existing := map[string]int64{}
+	for _, row := range rows {
+		if _, ok := existing[row.NormalizedStem]; !ok {
+			existing[row.NormalizedStem] = row.ID
+		}
+	}
+
+	for i := range drafts {
+		if id, ok := existing[normalizeStem(drafts[i].Stem)]; ok {
+			drafts[i].DuplicateOf = &id
+		}
+	}
+
+	return nil
+}
+
+// markSimilar lists, for drafts that are not exact duplicates, the bank
+// questions their text is close to.
+func markSimilar(ctx context.Context, q *repo.Queries, drafts []Draft) error {
+	for i := range drafts {
+		d := &drafts[i]
+		if d.DuplicateOf != nil || d.original != 0 {
+			continue
+		}
+
+		matches, err := similarity.Find(ctx, q, d.Stem, d.Options)
+		if err != nil {
+			return err
+		}
+		d.SimilarTo = matches
+	}
+
+	return nil
+}
@@ -0,0 +1,476 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"html"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+// column is one heading of a question sheet.
+type column struct {
+	Name    string
+	Help    string
+	Example string
+}
+
+// templateOptions and templatePairs are how many option and matching
+// columns a template starts with. Authors can add more: any option_<letter>,
+// prompt_<n> or match_<n> heading is read.
+const (
+	templateOptions = 5
+	templatePairs   = 4
+)
+
+// templateColumns lists a template's headings, with help text and an
+// example row, for one question type.
+func templateColumns(t repo.QuestionType) []column {
+	cols := []column{
+		{"type", "Question type. Leave as " + string(t) + ".", string(t)},
+		{"subject", "Subject, e.g. Mathematics. Optional.", "Mathematics"},
+		{"topic", "Topic within the subject. Optional.", "Geometry"},
+		{"stem", "The question. Write [image: name.png] to show an image from the media zip inside the text.", ""},
+		{"image", "File name of an image shown under the question, from the media zip. Optional.", ""},
+	}
+
+	switch t {
+	case repo.QuestionTypeSingleChoice, repo.QuestionTypeMultipleChoice:
+		examples := []string{"3", "4", "5", "6", "7"}
+		for i := 0; i < templateOptions; i++ {
+			label := strings.ToLower(choiceLabel(i))
+			help := "Option " + choiceLabel(i) + ". Leave later options empty if there are fewer."
+			cols = append(cols, column{"option_" + label, help, examples[i]})
+		}
+		if t == repo.QuestionTypeSingleChoice {
+			cols[3].Example = "How many sides does a square have?"
+			cols = append(cols, column{"answer", "Letter of the correct option, e.g. B.", "B"})
+		} else {
+			cols[3].Example = "Which of these numbers are odd?"
+			cols = append(cols, column{"answer", "Letters of every correct option separated by commas, e.g. A,C.", "A,C,E"})
+		}
+	case repo.QuestionTypeTrueFalse:
+		cols[3].Example = "A triangle has three sides."
+		cols = append(cols, column{"answer", "TRUE or FALSE.", "TRUE"})
+	case repo.QuestionTypeNumeric:
+		cols[3].Example = "What is the value of pi to two decimal places?"
+		cols = append(cols,
+			column{"answer", "The correct number.", "3.14"},
+			column{"tolerance", "How far an answer may be from the correct number and still be right. Optional, 0 by default.", "0.005"},
+		)
+	case repo.QuestionTypeMatching:
+		cols[3].Example = "Match each shape to its number of sides."
+		prompts := []string{"Triangle", "Square", "Pentagon", ""}
+		matches := []string{"3", "4", "5", "6"}
+		for i := 0; i < templatePairs; i++ {
+			n := strconv.Itoa(i + 1)
+			cols = append(cols, column{"prompt_" + n, "Item " + n + " to be matched. Optional after the first.", prompts[i]})
+		}
+		for i := 0; i < templatePairs; i++ {
+			n := strconv.Itoa(i + 1)
+			cols = append(cols, column{"match_" + n, "The answer for prompt_" + n + ". Without a prompt it is an extra wrong answer.", matches[i]})
+		}
+	case repo.QuestionTypeShortAnswer:
+		cols[3].Example = "Name the longest side of a right-angled triangle."
+		cols = append(cols,
+			column{"answer", "Accepted answers separated by |, e.g. hypotenuse|the hypotenuse.", "hypotenuse|the hypotenuse"},
+			column{"guidance", "Notes for the marker. Optional.", ""},
+		)
+	case repo.QuestionTypeEssay:
+		cols[3].Example = "Explain why the angles of a triangle add up to 180 degrees."
+		cols = append(cols, column{"guidance", "What the marker should look for. Optional.", "Uses parallel lines and alternate angles."})
+	}
+
+	return append(cols,
+		column{"marks", "Marks for a correct answer. Optional, 1 by default.", "1"},
+		column{"explanation", "Shown to candidates after the exam. Optional.", ""},
+	)
+}
+
+// buildTemplate writes a question type's template. A workbook has a second
+// sheet describing each column; a CSV only has the heading and example
+// rows.
+func buildTemplate(t repo.QuestionType, format Format) ([]byte, error) {
+	cols := templateColumns(t)
+	heading, example := make([]string, len(cols)), make([]string, len(cols))
+	help := [][]string{{"column", "what to enter"}}
+	for i, c := range cols {
+		heading[i], example[i] = c.Name, c.Example
+		help = append(help, []string{c.Name, c.Help})
+	}
+
+	if format == FormatXLSX {
+		return writeXLSX([]sheet{
+			{Name: "Questions", Rows: [][]string{heading, example}},
+			{Name: "Help", Rows: help},
+		})
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.WriteAll([][]string{heading, example})
+	return buf.Bytes(), w.Error()
+}
+
+// parseSpreadsheet reads a question sheet, telling XLSX from CSV by its zip
+// signature.
+func parseSpreadsheet(upload sheetUpload) (parsed, error) {
+	var rows [][]string
+	var err error
+	format := FormatCSV
+	if bytes.HasPrefix(upload.Sheet, []byte("PK\x03\x04")) {
+		format = FormatXLSX
+		rows, err = readXLSX(upload.Sheet)
+	} else {
+		rows, err = readCSV(upload.Sheet)
+	}
+	if err != nil {
+		return parsed{}, err
+	}
+
+	var files map[string][]byte
+	if len(upload.Media) > 0 {
+		files, err = readPackage(upload.Media, unpackedLimit())
+		if err != nil {
+			return parsed{}, fmt.Errorf("%w: %v", ErrInvalidMedia, err)
+		}
+	}
+
+	drafts, issues := parseSheet(rows, upload.Type, files)
+	return parsed{format: format, drafts: drafts, issues: issues, files: files}, nil
+}
+
+// readCSV reads a CSV as saved by Excel: with or without a byte order mark,
+// and separated by semicolons in locales that use a decimal comma.
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	first, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(first, []byte(";")) > bytes.Count(first, []byte(",")) {
+		r.Comma = ';'
+	}
+
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSheet, err)
+	}
+	return rows, nil
+}
+
+var sheetImage = regexp.MustCompile(`\[image:\s*([^\]]+?)\s*\]`)
+
+// sheetRow reads one row by heading.
+type sheetRow struct {
+	line   int
+	values map[string]string
+	issues []Issue
+}
+
+func (r *sheetRow) get(name string) string {
+	return strings.TrimSpace(r.values[name])
+}
+
+func (r *sheetRow) fail(format string, args ...any) {
+	r.issues = append(r.issues, Issue{Line: r.line, Severity: SeverityError, Message: fmt.Sprintf(format, args...)})
+}
+
+// numbered returns the suffixes of headings such as option_a, option_b, ...
+// or prompt_1, prompt_2, ..., in order.
+func (r *sheetRow) numbered(prefix string, less func(a, b string) bool) []string {
+	var keys []string
+	for name := range r.values {
+		if suffix, ok := strings.CutPrefix(name, prefix); ok && suffix != "" {
+			keys = append(keys, suffix)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+	return keys
+}
+
+func byNumber(a, b string) bool {
+	x, _ := strconv.Atoi(a)
+	y, _ := strconv.Atoi(b)
+	return x < y
+}
+
+func byLetter(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// parseSheet reads question rows under a heading row. defaultType fills in
+// rows with no type. Images are looked up by file name in media, the
+// entries of the zip uploaded with the sheet.
+func parseSheet(rows [][]string, defaultType repo.QuestionType, media map[string][]byte) ([]Draft, []Issue) {
+	var drafts []Draft
+	var issues []Issue
+
+	heading := -1
+	for i, row := range rows {
+		if hasValue(row) {
+			heading = i
+			break
+		}
+	}
+	if heading < 0 {
+		return nil, []Issue{{Line: 1, Severity: SeverityError, Message: "sheet is empty"}}
+	}
+
+	names := make([]string, len(rows[heading]))
+	known := map[string]bool{}
+	for _, c := range templateColumns(repo.QuestionTypeSingleChoice) {
+		known[c.Name] = true
+	}
+	for _, name := range []string{"tolerance", "guidance"} {
+		known[name] = true
+	}
+	for i, h := range rows[heading] {
+		name := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(h)), " ", "_")
+		names[i] = name
+		if name == "" || known[name] || strings.HasPrefix(name, "option_") || strings.HasPrefix(name, "prompt_") || strings.HasPrefix(name, "match_") {
+			continue
+		}
+		issues = append(issues, Issue{Line: heading + 1, Severity: SeverityWarning, Message: fmt.Sprintf("column %s is not used", h)})
+	}
+
+	// Image names match regardless of case or folder inside the zip.
+	files := map[string]string{}
+	for name := range media {
+		files[strings.ToLower(path.Base(name))] = name
+	}
+
+	for i := heading + 1; i < len(rows); i++ {
+		if !hasValue(rows[i]) {
+			continue
+		}
+
+		r := &sheetRow{line: i + 1, values: map[string]string{}}
+		for j, v := range rows[i] {
+			if j < len(names) && names[j] != "" {
+				r.values[names[j]] = v
+			}
+		}
+
+		if d, ok := sheetDraft(r, defaultType, files); ok {
+			drafts = append(drafts, d)
+		}
+		issues = append(issues, r.issues...)
+	}
+
+	return drafts, issues
+}
+
+func hasValue(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func sheetDraft(r *sheetRow, defaultType repo.QuestionType, files map[string]string) (Draft, bool) {
+	d := Draft{Line: r.line, Subject: r.get("subject"), Topic: r.get("topic"), Options: encode([]option{}), Marks: 1}
+
+	d.Type = repo.QuestionType(strings.ReplaceAll(strings.ToLower(r.get("type")), " ", "_"))
+	if d.Type == "" {
+		d.Type = defaultType
+	}
+	if d.Type == "" {
+		r.fail("type is empty")
+		return d, false
+	}
+
+	if v := r.get("marks"); v != "" {
+		marks, err := strconv.ParseFloat(v, 64)
+		if err != nil || marks <= 0 {
+			r.fail("marks %q is not a positive number", v)
+		}
+		d.Marks = marks
+	}
+
+	// image links a file from the media zip. Its src is the path in the zip
+	// until the import stores it.
+	image := func(column, name string) string {
+		file, ok := files[strings.ToLower(path.Base(name))]
+		if !ok {
+			r.fail("%s: image %s is not in the media zip", column, name)
+			return ""
+		}
+		if !contains(d.Media, file) {
+			d.Media = append(d.Media, file)
+		}
+		return `<img src="` + html.EscapeString(file) + `" alt=""/>`
+	}
+	toHTML := func(column, v string) string {
+		escaped := strings.ReplaceAll(html.EscapeString(v), "\n", "<br/>")
+		return sheetImage.ReplaceAllStringFunc(escaped, func(m string) string {
+			return image(column, html.UnescapeString(sheetImage.FindStringSubmatch(m)[1]))
+		})
+	}
+	// text reads a cell as question text, which becomes HTML when it shows
+	// images.
+	text := func(column string) string {
+		v := r.get(column)
+		if !sheetImage.MatchString(v) {
+			return v
+		}
+		return toHTML(column, v)
+	}
+
+	d.Stem = text("stem")
+	if d.Stem == "" {
+		r.fail("stem is empty")
+		return d, false
+	}
+	if v := r.get("image"); v != "" {
+		d.Media = nil
+		d.Stem = "<p>" + toHTML("stem", r.get("stem")) + "</p><p>" + image("image", v) + "</p>"
+	}
+	d.Explanation = text("explanation")
+
+	answer := r.get("answer")
+	switch d.Type {
+	case repo.QuestionTypeSingleChoice, repo.QuestionTypeMultipleChoice:
+		var opts []option
+		gap := ""
+		for _, suffix := range r.numbered("option_", byLetter) {
+			v := text("option_" + suffix)
+			if v == "" {
+				if gap == "" {
+					gap = "option_" + suffix
+				}
+				continue
+			}
+			if gap != "" {
+				r.fail("option_%s follows the empty %s", suffix, gap)
+				return d, false
+			}
+			opts = append(opts, option{Label: strings.ToUpper(suffix), Text: v})
+		}
+		if len(opts) < 2 {
+			r.fail("question needs at least two options")
+			return d, false
+		}
+		d.Options = encode(opts)
+
+		var right []string
+		for _, label := range strings.Split(answer, ",") {
+			label = strings.ToUpper(strings.TrimSpace(label))
+			if label == "" {
+				continue
+			}
+			if !hasOption(opts, label) {
+				r.fail("answer %s is not one of the options", label)
+				return d, false
+			}
+			right = append(right, label)
+		}
+		switch {
+		case len(right) == 0:
+			r.fail("answer is empty")
+			return d, false
+		case d.Type == repo.QuestionTypeSingleChoice && len(right) > 1:
+			r.fail("single choice questions have one answer, use multiple_choice for more")
+			return d, false
+		case d.Type == repo.QuestionTypeSingleChoice:
+			d.AnswerKey = encode(right[0])
+		default:
+			d.AnswerKey = encode(right)
+		}
+
+	case repo.QuestionTypeTrueFalse:
+		switch strings.ToLower(answer) {
+		case "true", "t", "yes", "1":
+			d.AnswerKey = encode(true)
+		case "false", "f", "no", "0":
+			d.AnswerKey = encode(false)
+		default:
+			r.fail("answer %q is not TRUE or FALSE", answer)
+			return d, false
+		}
+
+	case repo.QuestionTypeNumeric:
+		value, err := strconv.ParseFloat(answer, 64)
+		if err != nil {
+			r.fail("answer %q is not a number", answer)
+			return d, false
+		}
+		key := numericKey{Value: value}
+		if v := r.get("tolerance"); v != "" {
+			key.Tolerance, err = strconv.ParseFloat(v, 64)
+			if err != nil || key.Tolerance < 0 {
+				r.fail("tolerance %q is not a number of zero or more", v)
+				return d, false
+			}
+		}
+		d.AnswerKey = encode(key)
+
+	case repo.QuestionTypeMatching:
+		var pairs [][2]string
+		prompts := map[string]bool{}
+		for _, n := range r.numbered("prompt_", byNumber) {
+			prompt := text("prompt_" + n)
+			if prompt == "" {
+				continue
+			}
+			prompts[n] = true
+			match := text("match_" + n)
+			if match == "" {
+				r.fail("prompt_%s has no match_%s", n, n)
+				return d, false
+			}
+			pairs = append(pairs, [2]string{prompt, match})
+		}
+		for _, n := range r.numbered("match_", byNumber) {
+			if match := text("match_" + n); match != "" && !prompts[n] {
+				pairs = append(pairs, [2]string{"", match})
+			}
+		}
+		opts, key, err := pairUp(pairs)
+		if err != nil {
+			r.fail("%s", err.Error())
+			return d, false
+		}
+		d.Options = encode(opts)
+		d.AnswerKey = encode(key)
+
+	case repo.QuestionTypeShortAnswer:
+		key := manualKey{Guidance: r.get("guidance")}
+		for _, a := range strings.Split(answer, "|") {
+			if a = strings.TrimSpace(a); a != "" {
+				key.Accepted = append(key.Accepted, a)
+			}
+		}
+		d.AnswerKey = encode(key)
+
+	case repo.QuestionTypeEssay:
+		d.AnswerKey = encode(manualKey{Guidance: r.get("guidance")})
+
+	default:
+		r.fail("type %q is not a question type", d.Type)
+		return d, false
+	}
+
+	return d, len(r.issues) == 0
+}
+
+func hasOption(opts []option, label string) bool {
+	for _, opt := range opts {
+		if opt.Label == label {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package importer
+
+import (
+	"context"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+type Service interface {
+	Preview(ctx context.Context, params importParams) (previewResponse, error)
+	Import(ctx context.Context, createdBy int64, params importParams) (importResponse, error)
+	PreviewPackage(ctx context.Context, data []byte, opts importOptions) (previewResponse, error)
+	ImportPackage(ctx context.Context, createdBy int64, data []byte, opts importOptions) (importResponse, error)
+	Template(params templateParams) ([]byte, error)
+	PreviewSheet(ctx context.Context, upload sheetUpload, opts importOptions) (previewResponse, error)
+	ImportSheet(ctx context.Context, createdBy int64, upload sheetUpload, opts importOptions) (importResponse, error)
+	ExportQuestions(ctx context.Context, filter ExportFilter, version string) (exportResponse, error)
+	ExportExam(ctx context.Context, examID int64, version string) (exportResponse, error)
+}
+
+type importParams struct {
+	Format  Format `json:"format" validate:"required,oneof=gift aiken moodle_xml"`
+	Content string `json:"content" validate:"required,max=2000000"`
+	// Subject and Topic file questions the source does not put in a
+	// category.
+	Subject string `json:"subject" validate:"max=100"`
+	Topic   string `json:"topic" validate:"max=100"`
+	// IncludeDuplicates imports questions whose stem is already in the bank
+	// instead of skipping them.
+	IncludeDuplicates bool `json:"include_duplicates"`
+}
+
+func (p importParams) options() importOptions {
+	return importOptions{Subject: p.Subject, Topic: p.Topic, IncludeDuplicates: p.IncludeDuplicates}
+}
+
+// importOptions are the settings shared by every import. Package uploads
+// take them from the query string.
+type importOptions struct {
+	Subject           string `validate:"max=100"`
+	Topic             string `validate:"max=100"`
+	IncludeDuplicates bool
+}
+
+// templateParams picks the question sheet template to download.
+type templateParams struct {
+	Type   repo.QuestionType `validate:"required,oneof=single_choice multiple_choice true_false numeric matching short_answer essay"`
+	Format Format            `validate:"required,oneof=csv xlsx"`
+}
+
+// sheetUpload is a CSV or XLSX question sheet and the optional zip of
+// images its rows name. Type is used for rows that leave the type column
+// empty.
+type sheetUpload struct {
+	Sheet []byte
+	Media []byte
+	Type  repo.QuestionType `validate:"omitempty,oneof=single_choice multiple_choice true_false numeric matching short_answer essay"`
+}
+
+// ExportFilter picks the bank questions to export. Zero values match
+// everything.
+type ExportFilter struct {
+	Subject string
+	Topic   string
+}
+
+type importSummary struct {
+	Questions  int `json:"questions"`
+	Duplicates int `json:"duplicates"`
+	// NearDuplicates counts questions close to ones already in the bank.
+	NearDuplicates int `json:"near_duplicates"`
+	Errors         int `json:"errors"`
+	Warnings       int `json:"warnings"`
+}
+
+// previewResponse is what an import would do. Questions lists everything
+// that parsed, duplicates included; Issues lists what could not be carried
+// over, by line.
+type previewResponse struct {
+	Format    Format        `json:"format"`
+	Summary   importSummary `json:"summary"`
+	Questions []Draft       `json:"questions"`
+	Tests     []testDraft   `json:"tests,omitempty"`
+	Issues    []Issue       `json:"issues"`
+}
+
+type skippedQuestion struct {
+	File          string `json:"file,omitempty"`
+	Line          int    `json:"line"`
+	DuplicateOf   *int64 `json:"duplicate_of,omitempty"`
+	DuplicateLine int    `json:"duplicate_line,omitempty"`
+	DuplicateFile string `json:"duplicate_file,omitempty"`
+}
+
+// importResponse lists the questions added and the duplicates left out.
+// Issues holds the warnings for what was added, or the full list when the
+// import was refused.
+type importResponse struct {
+	Created []int64           `json:"created"`
+	Exams   []int64           `json:"exams,omitempty"`
+	Skipped []skippedQuestion `json:"skipped"`
+	Issues  []Issue           `json:"issues"`
+}
+
+// exportResponse is a finished package. Skipped lists the questions that
+// could not be written, by ID, with the reason.
+type exportResponse struct {
+	Package []byte
+	Skipped map[int64]string
+}
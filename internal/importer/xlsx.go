@@ -0,0 +1,227 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// The spreadsheet code reads and writes the small part of the Office Open
+// XML format that question sheets use: text and number cells on plain
+// worksheets, without styles or formulas.
+
+// sheet is a worksheet's name and rows. Row i is spreadsheet row i+1, and
+// cells past the last filled one are left off.
+type sheet struct {
+	Name string
+	Rows [][]string
+}
+
+// readXLSX returns the first worksheet of a workbook.
+func readXLSX(data []byte) ([][]string, error) {
+	files, err := readPackage(data, unpackedLimit())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSheet, err)
+	}
+
+	sheetFile := "xl/worksheets/sheet1.xml"
+	if wb, err := parseXML(files["xl/workbook.xml"]); err == nil {
+		if first := wb.find("sheet"); len(first) > 0 {
+			rid := first[0].attr("id")
+			if rels, err := parseXML(files["xl/_rels/workbook.xml.rels"]); err == nil {
+				for _, rel := range rels.find("Relationship") {
+					if rel.attr("Id") == rid {
+						sheetFile = path.Clean(path.Join("xl", strings.TrimPrefix(rel.attr("Target"), "/xl/")))
+					}
+				}
+			}
+		}
+	}
+
+	var shared []string
+	if data, ok := files["xl/sharedStrings.xml"]; ok {
+		sst, err := parseXML(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: shared strings: %v", ErrInvalidSheet, err)
+		}
+		for _, si := range sst.children("si") {
+			shared = append(shared, cellText(si))
+		}
+	}
+
+	ws, ok := files[sheetFile]
+	if !ok {
+		return nil, fmt.Errorf("%w: workbook has no worksheet", ErrInvalidSheet)
+	}
+	root, err := parseXML(ws)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSheet, err)
+	}
+
+	var rows [][]string
+	for _, row := range root.find("row") {
+		r, err := strconv.Atoi(row.attr("r"))
+		if err != nil || r < 1 {
+			r = len(rows) + 1
+		}
+		for len(rows) < r {
+			rows = append(rows, nil)
+		}
+
+		var cells []string
+		for _, c := range row.children("c") {
+			col := len(cells)
+			if ref := c.attr("r"); ref != "" {
+				col = columnIndex(ref)
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+
+			v := ""
+			if vn := c.child("v"); vn != nil {
+				v = vn.text()
+			}
+			switch c.attr("t") {
+			case "s":
+				i, err := strconv.Atoi(v)
+				if err != nil || i < 0 || i >= len(shared) {
+					return nil, fmt.Errorf("%w: cell %s has a bad shared string", ErrInvalidSheet, c.attr("r"))
+				}
+				v = shared[i]
+			case "inlineStr":
+				if is := c.child("is"); is != nil {
+					v = cellText(is)
+				}
+			case "b":
+				v = strings.ToUpper(strconv.FormatBool(v == "1"))
+			case "", "n":
+				// Numbers are stored as doubles, so 0.1 may read as
+				// 0.10000000000000001.
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					v = formatFloat(f)
+				}
+			}
+			cells[col] = v
+		}
+		rows[r-1] = cells
+	}
+
+	return rows, nil
+}
+
+// cellText joins the text runs of a shared or inline string, leaving out
+// phonetic hints.
+func cellText(n *node) string {
+	var b strings.Builder
+	for _, c := range n.Children {
+		switch c.Name {
+		case "t":
+			b.WriteString(c.text())
+		case "r":
+			b.WriteString(cellText(c))
+		}
+	}
+	return b.String()
+}
+
+// columnIndex turns a cell reference such as "AB12" into the zero-based
+// column, 27.
+func columnIndex(ref string) int {
+	col := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+	}
+	return col - 1
+}
+
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// writeXLSX builds a workbook with one worksheet per sheet. Every cell is
+// written as text so answers such as "1" or "TRUE" come back as typed.
+func writeXLSX(sheets []sheet) ([]byte, error) {
+	const (
+		pkgRels = "http://schemas.openxmlformats.org/package/2006/relationships"
+		docRels = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+		mainNS  = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+		typesNS = "http://schemas.openxmlformats.org/package/2006/content-types"
+		relType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/"
+		sheetCT = "application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"
+		bookCT  = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"
+		relsCT  = "application/vnd.openxmlformats-package.relationships+xml"
+	)
+
+	types := plainEl("Types", "xmlns", typesNS).add(
+		plainEl("Default", "Extension", "rels", "ContentType", relsCT),
+		plainEl("Default", "Extension", "xml", "ContentType", "application/xml"),
+		plainEl("Override", "PartName", "/xl/workbook.xml", "ContentType", bookCT),
+	)
+	rels := plainEl("Relationships", "xmlns", pkgRels).add(
+		plainEl("Relationship", "Id", "rId1", "Type", relType+"officeDocument", "Target", "xl/workbook.xml"),
+	)
+	bookSheets := plainEl("sheets")
+	book := plainEl("workbook", "xmlns", mainNS, "xmlns:r", docRels).add(bookSheets)
+	bookRels := plainEl("Relationships", "xmlns", pkgRels)
+
+	files := map[string][]byte{}
+	var names []string
+	for i, s := range sheets {
+		n := strconv.Itoa(i + 1)
+		file := "worksheets/sheet" + n + ".xml"
+
+		types.add(plainEl("Override", "PartName", "/xl/"+file, "ContentType", sheetCT))
+		bookSheets.add(plainEl("sheet", "name", s.Name, "sheetId", n, "r:id", "rId"+n))
+		bookRels.add(plainEl("Relationship", "Id", "rId"+n, "Type", relType+"worksheet", "Target", file))
+
+		data := plainEl("sheetData")
+		for r, row := range s.Rows {
+			rn := strconv.Itoa(r + 1)
+			xr := plainEl("row", "r", rn)
+			for c, v := range row {
+				if v == "" {
+					continue
+				}
+				xr.add(plainEl("c", "r", columnName(c)+rn, "t", "inlineStr").add(
+					plainEl("is").add(plainEl("t", "xml:space", "preserve").add(textNode(v))),
+				))
+			}
+			data.add(xr)
+		}
+		files["xl/"+file] = writeXML(plainEl("worksheet", "xmlns", mainNS).add(data), false)
+		names = append(names, "xl/"+file)
+	}
+
+	files["[Content_Types].xml"] = writeXML(types, false)
+	files["_rels/.rels"] = writeXML(rels, false)
+	files["xl/workbook.xml"] = writeXML(book, false)
+	files["xl/_rels/workbook.xml.rels"] = writeXML(bookRels, false)
+	names = append([]string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels"}, names...)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		f, err := zw.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Write(files[name]); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
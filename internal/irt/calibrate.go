@@ -0,0 +1,532 @@
+package irt
+
+import "math"
+
+// Model is the logistic model items are calibrated under.
+type Model string
+
+const (
+	// Model1PL gives every item the same discrimination.
+	Model1PL Model = "1pl"
+	Model2PL Model = "2pl"
+	Model3PL Model = "3pl"
+)
+
+// Observation is one scored answer in a respondent's pattern. Item indexes
+// the items being calibrated; items a respondent did not see are left out.
+type Observation struct {
+	Item    int
+	Correct bool
+}
+
+type CalibrationOptions struct {
+	Model     Model
+	MaxCycles int
+	// Tolerance is the largest parameter change between EM cycles that
+	// still counts as converged.
+	Tolerance float64
+	// Points is the number of quadrature points for the ability scale.
+	Points int
+}
+
+// ItemFit is one item's estimate.
+type ItemFit struct {
+	Item Item
+	// SE holds approximate standard errors; parameters the model fixes have
+	// none.
+	SE Item
+	// RMSD is the root mean square gap between the observed proportion
+	// correct at each ability level and the fitted curve, weighted by how
+	// many respondents sit there. Values above about 0.1 suggest misfit.
+	RMSD      float64
+	Responses int
+	Converged bool
+	// Reason says why the item did not converge.
+	Reason string
+}
+
+type Calibration struct {
+	Items         []ItemFit
+	Cycles        int
+	Converged     bool
+	LogLikelihood float64
+	AIC           float64
+	BIC           float64
+}
+
+// Prior and bound settings for the M-step. Discrimination and guessing get
+// the usual Bayesian modal priors so items with few or extreme responses
+// stay estimable; difficulty only gets a wide one.
+const (
+	logASD    = 0.5
+	bSD       = 3.0
+	cAlpha    = 5.0
+	cBeta     = 17.0
+	minA      = 0.05
+	maxA      = 4.0
+	maxAbsB   = 5.0
+	newtonMax = 25
+	diffStep  = 1e-4
+)
+
+// Calibrate fits item parameters by marginal maximum likelihood with the
+// Bock-Aitkin EM algorithm, taking ability to be standard normal. Missing
+// responses are ignored, so sparse designs such as adaptive tests can be
+// calibrated together with linear forms.
+func Calibrate(items int, patterns [][]Observation, opts CalibrationOptions) Calibration {
+	if opts.Points == 0 {
+		opts.Points = 41
+	}
+	if opts.MaxCycles == 0 {
+		opts.MaxCycles = 500
+	}
+	if opts.Tolerance == 0 {
+		opts.Tolerance = 1e-4
+	}
+
+	points, prior := Quadrature(opts.Points, -4, 4)
+
+	fits := make([]ItemFit, items)
+	correct := make([]float64, items)
+	for _, pattern := range patterns {
+		for _, o := range pattern {
+			fits[o.Item].Responses++
+			if o.Correct {
+				correct[o.Item]++
+			}
+		}
+	}
+
+	// Start from difficulties implied by the proportion correct.
+	params := make([]Item, items)
+	for j := range params {
+		p := clamp((correct[j]+0.5)/(float64(fits[j].Responses)+1), 0.02, 0.98)
+		params[j] = Item{A: 1, B: clamp(-math.Log(p/(1-p)), -3, 3)}
+		if opts.Model == Model3PL {
+			params[j].C = 0.2
+		}
+	}
+
+	r := make([][]float64, items)
+	n := make([][]float64, items)
+	for j := range r {
+		r[j] = make([]float64, len(points))
+		n[j] = make([]float64, len(points))
+	}
+
+	changes := make([]float64, items)
+	failed := make([]bool, items)
+	res := Calibration{}
+
+	for res.Cycles < opts.MaxCycles {
+		res.Cycles++
+		estep(params, patterns, points, prior, r, n)
+
+		largest := 0.0
+		for j := range params {
+			next, ok := mstep(opts.Model, params[j], r[j], n[j], points)
+			failed[j] = !ok
+			changes[j] = change(params[j], next)
+			largest = math.Max(largest, changes[j])
+			params[j] = next
+		}
+
+		if opts.Model == Model1PL {
+			a := commonSlope(params, r, n, points)
+			for j := range params {
+				changes[j] = math.Max(changes[j], math.Abs(a-params[j].A))
+				largest = math.Max(largest, changes[j])
+				params[j].A = a
+			}
+		}
+
+		if largest < opts.Tolerance {
+			res.Converged = true
+			break
+		}
+	}
+
+	res.LogLikelihood = estep(params, patterns, points, prior, r, n)
+
+	for j := range fits {
+		fits[j].Item = params[j]
+		fits[j].SE = standardErrors(opts.Model, params[j], r[j], n[j], points)
+		fits[j].RMSD = rmsd(params[j], r[j], n[j], points)
+
+		switch {
+		case fits[j].Responses == 0:
+			fits[j].Reason = "no responses"
+		case failed[j]:
+			fits[j].Reason = "estimation failed"
+		case changes[j] >= opts.Tolerance:
+			fits[j].Reason = "did not converge"
+		case params[j].A < minA || params[j].A > maxA:
+			fits[j].Reason = "discrimination out of range"
+		case math.Abs(params[j].B) > maxAbsB:
+			fits[j].Reason = "difficulty out of range"
+		default:
+			fits[j].Converged = true
+		}
+	}
+	res.Items = fits
+
+	free := float64(len(params))
+	switch opts.Model {
+	case Model1PL:
+		free++
+	case Model2PL:
+		free *= 2
+	case Model3PL:
+		free *= 3
+	}
+	res.AIC = -2*res.LogLikelihood + 2*free
+	res.BIC = -2*res.LogLikelihood + free*math.Log(float64(max(len(patterns), 1)))
+
+	return res
+}
+
+// estep fills r with the expected number correct and n with the expected
+// number of respondents at each quadrature point, and returns the marginal
+// log-likelihood.
+func estep(params []Item, patterns [][]Observation, points, prior []float64, r, n [][]float64) float64 {
+	logP := make([][]float64, len(params))
+	logQ := make([][]float64, len(params))
+	for j, it := range params {
+		logP[j] = make([]float64, len(points))
+		logQ[j] = make([]float64, len(points))
+		for k, theta := range points {
+			p := clamp(it.P(theta), 1e-9, 1-1e-9)
+			logP[j][k] = math.Log(p)
+			logQ[j][k] = math.Log(1 - p)
+		}
+		clear(r[j])
+		clear(n[j])
+	}
+
+	ll := 0.0
+	post := make([]float64, len(points))
+	for _, pattern := range patterns {
+		top := math.Inf(-1)
+		for k := range points {
+			post[k] = math.Log(prior[k])
+			for _, o := range pattern {
+				if o.Correct {
+					post[k] += logP[o.Item][k]
+				} else {
+					post[k] += logQ[o.Item][k]
+				}
+			}
+			top = math.Max(top, post[k])
+		}
+
+		sum := 0.0
+		for k := range post {
+			post[k] = math.Exp(post[k] - top)
+			sum += post[k]
+		}
+		ll += top + math.Log(sum)
+
+		for k := range post {
+			w := post[k] / sum
+			for _, o := range pattern {
+				n[o.Item][k] += w
+				if o.Correct {
+					r[o.Item][k] += w
+				}
+			}
+		}
+	}
+
+	return ll
+}
+
+// mstep maximises one item's expected complete-data log posterior. Work is
+// done on log a and logit c so the search cannot leave the valid range.
+func mstep(model Model, it Item, r, n, points []float64) (Item, bool) {
+	x := toVector(model, it)
+	f := func(x []float64) float64 {
+		next := fromVector(model, x, it.A)
+		return itemObjective(next, r, n, points) + logPrior(model, next)
+	}
+
+	x, ok := maximise(f, x)
+	return fromVector(model, x, it.A), ok
+}
+
+// commonSlope re-estimates the discrimination 1PL items share.
+func commonSlope(params []Item, r, n [][]float64, points []float64) float64 {
+	f := func(x []float64) float64 {
+		total := 0.0
+		for j, it := range params {
+			it.A = math.Exp(x[0])
+			total += itemObjective(it, r[j], n[j], points)
+		}
+		return total
+	}
+
+	x, _ := maximise(f, []float64{math.Log(params[0].A)})
+	return math.Exp(x[0])
+}
+
+func itemObjective(it Item, r, n, points []float64) float64 {
+	q := 0.0
+	for k, theta := range points {
+		if n[k] == 0 {
+			continue
+		}
+		p := clamp(it.P(theta), 1e-9, 1-1e-9)
+		q += r[k]*math.Log(p) + (n[k]-r[k])*math.Log(1-p)
+	}
+
+	return q
+}
+
+// logPrior is the log density of the priors on the parameters the model
+// estimates per item. The 1PL common slope has none.
+func logPrior(model Model, it Item) float64 {
+	lp := -it.B * it.B / (2 * bSD * bSD)
+
+	if model != Model1PL {
+		la := math.Log(it.A)
+		lp -= la * la / (2 * logASD * logASD)
+	}
+
+	if model == Model3PL {
+		lp += (cAlpha-1)*math.Log(it.C) + (cBeta-1)*math.Log(1-it.C)
+	}
+
+	return lp
+}
+
+func toVector(model Model, it Item) []float64 {
+	switch model {
+	case Model1PL:
+		return []float64{it.B}
+	case Model3PL:
+		return []float64{math.Log(it.A), it.B, math.Log(it.C / (1 - it.C))}
+	default:
+		return []float64{math.Log(it.A), it.B}
+	}
+}
+
+func fromVector(model Model, x []float64, a float64) Item {
+	switch model {
+	case Model1PL:
+		return Item{A: a, B: clamp(x[0], -10, 10)}
+	case Model3PL:
+		return Item{
+			A: math.Exp(clamp(x[0], -5, 2.5)),
+			B: clamp(x[1], -10, 10),
+			C: 1 / (1 + math.Exp(-clamp(x[2], -12, 0))),
+		}
+	default:
+		return Item{A: math.Exp(clamp(x[0], -5, 2.5)), B: clamp(x[1], -10, 10)}
+	}
+}
+
+// maximise runs Newton's method with numerical derivatives, falling back to
+// a gradient step when the Hessian is not negative definite, and halving
+// steps until the objective improves. ok is false if the objective stopped
+// being finite.
+func maximise(f func([]float64) float64, x []float64) ([]float64, bool) {
+	fx := f(x)
+	if math.IsNaN(fx) || math.IsInf(fx, 0) {
+		return x, false
+	}
+
+	for range newtonMax {
+		g, h := derivatives(f, x)
+
+		step, ok := solve(h, g)
+		if !ok || dot(step, g) >= 0 {
+			// Not an ascent direction: take a small gradient step instead.
+			step = make([]float64, len(g))
+			for i := range g {
+				step[i] = -0.1 * g[i]
+			}
+		}
+
+		improved := false
+		for range 20 {
+			next := make([]float64, len(x))
+			for i := range x {
+				next[i] = x[i] - step[i]
+			}
+			if fn := f(next); fn > fx {
+				x, fx, improved = next, fn, true
+				break
+			}
+			for i := range step {
+				step[i] /= 2
+			}
+		}
+
+		if !improved || maxAbs(step) < 1e-7 {
+			break
+		}
+	}
+
+	return x, !math.IsNaN(fx) && !math.IsInf(fx, 0)
+}
+
+// derivatives returns the gradient and Hessian of f at x by central
+// differences.
+func derivatives(f func([]float64) float64, x []float64) ([]float64, [][]float64) {
+	d := len(x)
+	g := make([]float64, d)
+	h := make([][]float64, d)
+	fx := f(x)
+
+	at := func(di, dj int, si, sj float64) float64 {
+		y := append([]float64(nil), x...)
+		y[di] += si
+		y[dj] += sj
+		return f(y)
+	}
+
+	for i := range d {
+		h[i] = make([]float64, d)
+		plus, minus := at(i, i, diffStep/2, diffStep/2), at(i, i, -diffStep/2, -diffStep/2)
+		g[i] = (plus - minus) / (2 * diffStep)
+		h[i][i] = (plus - 2*fx + minus) / (diffStep * diffStep)
+	}
+
+	for i := range d {
+		for j := i + 1; j < d; j++ {
+			v := (at(i, j, diffStep, diffStep) - at(i, j, diffStep, -diffStep) -
+				at(i, j, -diffStep, diffStep) + at(i, j, -diffStep, -diffStep)) / (4 * diffStep * diffStep)
+			h[i][j], h[j][i] = v, v
+		}
+	}
+
+	return g, h
+}
+
+// standardErrors inverts the observed information of the final M-step and
+// maps it back from log a and logit c with the delta method.
+func standardErrors(model Model, it Item, r, n, points []float64) Item {
+	x := toVector(model, it)
+	_, h := derivatives(func(x []float64) float64 {
+		next := fromVector(model, x, it.A)
+		return itemObjective(next, r, n, points) + logPrior(model, next)
+	}, x)
+
+	cov, ok := inverse(h)
+	if !ok {
+		return Item{}
+	}
+
+	sd := func(i int) float64 {
+		v := -cov[i][i]
+		if v <= 0 || math.IsNaN(v) {
+			return 0
+		}
+		return math.Sqrt(v)
+	}
+
+	switch model {
+	case Model1PL:
+		return Item{B: sd(0)}
+	case Model3PL:
+		return Item{A: it.A * sd(0), B: sd(1), C: it.C * (1 - it.C) * sd(2)}
+	default:
+		return Item{A: it.A * sd(0), B: sd(1)}
+	}
+}
+
+func rmsd(it Item, r, n, points []float64) float64 {
+	var total, sum float64
+	for k, theta := range points {
+		if n[k] <= 0 {
+			continue
+		}
+		gap := r[k]/n[k] - it.P(theta)
+		sum += n[k] * gap * gap
+		total += n[k]
+	}
+	if total == 0 {
+		return 0
+	}
+	return math.Sqrt(sum / total)
+}
+
+func change(a, b Item) float64 {
+	return math.Max(math.Abs(a.A-b.A), math.Max(math.Abs(a.B-b.B), math.Abs(a.C-b.C)))
+}
+
+// solve returns x with h·x = g by Gaussian elimination.
+func solve(h [][]float64, g []float64) ([]float64, bool) {
+	inv, ok := inverse(h)
+	if !ok {
+		return nil, false
+	}
+
+	x := make([]float64, len(g))
+	for i := range inv {
+		for j := range g {
+			x[i] += inv[i][j] * g[j]
+		}
+	}
+	return x, true
+}
+
+// inverse uses Gauss-Jordan elimination with partial pivoting. The matrices
+// here are at most 3×3.
+func inverse(m [][]float64) ([][]float64, bool) {
+	d := len(m)
+	a := make([][]float64, d)
+	for i := range m {
+		a[i] = make([]float64, 2*d)
+		copy(a[i], m[i])
+		a[i][d+i] = 1
+	}
+
+	for col := range d {
+		pivot := col
+		for row := col + 1; row < d; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return nil, false
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+
+		div := a[col][col]
+		for j := range a[col] {
+			a[col][j] /= div
+		}
+		for row := range d {
+			if row == col {
+				continue
+			}
+			factor := a[row][col]
+			for j := range a[row] {
+				a[row][j] -= factor * a[col][j]
+			}
+		}
+	}
+
+	inv := make([][]float64, d)
+	for i := range a {
+		inv[i] = a[i][d:]
+	}
+	return inv, true
+}
+
+func dot(a, b []float64) float64 {
+	s := 0.0
+	for i := range a {
+		s += a[i] * b[i]
+	}
+	return s
+}
+
+func maxAbs(v []float64) float64 {
+	m := 0.0
+	for _, x := range v {
+		m = math.Max(m, math.Abs(x))
+	}
+	return m
+}
@@ -0,0 +1,103 @@
+package irt
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// simulate draws respondents from a standard normal ability distribution
+// and scores them on items. Every respondent skips the items skip says to,
+// so sparse designs can be tested too.
+func simulate(items []Item, respondents int, skip func(respondent, item int) bool) [][]Observation {
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	patterns := make([][]Observation, respondents)
+	for i := range patterns {
+		theta := rng.NormFloat64()
+		for j, it := range items {
+			if skip != nil && skip(i, j) {
+				continue
+			}
+			patterns[i] = append(patterns[i], Observation{Item: j, Correct: rng.Float64() < it.P(theta)})
+		}
+	}
+	return patterns
+}
+
+func TestCalibrate(t *testing.T) {
+	twoPL := []Item{
+		{A: 0.8, B: -1.5}, {A: 1.2, B: -0.8}, {A: 1.5, B: -0.2}, {A: 1.0, B: 0.3},
+		{A: 1.8, B: 0.6}, {A: 0.9, B: 1.0}, {A: 1.3, B: 1.4}, {A: 1.1, B: 0},
+	}
+	onePL := []Item{
+		{A: 1.2, B: -1.5}, {A: 1.2, B: -0.7}, {A: 1.2, B: 0}, {A: 1.2, B: 0.5}, {A: 1.2, B: 1.2},
+	}
+
+	tests := []struct {
+		name  string
+		model Model
+		items []Item
+		skip  func(respondent, item int) bool
+		// tolA and tolB bound how far recovered parameters may be from the
+		// ones the data was drawn from.
+		tolA float64
+		tolB float64
+	}{
+		{"2PL complete data", Model2PL, twoPL, nil, 0.3, 0.25},
+		{"2PL with half the items missing", Model2PL, twoPL, func(i, j int) bool { return (i+j)%2 == 0 }, 0.35, 0.3},
+		{"1PL shares one slope", Model1PL, onePL, nil, 0.15, 0.15},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patterns := simulate(tt.items, 3000, tt.skip)
+			res := Calibrate(len(tt.items), patterns, CalibrationOptions{Model: tt.model})
+
+			if !res.Converged {
+				t.Fatalf("Calibrate() did not converge in %d cycles", res.Cycles)
+			}
+			if len(res.Items) != len(tt.items) {
+				t.Fatalf("Calibrate() returned %d items; want %d", len(res.Items), len(tt.items))
+			}
+
+			for j, fit := range res.Items {
+				if !fit.Converged {
+					t.Errorf("item %d did not converge: %s", j, fit.Reason)
+				}
+				if !near(fit.Item.A, tt.items[j].A, tt.tolA) || !near(fit.Item.B, tt.items[j].B, tt.tolB) {
+					t.Errorf("item %d = a %.2f b %.2f; want a %.2f b %.2f", j, fit.Item.A, fit.Item.B, tt.items[j].A, tt.items[j].B)
+				}
+				// The 1PL slope is shared, so items have no error of their own on it.
+				if fit.SE.B <= 0 || (tt.model != Model1PL) != (fit.SE.A > 0) {
+					t.Errorf("item %d standard errors = %+v", j, fit.SE)
+				}
+				if fit.RMSD > 0.1 {
+					t.Errorf("item %d RMSD = %.3f; want a good fit", j, fit.RMSD)
+				}
+				if tt.model == Model1PL && fit.Item.A != res.Items[0].Item.A {
+					t.Errorf("item %d slope %v differs from item 0 slope %v", j, fit.Item.A, res.Items[0].Item.A)
+				}
+			}
+
+			if res.LogLikelihood >= 0 || res.AIC <= -2*res.LogLikelihood || res.BIC <= res.AIC {
+				t.Errorf("Calibrate() log-likelihood %v, AIC %v, BIC %v are inconsistent", res.LogLikelihood, res.AIC, res.BIC)
+			}
+		})
+	}
+}
+
+func TestCalibrateFlagsItemsWithoutResponses(t *testing.T) {
+	items := []Item{{A: 1, B: -0.5}, {A: 1, B: 0.5}, {A: 1, B: 0}}
+	patterns := simulate(items, 500, func(_, j int) bool { return j == 2 })
+
+	res := Calibrate(len(items), patterns, CalibrationOptions{Model: Model2PL})
+
+	fit := res.Items[2]
+	if fit.Converged || fit.Reason != "no responses" || fit.Responses != 0 {
+		t.Errorf("unanswered item = %+v; want it flagged with no responses", fit)
+	}
+	if math.IsNaN(res.LogLikelihood) {
+		t.Errorf("Calibrate() log-likelihood is NaN")
+	}
+}
@@ -0,0 +1,153 @@
+// Package irt where item response theory models, information and ability estimates are computed
+package irt
+
+import "math"
+
+// Item holds three-parameter logistic (3PL) parameters on the logistic
+// metric: discrimination A, difficulty B and guessing C. 2PL items have
+// C = 0 and 1PL items also share one A.
+type Item struct {
+	A float64 `json:"a"`
+	B float64 `json:"b"`
+	C float64 `json:"c"`
+}
+
+// P is the chance a candidate of ability theta answers the item correctly.
+func (it Item) P(theta float64) float64 {
+	return it.C + (1-it.C)/(1+math.Exp(-it.A*(theta-it.B)))
+}
+
+// Information is the Fisher information the item gives about theta.
+func (it Item) Information(theta float64) float64 {
+	p := it.P(theta)
+	if p <= 0 || p >= 1 {
+		return 0
+	}
+
+	r := (p - it.C) / (1 - it.C)
+	return it.A * it.A * (1 - p) / p * r * r
+}
+
+// Response is a scored answer: Correct is 1 or 0.
+type Response struct {
+	Item    Item
+	Correct float64
+}
+
+// logLikelihood is summed over responses, with probabilities kept away from
+// 0 and 1 so it stays finite.
+func logLikelihood(responses []Response, theta float64) float64 {
+	ll := 0.0
+	for _, r := range responses {
+		p := clamp(r.Item.P(theta), 1e-9, 1-1e-9)
+		ll += r.Correct*math.Log(p) + (1-r.Correct)*math.Log(1-p)
+	}
+	return ll
+}
+
+// Quadrature returns n evenly spaced points on [lo, hi] with weights of a
+// standard normal prior that sum to one.
+func Quadrature(n int, lo, hi float64) ([]float64, []float64) {
+	points := make([]float64, n)
+	weights := make([]float64, n)
+
+	step := (hi - lo) / float64(n-1)
+	sum := 0.0
+	for i := range points {
+		points[i] = lo + float64(i)*step
+		weights[i] = math.Exp(-points[i] * points[i] / 2)
+		sum += weights[i]
+	}
+	for i := range weights {
+		weights[i] /= sum
+	}
+
+	return points, weights
+}
+
+// EAP is the expected a posteriori estimate of theta under a standard normal
+// prior, with the posterior standard deviation as its standard error. It is
+// defined for any response pattern, including none at all.
+func EAP(responses []Response) (float64, float64) {
+	points, weights := Quadrature(81, -4, 4)
+
+	post := make([]float64, len(points))
+	maxLL := math.Inf(-1)
+	for i, theta := range points {
+		post[i] = logLikelihood(responses, theta)
+		maxLL = math.Max(maxLL, post[i])
+	}
+
+	var norm, mean float64
+	for i, theta := range points {
+		post[i] = math.Exp(post[i]-maxLL) * weights[i]
+		norm += post[i]
+		mean += theta * post[i]
+	}
+	mean /= norm
+
+	variance := 0.0
+	for i, theta := range points {
+		variance += (theta - mean) * (theta - mean) * post[i]
+	}
+	variance /= norm
+
+	return mean, math.Sqrt(variance)
+}
+
+// MLE is the maximum likelihood estimate of theta, found by Fisher scoring,
+// and its standard error from the test information. ok is false when the
+// estimate does not exist, as with all-correct or all-wrong patterns.
+func MLE(responses []Response) (theta, se float64, ok bool) {
+	var right, wrong bool
+	for _, r := range responses {
+		if r.Correct >= 1 {
+			right = true
+		} else {
+			wrong = true
+		}
+	}
+	if !right || !wrong {
+		return 0, 0, false
+	}
+
+	theta, _ = EAP(responses)
+	for range 50 {
+		var grad, info float64
+		for _, r := range responses {
+			p := clamp(r.Item.P(theta), 1e-9, 1-1e-9)
+			w := (p - r.Item.C) / (p * (1 - r.Item.C))
+			grad += r.Item.A * w * (r.Correct - p)
+			info += r.Item.Information(theta)
+		}
+		if info <= 0 {
+			return 0, 0, false
+		}
+
+		delta := clamp(grad/info, -1, 1)
+		theta = clamp(theta+delta, -6, 6)
+		if math.Abs(delta) < 1e-6 {
+			break
+		}
+	}
+
+	info := TestInformation(responses, theta)
+	if info <= 0 {
+		return 0, 0, false
+	}
+
+	return theta, 1 / math.Sqrt(info), true
+}
+
+// TestInformation sums item information at theta.
+func TestInformation(responses []Response, theta float64) float64 {
+	info := 0.0
+	for _, r := range responses {
+		info += r.Item.Information(theta)
+	}
+	return info
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
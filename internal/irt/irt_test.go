@@ -0,0 +1,152 @@
+package irt
+
+import (
+	"math"
+	"testing"
+)
+
+func near(got, want, tol float64) bool {
+	return math.Abs(got-want) <= tol
+}
+
+func TestItemP(t *testing.T) {
+	tests := []struct {
+		name  string
+		item  Item
+		theta float64
+		want  float64
+	}{
+		{"at difficulty", Item{A: 1.2, B: 0.5}, 0.5, 0.5},
+		{"at difficulty with guessing", Item{A: 1, B: 0, C: 0.2}, 0, 0.6},
+		{"far below difficulty", Item{A: 2, B: 0, C: 0.25}, -10, 0.25},
+		{"far above difficulty", Item{A: 2, B: 0}, 10, 1},
+		{"one logit above", Item{A: 1, B: 0}, 1, 1 / (1 + math.Exp(-1))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.item.P(tt.theta); !near(got, tt.want, 1e-6) {
+				t.Errorf("P(%v) = %v; want %v", tt.theta, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestItemInformation(t *testing.T) {
+	tests := []struct {
+		name  string
+		item  Item
+		theta float64
+		want  float64
+	}{
+		{"2PL peaks at a²/4", Item{A: 2, B: 1}, 1, 1},
+		{"1PL at difficulty", Item{A: 1, B: 0}, 0, 0.25},
+		{"guessing lowers information", Item{A: 1, B: 0, C: 0.2}, 0, 1.0 / 6},
+		{"flat far away", Item{A: 1, B: 0}, 30, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.item.Information(tt.theta); !near(got, tt.want, 1e-6) {
+				t.Errorf("Information(%v) = %v; want %v", tt.theta, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEAP(t *testing.T) {
+	item := Item{A: 1.5, B: 0}
+	easy, hard := Item{A: 1.5, B: -1}, Item{A: 1.5, B: 1}
+
+	tests := []struct {
+		name      string
+		responses []Response
+		wantTheta float64
+		wantSE    float64
+		tol       float64
+	}{
+		{"no responses is the prior", nil, 0, 1, 1e-3},
+		{"balanced pattern", []Response{{easy, 1}, {hard, 0}}, 0, 0.777, 1e-3},
+		{"one right one wrong on the same item", []Response{{item, 1}, {item, 0}}, 0, 0.726, 1e-3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			theta, se := EAP(tt.responses)
+			if !near(theta, tt.wantTheta, tt.tol) || !near(se, tt.wantSE, tt.tol) {
+				t.Errorf("EAP() = %v, %v; want %v, %v", theta, se, tt.wantTheta, tt.wantSE)
+			}
+		})
+	}
+
+	t.Run("all right above all wrong", func(t *testing.T) {
+		right, _ := EAP([]Response{{easy, 1}, {item, 1}, {hard, 1}})
+		wrong, _ := EAP([]Response{{easy, 0}, {item, 0}, {hard, 0}})
+		if right <= 0 || wrong >= 0 || !near(right, -wrong, 1e-9) {
+			t.Errorf("EAP() all right = %v, all wrong = %v; want symmetric about 0", right, wrong)
+		}
+	})
+}
+
+func TestMLE(t *testing.T) {
+	items := []Item{{A: 1, B: -1}, {A: 1, B: 0}, {A: 1, B: 1}}
+
+	tests := []struct {
+		name      string
+		responses []Response
+		wantTheta float64
+		wantOK    bool
+	}{
+		{"no responses", nil, 0, false},
+		{"all right has no estimate", []Response{{items[0], 1}, {items[1], 1}}, 0, false},
+		{"all wrong has no estimate", []Response{{items[0], 0}, {items[2], 0}}, 0, false},
+		{"balanced pattern", []Response{{items[0], 1}, {items[2], 0}}, 0, true},
+		// With equal discriminations the estimate solves sum(p) = number right.
+		{"two of three right", []Response{{items[0], 1}, {items[1], 1}, {items[2], 0}}, 0.803, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			theta, se, ok := MLE(tt.responses)
+			if ok != tt.wantOK {
+				t.Fatalf("MLE() ok = %v; want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+
+			if !near(theta, tt.wantTheta, 1e-3) {
+				t.Errorf("MLE() theta = %v; want %v", theta, tt.wantTheta)
+			}
+
+			var sum float64
+			for _, r := range tt.responses {
+				sum += r.Item.P(theta) - r.Correct
+			}
+			if !near(sum, 0, 1e-4) {
+				t.Errorf("MLE() theta = %v does not solve the likelihood equation, residual %v", theta, sum)
+			}
+
+			if want := 1 / math.Sqrt(TestInformation(tt.responses, theta)); !near(se, want, 1e-9) {
+				t.Errorf("MLE() se = %v; want %v", se, want)
+			}
+		})
+	}
+}
+
+func TestQuadrature(t *testing.T) {
+	points, weights := Quadrature(41, -4, 4)
+
+	var sum, mean float64
+	for i, w := range weights {
+		sum += w
+		mean += points[i] * w
+	}
+
+	if points[0] != -4 || points[40] != 4 || !near(points[20], 0, 1e-12) {
+		t.Errorf("Quadrature() points run %v..%v with middle %v; want -4..4 with middle 0", points[0], points[40], points[20])
+	}
+	if !near(sum, 1, 1e-12) || !near(mean, 0, 1e-12) {
+		t.Errorf("Quadrature() weights sum to %v with mean %v; want 1 and 0", sum, mean)
+	}
+}
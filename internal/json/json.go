@@ -9,6 +9,9 @@ import (
 type FieldError struct {
 	Field   string `json:"field"`
 	Message string `json:"message"`
+	// Code is a stable identifier clients can branch on, set for rule
+	// violations rather than plain input validation.
+	Code string `json:"code,omitempty"`
 }
 
 type APIResponse struct {
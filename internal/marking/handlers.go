@@ -0,0 +1,220 @@
+package marking
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/jackc/pgx/v5"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/helpers"
+	"github.com/odundlaw/cbt-backend/internal/json"
+	"github.com/odundlaw/cbt-backend/internal/middlewares"
+	"github.com/odundlaw/cbt-backend/internal/validation"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service,
+	}
+}
+
+func (h *Handler) GetRubric(w http.ResponseWriter, r *http.Request) {
+	questionID, err := helpers.IDParam(r, "questionID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	rubric, err := h.service.GetRubric(r.Context(), questionID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		json.JSONError(w, http.StatusNotFound, constants.ErrQuestionNotFound, nil)
+		return
+	}
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, rubric, nil)
+}
+
+func (h *Handler) SaveRubric(w http.ResponseWriter, r *http.Request) {
+	questionID, err := helpers.IDParam(r, "questionID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req saveRubricParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	rubric, err := h.service.SaveRubric(r.Context(), questionID, req)
+	if errors.Is(err, pgx.ErrNoRows) {
+		json.JSONError(w, http.StatusNotFound, constants.ErrQuestionNotFound, nil)
+		return
+	}
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgRubricSaved, rubric, nil)
+}
+
+func (h *Handler) ListQueue(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	graderID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	limit, offset := helpers.Pagination(r)
+
+	queue, err := h.service.ListQueue(r.Context(), examID, graderID, limit, offset)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, queue, nil)
+}
+
+func (h *Handler) SubmitMark(w http.ResponseWriter, r *http.Request) {
+	code, questionID, ok := responseParams(w, r)
+	if !ok {
+		return
+	}
+
+	graderID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	var req submitMarkParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	res, err := h.service.SubmitMark(r.Context(), code, questionID, graderID, req)
+	if err != nil {
+		writeMarkingError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusCreated, constants.MsgMarkSubmitted, res, nil)
+}
+
+func (h *Handler) ListModeration(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	limit, offset := helpers.Pagination(r)
+
+	responses, err := h.service.ListModeration(r.Context(), examID, limit, offset)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, responses, nil)
+}
+
+func (h *Handler) Moderate(w http.ResponseWriter, r *http.Request) {
+	code, questionID, ok := responseParams(w, r)
+	if !ok {
+		return
+	}
+
+	moderatorID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	var req moderateParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	review, err := h.service.Moderate(r.Context(), code, questionID, moderatorID, req)
+	if err != nil {
+		writeMarkingError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgResponseModerated, review, nil)
+}
+
+func responseParams(w http.ResponseWriter, r *http.Request) (string, int64, bool) {
+	code := chi.URLParam(r, "candidateCode")
+
+	questionID, err := helpers.IDParam(r, "questionID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return "", 0, false
+	}
+
+	return code, questionID, true
+}
+
+func writeMarkingError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		json.JSONError(w, http.StatusNotFound, constants.ErrResponseNotFound, nil)
+	case errors.Is(err, ErrUnknownCandidate):
+		json.JSONError(w, http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, ErrAlreadyMarked),
+		errors.Is(err, ErrMarkingComplete),
+		errors.Is(err, ErrResponseNotManual),
+		errors.Is(err, ErrResponseNotInReview):
+		json.JSONError(w, http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, ErrScoreOutOfRange),
+		errors.Is(err, ErrUnknownCriterion),
+		errors.Is(err, ErrCriterionOutOfRange),
+		errors.Is(err, ErrScoreOrCriteriaNeeded):
+		json.JSONError(w, http.StatusUnprocessableEntity, err.Error(), nil)
+	default:
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+	}
+}
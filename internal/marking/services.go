@@ -0,0 +1,525 @@
+// Package marking where essay and short-answer responses are scored by people
+package marking
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/config"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+)
+
+var (
+	ErrResponseNotManual     = errors.New(constants.ErrResponseNotManual)
+	ErrResponseNotInReview   = errors.New(constants.ErrResponseNotInReview)
+	ErrAlreadyMarked         = errors.New(constants.ErrAlreadyMarked)
+	ErrMarkingComplete       = errors.New(constants.ErrMarkingComplete)
+	ErrScoreOutOfRange       = errors.New(constants.ErrScoreOutOfRange)
+	ErrUnknownCriterion      = errors.New(constants.ErrUnknownCriterion)
+	ErrCriterionOutOfRange   = errors.New(constants.ErrCriterionOutOfRange)
+	ErrScoreOrCriteriaNeeded = errors.New(constants.ErrScoreOrCriteriaNeeded)
+	ErrUnknownCandidate      = errors.New(constants.ErrUnknownCandidate)
+)
+
+const (
+	markedReason    = "manual marking"
+	moderatedReason = "moderated"
+)
+
+type svc struct {
+	repo   *repo.Queries
+	db     *pgxpool.Pool
+	grader Grader
+}
+
+func NewService(repo *repo.Queries, db *pgxpool.Pool, grader Grader) Service {
+	return &svc{repo: repo, db: db, grader: grader}
+}
+
+// SaveRubric replaces a question's rubric with params.Criteria, in order.
+func (s *svc) SaveRubric(ctx context.Context, questionID int64, params saveRubricParams) ([]repo.RubricCriterium, error) {
+	if _, err := s.repo.GetQuestionByID(ctx, questionID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	if err := qtx.DeleteRubricCriteria(ctx, questionID); err != nil {
+		return nil, err
+	}
+
+	criteria := make([]repo.RubricCriterium, 0, len(params.Criteria))
+	for i, c := range params.Criteria {
+		criterion, err := qtx.CreateRubricCriterion(ctx, repo.CreateRubricCriterionParams{
+			QuestionID:  questionID,
+			Name:        c.Name,
+			Description: pgtype.Text{String: c.Description, Valid: c.Description != ""},
+			MaxPoints:   c.MaxPoints,
+			Position:    int32(i),
+		})
+		if err != nil {
+			return nil, err
+		}
+		criteria = append(criteria, criterion)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return criteria, nil
+}
+
+func (s *svc) GetRubric(ctx context.Context, questionID int64) ([]repo.RubricCriterium, error) {
+	if _, err := s.repo.GetQuestionByID(ctx, questionID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListRubricCriteria(ctx, questionID)
+}
+
+// ListQueue returns the responses of an exam still waiting for a mark that
+// graderID has not already given.
+func (s *svc) ListQueue(ctx context.Context, examID, graderID int64, limit, offset int32) ([]responseView, error) {
+	rows, err := s.repo.ListPendingResponses(ctx, repo.ListPendingResponsesParams{
+		ExamID:   examID,
+		GraderID: graderID,
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]responseView, 0, len(rows))
+	for _, row := range rows {
+		views = append(views, responseView{
+			CandidateCode: candidateCode(row.AttemptID),
+			QuestionID:    row.QuestionID,
+			Type:          row.Type,
+			Stem:          row.Stem,
+			Answer:        row.Answer,
+			MaxScore:      row.MaxScore,
+			MarksCount:    row.MarksCount,
+		})
+	}
+
+	return views, nil
+}
+
+// SubmitMark records graderID's mark for a response. With single marking the
+// mark is final straight away. With double marking the response waits for a
+// second grader; marks within the exam's moderation threshold are averaged,
+// wider gaps are sent to a moderator.
+func (s *svc) SubmitMark(ctx context.Context, code string, questionID, graderID int64, params submitMarkParams) (markResponse, error) {
+	attemptID, err := attemptFromCode(code)
+	if err != nil {
+		return markResponse{}, err
+	}
+
+	response, err := s.repo.GetResponseForMarking(ctx, repo.GetResponseForMarkingParams{
+		AttemptID:  attemptID,
+		QuestionID: questionID,
+	})
+	if err != nil {
+		return markResponse{}, err
+	}
+
+	review, err := s.review(ctx, attemptID, questionID, response.Outcome)
+	if err != nil {
+		return markResponse{}, err
+	}
+
+	if review.Status != repo.ManualReviewStatusAwaitingMarks {
+		return markResponse{}, ErrResponseNotManual
+	}
+
+	score, criteria, err := s.score(ctx, questionID, response.MaxScore, params)
+	if err != nil {
+		return markResponse{}, err
+	}
+
+	policy, err := s.grader.GetPolicy(ctx, response.ExamID)
+	if err != nil {
+		return markResponse{}, err
+	}
+
+	required := 1
+	if policy.DoubleMarking {
+		required = 2
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return markResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	// Graders marking the same response at once queue up here, so each
+	// counts the marks committed before it.
+	if _, err := s.lockReview(ctx, qtx, attemptID, questionID, repo.ManualReviewStatusAwaitingMarks, ErrResponseNotManual); err != nil {
+		return markResponse{}, err
+	}
+
+	marks, err := qtx.ListManualMarks(ctx, repo.ListManualMarksParams{AttemptID: attemptID, QuestionID: questionID})
+	if err != nil {
+		return markResponse{}, err
+	}
+
+	for _, m := range marks {
+		if m.GraderID == graderID {
+			return markResponse{}, ErrAlreadyMarked
+		}
+	}
+
+	if len(marks) >= required {
+		return markResponse{}, ErrMarkingComplete
+	}
+
+	mark, err := qtx.CreateManualMark(ctx, repo.CreateManualMarkParams{
+		AttemptID:      attemptID,
+		QuestionID:     questionID,
+		GraderID:       graderID,
+		CriteriaScores: criteria,
+		Score:          score,
+		Comment:        pgtype.Text{String: params.Comment, Valid: params.Comment != ""},
+	})
+	if err != nil {
+		return markResponse{}, err
+	}
+	marks = append(marks, mark)
+
+	status := repo.ManualReviewStatusAwaitingMarks
+	var final float64
+
+	switch {
+	case len(marks) < required:
+	case required == 1:
+		status, final = repo.ManualReviewStatusFinalized, score
+	case math.Abs(marks[0].Score-marks[1].Score) <= policy.ModerationThreshold:
+		status, final = repo.ManualReviewStatusFinalized, round((marks[0].Score+marks[1].Score)/2)
+	default:
+		status = repo.ManualReviewStatusNeedsModeration
+	}
+
+	if status == repo.ManualReviewStatusFinalized {
+		review, err = s.finalize(ctx, qtx, attemptID, questionID, response.MaxScore, final, graderID, markedReason, pgtype.Text{})
+	} else {
+		review, err = qtx.UpsertManualReview(ctx, repo.UpsertManualReviewParams{
+			AttemptID:  attemptID,
+			QuestionID: questionID,
+			Status:     status,
+		})
+	}
+	if err != nil {
+		return markResponse{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return markResponse{}, err
+	}
+
+	if status == repo.ManualReviewStatusFinalized {
+		if _, err := s.grader.GradeAttempt(ctx, attemptID, graderID, markedReason); err != nil {
+			return markResponse{}, err
+		}
+	}
+
+	return markResponse{Mark: newMarkView(mark), Review: newReviewView(review)}, nil
+}
+
+// ListModeration returns the responses of an exam whose graders disagreed by
+// more than the moderation threshold, together with their marks.
+func (s *svc) ListModeration(ctx context.Context, examID int64, limit, offset int32) ([]moderationView, error) {
+	rows, err := s.repo.ListResponsesForModeration(ctx, repo.ListResponsesForModerationParams{
+		ExamID: examID,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]moderationView, 0, len(rows))
+	for _, row := range rows {
+		marks, err := s.repo.ListManualMarks(ctx, repo.ListManualMarksParams{
+			AttemptID:  row.AttemptID,
+			QuestionID: row.QuestionID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		views = append(views, moderationView{
+			responseView: responseView{
+				CandidateCode: candidateCode(row.AttemptID),
+				QuestionID:    row.QuestionID,
+				Type:          row.Type,
+				Stem:          row.Stem,
+				Answer:        row.Answer,
+				MaxScore:      row.MaxScore,
+				MarksCount:    int64(len(marks)),
+			},
+			Marks: newMarkViews(marks),
+		})
+	}
+
+	return views, nil
+}
+
+// Moderate settles a response the graders disagreed on with the moderator's
+// score.
+func (s *svc) Moderate(ctx context.Context, code string, questionID, moderatorID int64, params moderateParams) (reviewView, error) {
+	attemptID, err := attemptFromCode(code)
+	if err != nil {
+		return reviewView{}, err
+	}
+
+	response, err := s.repo.GetResponseForMarking(ctx, repo.GetResponseForMarkingParams{
+		AttemptID:  attemptID,
+		QuestionID: questionID,
+	})
+	if err != nil {
+		return reviewView{}, err
+	}
+
+	review, err := s.review(ctx, attemptID, questionID, response.Outcome)
+	if err != nil {
+		return reviewView{}, err
+	}
+
+	if review.Status != repo.ManualReviewStatusNeedsModeration {
+		return reviewView{}, ErrResponseNotInReview
+	}
+
+	score := round(*params.Score)
+	if score > response.MaxScore {
+		return reviewView{}, ErrScoreOutOfRange
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return reviewView{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	if _, err := s.lockReview(ctx, qtx, attemptID, questionID, repo.ManualReviewStatusNeedsModeration, ErrResponseNotInReview); err != nil {
+		return reviewView{}, err
+	}
+
+	review, err = s.finalize(ctx, qtx, attemptID, questionID, response.MaxScore, score, moderatorID,
+		moderatedReason, pgtype.Text{String: params.Comment, Valid: true})
+	if err != nil {
+		return reviewView{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return reviewView{}, err
+	}
+
+	if _, err := s.grader.GradeAttempt(ctx, attemptID, moderatorID, moderatedReason); err != nil {
+		return reviewView{}, err
+	}
+
+	return newReviewView(review), nil
+}
+
+// review returns the manual review of a response. Responses nobody has marked
+// yet have no row; they count as awaiting marks while still pending.
+func (s *svc) review(ctx context.Context, attemptID, questionID int64, outcome repo.ScoreOutcome) (repo.ManualReview, error) {
+	review, err := s.repo.GetManualReview(ctx, repo.GetManualReviewParams{AttemptID: attemptID, QuestionID: questionID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		if outcome != repo.ScoreOutcomePending {
+			return repo.ManualReview{}, ErrResponseNotManual
+		}
+		return repo.ManualReview{
+			AttemptID:  attemptID,
+			QuestionID: questionID,
+			Status:     repo.ManualReviewStatusAwaitingMarks,
+		}, nil
+	}
+
+	return review, err
+}
+
+// lockReview creates the response's review if it has none and locks it for
+// the rest of the transaction. It fails with notInStatus when, by the time
+// the lock is held, the review is no longer in status.
+func (s *svc) lockReview(ctx context.Context, q *repo.Queries, attemptID, questionID int64, status repo.ManualReviewStatus, notInStatus error) (repo.ManualReview, error) {
+	if err := q.EnsureManualReview(ctx, repo.EnsureManualReviewParams{AttemptID: attemptID, QuestionID: questionID}); err != nil {
+		return repo.ManualReview{}, err
+	}
+
+	review, err := q.GetManualReviewForUpdate(ctx, repo.GetManualReviewForUpdateParams{AttemptID: attemptID, QuestionID: questionID})
+	if err != nil {
+		return repo.ManualReview{}, err
+	}
+
+	if review.Status != status {
+		return repo.ManualReview{}, notInStatus
+	}
+
+	return review, nil
+}
+
+// score works out a grader's mark out of maxScore, either given directly or
+// scaled from the rubric criteria points. It also returns the criteria points
+// to store with the mark.
+func (s *svc) score(ctx context.Context, questionID int64, maxScore float64, params submitMarkParams) (float64, []byte, error) {
+	if len(params.Criteria) == 0 {
+		if params.Score == nil {
+			return 0, nil, ErrScoreOrCriteriaNeeded
+		}
+
+		score := round(*params.Score)
+		if score > maxScore {
+			return 0, nil, ErrScoreOutOfRange
+		}
+		return score, []byte("{}"), nil
+	}
+
+	rubric, err := s.repo.ListRubricCriteria(ctx, questionID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	limits := make(map[int64]float64, len(rubric))
+	var available float64
+	for _, c := range rubric {
+		limits[c.ID] = c.MaxPoints
+		available += c.MaxPoints
+	}
+
+	var awarded float64
+	for id, points := range params.Criteria {
+		limit, ok := limits[id]
+		if !ok {
+			return 0, nil, ErrUnknownCriterion
+		}
+		if points > limit {
+			return 0, nil, ErrCriterionOutOfRange
+		}
+		awarded += points
+	}
+
+	raw, err := json.Marshal(params.Criteria)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return round(awarded / available * maxScore), raw, nil
+}
+
+// finalize fixes a response's score, logs the change and closes its review.
+// The caller recomputes the attempt total once the transaction commits.
+func (s *svc) finalize(ctx context.Context, qtx *repo.Queries, attemptID, questionID int64, maxScore, score float64, by int64, reason string, comment pgtype.Text) (repo.ManualReview, error) {
+	previous, err := qtx.GetAttemptQuestionScore(ctx, repo.GetAttemptQuestionScoreParams{
+		AttemptID:  attemptID,
+		QuestionID: questionID,
+	})
+	if err != nil {
+		return repo.ManualReview{}, err
+	}
+
+	if _, err := qtx.UpdateAttemptQuestionScore(ctx, repo.UpdateAttemptQuestionScoreParams{
+		AttemptID:  attemptID,
+		QuestionID: questionID,
+		Score:      score,
+		Outcome:    outcome(score, maxScore),
+	}); err != nil {
+		return repo.ManualReview{}, err
+	}
+
+	if err := qtx.CreateScoreChange(ctx, repo.CreateScoreChangeParams{
+		AttemptID:  attemptID,
+		QuestionID: pgtype.Int8{Int64: questionID, Valid: true},
+		OldScore:   previous.Score,
+		NewScore:   score,
+		Reason:     reason,
+		ChangedBy:  pgtype.Int8{Int64: by, Valid: true},
+	}); err != nil {
+		return repo.ManualReview{}, err
+	}
+
+	return qtx.UpsertManualReview(ctx, repo.UpsertManualReviewParams{
+		AttemptID:        attemptID,
+		QuestionID:       questionID,
+		Status:           repo.ManualReviewStatusFinalized,
+		FinalScore:       pgtype.Float8{Float64: score, Valid: true},
+		ModeratorComment: comment,
+		FinalizedBy:      pgtype.Int8{Int64: by, Valid: true},
+	})
+}
+
+func outcome(score, maxScore float64) repo.ScoreOutcome {
+	switch {
+	case score >= maxScore:
+		return repo.ScoreOutcomeCorrect
+	case score > 0:
+		return repo.ScoreOutcomePartial
+	default:
+		return repo.ScoreOutcomeIncorrect
+	}
+}
+
+// candidateCode is a stable pseudonym for an attempt so graders never see who
+// wrote a response. It is the attempt ID encrypted under GRADING_ANON_SECRET,
+// so graders can mark against it without the attempt ID ever being shown.
+func candidateCode(attemptID int64) string {
+	var block [aes.BlockSize]byte
+	binary.BigEndian.PutUint64(block[8:], uint64(attemptID))
+	anonCipher().Encrypt(block[:], block[:])
+
+	return "C-" + strings.ToUpper(hex.EncodeToString(block[:]))
+}
+
+// attemptFromCode reverses candidateCode. The padding half of the block must
+// decrypt to zeros, so made-up codes are refused.
+func attemptFromCode(code string) (int64, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(strings.ToUpper(code), "C-"))
+	if err != nil || len(raw) != aes.BlockSize {
+		return 0, ErrUnknownCandidate
+	}
+
+	anonCipher().Decrypt(raw, raw)
+	for _, b := range raw[:8] {
+		if b != 0 {
+			return 0, ErrUnknownCandidate
+		}
+	}
+
+	return int64(binary.BigEndian.Uint64(raw[8:])), nil
+}
+
+func anonCipher() cipher.Block {
+	key := sha256.Sum256(config.GradingAnonSecret)
+	// A 32 byte key is always valid.
+	block, _ := aes.NewCipher(key[:])
+	return block
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,123 @@
+package marking
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+type Service interface {
+	SaveRubric(ctx context.Context, questionID int64, params saveRubricParams) ([]repo.RubricCriterium, error)
+	GetRubric(ctx context.Context, questionID int64) ([]repo.RubricCriterium, error)
+	ListQueue(ctx context.Context, examID, graderID int64, limit, offset int32) ([]responseView, error)
+	SubmitMark(ctx context.Context, candidateCode string, questionID, graderID int64, params submitMarkParams) (markResponse, error)
+	ListModeration(ctx context.Context, examID int64, limit, offset int32) ([]moderationView, error)
+	Moderate(ctx context.Context, candidateCode string, questionID, moderatorID int64, params moderateParams) (reviewView, error)
+}
+
+// Grader is the part of the grading service marking needs: the exam's policy
+// and recomputing an attempt's total once a manual score is final.
+type Grader interface {
+	GetPolicy(ctx context.Context, examID int64) (repo.ExamGradingPolicy, error)
+	GradeAttempt(ctx context.Context, attemptID, changedBy int64, reason string) (repo.AttemptResult, error)
+}
+
+type rubricCriterionParams struct {
+	Name        string  `json:"name" validate:"required,min=2,max=100"`
+	Description string  `json:"description" validate:"max=500"`
+	MaxPoints   float64 `json:"max_points" validate:"required,gt=0"`
+}
+
+type saveRubricParams struct {
+	Criteria []rubricCriterionParams `json:"criteria" validate:"required,min=1,dive"`
+}
+
+type submitMarkParams struct {
+	// Score is the mark out of the question's marks. Leave it out when
+	// scoring against the rubric with Criteria instead.
+	Score *float64 `json:"score" validate:"omitempty,gte=0"`
+	// Criteria maps rubric criterion IDs to the points awarded.
+	Criteria map[int64]float64 `json:"criteria" validate:"omitempty,dive,gte=0"`
+	Comment  string            `json:"comment" validate:"max=2000"`
+}
+
+type moderateParams struct {
+	Score   *float64 `json:"score" validate:"required,gte=0"`
+	Comment string   `json:"comment" validate:"required,min=3,max=2000"`
+}
+
+// responseView is a candidate's answer as graders see it. The candidate is
+// only identified by an opaque code.
+type responseView struct {
+	CandidateCode string            `json:"candidate_code"`
+	QuestionID    int64             `json:"question_id"`
+	Type          repo.QuestionType `json:"type"`
+	Stem          string            `json:"stem"`
+	Answer        json.RawMessage   `json:"answer"`
+	MaxScore      float64           `json:"max_score"`
+	MarksCount    int64             `json:"marks_count"`
+}
+
+type moderationView struct {
+	responseView
+	Marks []markView `json:"marks"`
+}
+
+// markView is one grader's mark without the attempt it belongs to.
+type markView struct {
+	ID             int64              `json:"id"`
+	GraderID       int64              `json:"grader_id"`
+	CriteriaScores json.RawMessage    `json:"criteria_scores"`
+	Score          float64            `json:"score"`
+	Comment        pgtype.Text        `json:"comment"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
+// reviewView is where a response stands, keyed by its candidate code.
+type reviewView struct {
+	CandidateCode    string                  `json:"candidate_code"`
+	QuestionID       int64                   `json:"question_id"`
+	Status           repo.ManualReviewStatus `json:"status"`
+	FinalScore       pgtype.Float8           `json:"final_score"`
+	ModeratorComment pgtype.Text             `json:"moderator_comment"`
+	FinalizedBy      pgtype.Int8             `json:"finalized_by"`
+	FinalizedAt      pgtype.Timestamptz      `json:"finalized_at"`
+}
+
+type markResponse struct {
+	Mark   markView   `json:"mark"`
+	Review reviewView `json:"review"`
+}
+
+func newMarkView(mark repo.ManualMark) markView {
+	return markView{
+		ID:             mark.ID,
+		GraderID:       mark.GraderID,
+		CriteriaScores: mark.CriteriaScores,
+		Score:          mark.Score,
+		Comment:        mark.Comment,
+		CreatedAt:      mark.CreatedAt,
+	}
+}
+
+func newMarkViews(marks []repo.ManualMark) []markView {
+	views := make([]markView, len(marks))
+	for i, mark := range marks {
+		views[i] = newMarkView(mark)
+	}
+	return views
+}
+
+func newReviewView(review repo.ManualReview) reviewView {
+	return reviewView{
+		CandidateCode:    candidateCode(review.AttemptID),
+		QuestionID:       review.QuestionID,
+		Status:           review.Status,
+		FinalScore:       review.FinalScore,
+		ModeratorComment: review.ModeratorComment,
+		FinalizedBy:      review.FinalizedBy,
+		FinalizedAt:      review.FinalizedAt,
+	}
+}
@@ -0,0 +1,149 @@
+package media
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/odundlaw/cbt-backend/internal/config"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/helpers"
+	"github.com/odundlaw/cbt-backend/internal/json"
+	"github.com/odundlaw/cbt-backend/internal/middlewares"
+	"github.com/odundlaw/cbt-backend/internal/storage"
+)
+
+// uploadOverhead allows for the multipart framing around an upload of the
+// largest permitted size.
+const uploadOverhead = 1 << 20
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service,
+	}
+}
+
+// Upload stores an image or audio clip sent as the file field of a
+// multipart form and returns the URL to use in question HTML.
+func (h *Handler) Upload(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	limit := int64(max(config.MediaMaxImageMB, config.MediaMaxAudioMB))<<20 + uploadOverhead
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
+
+	f, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			json.JSONError(w, http.StatusRequestEntityTooLarge, constants.ErrMediaTooLarge, nil)
+		case errors.Is(err, http.ErrMissingFile):
+			json.JSONError(w, http.StatusBadRequest, constants.ErrMediaFileMissing, nil)
+		default:
+			json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		}
+		return
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	file, err := h.service.Upload(r.Context(), adminID, header.Filename, data)
+	if err != nil {
+		writeMediaError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusCreated, constants.MsgMediaUploaded, newMediaResponse(file), nil)
+}
+
+// GetFile serves a stored file to admins. Files never change once stored,
+// so clients may cache them.
+func (h *Handler) GetFile(w http.ResponseWriter, r *http.Request) {
+	mediaID, err := helpers.IDParam(r, "mediaID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	h.serve(w, r, mediaID, "private, max-age=86400, immutable")
+}
+
+// GetSigned serves a file through a signed link from an attempt's paper.
+// The link needs no login, so images and audio load from plain src
+// attributes, and stops working when the attempt is submitted or would end.
+func (h *Handler) GetSigned(w http.ResponseWriter, r *http.Request) {
+	mediaID, err := helpers.IDParam(r, "mediaID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	query := r.URL.Query()
+	if err := h.service.VerifyLink(r.Context(), mediaID, query.Get("attempt"), query.Get("expires"), query.Get("sig")); err != nil {
+		writeMediaError(w, err)
+		return
+	}
+
+	exp, _ := strconv.ParseInt(query.Get("expires"), 10, 64)
+	maxAge := max(0, int(time.Until(time.Unix(exp, 0)).Seconds()))
+	h.serve(w, r, mediaID, fmt.Sprintf("private, max-age=%d", maxAge))
+}
+
+func (h *Handler) serve(w http.ResponseWriter, r *http.Request, mediaID int64, cacheControl string) {
+	file, err := h.service.GetFile(r.Context(), mediaID)
+	if err != nil {
+		writeMediaError(w, err)
+		return
+	}
+
+	body, err := h.service.Open(r.Context(), file)
+	if err != nil {
+		writeMediaError(w, err)
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(file.Size, 10))
+	w.Header().Set("Cache-Control", cacheControl)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, body); err != nil {
+		fmt.Println("failed to write media file:", err)
+	}
+}
+
+func writeMediaError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows), errors.Is(err, storage.ErrNotFound):
+		json.JSONError(w, http.StatusNotFound, constants.ErrMediaNotFound, nil)
+	case errors.Is(err, ErrLinkExpired):
+		json.JSONError(w, http.StatusForbidden, constants.ErrMediaLinkExpired, nil)
+	case errors.Is(err, ErrUnsupportedType):
+		json.JSONError(w, http.StatusUnsupportedMediaType, err.Error(), nil)
+	case errors.Is(err, ErrFileTooLarge):
+		json.JSONError(w, http.StatusRequestEntityTooLarge, err.Error(), nil)
+	case errors.Is(err, ErrImageTooLarge), errors.Is(err, ErrInvalidImage):
+		json.JSONError(w, http.StatusUnprocessableEntity, err.Error(), nil)
+	default:
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+	}
+}
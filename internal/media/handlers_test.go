@@ -0,0 +1,89 @@
+package media
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+// linkService checks links with Verify and stands in for the attempt lookup
+// with inProgress.
+type linkService struct {
+	Service
+	inProgress bool
+}
+
+func (s *linkService) VerifyLink(_ context.Context, ID int64, attempt, expires, sig string) error {
+	if _, err := Verify(ID, attempt, expires, sig); err != nil {
+		return err
+	}
+	if !s.inProgress {
+		return ErrLinkExpired
+	}
+	return nil
+}
+
+func (s *linkService) GetFile(_ context.Context, ID int64) (repo.MediaFile, error) {
+	return repo.MediaFile{ID: ID, ContentType: "image/png", Size: 3, Data: []byte("png")}, nil
+}
+
+func (s *linkService) Open(_ context.Context, file repo.MediaFile) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(string(file.Data))), nil
+}
+
+func TestGetSigned(t *testing.T) {
+	withSecret(t, "media-secret")
+
+	expires := time.Now().Add(time.Hour)
+	link := signedLinks(t, SignReferences(`<img src="/api/media/12">`, 7, expires))[0]
+
+	tests := []struct {
+		name       string
+		mediaID    string
+		query      string
+		inProgress bool
+		wantStatus int
+	}{
+		{"attempt in progress", "12", link.Encode(), true, http.StatusOK},
+		{"attempt submitted", "12", link.Encode(), false, http.StatusForbidden},
+		{"link for another file", "13", link.Encode(), true, http.StatusForbidden},
+		{"no signature", "12", "attempt=7&expires=" + strconv.FormatInt(expires.Unix(), 10), true, http.StatusForbidden},
+		{"bad id", "x", link.Encode(), true, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/media/"+tt.mediaID+"/signed?"+tt.query, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("mediaID", tt.mediaID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			rec := httptest.NewRecorder()
+
+			NewHandler(&linkService{inProgress: tt.inProgress}).GetSigned(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d; want %d", rec.Code, tt.wantStatus)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			if rec.Body.String() != "png" || rec.Header().Get("X-Content-Type-Options") != "nosniff" {
+				t.Errorf("served %q with headers %v; want the file with nosniff", rec.Body.String(), rec.Header())
+			}
+			// Browsers may cache the file only until the link expires.
+			cc := rec.Header().Get("Cache-Control")
+			age, err := strconv.Atoi(strings.TrimPrefix(cc, "private, max-age="))
+			if err != nil || age > 3600 || age < 3590 {
+				t.Errorf("Cache-Control = %q; want private for about an hour", cc)
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+// Package media where images and other files used in questions are stored and served
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/config"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/storage"
+)
+
+const urlPrefix = "/api/media/"
+
+var reference = regexp.MustCompile(regexp.QuoteMeta(urlPrefix) + `(\d+)`)
+
+var (
+	ErrUnsupportedType = errors.New(constants.ErrUnsupportedMediaType)
+	ErrFileTooLarge    = errors.New(constants.ErrMediaTooLarge)
+	ErrImageTooLarge   = errors.New(constants.ErrImageTooLarge)
+	ErrInvalidImage    = errors.New(constants.ErrInvalidImage)
+	ErrLinkExpired     = errors.New(constants.ErrMediaLinkExpired)
+)
+
+const (
+	KindImage = "image"
+	KindAudio = "audio"
+)
+
+// allowed maps the content types that may be uploaded, as sniffed from the
+// bytes, to their kind. SVG is left out since it can carry script.
+var allowed = map[string]string{
+	"image/png":   KindImage,
+	"image/jpeg":  KindImage,
+	"image/gif":   KindImage,
+	"image/webp":  KindImage,
+	"audio/mpeg":  KindAudio,
+	"audio/mp4":   KindAudio,
+	"audio/x-m4a": KindAudio,
+	"audio/aac":   KindAudio,
+	"audio/ogg":   KindAudio,
+	"audio/wav":   KindAudio,
+	"audio/flac":  KindAudio,
+}
+
+type svc struct {
+	repo *repo.Queries
+	blob storage.Blob
+}
+
+func NewService(repo *repo.Queries, blob storage.Blob) Service {
+	return &svc{repo: repo, blob: blob}
+}
+
+func (s *svc) GetFile(ctx context.Context, ID int64) (repo.MediaFile, error) {
+	return s.repo.GetMediaFile(ctx, ID)
+}
+
+// VerifyLink checks a signed link and that the attempt it was signed for is
+// still being sat, so links stop working once the attempt is submitted.
+func (s *svc) VerifyLink(ctx context.Context, ID int64, attempt, expires, sig string) error {
+	attemptID, err := Verify(ID, attempt, expires, sig)
+	if err != nil {
+		return err
+	}
+
+	sitting, err := s.repo.GetAttemptByID(ctx, attemptID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && sitting.Status != repo.AttemptStatusInProgress) {
+		return ErrLinkExpired
+	}
+
+	return err
+}
+
+// Open reads a file's contents. Files stored before blob storage keep their
+// bytes in the row.
+func (s *svc) Open(ctx context.Context, file repo.MediaFile) (io.ReadCloser, error) {
+	if !file.StorageKey.Valid {
+		return io.NopCloser(bytes.NewReader(file.Data)), nil
+	}
+	return s.blob.Open(ctx, file.StorageKey.String)
+}
+
+func (s *svc) Upload(ctx context.Context, createdBy int64, name string, data []byte) (repo.MediaFile, error) {
+	return s.Store(ctx, s.repo, createdBy, name, data)
+}
+
+// Store checks a file and saves it under its checksum. A file already stored
+// is returned as it is, so the same diagram uploaded twice, or imported with
+// two packages, is kept once. q lets an import store files in its own
+// transaction; the blob itself is written straight away and is harmless to
+// leave behind if the transaction rolls back, since its key is its content.
+func (s *svc) Store(ctx context.Context, q *repo.Queries, createdBy int64, name string, data []byte) (repo.MediaFile, error) {
+	info, err := Check(data)
+	if err != nil {
+		return repo.MediaFile{}, err
+	}
+
+	existing, err := q.GetMediaFileByChecksum(ctx, info.Checksum)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return repo.MediaFile{}, err
+	}
+
+	key := "sha256/" + info.Checksum[:2] + "/" + info.Checksum
+	if err := s.blob.Put(ctx, key, data, info.ContentType); err != nil {
+		return repo.MediaFile{}, err
+	}
+
+	return q.CreateMediaFile(ctx, repo.CreateMediaFileParams{
+		Checksum:    info.Checksum,
+		Name:        path.Base(name),
+		ContentType: info.ContentType,
+		Size:        int64(len(data)),
+		StorageKey:  pgtype.Text{String: key, Valid: true},
+		Width:       pgtype.Int4{Int32: int32(info.Width), Valid: info.Kind == KindImage},
+		Height:      pgtype.Int4{Int32: int32(info.Height), Valid: info.Kind == KindImage},
+		CreatedBy:   createdBy,
+	})
+}
+
+// Info is what Check learns about a file.
+type Info struct {
+	Checksum    string
+	ContentType string
+	Kind        string
+	Width       int
+	Height      int
+}
+
+// Check sniffs a file's type from its bytes, whatever its name says, and
+// applies the size limit for its kind. Images must also decode and fit
+// within MEDIA_MAX_IMAGE_SIDE pixels each way.
+func Check(data []byte) (Info, error) {
+	sum := sha256.Sum256(data)
+	info := Info{Checksum: hex.EncodeToString(sum[:]), ContentType: mimetype.Detect(data).String()}
+
+	kind, ok := allowed[info.ContentType]
+	if !ok {
+		return info, fmt.Errorf("%w: got %s", ErrUnsupportedType, info.ContentType)
+	}
+	info.Kind = kind
+
+	limit := int64(config.MediaMaxImageMB) << 20
+	if kind == KindAudio {
+		limit = int64(config.MediaMaxAudioMB) << 20
+	}
+	if int64(len(data)) > limit {
+		return info, fmt.Errorf("%w: %s files may be up to %d MB", ErrFileTooLarge, kind, limit>>20)
+	}
+
+	if kind != KindImage {
+		return info, nil
+	}
+
+	var err error
+	info.Width, info.Height, err = imageSize(info.ContentType, data)
+	if err != nil || info.Width <= 0 || info.Height <= 0 {
+		return info, ErrInvalidImage
+	}
+	if side := config.MediaMaxImageSide; info.Width > side || info.Height > side {
+		return info, fmt.Errorf("%w: %dx%d is over %d pixels", ErrImageTooLarge, info.Width, info.Height, side)
+	}
+
+	return info, nil
+}
+
+func imageSize(contentType string, data []byte) (int, int, error) {
+	if contentType == "image/webp" {
+		return webpSize(data)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	return cfg.Width, cfg.Height, err
+}
+
+// webpSize reads the canvas size from a WebP header, for the lossy, lossless
+// and extended formats. The standard library has no WebP decoder.
+func webpSize(b []byte) (int, int, error) {
+	if len(b) < 30 || string(b[0:4]) != "RIFF" || string(b[8:12]) != "WEBP" {
+		return 0, 0, ErrInvalidImage
+	}
+
+	switch string(b[12:16]) {
+	case "VP8 ":
+		w := binary.LittleEndian.Uint16(b[26:28]) & 0x3fff
+		h := binary.LittleEndian.Uint16(b[28:30]) & 0x3fff
+		return int(w), int(h), nil
+	case "VP8L":
+		bits := binary.LittleEndian.Uint32(b[21:25])
+		return int(bits&0x3fff) + 1, int(bits>>14&0x3fff) + 1, nil
+	case "VP8X":
+		w := uint32(b[24]) | uint32(b[25])<<8 | uint32(b[26])<<16
+		h := uint32(b[27]) | uint32(b[28])<<8 | uint32(b[29])<<16
+		return int(w) + 1, int(h) + 1, nil
+	}
+	return 0, 0, ErrInvalidImage
+}
+
+// URL is how question HTML links to a stored file. Only admins can follow
+// it; candidates get signed links instead.
+func URL(ID int64) string {
+	return fmt.Sprintf("%s%d", urlPrefix, ID)
+}
+
+// SignReferences rewrites the file links in question HTML to signed links
+// for the attempt that last until expires. It is used when a paper is shown
+// during an attempt, so candidates can only load media while they sit the
+// exam.
+func SignReferences(html string, attemptID int64, expires time.Time) string {
+	return reference.ReplaceAllStringFunc(html, func(m string) string {
+		id, err := strconv.ParseInt(m[len(urlPrefix):], 10, 64)
+		if err != nil {
+			return m
+		}
+		exp := expires.Unix()
+		return fmt.Sprintf("%s%d/signed?attempt=%d&amp;expires=%d&amp;sig=%s",
+			urlPrefix, id, attemptID, exp, signature(id, attemptID, exp))
+	})
+}
+
+// Verify checks a signed link's expiry and signature and returns the
+// attempt it was signed for.
+func Verify(ID int64, attempt, expires, sig string) (int64, error) {
+	attemptID, err := strconv.ParseInt(attempt, 10, 64)
+	if err != nil {
+		return 0, ErrLinkExpired
+	}
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return 0, ErrLinkExpired
+	}
+	if !hmac.Equal([]byte(sig), []byte(signature(ID, attemptID, exp))) {
+		return 0, ErrLinkExpired
+	}
+	return attemptID, nil
+}
+
+func signature(ID, attemptID, expires int64) string {
+	mac := hmac.New(sha256.New, config.MediaURLSecret)
+	fmt.Fprintf(mac, "%d:%d:%d", ID, attemptID, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// References lists the files a piece of question HTML links to, in order
+// of first use.
+func References(html string) []int64 {
+	var ids []int64
+	seen := map[int64]bool{}
+	for _, m := range reference.FindAllStringSubmatch(html, -1) {
+		id, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
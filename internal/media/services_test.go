@@ -0,0 +1,195 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"html"
+	"image"
+	"image/png"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/odundlaw/cbt-backend/internal/config"
+)
+
+func withSecret(t *testing.T, secret string) {
+	t.Helper()
+
+	old := config.MediaURLSecret
+	config.MediaURLSecret = []byte(secret)
+	t.Cleanup(func() { config.MediaURLSecret = old })
+}
+
+// signedLinks returns the query of every signed link in html, as a browser
+// would send it.
+func signedLinks(t *testing.T, signed string) []url.Values {
+	t.Helper()
+
+	var links []url.Values
+	for _, part := range strings.Split(signed, `src="`)[1:] {
+		u, err := url.Parse(html.UnescapeString(part[:strings.IndexByte(part, '"')]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		links = append(links, u.Query())
+	}
+	return links
+}
+
+func TestSignReferences(t *testing.T) {
+	withSecret(t, "media-secret")
+
+	in := `<p>See <img src="/api/media/12"> and <img src="/api/media/345">, not /api/media/x.</p>`
+	expires := time.Now().Add(time.Hour)
+
+	out := SignReferences(in, 7, expires)
+
+	links := signedLinks(t, out)
+	if len(links) != 2 {
+		t.Fatalf("SignReferences() = %s; want two signed links", out)
+	}
+	if !strings.Contains(out, `src="/api/media/12/signed?attempt=7&amp;`) || !strings.HasSuffix(out, `, not /api/media/x.</p>`) {
+		t.Errorf("SignReferences() = %s; want links rewritten in place and the rest untouched", out)
+	}
+
+	for i, id := range []int64{12, 345} {
+		q := links[i]
+		if q.Get("expires") != strconv.FormatInt(expires.Unix(), 10) {
+			t.Errorf("link %d expires = %s; want %d", i, q.Get("expires"), expires.Unix())
+		}
+		attemptID, err := Verify(id, q.Get("attempt"), q.Get("expires"), q.Get("sig"))
+		if err != nil || attemptID != 7 {
+			t.Errorf("Verify(link %d) = %d, %v; want 7", i, attemptID, err)
+		}
+	}
+
+	if got := SignReferences("<p>No media</p>", 7, expires); got != "<p>No media</p>" {
+		t.Errorf("SignReferences() = %s; want it unchanged", got)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	withSecret(t, "media-secret")
+
+	future := time.Now().Add(time.Hour).Unix()
+	past := time.Now().Add(-time.Minute).Unix()
+	exp := strconv.FormatInt(future, 10)
+	sig := signature(12, 7, future)
+
+	tests := []struct {
+		name    string
+		id      int64
+		attempt string
+		expires string
+		sig     string
+		wantErr error
+	}{
+		{name: "valid", id: 12, attempt: "7", expires: exp, sig: sig},
+		{name: "another file", id: 13, attempt: "7", expires: exp, sig: sig, wantErr: ErrLinkExpired},
+		{name: "another attempt", id: 12, attempt: "8", expires: exp, sig: sig, wantErr: ErrLinkExpired},
+		{name: "expiry pushed back", id: 12, attempt: "7", expires: strconv.FormatInt(future+3600, 10), sig: sig, wantErr: ErrLinkExpired},
+		{name: "expired", id: 12, attempt: "7", expires: strconv.FormatInt(past, 10), sig: signature(12, 7, past), wantErr: ErrLinkExpired},
+		{name: "no signature", id: 12, attempt: "7", expires: exp, wantErr: ErrLinkExpired},
+		{name: "attempt is not a number", id: 12, attempt: "x", expires: exp, sig: sig, wantErr: ErrLinkExpired},
+		{name: "expiry is not a number", id: 12, attempt: "7", expires: "soon", sig: sig, wantErr: ErrLinkExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attemptID, err := Verify(tt.id, tt.attempt, tt.expires, tt.sig)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v; want %v", err, tt.wantErr)
+			}
+			if err == nil && attemptID != 7 {
+				t.Errorf("Verify() = %d; want 7", attemptID)
+			}
+		})
+	}
+
+	t.Run("signed with another secret", func(t *testing.T) {
+		withSecret(t, "rotated")
+		if _, err := Verify(12, "7", exp, sig); !errors.Is(err, ErrLinkExpired) {
+			t.Errorf("Verify() error = %v; want %v", err, ErrLinkExpired)
+		}
+	})
+}
+
+func TestReferences(t *testing.T) {
+	tests := []struct {
+		html string
+		want []int64
+	}{
+		{`<img src="/api/media/3"><img src="/api/media/1"><img src="/api/media/3">`, []int64{3, 1}},
+		{`<audio src="/api/media/10/signed?attempt=1"></audio>`, []int64{10}},
+		{`<p>No media</p>`, nil},
+	}
+
+	for _, tt := range tests {
+		if got := References(tt.html); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("References(%s) = %v; want %v", tt.html, got, tt.want)
+		}
+	}
+}
+
+func pngOf(t *testing.T, w, h int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// webpOf builds just enough of an extended WebP header to carry a canvas
+// size.
+func webpOf(w, h int) []byte {
+	b := make([]byte, 30)
+	copy(b, "RIFF")
+	copy(b[8:], "WEBPVP8X")
+	w, h = w-1, h-1
+	b[24], b[25], b[26] = byte(w), byte(w>>8), byte(w>>16)
+	b[27], b[28], b[29] = byte(h), byte(h>>8), byte(h>>16)
+	return b
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		wantKind   string
+		wantWidth  int
+		wantHeight int
+		wantErr    error
+	}{
+		{name: "png", data: pngOf(t, 40, 30), wantKind: KindImage, wantWidth: 40, wantHeight: 30},
+		{name: "webp", data: webpOf(640, 480), wantKind: KindImage, wantWidth: 640, wantHeight: 480},
+		{name: "image too wide", data: pngOf(t, config.MediaMaxImageSide+1, 1), wantErr: ErrImageTooLarge},
+		{name: "webp too tall", data: webpOf(1, config.MediaMaxImageSide+1), wantErr: ErrImageTooLarge},
+		{name: "svg", data: []byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`), wantErr: ErrUnsupportedType},
+		{name: "truncated png", data: pngOf(t, 40, 30)[:20], wantErr: ErrInvalidImage},
+		{name: "plain text", data: []byte("not an image"), wantErr: ErrUnsupportedType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := Check(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Check() error = %v; want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if info.Kind != tt.wantKind || info.Width != tt.wantWidth || info.Height != tt.wantHeight {
+				t.Errorf("Check() = %s %dx%d; want %s %dx%d", info.Kind, info.Width, info.Height, tt.wantKind, tt.wantWidth, tt.wantHeight)
+			}
+			if len(info.Checksum) != 64 {
+				t.Errorf("Check() checksum = %q; want a hex SHA-256", info.Checksum)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package media
+
+import (
+	"context"
+	"io"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+type Service interface {
+	GetFile(ctx context.Context, ID int64) (repo.MediaFile, error)
+	VerifyLink(ctx context.Context, ID int64, attempt, expires, sig string) error
+	Open(ctx context.Context, file repo.MediaFile) (io.ReadCloser, error)
+	Upload(ctx context.Context, createdBy int64, name string, data []byte) (repo.MediaFile, error)
+	Store(ctx context.Context, q *repo.Queries, createdBy int64, name string, data []byte) (repo.MediaFile, error)
+}
+
+// mediaResponse describes a stored file. URL is the link to put in question
+// HTML.
+type mediaResponse struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Width       *int32 `json:"width,omitempty"`
+	Height      *int32 `json:"height,omitempty"`
+	URL         string `json:"url"`
+}
+
+func newMediaResponse(file repo.MediaFile) mediaResponse {
+	res := mediaResponse{
+		ID:          file.ID,
+		Name:        file.Name,
+		ContentType: file.ContentType,
+		Size:        file.Size,
+		URL:         URL(file.ID),
+	}
+	if file.Width.Valid && file.Height.Valid {
+		res.Width, res.Height = &file.Width.Int32, &file.Height.Int32
+	}
+	return res
+}
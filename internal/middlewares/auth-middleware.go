@@ -4,6 +4,7 @@ package middlewares
 import (
 	"context"
 	"net/http"
+	"strconv"
 
 	"github.com/odundlaw/cbt-backend/internal/config"
 	"github.com/odundlaw/cbt-backend/internal/constants"
@@ -47,3 +48,18 @@ func AuthMiddleware(redis *store.Redis) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// UserIDFromContext returns the authenticated user's ID set by AuthMiddleware.
+func UserIDFromContext(ctx context.Context) (int64, bool) {
+	sub, ok := ctx.Value(UserContextKey).(string)
+	if !ok {
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return id, true
+}
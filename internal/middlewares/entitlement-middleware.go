@@ -0,0 +1,50 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/json"
+)
+
+// Entitlements decides whether a user may use a paid resource.
+type Entitlements interface {
+	Allowed(ctx context.Context, userID int64, target repo.AccessTarget, targetID int64) (bool, error)
+}
+
+// RequireEntitlement only lets through users entitled to the target whose id
+// is in the param URL parameter. It must run after AuthMiddleware.
+func RequireEntitlement(e Entitlements, target repo.AccessTarget, param string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := UserIDFromContext(r.Context())
+			if !ok {
+				json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+				return
+			}
+
+			targetID, err := strconv.ParseInt(chi.URLParam(r, param), 10, 64)
+			if err != nil {
+				json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+				return
+			}
+
+			allowed, err := e.Allowed(r.Context(), userID, target, targetID)
+			if err != nil {
+				json.JSONError(w, http.StatusInternalServerError, constants.ErrInternalServer, nil)
+				return
+			}
+
+			if !allowed {
+				json.JSONError(w, http.StatusForbidden, constants.ErrEntitlementRequired, nil)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package middlewares
+
+import (
+	"net/http"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/json"
+)
+
+// RequirePermission only lets through users that were granted perm. It must
+// run after AuthMiddleware, and usually after RequireRole.
+func RequirePermission(q repo.Querier, perm repo.AdminPermission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := UserIDFromContext(r.Context())
+			if !ok {
+				json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+				return
+			}
+
+			granted, err := q.HasUserPermission(r.Context(), repo.HasUserPermissionParams{
+				UserID:     userID,
+				Permission: perm,
+			})
+			if err != nil {
+				json.JSONError(w, http.StatusInternalServerError, constants.ErrInternalServer, nil)
+				return
+			}
+
+			if !granted {
+				json.JSONError(w, http.StatusForbidden, constants.ErrPermissionRequired, nil)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package middlewares
+
+import (
+	"net/http"
+	"slices"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/json"
+)
+
+// RequireRole only lets through users holding one of roles. It must run after
+// AuthMiddleware. Admins are additionally required to be approved.
+func RequireRole(q repo.Querier, roles ...repo.UserRole) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := UserIDFromContext(r.Context())
+			if !ok {
+				json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+				return
+			}
+
+			user, err := q.GetUserByID(r.Context(), userID)
+			if err != nil {
+				json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+				return
+			}
+
+			if !slices.Contains(roles, user.Role) {
+				json.JSONError(w, http.StatusForbidden, constants.ErrForbidden, nil)
+				return
+			}
+
+			if user.Role == repo.UserRoleADMIN && user.Status != repo.UserStatusApproved {
+				json.JSONError(w, http.StatusForbidden, constants.ErrAccountNotApporve, nil)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package pastpapers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/helpers"
+	"github.com/odundlaw/cbt-backend/internal/json"
+	"github.com/odundlaw/cbt-backend/internal/middlewares"
+	"github.com/odundlaw/cbt-backend/internal/validation"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service,
+	}
+}
+
+// ListPapers lists the papers in the bank, e.g. ?exam_body=JAMB&exam_year=2021.
+// Papers that have been launched before carry their mock_exam_id.
+func (h *Handler) ListPapers(w http.ResponseWriter, r *http.Request) {
+	year, err := helpers.OptionalInt32(r, "exam_year")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	filter := PaperFilter{
+		ExamBody: r.URL.Query().Get("exam_body"),
+		ExamYear: year,
+		Subject:  r.URL.Query().Get("subject"),
+	}
+
+	papers, err := h.service.ListPapers(r.Context(), filter)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, papers, nil)
+}
+
+func (h *Handler) LaunchMock(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	var req launchMockParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	exam, err := h.service.LaunchMock(r.Context(), adminID, req)
+	if errors.Is(err, ErrPaperNotFound) {
+		json.JSONError(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgMockExamReady, exam, nil)
+}
@@ -0,0 +1,125 @@
+// Package pastpapers where past exam papers in the question bank are listed and sat as mock exams
+package pastpapers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+)
+
+var ErrPaperNotFound = errors.New(constants.ErrPaperNotFound)
+
+// minutesPerQuestion sets how long a mock exam runs for.
+const minutesPerQuestion = 1
+
+type svc struct {
+	repo *repo.Queries
+	db   *pgxpool.Pool
+}
+
+func NewService(repo *repo.Queries, db *pgxpool.Pool) Service {
+	return &svc{repo: repo, db: db}
+}
+
+func (s *svc) ListPapers(ctx context.Context, filter PaperFilter) ([]repo.ListPastPapersRow, error) {
+	return s.repo.ListPastPapers(ctx, repo.ListPastPapersParams{
+		ExamBody: pgtype.Text{String: filter.ExamBody, Valid: filter.ExamBody != ""},
+		ExamYear: pgtype.Int4{Int32: filter.ExamYear, Valid: filter.ExamYear != 0},
+		Subject:  pgtype.Text{String: filter.Subject, Valid: filter.Subject != ""},
+	})
+}
+
+// LaunchMock returns the published exam that holds a past paper, creating it
+// the first time an admin asks. Candidates then start attempts on it like any
+// other exam, so timing, grading and results need nothing extra. A new mock
+// requires access, so only candidates entitled to it through a voucher, order
+// or plan can sit it. Questions added to the paper since it was first
+// launched are brought in as well and the duration follows them.
+func (s *svc) LaunchMock(ctx context.Context, adminID int64, params launchMockParams) (repo.Exam, error) {
+	body := pgtype.Text{String: params.ExamBody, Valid: true}
+	year := pgtype.Int4{Int32: params.ExamYear, Valid: true}
+	paper := pgtype.Int4{Int32: params.PaperNumber, Valid: true}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.Exam{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	count, err := qtx.CountPaperQuestions(ctx, repo.CountPaperQuestionsParams{
+		ExamBody:    body,
+		ExamYear:    year,
+		PaperNumber: paper,
+	})
+	if err != nil {
+		return repo.Exam{}, err
+	}
+
+	if count == 0 {
+		return repo.Exam{}, ErrPaperNotFound
+	}
+
+	title := fmt.Sprintf("%s %d Paper %d", params.ExamBody, params.ExamYear, params.PaperNumber)
+
+	exam, err := qtx.CreatePaperMockExam(ctx, repo.CreatePaperMockExamParams{
+		Title:           title,
+		Description:     pgtype.Text{String: "Mock exam of the " + title + " past paper", Valid: true},
+		DurationMinutes: int32(count) * minutesPerQuestion,
+		CreatedBy:       adminID,
+		SourceExamBody:  body,
+		SourceYear:      year,
+		SourcePaper:     paper,
+	})
+	created := err == nil
+	if errors.Is(err, pgx.ErrNoRows) {
+		exam, err = qtx.GetPaperMockExam(ctx, repo.GetPaperMockExamParams{
+			SourceExamBody: body,
+			SourceYear:     year,
+			SourcePaper:    paper,
+		})
+	}
+	if err != nil {
+		return repo.Exam{}, err
+	}
+
+	// Only on creation, so an admin who later opens the mock up keeps it open.
+	if created {
+		if err := qtx.RequireMockExamAccess(ctx, exam.ID); err != nil {
+			return repo.Exam{}, err
+		}
+	}
+
+	added, err := qtx.AddPaperQuestionsToExam(ctx, repo.AddPaperQuestionsToExamParams{
+		ExamID:      exam.ID,
+		ExamBody:    body,
+		ExamYear:    year,
+		PaperNumber: paper,
+	})
+	if err != nil {
+		return repo.Exam{}, err
+	}
+
+	if added > 0 && !created {
+		exam, err = qtx.UpdateMockExamDuration(ctx, repo.UpdateMockExamDurationParams{
+			ID:                 exam.ID,
+			MinutesPerQuestion: minutesPerQuestion,
+		})
+		if err != nil {
+			return repo.Exam{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Exam{}, err
+	}
+
+	return exam, nil
+}
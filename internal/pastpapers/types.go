@@ -0,0 +1,25 @@
+package pastpapers
+
+import (
+	"context"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+type Service interface {
+	ListPapers(ctx context.Context, filter PaperFilter) ([]repo.ListPastPapersRow, error)
+	LaunchMock(ctx context.Context, adminID int64, params launchMockParams) (repo.Exam, error)
+}
+
+// PaperFilter narrows the paper list. Zero values match everything.
+type PaperFilter struct {
+	ExamBody string
+	ExamYear int32
+	Subject  string
+}
+
+type launchMockParams struct {
+	ExamBody    string `json:"exam_body" validate:"required,max=50"`
+	ExamYear    int32  `json:"exam_year" validate:"required,gte=1900,lte=2100"`
+	PaperNumber int32  `json:"paper_number" validate:"required,gt=0"`
+}
@@ -0,0 +1,211 @@
+package payments
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/jackc/pgx/v5"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/helpers"
+	"github.com/odundlaw/cbt-backend/internal/json"
+	"github.com/odundlaw/cbt-backend/internal/middlewares"
+	"github.com/odundlaw/cbt-backend/internal/validation"
+)
+
+const maxWebhookBytes = 64 << 10
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service,
+	}
+}
+
+func (h *Handler) ListPrices(w http.ResponseWriter, r *http.Request) {
+	prices, err := h.service.ListPrices(r.Context())
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, prices, nil)
+}
+
+func (h *Handler) SetPrice(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	var req setPriceParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	price, err := h.service.SetPrice(r.Context(), userID, req)
+	if errors.Is(err, pgx.ErrNoRows) {
+		json.JSONError(w, http.StatusNotFound, constants.ErrExamNotFound, nil)
+		return
+	}
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgPriceSaved, price, nil)
+}
+
+func (h *Handler) Checkout(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	var req checkoutParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	order, err := h.service.Checkout(r.Context(), userID, req)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusCreated, constants.MsgOrderCreated, order, nil)
+}
+
+func (h *Handler) ListOrders(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	limit, offset := helpers.Pagination(r)
+
+	orders, err := h.service.ListOrders(r.Context(), userID, limit, offset)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, orders, nil)
+}
+
+func (h *Handler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	order, err := h.service.GetOrder(r.Context(), userID, chi.URLParam(r, "reference"))
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, order, nil)
+}
+
+func (h *Handler) VerifyOrder(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	order, err := h.service.VerifyOrder(r.Context(), userID, chi.URLParam(r, "reference"))
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, order, nil)
+}
+
+// Webhook is called by the provider, not a signed-in user. The raw body is
+// needed as sent to check its signature.
+func (h *Handler) Webhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBytes))
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidWebhookPayload, nil)
+		return
+	}
+
+	if err := h.service.HandleWebhook(r.Context(), r.Header, body); err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgWebhookReceived, nil, nil)
+}
+
+func (h *Handler) ListAllOrders(w http.ResponseWriter, r *http.Request) {
+	limit, offset := helpers.Pagination(r)
+
+	orders, err := h.service.ListAllOrders(r.Context(), limit, offset)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, orders, nil)
+}
+
+func (h *Handler) Refund(w http.ResponseWriter, r *http.Request) {
+	orderID, err := helpers.IDParam(r, "orderID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	order, err := h.service.Refund(r.Context(), orderID)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgOrderRefunded, order, nil)
+}
+
+func writePaymentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrPriceNotFound), errors.Is(err, ErrOrderNotFound), errors.Is(err, ErrPlanNotFound):
+		json.JSONError(w, http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, ErrAlreadyEntitled), errors.Is(err, ErrInvalidOrderTransition):
+		json.JSONError(w, http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, ErrInvalidSignature), errors.Is(err, ErrStaleWebhook):
+		json.JSONError(w, http.StatusUnauthorized, err.Error(), nil)
+	case errors.Is(err, ErrInvalidWebhookPayload):
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+	case errors.Is(err, ErrUnknownTransaction):
+		json.JSONError(w, http.StatusBadGateway, err.Error(), nil)
+	default:
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+	}
+}
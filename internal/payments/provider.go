@@ -0,0 +1,70 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/odundlaw/cbt-backend/internal/config"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+)
+
+var ErrUnknownTransaction = errors.New(constants.ErrUnknownTransaction)
+
+// New returns the provider PAYMENT_PROVIDER names. The fake provider treats
+// every checkout as paid, so it is refused unless APP_ENV is development.
+func New() (Provider, error) {
+	switch config.PaymentProvider {
+	case "paystack":
+		return NewPaystack(config.PaystackBaseURL, config.PaystackSecretKey)
+	case "fake":
+		if config.AppEnv != "development" {
+			return nil, errors.New("the fake payment provider is only available when APP_ENV is development")
+		}
+		return NewFakeProvider(config.PaymentWebhookSecret), nil
+	}
+	return nil, fmt.Errorf("unknown payment provider %q", config.PaymentProvider)
+}
+
+// Provider is a payment gateway. Amounts are in minor currency units and
+// transactions are keyed by the order reference.
+type Provider interface {
+	Name() string
+	Initialize(ctx context.Context, req InitializeRequest) (Checkout, error)
+	Verify(ctx context.Context, reference string) (Verification, error)
+	Refund(ctx context.Context, reference string, amount int64) error
+	// ParseWebhook checks a delivery's signature the way the gateway signs
+	// it and decodes the event it carries.
+	ParseWebhook(header http.Header, body []byte) (WebhookEvent, error)
+}
+
+type TransactionStatus string
+
+const (
+	TransactionPending  TransactionStatus = "pending"
+	TransactionSuccess  TransactionStatus = "success"
+	TransactionFailed   TransactionStatus = "failed"
+	TransactionRefunded TransactionStatus = "refunded"
+)
+
+type InitializeRequest struct {
+	Reference   string
+	Email       string
+	Amount      int64
+	Currency    string
+	CallbackURL string
+}
+
+// Checkout is where the candidate is sent to pay.
+type Checkout struct {
+	ProviderReference string
+	AuthorizationURL  string
+}
+
+type Verification struct {
+	Status   TransactionStatus
+	Amount   int64
+	Currency string
+	Reason   string
+}
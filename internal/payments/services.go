@@ -0,0 +1,426 @@
+// Package payments where candidates pay for exams and practice packs and orders are settled
+package payments
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/config"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+)
+
+const accessSource = "payment"
+
+// transitions is the order state machine. Moving an order to the status it
+// already has is always a no-op, so provider retries are harmless.
+var transitions = map[repo.OrderStatus][]repo.OrderStatus{
+	repo.OrderStatusPending: {repo.OrderStatusPaid, repo.OrderStatusFailed, repo.OrderStatusCancelled},
+	// A late success can still land after a failure was reported.
+	repo.OrderStatusFailed: {repo.OrderStatusPaid},
+	repo.OrderStatusPaid:   {repo.OrderStatusRefunded},
+}
+
+var (
+	ErrPriceNotFound          = errors.New(constants.ErrPriceNotFound)
+	ErrAlreadyEntitled        = errors.New(constants.ErrAlreadyEntitled)
+	ErrOrderNotFound          = errors.New(constants.ErrOrderNotFound)
+	ErrInvalidOrderTransition = errors.New(constants.ErrInvalidOrderTransition)
+	ErrPlanNotFound           = errors.New(constants.ErrPlanNotFound)
+)
+
+type svc struct {
+	repo          *repo.Queries
+	db            *pgxpool.Pool
+	provider      Provider
+	subscriptions Subscriptions
+}
+
+func NewService(repo *repo.Queries, db *pgxpool.Pool, provider Provider, subscriptions Subscriptions) Service {
+	return &svc{repo: repo, db: db, provider: provider, subscriptions: subscriptions}
+}
+
+func (s *svc) ListPrices(ctx context.Context) ([]repo.ProductPrice, error) {
+	return s.repo.ListProductPrices(ctx)
+}
+
+func (s *svc) SetPrice(ctx context.Context, updatedBy int64, params setPriceParams) (repo.ProductPrice, error) {
+	switch params.TargetType {
+	case repo.AccessTargetExam:
+		if _, err := s.repo.GetExamByID(ctx, params.TargetID); err != nil {
+			return repo.ProductPrice{}, err
+		}
+	case repo.AccessTargetPlan:
+		if _, err := s.repo.GetPlan(ctx, params.TargetID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return repo.ProductPrice{}, ErrPlanNotFound
+			}
+			return repo.ProductPrice{}, err
+		}
+	}
+
+	currency := params.Currency
+	if currency == "" {
+		currency = config.PaymentCurrency
+	}
+
+	return s.repo.UpsertProductPrice(ctx, repo.UpsertProductPriceParams{
+		TargetType: params.TargetType,
+		TargetID:   params.TargetID,
+		Amount:     params.Amount,
+		Currency:   currency,
+		UpdatedBy:  updatedBy,
+	})
+}
+
+// Checkout opens an order at the current price and starts a transaction with
+// the provider. The order's authorization_url is where the candidate pays.
+// Plans can be bought again while active, which renews them.
+func (s *svc) Checkout(ctx context.Context, userID int64, params checkoutParams) (repo.Order, error) {
+	price, err := s.repo.GetProductPrice(ctx, repo.GetProductPriceParams{
+		TargetType: params.TargetType,
+		TargetID:   params.TargetID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.Order{}, ErrPriceNotFound
+	}
+	if err != nil {
+		return repo.Order{}, err
+	}
+
+	if params.TargetType == repo.AccessTargetPlan {
+		plan, err := s.repo.GetPlan(ctx, params.TargetID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return repo.Order{}, err
+		}
+		if err != nil || !plan.Active {
+			return repo.Order{}, ErrPriceNotFound
+		}
+	} else {
+		entitled, err := s.repo.HasAccess(ctx, repo.HasAccessParams{
+			UserID:     userID,
+			TargetType: params.TargetType,
+			TargetID:   params.TargetID,
+		})
+		if err != nil {
+			return repo.Order{}, err
+		}
+
+		if entitled {
+			return repo.Order{}, ErrAlreadyEntitled
+		}
+	}
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return repo.Order{}, err
+	}
+
+	reference, err := newReference()
+	if err != nil {
+		return repo.Order{}, err
+	}
+
+	order, err := s.repo.CreateOrder(ctx, repo.CreateOrderParams{
+		Reference:  reference,
+		UserID:     userID,
+		TargetType: price.TargetType,
+		TargetID:   price.TargetID,
+		Amount:     price.Amount,
+		Currency:   price.Currency,
+		Provider:   s.provider.Name(),
+	})
+	if err != nil {
+		return repo.Order{}, err
+	}
+
+	checkout, err := s.provider.Initialize(ctx, InitializeRequest{
+		Reference:   order.Reference,
+		Email:       user.Email,
+		Amount:      order.Amount,
+		Currency:    order.Currency,
+		CallbackURL: config.PaymentCallbackURL,
+	})
+	if err != nil {
+		if _, ferr := s.repo.UpdateOrderStatus(ctx, repo.UpdateOrderStatusParams{
+			ID:            order.ID,
+			Status:        repo.OrderStatusFailed,
+			FailureReason: pgtype.Text{String: err.Error(), Valid: true},
+		}); ferr != nil {
+			return repo.Order{}, ferr
+		}
+		return repo.Order{}, err
+	}
+
+	return s.repo.SetOrderCheckout(ctx, repo.SetOrderCheckoutParams{
+		ID:                order.ID,
+		ProviderReference: pgtype.Text{String: checkout.ProviderReference, Valid: true},
+		AuthorizationUrl:  pgtype.Text{String: checkout.AuthorizationURL, Valid: true},
+	})
+}
+
+func (s *svc) ListOrders(ctx context.Context, userID int64, limit, offset int32) ([]repo.Order, error) {
+	return s.repo.ListUserOrders(ctx, repo.ListUserOrdersParams{UserID: userID, Limit: limit, Offset: offset})
+}
+
+func (s *svc) GetOrder(ctx context.Context, userID int64, reference string) (repo.Order, error) {
+	order, err := s.repo.GetOrderByReference(ctx, reference)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && order.UserID != userID) {
+		return repo.Order{}, ErrOrderNotFound
+	}
+
+	return order, err
+}
+
+// VerifyOrder asks the provider about an order instead of waiting for its
+// webhook, typically when the candidate lands back on the callback page.
+func (s *svc) VerifyOrder(ctx context.Context, userID int64, reference string) (repo.Order, error) {
+	order, err := s.GetOrder(ctx, userID, reference)
+	if err != nil {
+		return repo.Order{}, err
+	}
+
+	if order.Status != repo.OrderStatusPending && order.Status != repo.OrderStatusFailed {
+		return order, nil
+	}
+
+	verification, err := s.provider.Verify(ctx, reference)
+	if err != nil {
+		return repo.Order{}, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.Order{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	order, err = s.settle(ctx, qtx, reference, verification)
+	if err != nil {
+		return repo.Order{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Order{}, err
+	}
+
+	return order, nil
+}
+
+// HandleWebhook applies a provider event once the provider has checked its
+// signature. An event id seen before is acknowledged without being applied
+// again.
+func (s *svc) HandleWebhook(ctx context.Context, header http.Header, body []byte) error {
+	event, err := s.provider.ParseWebhook(header, body)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	if _, err := qtx.RecordWebhookEvent(ctx, repo.RecordWebhookEventParams{
+		Provider:       s.provider.Name(),
+		EventID:        event.ID,
+		EventType:      event.Type,
+		OrderReference: pgtype.Text{String: event.Reference, Valid: event.Reference != ""},
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	// Events that arrive out of order, like a failure after the order was
+	// paid, are logged and acknowledged but change nothing.
+	if err := s.applyEvent(ctx, qtx, event); err != nil && !errors.Is(err, ErrInvalidOrderTransition) {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *svc) ListAllOrders(ctx context.Context, limit, offset int32) ([]repo.Order, error) {
+	return s.repo.ListOrders(ctx, repo.ListOrdersParams{Limit: limit, Offset: offset})
+}
+
+// Refund returns a paid order's money through the provider and withdraws
+// what it unlocked.
+func (s *svc) Refund(ctx context.Context, orderID int64) (repo.Order, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.Order{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	order, err := qtx.GetOrderForUpdate(ctx, orderID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.Order{}, ErrOrderNotFound
+	}
+	if err != nil {
+		return repo.Order{}, err
+	}
+
+	if order.Status != repo.OrderStatusPaid {
+		return repo.Order{}, ErrInvalidOrderTransition
+	}
+
+	if err := s.provider.Refund(ctx, order.Reference, order.Amount); err != nil {
+		return repo.Order{}, err
+	}
+
+	refunded, err := s.transition(ctx, qtx, order, repo.OrderStatusRefunded, "")
+	if err != nil {
+		return repo.Order{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Order{}, err
+	}
+
+	return refunded, nil
+}
+
+func (s *svc) applyEvent(ctx context.Context, q *repo.Queries, event WebhookEvent) error {
+	switch event.Type {
+	case EventChargeSuccess, EventChargeFailed:
+		status := TransactionSuccess
+		if event.Type == EventChargeFailed {
+			status = TransactionFailed
+		}
+
+		_, err := s.settle(ctx, q, event.Reference, Verification{
+			Status:   status,
+			Amount:   event.Amount,
+			Currency: event.Currency,
+			Reason:   event.Reason,
+		})
+		return err
+	case EventRefundProcessed:
+		order, err := s.lockOrder(ctx, q, event.Reference)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.transition(ctx, q, order, repo.OrderStatusRefunded, "")
+		return err
+	default:
+		return nil
+	}
+}
+
+// settle moves an order according to what the provider reports. A success
+// for the wrong amount or currency is treated as a failure.
+func (s *svc) settle(ctx context.Context, q *repo.Queries, reference string, verification Verification) (repo.Order, error) {
+	order, err := s.lockOrder(ctx, q, reference)
+	if err != nil {
+		return repo.Order{}, err
+	}
+
+	switch verification.Status {
+	case TransactionSuccess:
+		if verification.Amount != order.Amount || !strings.EqualFold(verification.Currency, order.Currency) {
+			reason := fmt.Sprintf("provider reported %d %s", verification.Amount, verification.Currency)
+			return s.transition(ctx, q, order, repo.OrderStatusFailed, reason)
+		}
+		return s.transition(ctx, q, order, repo.OrderStatusPaid, "")
+	case TransactionFailed:
+		return s.transition(ctx, q, order, repo.OrderStatusFailed, verification.Reason)
+	default:
+		return order, nil
+	}
+}
+
+// transition applies one step of the state machine along with its side
+// effects on access grants, or on the subscription for plan orders.
+func (s *svc) transition(ctx context.Context, q *repo.Queries, order repo.Order, to repo.OrderStatus, reason string) (repo.Order, error) {
+	if order.Status == to {
+		return order, nil
+	}
+
+	if !slices.Contains(transitions[order.Status], to) {
+		return repo.Order{}, ErrInvalidOrderTransition
+	}
+
+	updated, err := q.UpdateOrderStatus(ctx, repo.UpdateOrderStatusParams{
+		ID:            order.ID,
+		Status:        to,
+		FailureReason: pgtype.Text{String: reason, Valid: reason != ""},
+	})
+	if err != nil {
+		return repo.Order{}, err
+	}
+
+	if order.TargetType == repo.AccessTargetPlan {
+		switch to {
+		case repo.OrderStatusPaid:
+			_, err = s.subscriptions.Extend(ctx, q, order.UserID, order.TargetID)
+		case repo.OrderStatusRefunded:
+			err = s.subscriptions.Terminate(ctx, q, order.UserID, order.TargetID)
+		}
+		if err != nil {
+			return repo.Order{}, err
+		}
+		return updated, nil
+	}
+
+	source := pgtype.Int8{Int64: order.ID, Valid: true}
+
+	switch to {
+	case repo.OrderStatusPaid:
+		if _, err := q.GrantAccess(ctx, repo.GrantAccessParams{
+			UserID:     order.UserID,
+			TargetType: order.TargetType,
+			TargetID:   order.TargetID,
+			Source:     accessSource,
+			SourceID:   source,
+		}); err != nil {
+			return repo.Order{}, err
+		}
+	case repo.OrderStatusRefunded:
+		if _, err := q.RevokeAccessBySource(ctx, repo.RevokeAccessBySourceParams{
+			Source:   accessSource,
+			SourceID: source,
+		}); err != nil {
+			return repo.Order{}, err
+		}
+	}
+
+	return updated, nil
+}
+
+func (s *svc) lockOrder(ctx context.Context, q *repo.Queries, reference string) (repo.Order, error) {
+	order, err := q.GetOrderByReferenceForUpdate(ctx, reference)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.Order{}, ErrOrderNotFound
+	}
+
+	return order, err
+}
+
+func newReference() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return "ORD-" + strings.ToUpper(hex.EncodeToString(b)), nil
+}
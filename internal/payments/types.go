@@ -0,0 +1,40 @@
+package payments
+
+import (
+	"context"
+	"net/http"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+type Service interface {
+	ListPrices(ctx context.Context) ([]repo.ProductPrice, error)
+	SetPrice(ctx context.Context, updatedBy int64, params setPriceParams) (repo.ProductPrice, error)
+	Checkout(ctx context.Context, userID int64, params checkoutParams) (repo.Order, error)
+	ListOrders(ctx context.Context, userID int64, limit, offset int32) ([]repo.Order, error)
+	GetOrder(ctx context.Context, userID int64, reference string) (repo.Order, error)
+	VerifyOrder(ctx context.Context, userID int64, reference string) (repo.Order, error)
+	HandleWebhook(ctx context.Context, header http.Header, body []byte) error
+	ListAllOrders(ctx context.Context, limit, offset int32) ([]repo.Order, error)
+	Refund(ctx context.Context, orderID int64) (repo.Order, error)
+}
+
+// Subscriptions turns paid plan orders into subscription time. Both methods
+// run on the order's transaction.
+type Subscriptions interface {
+	Extend(ctx context.Context, q *repo.Queries, userID, planID int64) (repo.Subscription, error)
+	Terminate(ctx context.Context, q *repo.Queries, userID, planID int64) error
+}
+
+type setPriceParams struct {
+	TargetType repo.AccessTarget `json:"target_type" validate:"required,oneof=exam practice_pack plan"`
+	TargetID   int64             `json:"target_id" validate:"required,gt=0"`
+	Amount     int64             `json:"amount" validate:"required,gt=0"`
+	// Currency is an ISO 4217 code. Defaults to PAYMENT_CURRENCY.
+	Currency string `json:"currency" validate:"omitempty,len=3,uppercase"`
+}
+
+type checkoutParams struct {
+	TargetType repo.AccessTarget `json:"target_type" validate:"required,oneof=exam practice_pack plan"`
+	TargetID   int64             `json:"target_id" validate:"required,gt=0"`
+}
@@ -0,0 +1,237 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const paystackTestKey = "sk_test_123"
+
+func paystackSignature(key, body string) string {
+	mac := hmac.New(sha512.New, []byte(key))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestPaystackParseWebhook(t *testing.T) {
+	charge := `{"event":"charge.success","data":{"id":302961,"status":"success","reference":"ORD-1","amount":50000,"currency":"NGN","gateway_response":"Approved"}}`
+	refund := `{"event":"refund.processed","data":{"id":"1234","status":"processed","transaction_reference":"ORD-2","amount":"50000","currency":"NGN"}}`
+
+	tests := []struct {
+		name      string
+		body      string
+		signature string
+		want      WebhookEvent
+		wantErr   error
+	}{
+		{
+			name:      "charge success",
+			body:      charge,
+			signature: paystackSignature(paystackTestKey, charge),
+			want:      WebhookEvent{ID: "charge.success:302961", Type: EventChargeSuccess, Reference: "ORD-1", Amount: 50000, Currency: "NGN", Reason: "Approved"},
+		},
+		{
+			name:      "refund names the order as the transaction reference",
+			body:      refund,
+			signature: paystackSignature(paystackTestKey, refund),
+			want:      WebhookEvent{ID: "refund.processed:1234", Type: EventRefundProcessed, Reference: "ORD-2", Amount: 50000, Currency: "NGN"},
+		},
+		{
+			name:      "uppercase hex signature",
+			body:      charge,
+			signature: strings.ToUpper(paystackSignature(paystackTestKey, charge)),
+			want:      WebhookEvent{ID: "charge.success:302961", Type: EventChargeSuccess, Reference: "ORD-1", Amount: 50000, Currency: "NGN", Reason: "Approved"},
+		},
+		{name: "no signature", body: charge, wantErr: ErrInvalidSignature},
+		{name: "signature is not hex", body: charge, signature: "not-hex", wantErr: ErrInvalidSignature},
+		{name: "signed with another key", body: charge, signature: paystackSignature("sk_test_other", charge), wantErr: ErrInvalidSignature},
+		{name: "body changed after signing", body: strings.Replace(charge, "50000", "5", 1), signature: paystackSignature(paystackTestKey, charge), wantErr: ErrInvalidSignature},
+		{name: "old timestamped scheme", body: charge, signature: signTimestamped([]byte(paystackTestKey), []byte(charge), time.Now()), wantErr: ErrInvalidSignature},
+		{name: "not JSON", body: "event", signature: paystackSignature(paystackTestKey, "event"), wantErr: ErrInvalidWebhookPayload},
+		{name: "no event", body: `{"data":{"id":1}}`, signature: paystackSignature(paystackTestKey, `{"data":{"id":1}}`), wantErr: ErrInvalidWebhookPayload},
+		{name: "no data id", body: `{"event":"charge.success","data":{}}`, signature: paystackSignature(paystackTestKey, `{"event":"charge.success","data":{}}`), wantErr: ErrInvalidWebhookPayload},
+		{
+			name:      "amount is not a whole number",
+			body:      `{"event":"charge.success","data":{"id":1,"amount":"12.5"}}`,
+			signature: paystackSignature(paystackTestKey, `{"event":"charge.success","data":{"id":1,"amount":"12.5"}}`),
+			wantErr:   ErrInvalidWebhookPayload,
+		},
+	}
+
+	p, err := NewPaystack("https://api.paystack.co", paystackTestKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.signature != "" {
+				header.Set(PaystackSignatureHeader, tt.signature)
+			}
+
+			got, err := p.ParseWebhook(header, []byte(tt.body))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseWebhook() error = %v; want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseWebhook() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFakeParseWebhook(t *testing.T) {
+	secret := []byte("whsec")
+	body := `{"id":"evt_1","type":"charge.failed","data":{"reference":"ORD-1","amount":100,"currency":"NGN","reason":"Declined"}}`
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		body      string
+		signature string
+		want      WebhookEvent
+		wantErr   error
+	}{
+		{
+			name:      "signed now",
+			body:      body,
+			signature: signTimestamped(secret, []byte(body), now),
+			want:      WebhookEvent{ID: "evt_1", Type: EventChargeFailed, Reference: "ORD-1", Amount: 100, Currency: "NGN", Reason: "Declined"},
+		},
+		{name: "too old", body: body, signature: signTimestamped(secret, []byte(body), now.Add(-webhookTolerance-time.Minute)), wantErr: ErrStaleWebhook},
+		{name: "from the future", body: body, signature: signTimestamped(secret, []byte(body), now.Add(webhookTolerance+time.Minute)), wantErr: ErrStaleWebhook},
+		{name: "wrong secret", body: body, signature: signTimestamped([]byte("other"), []byte(body), now), wantErr: ErrInvalidSignature},
+		{name: "no timestamp", body: body, signature: "v1=" + timestampedMAC(secret, "", []byte(body)), wantErr: ErrInvalidSignature},
+		{name: "no signature", body: body, wantErr: ErrInvalidSignature},
+		{name: "no event id", body: `{"type":"charge.success"}`, signature: signTimestamped(secret, []byte(`{"type":"charge.success"}`), now), wantErr: ErrInvalidWebhookPayload},
+	}
+
+	p := NewFakeProvider(secret)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.signature != "" {
+				header.Set(FakeSignatureHeader, tt.signature)
+			}
+
+			got, err := p.ParseWebhook(header, []byte(tt.body))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseWebhook() error = %v; want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseWebhook() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaystackVerify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+paystackTestKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":false,"message":"Invalid key"}`))
+			return
+		}
+
+		switch strings.TrimPrefix(r.URL.Path, "/transaction/verify/") {
+		case "ORD-PAID":
+			w.Write([]byte(`{"status":true,"data":{"status":"success","amount":50000,"currency":"NGN","gateway_response":"Approved"}}`))
+		case "ORD-ABANDONED":
+			w.Write([]byte(`{"status":true,"data":{"status":"abandoned","amount":50000,"currency":"NGN","gateway_response":"The transaction was not completed"}}`))
+		case "ORD-REVERSED":
+			w.Write([]byte(`{"status":true,"data":{"status":"reversed","amount":50000,"currency":"NGN"}}`))
+		case "ORD-ONGOING":
+			w.Write([]byte(`{"status":true,"data":{"status":"ongoing","amount":50000,"currency":"NGN"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"status":false,"message":"Transaction reference not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		reference string
+		want      Verification
+		wantErr   error
+	}{
+		{"ORD-PAID", Verification{Status: TransactionSuccess, Amount: 50000, Currency: "NGN", Reason: "Approved"}, nil},
+		{"ORD-ABANDONED", Verification{Status: TransactionFailed, Amount: 50000, Currency: "NGN", Reason: "The transaction was not completed"}, nil},
+		{"ORD-REVERSED", Verification{Status: TransactionRefunded, Amount: 50000, Currency: "NGN"}, nil},
+		{"ORD-ONGOING", Verification{Status: TransactionPending, Amount: 50000, Currency: "NGN"}, nil},
+		{"ORD-MISSING", Verification{}, ErrUnknownTransaction},
+	}
+
+	p, err := NewPaystack(server.URL+"/", paystackTestKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reference, func(t *testing.T) {
+			got, err := p.Verify(context.Background(), tt.reference)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v; want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// webhookService records what the handler passes on and fails with err.
+type webhookService struct {
+	Service
+	header http.Header
+	body   string
+	err    error
+}
+
+func (s *webhookService) HandleWebhook(_ context.Context, header http.Header, body []byte) error {
+	s.header, s.body = header, string(body)
+	return s.err
+}
+
+func TestWebhookHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"applied", nil, http.StatusOK},
+		{"bad signature", ErrInvalidSignature, http.StatusUnauthorized},
+		{"stale", ErrStaleWebhook, http.StatusUnauthorized},
+		{"bad payload", ErrInvalidWebhookPayload, http.StatusBadRequest},
+		{"unknown order", ErrOrderNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &webhookService{err: tt.err}
+			body := `{"event":"charge.success"}`
+
+			req := httptest.NewRequest(http.MethodPost, "/api/payments/webhook", strings.NewReader(body))
+			req.Header.Set("x-paystack-signature", "abc")
+			rec := httptest.NewRecorder()
+
+			NewHandler(service).Webhook(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d; want %d", rec.Code, tt.wantStatus)
+			}
+			if service.body != body || service.header.Get(PaystackSignatureHeader) != "abc" {
+				t.Errorf("service got body %q and signature %q; want the request's", service.body, service.header.Get(PaystackSignatureHeader))
+			}
+		})
+	}
+}
@@ -0,0 +1,346 @@
+// Package practice where candidates drill a subject's questions untimed and see feedback after every answer
+package practice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/questions"
+)
+
+var (
+	ErrPracticePackNotFound    = errors.New(constants.ErrPracticePackNotFound)
+	ErrPracticeSessionNotFound = errors.New(constants.ErrPracticeSessionNotFound)
+	ErrNoPracticeQuestions     = errors.New(constants.ErrNoPracticeQuestions)
+	ErrNotInPracticeSession    = errors.New(constants.ErrNotInPracticeSession)
+	ErrAlreadyAnswered         = errors.New(constants.ErrPracticeAlreadyAnswered)
+	ErrBlankAnswer             = errors.New(constants.ErrBlankPracticeAnswer)
+	ErrInvalidAnswer           = errors.New(constants.ErrInvalidPracticeAnswer)
+)
+
+const defaultQuestionCount = 20
+
+type svc struct {
+	repo *repo.Queries
+	db   *pgxpool.Pool
+}
+
+func NewService(repo *repo.Queries, db *pgxpool.Pool) Service {
+	return &svc{repo: repo, db: db}
+}
+
+func (s *svc) CreatePack(ctx context.Context, createdBy int64, params createPackParams) (repo.PracticePack, error) {
+	return s.repo.CreatePracticePack(ctx, repo.CreatePracticePackParams{
+		Name:        params.Name,
+		Subject:     params.Subject,
+		Description: pgtype.Text{String: params.Description, Valid: params.Description != ""},
+		CreatedBy:   createdBy,
+	})
+}
+
+func (s *svc) ListPacks(ctx context.Context) ([]repo.PracticePack, error) {
+	return s.repo.ListPracticePacks(ctx)
+}
+
+// GetPack lists the pack's topics with how many practice questions each has,
+// so candidates can pick what to drill.
+func (s *svc) GetPack(ctx context.Context, packID int64) (packResponse, error) {
+	pack, err := s.pack(ctx, packID)
+	if err != nil {
+		return packResponse{}, err
+	}
+
+	topics, err := s.repo.ListSubjectTopics(ctx, pgtype.Text{String: pack.Subject, Valid: true})
+	if err != nil {
+		return packResponse{}, err
+	}
+
+	return packResponse{Pack: pack, Topics: topics}, nil
+}
+
+// StartSession draws random questions from the pack's subject. Short answer
+// and essay questions are left out since they cannot be marked instantly, and
+// so are questions on a draft or published exam, whose keys must stay secret.
+// Past paper mocks don't count; their questions are already public.
+func (s *svc) StartSession(ctx context.Context, userID, packID int64, params startSessionParams) (sessionResponse, error) {
+	pack, err := s.pack(ctx, packID)
+	if err != nil {
+		return sessionResponse{}, err
+	}
+
+	count := params.Count
+	if count == 0 {
+		count = defaultQuestionCount
+	}
+
+	// A nil slice is sent as NULL, which would match no topic at all.
+	topics := params.Topics
+	if topics == nil {
+		topics = []string{}
+	}
+
+	ids, err := s.repo.PickPracticeQuestions(ctx, repo.PickPracticeQuestionsParams{
+		Subject: pgtype.Text{String: pack.Subject, Valid: true},
+		Topics:  topics,
+		Count:   count,
+	})
+	if err != nil {
+		return sessionResponse{}, err
+	}
+
+	if len(ids) == 0 {
+		return sessionResponse{}, ErrNoPracticeQuestions
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return sessionResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	session, err := qtx.CreatePracticeSession(ctx, repo.CreatePracticeSessionParams{
+		UserID:        userID,
+		PackID:        packID,
+		Topics:        topics,
+		QuestionCount: int32(len(ids)),
+	})
+	if err != nil {
+		return sessionResponse{}, err
+	}
+
+	if err := qtx.AddPracticeSessionQuestions(ctx, repo.AddPracticeSessionQuestionsParams{
+		SessionID:   session.ID,
+		QuestionIds: ids,
+	}); err != nil {
+		return sessionResponse{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return sessionResponse{}, err
+	}
+
+	return s.sessionResponse(ctx, session)
+}
+
+func (s *svc) GetSession(ctx context.Context, userID, sessionID int64) (sessionResponse, error) {
+	session, err := s.session(ctx, s.repo, userID, sessionID)
+	if err != nil {
+		return sessionResponse{}, err
+	}
+
+	return s.sessionResponse(ctx, session)
+}
+
+func (s *svc) ListSessions(ctx context.Context, userID int64, limit, offset int32) ([]repo.PracticeSession, error) {
+	return s.repo.ListUserPracticeSessions(ctx, repo.ListUserPracticeSessionsParams{
+		UserID: userID,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// Answer grades one question with the same graders exams use and returns the
+// key and explanation straight away. Each question takes one answer; nothing
+// here touches exam attempts or results. A question that has since gone on a
+// live exam is accepted but not marked, and counts as not correct.
+func (s *svc) Answer(ctx context.Context, userID, sessionID int64, params answerParams) (feedbackResponse, error) {
+	if questions.IsBlank(params.Answer) {
+		return feedbackResponse{}, ErrBlankAnswer
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return feedbackResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	if _, err := s.session(ctx, qtx, userID, sessionID); err != nil {
+		return feedbackResponse{}, err
+	}
+
+	question, err := qtx.GetPracticeQuestionForUpdate(ctx, repo.GetPracticeQuestionForUpdateParams{
+		SessionID:  sessionID,
+		QuestionID: params.QuestionID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return feedbackResponse{}, ErrNotInPracticeSession
+	}
+	if err != nil {
+		return feedbackResponse{}, err
+	}
+
+	if question.AnsweredAt.Valid {
+		return feedbackResponse{}, ErrAlreadyAnswered
+	}
+
+	grader, err := questions.GraderFor(question.Type)
+	if err != nil {
+		return feedbackResponse{}, err
+	}
+
+	// A bad key is the author's fault; anything else is a malformed answer.
+	result, err := grader.Grade(question.AnswerKey, params.Answer)
+	if errors.Is(err, questions.ErrInvalidAnswerKey) {
+		return feedbackResponse{}, err
+	}
+	if err != nil {
+		return feedbackResponse{}, ErrInvalidAnswer
+	}
+
+	// A question put on a live exam after it was drawn is taken but left
+	// unmarked, so neither the feedback nor the session's counts give its key
+	// away.
+	fraction := pgtype.Float8{Float64: result.Fraction, Valid: !question.OnExam}
+
+	if err := qtx.RecordPracticeAnswer(ctx, repo.RecordPracticeAnswerParams{
+		SessionID:  sessionID,
+		QuestionID: params.QuestionID,
+		Answer:     params.Answer,
+		Fraction:   fraction,
+	}); err != nil {
+		return feedbackResponse{}, err
+	}
+
+	correct := fraction.Valid && fraction.Float64 >= 1
+
+	session, err := qtx.AdvancePracticeSession(ctx, repo.AdvancePracticeSessionParams{
+		Correct: correct,
+		ID:      sessionID,
+	})
+	if err != nil {
+		return feedbackResponse{}, err
+	}
+
+	streak, err := qtx.TouchPracticeStreak(ctx, repo.TouchPracticeStreakParams{
+		UserID: userID,
+		Day:    pgtype.Date{Time: today(), Valid: true},
+	})
+	if err != nil {
+		return feedbackResponse{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return feedbackResponse{}, err
+	}
+
+	res := feedbackResponse{
+		Session: session,
+		Streak:  toStreakResponse(streak),
+	}
+
+	if fraction.Valid {
+		res.Correct = &correct
+		res.Fraction = &fraction.Float64
+		res.AnswerKey = question.AnswerKey
+		res.Explanation = textPtr(question.Explanation)
+	}
+
+	return res, nil
+}
+
+func (s *svc) Streak(ctx context.Context, userID int64) (streakResponse, error) {
+	streak, err := s.repo.GetPracticeStreak(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return streakResponse{}, nil
+	}
+	if err != nil {
+		return streakResponse{}, err
+	}
+
+	return toStreakResponse(streak), nil
+}
+
+func (s *svc) pack(ctx context.Context, packID int64) (repo.PracticePack, error) {
+	pack, err := s.repo.GetPracticePack(ctx, packID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.PracticePack{}, ErrPracticePackNotFound
+	}
+
+	return pack, err
+}
+
+// session loads a session only for the candidate who owns it.
+func (s *svc) session(ctx context.Context, q *repo.Queries, userID, sessionID int64) (repo.PracticeSession, error) {
+	session, err := q.GetPracticeSession(ctx, sessionID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && session.UserID != userID) {
+		return repo.PracticeSession{}, ErrPracticeSessionNotFound
+	}
+
+	return session, err
+}
+
+func (s *svc) sessionResponse(ctx context.Context, session repo.PracticeSession) (sessionResponse, error) {
+	rows, err := s.repo.ListPracticeSessionQuestions(ctx, session.ID)
+	if err != nil {
+		return sessionResponse{}, err
+	}
+
+	items := make([]sessionQuestion, 0, len(rows))
+	for _, row := range rows {
+		item := sessionQuestion{
+			QuestionID: row.QuestionID,
+			Position:   row.Position,
+			Type:       row.Type,
+			Stem:       row.Stem,
+			Options:    json.RawMessage(row.Options),
+		}
+
+		if row.AnsweredAt.Valid {
+			item.Answer = json.RawMessage(row.Answer)
+		}
+		// A question now on a live exam shows nothing that hints at its key.
+		if row.AnsweredAt.Valid && row.Fraction.Valid && !row.OnExam {
+			correct := row.Fraction.Float64 >= 1
+			item.Correct = &correct
+			item.Fraction = &row.Fraction.Float64
+			item.AnswerKey = json.RawMessage(row.AnswerKey)
+			item.Explanation = textPtr(row.Explanation)
+		}
+
+		items = append(items, item)
+	}
+
+	return sessionResponse{Session: session, Questions: items}, nil
+}
+
+// toStreakResponse reports a streak as broken once a whole UTC day has
+// passed without practice, even though the row is only reset on the next
+// answer.
+func toStreakResponse(streak repo.PracticeStreak) streakResponse {
+	last := streak.LastPracticeDate.Time
+	day := last.Format(time.DateOnly)
+
+	current := streak.CurrentStreak
+	if last.Before(today().AddDate(0, 0, -1)) {
+		current = 0
+	}
+
+	return streakResponse{
+		CurrentStreak:    current,
+		LongestStreak:    streak.LongestStreak,
+		LastPracticeDate: &day,
+	}
+}
+
+func today() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func textPtr(t pgtype.Text) *string {
+	if !t.Valid {
+		return nil
+	}
+	return &t.String
+}
@@ -0,0 +1,82 @@
+package practice
+
+import (
+	"context"
+	"encoding/json"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+type Service interface {
+	CreatePack(ctx context.Context, createdBy int64, params createPackParams) (repo.PracticePack, error)
+	ListPacks(ctx context.Context) ([]repo.PracticePack, error)
+	GetPack(ctx context.Context, packID int64) (packResponse, error)
+	StartSession(ctx context.Context, userID, packID int64, params startSessionParams) (sessionResponse, error)
+	GetSession(ctx context.Context, userID, sessionID int64) (sessionResponse, error)
+	ListSessions(ctx context.Context, userID int64, limit, offset int32) ([]repo.PracticeSession, error)
+	Answer(ctx context.Context, userID, sessionID int64, params answerParams) (feedbackResponse, error)
+	Streak(ctx context.Context, userID int64) (streakResponse, error)
+}
+
+type createPackParams struct {
+	Name        string `json:"name" validate:"required,max=200"`
+	Subject     string `json:"subject" validate:"required,max=100"`
+	Description string `json:"description"`
+}
+
+type packResponse struct {
+	Pack   repo.PracticePack           `json:"pack"`
+	Topics []repo.ListSubjectTopicsRow `json:"topics"`
+}
+
+type startSessionParams struct {
+	// Topics narrows the drill to some of the pack's topics. Empty means the
+	// whole subject.
+	Topics []string `json:"topics" validate:"dive,required,max=100"`
+	// Count defaults to 20.
+	Count int32 `json:"count" validate:"gte=0,lte=100"`
+}
+
+type answerParams struct {
+	QuestionID int64           `json:"question_id" validate:"required,gt=0"`
+	Answer     json.RawMessage `json:"answer" validate:"required"`
+}
+
+// sessionQuestion hides the key and explanation until the question has been
+// answered.
+type sessionQuestion struct {
+	QuestionID  int64             `json:"question_id"`
+	Position    int32             `json:"position"`
+	Type        repo.QuestionType `json:"type"`
+	Stem        string            `json:"stem"`
+	Options     json.RawMessage   `json:"options"`
+	Answer      json.RawMessage   `json:"answer,omitempty"`
+	Correct     *bool             `json:"correct,omitempty"`
+	Fraction    *float64          `json:"fraction,omitempty"`
+	AnswerKey   json.RawMessage   `json:"answer_key,omitempty"`
+	Explanation *string           `json:"explanation,omitempty"`
+}
+
+type sessionResponse struct {
+	Session   repo.PracticeSession `json:"session"`
+	Questions []sessionQuestion    `json:"questions"`
+}
+
+// feedbackResponse is returned straight after an answer so the candidate can
+// see what was expected and why. Everything but the session and streak is
+// null for a question that is now on a live exam.
+type feedbackResponse struct {
+	Correct     *bool                `json:"correct"`
+	Fraction    *float64             `json:"fraction"`
+	AnswerKey   json.RawMessage      `json:"answer_key"`
+	Explanation *string              `json:"explanation"`
+	Session     repo.PracticeSession `json:"session"`
+	Streak      streakResponse       `json:"streak"`
+}
+
+type streakResponse struct {
+	CurrentStreak int32 `json:"current_streak"`
+	LongestStreak int32 `json:"longest_streak"`
+	// LastPracticeDate is a UTC date, written as 2006-01-02.
+	LastPracticeDate *string `json:"last_practice_date"`
+}
@@ -0,0 +1,161 @@
+package proctoring
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/helpers"
+	"github.com/odundlaw/cbt-backend/internal/json"
+	"github.com/odundlaw/cbt-backend/internal/middlewares"
+	"github.com/odundlaw/cbt-backend/internal/store"
+	"github.com/odundlaw/cbt-backend/internal/validation"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service,
+	}
+}
+
+// Record takes a batch of events from the candidate's exam client.
+func (h *Handler) Record(w http.ResponseWriter, r *http.Request) {
+	attemptID, err := helpers.IDParam(r, "attemptID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req recordParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	res, err := h.service.Record(r.Context(), userID, attemptID, req)
+	if err != nil {
+		writeProctorError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusCreated, constants.MsgProctorEventsRecorded, res, nil)
+}
+
+func (h *Handler) GetRules(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	rules, err := h.service.GetRules(r.Context(), examID)
+	if err != nil {
+		writeProctorError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, rules, nil)
+}
+
+func (h *Handler) SaveRules(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req saveRulesParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	rules, err := h.service.SaveRules(r.Context(), examID, req)
+	if err != nil {
+		writeProctorError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgProctorRulesSaved, rules, nil)
+}
+
+// ListFlags lists the exam's flagged and auto-submitted attempts.
+func (h *Handler) ListFlags(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	limit, offset := helpers.Pagination(r)
+
+	flags, err := h.service.ListFlags(r.Context(), examID, limit, offset)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, flags, nil)
+}
+
+func (h *Handler) Timeline(w http.ResponseWriter, r *http.Request) {
+	attemptID, err := helpers.IDParam(r, "attemptID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	limit, offset := helpers.Pagination(r)
+
+	timeline, err := h.service.Timeline(r.Context(), attemptID, limit, offset)
+	if err != nil {
+		writeProctorError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, timeline, nil)
+}
+
+func writeProctorError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		json.JSONError(w, http.StatusNotFound, constants.ErrNotFound, nil)
+	case errors.Is(err, store.ErrAttemptNotOwner):
+		json.JSONError(w, http.StatusForbidden, err.Error(), nil)
+	case errors.Is(err, store.ErrAttemptClosed), errors.Is(err, store.ErrAttemptExpired):
+		json.JSONError(w, http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, ErrRateLimited):
+		json.JSONError(w, http.StatusTooManyRequests, err.Error(), nil)
+	case errors.Is(err, ErrInvalidEvent), errors.Is(err, ErrDuplicateRule):
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+	case errors.Is(err, ErrInvalidRule):
+		json.JSONError(w, http.StatusUnprocessableEntity, err.Error(), nil)
+	default:
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+	}
+}
@@ -0,0 +1,269 @@
+// Package proctoring where integrity events from remote exam clients are
+// recorded and acted on
+package proctoring
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/config"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/store"
+)
+
+// maxDetailsBytes caps what a client can attach to one event.
+const maxDetailsBytes = 1024
+
+var (
+	ErrRateLimited   = errors.New(constants.ErrProctorRateLimited)
+	ErrInvalidEvent  = errors.New(constants.ErrInvalidProctorEvent)
+	ErrDuplicateRule = errors.New(constants.ErrDuplicateProctorRule)
+	ErrInvalidRule   = errors.New(constants.ErrInvalidProctorRule)
+)
+
+type svc struct {
+	repo      *repo.Queries
+	db        *pgxpool.Pool
+	rdb       *store.Redis
+	submitter Submitter
+}
+
+func NewService(repo *repo.Queries, db *pgxpool.Pool, rdb *store.Redis, submitter Submitter) Service {
+	return &svc{repo: repo, db: db, rdb: rdb, submitter: submitter}
+}
+
+// Record stores a batch of events from the candidate's client and applies
+// the exam's rules to the attempt's new totals. A submit rule submits the
+// attempt once the events are stored; the client should stop the sitting
+// when the response says so. A submit whose submission failed on an earlier
+// batch is retried on the next one.
+func (s *svc) Record(ctx context.Context, userID, attemptID int64, params recordParams) (recordResponse, error) {
+	attempt, err := s.repo.GetAttemptByID(ctx, attemptID)
+	if err != nil {
+		return recordResponse{}, err
+	}
+
+	if attempt.UserID != userID {
+		return recordResponse{}, store.ErrAttemptNotOwner
+	}
+
+	if attempt.Status != repo.AttemptStatusInProgress {
+		return recordResponse{}, store.ErrAttemptClosed
+	}
+
+	events := repo.RecordProctorEventsParams{AttemptID: attemptID}
+	for _, e := range params.Events {
+		details := bytes.TrimSpace(e.Details)
+		if len(details) == 0 || bytes.Equal(details, []byte("null")) {
+			details = []byte("{}")
+		}
+		if len(details) > maxDetailsBytes || details[0] != '{' {
+			return recordResponse{}, ErrInvalidEvent
+		}
+
+		events.EventTypes = append(events.EventTypes, string(e.Type))
+		events.OccurredAts = append(events.OccurredAts, pgtype.Timestamptz{Time: e.OccurredAt, Valid: true})
+		events.Details = append(events.Details, details)
+	}
+
+	// Rejected batches count too, so a client stuck in a loop is held back.
+	total, err := s.rdb.CountProctorEvents(ctx, attemptID, int64(len(params.Events)), time.Minute, time.Now())
+	if err != nil {
+		return recordResponse{}, err
+	}
+	if total > int64(config.ProctorEventsPerMinute) {
+		return recordResponse{}, ErrRateLimited
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return recordResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	res := recordResponse{Actions: []repo.AttemptProctorAction{}}
+
+	if res.Recorded, err = qtx.RecordProctorEvents(ctx, events); err != nil {
+		return recordResponse{}, err
+	}
+
+	counts, err := qtx.IncrementProctorCounts(ctx, repo.IncrementProctorCountsParams{
+		AttemptID:  attemptID,
+		EventTypes: events.EventTypes,
+	})
+	if err != nil {
+		return recordResponse{}, err
+	}
+
+	rules, err := qtx.ListProctorRules(ctx, attempt.ExamID)
+	if err != nil {
+		return recordResponse{}, err
+	}
+
+	byType := make(map[repo.ProctorEventType]repo.ExamProctoringRule, len(rules))
+	for _, rule := range rules {
+		byType[rule.EventType] = rule
+	}
+
+	for _, c := range counts {
+		rule, ok := byType[c.EventType]
+		if !ok {
+			continue
+		}
+
+		for _, threshold := range []struct {
+			action repo.ProctorAction
+			after  pgtype.Int4
+		}{
+			{repo.ProctorActionFlag, rule.FlagAfter},
+			{repo.ProctorActionSubmit, rule.SubmitAfter},
+		} {
+			if !threshold.after.Valid || c.Count < threshold.after.Int32 {
+				continue
+			}
+
+			action, err := qtx.RecordProctorAction(ctx, repo.RecordProctorActionParams{
+				AttemptID:  attemptID,
+				EventType:  c.EventType,
+				Action:     threshold.action,
+				EventCount: c.Count,
+			})
+			// Taken on an earlier batch.
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return recordResponse{}, err
+			}
+
+			res.Actions = append(res.Actions, action)
+		}
+	}
+
+	// The attempt is still in progress, so any submit action on it, new or
+	// from an earlier batch, has yet to go through.
+	actions, err := qtx.ListAttemptProctorActions(ctx, attemptID)
+	if err != nil {
+		return recordResponse{}, err
+	}
+	submit := slices.ContainsFunc(actions, func(a repo.AttemptProctorAction) bool {
+		return a.Action == repo.ProctorActionSubmit
+	})
+
+	if err := tx.Commit(ctx); err != nil {
+		return recordResponse{}, err
+	}
+
+	if submit {
+		if _, err := s.submitter.SubmitAttempt(ctx, userID, attemptID); err != nil {
+			return recordResponse{}, err
+		}
+		res.Submitted = true
+	}
+
+	return res, nil
+}
+
+func (s *svc) GetRules(ctx context.Context, examID int64) ([]repo.ExamProctoringRule, error) {
+	if _, err := s.repo.GetExamByID(ctx, examID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListProctorRules(ctx, examID)
+}
+
+// SaveRules replaces the exam's rules. Attempts already flagged or submitted
+// stay so; new totals are checked against the new rules.
+func (s *svc) SaveRules(ctx context.Context, examID int64, params saveRulesParams) ([]repo.ExamProctoringRule, error) {
+	seen := map[repo.ProctorEventType]bool{}
+	for _, rule := range params.Rules {
+		if seen[rule.EventType] {
+			return nil, ErrDuplicateRule
+		}
+		seen[rule.EventType] = true
+
+		if rule.FlagAfter > 0 && rule.SubmitAfter > 0 && rule.SubmitAfter < rule.FlagAfter {
+			return nil, ErrInvalidRule
+		}
+	}
+
+	if _, err := s.repo.GetExamByID(ctx, examID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	if err := qtx.DeleteProctorRules(ctx, examID); err != nil {
+		return nil, err
+	}
+
+	saved := make([]repo.ExamProctoringRule, 0, len(params.Rules))
+	for _, rule := range params.Rules {
+		row, err := qtx.CreateProctorRule(ctx, repo.CreateProctorRuleParams{
+			ExamID:      examID,
+			EventType:   rule.EventType,
+			FlagAfter:   pgtype.Int4{Int32: rule.FlagAfter, Valid: rule.FlagAfter > 0},
+			SubmitAfter: pgtype.Int4{Int32: rule.SubmitAfter, Valid: rule.SubmitAfter > 0},
+		})
+		if err != nil {
+			return nil, err
+		}
+		saved = append(saved, row)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+// ListFlags lists the actions taken on the exam's attempts, latest first.
+func (s *svc) ListFlags(ctx context.Context, examID int64, limit, offset int32) ([]repo.ListExamProctorActionsRow, error) {
+	return s.repo.ListExamProctorActions(ctx, repo.ListExamProctorActionsParams{ExamID: examID, Limit: limit, Offset: offset})
+}
+
+// Timeline is one candidate's attempt as the proctor sees it. Events are
+// paged; the totals and actions cover the whole attempt.
+func (s *svc) Timeline(ctx context.Context, attemptID int64, limit, offset int32) (timelineResponse, error) {
+	attempt, err := s.repo.GetProctorAttempt(ctx, attemptID)
+	if err != nil {
+		return timelineResponse{}, err
+	}
+
+	counts, err := s.repo.ListAttemptProctorCounts(ctx, attemptID)
+	if err != nil {
+		return timelineResponse{}, err
+	}
+
+	actions, err := s.repo.ListAttemptProctorActions(ctx, attemptID)
+	if err != nil {
+		return timelineResponse{}, err
+	}
+
+	events, err := s.repo.ListAttemptProctorEvents(ctx, repo.ListAttemptProctorEventsParams{
+		AttemptID: attemptID,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return timelineResponse{}, err
+	}
+
+	return timelineResponse{Attempt: attempt, Counts: counts, Actions: actions, Events: events}, nil
+}
@@ -0,0 +1,66 @@
+package proctoring
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+type Service interface {
+	Record(ctx context.Context, userID, attemptID int64, params recordParams) (recordResponse, error)
+	GetRules(ctx context.Context, examID int64) ([]repo.ExamProctoringRule, error)
+	SaveRules(ctx context.Context, examID int64, params saveRulesParams) ([]repo.ExamProctoringRule, error)
+	ListFlags(ctx context.Context, examID int64, limit, offset int32) ([]repo.ListExamProctorActionsRow, error)
+	Timeline(ctx context.Context, attemptID int64, limit, offset int32) (timelineResponse, error)
+}
+
+// Submitter submits an attempt for its candidate once it crosses a submit
+// threshold, which also grades it.
+type Submitter interface {
+	SubmitAttempt(ctx context.Context, userID, attemptID int64) (repo.ExamAttempt, error)
+}
+
+type eventParams struct {
+	Type repo.ProctorEventType `json:"type" validate:"required,oneof=tab_switch fullscreen_exit copy paste window_blur multiple_faces"`
+	// OccurredAt is when the client saw the event, by its own clock.
+	OccurredAt time.Time `json:"occurred_at" validate:"required"`
+	// Details is whatever else the client reports, such as the face count
+	// and confidence behind a multiple_faces event.
+	Details json.RawMessage `json:"details"`
+}
+
+// recordParams is a batch of events. Clients queue events and send them
+// together rather than one request each.
+type recordParams struct {
+	Events []eventParams `json:"events" validate:"required,min=1,max=50,dive"`
+}
+
+type recordResponse struct {
+	Recorded int64 `json:"recorded"`
+	// Actions are the thresholds this batch crossed.
+	Actions   []repo.AttemptProctorAction `json:"actions"`
+	Submitted bool                        `json:"submitted"`
+}
+
+type ruleParams struct {
+	EventType   repo.ProctorEventType `json:"event_type" validate:"required,oneof=tab_switch fullscreen_exit copy paste window_blur multiple_faces"`
+	FlagAfter   int32                 `json:"flag_after" validate:"required_without=SubmitAfter,gte=0"`
+	SubmitAfter int32                 `json:"submit_after" validate:"required_without=FlagAfter,gte=0"`
+}
+
+type saveRulesParams struct {
+	// Rules replace the exam's rules. An empty list turns automatic actions
+	// off; events are still recorded.
+	Rules []ruleParams `json:"rules" validate:"dive"`
+}
+
+// timelineResponse is what an attempt reported, in the order the client saw
+// it, with the totals and the actions they led to.
+type timelineResponse struct {
+	Attempt repo.GetProctorAttemptRow          `json:"attempt"`
+	Counts  []repo.AttemptProctorCount         `json:"counts"`
+	Actions []repo.AttemptProctorAction        `json:"actions"`
+	Events  []repo.ListAttemptProctorEventsRow `json:"events"`
+}
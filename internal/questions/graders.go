@@ -0,0 +1,258 @@
+package questions
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+)
+
+var (
+	ErrUnknownQuestionType = errors.New(constants.ErrUnknownQuestionType)
+	ErrInvalidAnswerKey    = errors.New(constants.ErrInvalidAnswerKey)
+)
+
+// Result is the share of a question's marks an answer earned before any
+// exam policy is applied.
+type Result struct {
+	// Fraction is between 0 and 1. Anything below 1 is partially correct.
+	Fraction float64
+	// Manual is set when the answer has to be scored by a person.
+	Manual bool
+}
+
+// Grader scores answers for one question type. Keys and answers are the raw
+// JSON stored in questions.answer_key and attempt_answers.answer.
+type Grader interface {
+	ValidateKey(key []byte) error
+	Grade(key, answer []byte) (Result, error)
+}
+
+var graders = map[repo.QuestionType]Grader{
+	repo.QuestionTypeSingleChoice:   singleChoiceGrader{},
+	repo.QuestionTypeMultipleChoice: multipleChoiceGrader{},
+	repo.QuestionTypeTrueFalse:      trueFalseGrader{},
+	repo.QuestionTypeNumeric:        numericGrader{},
+	repo.QuestionTypeMatching:       matchingGrader{},
+	repo.QuestionTypeShortAnswer:    manualGrader{},
+	repo.QuestionTypeEssay:          manualGrader{},
+}
+
+// GraderFor returns the grader registered for a question type.
+func GraderFor(t repo.QuestionType) (Grader, error) {
+	g, ok := graders[t]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownQuestionType, t)
+	}
+	return g, nil
+}
+
+// IsBlank reports whether an answer is missing or empty.
+func IsBlank(answer []byte) bool {
+	trimmed := bytes.TrimSpace(answer)
+	switch string(trimmed) {
+	case "", "null", `""`, "[]", "{}":
+		return true
+	}
+	return false
+}
+
+func decodeKey(key []byte, v any) error {
+	if err := json.Unmarshal(key, v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidAnswerKey, err)
+	}
+	return nil
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// singleChoiceGrader expects the key and answer to be one option label, e.g. "B".
+type singleChoiceGrader struct{}
+
+func (singleChoiceGrader) ValidateKey(key []byte) error {
+	var k string
+	if err := decodeKey(key, &k); err != nil {
+		return err
+	}
+	if normalize(k) == "" {
+		return ErrInvalidAnswerKey
+	}
+	return nil
+}
+
+func (singleChoiceGrader) Grade(key, answer []byte) (Result, error) {
+	var k, a string
+	if err := decodeKey(key, &k); err != nil {
+		return Result{}, err
+	}
+	if err := json.Unmarshal(answer, &a); err != nil {
+		return Result{}, err
+	}
+	if normalize(k) == normalize(a) {
+		return Result{Fraction: 1}, nil
+	}
+	return Result{}, nil
+}
+
+// multipleChoiceGrader expects a list of option labels. Every wrong pick
+// cancels out a right one, so selecting everything earns nothing.
+type multipleChoiceGrader struct{}
+
+func (multipleChoiceGrader) ValidateKey(key []byte) error {
+	var k []string
+	if err := decodeKey(key, &k); err != nil {
+		return err
+	}
+	if len(k) == 0 {
+		return ErrInvalidAnswerKey
+	}
+	return nil
+}
+
+func (multipleChoiceGrader) Grade(key, answer []byte) (Result, error) {
+	var k, a []string
+	if err := decodeKey(key, &k); err != nil {
+		return Result{}, err
+	}
+	if err := json.Unmarshal(answer, &a); err != nil {
+		return Result{}, err
+	}
+
+	correct := make(map[string]bool, len(k))
+	for _, opt := range k {
+		correct[normalize(opt)] = true
+	}
+
+	seen := make(map[string]bool, len(a))
+	hits, misses := 0, 0
+	for _, opt := range a {
+		opt = normalize(opt)
+		if seen[opt] {
+			continue
+		}
+		seen[opt] = true
+		if correct[opt] {
+			hits++
+		} else {
+			misses++
+		}
+	}
+
+	return Result{Fraction: math.Max(0, float64(hits-misses)/float64(len(correct)))}, nil
+}
+
+// trueFalseGrader expects a JSON boolean.
+type trueFalseGrader struct{}
+
+func (trueFalseGrader) ValidateKey(key []byte) error {
+	var k bool
+	return decodeKey(key, &k)
+}
+
+func (trueFalseGrader) Grade(key, answer []byte) (Result, error) {
+	var k, a bool
+	if err := decodeKey(key, &k); err != nil {
+		return Result{}, err
+	}
+	if err := json.Unmarshal(answer, &a); err != nil {
+		return Result{}, err
+	}
+	if k == a {
+		return Result{Fraction: 1}, nil
+	}
+	return Result{}, nil
+}
+
+// numericKey accepts any answer within tolerance of value.
+type numericKey struct {
+	Value     *float64 `json:"value"`
+	Tolerance float64  `json:"tolerance"`
+}
+
+type numericGrader struct{}
+
+func (numericGrader) ValidateKey(key []byte) error {
+	var k numericKey
+	if err := decodeKey(key, &k); err != nil {
+		return err
+	}
+	if k.Value == nil || k.Tolerance < 0 {
+		return ErrInvalidAnswerKey
+	}
+	return nil
+}
+
+func (numericGrader) Grade(key, answer []byte) (Result, error) {
+	var k numericKey
+	if err := decodeKey(key, &k); err != nil {
+		return Result{}, err
+	}
+	if k.Value == nil {
+		return Result{}, ErrInvalidAnswerKey
+	}
+
+	var a float64
+	if err := json.Unmarshal(answer, &a); err != nil {
+		return Result{}, err
+	}
+	if math.Abs(a-*k.Value) <= k.Tolerance {
+		return Result{Fraction: 1}, nil
+	}
+	return Result{}, nil
+}
+
+// matchingGrader expects an object pairing each prompt with a choice, e.g.
+// {"1": "c", "2": "a"}. Each correct pair earns an equal share.
+type matchingGrader struct{}
+
+func (matchingGrader) ValidateKey(key []byte) error {
+	var k map[string]string
+	if err := decodeKey(key, &k); err != nil {
+		return err
+	}
+	if len(k) == 0 {
+		return ErrInvalidAnswerKey
+	}
+	return nil
+}
+
+func (matchingGrader) Grade(key, answer []byte) (Result, error) {
+	var k, a map[string]string
+	if err := decodeKey(key, &k); err != nil {
+		return Result{}, err
+	}
+	if err := json.Unmarshal(answer, &a); err != nil {
+		return Result{}, err
+	}
+
+	hits := 0
+	for prompt, choice := range k {
+		if normalize(a[prompt]) == normalize(choice) {
+			hits++
+		}
+	}
+
+	return Result{Fraction: float64(hits) / float64(len(k))}, nil
+}
+
+// manualGrader leaves scoring to a human. Its key holds optional guidance
+// for graders and is not checked.
+type manualGrader struct{}
+
+func (manualGrader) ValidateKey(key []byte) error {
+	if !json.Valid(key) {
+		return ErrInvalidAnswerKey
+	}
+	return nil
+}
+
+func (manualGrader) Grade(key, answer []byte) (Result, error) {
+	return Result{Manual: true}, nil
+}
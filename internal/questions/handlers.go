@@ -0,0 +1,411 @@
+package questions
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/jackc/pgx/v5"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/helpers"
+	"github.com/odundlaw/cbt-backend/internal/json"
+	"github.com/odundlaw/cbt-backend/internal/middlewares"
+	"github.com/odundlaw/cbt-backend/internal/richtext"
+	"github.com/odundlaw/cbt-backend/internal/validation"
+)
+
+type Handler struct {
+	service  Service
+	regrader Regrader
+}
+
+func NewHandler(service Service, regrader Regrader) *Handler {
+	return &Handler{
+		service,
+		regrader,
+	}
+}
+
+func (h *Handler) CreateQuestion(w http.ResponseWriter, r *http.Request) {
+	var req createQuestionParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	adminID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	question, err := h.service.CreateQuestion(r.Context(), adminID, req)
+	if err != nil {
+		writeQuestionError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusCreated, constants.MsgQuestionCreated, question, nil)
+}
+
+// ListQuestions searches the bank by subject, topic, past paper and review
+// status, e.g. ?exam_body=WAEC&exam_year=2019&paper_number=2&status=approved.
+func (h *Handler) ListQuestions(w http.ResponseWriter, r *http.Request) {
+	limit, offset := helpers.Pagination(r)
+
+	query := r.URL.Query()
+	filter := QuestionFilter{
+		Subject:  query.Get("subject"),
+		Topic:    query.Get("topic"),
+		ExamBody: query.Get("exam_body"),
+		Status:   repo.QuestionStatus(query.Get("status")),
+	}
+
+	if err := validation.Validate.Var(filter.Status, "omitempty,oneof=draft in_review approved rejected retired"); err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var err error
+	if filter.ExamYear, err = helpers.OptionalInt32(r, "exam_year"); err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+	if filter.PaperNumber, err = helpers.OptionalInt32(r, "paper_number"); err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	questions, err := h.service.ListQuestions(r.Context(), filter, limit, offset)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, questions, nil)
+}
+
+// Search is full-text search over the bank, with ?q= in web search syntax
+// and the same filters as the facets it returns.
+func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
+	limit, offset := helpers.Pagination(r)
+
+	query := r.URL.Query()
+	search := SearchQuery{
+		Query:      query.Get("q"),
+		Subject:    query.Get("subject"),
+		Topic:      query.Get("topic"),
+		Type:       repo.QuestionType(query.Get("type")),
+		Difficulty: repo.QuestionDifficulty(query.Get("difficulty")),
+		Status:     repo.QuestionStatus(query.Get("status")),
+	}
+
+	if err := validation.Validate.Struct(search); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	res, err := h.service.Search(r.Context(), search, limit, offset)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, res, nil)
+}
+
+func (h *Handler) GetQuestion(w http.ResponseWriter, r *http.Request) {
+	questionID, err := helpers.IDParam(r, "questionID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	question, err := h.service.GetQuestionByID(r.Context(), questionID)
+	if err != nil {
+		writeQuestionError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, question, nil)
+}
+
+// UpdateQuestion saves new content for a question as a new version.
+func (h *Handler) UpdateQuestion(w http.ResponseWriter, r *http.Request) {
+	questionID, err := helpers.IDParam(r, "questionID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req updateQuestionParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	adminID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	question, err := h.service.UpdateQuestion(r.Context(), questionID, adminID, req)
+	if err != nil {
+		writeQuestionError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgQuestionUpdated, question, nil)
+}
+
+// UpdateAnswerKey corrects a question's key and re-grades every submitted
+// attempt of the exams that use it.
+func (h *Handler) UpdateAnswerKey(w http.ResponseWriter, r *http.Request) {
+	questionID, err := helpers.IDParam(r, "questionID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req updateAnswerKeyParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	adminID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	question, err := h.service.UpdateAnswerKey(r.Context(), questionID, adminID, req)
+	if err != nil {
+		writeQuestionError(w, err)
+		return
+	}
+
+	regraded, err := h.regrader.RegradeQuestion(r.Context(), questionID, adminID, req.Reason)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgAnswerKeyUpdated, updateAnswerKeyResponse{
+		Question:         question,
+		AttemptsRegraded: regraded,
+	}, nil)
+}
+
+func (h *Handler) ListVersions(w http.ResponseWriter, r *http.Request) {
+	questionID, err := helpers.IDParam(r, "questionID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	versions, err := h.service.ListVersions(r.Context(), questionID)
+	if err != nil {
+		writeQuestionError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, versions, nil)
+}
+
+// DiffVersions compares two versions, e.g. ?from=1&to=3.
+func (h *Handler) DiffVersions(w http.ResponseWriter, r *http.Request) {
+	questionID, err := helpers.IDParam(r, "questionID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	from, err := helpers.OptionalInt32(r, "from")
+	if err != nil || from <= 0 {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+	to, err := helpers.OptionalInt32(r, "to")
+	if err != nil || to <= 0 {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	diff, err := h.service.DiffVersions(r.Context(), questionID, from, to)
+	if err != nil {
+		writeQuestionError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, diff, nil)
+}
+
+// Rollback restores an earlier version as a new version.
+func (h *Handler) Rollback(w http.ResponseWriter, r *http.Request) {
+	questionID, err := helpers.IDParam(r, "questionID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req rollbackParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	adminID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	question, err := h.service.Rollback(r.Context(), questionID, adminID, req)
+	if err != nil {
+		writeQuestionError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgQuestionRolledBack, question, nil)
+}
+
+// GetUsage shows the exams that use a question and which versions their
+// attempts were shown.
+func (h *Handler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	questionID, err := helpers.IDParam(r, "questionID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	usage, err := h.service.GetUsage(r.Context(), questionID)
+	if err != nil {
+		writeQuestionError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, usage, nil)
+}
+
+// ListVersionAttempts lists the attempts that were shown one version.
+func (h *Handler) ListVersionAttempts(w http.ResponseWriter, r *http.Request) {
+	questionID, err := helpers.IDParam(r, "questionID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	version, err := strconv.ParseInt(chi.URLParam(r, "version"), 10, 32)
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	limit, offset := helpers.Pagination(r)
+
+	attempts, err := h.service.ListVersionAttempts(r.Context(), questionID, int32(version), limit, offset)
+	if err != nil {
+		writeQuestionError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, attempts, nil)
+}
+
+func (h *Handler) AddExamQuestion(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req addExamQuestionParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	examQuestion, err := h.service.AddExamQuestion(r.Context(), examID, req)
+	if err != nil {
+		writeQuestionError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgUpdateSuccessful, examQuestion, nil)
+}
+
+func (h *Handler) ListExamQuestions(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	examQuestions, err := h.service.ListExamQuestions(r.Context(), examID)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, examQuestions, nil)
+}
+
+func writeQuestionError(w http.ResponseWriter, err error) {
+	var field *richtext.FieldError
+	switch {
+	case errors.As(err, &field):
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidMath, []json.FieldError{
+			{Field: field.Field, Message: field.Err.Error()},
+		})
+	case errors.Is(err, pgx.ErrNoRows):
+		json.JSONError(w, http.StatusNotFound, constants.ErrQuestionNotFound, nil)
+	case errors.Is(err, ErrVersionNotFound):
+		json.JSONError(w, http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, ErrQuestionUnchanged), errors.Is(err, ErrNotApproved):
+		json.JSONError(w, http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, ErrInvalidAnswerKey), errors.Is(err, ErrUnknownQuestionType):
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+	default:
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+	}
+}
@@ -0,0 +1,467 @@
+// Package questions where the question bank and per-type graders live
+package questions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/richtext"
+	"github.com/odundlaw/cbt-backend/internal/similarity"
+)
+
+var (
+	ErrVersionNotFound   = errors.New(constants.ErrVersionNotFound)
+	ErrQuestionUnchanged = errors.New(constants.ErrQuestionUnchanged)
+	ErrNotApproved       = errors.New(constants.ErrQuestionNotApproved)
+)
+
+type svc struct {
+	repo *repo.Queries
+	db   *pgxpool.Pool
+}
+
+func NewService(repo *repo.Queries, db *pgxpool.Pool) Service {
+	return &svc{repo: repo, db: db}
+}
+
+func (s *svc) CreateQuestion(ctx context.Context, createdBy int64, params createQuestionParams) (repo.Question, error) {
+	grader, err := GraderFor(params.Type)
+	if err != nil {
+		return repo.Question{}, err
+	}
+
+	if err := grader.ValidateKey(params.AnswerKey); err != nil {
+		return repo.Question{}, err
+	}
+
+	options := []byte(params.Options)
+	if len(options) == 0 {
+		options = []byte("[]")
+	}
+
+	text, err := richtext.PrepareQuestion(richtext.Question{
+		Stem:        params.Stem,
+		Options:     options,
+		Explanation: params.Explanation,
+	})
+	if err != nil {
+		return repo.Question{}, err
+	}
+
+	marks := params.Marks
+	if marks == 0 {
+		marks = 1
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.Question{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	question, err := qtx.CreateQuestion(ctx, repo.CreateQuestionParams{
+		Type:           params.Type,
+		Stem:           text.Stem,
+		Options:        text.Options,
+		AnswerKey:      params.AnswerKey,
+		Explanation:    pgtype.Text{String: text.Explanation, Valid: text.Explanation != ""},
+		Marks:          marks,
+		CreatedBy:      createdBy,
+		Subject:        pgtype.Text{String: params.Subject, Valid: params.Subject != ""},
+		Topic:          pgtype.Text{String: params.Topic, Valid: params.Topic != ""},
+		ExamBody:       pgtype.Text{String: params.ExamBody, Valid: params.ExamBody != ""},
+		ExamYear:       pgtype.Int4{Int32: params.ExamYear, Valid: params.ExamYear != 0},
+		PaperNumber:    pgtype.Int4{Int32: params.PaperNumber, Valid: params.PaperNumber != 0},
+		QuestionNumber: pgtype.Int4{Int32: params.QuestionNumber, Valid: params.QuestionNumber != 0},
+	})
+	if err != nil {
+		return repo.Question{}, err
+	}
+
+	if err := SaveVersion(ctx, qtx, question.ID, createdBy, false, ""); err != nil {
+		return repo.Question{}, err
+	}
+
+	if _, err := similarity.Index(ctx, qtx, question); err != nil {
+		return repo.Question{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Question{}, err
+	}
+
+	return question, nil
+}
+
+// SaveVersion records the question as it now stands as its latest version.
+// It is called in the same transaction as every change to a question's
+// content or key. keyOnly marks a key correction, which keeps the content
+// of the version before it.
+func SaveVersion(ctx context.Context, q *repo.Queries, questionID, changedBy int64, keyOnly bool, reason string) error {
+	_, err := q.CreateQuestionVersion(ctx, repo.CreateQuestionVersionParams{
+		KeyOnly:    keyOnly,
+		Reason:     reason,
+		CreatedBy:  changedBy,
+		QuestionID: questionID,
+	})
+	return err
+}
+
+func (s *svc) GetQuestionByID(ctx context.Context, ID int64) (repo.Question, error) {
+	return s.repo.GetQuestionByID(ctx, ID)
+}
+
+func (s *svc) ListQuestions(ctx context.Context, filter QuestionFilter, limit, offset int32) ([]repo.Question, error) {
+	return s.repo.ListQuestions(ctx, repo.ListQuestionsParams{
+		Subject:     pgtype.Text{String: filter.Subject, Valid: filter.Subject != ""},
+		Topic:       pgtype.Text{String: filter.Topic, Valid: filter.Topic != ""},
+		ExamBody:    pgtype.Text{String: filter.ExamBody, Valid: filter.ExamBody != ""},
+		ExamYear:    pgtype.Int4{Int32: filter.ExamYear, Valid: filter.ExamYear != 0},
+		PaperNumber: pgtype.Int4{Int32: filter.PaperNumber, Valid: filter.PaperNumber != 0},
+		Status:      repo.NullQuestionStatus{QuestionStatus: filter.Status, Valid: filter.Status != ""},
+		Limit:       limit,
+		Offset:      offset,
+	})
+}
+
+// searchFacets are the facets a search is counted by.
+var searchFacets = []string{"subject", "topic", "difficulty", "type"}
+
+// Search ranks matching questions, weighting the stem above the options
+// and both above the explanation.
+func (s *svc) Search(ctx context.Context, query SearchQuery, limit, offset int32) (searchResponse, error) {
+	subject := pgtype.Text{String: query.Subject, Valid: query.Subject != ""}
+	topic := pgtype.Text{String: query.Topic, Valid: query.Topic != ""}
+	questionType := repo.NullQuestionType{QuestionType: query.Type, Valid: query.Type != ""}
+	difficulty := repo.NullQuestionDifficulty{QuestionDifficulty: query.Difficulty, Valid: query.Difficulty != ""}
+	status := repo.NullQuestionStatus{QuestionStatus: query.Status, Valid: query.Status != ""}
+
+	results, err := s.repo.SearchQuestions(ctx, repo.SearchQuestionsParams{
+		Query:      query.Query,
+		Subject:    subject,
+		Topic:      topic,
+		Type:       questionType,
+		Difficulty: difficulty,
+		Status:     status,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		return searchResponse{}, err
+	}
+
+	counts, err := s.repo.SearchQuestionFacets(ctx, repo.SearchQuestionFacetsParams{
+		Query:      query.Query,
+		Subject:    subject,
+		Topic:      topic,
+		Type:       questionType,
+		Difficulty: difficulty,
+		Status:     status,
+	})
+	if err != nil {
+		return searchResponse{}, err
+	}
+
+	res := searchResponse{Results: results, Facets: make(map[string][]facetCount, len(searchFacets))}
+	if res.Results == nil {
+		res.Results = []repo.SearchQuestionsRow{}
+	}
+	for _, facet := range searchFacets {
+		res.Facets[facet] = []facetCount{}
+	}
+	for _, c := range counts {
+		res.Facets[c.Facet] = append(res.Facets[c.Facet], facetCount{Value: c.Value, Count: c.Count})
+		// Every question has a type, so its counts add up to the total.
+		if c.Facet == "type" {
+			res.Total += c.Count
+		}
+	}
+
+	return res, nil
+}
+
+// UpdateQuestion saves new content for a question as a new version. Attempts
+// already started keep the version they were shown.
+func (s *svc) UpdateQuestion(ctx context.Context, ID, editedBy int64, params updateQuestionParams) (repo.Question, error) {
+	question, err := s.repo.GetQuestionByID(ctx, ID)
+	if err != nil {
+		return repo.Question{}, err
+	}
+
+	options := []byte(params.Options)
+	if len(options) == 0 {
+		options = []byte("[]")
+	}
+
+	text, err := richtext.PrepareQuestion(richtext.Question{
+		Stem:        params.Stem,
+		Options:     options,
+		Explanation: params.Explanation,
+	})
+	if err != nil {
+		return repo.Question{}, err
+	}
+
+	marks := params.Marks
+	if marks == 0 {
+		marks = question.Marks
+	}
+
+	update := repo.UpdateQuestionParams{
+		ID:          ID,
+		Stem:        text.Stem,
+		Options:     text.Options,
+		Explanation: pgtype.Text{String: text.Explanation, Valid: text.Explanation != ""},
+		Marks:       marks,
+		Subject:     pgtype.Text{String: params.Subject, Valid: params.Subject != ""},
+		Topic:       pgtype.Text{String: params.Topic, Valid: params.Topic != ""},
+	}
+
+	if update.Stem == question.Stem && sameJSON(update.Options, question.Options) &&
+		update.Explanation == question.Explanation && update.Marks == question.Marks &&
+		update.Subject == question.Subject && update.Topic == question.Topic {
+		return repo.Question{}, ErrQuestionUnchanged
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.Question{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	question, err = qtx.UpdateQuestion(ctx, update)
+	if err != nil {
+		return repo.Question{}, err
+	}
+
+	if err := SaveVersion(ctx, qtx, ID, editedBy, false, params.Reason); err != nil {
+		return repo.Question{}, err
+	}
+
+	if _, err := similarity.Index(ctx, qtx, question); err != nil {
+		return repo.Question{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Question{}, err
+	}
+
+	return question, nil
+}
+
+// UpdateAnswerKey corrects a question's key as a new version. Attempts shown
+// the same content are graded against the corrected key when re-graded.
+func (s *svc) UpdateAnswerKey(ctx context.Context, ID, changedBy int64, params updateAnswerKeyParams) (repo.Question, error) {
+	question, err := s.repo.GetQuestionByID(ctx, ID)
+	if err != nil {
+		return repo.Question{}, err
+	}
+
+	grader, err := GraderFor(question.Type)
+	if err != nil {
+		return repo.Question{}, err
+	}
+
+	if err := grader.ValidateKey(params.AnswerKey); err != nil {
+		return repo.Question{}, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.Question{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	question, err = qtx.UpdateQuestionAnswerKey(ctx, repo.UpdateQuestionAnswerKeyParams{ID: ID, AnswerKey: params.AnswerKey})
+	if err != nil {
+		return repo.Question{}, err
+	}
+
+	if err := SaveVersion(ctx, qtx, ID, changedBy, true, params.Reason); err != nil {
+		return repo.Question{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Question{}, err
+	}
+
+	return question, nil
+}
+
+func (s *svc) ListVersions(ctx context.Context, ID int64) ([]repo.QuestionVersion, error) {
+	if _, err := s.repo.GetQuestionByID(ctx, ID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListQuestionVersions(ctx, ID)
+}
+
+// DiffVersions compares two versions of a question field by field.
+func (s *svc) DiffVersions(ctx context.Context, ID int64, from, to int32) (versionDiff, error) {
+	a, err := s.version(ctx, ID, from)
+	if err != nil {
+		return versionDiff{}, err
+	}
+
+	b, err := s.version(ctx, ID, to)
+	if err != nil {
+		return versionDiff{}, err
+	}
+
+	diff := versionDiff{QuestionID: ID, From: from, To: to, Changes: []fieldChange{}}
+	add := func(field string, changed bool, from, to any) {
+		if changed {
+			diff.Changes = append(diff.Changes, fieldChange{Field: field, From: from, To: to})
+		}
+	}
+
+	add("type", a.Type != b.Type, a.Type, b.Type)
+	add("stem", a.Stem != b.Stem, a.Stem, b.Stem)
+	add("options", !sameJSON(a.Options, b.Options), json.RawMessage(a.Options), json.RawMessage(b.Options))
+	add("answer_key", !sameJSON(a.AnswerKey, b.AnswerKey), json.RawMessage(a.AnswerKey), json.RawMessage(b.AnswerKey))
+	add("explanation", a.Explanation != b.Explanation, a.Explanation, b.Explanation)
+	add("marks", a.Marks != b.Marks, a.Marks, b.Marks)
+	add("subject", a.Subject != b.Subject, a.Subject, b.Subject)
+	add("topic", a.Topic != b.Topic, a.Topic, b.Topic)
+
+	return diff, nil
+}
+
+// Rollback restores an earlier version's content and key as a new version.
+// The restored content counts as new, so attempts shown other versions are
+// not affected.
+func (s *svc) Rollback(ctx context.Context, ID, changedBy int64, params rollbackParams) (repo.Question, error) {
+	question, err := s.repo.GetQuestionByID(ctx, ID)
+	if err != nil {
+		return repo.Question{}, err
+	}
+
+	if _, err := s.version(ctx, ID, params.Version); err != nil {
+		return repo.Question{}, err
+	}
+
+	if params.Version == question.Version {
+		return repo.Question{}, ErrQuestionUnchanged
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.Question{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	question, err = qtx.RestoreQuestionVersion(ctx, repo.RestoreQuestionVersionParams{ID: ID, Version: params.Version})
+	if err != nil {
+		return repo.Question{}, err
+	}
+
+	if err := SaveVersion(ctx, qtx, ID, changedBy, false, params.Reason); err != nil {
+		return repo.Question{}, err
+	}
+
+	if _, err := similarity.Index(ctx, qtx, question); err != nil {
+		return repo.Question{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Question{}, err
+	}
+
+	return question, nil
+}
+
+func (s *svc) GetUsage(ctx context.Context, ID int64) (usageResponse, error) {
+	question, err := s.repo.GetQuestionByID(ctx, ID)
+	if err != nil {
+		return usageResponse{}, err
+	}
+
+	exams, err := s.repo.ListQuestionExams(ctx, ID)
+	if err != nil {
+		return usageResponse{}, err
+	}
+
+	deliveries, err := s.repo.ListQuestionVersionUsage(ctx, ID)
+	if err != nil {
+		return usageResponse{}, err
+	}
+
+	return usageResponse{CurrentVersion: question.Version, Exams: exams, Deliveries: deliveries}, nil
+}
+
+func (s *svc) ListVersionAttempts(ctx context.Context, ID int64, version, limit, offset int32) ([]repo.ListQuestionVersionAttemptsRow, error) {
+	if _, err := s.version(ctx, ID, version); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListQuestionVersionAttempts(ctx, repo.ListQuestionVersionAttemptsParams{
+		QuestionID: ID,
+		Version:    version,
+		Limit:      limit,
+		Offset:     offset,
+	})
+}
+
+func (s *svc) version(ctx context.Context, ID int64, version int32) (repo.QuestionVersion, error) {
+	v, err := s.repo.GetQuestionVersion(ctx, repo.GetQuestionVersionParams{QuestionID: ID, Version: version})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.QuestionVersion{}, ErrVersionNotFound
+	}
+	return v, err
+}
+
+// sameJSON compares two JSON documents by value, since the database keeps
+// its own key order and spacing.
+func sameJSON(a, b []byte) bool {
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return bytes.Equal(a, b)
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// AddExamQuestion puts a question on an exam. Only approved questions can
+// be drawn into exams.
+func (s *svc) AddExamQuestion(ctx context.Context, examID int64, params addExamQuestionParams) (repo.ExamQuestion, error) {
+	question, err := s.repo.GetQuestionByID(ctx, params.QuestionID)
+	if err != nil {
+		return repo.ExamQuestion{}, err
+	}
+
+	if question.Status != repo.QuestionStatusApproved {
+		return repo.ExamQuestion{}, ErrNotApproved
+	}
+
+	section := params.Section
+	if section == "" {
+		section = "general"
+	}
+
+	return s.repo.AddExamQuestion(ctx, repo.AddExamQuestionParams{
+		ExamID:     examID,
+		QuestionID: params.QuestionID,
+		Section:    section,
+		Position:   params.Position,
+		Marks:      pgtype.Float8{Float64: params.Marks, Valid: params.Marks > 0},
+	})
+}
+
+func (s *svc) ListExamQuestions(ctx context.Context, examID int64) ([]repo.ExamQuestion, error) {
+	return s.repo.ListExamQuestions(ctx, examID)
+}
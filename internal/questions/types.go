@@ -0,0 +1,143 @@
+package questions
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+type Service interface {
+	CreateQuestion(ctx context.Context, createdBy int64, params createQuestionParams) (repo.Question, error)
+	GetQuestionByID(ctx context.Context, ID int64) (repo.Question, error)
+	ListQuestions(ctx context.Context, filter QuestionFilter, limit, offset int32) ([]repo.Question, error)
+	Search(ctx context.Context, query SearchQuery, limit, offset int32) (searchResponse, error)
+	UpdateQuestion(ctx context.Context, ID, editedBy int64, params updateQuestionParams) (repo.Question, error)
+	UpdateAnswerKey(ctx context.Context, ID, changedBy int64, params updateAnswerKeyParams) (repo.Question, error)
+	ListVersions(ctx context.Context, ID int64) ([]repo.QuestionVersion, error)
+	DiffVersions(ctx context.Context, ID int64, from, to int32) (versionDiff, error)
+	Rollback(ctx context.Context, ID, changedBy int64, params rollbackParams) (repo.Question, error)
+	GetUsage(ctx context.Context, ID int64) (usageResponse, error)
+	ListVersionAttempts(ctx context.Context, ID int64, version, limit, offset int32) ([]repo.ListQuestionVersionAttemptsRow, error)
+	AddExamQuestion(ctx context.Context, examID int64, params addExamQuestionParams) (repo.ExamQuestion, error)
+	ListExamQuestions(ctx context.Context, examID int64) ([]repo.ExamQuestion, error)
+}
+
+// Regrader re-scores every attempt that used a question after its answer
+// key changes.
+type Regrader interface {
+	RegradeQuestion(ctx context.Context, questionID, changedBy int64, reason string) (int, error)
+}
+
+type createQuestionParams struct {
+	Type        repo.QuestionType `json:"type" validate:"required,oneof=single_choice multiple_choice true_false numeric matching short_answer essay"`
+	Stem        string            `json:"stem" validate:"required"`
+	Options     json.RawMessage   `json:"options"`
+	AnswerKey   json.RawMessage   `json:"answer_key" validate:"required"`
+	Explanation string            `json:"explanation"`
+	Marks       float64           `json:"marks" validate:"omitempty,gt=0"`
+	// Subject and Topic place the question in the bank for practice drills.
+	Subject string `json:"subject" validate:"max=100"`
+	Topic   string `json:"topic" validate:"max=100"`
+	// ExamBody, ExamYear and PaperNumber mark a past question and are set
+	// together. QuestionNumber is its number on the original paper.
+	ExamBody       string `json:"exam_body" validate:"required_with=ExamYear PaperNumber,max=50"`
+	ExamYear       int32  `json:"exam_year" validate:"required_with=ExamBody,omitempty,gte=1900,lte=2100"`
+	PaperNumber    int32  `json:"paper_number" validate:"required_with=ExamBody,omitempty,gt=0"`
+	QuestionNumber int32  `json:"question_number" validate:"excluded_without=ExamBody,omitempty,gt=0"`
+}
+
+// QuestionFilter narrows the question bank. Zero values match everything.
+type QuestionFilter struct {
+	Subject     string
+	Topic       string
+	ExamBody    string
+	ExamYear    int32
+	PaperNumber int32
+	Status      repo.QuestionStatus
+}
+
+// SearchQuery is a full-text search of the bank. Query takes web search
+// syntax: "quoted phrases", or, and -word to leave a word out. The other
+// fields narrow the matches; zero values match everything. Tags name the
+// query string parameters, for validation messages.
+type SearchQuery struct {
+	Query      string                  `json:"q" validate:"required,max=200"`
+	Subject    string                  `json:"subject" validate:"max=100"`
+	Topic      string                  `json:"topic" validate:"max=100"`
+	Type       repo.QuestionType       `json:"type" validate:"omitempty,oneof=single_choice multiple_choice true_false numeric matching short_answer essay"`
+	Difficulty repo.QuestionDifficulty `json:"difficulty" validate:"omitempty,oneof=easy medium hard"`
+	Status     repo.QuestionStatus     `json:"status" validate:"omitempty,oneof=draft in_review approved rejected retired"`
+}
+
+// searchResponse is a page of matches, best first, with matched words
+// wrapped in <mark> in the highlights. Total and Facets cover every match,
+// not just the page: Facets counts them by subject, topic, difficulty and
+// type, with a null value for questions that have none.
+type searchResponse struct {
+	Total   int64                     `json:"total"`
+	Results []repo.SearchQuestionsRow `json:"results"`
+	Facets  map[string][]facetCount   `json:"facets"`
+}
+
+type facetCount struct {
+	Value pgtype.Text `json:"value"`
+	Count int64       `json:"count"`
+}
+
+// updateQuestionParams replaces a question's content. The type stays, and
+// the key is changed through its own endpoint so attempts get re-graded.
+type updateQuestionParams struct {
+	Stem        string          `json:"stem" validate:"required"`
+	Options     json.RawMessage `json:"options"`
+	Explanation string          `json:"explanation"`
+	Marks       float64         `json:"marks" validate:"omitempty,gt=0"`
+	Subject     string          `json:"subject" validate:"max=100"`
+	Topic       string          `json:"topic" validate:"max=100"`
+	Reason      string          `json:"reason" validate:"required,min=3"`
+}
+
+type updateAnswerKeyParams struct {
+	AnswerKey json.RawMessage `json:"answer_key" validate:"required"`
+	Reason    string          `json:"reason" validate:"required,min=3"`
+}
+
+type updateAnswerKeyResponse struct {
+	Question         repo.Question `json:"question"`
+	AttemptsRegraded int           `json:"attempts_regraded"`
+}
+
+type rollbackParams struct {
+	Version int32  `json:"version" validate:"required,gt=0"`
+	Reason  string `json:"reason" validate:"required,min=3"`
+}
+
+// versionDiff lists the fields that differ between two versions.
+type versionDiff struct {
+	QuestionID int64         `json:"question_id"`
+	From       int32         `json:"from"`
+	To         int32         `json:"to"`
+	Changes    []fieldChange `json:"changes"`
+}
+
+type fieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// usageResponse shows the exams a question is on now and how many attempts
+// of each exam were shown each version.
+type usageResponse struct {
+	CurrentVersion int32                              `json:"current_version"`
+	Exams          []repo.ListQuestionExamsRow        `json:"exams"`
+	Deliveries     []repo.ListQuestionVersionUsageRow `json:"deliveries"`
+}
+
+type addExamQuestionParams struct {
+	QuestionID int64   `json:"question_id" validate:"required,gt=0"`
+	Section    string  `json:"section"`
+	Position   int32   `json:"position" validate:"gte=0"`
+	Marks      float64 `json:"marks" validate:"omitempty,gt=0"`
+}
@@ -0,0 +1,295 @@
+// Package results where candidates see their graded attempts
+package results
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+)
+
+var (
+	ErrAttemptNotOwner      = errors.New(constants.ErrAttemptNotOwner)
+	ErrResultNotReleased    = errors.New(constants.ErrResultNotReleased)
+	ErrAnswerReviewDisabled = errors.New(constants.ErrAnswerReviewDisabled)
+)
+
+type svc struct {
+	repo *repo.Queries
+}
+
+func NewService(repo *repo.Queries) Service {
+	return &svc{repo: repo}
+}
+
+func (s *svc) ListResults(ctx context.Context, userID int64, limit, offset int32) ([]resultSummary, error) {
+	rows, err := s.repo.ListUserResults(ctx, repo.ListUserResultsParams{
+		UserID: userID,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	settings := map[int64]repo.ExamResultSetting{}
+	summaries := make([]resultSummary, 0, len(rows))
+
+	for _, row := range rows {
+		setting, ok := settings[row.ExamID]
+		if !ok {
+			if setting, err = s.settings(ctx, row.ExamID); err != nil {
+				return nil, err
+			}
+			settings[row.ExamID] = setting
+		}
+
+		summary := resultSummary{
+			AttemptID: row.AttemptID,
+			ExamID:    row.ExamID,
+			ExamTitle: row.Title,
+			Released:  released(setting, row.Status, now),
+		}
+		if summary.Released {
+			summary.TotalScore = &row.TotalScore
+			summary.MaxScore = &row.MaxScore
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
+
+// GetResult returns a candidate's released result with pass/fail, section
+// breakdown and where they stand among everyone graded on the exam. Every
+// other candidate is counted once, by their best graded attempt.
+func (s *svc) GetResult(ctx context.Context, userID, attemptID int64) (resultResponse, error) {
+	result, setting, err := s.releasedResult(ctx, userID, attemptID)
+	if err != nil {
+		return resultResponse{}, err
+	}
+
+	standing, err := s.repo.GetResultStanding(ctx, repo.GetResultStandingParams{
+		ExamID:     result.ExamID,
+		UserID:     userID,
+		TotalScore: result.TotalScore,
+	})
+	if err != nil {
+		return resultResponse{}, err
+	}
+
+	sections, err := s.repo.ListAttemptSectionScores(ctx, attemptID)
+	if err != nil {
+		return resultResponse{}, err
+	}
+
+	subjects, err := s.repo.ListAttemptSubjectScores(ctx, attemptID)
+	if err != nil {
+		return resultResponse{}, err
+	}
+
+	res := resultResponse{
+		AttemptID:  result.AttemptID,
+		ExamID:     result.ExamID,
+		TotalScore: result.TotalScore,
+		MaxScore:   result.MaxScore,
+		Rank:       standing.Above + 1,
+		Candidates: standing.Total,
+		Sections:   make([]sectionScore, 0, len(sections)),
+		GradedAt:   result.GradedAt.Time,
+		CanReview:  setting.AllowAnswerReview,
+	}
+
+	if result.MaxScore > 0 {
+		res.Percentage = round(result.TotalScore / result.MaxScore * 100)
+	}
+
+	if setting.PassMark.Valid {
+		passed := res.Percentage >= setting.PassMark.Float64
+		res.Passed = &passed
+	}
+
+	// Ties count half, so everyone on the same score shares a percentile.
+	if standing.Total > 0 {
+		below := standing.Total - standing.Above - standing.Tied
+		res.Percentile = round((float64(below) + float64(standing.Tied)/2) / float64(standing.Total) * 100)
+	}
+
+	for _, section := range sections {
+		res.Sections = append(res.Sections, sectionScore{
+			Section:  section.Section,
+			Score:    round(section.Score),
+			MaxScore: round(section.MaxScore),
+		})
+	}
+
+	for _, subject := range subjects {
+		res.Subjects = append(res.Subjects, subjectScore{
+			Subject:     subject.Subject,
+			RawScore:    subject.RawScore,
+			RawMax:      subject.RawMax,
+			ScaledScore: subject.ScaledScore,
+			ScaledMax:   subject.ScaledMax,
+		})
+	}
+
+	state, err := s.repo.GetAdaptiveState(ctx, attemptID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return resultResponse{}, err
+	}
+	if err == nil && state.Items > 0 {
+		res.Ability = &abilityEstimate{
+			Theta: state.Theta,
+			SE:    state.Se,
+			Items: state.Items,
+		}
+	}
+
+	return res, nil
+}
+
+// ReviewAnswers shows a candidate each question with their answer, the
+// correct answer and the explanation, when the exam allows it.
+func (s *svc) ReviewAnswers(ctx context.Context, userID, attemptID int64) ([]reviewItem, error) {
+	_, setting, err := s.releasedResult(ctx, userID, attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !setting.AllowAnswerReview {
+		return nil, ErrAnswerReviewDisabled
+	}
+
+	rows, err := s.repo.ListAttemptReview(ctx, attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]reviewItem, 0, len(rows))
+	for _, row := range rows {
+		item := reviewItem{
+			QuestionID:    row.QuestionID,
+			Type:          row.Type,
+			Section:       row.Section,
+			Stem:          row.Stem,
+			Options:       row.Options,
+			Answer:        row.Answer,
+			CorrectAnswer: row.AnswerKey,
+			Score:         row.Score,
+			MaxScore:      row.MaxScore,
+			Outcome:       row.Outcome,
+		}
+		if row.Explanation.Valid {
+			item.Explanation = &row.Explanation.String
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// GetSettings returns an exam's result settings, falling back to immediate
+// release without answer review when none were saved.
+func (s *svc) GetSettings(ctx context.Context, examID int64) (repo.ExamResultSetting, error) {
+	if _, err := s.repo.GetExamByID(ctx, examID); err != nil {
+		return repo.ExamResultSetting{}, err
+	}
+
+	return s.settings(ctx, examID)
+}
+
+func (s *svc) UpsertSettings(ctx context.Context, examID int64, params upsertSettingsParams) (repo.ExamResultSetting, error) {
+	if _, err := s.repo.GetExamByID(ctx, examID); err != nil {
+		return repo.ExamResultSetting{}, err
+	}
+
+	update := repo.UpsertResultSettingsParams{
+		ExamID:            examID,
+		ReleaseMode:       params.ReleaseMode,
+		AllowAnswerReview: params.AllowAnswerReview,
+	}
+
+	if params.ReleaseAt != nil {
+		update.ReleaseAt = pgtype.Timestamptz{Time: *params.ReleaseAt, Valid: true}
+	}
+
+	if params.PassMark != nil {
+		update.PassMark = pgtype.Float8{Float64: *params.PassMark, Valid: true}
+	}
+
+	return s.repo.UpsertResultSettings(ctx, update)
+}
+
+// PublishResults releases an exam's results now. Exams with no settings are
+// switched to manual release so the publication is recorded.
+func (s *svc) PublishResults(ctx context.Context, examID int64) (repo.ExamResultSetting, error) {
+	if _, err := s.repo.GetExamByID(ctx, examID); err != nil {
+		return repo.ExamResultSetting{}, err
+	}
+
+	return s.repo.PublishResults(ctx, examID)
+}
+
+// releasedResult loads a candidate's own result and fails unless it is fully
+// graded and released.
+func (s *svc) releasedResult(ctx context.Context, userID, attemptID int64) (repo.AttemptResult, repo.ExamResultSetting, error) {
+	result, err := s.repo.GetAttemptResult(ctx, attemptID)
+	if err != nil {
+		return repo.AttemptResult{}, repo.ExamResultSetting{}, err
+	}
+
+	if result.UserID != userID {
+		return repo.AttemptResult{}, repo.ExamResultSetting{}, ErrAttemptNotOwner
+	}
+
+	setting, err := s.settings(ctx, result.ExamID)
+	if err != nil {
+		return repo.AttemptResult{}, repo.ExamResultSetting{}, err
+	}
+
+	if !released(setting, result.Status, time.Now()) {
+		return repo.AttemptResult{}, repo.ExamResultSetting{}, ErrResultNotReleased
+	}
+
+	return result, setting, nil
+}
+
+func (s *svc) settings(ctx context.Context, examID int64) (repo.ExamResultSetting, error) {
+	setting, err := s.repo.GetResultSettings(ctx, examID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.ExamResultSetting{ExamID: examID, ReleaseMode: repo.ResultReleaseModeImmediate}, nil
+	}
+
+	return setting, err
+}
+
+// released reports whether a candidate may see a result. Results still
+// waiting on manual marking are never released.
+func released(setting repo.ExamResultSetting, status repo.ResultStatus, now time.Time) bool {
+	if status != repo.ResultStatusGraded {
+		return false
+	}
+
+	switch setting.ReleaseMode {
+	case repo.ResultReleaseModeImmediate:
+		return true
+	case repo.ResultReleaseModeScheduled:
+		return setting.ReleaseAt.Valid && !now.Before(setting.ReleaseAt.Time)
+	case repo.ResultReleaseModeManual:
+		return setting.ReleasedAt.Valid
+	}
+
+	return false
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,93 @@
+package results
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+type Service interface {
+	ListResults(ctx context.Context, userID int64, limit, offset int32) ([]resultSummary, error)
+	GetResult(ctx context.Context, userID, attemptID int64) (resultResponse, error)
+	ReviewAnswers(ctx context.Context, userID, attemptID int64) ([]reviewItem, error)
+	GetSettings(ctx context.Context, examID int64) (repo.ExamResultSetting, error)
+	UpsertSettings(ctx context.Context, examID int64, params upsertSettingsParams) (repo.ExamResultSetting, error)
+	PublishResults(ctx context.Context, examID int64) (repo.ExamResultSetting, error)
+}
+
+type upsertSettingsParams struct {
+	ReleaseMode repo.ResultReleaseMode `json:"release_mode" validate:"required,oneof=immediate scheduled manual"`
+	// ReleaseAt is when results become visible under scheduled release.
+	ReleaseAt         *time.Time `json:"release_at" validate:"required_if=ReleaseMode scheduled"`
+	PassMark          *float64   `json:"pass_mark" validate:"omitempty,gte=0,lte=100"`
+	AllowAnswerReview bool       `json:"allow_answer_review"`
+}
+
+// resultSummary is one line of a candidate's results list. Scores are left
+// out until the exam's results are released.
+type resultSummary struct {
+	AttemptID  int64    `json:"attempt_id"`
+	ExamID     int64    `json:"exam_id"`
+	ExamTitle  string   `json:"exam_title"`
+	Released   bool     `json:"released"`
+	TotalScore *float64 `json:"total_score,omitempty"`
+	MaxScore   *float64 `json:"max_score,omitempty"`
+}
+
+type sectionScore struct {
+	Section  string  `json:"section"`
+	Score    float64 `json:"score"`
+	MaxScore float64 `json:"max_score"`
+}
+
+type subjectScore struct {
+	Subject     string  `json:"subject"`
+	RawScore    float64 `json:"raw_score"`
+	RawMax      float64 `json:"raw_max"`
+	ScaledScore float64 `json:"scaled_score"`
+	ScaledMax   float64 `json:"scaled_max"`
+}
+
+type resultResponse struct {
+	AttemptID  int64   `json:"attempt_id"`
+	ExamID     int64   `json:"exam_id"`
+	TotalScore float64 `json:"total_score"`
+	MaxScore   float64 `json:"max_score"`
+	Percentage float64 `json:"percentage"`
+	// Passed is nil when the exam has no pass mark.
+	Passed     *bool          `json:"passed"`
+	Rank       int64          `json:"rank"`
+	Candidates int64          `json:"candidates"`
+	Percentile float64        `json:"percentile"`
+	Sections   []sectionScore `json:"sections"`
+	// Subjects holds per-subject scaled scores for combination exams; the
+	// total is then their aggregate.
+	Subjects []subjectScore `json:"subjects,omitempty"`
+	// Ability is the final estimate for adaptive exams, whose total is the
+	// same estimate on the exam's reporting scale.
+	Ability   *abilityEstimate `json:"ability,omitempty"`
+	GradedAt  time.Time        `json:"graded_at"`
+	CanReview bool             `json:"can_review"`
+}
+
+type abilityEstimate struct {
+	Theta float64 `json:"theta"`
+	SE    float64 `json:"se"`
+	Items int32   `json:"items"`
+}
+
+type reviewItem struct {
+	QuestionID    int64             `json:"question_id"`
+	Type          repo.QuestionType `json:"type"`
+	Section       string            `json:"section"`
+	Stem          string            `json:"stem"`
+	Options       json.RawMessage   `json:"options"`
+	Answer        json.RawMessage   `json:"answer"`
+	CorrectAnswer json.RawMessage   `json:"correct_answer"`
+	Explanation   *string           `json:"explanation"`
+	Score         float64           `json:"score"`
+	MaxScore      float64           `json:"max_score"`
+	Outcome       repo.ScoreOutcome `json:"outcome"`
+}
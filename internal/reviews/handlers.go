@@ -0,0 +1,272 @@
+package reviews
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/helpers"
+	"github.com/odundlaw/cbt-backend/internal/json"
+	"github.com/odundlaw/cbt-backend/internal/middlewares"
+	"github.com/odundlaw/cbt-backend/internal/validation"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service,
+	}
+}
+
+func (h *Handler) GetReview(w http.ResponseWriter, r *http.Request) {
+	questionID, err := helpers.IDParam(r, "questionID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	review, err := h.service.GetReview(r.Context(), questionID)
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, review, nil)
+}
+
+func (h *Handler) Submit(w http.ResponseWriter, r *http.Request) {
+	questionID, err := helpers.IDParam(r, "questionID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req submitParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	question, err := h.service.Submit(r.Context(), questionID, userID, req)
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgSubmittedForReview, question, nil)
+}
+
+func (h *Handler) AssignReviewer(w http.ResponseWriter, r *http.Request) {
+	questionID, err := helpers.IDParam(r, "questionID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req assignReviewerParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	adminID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	reviewer, err := h.service.AssignReviewer(r.Context(), questionID, adminID, req)
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgReviewerAssigned, reviewer, nil)
+}
+
+func (h *Handler) RemoveReviewer(w http.ResponseWriter, r *http.Request) {
+	questionID, err := helpers.IDParam(r, "questionID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	reviewerID, err := helpers.IDParam(r, "reviewerID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	if err := h.service.RemoveReviewer(r.Context(), questionID, reviewerID); err != nil {
+		writeReviewError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgReviewerRemoved, nil, nil)
+}
+
+// Decide records the signed-in reviewer's decision on the current version.
+func (h *Handler) Decide(w http.ResponseWriter, r *http.Request) {
+	questionID, err := helpers.IDParam(r, "questionID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req decisionParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	reviewerID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	review, err := h.service.Decide(r.Context(), questionID, reviewerID, req)
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgReviewRecorded, review, nil)
+}
+
+func (h *Handler) AddComment(w http.ResponseWriter, r *http.Request) {
+	questionID, err := helpers.IDParam(r, "questionID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req commentParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	authorID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	comment, err := h.service.AddComment(r.Context(), questionID, authorID, req)
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusCreated, constants.MsgCommentAdded, comment, nil)
+}
+
+func (h *Handler) Retire(w http.ResponseWriter, r *http.Request) {
+	questionID, err := helpers.IDParam(r, "questionID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req retireParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	question, err := h.service.Retire(r.Context(), questionID, userID, req)
+	if err != nil {
+		writeReviewError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgQuestionRetired, question, nil)
+}
+
+// ListQueue lists the questions waiting on the signed-in reviewer.
+func (h *Handler) ListQueue(w http.ResponseWriter, r *http.Request) {
+	reviewerID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	limit, offset := helpers.Pagination(r)
+
+	queue, err := h.service.ListQueue(r.Context(), reviewerID, limit, offset)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, queue, nil)
+}
+
+func writeReviewError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		json.JSONError(w, http.StatusNotFound, constants.ErrQuestionNotFound, nil)
+	case errors.Is(err, ErrReviewerNotFound), errors.Is(err, ErrCommentNotFound):
+		json.JSONError(w, http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, ErrNotAssigned):
+		json.JSONError(w, http.StatusForbidden, err.Error(), nil)
+	case errors.Is(err, ErrNotReviewer), errors.Is(err, ErrOwnQuestion):
+		json.JSONError(w, http.StatusUnprocessableEntity, err.Error(), nil)
+	case errors.Is(err, ErrInvalidTransition), errors.Is(err, ErrNotInReview):
+		json.JSONError(w, http.StatusConflict, err.Error(), nil)
+	default:
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+	}
+}
@@ -0,0 +1,332 @@
+// Package reviews where questions are reviewed and approved before they can be used
+package reviews
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/config"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+)
+
+// transitions is the review state machine. A content edit also sends an
+// approved or rejected question back to draft, outside of review.
+var transitions = map[repo.QuestionStatus][]repo.QuestionStatus{
+	repo.QuestionStatusDraft:    {repo.QuestionStatusInReview, repo.QuestionStatusRetired},
+	repo.QuestionStatusInReview: {repo.QuestionStatusApproved, repo.QuestionStatusRejected, repo.QuestionStatusRetired},
+	repo.QuestionStatusApproved: {repo.QuestionStatusRetired},
+	repo.QuestionStatusRejected: {repo.QuestionStatusInReview, repo.QuestionStatusRetired},
+	// A retired question comes back through review.
+	repo.QuestionStatusRetired: {repo.QuestionStatusInReview},
+}
+
+var (
+	ErrInvalidTransition = errors.New(constants.ErrReviewTransition)
+	ErrNotReviewer       = errors.New(constants.ErrNotReviewer)
+	ErrOwnQuestion       = errors.New(constants.ErrOwnQuestionReview)
+	ErrNotAssigned       = errors.New(constants.ErrNotAssigned)
+	ErrReviewerNotFound  = errors.New(constants.ErrReviewerNotFound)
+	ErrCommentNotFound   = errors.New(constants.ErrCommentNotFound)
+	ErrNotInReview       = errors.New(constants.ErrNotInReview)
+)
+
+type svc struct {
+	repo *repo.Queries
+	db   *pgxpool.Pool
+}
+
+func NewService(repo *repo.Queries, db *pgxpool.Pool) Service {
+	return &svc{repo: repo, db: db}
+}
+
+func (s *svc) GetReview(ctx context.Context, questionID int64) (reviewResponse, error) {
+	return s.review(ctx, s.repo, questionID)
+}
+
+// Submit sends a question to its reviewers. Decisions from an earlier round
+// are cleared, so every reviewer decides again.
+func (s *svc) Submit(ctx context.Context, questionID, userID int64, params submitParams) (repo.Question, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.Question{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	question, err := s.transition(ctx, qtx, questionID, repo.QuestionStatusInReview)
+	if err != nil {
+		return repo.Question{}, err
+	}
+
+	if err := qtx.ClearReviewDecisions(ctx, questionID); err != nil {
+		return repo.Question{}, err
+	}
+
+	if err := comment(ctx, qtx, question, userID, params.Comment); err != nil {
+		return repo.Question{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Question{}, err
+	}
+
+	return question, nil
+}
+
+// AssignReviewer adds a reviewer to a question. Reviewers must hold the
+// reviewer permission and cannot review questions they wrote.
+func (s *svc) AssignReviewer(ctx context.Context, questionID, assignedBy int64, params assignReviewerParams) (repo.QuestionReviewer, error) {
+	question, err := s.repo.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		return repo.QuestionReviewer{}, err
+	}
+
+	if question.CreatedBy == params.ReviewerID {
+		return repo.QuestionReviewer{}, ErrOwnQuestion
+	}
+
+	allowed, err := s.repo.HasUserPermission(ctx, repo.HasUserPermissionParams{
+		UserID:     params.ReviewerID,
+		Permission: repo.AdminPermissionReviewer,
+	})
+	if err != nil {
+		return repo.QuestionReviewer{}, err
+	}
+	if !allowed {
+		return repo.QuestionReviewer{}, ErrNotReviewer
+	}
+
+	return s.repo.AssignQuestionReviewer(ctx, repo.AssignQuestionReviewerParams{
+		QuestionID: questionID,
+		ReviewerID: params.ReviewerID,
+		AssignedBy: assignedBy,
+	})
+}
+
+func (s *svc) RemoveReviewer(ctx context.Context, questionID, reviewerID int64) error {
+	n, err := s.repo.RemoveQuestionReviewer(ctx, repo.RemoveQuestionReviewerParams{
+		QuestionID: questionID,
+		ReviewerID: reviewerID,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrReviewerNotFound
+	}
+	return nil
+}
+
+// Decide records a reviewer's decision on the question's current version.
+// One rejection sends the question back to its author; it is approved once
+// REVIEW_REQUIRED_APPROVALS reviewers have approved the current version.
+func (s *svc) Decide(ctx context.Context, questionID, reviewerID int64, params decisionParams) (reviewResponse, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return reviewResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	question, err := qtx.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		return reviewResponse{}, err
+	}
+
+	if question.Status != repo.QuestionStatusInReview {
+		return reviewResponse{}, ErrNotInReview
+	}
+
+	version := pgtype.Int4{Int32: question.Version, Valid: true}
+
+	if _, err := qtx.RecordReviewDecision(ctx, repo.RecordReviewDecisionParams{
+		QuestionID: questionID,
+		ReviewerID: reviewerID,
+		Decision:   repo.NullReviewDecision{ReviewDecision: params.Decision, Valid: true},
+		Version:    version,
+	}); errors.Is(err, pgx.ErrNoRows) {
+		return reviewResponse{}, ErrNotAssigned
+	} else if err != nil {
+		return reviewResponse{}, err
+	}
+
+	if err := comment(ctx, qtx, question, reviewerID, params.Comment); err != nil {
+		return reviewResponse{}, err
+	}
+
+	if params.Decision == repo.ReviewDecisionRejected {
+		if _, err := s.transition(ctx, qtx, questionID, repo.QuestionStatusRejected); err != nil {
+			return reviewResponse{}, err
+		}
+	} else {
+		approvals, err := qtx.CountReviewApprovals(ctx, repo.CountReviewApprovalsParams{QuestionID: questionID, Version: version})
+		if err != nil {
+			return reviewResponse{}, err
+		}
+		if approvals >= int64(config.ReviewRequiredApprovals) {
+			if _, err := s.transition(ctx, qtx, questionID, repo.QuestionStatusApproved); err != nil {
+				return reviewResponse{}, err
+			}
+		}
+	}
+
+	res, err := s.review(ctx, qtx, questionID)
+	if err != nil {
+		return reviewResponse{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return reviewResponse{}, err
+	}
+
+	return res, nil
+}
+
+func (s *svc) AddComment(ctx context.Context, questionID, authorID int64, params commentParams) (repo.QuestionReviewComment, error) {
+	question, err := s.repo.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		return repo.QuestionReviewComment{}, err
+	}
+
+	if params.ParentID != 0 {
+		parent, err := s.repo.GetReviewComment(ctx, params.ParentID)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && parent.QuestionID != questionID) {
+			return repo.QuestionReviewComment{}, ErrCommentNotFound
+		}
+		if err != nil {
+			return repo.QuestionReviewComment{}, err
+		}
+	}
+
+	return s.repo.CreateReviewComment(ctx, repo.CreateReviewCommentParams{
+		QuestionID: questionID,
+		ParentID:   pgtype.Int8{Int64: params.ParentID, Valid: params.ParentID != 0},
+		AuthorID:   authorID,
+		Version:    question.Version,
+		Body:       params.Body,
+	})
+}
+
+// Retire withdraws a question from use. Exams it is already on keep it, but
+// it can't be added to new ones or drawn for practice or adaptive tests.
+func (s *svc) Retire(ctx context.Context, questionID, userID int64, params retireParams) (repo.Question, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.Question{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	question, err := s.transition(ctx, qtx, questionID, repo.QuestionStatusRetired)
+	if err != nil {
+		return repo.Question{}, err
+	}
+
+	if err := comment(ctx, qtx, question, userID, params.Reason); err != nil {
+		return repo.Question{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Question{}, err
+	}
+
+	return question, nil
+}
+
+// ListQueue lists the questions in review waiting on reviewerID's decision.
+func (s *svc) ListQueue(ctx context.Context, reviewerID int64, limit, offset int32) ([]repo.Question, error) {
+	return s.repo.ListReviewQueue(ctx, repo.ListReviewQueueParams{ReviewerID: reviewerID, Limit: limit, Offset: offset})
+}
+
+// transition moves a question one step through the state machine.
+func (s *svc) transition(ctx context.Context, q *repo.Queries, questionID int64, to repo.QuestionStatus) (repo.Question, error) {
+	question, err := q.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		return repo.Question{}, err
+	}
+
+	// A question merged into another stays retired.
+	if question.MergedInto.Valid || !slices.Contains(transitions[question.Status], to) {
+		return repo.Question{}, ErrInvalidTransition
+	}
+
+	return q.SetQuestionStatus(ctx, repo.SetQuestionStatusParams{ID: questionID, Status: to})
+}
+
+func (s *svc) review(ctx context.Context, q *repo.Queries, questionID int64) (reviewResponse, error) {
+	question, err := q.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		return reviewResponse{}, err
+	}
+
+	reviewers, err := q.ListQuestionReviewers(ctx, questionID)
+	if err != nil {
+		return reviewResponse{}, err
+	}
+
+	approvals, err := q.CountReviewApprovals(ctx, repo.CountReviewApprovalsParams{
+		QuestionID: questionID,
+		Version:    pgtype.Int4{Int32: question.Version, Valid: true},
+	})
+	if err != nil {
+		return reviewResponse{}, err
+	}
+
+	comments, err := q.ListReviewComments(ctx, questionID)
+	if err != nil {
+		return reviewResponse{}, err
+	}
+
+	return reviewResponse{
+		QuestionID:        questionID,
+		Status:            question.Status,
+		Version:           question.Version,
+		RequiredApprovals: config.ReviewRequiredApprovals,
+		Approvals:         approvals,
+		Reviewers:         reviewers,
+		Comments:          threads(comments),
+	}, nil
+}
+
+// threads nests replies under their parents. Comments come oldest first, so
+// a parent is always seen before its replies.
+func threads(comments []repo.ListReviewCommentsRow) []*commentThread {
+	roots := []*commentThread{}
+	byID := make(map[int64]*commentThread, len(comments))
+
+	for _, c := range comments {
+		t := &commentThread{ListReviewCommentsRow: c, Replies: []*commentThread{}}
+		byID[c.ID] = t
+
+		if parent, ok := byID[c.ParentID.Int64]; c.ParentID.Valid && ok {
+			parent.Replies = append(parent.Replies, t)
+			continue
+		}
+		roots = append(roots, t)
+	}
+
+	return roots
+}
+
+// comment adds body to the question's discussion, if there is one.
+func comment(ctx context.Context, q *repo.Queries, question repo.Question, authorID int64, body string) error {
+	if body == "" {
+		return nil
+	}
+
+	_, err := q.CreateReviewComment(ctx, repo.CreateReviewCommentParams{
+		QuestionID: question.ID,
+		AuthorID:   authorID,
+		Version:    question.Version,
+		Body:       body,
+	})
+	return err
+}
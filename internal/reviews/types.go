@@ -0,0 +1,61 @@
+package reviews
+
+import (
+	"context"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+type Service interface {
+	GetReview(ctx context.Context, questionID int64) (reviewResponse, error)
+	Submit(ctx context.Context, questionID, userID int64, params submitParams) (repo.Question, error)
+	AssignReviewer(ctx context.Context, questionID, assignedBy int64, params assignReviewerParams) (repo.QuestionReviewer, error)
+	RemoveReviewer(ctx context.Context, questionID, reviewerID int64) error
+	Decide(ctx context.Context, questionID, reviewerID int64, params decisionParams) (reviewResponse, error)
+	AddComment(ctx context.Context, questionID, authorID int64, params commentParams) (repo.QuestionReviewComment, error)
+	Retire(ctx context.Context, questionID, userID int64, params retireParams) (repo.Question, error)
+	ListQueue(ctx context.Context, reviewerID int64, limit, offset int32) ([]repo.Question, error)
+}
+
+type submitParams struct {
+	// Comment is an optional note to the reviewers.
+	Comment string `json:"comment" validate:"max=2000"`
+}
+
+type assignReviewerParams struct {
+	ReviewerID int64 `json:"reviewer_id" validate:"required,gt=0"`
+}
+
+type decisionParams struct {
+	Decision repo.ReviewDecision `json:"decision" validate:"required,oneof=approved rejected"`
+	// A rejection must say what to fix.
+	Comment string `json:"comment" validate:"required_if=Decision rejected,max=2000"`
+}
+
+type commentParams struct {
+	Body string `json:"body" validate:"required,max=2000"`
+	// ParentID makes the comment a reply.
+	ParentID int64 `json:"parent_id" validate:"omitempty,gt=0"`
+}
+
+type retireParams struct {
+	Reason string `json:"reason" validate:"required,min=3,max=2000"`
+}
+
+// reviewResponse is where a question stands in review: its reviewers, their
+// decisions on the current version and the discussion so far.
+type reviewResponse struct {
+	QuestionID        int64                           `json:"question_id"`
+	Status            repo.QuestionStatus             `json:"status"`
+	Version           int32                           `json:"version"`
+	RequiredApprovals int                             `json:"required_approvals"`
+	Approvals         int64                           `json:"approvals"`
+	Reviewers         []repo.ListQuestionReviewersRow `json:"reviewers"`
+	Comments          []*commentThread                `json:"comments"`
+}
+
+// commentThread is a comment with its replies, oldest first.
+type commentThread struct {
+	repo.ListReviewCommentsRow
+	Replies []*commentThread `json:"replies"`
+}
@@ -0,0 +1,348 @@
+package richtext
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Chemical formulas are written inside \ce{...} the way the mhchem package
+// writes them: "2H2 + O2 -> 2H2O", "SO4^2-", "Na+", "CuSO4.5H2O",
+// "NaCl(aq)" and "A <=>[heat] B". Digits after an element or a bracket are
+// subscripts; charges follow a caret, or a lone + or - at the end of a
+// species. Element symbols are checked against the periodic table.
+
+var elements = map[string]bool{}
+
+func init() {
+	for _, s := range strings.Fields(`H He Li Be B C N O F Ne Na Mg Al Si P S Cl Ar K Ca
+		Sc Ti V Cr Mn Fe Co Ni Cu Zn Ga Ge As Se Br Kr Rb Sr Y Zr Nb Mo Tc Ru Rh Pd
+		Ag Cd In Sn Sb Te I Xe Cs Ba La Ce Pr Nd Pm Sm Eu Gd Tb Dy Ho Er Tm Yb Lu Hf
+		Ta W Re Os Ir Pt Au Hg Tl Pb Bi Po At Rn Fr Ra Ac Th Pa U Np Pu Am Cm Bk Cf
+		Es Fm Md No Lr Rf Db Sg Bh Hs Mt Ds Rg Cn Nh Fl Mc Lv Ts Og D T`) {
+		elements[s] = true
+	}
+}
+
+// arrows lists reaction arrows, longest first so "<=>" is not read as "<=".
+var arrows = []struct{ src, mark string }{
+	{"<=>", "⇌"}, {"<->", "↔"}, {"->", "→"}, {"<-", "←"},
+}
+
+var states = []string{"(aq)", "(s)", "(l)", "(g)"}
+
+type chemParser struct {
+	src []rune
+	pos int
+}
+
+func parseChem(src string) (*mnode, error) {
+	p := &chemParser{src: []rune(src)}
+	row := mel("mrow")
+
+	for {
+		spaced := p.skipSpace()
+		if p.eof() {
+			break
+		}
+
+		if a, ok := p.arrow(); ok {
+			row.kids = append(row.kids, a)
+			continue
+		}
+
+		c := p.peek()
+		switch {
+		case (c == '+' || c == '=') && spaced && p.spaceAfter():
+			p.pos++
+			row.kids = append(row.kids, mtok("mo", string(c)))
+			continue
+		case c == '^' && spaced && p.spaceAfter():
+			p.pos++
+			row.kids = append(row.kids, mtok("mo", "↑"))
+			continue
+		case c == 'v' && spaced && p.spaceAfter():
+			p.pos++
+			row.kids = append(row.kids, mtok("mo", "↓"))
+			continue
+		}
+
+		species, err := p.species()
+		if err != nil {
+			return nil, fmt.Errorf(`\ce: %w`, err)
+		}
+		row.kids = append(row.kids, species...)
+	}
+
+	if len(row.kids) == 0 {
+		return nil, fmt.Errorf(`\ce is empty`)
+	}
+	return row, nil
+}
+
+func (p *chemParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *chemParser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *chemParser) skipSpace() bool {
+	start := p.pos
+	for !p.eof() && unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+	return p.pos > start || p.pos == 0
+}
+
+func (p *chemParser) spaceAfter() bool {
+	return p.pos+1 >= len(p.src) || unicode.IsSpace(p.src[p.pos+1])
+}
+
+func (p *chemParser) hasPrefix(s string) bool {
+	return strings.HasPrefix(string(p.src[p.pos:]), s)
+}
+
+// arrow reads a reaction arrow with any conditions written above and below
+// it in brackets, as in "->[heat][catalyst]".
+func (p *chemParser) arrow() (*mnode, bool) {
+	for _, a := range arrows {
+		if !p.hasPrefix(a.src) {
+			continue
+		}
+		p.pos += len(a.src)
+		mark := mtok("mo", a.mark).set("stretchy", "true")
+
+		var labels []*mnode
+		for len(labels) < 2 && p.peek() == '[' {
+			end := strings.IndexRune(string(p.src[p.pos:]), ']')
+			if end < 0 {
+				break
+			}
+			labels = append(labels, mtok("mtext", string(p.src[p.pos+1:p.pos+end])))
+			p.pos += end + 1
+		}
+
+		switch len(labels) {
+		case 1:
+			return mel("mover", mark, labels[0]), true
+		case 2:
+			return mel("munderover", mark, labels[1], labels[0]), true
+		}
+		return mark, true
+	}
+	return nil, false
+}
+
+// unit is one element or bracketed group with its subscript and charge.
+type unit struct {
+	base     *mnode
+	sub, sup string
+}
+
+func (u unit) node() *mnode {
+	switch {
+	case u.sub != "" && u.sup != "":
+		return mel("msubsup", u.base, mtok("mn", u.sub), mtok("mo", u.sup))
+	case u.sub != "":
+		return mel("msub", u.base, mtok("mn", u.sub))
+	case u.sup != "":
+		return mel("msup", u.base, mtok("mo", u.sup))
+	}
+	return u.base
+}
+
+// species reads a coefficient and formula up to the next space.
+func (p *chemParser) species() ([]*mnode, error) {
+	var out []*mnode
+
+	if coef := p.digits(true); coef != "" {
+		out = append(out, mtok("mn", coef))
+	}
+
+	units, err := p.formula(0)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range units {
+		out = append(out, u.node())
+	}
+
+	for _, s := range states {
+		if p.hasPrefix(s) {
+			p.pos += len(s)
+			out = append(out, mtok("mtext", s))
+			break
+		}
+	}
+
+	if !p.eof() && !unicode.IsSpace(p.peek()) {
+		return nil, fmt.Errorf("unexpected %q", p.peek())
+	}
+	return out, nil
+}
+
+func (p *chemParser) digits(fraction bool) string {
+	start := p.pos
+	for !p.eof() && (unicode.IsDigit(p.peek()) || fraction && p.pos > start && p.peek() == '/') {
+		p.pos++
+	}
+	return string(p.src[start:p.pos])
+}
+
+// formula reads elements and bracketed groups. closer is the bracket that
+// ends a group, or 0 at the top level.
+func (p *chemParser) formula(closer rune) ([]unit, error) {
+	var units []unit
+	for !p.eof() {
+		c := p.peek()
+		switch {
+		case c == closer:
+			return units, nil
+
+		case unicode.IsUpper(c):
+			sym := string(c)
+			p.pos++
+			if unicode.IsLower(p.peek()) && elements[sym+string(p.peek())] {
+				sym += string(p.peek())
+				p.pos++
+			}
+			if !elements[sym] {
+				if unicode.IsLower(p.peek()) {
+					sym += string(p.peek())
+				}
+				return nil, fmt.Errorf("unknown element %s", sym)
+			}
+			units = append(units, unit{base: mtok("mi", sym).set("mathvariant", "normal")})
+
+		case c == 'e' && len(units) == 0:
+			p.pos++
+			units = append(units, unit{base: mtok("mi", "e")})
+
+		case c == '(' || c == '[':
+			if closer == 0 && p.isState() {
+				return units, nil
+			}
+			close := ')'
+			if c == '[' {
+				close = ']'
+			}
+			p.pos++
+			inner, err := p.formula(close)
+			if err != nil {
+				return nil, err
+			}
+			if p.peek() != close {
+				return nil, fmt.Errorf("%c is never closed", c)
+			}
+			p.pos++
+			row := mel("mrow", mtok("mo", string(c)))
+			for _, u := range inner {
+				row.kids = append(row.kids, u.node())
+			}
+			row.kids = append(row.kids, mtok("mo", string(close)))
+			units = append(units, unit{base: row})
+
+		case c == '.' || c == '*':
+			p.pos++
+			units = append(units, unit{base: mtok("mo", "·")})
+			if coef := p.digits(false); coef != "" {
+				units = append(units, unit{base: mtok("mn", coef)})
+			}
+			continue
+
+		case c == '^':
+			if len(units) == 0 {
+				return nil, fmt.Errorf("charge has nothing before it")
+			}
+			p.pos++
+			charge, err := p.charge()
+			if err != nil {
+				return nil, err
+			}
+			units[len(units)-1].sup = charge
+			continue
+
+		case (c == '+' || c == '-') && len(units) > 0 && p.chargeEnds():
+			start := p.pos
+			for p.peek() == c {
+				p.pos++
+			}
+			units[len(units)-1].sup = strings.ReplaceAll(string(p.src[start:p.pos]), "-", "−")
+			continue
+
+		case c == '-' || c == '=' || c == '#':
+			p.pos++
+			bonds := map[rune]string{'-': "−", '=': "=", '#': "≡"}
+			units = append(units, unit{base: mtok("mo", bonds[c])})
+			continue
+
+		default:
+			return units, nil
+		}
+
+		if sub := p.digits(false); sub != "" {
+			units[len(units)-1].sub = sub
+		}
+	}
+	return units, nil
+}
+
+// isState reports whether a state symbol such as "(aq)" comes next.
+func (p *chemParser) isState() bool {
+	for _, s := range states {
+		if p.hasPrefix(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// chargeEnds reports whether the run of + or - at the current position
+// ends the species, which makes it a charge rather than a bond.
+func (p *chemParser) chargeEnds() bool {
+	end := p.pos
+	for end < len(p.src) && p.src[end] == p.src[p.pos] {
+		end++
+	}
+	if end == len(p.src) || unicode.IsSpace(p.src[end]) {
+		return true
+	}
+	rest := string(p.src[end:])
+	for _, s := range states {
+		if strings.HasPrefix(rest, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// charge reads what follows a caret: "{2+}", "2-" or "+".
+func (p *chemParser) charge() (string, error) {
+	var s string
+	if p.peek() == '{' {
+		end := strings.IndexRune(string(p.src[p.pos:]), '}')
+		if end < 0 {
+			return "", fmt.Errorf("{ is never closed")
+		}
+		s = string(p.src[p.pos+1 : p.pos+end])
+		p.pos += end + 1
+	} else {
+		start := p.pos
+		p.digits(false)
+		for p.peek() == '+' || p.peek() == '-' {
+			p.pos++
+		}
+		s = string(p.src[start:p.pos])
+	}
+
+	s = strings.TrimSpace(s)
+	if s == "" || strings.Trim(s, "0123456789+-") != "" {
+		return "", fmt.Errorf("charge %q should be a number and sign, such as 2+", s)
+	}
+	return strings.ReplaceAll(s, "-", "−"), nil
+}
@@ -0,0 +1,42 @@
+package richtext
+
+import (
+	"net/http"
+
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/json"
+	"github.com/odundlaw/cbt-backend/internal/validation"
+)
+
+type Handler struct{}
+
+func NewHandler() *Handler {
+	return &Handler{}
+}
+
+// Render turns question content into safe HTML with MathML, for editors to
+// preview and for clients that cannot typeset LaTeX themselves.
+func (h *Handler) Render(w http.ResponseWriter, r *http.Request) {
+	var req renderParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	html, err := Render(req.Content)
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidMath, []json.FieldError{
+			{Field: "content", Message: err.Error()},
+		})
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgContentRendered, renderResponse{HTML: html}, nil)
+}
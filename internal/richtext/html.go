@@ -0,0 +1,369 @@
+package richtext
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// The sanitizer keeps a small set of formatting tags and drops everything
+// else. Text inside a dropped tag is kept, except for the elements in
+// dropContent, whose contents are script, styles or markup of their own.
+
+type tokenKind int
+
+const (
+	textToken tokenKind = iota
+	startToken
+	endToken
+)
+
+type attr struct {
+	name, value string
+}
+
+type token struct {
+	kind  tokenKind
+	name  string
+	attrs []attr
+	// selfClosing is set for tags written as <x/>.
+	selfClosing bool
+	// text is decoded, so "&lt;" reads as "<".
+	text string
+}
+
+// allowedTags lists each tag that may appear in content with its allowed
+// attributes.
+var allowedTags = map[string][]string{
+	"p": nil, "br": nil, "hr": nil, "div": nil, "span": nil,
+	"b": nil, "strong": nil, "i": nil, "em": nil, "u": nil, "s": nil,
+	"sub": nil, "sup": nil, "small": nil,
+	"blockquote": nil, "pre": nil, "code": nil,
+	"ul": nil, "ol": {"start"}, "li": nil,
+	"table": nil, "caption": nil, "thead": nil, "tbody": nil, "tfoot": nil, "tr": nil,
+	"th": {"colspan", "rowspan"}, "td": {"colspan", "rowspan"},
+	"figure": nil, "figcaption": nil,
+	"img":   {"src", "alt", "title", "width", "height"},
+	"audio": {"src", "controls"},
+	"a":     {"href", "title"},
+}
+
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true}
+
+var dropContent = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true,
+	"noscript": true, "template": true, "svg": true, "math": true, "textarea": true,
+	"select": true, "title": true, "head": true, "xmp": true, "noembed": true, "noframes": true,
+}
+
+// rawText elements hold text that is not markup, up to their end tag.
+var rawText = map[string]bool{
+	"script": true, "style": true, "textarea": true, "title": true,
+	"xmp": true, "iframe": true, "noembed": true, "noframes": true,
+}
+
+var (
+	mediaSrc = regexp.MustCompile(`^/api/media/\d+$`)
+	number   = regexp.MustCompile(`^\d{1,4}$`)
+)
+
+// sanitize reads an HTML fragment and returns it as balanced tokens using
+// only allowed tags and attributes.
+func sanitize(s string) []token {
+	var out []token
+	var open []string
+	dropping := 0
+
+	for _, t := range tokenize(s) {
+		switch t.kind {
+		case textToken:
+			if dropping > 0 {
+				continue
+			}
+			if n := len(out); n > 0 && out[n-1].kind == textToken {
+				out[n-1].text += t.text
+				continue
+			}
+			out = append(out, t)
+
+		case startToken:
+			if dropContent[t.name] {
+				if t.name != "embed" && !t.selfClosing {
+					dropping++
+				}
+				continue
+			}
+			if dropping > 0 {
+				continue
+			}
+			names, ok := allowedTags[t.name]
+			if !ok {
+				continue
+			}
+			t.attrs = cleanAttrs(t.name, t.attrs, names)
+			if t.name == "img" && !hasAttr(t.attrs, "src") {
+				continue
+			}
+			out = append(out, t)
+			if !voidTags[t.name] {
+				open = append(open, t.name)
+			}
+
+		case endToken:
+			if dropContent[t.name] {
+				if dropping > 0 {
+					dropping--
+				}
+				continue
+			}
+			if dropping > 0 {
+				continue
+			}
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != t.name {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					out = append(out, token{kind: endToken, name: open[j]})
+				}
+				open = open[:i]
+				break
+			}
+		}
+	}
+
+	for j := len(open) - 1; j >= 0; j-- {
+		out = append(out, token{kind: endToken, name: open[j]})
+	}
+	return out
+}
+
+func cleanAttrs(tag string, attrs []attr, allowed []string) []attr {
+	var out []attr
+	for _, a := range attrs {
+		if !contains(allowed, a.name) {
+			continue
+		}
+		v := strings.TrimSpace(a.value)
+		switch a.name {
+		case "src":
+			if !mediaSrc.MatchString(v) && !hasScheme(v, "https:") {
+				continue
+			}
+		case "href":
+			if !hasScheme(v, "https:", "http:", "mailto:") && !(strings.HasPrefix(v, "/") && !strings.HasPrefix(v, "//")) {
+				continue
+			}
+		case "width", "height", "colspan", "rowspan", "start":
+			if !number.MatchString(v) {
+				continue
+			}
+		case "controls":
+			v = ""
+		}
+		out = append(out, attr{a.name, v})
+	}
+
+	switch tag {
+	case "a":
+		out = append(out, attr{"rel", "noopener noreferrer"})
+	case "audio":
+		if !hasAttr(out, "controls") {
+			out = append(out, attr{"controls", ""})
+		}
+	}
+	return out
+}
+
+// hasScheme checks a URL's scheme the way a browser would read it, with
+// any tabs or newlines inside it removed.
+func hasScheme(v string, schemes ...string) bool {
+	v = strings.ToLower(strings.Map(func(r rune) rune {
+		if r < ' ' {
+			return -1
+		}
+		return r
+	}, v))
+	for _, s := range schemes {
+		if strings.HasPrefix(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAttr(attrs []attr, name string) bool {
+	for _, a := range attrs {
+		if a.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenize splits an HTML fragment into text, start and end tags. Comments,
+// doctypes and processing instructions are left out, and a tag cut off by
+// the end of the input is dropped, as browsers do.
+func tokenize(s string) []token {
+	var tokens []token
+	text := func(t string) {
+		if t != "" {
+			tokens = append(tokens, token{kind: textToken, text: html.UnescapeString(t)})
+		}
+	}
+
+	for len(s) > 0 {
+		i := strings.IndexByte(s, '<')
+		if i < 0 {
+			text(s)
+			break
+		}
+		text(s[:i])
+		s = s[i:]
+
+		switch {
+		case strings.HasPrefix(s, "<!--"):
+			end := strings.Index(s[4:], "-->")
+			if end < 0 {
+				return tokens
+			}
+			s = s[4+end+3:]
+
+		case strings.HasPrefix(s, "<!"), strings.HasPrefix(s, "<?"):
+			end := strings.IndexByte(s, '>')
+			if end < 0 {
+				return tokens
+			}
+			s = s[end+1:]
+
+		case len(s) > 2 && s[1] == '/' && isASCIILetter(s[2]):
+			name, _ := tagName(s[2:])
+			end := strings.IndexByte(s, '>')
+			if end < 0 {
+				return tokens
+			}
+			tokens = append(tokens, token{kind: endToken, name: name})
+			s = s[end+1:]
+
+		case len(s) > 1 && isASCIILetter(s[1]):
+			t, rest, ok := startTag(s[1:])
+			if !ok {
+				return tokens
+			}
+			tokens = append(tokens, t)
+			s = rest
+			if rawText[t.name] {
+				end := indexFold(s, "</"+t.name)
+				if end < 0 {
+					return tokens
+				}
+				s = s[end:]
+			}
+
+		default:
+			text("<")
+			s = s[1:]
+		}
+	}
+	return tokens
+}
+
+func startTag(s string) (token, string, bool) {
+	name, s := tagName(s)
+	t := token{kind: startToken, name: name}
+
+	for {
+		trimmed := strings.TrimLeft(s, " \t\n\r\f/")
+		t.selfClosing = len(trimmed) < len(s) && s[len(s)-len(trimmed)-1] == '/'
+		s = trimmed
+		if s == "" {
+			return t, "", false
+		}
+		if s[0] == '>' {
+			return t, s[1:], true
+		}
+
+		n := strings.IndexAny(s, " \t\n\r\f/>=")
+		if n == 0 {
+			n = 1
+		}
+		if n < 0 {
+			return t, "", false
+		}
+		a := attr{name: strings.ToLower(s[:n])}
+		s = strings.TrimLeft(s[n:], " \t\n\r\f")
+
+		if strings.HasPrefix(s, "=") {
+			s = strings.TrimLeft(s[1:], " \t\n\r\f")
+			if s == "" {
+				return t, "", false
+			}
+			var raw string
+			if q := s[0]; q == '"' || q == '\'' {
+				end := strings.IndexByte(s[1:], q)
+				if end < 0 {
+					return t, "", false
+				}
+				raw, s = s[1:1+end], s[2+end:]
+			} else {
+				end := strings.IndexAny(s, " \t\n\r\f>")
+				if end < 0 {
+					return t, "", false
+				}
+				raw, s = s[:end], s[end:]
+			}
+			a.value = html.UnescapeString(raw)
+		}
+
+		if !hasAttr(t.attrs, a.name) {
+			t.attrs = append(t.attrs, a)
+		}
+	}
+}
+
+func tagName(s string) (string, string) {
+	n := 0
+	for n < len(s) && (isASCIILetter(s[n]) || s[n] >= '0' && s[n] <= '9') {
+		n++
+	}
+	return strings.ToLower(s[:n]), s[n:]
+}
+
+func isASCIILetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// indexFold finds an ASCII string in s ignoring case.
+func indexFold(s, sub string) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if strings.EqualFold(s[i:i+len(sub)], sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+// writeTag writes a start or end tag with its attributes escaped.
+func writeTag(b *strings.Builder, t token) {
+	if t.kind == endToken {
+		b.WriteString("</" + t.name + ">")
+		return
+	}
+	b.WriteString("<" + t.name)
+	for _, a := range t.attrs {
+		b.WriteString(" " + a.name)
+		if a.name != "controls" {
+			b.WriteString(`="` + html.EscapeString(a.value) + `"`)
+		}
+	}
+	b.WriteString(">")
+}
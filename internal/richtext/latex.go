@@ -0,0 +1,606 @@
+package richtext
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// maxDepth bounds how deeply groups may nest, so a hostile formula cannot
+// exhaust the stack.
+const maxDepth = 64
+
+// texParser reads the LaTeX math commonly used in exam papers into MathML.
+// Anything it does not know is an error rather than being passed through,
+// so what validates is exactly what renders.
+type texParser struct {
+	src   []rune
+	pos   int
+	depth int
+}
+
+func parseTeX(src string) (*mnode, error) {
+	p := &texParser{src: []rune(src)}
+	kids, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if !p.eof() {
+		return nil, p.stray()
+	}
+	return mel("mrow", kids...), nil
+}
+
+func (p *texParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *texParser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *texParser) skipSpace() {
+	for !p.eof() && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+// peekCommand returns the name of the command at the current position
+// without reading it: letters, or the single symbol after the backslash.
+func (p *texParser) peekCommand() string {
+	if p.peek() != '\\' || p.pos+1 >= len(p.src) {
+		return ""
+	}
+	end := p.pos + 1
+	for end < len(p.src) && isTeXLetter(p.src[end]) {
+		end++
+	}
+	if end == p.pos+1 {
+		return string(p.src[end])
+	}
+	return string(p.src[p.pos+1 : end])
+}
+
+func (p *texParser) readCommand() string {
+	name := p.peekCommand()
+	p.pos += 1 + len([]rune(name))
+	return name
+}
+
+func isTeXLetter(r rune) bool {
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z'
+}
+
+// atEnd reports whether the current position closes the expression being
+// read: a brace, a matrix separator, \end, \right, \middle or closer.
+func (p *texParser) atEnd(closer rune) bool {
+	if p.eof() {
+		return true
+	}
+	switch c := p.peek(); {
+	case c == '}', c == '&', closer != 0 && c == closer:
+		return true
+	case c == '\\':
+		switch p.peekCommand() {
+		case "\\", "end", "right", "middle":
+			return true
+		}
+	}
+	return false
+}
+
+// stray describes whatever stopped parsing at the top level.
+func (p *texParser) stray() error {
+	switch c := p.peek(); {
+	case c == '}':
+		return errors.New("} has no matching {")
+	case c == '&':
+		return errors.New("& can only separate matrix columns")
+	case c == '\\':
+		switch name := p.peekCommand(); name {
+		case "\\":
+			return errors.New(`\\ can only separate matrix rows`)
+		case "right":
+			return errors.New(`\right has no matching \left`)
+		case "middle":
+			return errors.New(`\middle must be between \left and \right`)
+		default:
+			return fmt.Errorf(`\%s has no matching \begin`, name)
+		}
+	}
+	return fmt.Errorf("unexpected %q", p.peek())
+}
+
+func (p *texParser) parseExpr(closer rune) ([]*mnode, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxDepth {
+		return nil, errors.New("formula is nested too deeply")
+	}
+
+	kids := []*mnode{}
+	for {
+		p.skipSpace()
+		if p.atEnd(closer) {
+			return kids, nil
+		}
+		n, err := p.parseItem()
+		if err != nil {
+			return nil, err
+		}
+		kids = append(kids, n)
+	}
+}
+
+// parseItem reads an atom and any sub- and superscripts on it.
+func (p *texParser) parseItem() (*mnode, error) {
+	var base *mnode
+	limits := false
+	if c := p.peek(); c != '^' && c != '_' {
+		var err error
+		base, limits, err = p.parseAtom(false)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		base = mel("mrow")
+	}
+
+	var sub, sup *mnode
+	primes := ""
+	for {
+		p.skipSpace()
+		c := p.peek()
+		if c == '\'' {
+			p.pos++
+			primes += "′"
+			continue
+		}
+		if c != '^' && c != '_' {
+			break
+		}
+		p.pos++
+
+		arg, err := p.parseArg(string(c))
+		if err != nil {
+			return nil, err
+		}
+		if c == '^' {
+			if sup != nil {
+				return nil, errors.New("double superscript, group them with braces")
+			}
+			sup = arg
+		} else {
+			if sub != nil {
+				return nil, errors.New("double subscript, group them with braces")
+			}
+			sub = arg
+		}
+	}
+
+	if primes != "" {
+		prime := mtok("mo", primes)
+		if sup == nil {
+			sup = prime
+		} else {
+			sup = mel("mrow", prime, sup)
+		}
+	}
+
+	under, over, both := "msub", "msup", "msubsup"
+	if limits {
+		under, over, both = "munder", "mover", "munderover"
+	}
+	switch {
+	case sub != nil && sup != nil:
+		return mel(both, base, sub, sup), nil
+	case sub != nil:
+		return mel(under, base, sub), nil
+	case sup != nil:
+		return mel(over, base, sup), nil
+	}
+	return base, nil
+}
+
+// parseArg reads one argument: a braced group or a single token. name is
+// what the argument belongs to, for the error when it is missing.
+func (p *texParser) parseArg(name string) (*mnode, error) {
+	p.skipSpace()
+	if p.atEnd(0) || p.peek() == '^' || p.peek() == '_' {
+		return nil, fmt.Errorf("%s is missing an argument", name)
+	}
+	n, _, err := p.parseAtom(true)
+	return n, err
+}
+
+func (p *texParser) parseGroup() (*mnode, error) {
+	p.pos++ // {
+	kids, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != '}' {
+		if p.eof() {
+			return nil, errors.New("{ is never closed")
+		}
+		return nil, p.stray()
+	}
+	p.pos++
+	return mrow(kids), nil
+}
+
+// parseAtom reads one symbol, number, group or command. single limits a
+// number to one digit, as in x^23, which TeX reads as x squared then 3.
+// limits is set for operators that take their scripts above and below.
+func (p *texParser) parseAtom(single bool) (n *mnode, limits bool, err error) {
+	c := p.peek()
+	switch {
+	case c == '{':
+		n, err = p.parseGroup()
+		return n, false, err
+	case c == '\\':
+		return p.parseCommand()
+	case unicode.IsDigit(c) || c == '.' && p.pos+1 < len(p.src) && unicode.IsDigit(p.src[p.pos+1]):
+		start := p.pos
+		p.pos++
+		for !single && !p.eof() && (unicode.IsDigit(p.peek()) || p.peek() == '.' && p.pos+1 < len(p.src) && unicode.IsDigit(p.src[p.pos+1])) {
+			p.pos++
+		}
+		return mtok("mn", string(p.src[start:p.pos])), false, nil
+	case strings.ContainsRune("#$%", c):
+		return nil, false, fmt.Errorf("%c must be written as \\%c", c, c)
+	case c == '~':
+		p.pos++
+		return mtok("mtext", " "), false, nil
+	case c == '-':
+		p.pos++
+		return mtok("mo", "−"), false, nil
+	case c == '*':
+		p.pos++
+		return mtok("mo", "∗"), false, nil
+	case c == '\'':
+		p.pos++
+		return mtok("mo", "′"), false, nil
+	case unicode.IsLetter(c):
+		p.pos++
+		return mtok("mi", string(c)), false, nil
+	}
+	p.pos++
+	return mtok("mo", string(c)), false, nil
+}
+
+func (p *texParser) parseCommand() (*mnode, bool, error) {
+	name := p.readCommand()
+	cmd := `\` + name
+
+	if s, ok := greek[name]; ok {
+		n := mtok("mi", s)
+		if unicode.IsUpper([]rune(name)[0]) {
+			n.set("mathvariant", "normal")
+		}
+		return n, false, nil
+	}
+	if s, ok := symbols[name]; ok {
+		return mtok("mo", s), false, nil
+	}
+	if functions[name] {
+		return mtok("mi", name), false, nil
+	}
+	if s, ok := bigOperators[name]; ok {
+		if len([]rune(s)) > 1 {
+			return mtok("mi", s), true, nil
+		}
+		return mtok("mo", s).set("largeop", "true"), true, nil
+	}
+	if s, ok := integrals[name]; ok {
+		return mtok("mo", s).set("largeop", "true"), false, nil
+	}
+	if w, ok := spaces[name]; ok {
+		return mel("mspace").set("width", w), false, nil
+	}
+	if v, ok := variants[name]; ok {
+		arg, err := p.parseArg(cmd)
+		if err != nil {
+			return nil, false, err
+		}
+		arg.setVariant(v)
+		return arg, false, nil
+	}
+	if a, ok := accents[name]; ok {
+		arg, err := p.parseArg(cmd)
+		if err != nil {
+			return nil, false, err
+		}
+		if a.under {
+			return mel("munder", arg, mtok("mo", a.mark)).set("accentunder", "true"), false, nil
+		}
+		return mel("mover", arg, mtok("mo", a.mark)).set("accent", "true"), false, nil
+	}
+	if size, ok := bigSizes[name]; ok {
+		d, err := p.readDelimiter(cmd)
+		if err != nil {
+			return nil, false, err
+		}
+		return mtok("mo", d).set("minsize", size).set("maxsize", size), false, nil
+	}
+
+	switch name {
+	case "{", "}", "%", "$", "&", "#", "_", "|":
+		if name == "|" {
+			name = "‖"
+		}
+		return mtok("mo", name), false, nil
+
+	case "frac", "dfrac", "tfrac", "cfrac", "binom":
+		num, err := p.parseArg(cmd)
+		if err != nil {
+			return nil, false, err
+		}
+		den, err := p.parseArg(cmd)
+		if err != nil {
+			return nil, false, err
+		}
+		if name == "binom" {
+			return mel("mrow", mtok("mo", "("), mel("mfrac", num, den).set("linethickness", "0"), mtok("mo", ")")), false, nil
+		}
+		return mel("mfrac", num, den), false, nil
+
+	case "sqrt":
+		p.skipSpace()
+		var index *mnode
+		if p.peek() == '[' {
+			p.pos++
+			kids, err := p.parseExpr(']')
+			if err != nil {
+				return nil, false, err
+			}
+			if p.peek() != ']' {
+				return nil, false, errors.New(`\sqrt[ is never closed`)
+			}
+			p.pos++
+			index = mrow(kids)
+		}
+		arg, err := p.parseArg(cmd)
+		if err != nil {
+			return nil, false, err
+		}
+		if index != nil {
+			return mel("mroot", arg, index), false, nil
+		}
+		return mel("msqrt", arg), false, nil
+
+	case "overset", "underset", "stackrel":
+		top, err := p.parseArg(cmd)
+		if err != nil {
+			return nil, false, err
+		}
+		base, err := p.parseArg(cmd)
+		if err != nil {
+			return nil, false, err
+		}
+		if name == "underset" {
+			return mel("munder", base, top), false, nil
+		}
+		return mel("mover", base, top), false, nil
+
+	case "text", "textrm", "textbf", "textit", "mbox":
+		s, err := p.readRaw(cmd)
+		if err != nil {
+			return nil, false, err
+		}
+		n := mtok("mtext", s)
+		switch name {
+		case "textbf":
+			n.set("mathvariant", "bold")
+		case "textit":
+			n.set("mathvariant", "italic")
+		}
+		return n, false, nil
+
+	case "operatorname":
+		s, err := p.readRaw(cmd)
+		if err != nil {
+			return nil, false, err
+		}
+		return mtok("mi", strings.TrimSpace(s)), false, nil
+
+	case "bmod", "mod":
+		return mtok("mo", "mod"), false, nil
+
+	case "pmod":
+		arg, err := p.parseArg(cmd)
+		if err != nil {
+			return nil, false, err
+		}
+		return mel("mrow", mel("mspace").set("width", "1em"), mtok("mo", "("), mtok("mo", "mod"), arg, mtok("mo", ")")), false, nil
+
+	case "left":
+		return p.parseFenced()
+
+	case "begin":
+		return p.parseMatrix()
+
+	case "ce":
+		s, err := p.readRaw(cmd)
+		if err != nil {
+			return nil, false, err
+		}
+		n, err := parseChem(s)
+		return n, false, err
+	}
+
+	return nil, false, fmt.Errorf(`unknown command \%s`, name)
+}
+
+// readRaw reads a braced argument as plain text, for \text and \ce.
+func (p *texParser) readRaw(cmd string) (string, error) {
+	p.skipSpace()
+	if p.peek() != '{' {
+		return "", fmt.Errorf("%s needs its argument in braces", cmd)
+	}
+	start := p.pos + 1
+	depth := 0
+	for ; !p.eof(); p.pos++ {
+		switch p.peek() {
+		case '\\':
+			p.pos++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				p.pos++
+				return string(p.src[start : p.pos-1]), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%s{ is never closed", cmd)
+}
+
+func (p *texParser) readDelimiter(cmd string) (string, error) {
+	p.skipSpace()
+	if p.eof() {
+		return "", fmt.Errorf("%s needs a delimiter", cmd)
+	}
+	key := string(p.peek())
+	if key == `\` {
+		key = `\` + p.peekCommand()
+	}
+	d, ok := delimiters[key]
+	if !ok {
+		return "", fmt.Errorf("%s cannot be used with %s", key, cmd)
+	}
+	p.pos += len([]rune(key))
+	return d, nil
+}
+
+func fence(d string) *mnode {
+	return mtok("mo", d).set("fence", "true").set("stretchy", "true")
+}
+
+// parseFenced reads from after \left to the matching \right.
+func (p *texParser) parseFenced() (*mnode, bool, error) {
+	open, err := p.readDelimiter(`\left`)
+	if err != nil {
+		return nil, false, err
+	}
+	row := mel("mrow")
+	if open != "" {
+		row.kids = append(row.kids, fence(open))
+	}
+
+	for {
+		kids, err := p.parseExpr(0)
+		if err != nil {
+			return nil, false, err
+		}
+		row.kids = append(row.kids, kids...)
+
+		switch p.peekCommand() {
+		case "middle":
+			p.readCommand()
+			d, err := p.readDelimiter(`\middle`)
+			if err != nil {
+				return nil, false, err
+			}
+			row.kids = append(row.kids, fence(d))
+			continue
+		case "right":
+			p.readCommand()
+			d, err := p.readDelimiter(`\right`)
+			if err != nil {
+				return nil, false, err
+			}
+			if d != "" {
+				row.kids = append(row.kids, fence(d))
+			}
+			return row, false, nil
+		}
+
+		if p.eof() || p.peek() == '}' {
+			return nil, false, errors.New(`\left has no matching \right`)
+		}
+		return nil, false, p.stray()
+	}
+}
+
+func (p *texParser) readEnvName(cmd string) (string, error) {
+	s, err := p.readRaw(cmd)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(s), nil
+}
+
+// parseMatrix reads from after \begin to the matching \end.
+func (p *texParser) parseMatrix() (*mnode, bool, error) {
+	env, err := p.readEnvName(`\begin`)
+	if err != nil {
+		return nil, false, err
+	}
+	fences, ok := matrices[env]
+	if !ok {
+		return nil, false, fmt.Errorf("unknown environment %s", env)
+	}
+
+	table := mel("mtable")
+	row := mel("mtr")
+	for {
+		kids, err := p.parseExpr(0)
+		if err != nil {
+			return nil, false, err
+		}
+		row.kids = append(row.kids, mel("mtd", kids...))
+
+		switch {
+		case p.peek() == '&':
+			p.pos++
+			continue
+		case p.peekCommand() == `\`:
+			p.readCommand()
+			table.kids = append(table.kids, row)
+			row = mel("mtr")
+			continue
+		case p.peekCommand() == "end":
+			p.readCommand()
+			end, err := p.readEnvName(`\end`)
+			if err != nil {
+				return nil, false, err
+			}
+			if end != env {
+				return nil, false, fmt.Errorf(`\begin{%s} is ended by \end{%s}`, env, end)
+			}
+		case p.eof():
+			return nil, false, fmt.Errorf(`\begin{%s} is never ended`, env)
+		default:
+			return nil, false, p.stray()
+		}
+		break
+	}
+
+	// A trailing \\ leaves an empty last row.
+	if len(row.kids) > 1 || len(row.kids[0].kids) > 0 {
+		table.kids = append(table.kids, row)
+	}
+
+	switch env {
+	case "cases":
+		table.set("columnalign", "left")
+	case "aligned":
+		table.set("columnalign", "right left").set("columnspacing", "0")
+	}
+
+	out := mel("mrow")
+	if fences[0] != "" {
+		out.kids = append(out.kids, fence(fences[0]))
+	}
+	out.kids = append(out.kids, table)
+	if fences[1] != "" {
+		out.kids = append(out.kids, fence(fences[1]))
+	}
+	return out, false, nil
+}
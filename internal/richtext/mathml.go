@@ -0,0 +1,166 @@
+package richtext
+
+import (
+	"html"
+	"strings"
+)
+
+// mnode is a MathML element. Token elements such as mi and mo hold text,
+// the rest hold children.
+type mnode struct {
+	tag   string
+	attrs []attr
+	text  string
+	kids  []*mnode
+}
+
+func mel(tag string, kids ...*mnode) *mnode {
+	return &mnode{tag: tag, kids: kids}
+}
+
+func mtok(tag, text string) *mnode {
+	return &mnode{tag: tag, text: text}
+}
+
+func (n *mnode) set(name, value string) *mnode {
+	n.attrs = append(n.attrs, attr{name, value})
+	return n
+}
+
+// mrow groups nodes, leaving a single node as it is.
+func mrow(kids []*mnode) *mnode {
+	if len(kids) == 1 {
+		return kids[0]
+	}
+	return mel("mrow", kids...)
+}
+
+func (n *mnode) write(b *strings.Builder) {
+	b.WriteString("<" + n.tag)
+	for _, a := range n.attrs {
+		b.WriteString(" " + a.name + `="` + html.EscapeString(a.value) + `"`)
+	}
+	b.WriteString(">")
+	if n.kids == nil {
+		b.WriteString(html.EscapeString(n.text))
+	}
+	for _, k := range n.kids {
+		k.write(b)
+	}
+	b.WriteString("</" + n.tag + ">")
+}
+
+// setVariant applies a style command such as \mathbf to the identifiers and
+// numbers under n.
+func (n *mnode) setVariant(variant string) {
+	switch n.tag {
+	case "mi", "mn", "mtext":
+		for i, a := range n.attrs {
+			if a.name == "mathvariant" {
+				n.attrs[i].value = variant
+				return
+			}
+		}
+		n.set("mathvariant", variant)
+	}
+	for _, k := range n.kids {
+		k.setVariant(variant)
+	}
+}
+
+var greek = map[string]string{
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ϵ", "varepsilon": "ε",
+	"zeta": "ζ", "eta": "η", "theta": "θ", "vartheta": "ϑ", "iota": "ι", "kappa": "κ",
+	"lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ", "omicron": "ο", "pi": "π", "varpi": "ϖ",
+	"rho": "ρ", "varrho": "ϱ", "sigma": "σ", "varsigma": "ς", "tau": "τ", "upsilon": "υ",
+	"phi": "ϕ", "varphi": "φ", "chi": "χ", "psi": "ψ", "omega": "ω",
+	"Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ", "Pi": "Π",
+	"Sigma": "Σ", "Upsilon": "Υ", "Phi": "Φ", "Psi": "Ψ", "Omega": "Ω",
+}
+
+// symbols are commands that stand for one operator or identifier.
+var symbols = map[string]string{
+	"times": "×", "div": "÷", "pm": "±", "mp": "∓", "cdot": "⋅", "ast": "∗", "star": "⋆",
+	"circ": "∘", "bullet": "∙", "oplus": "⊕", "otimes": "⊗",
+	"leq": "≤", "le": "≤", "geq": "≥", "ge": "≥", "neq": "≠", "ne": "≠", "approx": "≈",
+	"equiv": "≡", "sim": "∼", "simeq": "≃", "cong": "≅", "propto": "∝", "ll": "≪", "gg": "≫",
+	"in": "∈", "notin": "∉", "ni": "∋", "subset": "⊂", "subseteq": "⊆", "supset": "⊃",
+	"supseteq": "⊇", "cup": "∪", "cap": "∩", "setminus": "∖", "emptyset": "∅", "varnothing": "∅",
+	"forall": "∀", "exists": "∃", "neg": "¬", "lnot": "¬", "land": "∧", "wedge": "∧",
+	"lor": "∨", "vee": "∨", "implies": "⟹", "iff": "⟺",
+	"to": "→", "rightarrow": "→", "leftarrow": "←", "gets": "←", "leftrightarrow": "↔",
+	"Rightarrow": "⇒", "Leftarrow": "⇐", "Leftrightarrow": "⇔", "longrightarrow": "⟶",
+	"longleftarrow": "⟵", "mapsto": "↦", "uparrow": "↑", "downarrow": "↓",
+	"rightleftharpoons": "⇌", "leftrightharpoons": "⇋",
+	"infty": "∞", "partial": "∂", "nabla": "∇", "angle": "∠", "triangle": "△", "perp": "⊥",
+	"parallel": "∥", "mid": "∣", "therefore": "∴", "because": "∵", "degree": "°",
+	"prime": "′", "ldots": "…", "dots": "…", "cdots": "⋯", "vdots": "⋮", "ddots": "⋱",
+	"hbar": "ℏ", "ell": "ℓ", "Re": "ℜ", "Im": "ℑ", "aleph": "ℵ",
+	"langle": "⟨", "rangle": "⟩", "lfloor": "⌊", "rfloor": "⌋", "lceil": "⌈", "rceil": "⌉",
+	"vert": "|", "Vert": "‖", "backslash": "∖",
+}
+
+// functions are set upright, as their names rather than as products of
+// variables.
+var functions = map[string]bool{
+	"sin": true, "cos": true, "tan": true, "cot": true, "sec": true, "csc": true,
+	"arcsin": true, "arccos": true, "arctan": true, "sinh": true, "cosh": true, "tanh": true,
+	"log": true, "ln": true, "lg": true, "exp": true, "deg": true, "arg": true, "dim": true,
+	"ker": true, "hom": true,
+}
+
+// bigOperators take limits above and below. Integrals keep theirs to the
+// side.
+var bigOperators = map[string]string{
+	"sum": "∑", "prod": "∏", "coprod": "∐", "bigcup": "⋃", "bigcap": "⋂",
+	"bigoplus": "⨁", "bigotimes": "⨂", "lim": "lim", "limsup": "lim sup", "liminf": "lim inf",
+	"max": "max", "min": "min", "sup": "sup", "inf": "inf", "det": "det", "gcd": "gcd",
+}
+
+var integrals = map[string]string{
+	"int": "∫", "iint": "∬", "iiint": "∭", "oint": "∮",
+}
+
+var variants = map[string]string{
+	"mathbf": "bold", "mathrm": "normal", "mathit": "italic", "mathbb": "double-struck",
+	"mathcal": "script", "mathsf": "sans-serif", "mathtt": "monospace",
+	"mathfrak": "fraktur", "boldsymbol": "bold-italic",
+}
+
+type accent struct {
+	mark  string
+	under bool
+}
+
+var accents = map[string]accent{
+	"hat": {"^", false}, "widehat": {"^", false}, "bar": {"¯", false}, "overline": {"‾", false},
+	"vec": {"→", false}, "overrightarrow": {"→", false}, "overleftarrow": {"←", false},
+	"dot": {"˙", false}, "ddot": {"¨", false}, "tilde": {"~", false}, "widetilde": {"~", false},
+	"underline": {"_", true},
+}
+
+var spaces = map[string]string{
+	",": "0.1667em", ":": "0.2222em", ";": "0.2778em", "!": "-0.1667em", " ": "0.25em",
+	"quad": "1em", "qquad": "2em",
+}
+
+// delimiters are what may follow \left, \right and the \big commands.
+var delimiters = map[string]string{
+	"(": "(", ")": ")", "[": "[", "]": "]", "\\{": "{", "\\}": "}", "|": "|", "\\|": "‖",
+	"/": "/", ".": "", "\\langle": "⟨", "\\rangle": "⟩", "\\lfloor": "⌊", "\\rfloor": "⌋",
+	"\\lceil": "⌈", "\\rceil": "⌉", "\\vert": "|", "\\Vert": "‖",
+}
+
+var bigSizes = map[string]string{
+	"big": "1.2em", "bigl": "1.2em", "bigr": "1.2em",
+	"Big": "1.8em", "Bigl": "1.8em", "Bigr": "1.8em",
+	"bigg": "2.4em", "biggl": "2.4em", "biggr": "2.4em",
+	"Bigg": "3em", "Biggl": "3em", "Biggr": "3em",
+}
+
+// matrices maps each supported environment to the fences around it.
+var matrices = map[string][2]string{
+	"matrix": {"", ""}, "pmatrix": {"(", ")"}, "bmatrix": {"[", "]"}, "Bmatrix": {"{", "}"},
+	"vmatrix": {"|", "|"}, "Vmatrix": {"‖", "‖"}, "cases": {"{", ""},
+	"aligned": {"", ""}, "gathered": {"", ""},
+}
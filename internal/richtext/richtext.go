@@ -0,0 +1,243 @@
+// Package richtext where question text is sanitized, its math checked and
+// rendered to HTML with MathML
+package richtext
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/odundlaw/cbt-backend/internal/constants"
+)
+
+// Question content is an HTML fragment limited to the tags in allowedTags.
+// Math is LaTeX between \( and \) inline, or \[ and \] on a line of its
+// own, and chemical formulas are written as \ce{...} inside math. Content
+// is stored as sanitized source, with the LaTeX left as written, and turned
+// into MathML by Render.
+
+var ErrInvalidMath = errors.New(constants.ErrInvalidMath)
+
+// MathError says why a piece of math could not be read. It matches
+// ErrInvalidMath.
+type MathError struct {
+	// Math is the formula with its delimiters, when the error is inside one.
+	Math   string
+	Reason string
+}
+
+func (e *MathError) Error() string {
+	if e.Math == "" {
+		return e.Reason
+	}
+	return e.Reason + " in " + e.Math
+}
+
+func (e *MathError) Is(target error) bool {
+	return target == ErrInvalidMath
+}
+
+// FieldError names the part of a question whose content could not be read.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Question is the content of a question that is written by hand.
+type Question struct {
+	Stem        string
+	Options     []byte
+	Explanation string
+}
+
+// PrepareQuestion runs Prepare over the stem, explanation and the text of
+// every option. A failure is a *FieldError.
+func PrepareQuestion(q Question) (Question, error) {
+	var err error
+	if q.Stem, err = Prepare(q.Stem); err != nil {
+		return q, &FieldError{Field: "stem", Err: err}
+	}
+	if q.Explanation, err = Prepare(q.Explanation); err != nil {
+		return q, &FieldError{Field: "explanation", Err: err}
+	}
+	if q.Options, err = prepareOptions(q.Options); err != nil {
+		return q, err
+	}
+	return q, nil
+}
+
+// Prepare sanitizes content for storage and checks that its math parses.
+func Prepare(s string) (string, error) {
+	return write(sanitize(s), false)
+}
+
+// Render sanitizes content and replaces its math with MathML. The LaTeX is
+// kept as an annotation for screen readers and copying.
+func Render(s string) (string, error) {
+	return write(sanitize(s), true)
+}
+
+func write(tokens []token, render bool) (string, error) {
+	var b strings.Builder
+	for _, t := range tokens {
+		if t.kind != textToken {
+			writeTag(&b, t)
+			continue
+		}
+
+		segments, err := splitMath(t.text)
+		if err != nil {
+			return "", err
+		}
+		for _, seg := range segments {
+			if seg.open == "" {
+				b.WriteString(escapeText(seg.text))
+				continue
+			}
+
+			tree, err := parseTeX(seg.text)
+			if err != nil {
+				return "", &MathError{Math: seg.open + seg.text + seg.close, Reason: err.Error()}
+			}
+			if !render {
+				b.WriteString(escapeText(seg.open + seg.text + seg.close))
+				continue
+			}
+
+			display := "inline"
+			if seg.open == `\[` {
+				display = "block"
+			}
+			math := mel("math", mel("semantics", tree,
+				mtok("annotation", seg.text).set("encoding", "application/x-tex"),
+			)).set("xmlns", "http://www.w3.org/1998/Math/MathML").set("display", display)
+			math.write(&b)
+		}
+	}
+	return b.String(), nil
+}
+
+// escapeText escapes what HTML text needs, leaving quotes as they are.
+func escapeText(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+type segment struct {
+	// open and close are the math delimiters, empty for plain text.
+	open, close string
+	text        string
+}
+
+// splitMath separates math from the text around it.
+func splitMath(s string) ([]segment, error) {
+	var out []segment
+	for s != "" {
+		i := indexOpener(s)
+		if i < 0 {
+			if strings.Contains(s, `\)`) {
+				return nil, &MathError{Reason: `\) has no matching \(`}
+			}
+			if strings.Contains(s, `\]`) {
+				return nil, &MathError{Reason: `\] has no matching \[`}
+			}
+			out = append(out, segment{text: s})
+			break
+		}
+		if i > 0 {
+			out = append(out, segment{text: s[:i]})
+		}
+
+		open := s[i : i+2]
+		close := `\)`
+		if open == `\[` {
+			close = `\]`
+		}
+		rest := s[i+2:]
+		j := strings.Index(rest, close)
+		if j < 0 {
+			return nil, &MathError{Reason: open + " is never closed"}
+		}
+		out = append(out, segment{open: open, close: close, text: rest[:j]})
+		s = rest[j+2:]
+	}
+	return out, nil
+}
+
+func indexOpener(s string) int {
+	i, j := strings.Index(s, `\(`), strings.Index(s, `\[`)
+	if i < 0 || j >= 0 && j < i {
+		return j
+	}
+	return i
+}
+
+// prepareOptions runs Prepare over the text of every option, whether the
+// options are a list of choices or matching prompts and choices. Anything
+// that is not an option's text is left as it is.
+func prepareOptions(options []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(options)) == 0 {
+		return options, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(options))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		// Options that are not JSON are left for the database to refuse.
+		return options, nil
+	}
+
+	v, err := prepareValue(v, "options")
+	if err != nil {
+		return options, err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return options, err
+	}
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
+
+func prepareValue(v any, path string) (any, error) {
+	switch v := v.(type) {
+	case []any:
+		for i, item := range v {
+			out, err := prepareValue(item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			v[i] = out
+		}
+	case map[string]any:
+		for key, item := range v {
+			field := path + "." + key
+			if text, ok := item.(string); ok && key == "text" {
+				prepared, err := Prepare(text)
+				if err != nil {
+					return nil, &FieldError{Field: field, Err: err}
+				}
+				v[key] = prepared
+				continue
+			}
+			out, err := prepareValue(item, field)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = out
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,99 @@
+package richtext
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPrepareSanitizes(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"script dropped with its content", `<script>alert(1)</script>hi`, `hi`},
+		{"uppercase style dropped", `<STYLE>body{}</STYLE>z`, `z`},
+		{"img with foreign src dropped", `<img src=x onerror=alert(1)>`, ``},
+		{"event handler stripped", `<img src="/api/media/12" onerror="alert(1)" alt="a">`, `<img src="/api/media/12" alt="a">`},
+		{"data URL image dropped", `<img src="data:image/svg+xml;base64,PHN2Zz4=">`, ``},
+		{"javascript href", `<a href="javascript:alert(1)">x</a>`, `<a rel="noopener noreferrer">x</a>`},
+		{"javascript href with tab", `<a href="java&#09;script:alert(1)">x</a>`, `<a rel="noopener noreferrer">x</a>`},
+		{"javascript href mixed case", `<a href=" JaVaScRiPt:alert(1)">x</a>`, `<a rel="noopener noreferrer">x</a>`},
+		{"https href kept", `<a href="https://example.com" target="_blank">x</a>`, `<a href="https://example.com" rel="noopener noreferrer">x</a>`},
+		{"svg dropped with its content", `<svg onload=alert(1)><circle/></svg>ok`, `ok`},
+		{"raw math markup dropped", `<math><mi>x</mi></math>y`, `y`},
+		{"iframe dropped", `<iframe src="https://evil"></iframe>`, ``},
+		{"style attribute stripped", `<p style="background:url(javascript:alert(1))">t</p>`, `<p>t</p>`},
+		{"nested script tag", `<scr<script>ipt>alert(1)</script>`, `ipt&gt;alert(1)`},
+		{"doubled brackets", `<<script>script>alert(1)<</script>/script>`, `&lt;/script&gt;`},
+		{"script in comment", `<!--<script>alert(1)//--><p>ok</p>`, `<p>ok</p>`},
+		{"script inside textarea", `<textarea><script>alert(1)</script></textarea>x`, `x`},
+		{"quotes escaped in attributes", `<a href="https://x.y/?a=1&b=2" title='"><script>'>q</a>`,
+			`<a href="https://x.y/?a=1&amp;b=2" title="&#34;&gt;&lt;script&gt;" rel="noopener noreferrer">q</a>`},
+		{"mismatched end tag", `<b>bold</i>`, `<b>bold</b>`},
+		{"unclosed tags balanced", `<div><p>unclosed`, `<div><p>unclosed</p></div>`},
+		{"bad colspan stripped", `<td colspan="9999999">x</td>`, `<td>x</td>`},
+		{"allowed attribute kept", `<ol start="3" onclick="x"><li>a</li></ol>`, `<ol start="3"><li>a</li></ol>`},
+		{"unlisted audio attribute stripped", `<audio src="/api/media/5" controls autoplay></audio>`, `<audio src="/api/media/5" controls></audio>`},
+		{"text escaped", `a < b & c > d`, `a &lt; b &amp; c &gt; d`},
+		{"math left as written", `<b>\(x^2\)</b>`, `<b>\(x^2\)</b>`},
+		{"markup inside text command dropped", `\(\text{<script>alert(1)</script>}\)`, `\(\text{}\)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Prepare(tt.in)
+			if err != nil {
+				t.Fatalf("Prepare(%q) error = %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("Prepare(%q) = %q; want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrepareRejectsBadMath(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"unknown command", `\(\href{javascript:alert(1)}{x}\)`},
+		{"unclosed group", `\(\frac{1}{2\)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Prepare(tt.in)
+			if !errors.Is(err, ErrInvalidMath) {
+				t.Errorf("Prepare(%q) error = %v; want ErrInvalidMath", tt.in, err)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"superscript", `\(x^2\)`, `<msup><mi>x</mi><mn>2</mn></msup>`},
+		{"chemistry", `\(\ce{H2O}\)`, `<msub><mi mathvariant="normal">H</mi><mn>2</mn></msub>`},
+		{"inside formatting", `<b>\(a\)</b>`, `<b><math xmlns="http://www.w3.org/1998/Math/MathML" display="inline">`},
+		{"source kept as annotation", `\(x^2\)`, `<annotation encoding="application/x-tex">x^2</annotation>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.in)
+			if err != nil {
+				t.Fatalf("Render(%q) error = %v", tt.in, err)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("Render(%q) = %q; want it to contain %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
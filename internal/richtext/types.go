@@ -0,0 +1,9 @@
+package richtext
+
+type renderParams struct {
+	Content string `json:"content" validate:"required,max=200000"`
+}
+
+type renderResponse struct {
+	HTML string `json:"html"`
+}
@@ -0,0 +1,313 @@
+package scheduling
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/helpers"
+	"github.com/odundlaw/cbt-backend/internal/json"
+	"github.com/odundlaw/cbt-backend/internal/middlewares"
+	"github.com/odundlaw/cbt-backend/internal/validation"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service,
+	}
+}
+
+func (h *Handler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	schedule, err := h.service.GetSchedule(r.Context(), examID)
+	if err != nil {
+		writeSchedulingError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, schedule, nil)
+}
+
+func (h *Handler) UpsertSchedule(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req upsertScheduleParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	schedule, err := h.service.UpsertSchedule(r.Context(), examID, req)
+	if err != nil {
+		writeSchedulingError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgUpdateSuccessful, schedule, nil)
+}
+
+func (h *Handler) GetEligibility(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	rules, err := h.service.GetEligibility(r.Context(), examID)
+	if err != nil {
+		writeSchedulingError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, rules, nil)
+}
+
+func (h *Handler) UpsertEligibility(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req upsertEligibilityParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	rules, err := h.service.UpsertEligibility(r.Context(), examID, req)
+	if err != nil {
+		writeSchedulingError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgUpdateSuccessful, rules, nil)
+}
+
+func (h *Handler) AssignExam(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req assignExamParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	added, err := h.service.AssignExam(r.Context(), examID, req)
+	if err != nil {
+		writeSchedulingError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgExamAssigned, countResponse{Added: added}, nil)
+}
+
+func (h *Handler) ListAssignments(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	assignments, err := h.service.ListAssignments(r.Context(), examID)
+	if err != nil {
+		writeSchedulingError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, assignments, nil)
+}
+
+func (h *Handler) RemoveAssignment(w http.ResponseWriter, r *http.Request) {
+	examID, err := helpers.IDParam(r, "examID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	assignmentID, err := helpers.IDParam(r, "assignmentID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	if err := h.service.RemoveAssignment(r.Context(), examID, assignmentID); err != nil {
+		writeSchedulingError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgDeleteSuccessful, nil, nil)
+}
+
+func (h *Handler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	var req createGroupParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	group, err := h.service.CreateGroup(r.Context(), userID, req)
+	if err != nil {
+		writeSchedulingError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusCreated, constants.MsgGroupCreated, group, nil)
+}
+
+func (h *Handler) ListGroups(w http.ResponseWriter, r *http.Request) {
+	limit, offset := helpers.Pagination(r)
+
+	groups, err := h.service.ListGroups(r.Context(), limit, offset)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, groups, nil)
+}
+
+func (h *Handler) AddGroupMembers(w http.ResponseWriter, r *http.Request) {
+	groupID, err := helpers.IDParam(r, "groupID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req groupMembersParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	added, err := h.service.AddGroupMembers(r.Context(), groupID, req)
+	if err != nil {
+		writeSchedulingError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgMembersAdded, countResponse{Added: added}, nil)
+}
+
+func (h *Handler) ListGroupMembers(w http.ResponseWriter, r *http.Request) {
+	groupID, err := helpers.IDParam(r, "groupID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	limit, offset := helpers.Pagination(r)
+
+	members, err := h.service.ListGroupMembers(r.Context(), groupID, limit, offset)
+	if err != nil {
+		writeSchedulingError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, members, nil)
+}
+
+func (h *Handler) RemoveGroupMember(w http.ResponseWriter, r *http.Request) {
+	groupID, err := helpers.IDParam(r, "groupID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	userID, err := helpers.IDParam(r, "userID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	if err := h.service.RemoveGroupMember(r.Context(), groupID, userID); err != nil {
+		writeSchedulingError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgDeleteSuccessful, nil, nil)
+}
+
+func writeSchedulingError(w http.ResponseWriter, err error) {
+	var pgErr *pgconn.PgError
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		json.JSONError(w, http.StatusNotFound, constants.ErrExamNotFound, nil)
+	case errors.Is(err, ErrGroupNotFound),
+		errors.Is(err, ErrAssignmentNotFound),
+		errors.Is(err, ErrMemberNotFound):
+		json.JSONError(w, http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, ErrInvalidTimeZone),
+		errors.Is(err, ErrInvalidWindow),
+		errors.Is(err, ErrInvalidWindowTime):
+		json.JSONError(w, http.StatusUnprocessableEntity, err.Error(), nil)
+	case errors.As(err, &pgErr) && pgErr.Code == "23503":
+		// A user or group ID that doesn't exist.
+		json.JSONError(w, http.StatusUnprocessableEntity, constants.ErrInvalidInput, nil)
+	case errors.As(err, &pgErr) && pgErr.Code == "23505":
+		json.JSONError(w, http.StatusConflict, constants.ErrConflict, nil)
+	default:
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+	}
+}
@@ -0,0 +1,426 @@
+// Package scheduling where exam windows, assignments and eligibility rules are kept
+package scheduling
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+)
+
+const localLayout = "2006-01-02T15:04"
+
+var (
+	ErrInvalidTimeZone    = errors.New(constants.ErrInvalidTimeZone)
+	ErrInvalidWindow      = errors.New(constants.ErrInvalidWindow)
+	ErrInvalidWindowTime  = errors.New(constants.ErrInvalidWindowTime)
+	ErrGroupNotFound      = errors.New(constants.ErrGroupNotFound)
+	ErrAssignmentNotFound = errors.New(constants.ErrAssignmentNotFound)
+	ErrMemberNotFound     = errors.New(constants.ErrMemberNotFound)
+)
+
+// IneligibleError is returned when a candidate may not start an exam. Code
+// tells clients which rule failed.
+type IneligibleError struct {
+	Code    string
+	Message string
+}
+
+func (e *IneligibleError) Error() string {
+	return e.Message
+}
+
+func ineligible(code, message string) error {
+	return &IneligibleError{Code: code, Message: message}
+}
+
+type svc struct {
+	repo         *repo.Queries
+	entitlements Entitlements
+}
+
+func NewService(repo *repo.Queries, entitlements Entitlements) Service {
+	return &svc{repo: repo, entitlements: entitlements}
+}
+
+func (s *svc) GetSchedule(ctx context.Context, examID int64) (scheduleResponse, error) {
+	if _, err := s.repo.GetExamByID(ctx, examID); err != nil {
+		return scheduleResponse{}, err
+	}
+
+	schedule, err := s.schedule(ctx, examID)
+	if err != nil {
+		return scheduleResponse{}, err
+	}
+
+	return toScheduleResponse(schedule), nil
+}
+
+func (s *svc) UpsertSchedule(ctx context.Context, examID int64, params upsertScheduleParams) (scheduleResponse, error) {
+	if _, err := s.repo.GetExamByID(ctx, examID); err != nil {
+		return scheduleResponse{}, err
+	}
+
+	zone := params.TimeZone
+	if zone == "" {
+		zone = "UTC"
+	}
+
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return scheduleResponse{}, ErrInvalidTimeZone
+	}
+
+	opensAt, err := parseLocal(params.OpensAt, loc)
+	if err != nil {
+		return scheduleResponse{}, err
+	}
+
+	closesAt, err := parseLocal(params.ClosesAt, loc)
+	if err != nil {
+		return scheduleResponse{}, err
+	}
+
+	if opensAt.Valid && closesAt.Valid && !opensAt.Time.Before(closesAt.Time) {
+		return scheduleResponse{}, ErrInvalidWindow
+	}
+
+	schedule, err := s.repo.UpsertExamSchedule(ctx, repo.UpsertExamScheduleParams{
+		ExamID:                examID,
+		OpensAt:               opensAt,
+		ClosesAt:              closesAt,
+		TimeZone:              zone,
+		MaxAttempts:           pgtype.Int4{Int32: params.MaxAttempts, Valid: params.MaxAttempts > 0},
+		RetakeCooldownMinutes: params.RetakeCooldownMinutes,
+		AssignedOnly:          params.AssignedOnly,
+		RequiresAccess:        params.RequiresAccess,
+	})
+	if err != nil {
+		return scheduleResponse{}, err
+	}
+
+	return toScheduleResponse(schedule), nil
+}
+
+func (s *svc) GetEligibility(ctx context.Context, examID int64) (repo.ExamEligibilityRule, error) {
+	if _, err := s.repo.GetExamByID(ctx, examID); err != nil {
+		return repo.ExamEligibilityRule{}, err
+	}
+
+	return s.eligibility(ctx, examID)
+}
+
+func (s *svc) UpsertEligibility(ctx context.Context, examID int64, params upsertEligibilityParams) (repo.ExamEligibilityRule, error) {
+	if _, err := s.repo.GetExamByID(ctx, examID); err != nil {
+		return repo.ExamEligibilityRule{}, err
+	}
+
+	return s.repo.UpsertExamEligibility(ctx, repo.UpsertExamEligibilityParams{
+		ExamID:                  examID,
+		Schools:                 normalize(params.Schools),
+		States:                  normalize(params.States),
+		RequireProfileCompleted: params.RequireProfileCompleted,
+		RequireEmailVerified:    params.RequireEmailVerified,
+	})
+}
+
+func (s *svc) CreateGroup(ctx context.Context, createdBy int64, params createGroupParams) (repo.CandidateGroup, error) {
+	return s.repo.CreateCandidateGroup(ctx, repo.CreateCandidateGroupParams{
+		Name:        params.Name,
+		Description: pgtype.Text{String: params.Description, Valid: params.Description != ""},
+		CreatedBy:   createdBy,
+	})
+}
+
+func (s *svc) ListGroups(ctx context.Context, limit, offset int32) ([]repo.CandidateGroup, error) {
+	return s.repo.ListCandidateGroups(ctx, repo.ListCandidateGroupsParams{Limit: limit, Offset: offset})
+}
+
+func (s *svc) AddGroupMembers(ctx context.Context, groupID int64, params groupMembersParams) (int64, error) {
+	if err := s.groupExists(ctx, groupID); err != nil {
+		return 0, err
+	}
+
+	return s.repo.AddCandidateGroupMembers(ctx, repo.AddCandidateGroupMembersParams{
+		GroupID: groupID,
+		UserIds: params.UserIDs,
+	})
+}
+
+func (s *svc) ListGroupMembers(ctx context.Context, groupID int64, limit, offset int32) ([]repo.CandidateGroupMember, error) {
+	if err := s.groupExists(ctx, groupID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListCandidateGroupMembers(ctx, repo.ListCandidateGroupMembersParams{
+		GroupID: groupID,
+		Limit:   limit,
+		Offset:  offset,
+	})
+}
+
+func (s *svc) RemoveGroupMember(ctx context.Context, groupID, userID int64) error {
+	n, err := s.repo.RemoveCandidateGroupMember(ctx, repo.RemoveCandidateGroupMemberParams{
+		GroupID: groupID,
+		UserID:  userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrMemberNotFound
+	}
+
+	return nil
+}
+
+// AssignExam assigns an exam to individual candidates and whole groups.
+// Existing assignments are left alone; the count of new ones is returned.
+func (s *svc) AssignExam(ctx context.Context, examID int64, params assignExamParams) (int64, error) {
+	if _, err := s.repo.GetExamByID(ctx, examID); err != nil {
+		return 0, err
+	}
+
+	var added int64
+
+	if len(params.UserIDs) > 0 {
+		n, err := s.repo.AssignExamToUsers(ctx, repo.AssignExamToUsersParams{ExamID: examID, UserIds: params.UserIDs})
+		if err != nil {
+			return 0, err
+		}
+		added += n
+	}
+
+	if len(params.GroupIDs) > 0 {
+		n, err := s.repo.AssignExamToGroups(ctx, repo.AssignExamToGroupsParams{ExamID: examID, GroupIds: params.GroupIDs})
+		if err != nil {
+			return added, err
+		}
+		added += n
+	}
+
+	return added, nil
+}
+
+func (s *svc) ListAssignments(ctx context.Context, examID int64) ([]repo.ExamAssignment, error) {
+	if _, err := s.repo.GetExamByID(ctx, examID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListExamAssignments(ctx, examID)
+}
+
+func (s *svc) RemoveAssignment(ctx context.Context, examID, assignmentID int64) error {
+	n, err := s.repo.DeleteExamAssignment(ctx, repo.DeleteExamAssignmentParams{ExamID: examID, ID: assignmentID})
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrAssignmentNotFound
+	}
+
+	return nil
+}
+
+// CheckEligibility decides whether userID may start a new attempt at examID
+// at now. Failures are *IneligibleError. On success it returns the time the
+// exam window closes, or the zero time when it stays open.
+func (s *svc) CheckEligibility(ctx context.Context, userID, examID int64, now time.Time) (time.Time, error) {
+	schedule, err := s.schedule(ctx, examID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if schedule.OpensAt.Valid && now.Before(schedule.OpensAt.Time) {
+		return time.Time{}, ineligible(constants.CodeExamNotOpenYet, constants.ErrExamNotOpenYet)
+	}
+
+	if schedule.ClosesAt.Valid && !now.Before(schedule.ClosesAt.Time) {
+		return time.Time{}, ineligible(constants.CodeExamWindowClosed, constants.ErrExamWindowClosed)
+	}
+
+	if schedule.AssignedOnly {
+		assigned, err := s.repo.IsAssignedToExam(ctx, repo.IsAssignedToExamParams{ExamID: examID, UserID: userID})
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !assigned {
+			return time.Time{}, ineligible(constants.CodeExamNotAssigned, constants.ErrExamNotAssigned)
+		}
+	}
+
+	if schedule.RequiresAccess {
+		unlocked, err := s.entitlements.Allowed(ctx, userID, repo.AccessTargetExam, examID)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !unlocked {
+			return time.Time{}, ineligible(constants.CodeExamAccessRequired, constants.ErrExamAccessRequired)
+		}
+	}
+
+	if schedule.MaxAttempts.Valid {
+		used, err := s.repo.CountUserAttempts(ctx, repo.CountUserAttemptsParams{ExamID: examID, UserID: userID})
+		if err != nil {
+			return time.Time{}, err
+		}
+		if used >= int64(schedule.MaxAttempts.Int32) {
+			return time.Time{}, ineligible(constants.CodeMaxAttemptsReached, constants.ErrMaxAttemptsReached)
+		}
+	}
+
+	if schedule.RetakeCooldownMinutes > 0 {
+		last, err := s.repo.GetLastSubmittedAttempt(ctx, repo.GetLastSubmittedAttemptParams{ExamID: examID, UserID: userID})
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, err
+		}
+		cooldown := time.Duration(schedule.RetakeCooldownMinutes) * time.Minute
+		if err == nil && last.SubmittedAt.Valid && now.Before(last.SubmittedAt.Time.Add(cooldown)) {
+			return time.Time{}, ineligible(constants.CodeRetakeCooldown, constants.ErrRetakeCooldown)
+		}
+	}
+
+	if err := s.checkRules(ctx, userID, examID); err != nil {
+		return time.Time{}, err
+	}
+
+	if schedule.ClosesAt.Valid {
+		return schedule.ClosesAt.Time, nil
+	}
+
+	return time.Time{}, nil
+}
+
+func (s *svc) checkRules(ctx context.Context, userID, examID int64) error {
+	rules, err := s.eligibility(ctx, examID)
+	if err != nil {
+		return err
+	}
+
+	if len(rules.Schools) == 0 && len(rules.States) == 0 && !rules.RequireProfileCompleted && !rules.RequireEmailVerified {
+		return nil
+	}
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if len(rules.Schools) > 0 && !slices.Contains(rules.Schools, normalizeOne(user.School.String)) {
+		return ineligible(constants.CodeSchoolNotEligible, constants.ErrSchoolNotEligible)
+	}
+
+	if len(rules.States) > 0 && !slices.Contains(rules.States, normalizeOne(user.State.String)) {
+		return ineligible(constants.CodeStateNotEligible, constants.ErrStateNotEligible)
+	}
+
+	if rules.RequireProfileCompleted && !user.ProfileCompleted.Bool {
+		return ineligible(constants.CodeProfileIncomplete, constants.ErrProfileIncomplete)
+	}
+
+	if rules.RequireEmailVerified && !user.EmailVerifiedAt.Valid {
+		return ineligible(constants.CodeEmailNotVerified, constants.ErrEmailNotVerified)
+	}
+
+	return nil
+}
+
+// schedule returns an exam's schedule, or an always-open one when none was set.
+func (s *svc) schedule(ctx context.Context, examID int64) (repo.ExamSchedule, error) {
+	schedule, err := s.repo.GetExamSchedule(ctx, examID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.ExamSchedule{ExamID: examID, TimeZone: "UTC"}, nil
+	}
+
+	return schedule, err
+}
+
+// eligibility returns an exam's rules, or rules that admit everyone when none
+// were set.
+func (s *svc) eligibility(ctx context.Context, examID int64) (repo.ExamEligibilityRule, error) {
+	rules, err := s.repo.GetExamEligibility(ctx, examID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.ExamEligibilityRule{ExamID: examID, Schools: []string{}, States: []string{}}, nil
+	}
+
+	return rules, err
+}
+
+func (s *svc) groupExists(ctx context.Context, groupID int64) error {
+	_, err := s.repo.GetCandidateGroup(ctx, groupID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrGroupNotFound
+	}
+
+	return err
+}
+
+func parseLocal(value string, loc *time.Location) (pgtype.Timestamptz, error) {
+	if value == "" {
+		return pgtype.Timestamptz{}, nil
+	}
+
+	t, err := time.ParseInLocation(localLayout, value, loc)
+	if err != nil {
+		if t, err = time.Parse(time.RFC3339, value); err != nil {
+			return pgtype.Timestamptz{}, ErrInvalidWindowTime
+		}
+	}
+
+	return pgtype.Timestamptz{Time: t, Valid: true}, nil
+}
+
+func toScheduleResponse(schedule repo.ExamSchedule) scheduleResponse {
+	res := scheduleResponse{
+		ExamID:                schedule.ExamID,
+		TimeZone:              schedule.TimeZone,
+		RetakeCooldownMinutes: schedule.RetakeCooldownMinutes,
+		AssignedOnly:          schedule.AssignedOnly,
+		RequiresAccess:        schedule.RequiresAccess,
+	}
+
+	loc, err := time.LoadLocation(schedule.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	if schedule.OpensAt.Valid {
+		opensAt := schedule.OpensAt.Time.In(loc).Format(time.RFC3339)
+		res.OpensAt = &opensAt
+	}
+
+	if schedule.ClosesAt.Valid {
+		closesAt := schedule.ClosesAt.Time.In(loc).Format(time.RFC3339)
+		res.ClosesAt = &closesAt
+	}
+
+	if schedule.MaxAttempts.Valid {
+		res.MaxAttempts = &schedule.MaxAttempts.Int32
+	}
+
+	return res
+}
+
+// normalize lower-cases and trims values so school and state names match
+// however they were typed.
+func normalize(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if n := normalizeOne(v); n != "" && !slices.Contains(out, n) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+func normalizeOne(v string) string {
+	return strings.ToLower(strings.TrimSpace(v))
+}
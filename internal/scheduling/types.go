@@ -0,0 +1,80 @@
+package scheduling
+
+import (
+	"context"
+	"time"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+type Service interface {
+	GetSchedule(ctx context.Context, examID int64) (scheduleResponse, error)
+	UpsertSchedule(ctx context.Context, examID int64, params upsertScheduleParams) (scheduleResponse, error)
+	GetEligibility(ctx context.Context, examID int64) (repo.ExamEligibilityRule, error)
+	UpsertEligibility(ctx context.Context, examID int64, params upsertEligibilityParams) (repo.ExamEligibilityRule, error)
+	CreateGroup(ctx context.Context, createdBy int64, params createGroupParams) (repo.CandidateGroup, error)
+	ListGroups(ctx context.Context, limit, offset int32) ([]repo.CandidateGroup, error)
+	AddGroupMembers(ctx context.Context, groupID int64, params groupMembersParams) (int64, error)
+	ListGroupMembers(ctx context.Context, groupID int64, limit, offset int32) ([]repo.CandidateGroupMember, error)
+	RemoveGroupMember(ctx context.Context, groupID, userID int64) error
+	AssignExam(ctx context.Context, examID int64, params assignExamParams) (int64, error)
+	ListAssignments(ctx context.Context, examID int64) ([]repo.ExamAssignment, error)
+	RemoveAssignment(ctx context.Context, examID, assignmentID int64) error
+	CheckEligibility(ctx context.Context, userID, examID int64, now time.Time) (time.Time, error)
+}
+
+// Entitlements decides whether a candidate has bought or subscribed to an
+// exam that requires access.
+type Entitlements interface {
+	Allowed(ctx context.Context, userID int64, target repo.AccessTarget, targetID int64) (bool, error)
+}
+
+type upsertScheduleParams struct {
+	// OpensAt and ClosesAt are wall-clock times in TimeZone, written as
+	// 2006-01-02T15:04. RFC 3339 timestamps are accepted as well.
+	OpensAt               string `json:"opens_at"`
+	ClosesAt              string `json:"closes_at"`
+	TimeZone              string `json:"time_zone"`
+	MaxAttempts           int32  `json:"max_attempts" validate:"gte=0"`
+	RetakeCooldownMinutes int32  `json:"retake_cooldown_minutes" validate:"gte=0"`
+	AssignedOnly          bool   `json:"assigned_only"`
+	// RequiresAccess makes candidates unlock the exam, e.g. with a voucher.
+	RequiresAccess bool `json:"requires_access"`
+}
+
+type scheduleResponse struct {
+	ExamID   int64   `json:"exam_id"`
+	TimeZone string  `json:"time_zone"`
+	OpensAt  *string `json:"opens_at"`
+	ClosesAt *string `json:"closes_at"`
+	// MaxAttempts is nil when attempts are unlimited.
+	MaxAttempts           *int32 `json:"max_attempts"`
+	RetakeCooldownMinutes int32  `json:"retake_cooldown_minutes"`
+	AssignedOnly          bool   `json:"assigned_only"`
+	RequiresAccess        bool   `json:"requires_access"`
+}
+
+type upsertEligibilityParams struct {
+	Schools                 []string `json:"schools" validate:"dive,required"`
+	States                  []string `json:"states" validate:"dive,required"`
+	RequireProfileCompleted bool     `json:"require_profile_completed"`
+	RequireEmailVerified    bool     `json:"require_email_verified"`
+}
+
+type createGroupParams struct {
+	Name        string `json:"name" validate:"required,min=2,max=100"`
+	Description string `json:"description" validate:"max=500"`
+}
+
+type groupMembersParams struct {
+	UserIDs []int64 `json:"user_ids" validate:"required,min=1,dive,gt=0"`
+}
+
+type assignExamParams struct {
+	UserIDs  []int64 `json:"user_ids" validate:"dive,gt=0"`
+	GroupIDs []int64 `json:"group_ids" validate:"dive,gt=0"`
+}
+
+type countResponse struct {
+	Added int64 `json:"added"`
+}
@@ -0,0 +1,132 @@
+package similarity
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/helpers"
+	"github.com/odundlaw/cbt-backend/internal/json"
+	"github.com/odundlaw/cbt-backend/internal/middlewares"
+	"github.com/odundlaw/cbt-backend/internal/validation"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service,
+	}
+}
+
+// ListFlags is the duplicate report, most alike pairs first.
+func (h *Handler) ListFlags(w http.ResponseWriter, r *http.Request) {
+	limit, offset := helpers.Pagination(r)
+
+	filter := FlagFilter{Status: repo.DuplicateStatus(r.URL.Query().Get("status"))}
+
+	if err := validation.Validate.Var(filter.Status, "omitempty,oneof=open merged dismissed"); err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var err error
+	if filter.QuestionID, err = helpers.OptionalInt64(r, "question_id"); err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	flags, err := h.service.ListFlags(r.Context(), filter, limit, offset)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, flags, nil)
+}
+
+func (h *Handler) Scan(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.Scan(r.Context())
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgDuplicatesScanned, res, nil)
+}
+
+func (h *Handler) Merge(w http.ResponseWriter, r *http.Request) {
+	flagID, err := helpers.IDParam(r, "flagID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req mergeParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	adminID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	res, err := h.service.Merge(r.Context(), flagID, adminID, req)
+	if err != nil {
+		writeDuplicateError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgDuplicatesMerged, res, nil)
+}
+
+func (h *Handler) Dismiss(w http.ResponseWriter, r *http.Request) {
+	flagID, err := helpers.IDParam(r, "flagID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	adminID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	flag, err := h.service.Dismiss(r.Context(), flagID, adminID)
+	if err != nil {
+		writeDuplicateError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgDuplicateDismissed, flag, nil)
+}
+
+func writeDuplicateError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		json.JSONError(w, http.StatusNotFound, constants.ErrQuestionNotFound, nil)
+	case errors.Is(err, ErrFlagNotFound):
+		json.JSONError(w, http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, ErrKeepNotInPair):
+		json.JSONError(w, http.StatusUnprocessableEntity, err.Error(), nil)
+	case errors.Is(err, ErrFlagResolved), errors.Is(err, ErrKeepNotUsable), errors.Is(err, ErrKeepNotApproved):
+		json.JSONError(w, http.StatusConflict, err.Error(), nil)
+	default:
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+	}
+}
@@ -0,0 +1,283 @@
+// Package similarity where near-duplicate questions are found by comparing
+// MinHash signatures of their text, and merged
+package similarity
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/config"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+)
+
+var (
+	ErrFlagNotFound    = errors.New(constants.ErrDuplicateFlagNotFound)
+	ErrFlagResolved    = errors.New(constants.ErrDuplicateResolved)
+	ErrKeepNotInPair   = errors.New(constants.ErrKeepNotInPair)
+	ErrKeepNotUsable   = errors.New(constants.ErrKeepNotUsable)
+	ErrKeepNotApproved = errors.New(constants.ErrKeepNotApproved)
+)
+
+type svc struct {
+	repo *repo.Queries
+	db   *pgxpool.Pool
+}
+
+func NewService(repo *repo.Queries, db *pgxpool.Pool) Service {
+	return &svc{repo: repo, db: db}
+}
+
+// Index stores the signature of a question's current text and flags the
+// bank questions it is a near-duplicate of. It is called in the same
+// transaction as every change to a question's content. Open flags on the
+// question are worked out again; dismissed ones stay dismissed.
+func Index(ctx context.Context, q *repo.Queries, question repo.Question) ([]Match, error) {
+	sig := Sign(question.Stem, question.Options)
+
+	if err := q.SaveQuestionSignature(ctx, repo.SaveQuestionSignatureParams{
+		QuestionID: question.ID,
+		Minhash:    sig.MinHash,
+		Bands:      sig.Bands,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := q.ClearOpenDuplicateFlags(ctx, question.ID); err != nil {
+		return nil, err
+	}
+
+	// A merged question has been dealt with.
+	if question.MergedInto.Valid {
+		return nil, nil
+	}
+
+	matches, err := find(ctx, q, sig, question.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range matches {
+		// The newer question is the one flagged as the duplicate.
+		newer, older := question.ID, m.QuestionID
+		if newer < older {
+			newer, older = older, newer
+		}
+		if err := q.FlagDuplicate(ctx, repo.FlagDuplicateParams{
+			QuestionID:  newer,
+			DuplicateOf: older,
+			Similarity:  m.Similarity,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return matches, nil
+}
+
+// Find lists the bank questions a question not yet stored would be a
+// near-duplicate of, most alike first.
+func Find(ctx context.Context, q *repo.Queries, stem string, options []byte) ([]Match, error) {
+	return find(ctx, q, Sign(stem, options), 0)
+}
+
+func find(ctx context.Context, q *repo.Queries, sig Signature, questionID int64) ([]Match, error) {
+	if len(sig.Bands) == 0 {
+		return nil, nil
+	}
+
+	candidates, err := q.FindSignatureCandidates(ctx, repo.FindSignatureCandidatesParams{
+		Bands:      sig.Bands,
+		QuestionID: questionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := float64(config.DuplicateSimilarityPercent) / 100
+
+	var matches []Match
+	for _, c := range candidates {
+		if sim := Similarity(sig.MinHash, c.Minhash); sim >= threshold {
+			matches = append(matches, Match{QuestionID: c.QuestionID, Similarity: sim})
+		}
+	}
+
+	slices.SortFunc(matches, func(a, b Match) int {
+		if c := cmp.Compare(b.Similarity, a.Similarity); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.QuestionID, b.QuestionID)
+	})
+
+	return matches, nil
+}
+
+func (s *svc) ListFlags(ctx context.Context, filter FlagFilter, limit, offset int32) ([]repo.ListDuplicateFlagsRow, error) {
+	return s.repo.ListDuplicateFlags(ctx, repo.ListDuplicateFlagsParams{
+		Status:     repo.NullDuplicateStatus{DuplicateStatus: filter.Status, Valid: filter.Status != ""},
+		QuestionID: pgtype.Int8{Int64: filter.QuestionID, Valid: filter.QuestionID != 0},
+		Limit:      limit,
+		Offset:     offset,
+	})
+}
+
+// Scan indexes a batch of questions that have no signature yet, such as
+// those in the bank before duplicate detection, and reports how many are
+// left for the next call.
+func (s *svc) Scan(ctx context.Context) (scanResponse, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return scanResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	questions, err := qtx.ListUnindexedQuestions(ctx, int32(config.DuplicateScanBatch))
+	if err != nil {
+		return scanResponse{}, err
+	}
+
+	var res scanResponse
+	for _, question := range questions {
+		matches, err := Index(ctx, qtx, question)
+		if err != nil {
+			return scanResponse{}, err
+		}
+		res.Indexed++
+		res.Flagged += len(matches)
+	}
+
+	if res.Remaining, err = qtx.CountUnindexedQuestions(ctx); err != nil {
+		return scanResponse{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return scanResponse{}, err
+	}
+
+	return res, nil
+}
+
+// Merge keeps one question of a flagged pair and retires the other as
+// merged into it. Attempts, versions and scores of the merged question are
+// left as they are, so past papers and results read as they did. Exams no
+// one has sat yet are moved onto the kept question.
+func (s *svc) Merge(ctx context.Context, flagID, resolvedBy int64, params mergeParams) (mergeResponse, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return mergeResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	flag, err := openFlag(ctx, qtx, flagID)
+	if err != nil {
+		return mergeResponse{}, err
+	}
+
+	var mergedID int64
+	switch params.KeepID {
+	case flag.QuestionID:
+		mergedID = flag.DuplicateOf
+	case flag.DuplicateOf:
+		mergedID = flag.QuestionID
+	default:
+		return mergeResponse{}, ErrKeepNotInPair
+	}
+
+	kept, err := qtx.GetQuestionByID(ctx, params.KeepID)
+	if err != nil {
+		return mergeResponse{}, err
+	}
+	if kept.Status == repo.QuestionStatusRetired || kept.MergedInto.Valid {
+		return mergeResponse{}, ErrKeepNotUsable
+	}
+
+	merged, err := qtx.MergeQuestion(ctx, repo.MergeQuestionParams{
+		ID:         mergedID,
+		MergedInto: pgtype.Int8{Int64: kept.ID, Valid: true},
+	})
+	if err != nil {
+		return mergeResponse{}, err
+	}
+
+	moved, err := qtx.MoveMergedExamQuestions(ctx, repo.MoveMergedExamQuestionsParams{KeepID: kept.ID, MergedID: mergedID})
+	if err != nil {
+		return mergeResponse{}, err
+	}
+
+	// Only approved questions go on exams, so a kept draft can't take the
+	// merged question's place on one.
+	if moved > 0 && kept.Status != repo.QuestionStatusApproved {
+		return mergeResponse{}, ErrKeepNotApproved
+	}
+
+	// Unsat exams that already have the kept question just lose the merged one.
+	dropped, err := qtx.DropMergedExamQuestions(ctx, mergedID)
+	if err != nil {
+		return mergeResponse{}, err
+	}
+
+	by := pgtype.Int8{Int64: resolvedBy, Valid: true}
+
+	if _, err := qtx.ResolveDuplicateFlag(ctx, repo.ResolveDuplicateFlagParams{
+		ID:         flagID,
+		Status:     repo.DuplicateStatusMerged,
+		ResolvedBy: by,
+	}); err != nil {
+		return mergeResponse{}, err
+	}
+
+	// The merged question's other flags have nothing left to decide.
+	if err := qtx.DismissDuplicateFlagsFor(ctx, repo.DismissDuplicateFlagsForParams{QuestionID: mergedID, ResolvedBy: by}); err != nil {
+		return mergeResponse{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return mergeResponse{}, err
+	}
+
+	return mergeResponse{Kept: kept, Merged: merged, ExamsUpdated: moved + dropped}, nil
+}
+
+// Dismiss marks a flagged pair as not duplicates. They are not flagged again.
+func (s *svc) Dismiss(ctx context.Context, flagID, resolvedBy int64) (repo.DuplicateFlag, error) {
+	if _, err := openFlag(ctx, s.repo, flagID); err != nil {
+		return repo.DuplicateFlag{}, err
+	}
+
+	flag, err := s.repo.ResolveDuplicateFlag(ctx, repo.ResolveDuplicateFlagParams{
+		ID:         flagID,
+		Status:     repo.DuplicateStatusDismissed,
+		ResolvedBy: pgtype.Int8{Int64: resolvedBy, Valid: true},
+	})
+	// Resolved by someone else in the meantime.
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.DuplicateFlag{}, ErrFlagResolved
+	}
+	return flag, err
+}
+
+func openFlag(ctx context.Context, q *repo.Queries, flagID int64) (repo.DuplicateFlag, error) {
+	flag, err := q.GetDuplicateFlag(ctx, flagID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.DuplicateFlag{}, ErrFlagNotFound
+	}
+	if err != nil {
+		return repo.DuplicateFlag{}, err
+	}
+
+	if flag.Status != repo.DuplicateStatusOpen {
+		return repo.DuplicateFlag{}, ErrFlagResolved
+	}
+
+	return flag, nil
+}
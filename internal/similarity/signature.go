@@ -0,0 +1,192 @@
+package similarity
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"hash/fnv"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	// shingleSize is the number of words in a shingle.
+	shingleSize = 3
+	// numHashes is the length of a MinHash signature. Two signatures agree
+	// at a position with the probability that the shingle sets overlap, so
+	// the share of agreeing positions estimates their Jaccard similarity.
+	numHashes = 128
+	// Signatures are cut into bands of bandRows values. Questions sharing a
+	// band are compared; at 4 rows a pair 80% alike shares one almost
+	// always, and a pair 30% alike only about a quarter of the time.
+	bandRows = 4
+	numBands = numHashes / bandRows
+)
+
+// seeds pick the MinHash functions. They are fixed, since signatures stored
+// earlier are compared with new ones.
+var seeds = func() [numHashes]uint64 {
+	var s [numHashes]uint64
+	x := uint64(0)
+	for i := range s {
+		x = mix(x + uint64(i))
+		s[i] = x
+	}
+	return s
+}()
+
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// Signature is the MinHash signature of a question's text and the band
+// hashes used to find other questions it may be close to. Both are empty
+// for a question with no text, such as one that is only an image.
+type Signature struct {
+	MinHash []int64
+	Bands   []int64
+}
+
+// Sign computes the signature of a question from its stem and the text of
+// its options. The explanation is left out: the same question explained
+// differently is still the same question.
+func Sign(stem string, options []byte) Signature {
+	set := map[uint64]struct{}{}
+	for _, text := range append([]string{stem}, optionTexts(options)...) {
+		shingle(Normalize(text), set)
+	}
+
+	if len(set) == 0 {
+		return Signature{MinHash: []int64{}, Bands: []int64{}}
+	}
+
+	minhash := make([]int64, numHashes)
+	for i, seed := range seeds {
+		low := ^uint64(0)
+		for sh := range set {
+			if h := mix(sh ^ seed); h < low {
+				low = h
+			}
+		}
+		minhash[i] = int64(low)
+	}
+
+	bands := make([]int64, numBands)
+	for b := range bands {
+		h := fnv.New64a()
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], uint64(b))
+		h.Write(buf[:])
+		for _, v := range minhash[b*bandRows : (b+1)*bandRows] {
+			binary.LittleEndian.PutUint64(buf[:], uint64(v))
+			h.Write(buf[:])
+		}
+		bands[b] = int64(h.Sum64())
+	}
+
+	return Signature{MinHash: minhash, Bands: bands}
+}
+
+// Similarity estimates how alike two questions' text is, from 0 to 1.
+func Similarity(a, b []int64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+
+	same := 0
+	for i := range a {
+		if a[i] == b[i] {
+			same++
+		}
+	}
+	return float64(same) / float64(len(a))
+}
+
+// Normalize reduces question text to its words: markup and entities are
+// dropped, letters lower-cased and punctuation removed. Symbols such as +
+// and × are kept as words of their own, so 2 + 3 and 2 × 3 differ.
+func Normalize(text string) []string {
+	text = html.UnescapeString(tagPattern.ReplaceAllString(text, " "))
+	text = strings.ToLower(text)
+
+	var words []string
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			words = append(words, text[start:i])
+			start = -1
+		}
+		if unicode.IsSymbol(r) {
+			words = append(words, string(r))
+		}
+	}
+	if start >= 0 {
+		words = append(words, text[start:])
+	}
+
+	return words
+}
+
+// shingle adds the hashes of every run of shingleSize words to set. Text
+// shorter than that is one shingle.
+func shingle(words []string, set map[uint64]struct{}) {
+	if len(words) == 0 {
+		return
+	}
+
+	n := min(shingleSize, len(words))
+	for i := 0; i+n <= len(words); i++ {
+		h := fnv.New64a()
+		for _, w := range words[i : i+n] {
+			h.Write([]byte(w))
+			h.Write([]byte{0})
+		}
+		set[h.Sum64()] = struct{}{}
+	}
+}
+
+// optionTexts collects the text of every option, in the shapes richtext
+// accepts: a list of choices or matching prompts and choices.
+func optionTexts(options []byte) []string {
+	var v any
+	if err := json.NewDecoder(bytes.NewReader(options)).Decode(&v); err != nil {
+		return nil
+	}
+
+	var texts []string
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case []any:
+			for _, item := range v {
+				walk(item)
+			}
+		case map[string]any:
+			for key, item := range v {
+				if text, ok := item.(string); ok && key == "text" {
+					texts = append(texts, text)
+					continue
+				}
+				walk(item)
+			}
+		}
+	}
+	walk(v)
+
+	return texts
+}
+
+// mix is the splitmix64 finalizer, which spreads a 64-bit value evenly
+// over all 64 bits.
+func mix(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
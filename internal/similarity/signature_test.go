@@ -0,0 +1,187 @@
+package similarity
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"What is the Capital of Nigeria?", []string{"what", "is", "the", "capital", "of", "nigeria"}},
+		{"<p>What&nbsp;is <b>2</b>+3?</p>", []string{"what", "is", "2", "+", "3"}},
+		{"2 × 3 = ?", []string{"2", "×", "3", "="}},
+		{"Ọmọ ẹ̀kọ́, café!", []string{"ọmọ", "ẹ", "kọ", "café"}},
+		{`<img src="/api/media/4">`, nil},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Normalize(%q) = %q; want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSign(t *testing.T) {
+	options := []byte(`[{"label":"A","text":"Lagos"},{"label":"B","text":"Abuja"}]`)
+
+	tests := []struct {
+		name     string
+		a, b     string
+		aOptions []byte
+		bOptions []byte
+		want     float64
+	}{
+		{
+			name: "same text",
+			a:    "Which city is the capital of Nigeria?", aOptions: options,
+			b: "Which city is the capital of Nigeria?", bOptions: options,
+			want: 1,
+		},
+		{
+			name: "markup, case and punctuation do not count",
+			a:    "<p>Which city is the <b>capital</b> of Nigeria?</p>", aOptions: options,
+			b: "which city is the capital of nigeria", bOptions: options,
+			want: 1,
+		},
+		{
+			name: "option labels do not count",
+			a:    "Which city is the capital of Nigeria?", aOptions: options,
+			b: "Which city is the capital of Nigeria?", bOptions: []byte(`[{"label":"1","text":"Lagos"},{"label":"2","text":"Abuja"}]`),
+			want: 1,
+		},
+		{
+			name: "matching prompts and choices count",
+			a:    "Match the capitals", aOptions: []byte(`{"prompts":[{"label":"1","text":"Nigeria"}],"choices":[{"label":"a","text":"Abuja"}]}`),
+			b: "Match the capitals", bOptions: []byte(`{"prompts":[{"label":"1","text":"Ghana"}],"choices":[{"label":"a","text":"Accra"}]}`),
+			// One shingle shared out of five.
+			want: 0.2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := Sign(tt.a, tt.aOptions), Sign(tt.b, tt.bOptions)
+			if got := Similarity(a.MinHash, b.MinHash); math.Abs(got-tt.want) > 0.1 {
+				t.Errorf("Similarity() = %v; want about %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignEmpty(t *testing.T) {
+	for _, stem := range []string{"", `<p><img src="/api/media/4"></p>`} {
+		sig := Sign(stem, []byte(`[]`))
+		if sig.MinHash == nil || sig.Bands == nil || len(sig.MinHash) != 0 || len(sig.Bands) != 0 {
+			t.Errorf("Sign(%q) = %+v; want empty, non-nil slices", stem, sig)
+		}
+	}
+
+	if got := Similarity(nil, nil); got != 0 {
+		t.Errorf("Similarity() of two empty signatures = %v; want 0", got)
+	}
+}
+
+func TestSignStable(t *testing.T) {
+	// Signatures are stored, so the hash functions must never change.
+	sig := Sign("Which city is the capital of Nigeria?", nil)
+	if len(sig.MinHash) != numHashes || len(sig.Bands) != numBands {
+		t.Fatalf("Sign() = %d values in %d bands; want %d in %d", len(sig.MinHash), len(sig.Bands), numHashes, numBands)
+	}
+	if again := Sign("Which city is the capital of Nigeria?", nil); !reflect.DeepEqual(sig, again) {
+		t.Error("Sign() is not deterministic")
+	}
+	if got := sig.MinHash[0]; got != 727978962214810428 {
+		t.Errorf("Sign() first value = %d; want 727978962214810428", got)
+	}
+}
+
+// words returns n distinct words, starting from the from'th.
+func words(from, n int) []string {
+	w := make([]string, n)
+	for i := range w {
+		w[i] = fmt.Sprintf("w%d", from+i)
+	}
+	return w
+}
+
+// jaccard is the exact similarity the signatures estimate.
+func jaccard(a, b string) float64 {
+	sa, sb := map[uint64]struct{}{}, map[uint64]struct{}{}
+	shingle(Normalize(a), sa)
+	shingle(Normalize(b), sb)
+
+	shared := 0
+	for h := range sa {
+		if _, ok := sb[h]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(sa)+len(sb)-shared)
+}
+
+func TestSimilarityEstimate(t *testing.T) {
+	base := words(0, 60)
+
+	// At about 0.3 alike a pair shares a band only by chance, so wantBands
+	// is nil there.
+	yes, no := true, false
+	tests := []struct {
+		name      string
+		other     []string
+		wantBands *bool
+	}{
+		{"one word changed", append(append(words(0, 30), "changed"), words(31, 29)...), &yes},
+		{"a sentence added", append(words(0, 60), words(100, 6)...), &yes},
+		{"half rewritten", append(words(0, 30), words(200, 30)...), nil},
+		{"unrelated", words(300, 60), &no},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := strings.Join(base, " "), strings.Join(tt.other, " ")
+			sa, sb := Sign(a, nil), Sign(b, nil)
+
+			want := jaccard(a, b)
+			if got := Similarity(sa.MinHash, sb.MinHash); math.Abs(got-want) > 0.12 {
+				t.Errorf("Similarity() = %.3f; want about %.3f", got, want)
+			}
+
+			shared := false
+			for i := range sa.Bands {
+				shared = shared || sa.Bands[i] == sb.Bands[i]
+			}
+			if tt.wantBands != nil && shared != *tt.wantBands {
+				t.Errorf("share a band = %v at similarity %.3f; want %v", shared, want, *tt.wantBands)
+			}
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []int64
+		want float64
+	}{
+		{"equal", []int64{1, 2, 3, 4}, []int64{1, 2, 3, 4}, 1},
+		{"half", []int64{1, 2, 3, 4}, []int64{1, 0, 3, 0}, 0.5},
+		{"none", []int64{1, 2}, []int64{3, 4}, 0},
+		{"different lengths", []int64{1, 2}, []int64{1, 2, 3}, 0},
+		{"empty", []int64{}, []int64{}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Similarity(tt.a, tt.b); got != tt.want {
+				t.Errorf("Similarity() = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package similarity
+
+import (
+	"context"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+type Service interface {
+	ListFlags(ctx context.Context, filter FlagFilter, limit, offset int32) ([]repo.ListDuplicateFlagsRow, error)
+	Scan(ctx context.Context) (scanResponse, error)
+	Merge(ctx context.Context, flagID, resolvedBy int64, params mergeParams) (mergeResponse, error)
+	Dismiss(ctx context.Context, flagID, resolvedBy int64) (repo.DuplicateFlag, error)
+}
+
+type FlagFilter struct {
+	Status repo.DuplicateStatus
+	// QuestionID narrows the report to flags involving one question.
+	QuestionID int64
+}
+
+// Match is a bank question found to be a near-duplicate.
+type Match struct {
+	QuestionID int64   `json:"question_id"`
+	Similarity float64 `json:"similarity"`
+}
+
+type mergeParams struct {
+	// KeepID is the question of the pair that stays in the bank.
+	KeepID int64 `json:"keep_id" validate:"required,gt=0"`
+}
+
+type mergeResponse struct {
+	Kept   repo.Question `json:"kept"`
+	Merged repo.Question `json:"merged"`
+	// ExamsUpdated counts the unsat exams moved onto the kept question.
+	ExamsUpdated int64 `json:"exams_updated"`
+}
+
+type scanResponse struct {
+	Indexed   int   `json:"indexed"`
+	Flagged   int   `json:"flagged"`
+	Remaining int64 `json:"remaining"`
+}
@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// S3Config points at a bucket on AWS S3 or a compatible service such as
+// MinIO or R2. Objects are addressed path-style, endpoint/bucket/key, which
+// every compatible service accepts.
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+// S3 keeps blobs as objects in a bucket, signing requests with AWS
+// Signature Version 4.
+type S3 struct {
+	cfg      S3Config
+	endpoint *url.URL
+	client   *http.Client
+}
+
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, errors.New("s3 storage needs an endpoint, bucket, access key and secret key")
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(cfg.Endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3 endpoint: %w", err)
+	}
+	return &S3{cfg: cfg, endpoint: endpoint, client: &http.Client{Timeout: time.Minute}}, nil
+}
+
+func (s *S3) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	res, err := s.do(ctx, http.MethodPut, key, data, contentType)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+func (s *S3) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	res, err := s.do(ctx, http.MethodGet, key, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	res, err := s.do(ctx, http.MethodDelete, key, nil, "")
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+// do sends a signed request. A 404 is ErrNotFound and any other failure
+// status an error carrying the start of the response body.
+func (s *S3) do(ctx context.Context, method, key string, body []byte, contentType string) (*http.Response, error) {
+	u := *s.endpoint
+	u.RawPath = u.EscapedPath() + "/" + escapePath(s.cfg.Bucket+"/"+key)
+	u.Path = u.Path + "/" + s.cfg.Bucket + "/" + key
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.sign(req, body, time.Now().UTC())
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil, ErrNotFound
+	}
+	if res.StatusCode >= 300 {
+		defer res.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return nil, fmt.Errorf("s3 %s %s: %s: %s", method, key, res.Status, bytes.TrimSpace(msg))
+	}
+	return res, nil
+}
+
+// sign adds the Authorization header for AWS Signature Version 4.
+func (s *S3) sign(req *http.Request, body []byte, now time.Time) {
+	payload := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(payload[:])
+	stamp := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", stamp)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	names := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	values := map[string]string{"host": req.URL.Host, "x-amz-content-sha256": payloadHash, "x-amz-date": stamp}
+	if ct := req.Header.Get("Content-Type"); ct != "" {
+		names = []string{"content-type", "host", "x-amz-content-sha256", "x-amz-date"}
+		values["content-type"] = ct
+	}
+
+	var headers strings.Builder
+	for _, name := range names {
+		headers.WriteString(name + ":" + strings.TrimSpace(values[name]) + "\n")
+	}
+	signed := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		headers.String(),
+		signed,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + s.cfg.Region + "/s3/aws4_request"
+	digest := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + stamp + "\n" + scope + "\n" + hex.EncodeToString(digest[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), day)
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKey, scope, signed, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// escapePath percent-encodes each segment of a key the way Signature
+// Version 4 expects: everything but unreserved characters.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, seg := range segments {
+		var b strings.Builder
+		for _, c := range []byte(seg) {
+			if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+				b.WriteByte(c)
+			} else {
+				fmt.Fprintf(&b, "%%%02X", c)
+			}
+		}
+		segments[i] = b.String()
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,39 @@
+// Package storage where file contents are kept, on local disk or in an
+// S3-compatible bucket
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/odundlaw/cbt-backend/internal/config"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// Blob stores opaque contents by key. Keys are slash-separated paths such as
+// "sha256/ab/abcd...". Writing a key that exists replaces it.
+type Blob interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// New returns the driver STORAGE_DRIVER names.
+func New() (Blob, error) {
+	switch config.StorageDriver {
+	case "local":
+		return NewLocal(config.StorageDir)
+	case "s3":
+		return NewS3(S3Config{
+			Endpoint:  config.S3Endpoint,
+			Region:    config.S3Region,
+			Bucket:    config.S3Bucket,
+			AccessKey: config.S3AccessKey,
+			SecretKey: config.S3SecretKey,
+		})
+	}
+	return nil, fmt.Errorf("unknown storage driver %q", config.StorageDriver)
+}
@@ -0,0 +1,263 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/redis/go-redis/v9"
+)
+
+// DirtyAttemptsKey is a sorted set of attempt IDs with unflushed answers,
+// scored by the time of their latest save.
+const DirtyAttemptsKey = "autosave:dirty"
+
+// FailedAttemptsKey holds attempts whose answers Postgres rejected, scored
+// like DirtyAttemptsKey, so they stop holding back the rest of the flush.
+const FailedAttemptsKey = "autosave:failed"
+
+var (
+	ErrAttemptNotCached   = errors.New(constants.ErrAttemptNotCached)
+	ErrAttemptNotOwner    = errors.New(constants.ErrAttemptNotOwner)
+	ErrAttemptClosed      = errors.New(constants.ErrAttemptClosed)
+	ErrAttemptExpired     = errors.New(constants.ErrAttemptExpired)
+	ErrAnswerVersionStale = errors.New(constants.ErrAnswerVersionStale)
+	ErrQuestionNotOnPaper = errors.New(constants.ErrQuestionNotOnPaper)
+)
+
+// AttemptMeta is the slice of an attempt the save path needs to accept or
+// reject an answer without touching Postgres.
+type AttemptMeta struct {
+	AttemptID int64
+	ExamID    int64
+	UserID    int64
+	Status    string
+	ExpiresAt time.Time
+	// QuestionIDs are the questions on the attempt's paper; answers to any
+	// other question are refused.
+	QuestionIDs []int64
+}
+
+// SavedAnswer is a single answer as held in Redis.
+type SavedAnswer struct {
+	QuestionID int64           `json:"question_id"`
+	Answer     json.RawMessage `json:"answer"`
+	Version    int64           `json:"version"`
+	SavedAt    int64           `json:"saved_at"`
+}
+
+func attemptMetaKey(attemptID int64) string {
+	return fmt.Sprintf("attempt:%d:meta", attemptID)
+}
+
+func attemptAnswersKey(attemptID int64) string {
+	return fmt.Sprintf("attempt:%d:answers", attemptID)
+}
+
+func attemptVersionsKey(attemptID int64) string {
+	return fmt.Sprintf("attempt:%d:versions", attemptID)
+}
+
+// saveAnswerScript checks the attempt meta, the question and the caller's
+// expected version, bumps the per-question version, stores the answer
+// envelope and marks the attempt dirty, all in one round trip. Meta cached
+// without the paper's questions counts as not cached so it is primed again.
+var saveAnswerScript = redis.NewScript(`
+local meta = redis.call('HMGET', KEYS[1], 'user_id', 'status', 'expires_at', 'questions')
+if not meta[1] or not meta[4] then return {-1, 0} end
+if meta[1] ~= ARGV[6] then return {-2, 0} end
+if meta[2] ~= 'in_progress' then return {-3, 0} end
+if tonumber(meta[3]) < tonumber(ARGV[3]) then return {-4, 0} end
+if not string.find(meta[4], ',' .. ARGV[1] .. ',', 1, true) then return {-6, 0} end
+local current = tonumber(redis.call('HGET', KEYS[3], ARGV[1]) or '0')
+local expected = tonumber(ARGV[4])
+if expected >= 0 and expected ~= current then return {-5, current} end
+local nextv = current + 1
+redis.call('HSET', KEYS[3], ARGV[1], nextv)
+redis.call('HSET', KEYS[2], ARGV[1], '{"question_id":' .. ARGV[1] .. ',"version":' .. nextv .. ',"saved_at":' .. ARGV[3] .. ',"answer":' .. ARGV[2] .. '}')
+redis.call('ZADD', KEYS[4], ARGV[3], ARGV[5])
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl > 0 then
+  redis.call('PEXPIRE', KEYS[2], ttl)
+  redis.call('PEXPIRE', KEYS[3], ttl)
+end
+return {1, nextv}
+`)
+
+// clearDirtyScript removes an attempt from the dirty set only if no save
+// happened after the flush read it.
+var clearDirtyScript = redis.NewScript(`
+local score = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if score and tonumber(score) <= tonumber(ARGV[2]) then
+  return redis.call('ZREM', KEYS[1], ARGV[1])
+end
+return 0
+`)
+
+// closeAttemptScript flips an attempt to submitted so no further saves are
+// accepted while the answers are being reconciled.
+var closeAttemptScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then return 0 end
+redis.call('HSET', KEYS[1], 'status', ARGV[1])
+return 1
+`)
+
+// PrimeAttempt caches the attempt meta used by SaveAnswer, seeding it with
+// answers already persisted so versions keep increasing after Redis loses
+// data. All keys of the attempt live until ttl past the attempt's expiry.
+func (r *Redis) PrimeAttempt(ctx context.Context, meta AttemptMeta, seed []SavedAnswer, ttl time.Duration) error {
+	key := attemptMetaKey(meta.AttemptID)
+	exp := meta.ExpiresAt.Add(ttl)
+
+	pipe := r.Client.TxPipeline()
+	for _, a := range seed {
+		envelope, err := json.Marshal(a)
+		if err != nil {
+			return err
+		}
+		field := strconv.FormatInt(a.QuestionID, 10)
+		pipe.HSetNX(ctx, attemptAnswersKey(meta.AttemptID), field, envelope)
+		pipe.HSetNX(ctx, attemptVersionsKey(meta.AttemptID), field, a.Version)
+	}
+	pipe.HSet(ctx, key,
+		"exam_id", meta.ExamID,
+		"user_id", meta.UserID,
+		"status", meta.Status,
+		"expires_at", meta.ExpiresAt.UnixMilli(),
+		"questions", paperField(meta.QuestionIDs),
+	)
+	pipe.ExpireAt(ctx, key, exp)
+	pipe.ExpireAt(ctx, attemptAnswersKey(meta.AttemptID), exp)
+	pipe.ExpireAt(ctx, attemptVersionsKey(meta.AttemptID), exp)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// SaveAnswer stores an answer and returns its new version. expected is the
+// version the client last saw, or -1 to overwrite unconditionally.
+func (r *Redis) SaveAnswer(ctx context.Context, attemptID, userID, questionID int64, answer []byte, expected int64, now time.Time) (int64, error) {
+	keys := []string{
+		attemptMetaKey(attemptID),
+		attemptAnswersKey(attemptID),
+		attemptVersionsKey(attemptID),
+		DirtyAttemptsKey,
+	}
+
+	res, err := saveAnswerScript.Run(ctx, r.Client, keys,
+		questionID, string(answer), now.UnixMilli(), expected, attemptID, userID,
+	).Int64Slice()
+	if err != nil {
+		return 0, err
+	}
+
+	switch res[0] {
+	case -1:
+		return 0, ErrAttemptNotCached
+	case -2:
+		return 0, ErrAttemptNotOwner
+	case -3:
+		return 0, ErrAttemptClosed
+	case -4:
+		return 0, ErrAttemptExpired
+	case -5:
+		return res[1], ErrAnswerVersionStale
+	case -6:
+		return 0, ErrQuestionNotOnPaper
+	}
+
+	return res[1], nil
+}
+
+// AttemptAnswers returns every answer cached for the attempt.
+func (r *Redis) AttemptAnswers(ctx context.Context, attemptID int64) ([]SavedAnswer, error) {
+	raw, err := r.Client.HGetAll(ctx, attemptAnswersKey(attemptID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	answers := make([]SavedAnswer, 0, len(raw))
+	for field, value := range raw {
+		var a SavedAnswer
+		if err := json.Unmarshal([]byte(value), &a); err != nil {
+			return nil, fmt.Errorf("attempt %d question %s: %w", attemptID, field, err)
+		}
+		answers = append(answers, a)
+	}
+
+	return answers, nil
+}
+
+// DirtyAttempts returns up to limit attempt IDs with unflushed answers along
+// with the score they were read at.
+func (r *Redis) DirtyAttempts(ctx context.Context, limit int64) (map[int64]float64, error) {
+	zs, err := r.Client.ZRangeWithScores(ctx, DirtyAttemptsKey, 0, limit-1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	dirty := make(map[int64]float64, len(zs))
+	for _, z := range zs {
+		member, _ := z.Member.(string)
+		id, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		dirty[id] = z.Score
+	}
+
+	return dirty, nil
+}
+
+// ClearDirty drops the attempt from the dirty set unless it was saved again
+// after score.
+func (r *Redis) ClearDirty(ctx context.Context, attemptID int64, score float64) error {
+	return clearDirtyScript.Run(ctx, r.Client, []string{DirtyAttemptsKey}, attemptID, score).Err()
+}
+
+// FailDirty moves the attempt from the dirty set to FailedAttemptsKey. A
+// later save marks it dirty again.
+func (r *Redis) FailDirty(ctx context.Context, attemptID int64, score float64) error {
+	pipe := r.Client.TxPipeline()
+	pipe.ZAdd(ctx, FailedAttemptsKey, redis.Z{Score: score, Member: attemptID})
+	pipe.ZRem(ctx, DirtyAttemptsKey, attemptID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// CloseAttempt stops the attempt from accepting saves. It reports false when
+// the attempt is not cached.
+func (r *Redis) CloseAttempt(ctx context.Context, attemptID int64, status string) (bool, error) {
+	n, err := closeAttemptScript.Run(ctx, r.Client, []string{attemptMetaKey(attemptID)}, status).Int()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// ForgetAnswers removes the cached answers of a reconciled attempt. The meta
+// key is kept until it expires so late saves are still rejected as closed.
+func (r *Redis) ForgetAnswers(ctx context.Context, attemptID int64) error {
+	pipe := r.Client.TxPipeline()
+	pipe.Del(ctx, attemptAnswersKey(attemptID), attemptVersionsKey(attemptID))
+	pipe.ZRem(ctx, DirtyAttemptsKey, attemptID)
+	pipe.ZRem(ctx, FailedAttemptsKey, attemptID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// paperField joins question IDs as ",1,2,3," so the save script can look one
+// up with a plain substring search.
+func paperField(questionIDs []int64) string {
+	var b strings.Builder
+	b.WriteByte(',')
+	for _, id := range questionIDs {
+		b.WriteString(strconv.FormatInt(id, 10))
+		b.WriteByte(',')
+	}
+	return b.String()
+}
@@ -0,0 +1,365 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	testAttempt = 7
+	testUser    = 42
+)
+
+func newTestRedis(t *testing.T) (*Redis, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return &Redis{Client: client}, mr
+}
+
+// primeTestAttempt caches an in-progress attempt on questions 1, 2 and 3
+// that expires an hour after now.
+func primeTestAttempt(t *testing.T, r *Redis, now time.Time, seed ...SavedAnswer) {
+	t.Helper()
+
+	err := r.PrimeAttempt(context.Background(), AttemptMeta{
+		AttemptID:   testAttempt,
+		ExamID:      1,
+		UserID:      testUser,
+		Status:      "in_progress",
+		ExpiresAt:   now.Add(time.Hour),
+		QuestionIDs: []int64{1, 2, 3},
+	}, seed, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func dirtyScore(t *testing.T, mr *miniredis.Miniredis, key string) (float64, bool) {
+	t.Helper()
+
+	if !mr.Exists(key) {
+		return 0, false
+	}
+	members, err := mr.ZMembers(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(members, strconv.Itoa(testAttempt)) {
+		return 0, false
+	}
+	score, err := mr.ZScore(key, strconv.Itoa(testAttempt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return score, true
+}
+
+func TestSaveAnswer(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		prime       bool
+		status      string
+		userID      int64
+		questionID  int64
+		expected    int64
+		at          time.Time
+		wantVersion int64
+		wantErr     error
+	}{
+		{name: "first save", prime: true, userID: testUser, questionID: 1, expected: 0, at: now, wantVersion: 1},
+		{name: "overwrite whatever is there", prime: true, userID: testUser, questionID: 1, expected: -1, at: now, wantVersion: 1},
+		{name: "not cached", userID: testUser, questionID: 1, expected: -1, at: now, wantErr: ErrAttemptNotCached},
+		{name: "someone else's attempt", prime: true, userID: 99, questionID: 1, expected: -1, at: now, wantErr: ErrAttemptNotOwner},
+		{name: "closed", prime: true, status: "submitted", userID: testUser, questionID: 1, expected: -1, at: now, wantErr: ErrAttemptClosed},
+		{name: "expired", prime: true, userID: testUser, questionID: 1, expected: -1, at: now.Add(2 * time.Hour), wantErr: ErrAttemptExpired},
+		{name: "not on the paper", prime: true, userID: testUser, questionID: 4, expected: -1, at: now, wantErr: ErrQuestionNotOnPaper},
+		{name: "prefix of a question on the paper", prime: true, userID: testUser, questionID: 11, expected: -1, at: now, wantErr: ErrQuestionNotOnPaper},
+		{name: "stale version", prime: true, userID: testUser, questionID: 1, expected: 3, at: now, wantErr: ErrAnswerVersionStale},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, mr := newTestRedis(t)
+			if tt.prime {
+				primeTestAttempt(t, r, now)
+			}
+			if tt.status != "" {
+				mr.HSet(attemptMetaKey(testAttempt), "status", tt.status)
+			}
+
+			version, err := r.SaveAnswer(ctx, testAttempt, tt.userID, tt.questionID, []byte(`"A"`), tt.expected, tt.at)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SaveAnswer() error = %v; want %v", err, tt.wantErr)
+			}
+			if version != tt.wantVersion {
+				t.Errorf("SaveAnswer() = %d; want %d", version, tt.wantVersion)
+			}
+
+			_, dirty := dirtyScore(t, mr, DirtyAttemptsKey)
+			if dirty != (tt.wantErr == nil) {
+				t.Errorf("attempt dirty = %v; want %v", dirty, tt.wantErr == nil)
+			}
+		})
+	}
+}
+
+func TestSaveAnswerVersions(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	r, _ := newTestRedis(t)
+
+	// Question 1 was saved twice before Redis lost its data.
+	primeTestAttempt(t, r, now, SavedAnswer{QuestionID: 1, Answer: []byte(`"B"`), Version: 2, SavedAt: now.UnixMilli()})
+
+	steps := []struct {
+		answer      string
+		expected    int64
+		wantVersion int64
+		wantErr     error
+	}{
+		{`"C"`, 2, 3, nil},
+		// A second tab still holding version 2 is told the current one.
+		{`"D"`, 2, 3, ErrAnswerVersionStale},
+		{`"D"`, 3, 4, nil},
+		{`"E"`, -1, 5, nil},
+	}
+
+	for i, step := range steps {
+		version, err := r.SaveAnswer(ctx, testAttempt, testUser, 1, []byte(step.answer), step.expected, now)
+		if !errors.Is(err, step.wantErr) || version != step.wantVersion {
+			t.Fatalf("save %d = %d, %v; want %d, %v", i+1, version, err, step.wantVersion, step.wantErr)
+		}
+	}
+
+	answers, err := r.AttemptAnswers(ctx, testAttempt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(answers) != 1 || string(answers[0].Answer) != `"E"` || answers[0].Version != 5 || answers[0].SavedAt != now.UnixMilli() {
+		t.Errorf("AttemptAnswers() = %+v; want only question 1 at version 5 answering \"E\"", answers)
+	}
+}
+
+func TestPrimeAttemptKeepsNewerAnswers(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	r, _ := newTestRedis(t)
+
+	primeTestAttempt(t, r, now)
+	if _, err := r.SaveAnswer(ctx, testAttempt, testUser, 1, []byte(`"new"`), -1, now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.SaveAnswer(ctx, testAttempt, testUser, 1, []byte(`"newer"`), -1, now); err != nil {
+		t.Fatal(err)
+	}
+
+	// Priming again from an older copy in Postgres must not roll back.
+	primeTestAttempt(t, r, now, SavedAnswer{QuestionID: 1, Answer: []byte(`"old"`), Version: 1, SavedAt: now.UnixMilli()})
+
+	answers, err := r.AttemptAnswers(ctx, testAttempt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(answers) != 1 || string(answers[0].Answer) != `"newer"` || answers[0].Version != 2 {
+		t.Errorf("AttemptAnswers() = %+v; want \"newer\" at version 2", answers)
+	}
+
+	version, err := r.SaveAnswer(ctx, testAttempt, testUser, 1, []byte(`"next"`), 2, now)
+	if err != nil || version != 3 {
+		t.Errorf("SaveAnswer() = %d, %v; want 3", version, err)
+	}
+}
+
+func TestPrimeAttemptExpiry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	r, mr := newTestRedis(t)
+
+	primeTestAttempt(t, r, now)
+	if _, err := r.SaveAnswer(ctx, testAttempt, testUser, 1, []byte(`"A"`), -1, now); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every key lives until an hour past the attempt's expiry.
+	for _, key := range []string{attemptMetaKey(testAttempt), attemptAnswersKey(testAttempt), attemptVersionsKey(testAttempt)} {
+		if ttl := mr.TTL(key); ttl < time.Hour || ttl > 2*time.Hour {
+			t.Errorf("TTL(%s) = %v; want about 2h", key, ttl)
+		}
+	}
+}
+
+func TestClearDirty(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		saveAfter bool
+		wantDirty bool
+	}{
+		{name: "nothing saved since the read", wantDirty: false},
+		{name: "saved while the flush was writing", saveAfter: true, wantDirty: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, mr := newTestRedis(t)
+			primeTestAttempt(t, r, now)
+
+			if _, err := r.SaveAnswer(ctx, testAttempt, testUser, 1, []byte(`"A"`), -1, now); err != nil {
+				t.Fatal(err)
+			}
+
+			dirty, err := r.DirtyAttempts(ctx, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			score, ok := dirty[testAttempt]
+			if !ok {
+				t.Fatalf("DirtyAttempts() = %v; want attempt %d", dirty, testAttempt)
+			}
+
+			if tt.saveAfter {
+				if _, err := r.SaveAnswer(ctx, testAttempt, testUser, 2, []byte(`"B"`), -1, now.Add(time.Second)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := r.ClearDirty(ctx, testAttempt, score); err != nil {
+				t.Fatal(err)
+			}
+
+			got, ok := dirtyScore(t, mr, DirtyAttemptsKey)
+			if ok != tt.wantDirty {
+				t.Fatalf("attempt dirty = %v; want %v", ok, tt.wantDirty)
+			}
+			if ok && got != float64(now.Add(time.Second).UnixMilli()) {
+				t.Errorf("dirty score = %v; want the later save's time", got)
+			}
+		})
+	}
+}
+
+func TestFailDirty(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	r, mr := newTestRedis(t)
+	primeTestAttempt(t, r, now)
+
+	if _, err := r.SaveAnswer(ctx, testAttempt, testUser, 1, []byte(`"A"`), -1, now); err != nil {
+		t.Fatal(err)
+	}
+	dirty, err := r.DirtyAttempts(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.FailDirty(ctx, testAttempt, dirty[testAttempt]); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := dirtyScore(t, mr, DirtyAttemptsKey); ok {
+		t.Error("failed attempt is still dirty")
+	}
+	if score, ok := dirtyScore(t, mr, FailedAttemptsKey); !ok || score != dirty[testAttempt] {
+		t.Errorf("failed score = %v, %v; want %v", score, ok, dirty[testAttempt])
+	}
+
+	// The failed answers stay cached for submission.
+	answers, err := r.AttemptAnswers(ctx, testAttempt)
+	if err != nil || len(answers) != 1 {
+		t.Errorf("AttemptAnswers() = %+v, %v; want the failed answer", answers, err)
+	}
+
+	if _, err := r.SaveAnswer(ctx, testAttempt, testUser, 2, []byte(`"B"`), -1, now); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := dirtyScore(t, mr, DirtyAttemptsKey); !ok {
+		t.Error("a save after the failure did not mark the attempt dirty again")
+	}
+}
+
+func TestCloseAttempt(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	r, mr := newTestRedis(t)
+
+	closed, err := r.CloseAttempt(ctx, testAttempt, "submitted")
+	if err != nil || closed {
+		t.Fatalf("CloseAttempt() on an uncached attempt = %v, %v; want false", closed, err)
+	}
+	if mr.Exists(attemptMetaKey(testAttempt)) {
+		t.Error("CloseAttempt() cached meta for an uncached attempt")
+	}
+
+	primeTestAttempt(t, r, now)
+	if _, err := r.SaveAnswer(ctx, testAttempt, testUser, 1, []byte(`"A"`), -1, now); err != nil {
+		t.Fatal(err)
+	}
+
+	closed, err = r.CloseAttempt(ctx, testAttempt, "submitted")
+	if err != nil || !closed {
+		t.Fatalf("CloseAttempt() = %v, %v; want true", closed, err)
+	}
+
+	if _, err := r.SaveAnswer(ctx, testAttempt, testUser, 1, []byte(`"late"`), -1, now); !errors.Is(err, ErrAttemptClosed) {
+		t.Errorf("SaveAnswer() after close error = %v; want %v", err, ErrAttemptClosed)
+	}
+
+	answers, err := r.AttemptAnswers(ctx, testAttempt)
+	if err != nil || len(answers) != 1 || string(answers[0].Answer) != `"A"` {
+		t.Errorf("AttemptAnswers() = %+v, %v; want the answer saved before close", answers, err)
+	}
+}
+
+func TestForgetAnswers(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	r, mr := newTestRedis(t)
+	primeTestAttempt(t, r, now)
+
+	if _, err := r.SaveAnswer(ctx, testAttempt, testUser, 1, []byte(`"A"`), -1, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.FailDirty(ctx, testAttempt, float64(now.UnixMilli())); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.SaveAnswer(ctx, testAttempt, testUser, 2, []byte(`"B"`), -1, now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.CloseAttempt(ctx, testAttempt, "submitted"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.ForgetAnswers(ctx, testAttempt); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{attemptAnswersKey(testAttempt), attemptVersionsKey(testAttempt)} {
+		if mr.Exists(key) {
+			t.Errorf("%s still exists", key)
+		}
+	}
+	for _, key := range []string{DirtyAttemptsKey, FailedAttemptsKey} {
+		if _, ok := dirtyScore(t, mr, key); ok {
+			t.Errorf("attempt still in %s", key)
+		}
+	}
+
+	// The meta stays so a late save is refused rather than primed again.
+	if _, err := r.SaveAnswer(ctx, testAttempt, testUser, 1, []byte(`"late"`), -1, now); !errors.Is(err, ErrAttemptClosed) {
+		t.Errorf("SaveAnswer() after forget error = %v; want %v", err, ErrAttemptClosed)
+	}
+}
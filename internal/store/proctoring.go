@@ -0,0 +1,27 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+func proctorWindowKey(attemptID, window int64) string {
+	return fmt.Sprintf("attempt:%d:proctor:%d", attemptID, window)
+}
+
+// CountProctorEvents adds n to the events an attempt has reported in the
+// current fixed window and returns the new total, so callers can refuse a
+// batch that takes it over their limit.
+func (r *Redis) CountProctorEvents(ctx context.Context, attemptID, n int64, window time.Duration, now time.Time) (int64, error) {
+	key := proctorWindowKey(attemptID, now.UnixMilli()/window.Milliseconds())
+
+	pipe := r.Client.TxPipeline()
+	total := pipe.IncrBy(ctx, key, n)
+	pipe.Expire(ctx, key, 2*window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	return total.Val(), nil
+}
@@ -0,0 +1,249 @@
+package subscriptions
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/jackc/pgx/v5"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/helpers"
+	"github.com/odundlaw/cbt-backend/internal/json"
+	"github.com/odundlaw/cbt-backend/internal/middlewares"
+	"github.com/odundlaw/cbt-backend/internal/validation"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service,
+	}
+}
+
+func (h *Handler) CreatePlan(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	var req createPlanParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	plan, err := h.service.CreatePlan(r.Context(), userID, req)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusCreated, constants.MsgPlanCreated, plan, nil)
+}
+
+func (h *Handler) UpdatePlan(w http.ResponseWriter, r *http.Request) {
+	planID, err := helpers.IDParam(r, "planID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req updatePlanParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	plan, err := h.service.UpdatePlan(r.Context(), planID, req)
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgUpdateSuccessful, plan, nil)
+}
+
+func (h *Handler) GetPlan(w http.ResponseWriter, r *http.Request) {
+	planID, err := helpers.IDParam(r, "planID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	plan, err := h.service.GetPlan(r.Context(), planID)
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, plan, nil)
+}
+
+// ListPlans shows candidates the plans on sale.
+func (h *Handler) ListPlans(w http.ResponseWriter, r *http.Request) {
+	h.listPlans(w, r, true)
+}
+
+func (h *Handler) ListAllPlans(w http.ResponseWriter, r *http.Request) {
+	h.listPlans(w, r, false)
+}
+
+func (h *Handler) listPlans(w http.ResponseWriter, r *http.Request, activeOnly bool) {
+	plans, err := h.service.ListPlans(r.Context(), activeOnly)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, plans, nil)
+}
+
+func (h *Handler) AddPlanItem(w http.ResponseWriter, r *http.Request) {
+	planID, err := helpers.IDParam(r, "planID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req planItemParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	if err := h.service.AddPlanItem(r.Context(), planID, req); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			json.JSONError(w, http.StatusNotFound, constants.ErrExamNotFound, nil)
+			return
+		}
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgPlanItemAdded, nil, nil)
+}
+
+func (h *Handler) RemovePlanItem(w http.ResponseWriter, r *http.Request) {
+	planID, err := helpers.IDParam(r, "planID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	targetID, err := helpers.IDParam(r, "targetID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	req := planItemParams{TargetType: repo.AccessTarget(chi.URLParam(r, "targetType")), TargetID: targetID}
+
+	if err := h.service.RemovePlanItem(r.Context(), planID, req); err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgPlanItemRemoved, nil, nil)
+}
+
+func (h *Handler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	subs, err := h.service.ListSubscriptions(r.Context(), userID)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, subs, nil)
+}
+
+func (h *Handler) Cancel(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	planID, err := helpers.IDParam(r, "planID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	sub, err := h.service.Cancel(r.Context(), userID, planID)
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgSubscriptionCanceled, sub, nil)
+}
+
+func (h *Handler) Grant(w http.ResponseWriter, r *http.Request) {
+	var req grantParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	sub, err := h.service.Grant(r.Context(), req)
+	if errors.Is(err, pgx.ErrNoRows) {
+		json.JSONError(w, http.StatusNotFound, constants.ErrUserNotFound, nil)
+		return
+	}
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusCreated, constants.MsgSubscriptionGranted, sub, nil)
+}
+
+func writeSubscriptionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrPlanNotFound),
+		errors.Is(err, ErrPlanItemNotFound),
+		errors.Is(err, ErrSubscriptionNotFound):
+		json.JSONError(w, http.StatusNotFound, err.Error(), nil)
+	default:
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+	}
+}
@@ -0,0 +1,241 @@
+// Package subscriptions where practice plans are defined and candidates' time on them is tracked
+package subscriptions
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+)
+
+var (
+	ErrPlanNotFound         = errors.New(constants.ErrPlanNotFound)
+	ErrPlanItemNotFound     = errors.New(constants.ErrPlanItemNotFound)
+	ErrSubscriptionNotFound = errors.New(constants.ErrSubscriptionNotFound)
+)
+
+type svc struct {
+	repo *repo.Queries
+	db   *pgxpool.Pool
+}
+
+func NewService(repo *repo.Queries, db *pgxpool.Pool) Service {
+	return &svc{repo: repo, db: db}
+}
+
+func (s *svc) CreatePlan(ctx context.Context, createdBy int64, params createPlanParams) (repo.Plan, error) {
+	return s.repo.CreatePlan(ctx, repo.CreatePlanParams{
+		Name:        params.Name,
+		Description: pgtype.Text{String: params.Description, Valid: params.Description != ""},
+		PeriodDays:  params.PeriodDays,
+		GraceDays:   params.GraceDays,
+		CreatedBy:   createdBy,
+	})
+}
+
+// UpdatePlan changes a plan for future renewals. Periods already paid for
+// keep the dates they were given.
+func (s *svc) UpdatePlan(ctx context.Context, planID int64, params updatePlanParams) (repo.Plan, error) {
+	plan, err := s.repo.UpdatePlan(ctx, repo.UpdatePlanParams{
+		ID:          planID,
+		Name:        params.Name,
+		Description: pgtype.Text{String: params.Description, Valid: params.Description != ""},
+		PeriodDays:  params.PeriodDays,
+		GraceDays:   params.GraceDays,
+		Active:      params.Active,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.Plan{}, ErrPlanNotFound
+	}
+
+	return plan, err
+}
+
+func (s *svc) GetPlan(ctx context.Context, planID int64) (planResponse, error) {
+	plan, err := s.plan(ctx, s.repo, planID)
+	if err != nil {
+		return planResponse{}, err
+	}
+
+	items, err := s.repo.ListPlanItems(ctx, planID)
+	if err != nil {
+		return planResponse{}, err
+	}
+
+	return planResponse{Plan: plan, Items: items}, nil
+}
+
+func (s *svc) ListPlans(ctx context.Context, activeOnly bool) ([]repo.Plan, error) {
+	return s.repo.ListPlans(ctx, activeOnly)
+}
+
+func (s *svc) AddPlanItem(ctx context.Context, planID int64, params planItemParams) error {
+	if _, err := s.plan(ctx, s.repo, planID); err != nil {
+		return err
+	}
+
+	if params.TargetType == repo.AccessTargetExam {
+		if _, err := s.repo.GetExamByID(ctx, params.TargetID); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.AddPlanItem(ctx, repo.AddPlanItemParams{
+		PlanID:     planID,
+		TargetType: params.TargetType,
+		TargetID:   params.TargetID,
+	})
+}
+
+func (s *svc) RemovePlanItem(ctx context.Context, planID int64, params planItemParams) error {
+	n, err := s.repo.RemovePlanItem(ctx, repo.RemovePlanItemParams{
+		PlanID:     planID,
+		TargetType: params.TargetType,
+		TargetID:   params.TargetID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrPlanItemNotFound
+	}
+
+	return nil
+}
+
+func (s *svc) ListSubscriptions(ctx context.Context, userID int64) ([]repo.ListUserSubscriptionsRow, error) {
+	return s.repo.ListUserSubscriptions(ctx, userID)
+}
+
+// Cancel stops a subscription at the end of the current period. The grace
+// period is given up, since it only exists to allow renewing.
+func (s *svc) Cancel(ctx context.Context, userID, planID int64) (repo.Subscription, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.Subscription{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	sub, err := qtx.GetSubscriptionForUpdate(ctx, repo.GetSubscriptionForUpdateParams{UserID: userID, PlanID: planID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.Subscription{}, ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return repo.Subscription{}, err
+	}
+
+	if sub.CancelledAt.Valid {
+		return sub, nil
+	}
+
+	cancelled, err := qtx.CancelSubscription(ctx, sub.ID)
+	if err != nil {
+		return repo.Subscription{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Subscription{}, err
+	}
+
+	return cancelled, nil
+}
+
+// Grant gives a candidate one period of a plan without payment.
+func (s *svc) Grant(ctx context.Context, params grantParams) (repo.Subscription, error) {
+	if _, err := s.repo.GetUserByID(ctx, params.UserID); err != nil {
+		return repo.Subscription{}, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.Subscription{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	sub, err := s.Extend(ctx, s.repo.WithTx(tx), params.UserID, params.PlanID)
+	if err != nil {
+		return repo.Subscription{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Subscription{}, err
+	}
+
+	return sub, nil
+}
+
+// Extend adds one period of a plan. Renewing before access runs out, grace
+// included, continues from the current period end so no paid time is lost;
+// after that a fresh period starts now. It runs on the caller's transaction.
+func (s *svc) Extend(ctx context.Context, q *repo.Queries, userID, planID int64) (repo.Subscription, error) {
+	plan, err := s.plan(ctx, q, planID)
+	if err != nil {
+		return repo.Subscription{}, err
+	}
+
+	now := time.Now()
+
+	sub, err := q.GetSubscriptionForUpdate(ctx, repo.GetSubscriptionForUpdateParams{UserID: userID, PlanID: planID})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return repo.Subscription{}, err
+	}
+
+	exists := err == nil
+
+	start := now
+	if exists && sub.GraceEndsAt.Time.After(now) {
+		start = sub.CurrentPeriodEnd.Time
+	}
+
+	end := start.AddDate(0, 0, int(plan.PeriodDays))
+	graceEnd := end.AddDate(0, 0, int(plan.GraceDays))
+
+	if !exists {
+		return q.CreateSubscription(ctx, repo.CreateSubscriptionParams{
+			UserID:             userID,
+			PlanID:             planID,
+			CurrentPeriodStart: pgtype.Timestamptz{Time: start, Valid: true},
+			CurrentPeriodEnd:   pgtype.Timestamptz{Time: end, Valid: true},
+			GraceEndsAt:        pgtype.Timestamptz{Time: graceEnd, Valid: true},
+		})
+	}
+
+	return q.RenewSubscription(ctx, repo.RenewSubscriptionParams{
+		ID:                 sub.ID,
+		CurrentPeriodStart: pgtype.Timestamptz{Time: start, Valid: true},
+		CurrentPeriodEnd:   pgtype.Timestamptz{Time: end, Valid: true},
+		GraceEndsAt:        pgtype.Timestamptz{Time: graceEnd, Valid: true},
+	})
+}
+
+// Terminate ends a subscription immediately, as when its payment is
+// refunded. It runs on the caller's transaction.
+func (s *svc) Terminate(ctx context.Context, q *repo.Queries, userID, planID int64) error {
+	sub, err := q.GetSubscriptionForUpdate(ctx, repo.GetSubscriptionForUpdateParams{UserID: userID, PlanID: planID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = q.TerminateSubscription(ctx, sub.ID)
+	return err
+}
+
+func (s *svc) plan(ctx context.Context, q *repo.Queries, planID int64) (repo.Plan, error) {
+	plan, err := q.GetPlan(ctx, planID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.Plan{}, ErrPlanNotFound
+	}
+
+	return plan, err
+}
@@ -0,0 +1,49 @@
+package subscriptions
+
+import (
+	"context"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+type Service interface {
+	CreatePlan(ctx context.Context, createdBy int64, params createPlanParams) (repo.Plan, error)
+	UpdatePlan(ctx context.Context, planID int64, params updatePlanParams) (repo.Plan, error)
+	GetPlan(ctx context.Context, planID int64) (planResponse, error)
+	ListPlans(ctx context.Context, activeOnly bool) ([]repo.Plan, error)
+	AddPlanItem(ctx context.Context, planID int64, params planItemParams) error
+	RemovePlanItem(ctx context.Context, planID int64, params planItemParams) error
+	ListSubscriptions(ctx context.Context, userID int64) ([]repo.ListUserSubscriptionsRow, error)
+	Cancel(ctx context.Context, userID, planID int64) (repo.Subscription, error)
+	Grant(ctx context.Context, params grantParams) (repo.Subscription, error)
+	Extend(ctx context.Context, q *repo.Queries, userID, planID int64) (repo.Subscription, error)
+	Terminate(ctx context.Context, q *repo.Queries, userID, planID int64) error
+}
+
+type createPlanParams struct {
+	Name        string `json:"name" validate:"required,min=2,max=100"`
+	Description string `json:"description" validate:"max=1000"`
+	PeriodDays  int32  `json:"period_days" validate:"required,gt=0,lte=3660"`
+	GraceDays   int32  `json:"grace_days" validate:"gte=0,lte=90"`
+}
+
+type updatePlanParams struct {
+	createPlanParams
+	Active bool `json:"active"`
+}
+
+// planItemParams names something a plan unlocks. Plans can't contain plans.
+type planItemParams struct {
+	TargetType repo.AccessTarget `json:"target_type" validate:"required,oneof=exam practice_pack"`
+	TargetID   int64             `json:"target_id" validate:"required,gt=0"`
+}
+
+type grantParams struct {
+	UserID int64 `json:"user_id" validate:"required,gt=0"`
+	PlanID int64 `json:"plan_id" validate:"required,gt=0"`
+}
+
+type planResponse struct {
+	Plan  repo.Plan       `json:"plan"`
+	Items []repo.PlanItem `json:"items"`
+}
@@ -2,17 +2,21 @@ package users
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/go-chi/chi"
+	"github.com/jackc/pgx/v5"
 	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
 	"github.com/odundlaw/cbt-backend/internal/config"
 	"github.com/odundlaw/cbt-backend/internal/constants"
 	"github.com/odundlaw/cbt-backend/internal/helpers"
 	"github.com/odundlaw/cbt-backend/internal/json"
 	"github.com/odundlaw/cbt-backend/internal/jwt"
+	"github.com/odundlaw/cbt-backend/internal/middlewares"
 	"github.com/odundlaw/cbt-backend/internal/store"
 	"github.com/odundlaw/cbt-backend/internal/validation"
 )
@@ -44,6 +48,10 @@ func (h *Handler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	user, err := h.service.CreateUser(r.Context(), req)
+	if errors.Is(err, ErrInvalidReferralCode) {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
 	if err != nil {
 		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
 		return
@@ -192,7 +200,7 @@ func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.service.UpdatePassword(r.Context(), repo.UpdateUserPasswordParams{
+	user, err := h.service.ResetPassword(r.Context(), repo.UpdateUserPasswordParams{
 		ID:       claims.UserID,
 		Password: req.Password,
 	})
@@ -386,3 +394,130 @@ func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 		ExpiresIn:   toks.ExpAcc.Second(),
 	})
 }
+
+func (h *Handler) GrantPermission(w http.ResponseWriter, r *http.Request) {
+	userID, err := helpers.IDParam(r, "userID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	grantedBy, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	var req grantPermissionParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	permission, err := h.service.GrantPermission(r.Context(), userID, grantedBy, req.Permission)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		json.JSONError(w, http.StatusNotFound, constants.ErrUserNotFound, nil)
+		return
+	case errors.Is(err, ErrSelfGrant):
+		json.JSONError(w, http.StatusForbidden, err.Error(), nil)
+		return
+	case errors.Is(err, ErrNotAdmin):
+		json.JSONError(w, http.StatusUnprocessableEntity, err.Error(), nil)
+		return
+	case err != nil:
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgPermissionGranted, permission, nil)
+}
+
+func (h *Handler) RevokePermission(w http.ResponseWriter, r *http.Request) {
+	userID, err := helpers.IDParam(r, "userID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	permission := repo.AdminPermission(chi.URLParam(r, "permission"))
+
+	err = h.service.RevokePermission(r.Context(), userID, permission)
+	if errors.Is(err, ErrPermissionNotGranted) {
+		json.JSONError(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgPermissionRevoked, nil, nil)
+}
+
+func (h *Handler) ListPermissions(w http.ResponseWriter, r *http.Request) {
+	userID, err := helpers.IDParam(r, "userID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	permissions, err := h.service.ListPermissions(r.Context(), userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		json.JSONError(w, http.StatusNotFound, constants.ErrUserNotFound, nil)
+		return
+	}
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, permissions, nil)
+}
+
+func (h *Handler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	userID, err := helpers.IDParam(r, "userID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req updateRoleParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	user, err := h.service.UpdateRole(r.Context(), userID, req.Role)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		json.JSONError(w, http.StatusNotFound, constants.ErrUserNotFound, nil)
+		return
+	case errors.Is(err, ErrCannotChangeAdminRole):
+		json.JSONError(w, http.StatusUnprocessableEntity, err.Error(), nil)
+		return
+	case err != nil:
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgRoleUpdated, roleResponse{
+		UserID:       user.ID,
+		Role:         user.Role,
+		ReferralCode: user.ReferralCode.String,
+	}, nil)
+}
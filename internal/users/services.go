@@ -3,20 +3,41 @@ package users
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
+	"strings"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
 	"github.com/odundlaw/cbt-backend/internal/constants"
 	"github.com/odundlaw/cbt-backend/internal/helpers"
 )
 
+var (
+	ErrNotAdmin              = errors.New(constants.ErrNotAdmin)
+	ErrPermissionNotGranted  = errors.New(constants.ErrPermissionNotGranted)
+	ErrCannotChangeAdminRole = errors.New(constants.ErrCannotChangeAdminRole)
+	ErrInvalidReferralCode   = errors.New(constants.ErrInvalidReferralCode)
+	ErrSelfGrant             = errors.New(constants.ErrSelfGrant)
+)
+
+// referralAlphabet leaves out 0/O and 1/I so codes survive being read aloud.
+const (
+	referralAlphabet   = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	referralCodeLength = 8
+)
+
 type svc struct {
-	repo *repo.Queries
+	repo      *repo.Queries
+	db        *pgxpool.Pool
+	referrals Referrals
 }
 
-func NewService(repo *repo.Queries) Service {
-	return &svc{repo: repo}
+func NewService(repo *repo.Queries, db *pgxpool.Pool, referrals Referrals) Service {
+	return &svc{repo: repo, db: db, referrals: referrals}
 }
 
 func (s *svc) CreateUser(ctx context.Context, userParams createUserParams) (repo.User, error) {
@@ -25,13 +46,49 @@ func (s *svc) CreateUser(ctx context.Context, userParams createUserParams) (repo
 		return repo.User{}, errors.New(constants.ErrFailedHashPass)
 	}
 
-	user := repo.CreateUserParams{
-		FullName: userParams.FullName,
-		Email:    userParams.Email,
-		Password: hashed,
+	var agentID pgtype.Int8
+	if code := strings.ToUpper(strings.TrimSpace(userParams.ReferralCode)); code != "" {
+		agent, err := s.repo.GetUserByReferralCode(ctx, pgtype.Text{String: code, Valid: true})
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && agent.Role != repo.UserRoleAGENT) {
+			return repo.User{}, ErrInvalidReferralCode
+		}
+		if err != nil {
+			return repo.User{}, err
+		}
+		agentID = pgtype.Int8{Int64: agent.ID, Valid: true}
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.User{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	user, err := qtx.CreateUser(ctx, repo.CreateUserParams{
+		FullName:   userParams.FullName,
+		Email:      userParams.Email,
+		Password:   hashed,
+		ReferredBy: agentID,
+	})
+	if err != nil {
+		return repo.User{}, err
+	}
+
+	// The referring agent's commission is posted with the account, so a
+	// referred candidate never exists without it.
+	if agentID.Valid {
+		if err := s.referrals.EarnOnRegistration(ctx, qtx, agentID.Int64, user.ID); err != nil {
+			return repo.User{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.User{}, err
 	}
 
-	return s.repo.CreateUser(ctx, user)
+	return user, nil
 }
 
 func (s *svc) CreateAdmin(ctx context.Context, params createAdminParams) (repo.User, error) {
@@ -64,16 +121,138 @@ func (s *svc) UpdateLastLogin(ctx context.Context, ID int64) (repo.User, error)
 	return s.repo.UpdateLastLogin(ctx, ID)
 }
 
-func (s *svc) UpdatePassword(ctx context.Context, params repo.UpdateUserPasswordParams) (repo.User, error) {
+// ResetPassword sets a new password from a reset link. The link was sent to
+// the user's address, so following it also verifies their email.
+func (s *svc) ResetPassword(ctx context.Context, params repo.UpdateUserPasswordParams) (repo.User, error) {
 	hashed, err := helpers.HashPassword(params.Password)
 	if err != nil {
 		return repo.User{}, errors.New(constants.ErrFailedHashPass)
 	}
 
-	update := repo.UpdateUserPasswordParams{
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.User{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	user, err := qtx.UpdateUserPassword(ctx, repo.UpdateUserPasswordParams{
 		ID:       params.ID,
 		Password: hashed,
+	})
+	if err != nil {
+		return repo.User{}, err
+	}
+
+	if err := qtx.MarkEmailVerified(ctx, user.ID); err != nil {
+		return repo.User{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.User{}, err
+	}
+
+	return user, nil
+}
+
+// GrantPermission gives an admin a grading, review or super admin
+// permission. Only admins can hold one, and nobody grants one to themselves.
+func (s *svc) GrantPermission(ctx context.Context, userID, grantedBy int64, permission repo.AdminPermission) (repo.UserPermission, error) {
+	if userID == grantedBy {
+		return repo.UserPermission{}, ErrSelfGrant
+	}
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return repo.UserPermission{}, err
+	}
+
+	if user.Role != repo.UserRoleADMIN {
+		return repo.UserPermission{}, ErrNotAdmin
+	}
+
+	return s.repo.GrantUserPermission(ctx, repo.GrantUserPermissionParams{
+		UserID:     userID,
+		Permission: permission,
+		GrantedBy:  grantedBy,
+	})
+}
+
+func (s *svc) RevokePermission(ctx context.Context, userID int64, permission repo.AdminPermission) error {
+	n, err := s.repo.RevokeUserPermission(ctx, repo.RevokeUserPermissionParams{
+		UserID:     userID,
+		Permission: permission,
+	})
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrPermissionNotGranted
+	}
+
+	return nil
+}
+
+func (s *svc) ListPermissions(ctx context.Context, userID int64) ([]repo.UserPermission, error) {
+	if _, err := s.repo.GetUserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListUserPermissions(ctx, userID)
+}
+
+func (s *svc) UpdateRole(ctx context.Context, userID int64, role repo.UserRole) (repo.User, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return repo.User{}, err
+	}
+
+	if user.Role == repo.UserRoleADMIN {
+		return repo.User{}, ErrCannotChangeAdminRole
+	}
+
+	updated, err := s.repo.UpdateUserRole(ctx, repo.UpdateUserRoleParams{ID: userID, Role: role})
+	if err != nil || role != repo.UserRoleAGENT || updated.ReferralCode.Valid {
+		return updated, err
+	}
+
+	return s.assignReferralCode(ctx, userID)
+}
+
+// assignReferralCode gives a new agent the code candidates register with,
+// drawing again on the rare clash with an existing code.
+func (s *svc) assignReferralCode(ctx context.Context, userID int64) (repo.User, error) {
+	for {
+		code, err := newReferralCode()
+		if err != nil {
+			return repo.User{}, err
+		}
+
+		user, err := s.repo.SetReferralCode(ctx, repo.SetReferralCodeParams{
+			ID:           userID,
+			ReferralCode: pgtype.Text{String: code, Valid: true},
+		})
+
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			continue
+		}
+
+		return user, err
+	}
+}
+
+func newReferralCode() (string, error) {
+	b := make([]byte, referralCodeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	for i := range b {
+		b[i] = referralAlphabet[int(b[i])%len(referralAlphabet)]
 	}
 
-	return s.repo.UpdateUserPassword(ctx, update)
+	return string(b), nil
 }
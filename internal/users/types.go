@@ -12,13 +12,23 @@ type Service interface {
 	GetUserByID(ctx context.Context, ID int64) (repo.User, error)
 	GetUserByEmail(ctx context.Context, email string) (repo.User, error)
 	UpdateLastLogin(ctx context.Context, ID int64) (repo.User, error)
-	UpdatePassword(ctx context.Context, params repo.UpdateUserPasswordParams) (repo.User, error)
+	ResetPassword(ctx context.Context, params repo.UpdateUserPasswordParams) (repo.User, error)
+	GrantPermission(ctx context.Context, userID, grantedBy int64, permission repo.AdminPermission) (repo.UserPermission, error)
+	RevokePermission(ctx context.Context, userID int64, permission repo.AdminPermission) error
+	ListPermissions(ctx context.Context, userID int64) ([]repo.UserPermission, error)
+	UpdateRole(ctx context.Context, userID int64, role repo.UserRole) (repo.User, error)
+}
+
+// Referrals credits an agent for a candidate who registered with their code.
+type Referrals interface {
+	EarnOnRegistration(ctx context.Context, q *repo.Queries, agentID, userID int64) error
 }
 
 type createUserParams struct {
-	FullName string `json:"full_name" validate:"required,min=3,max=100"`
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8"`
+	FullName     string `json:"full_name" validate:"required,min=3,max=100"`
+	Email        string `json:"email" validate:"required,email"`
+	Password     string `json:"password" validate:"required,min=8"`
+	ReferralCode string `json:"referral_code" validate:"omitempty,max=16"`
 }
 
 type loginParams struct {
@@ -69,3 +79,19 @@ type LogoutResData struct {
 	UserID      int64  `json:"user_id"`
 	LoggedOutAt string `json:"logged_out_at"`
 }
+
+// updateRoleParams only moves accounts between candidate and agent. Admins
+// go through admin registration and approval.
+type updateRoleParams struct {
+	Role repo.UserRole `json:"role" validate:"required,oneof=USER AGENT"`
+}
+
+type roleResponse struct {
+	UserID       int64         `json:"user_id"`
+	Role         repo.UserRole `json:"role"`
+	ReferralCode string        `json:"referral_code,omitempty"`
+}
+
+type grantPermissionParams struct {
+	Permission repo.AdminPermission `json:"permission" validate:"required,oneof=grader moderator reviewer super_admin"`
+}
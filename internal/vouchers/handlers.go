@@ -0,0 +1,261 @@
+package vouchers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/jackc/pgx/v5"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+	"github.com/odundlaw/cbt-backend/internal/helpers"
+	"github.com/odundlaw/cbt-backend/internal/json"
+	"github.com/odundlaw/cbt-backend/internal/middlewares"
+	"github.com/odundlaw/cbt-backend/internal/validation"
+)
+
+const dateLayout = "2006-01-02"
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service,
+	}
+}
+
+func (h *Handler) GenerateBatch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	var req generateBatchParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	batch, err := h.service.GenerateBatch(r.Context(), userID, req)
+	if errors.Is(err, pgx.ErrNoRows) {
+		json.JSONError(w, http.StatusNotFound, constants.ErrExamNotFound, nil)
+		return
+	}
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusCreated, constants.MsgVoucherBatchCreated, batch, nil)
+}
+
+func (h *Handler) ListBatches(w http.ResponseWriter, r *http.Request) {
+	limit, offset := helpers.Pagination(r)
+
+	batches, err := h.service.ListBatches(r.Context(), limit, offset)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, batches, nil)
+}
+
+func (h *Handler) GetBatch(w http.ResponseWriter, r *http.Request) {
+	batchID, err := helpers.IDParam(r, "batchID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	batch, err := h.service.GetBatch(r.Context(), batchID)
+	if err != nil {
+		writeVoucherError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, batch, nil)
+}
+
+func (h *Handler) AllocateBatch(w http.ResponseWriter, r *http.Request) {
+	batchID, err := helpers.IDParam(r, "batchID")
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidInput, nil)
+		return
+	}
+
+	var req allocateParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	allocation, err := h.service.AllocateBatch(r.Context(), batchID, req)
+	if errors.Is(err, pgx.ErrNoRows) {
+		json.JSONError(w, http.StatusNotFound, constants.ErrUserNotFound, nil)
+		return
+	}
+	if err != nil {
+		writeVoucherError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgVouchersAllocated, allocation, nil)
+}
+
+func (h *Handler) VoidVoucher(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.VoidVoucher(r.Context(), chi.URLParam(r, "serial")); err != nil {
+		writeVoucherError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgVoucherVoided, nil, nil)
+}
+
+func (h *Handler) Redeem(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	var req redeemParams
+
+	if err := json.ReadJSON(r, &req); err != nil {
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := validation.Validate.Struct(req); err != nil {
+		formattedErr := validation.FormatValidationErrors(err)
+		json.JSONError(w, http.StatusBadRequest, constants.ErrValidationFailed, formattedErr)
+		return
+	}
+
+	grant, err := h.service.Redeem(r.Context(), userID, req)
+	if err != nil {
+		writeVoucherError(w, err)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgVoucherRedeemed, grant, nil)
+}
+
+func (h *Handler) ListAccess(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	grants, err := h.service.ListAccess(r.Context(), userID)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, grants, nil)
+}
+
+func (h *Handler) AgentStock(w http.ResponseWriter, r *http.Request) {
+	agentID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	stock, err := h.service.AgentStock(r.Context(), agentID)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, stock, nil)
+}
+
+// AgentSales reports sales between the from and to query dates, both
+// inclusive. It defaults to the last 30 days.
+func (h *Handler) AgentSales(w http.ResponseWriter, r *http.Request) {
+	agentID, ok := middlewares.UserIDFromContext(r.Context())
+	if !ok {
+		json.JSONError(w, http.StatusUnauthorized, constants.ErrUnauthorized, nil)
+		return
+	}
+
+	from, to, err := dateRange(r)
+	if err != nil {
+		json.JSONError(w, http.StatusBadRequest, constants.ErrInvalidDateRange, nil)
+		return
+	}
+
+	report, err := h.service.AgentSales(r.Context(), agentID, from, to)
+	if err != nil {
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	json.JSONSuccess(w, http.StatusOK, constants.MsgFetchSuccessful, report, nil)
+}
+
+func dateRange(r *http.Request) (time.Time, time.Time, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	from, to := today.AddDate(0, 0, -30), today
+
+	if v := r.URL.Query().Get("from"); v != "" {
+		t, err := time.Parse(dateLayout, v)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		from = t
+	}
+
+	if v := r.URL.Query().Get("to"); v != "" {
+		t, err := time.Parse(dateLayout, v)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to = t
+	}
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, errors.New(constants.ErrInvalidDateRange)
+	}
+
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+func writeVoucherError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrBatchNotFound), errors.Is(err, ErrVoucherNotFound):
+		json.JSONError(w, http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, ErrInvalidVoucher):
+		json.JSONError(w, http.StatusBadRequest, err.Error(), nil)
+	case errors.Is(err, ErrVoucherVoid),
+		errors.Is(err, ErrVoucherExpired),
+		errors.Is(err, ErrVoucherUsedUp),
+		errors.Is(err, ErrVoucherAlreadyRedeemed),
+		errors.Is(err, ErrInsufficientStock):
+		json.JSONError(w, http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, ErrNotAgent):
+		json.JSONError(w, http.StatusUnprocessableEntity, err.Error(), nil)
+	default:
+		json.JSONError(w, http.StatusInternalServerError, err.Error(), nil)
+	}
+}
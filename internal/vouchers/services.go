@@ -0,0 +1,336 @@
+// Package vouchers where scratch-card PINs are generated, handed to agents and redeemed
+package vouchers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+	"github.com/odundlaw/cbt-backend/internal/config"
+	"github.com/odundlaw/cbt-backend/internal/constants"
+)
+
+// pinDigits is long enough that guessing a PIN for a known serial is hopeless.
+const pinDigits = 12
+
+const accessSource = "voucher"
+
+var (
+	ErrInvalidVoucher         = errors.New(constants.ErrInvalidVoucher)
+	ErrVoucherVoid            = errors.New(constants.ErrVoucherVoid)
+	ErrVoucherExpired         = errors.New(constants.ErrVoucherExpired)
+	ErrVoucherUsedUp          = errors.New(constants.ErrVoucherUsedUp)
+	ErrVoucherAlreadyRedeemed = errors.New(constants.ErrVoucherAlreadyRedeemed)
+	ErrVoucherNotFound        = errors.New(constants.ErrVoucherNotFound)
+	ErrBatchNotFound          = errors.New(constants.ErrBatchNotFound)
+	ErrNotAgent               = errors.New(constants.ErrNotAgent)
+	ErrInsufficientStock      = errors.New(constants.ErrInsufficientStock)
+)
+
+type svc struct {
+	repo        *repo.Queries
+	db          *pgxpool.Pool
+	commissions Commissions
+}
+
+func NewService(repo *repo.Queries, db *pgxpool.Pool, commissions Commissions) Service {
+	return &svc{repo: repo, db: db, commissions: commissions}
+}
+
+// GenerateBatch creates params.Quantity serial/PIN pairs. Only PIN hashes are
+// stored, so the returned PINs are the one chance to print them.
+func (s *svc) GenerateBatch(ctx context.Context, createdBy int64, params generateBatchParams) (generatedBatch, error) {
+	if params.TargetType == repo.AccessTargetExam {
+		if _, err := s.repo.GetExamByID(ctx, params.TargetID); err != nil {
+			return generatedBatch{}, err
+		}
+	}
+
+	maxUses := params.MaxUses
+	if maxUses == 0 {
+		maxUses = 1
+	}
+
+	var expiresAt pgtype.Timestamptz
+	if params.ExpiresAt != nil {
+		expiresAt = pgtype.Timestamptz{Time: *params.ExpiresAt, Valid: true}
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return generatedBatch{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	batch, err := qtx.CreateVoucherBatch(ctx, repo.CreateVoucherBatchParams{
+		Name:       params.Name,
+		TargetType: params.TargetType,
+		TargetID:   params.TargetID,
+		Quantity:   params.Quantity,
+		MaxUses:    maxUses,
+		UnitPrice:  params.UnitPrice,
+		ExpiresAt:  expiresAt,
+		CreatedBy:  createdBy,
+	})
+	if err != nil {
+		return generatedBatch{}, err
+	}
+
+	issued := make([]issuedVoucher, 0, params.Quantity)
+	insert := repo.CreateVouchersParams{BatchID: batch.ID}
+
+	for i := range int(params.Quantity) {
+		pin, err := newPin()
+		if err != nil {
+			return generatedBatch{}, err
+		}
+
+		serial := fmt.Sprintf("V%d-%06d", batch.ID, i+1)
+		issued = append(issued, issuedVoucher{Serial: serial, Pin: pin})
+		insert.Serials = append(insert.Serials, serial)
+		insert.PinHashes = append(insert.PinHashes, hashPin(pin))
+	}
+
+	if _, err := qtx.CreateVouchers(ctx, insert); err != nil {
+		return generatedBatch{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return generatedBatch{}, err
+	}
+
+	return generatedBatch{Batch: batch, Vouchers: issued}, nil
+}
+
+func (s *svc) ListBatches(ctx context.Context, limit, offset int32) ([]repo.VoucherBatch, error) {
+	return s.repo.ListVoucherBatches(ctx, repo.ListVoucherBatchesParams{Limit: limit, Offset: offset})
+}
+
+func (s *svc) GetBatch(ctx context.Context, batchID int64) (batchResponse, error) {
+	batch, err := s.batch(ctx, s.repo, batchID)
+	if err != nil {
+		return batchResponse{}, err
+	}
+
+	stats, err := s.repo.GetVoucherBatchStats(ctx, batchID)
+	if err != nil {
+		return batchResponse{}, err
+	}
+
+	return batchResponse{Batch: batch, Stats: stats}, nil
+}
+
+// AllocateBatch hands the next params.Quantity unallocated vouchers of a batch
+// to an agent. Nothing is allocated unless the whole quantity is available.
+func (s *svc) AllocateBatch(ctx context.Context, batchID int64, params allocateParams) (allocationResponse, error) {
+	agent, err := s.repo.GetUserByID(ctx, params.AgentID)
+	if err != nil {
+		return allocationResponse{}, err
+	}
+
+	if agent.Role != repo.UserRoleAGENT {
+		return allocationResponse{}, ErrNotAgent
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return allocationResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	if _, err := s.batch(ctx, qtx, batchID); err != nil {
+		return allocationResponse{}, err
+	}
+
+	serials, err := qtx.AllocateVouchers(ctx, repo.AllocateVouchersParams{
+		AgentID:  pgtype.Int8{Int64: agent.ID, Valid: true},
+		BatchID:  batchID,
+		Quantity: params.Quantity,
+	})
+	if err != nil {
+		return allocationResponse{}, err
+	}
+
+	if len(serials) < int(params.Quantity) {
+		return allocationResponse{}, ErrInsufficientStock
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return allocationResponse{}, err
+	}
+
+	return allocationResponse{BatchID: batchID, AgentID: agent.ID, Serials: serials}, nil
+}
+
+func (s *svc) VoidVoucher(ctx context.Context, serial string) error {
+	n, err := s.repo.VoidVoucher(ctx, normalizeSerial(serial))
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrVoucherNotFound
+	}
+
+	return nil
+}
+
+// Redeem checks a serial/PIN pair and unlocks what its batch is for. Unknown
+// serials and wrong PINs fail the same way so serials can't be probed.
+func (s *svc) Redeem(ctx context.Context, userID int64, params redeemParams) (repo.AccessGrant, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return repo.AccessGrant{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.repo.WithTx(tx)
+
+	voucher, err := qtx.GetVoucherBySerialForUpdate(ctx, normalizeSerial(params.Serial))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.AccessGrant{}, ErrInvalidVoucher
+	}
+	if err != nil {
+		return repo.AccessGrant{}, err
+	}
+
+	if !hmac.Equal([]byte(hashPin(normalizePin(params.Pin))), []byte(voucher.PinHash)) {
+		return repo.AccessGrant{}, ErrInvalidVoucher
+	}
+
+	if voucher.Status == repo.VoucherStatusVoid {
+		return repo.AccessGrant{}, ErrVoucherVoid
+	}
+
+	batch, err := qtx.GetVoucherBatch(ctx, voucher.BatchID)
+	if err != nil {
+		return repo.AccessGrant{}, err
+	}
+
+	if batch.ExpiresAt.Valid && !time.Now().Before(batch.ExpiresAt.Time) {
+		return repo.AccessGrant{}, ErrVoucherExpired
+	}
+
+	if voucher.Uses >= batch.MaxUses {
+		return repo.AccessGrant{}, ErrVoucherUsedUp
+	}
+
+	if _, err := qtx.CreateVoucherRedemption(ctx, repo.CreateVoucherRedemptionParams{
+		VoucherID: voucher.ID,
+		UserID:    userID,
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repo.AccessGrant{}, ErrVoucherAlreadyRedeemed
+		}
+		return repo.AccessGrant{}, err
+	}
+
+	if err := qtx.IncrementVoucherUses(ctx, voucher.ID); err != nil {
+		return repo.AccessGrant{}, err
+	}
+
+	if err := s.commissions.EarnOnRedemption(ctx, qtx, voucher, batch); err != nil {
+		return repo.AccessGrant{}, err
+	}
+
+	grant, err := qtx.GrantAccess(ctx, repo.GrantAccessParams{
+		UserID:     userID,
+		TargetType: batch.TargetType,
+		TargetID:   batch.TargetID,
+		Source:     accessSource,
+		SourceID:   pgtype.Int8{Int64: voucher.ID, Valid: true},
+	})
+	if err != nil {
+		return repo.AccessGrant{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.AccessGrant{}, err
+	}
+
+	return grant, nil
+}
+
+func (s *svc) ListAccess(ctx context.Context, userID int64) ([]repo.AccessGrant, error) {
+	return s.repo.ListUserAccessGrants(ctx, userID)
+}
+
+func (s *svc) AgentStock(ctx context.Context, agentID int64) ([]repo.ListAgentStockRow, error) {
+	return s.repo.ListAgentStock(ctx, pgtype.Int8{Int64: agentID, Valid: true})
+}
+
+// AgentSales lists an agent's vouchers first redeemed in [from, to). A PIN
+// that several candidates redeem is one sale.
+func (s *svc) AgentSales(ctx context.Context, agentID int64, from, to time.Time) (salesReport, error) {
+	sales, err := s.repo.ListAgentSales(ctx, repo.ListAgentSalesParams{
+		AgentID:  pgtype.Int8{Int64: agentID, Valid: true},
+		SoldFrom: pgtype.Timestamptz{Time: from, Valid: true},
+		SoldTo:   pgtype.Timestamptz{Time: to, Valid: true},
+	})
+	if err != nil {
+		return salesReport{}, err
+	}
+
+	report := salesReport{From: from, To: to, Count: len(sales), Sales: sales}
+	for _, sale := range sales {
+		report.Revenue += sale.UnitPrice
+	}
+
+	return report, nil
+}
+
+func (s *svc) batch(ctx context.Context, q *repo.Queries, batchID int64) (repo.VoucherBatch, error) {
+	batch, err := q.GetVoucherBatch(ctx, batchID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repo.VoucherBatch{}, ErrBatchNotFound
+	}
+
+	return batch, err
+}
+
+func newPin() (string, error) {
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(pinDigits), nil)
+
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", pinDigits, n), nil
+}
+
+func hashPin(pin string) string {
+	mac := hmac.New(sha256.New, config.VoucherPinSecret)
+	mac.Write([]byte(pin))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// normalizePin drops the spaces and dashes people copy off printed cards.
+func normalizePin(pin string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, pin)
+}
+
+func normalizeSerial(serial string) string {
+	return strings.ToUpper(strings.TrimSpace(serial))
+}
@@ -0,0 +1,78 @@
+package vouchers
+
+import (
+	"context"
+	"time"
+
+	repo "github.com/odundlaw/cbt-backend/internal/adapters/postgresql/sqlc"
+)
+
+type Service interface {
+	GenerateBatch(ctx context.Context, createdBy int64, params generateBatchParams) (generatedBatch, error)
+	ListBatches(ctx context.Context, limit, offset int32) ([]repo.VoucherBatch, error)
+	GetBatch(ctx context.Context, batchID int64) (batchResponse, error)
+	AllocateBatch(ctx context.Context, batchID int64, params allocateParams) (allocationResponse, error)
+	VoidVoucher(ctx context.Context, serial string) error
+	Redeem(ctx context.Context, userID int64, params redeemParams) (repo.AccessGrant, error)
+	ListAccess(ctx context.Context, userID int64) ([]repo.AccessGrant, error)
+	AgentStock(ctx context.Context, agentID int64) ([]repo.ListAgentStockRow, error)
+	AgentSales(ctx context.Context, agentID int64, from, to time.Time) (salesReport, error)
+}
+
+// Commissions credits the agent behind a sale. It posts on the redemption's
+// transaction.
+type Commissions interface {
+	EarnOnRedemption(ctx context.Context, q *repo.Queries, voucher repo.Voucher, batch repo.VoucherBatch) error
+}
+
+type generateBatchParams struct {
+	Name       string            `json:"name" validate:"required,min=2,max=100"`
+	TargetType repo.AccessTarget `json:"target_type" validate:"required,oneof=exam practice_pack"`
+	TargetID   int64             `json:"target_id" validate:"required,gt=0"`
+	Quantity   int32             `json:"quantity" validate:"required,gt=0,lte=10000"`
+	// MaxUses is how many candidates may redeem one PIN. Defaults to 1.
+	MaxUses   int32      `json:"max_uses" validate:"gte=0"`
+	UnitPrice int64      `json:"unit_price" validate:"gte=0"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type allocateParams struct {
+	AgentID  int64 `json:"agent_id" validate:"required,gt=0"`
+	Quantity int32 `json:"quantity" validate:"required,gt=0"`
+}
+
+type redeemParams struct {
+	Serial string `json:"serial" validate:"required"`
+	Pin    string `json:"pin" validate:"required"`
+}
+
+// issuedVoucher carries a PIN in the clear. It is only ever returned from
+// batch generation, for printing.
+type issuedVoucher struct {
+	Serial string `json:"serial"`
+	Pin    string `json:"pin"`
+}
+
+type generatedBatch struct {
+	Batch    repo.VoucherBatch `json:"batch"`
+	Vouchers []issuedVoucher   `json:"vouchers"`
+}
+
+type batchResponse struct {
+	Batch repo.VoucherBatch            `json:"batch"`
+	Stats repo.GetVoucherBatchStatsRow `json:"stats"`
+}
+
+type allocationResponse struct {
+	BatchID int64    `json:"batch_id"`
+	AgentID int64    `json:"agent_id"`
+	Serials []string `json:"serials"`
+}
+
+type salesReport struct {
+	From    time.Time                `json:"from"`
+	To      time.Time                `json:"to"`
+	Count   int                      `json:"count"`
+	Revenue int64                    `json:"revenue"`
+	Sales   []repo.ListAgentSalesRow `json:"sales"`
+}